@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/authzed/authzed-go/v1"
+	"github.com/spf13/cobra"
+	"go.infratographer.com/x/otelx"
+
+	"go.infratographer.com/permissions-api/internal/backup"
+	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+)
+
+var (
+	backupCmd = &cobra.Command{
+		Use:   "backup",
+		Short: "export and import the relationships stored in SpiceDB",
+	}
+
+	backupExportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "write all relationships to a checksummed backup file",
+		Run: func(cmd *cobra.Command, _ []string) {
+			exportBackup(cmd.Context(), globalCfg)
+		},
+	}
+
+	backupImportCmd = &cobra.Command{
+		Use:   "import",
+		Short: "verify a backup file and write its relationships to SpiceDB, resuming an interrupted import",
+		Run: func(cmd *cobra.Command, _ []string) {
+			importBackup(cmd.Context(), globalCfg)
+		},
+	}
+
+	backupFile          string
+	backupNamespace     string
+	backupResourceTypes []string
+	backupBatchSize     int
+	backupResume        bool
+)
+
+// backupProgress records how far an import got, so it can be resumed.
+type backupProgress struct {
+	SHA256   string `json:"sha256"`
+	Imported int64  `json:"imported"`
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(backupExportCmd, backupImportCmd)
+
+	flags := backupCmd.PersistentFlags()
+	flags.StringVar(&backupFile, "file", "", "backup file to write or read")
+	flags.StringVar(&backupNamespace, "namespace", "infratographer", "SpiceDB namespace to export from or import into")
+	flags.StringSliceVar(&backupResourceTypes, "resource-types", nil, "only export or import the relationships of the given resource types")
+
+	backupImportCmd.Flags().IntVar(&backupBatchSize, "batch-size", backup.DefaultBatchSize, "number of relationships written to SpiceDB at once")
+	backupImportCmd.Flags().BoolVar(&backupResume, "resume", true, "resume an interrupted import of the same backup")
+}
+
+func exportBackup(ctx context.Context, cfg *config.AppConfig) {
+	if backupFile == "" {
+		logger.Fatal("--file is required")
+	}
+
+	resourceTypes := backupResourceTypes
+
+	if len(resourceTypes) == 0 {
+		for _, resourceType := range loadBackupPolicy(cfg).Schema() {
+			resourceTypes = append(resourceTypes, resourceType.Name)
+		}
+	}
+
+	client := newBackupClient(cfg)
+
+	// the backup is written to a temporary file first, so an interrupted
+	// export never leaves a partial backup behind.
+	tmpFile := backupFile + ".tmp"
+
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		logger.Fatalw("unable to create backup file", "file", tmpFile, "error", err)
+	}
+
+	trailer, err := backup.Export(ctx, client, f, backup.ExportOptions{
+		Namespace:     backupNamespace,
+		ResourceTypes: resourceTypes,
+	})
+	if err != nil {
+		f.Close()
+
+		logger.Fatalw("unable to export relationships", "error", err)
+	}
+
+	if err := f.Close(); err != nil {
+		logger.Fatalw("unable to write backup file", "file", tmpFile, "error", err)
+	}
+
+	if err := os.Rename(tmpFile, backupFile); err != nil {
+		logger.Fatalw("unable to write backup file", "file", backupFile, "error", err)
+	}
+
+	logger.Infow("relationships exported", "file", backupFile, "relationships", trailer.Count, "sha256", trailer.SHA256)
+}
+
+func importBackup(ctx context.Context, cfg *config.AppConfig) {
+	if backupFile == "" {
+		logger.Fatal("--file is required")
+	}
+
+	f, err := os.Open(backupFile)
+	if err != nil {
+		logger.Fatalw("unable to open backup file", "file", backupFile, "error", err)
+	}
+
+	defer f.Close()
+
+	trailer, err := backup.Verify(f)
+	if err != nil {
+		logger.Fatalw("invalid backup file", "file", backupFile, "error", err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		logger.Fatalw("unable to read backup file", "file", backupFile, "error", err)
+	}
+
+	progressFile := backupFile + ".progress"
+
+	var skip int64
+
+	if backupResume {
+		progress, err := readBackupProgress(progressFile)
+		if err != nil {
+			logger.Fatalw("unable to read import progress", "file", progressFile, "error", err)
+		}
+
+		if progress.SHA256 == trailer.SHA256 {
+			skip = progress.Imported
+
+			logger.Infow("resuming import", "relationships_imported", skip)
+		}
+	}
+
+	client := newBackupClient(cfg)
+
+	imported, err := backup.Import(ctx, client, f, backup.ImportOptions{
+		Namespace:     backupNamespace,
+		ResourceTypes: backupResourceTypes,
+		BatchSize:     backupBatchSize,
+		Skip:          skip,
+		Progress: func(imported int64) error {
+			return writeBackupProgress(progressFile, backupProgress{SHA256: trailer.SHA256, Imported: imported})
+		},
+	})
+	if err != nil {
+		logger.Fatalw("unable to import relationships, rerun the import to resume it", "relationships_imported", imported, "error", err)
+	}
+
+	if err := os.Remove(progressFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warnw("unable to remove import progress", "file", progressFile, "error", err)
+	}
+
+	logger.Infow("relationships imported", "file", backupFile, "relationships", imported)
+}
+
+func loadBackupPolicy(cfg *config.AppConfig) iapl.Policy {
+	var (
+		err    error
+		policy iapl.Policy
+	)
+
+	if cfg.SpiceDB.PolicyDir != "" {
+		policy, err = iapl.NewPolicyFromDirectory(cfg.SpiceDB.PolicyDir)
+		if err != nil {
+			logger.Fatalw("unable to load new policy from schema directory", "policy_dir", cfg.SpiceDB.PolicyDir, "error", err)
+		}
+	} else {
+		logger.Warn("no spicedb policy defined, using default policy")
+
+		policy = iapl.DefaultPolicy()
+	}
+
+	if err = policy.Validate(); err != nil {
+		logger.Fatalw("invalid spicedb policy", "error", err)
+	}
+
+	return policy
+}
+
+func newBackupClient(cfg *config.AppConfig) *authzed.Client {
+	if err := otelx.InitTracer(cfg.Tracing, appName, logger); err != nil {
+		logger.Fatalw("unable to initialize tracing system", "error", err)
+	}
+
+	client, err := spicedbx.NewClient(cfg.SpiceDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize spicedb client", "error", err)
+	}
+
+	return client
+}
+
+func readBackupProgress(file string) (backupProgress, error) {
+	var progress backupProgress
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, nil
+	}
+
+	if err != nil {
+		return progress, err
+	}
+
+	err = json.Unmarshal(data, &progress)
+
+	return progress, err
+}
+
+// writeBackupProgress replaces the progress file, so it is never partially written.
+func writeBackupProgress(file string, progress backupProgress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+
+	tmpFile := file + ".tmp"
+
+	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, file)
+}
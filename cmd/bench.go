@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.infratographer.com/x/crdbx"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/bench"
+	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/encryption"
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+	"go.infratographer.com/permissions-api/pkg/client"
+)
+
+const (
+	benchWorkloadCheck            = "check"
+	benchWorkloadCheckAll         = "check-all"
+	benchWorkloadListRoles        = "list-roles"
+	benchWorkloadListRoleBindings = "list-role-bindings"
+)
+
+// benchWorkloadNames are the names of all workloads, in the order they are reported.
+var benchWorkloadNames = []string{
+	benchWorkloadCheck,
+	benchWorkloadCheckAll,
+	benchWorkloadListRoles,
+	benchWorkloadListRoleBindings,
+}
+
+var (
+	benchCmd = &cobra.Command{
+		Use:   "bench",
+		Short: "generate synthetic tenants, roles and role-bindings and benchmark a permissions-api server with them",
+		Long: `Generate a synthetic tenant hierarchy with roles and role-bindings directly in
+SpiceDB and the database, using the server configuration, then send a mix of
+check and list requests to the target server and report their latency
+percentiles.
+
+Requests are authenticated with --token, the subject of the token must be given
+with --subject so it can be bound to roles on some of the generated tenants,
+checks are allowed on those tenants and denied on the others.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			runBench(cmd.Context(), globalCfg)
+		},
+	}
+
+	benchTarget      string
+	benchToken       string
+	benchSubject     string
+	benchTenants     int
+	benchRoles       int
+	benchBindings    int
+	benchAllowRatio  float64
+	benchMix         string
+	benchConcurrency int
+	benchDuration    time.Duration
+	benchRequests    int
+)
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	flags := benchCmd.Flags()
+	flags.StringVar(&benchTarget, "target", "http://localhost:7602", "URL of the permissions-api server to benchmark")
+	flags.StringVar(&benchToken, "token", "", "bearer token to authenticate requests with")
+	flags.StringVar(&benchSubject, "subject", "", "ID of the subject of the bearer token")
+	flags.IntVar(&benchTenants, "tenants", 100, "number of tenants to generate below the root tenant")
+	flags.IntVar(&benchRoles, "roles", 5, "number of roles to generate on the root tenant")
+	flags.IntVar(&benchBindings, "bindings", 2, "number of role-bindings of synthetic users to generate per tenant")
+	flags.Float64Var(&benchAllowRatio, "allow-ratio", 0.5, "share of tenants the subject is bound to a role on")
+	flags.StringVar(&benchMix, "mix", "check=8,check-all=1,list-roles=1,list-role-bindings=1", "weights of the workloads, as name=weight pairs")
+	flags.IntVar(&benchConcurrency, "concurrency", 10, "number of requests in flight at once")
+	flags.DurationVar(&benchDuration, "duration", 30*time.Second, "how long to send requests for")
+	flags.IntVar(&benchRequests, "requests", 0, "stop after this many requests, 0 for no limit")
+}
+
+// benchData is the generated data the workloads send requests for.
+type benchData struct {
+	root    types.Resource
+	tenants []types.Resource
+	// allowed are the tenants the subject is bound to a role on.
+	allowed []types.Resource
+	actions []string
+}
+
+func (d *benchData) tenant(rnd *rand.Rand) gidx.PrefixedID {
+	return d.tenants[rnd.IntN(len(d.tenants))].ID
+}
+
+func (d *benchData) allowedTenant(rnd *rand.Rand) gidx.PrefixedID {
+	return d.allowed[rnd.IntN(len(d.allowed))].ID
+}
+
+func (d *benchData) action(rnd *rand.Rand) string {
+	return d.actions[rnd.IntN(len(d.actions))]
+}
+
+func runBench(ctx context.Context, cfg *config.AppConfig) {
+	if benchSubject == "" {
+		logger.Fatal("--subject is required")
+	}
+
+	if benchTenants < 1 || benchRoles < 1 {
+		logger.Fatal("at least one tenant and one role are required")
+	}
+
+	mix, err := bench.ParseMix(benchMix)
+	if err != nil {
+		logger.Fatalw("invalid workload mix", "mix", benchMix, "error", err)
+	}
+
+	for name := range mix {
+		if !slices.Contains(benchWorkloadNames, name) {
+			logger.Fatalw("unknown workload", "workload", name, "workloads", benchWorkloadNames)
+		}
+	}
+
+	spiceClient, err := spicedbx.NewClient(cfg.SpiceDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize spicedb client", "error", err)
+	}
+
+	db, err := crdbx.NewDB(cfg.CRDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize permissions-api database", "error", err)
+	}
+
+	encryptor, err := encryption.NewEncryptorFromConfig(cfg.Encryption)
+	if err != nil {
+		logger.Fatalw("unable to initialize encryption", "error", err)
+	}
+
+	store := storage.New(db, storage.WithLogger(logger), storage.WithEncryptor(encryptor))
+
+	var policy iapl.Policy
+
+	if cfg.SpiceDB.PolicyDir != "" {
+		policy, err = iapl.NewPolicyFromDirectory(cfg.SpiceDB.PolicyDir)
+		if err != nil {
+			logger.Fatalw("unable to load new policy from schema directory", "policy_dir", cfg.SpiceDB.PolicyDir, "error", err)
+		}
+	} else {
+		logger.Warn("no spicedb policy defined, using default policy")
+
+		policy = iapl.DefaultPolicy()
+	}
+
+	if err = policy.Validate(); err != nil {
+		logger.Fatalw("invalid spicedb policy", "error", err)
+	}
+
+	engine, err := query.NewEngine("infratographer", spiceClient, store, query.WithPolicy(policy), query.WithLogger(logger), query.WithoutDelegation())
+	if err != nil {
+		logger.Fatalw("error creating engine", "error", err)
+	}
+
+	subjectID, err := gidx.Parse(benchSubject)
+	if err != nil {
+		logger.Fatalw("invalid subject", "subject", benchSubject, "error", err)
+	}
+
+	subject, err := engine.NewResourceFromID(subjectID)
+	if err != nil {
+		logger.Fatalw("invalid subject", "subject", benchSubject, "error", err)
+	}
+
+	start := time.Now()
+
+	data := generateBenchData(ctx, engine, policy, subject)
+
+	logger.Infow("generated benchmark data",
+		"root_tenant", data.root.ID,
+		"tenants", len(data.tenants),
+		"allowed_tenants", len(data.allowed),
+		"duration", time.Since(start),
+	)
+
+	c, err := client.New(benchTarget, client.WithToken(benchToken), client.WithRetries(0, 0, 0))
+	if err != nil {
+		logger.Fatalw("unable to create client", "error", err)
+	}
+
+	workloads := benchWorkloads(c, data)
+
+	for i := range workloads {
+		workloads[i].Weight = mix[workloads[i].Name]
+	}
+
+	results, err := bench.Run(ctx, bench.Options{
+		Concurrency: benchConcurrency,
+		Duration:    benchDuration,
+		Requests:    benchRequests,
+	}, workloads)
+	if err != nil {
+		logger.Fatalw("unable to run benchmark", "error", err)
+	}
+
+	printBenchResults(results)
+}
+
+// generateBenchData creates the root tenant with the roles and the tenants
+// below it, binding the subject to the first role on a share of the tenants
+// and synthetic users to random roles on every tenant.
+func generateBenchData(ctx context.Context, engine query.Engine, policy iapl.Policy, subject types.Resource) *benchData {
+	rbac := policy.RBAC()
+	if rbac == nil || len(rbac.RoleOwners) == 0 {
+		logger.Fatal("the policy must support RBAC v2 to generate benchmark data")
+	}
+
+	ownerType := engine.GetResourceType(rbac.RoleOwners[0])
+	if ownerType == nil {
+		logger.Fatalw("policy has no role owner type", "type", rbac.RoleOwners[0])
+	}
+
+	var parentRelation string
+
+	for _, rel := range ownerType.Relationships {
+		for _, target := range rel.Types {
+			if target.Name == ownerType.Name {
+				parentRelation = rel.Relation
+			}
+		}
+	}
+
+	if parentRelation == "" {
+		logger.Fatalw("role owner type has no parent relation", "type", ownerType.Name)
+	}
+
+	newResource := func(prefix string) types.Resource {
+		resource, err := engine.NewResourceFromID(gidx.MustNewID(prefix))
+		if err != nil {
+			logger.Fatalw("error creating resource", "prefix", prefix, "error", err)
+		}
+
+		return resource
+	}
+
+	data := &benchData{
+		root:    newResource(ownerType.IDPrefix),
+		actions: engine.AllActions(),
+	}
+
+	if len(data.actions) == 0 {
+		logger.Fatal("the policy defines no actions roles can grant")
+	}
+
+	// the roles grant growing subsets of the actions, the first role grants them all.
+	roles := make([]types.Resource, benchRoles)
+
+	for i := range roles {
+		actions := data.actions[:len(data.actions)-i*len(data.actions)/benchRoles]
+
+		role, err := engine.CreateRoleV2(ctx, subject, data.root, fmt.Sprintf("bench-%d", i), actions)
+		if err != nil {
+			logger.Fatalw("error creating role", "error", err)
+		}
+
+		if roles[i], err = engine.NewResourceFromID(role.ID); err != nil {
+			logger.Fatalw("error creating role resource", "error", err)
+		}
+	}
+
+	rnd := rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0))
+
+	users := make([]types.Resource, max(benchBindings, 1)*4)
+
+	for i := range users {
+		users[i] = newResource(subject.ID.Prefix())
+	}
+
+	allowed := int(float64(benchTenants) * benchAllowRatio)
+
+	for i := 0; i < benchTenants; i++ {
+		tenant := newResource(ownerType.IDPrefix)
+
+		err := engine.CreateRelationships(ctx, []types.Relationship{{
+			Resource: tenant,
+			Relation: parentRelation,
+			Subject:  data.root,
+		}})
+		if err != nil {
+			logger.Fatalw("error creating tenant relationship", "error", err)
+		}
+
+		var requests []types.RoleBindingRequest
+
+		if i < allowed {
+			requests = append(requests, types.RoleBindingRequest{
+				Role:     roles[0],
+				Subjects: []types.RoleBindingSubject{{SubjectResource: subject}},
+			})
+
+			data.allowed = append(data.allowed, tenant)
+		}
+
+		for j := 0; j < benchBindings; j++ {
+			requests = append(requests, types.RoleBindingRequest{
+				Role:     roles[rnd.IntN(len(roles))],
+				Subjects: []types.RoleBindingSubject{{SubjectResource: users[rnd.IntN(len(users))]}},
+			})
+		}
+
+		for _, result := range engine.CreateRoleBindings(ctx, subject, tenant, requests) {
+			if result.Err != nil {
+				logger.Fatalw("error creating role-binding", "tenant", tenant.ID, "error", result.Err)
+			}
+		}
+
+		data.tenants = append(data.tenants, tenant)
+	}
+
+	return data
+}
+
+// benchWorkloads returns the workloads, list requests are only sent for the
+// tenants the subject is bound on, as they would be denied on the others.
+func benchWorkloads(c *client.Client, data *benchData) []bench.Workload {
+	workloads := []bench.Workload{
+		{
+			Name: benchWorkloadCheck,
+			Fn: func(ctx context.Context, rnd *rand.Rand) error {
+				_, err := c.Check(ctx, data.tenant(rnd), data.action(rnd))
+
+				return err
+			},
+		},
+		{
+			Name: benchWorkloadCheckAll,
+			Fn: func(ctx context.Context, rnd *rand.Rand) error {
+				requests := make([]client.AccessRequest, 3)
+
+				for i := range requests {
+					requests[i] = client.AccessRequest{ResourceID: data.tenant(rnd), Action: data.action(rnd)}
+				}
+
+				_, err := c.CheckAll(ctx, requests)
+
+				return err
+			},
+		},
+	}
+
+	if len(data.allowed) == 0 {
+		return workloads
+	}
+
+	return append(workloads,
+		bench.Workload{
+			Name: benchWorkloadListRoles,
+			Fn: func(ctx context.Context, rnd *rand.Rand) error {
+				_, err := c.ListRoles(ctx, data.allowedTenant(rnd))
+
+				return err
+			},
+		},
+		bench.Workload{
+			Name: benchWorkloadListRoleBindings,
+			Fn: func(ctx context.Context, rnd *rand.Rand) error {
+				_, err := c.ListRoleBindings(ctx, data.allowedTenant(rnd))
+
+				return err
+			},
+		},
+	)
+}
+
+func printBenchResults(results []bench.Result) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(w, "workload\trequests\terrors\treq/s\tmean\tp50\tp90\tp99\tmax\t")
+
+	round := func(d time.Duration) time.Duration {
+		return d.Round(10 * time.Microsecond)
+	}
+
+	for _, r := range results {
+		if r.Requests == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t%s\t\n",
+			r.Name, r.Requests, r.Errors, r.Throughput,
+			round(r.Mean), round(r.P50), round(r.P90), round(r.P99), round(r.Max))
+	}
+
+	w.Flush()
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.infratographer.com/x/crdbx"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/encryption"
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/storage"
+)
+
+var (
+	checkCmd = &cobra.Command{
+		Use:   "check <subject> <action> <resource>",
+		Short: "check whether a subject may perform an action on a resource",
+		Long: `Check whether a subject may perform an action on a resource, directly against
+SpiceDB using the server configuration. The resource may be a resource alias.
+The command exits with status 1 if the action is denied.`,
+		Args: cobra.ExactArgs(3),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkPermission(cmd.Context(), globalCfg, args[0], args[1], args[2], checkExplain)
+		},
+	}
+
+	checkExplain bool
+)
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().BoolVar(&checkExplain, "explain", false, "print the relations granting the action")
+}
+
+func checkPermission(ctx context.Context, cfg *config.AppConfig, subjectIDStr, action, resourceIDStr string, explain bool) {
+	spiceClient, err := spicedbx.NewClient(cfg.SpiceDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize spicedb client", "error", err)
+	}
+
+	db, err := crdbx.NewDB(cfg.CRDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize permissions-api database", "error", err)
+	}
+
+	encryptor, err := encryption.NewEncryptorFromConfig(cfg.Encryption)
+	if err != nil {
+		logger.Fatalw("unable to initialize encryption", "error", err)
+	}
+
+	store := storage.New(db, storage.WithLogger(logger), storage.WithEncryptor(encryptor))
+
+	var policy iapl.Policy
+
+	if cfg.SpiceDB.PolicyDir != "" {
+		policy, err = iapl.NewPolicyFromDirectory(cfg.SpiceDB.PolicyDir)
+		if err != nil {
+			logger.Fatalw("unable to load new policy from schema directory", "policy_dir", cfg.SpiceDB.PolicyDir, "error", err)
+		}
+	} else {
+		logger.Warn("no spicedb policy defined, using default policy")
+
+		policy = iapl.DefaultPolicy()
+	}
+
+	if err = policy.Validate(); err != nil {
+		logger.Fatalw("invalid spicedb policy", "error", err)
+	}
+
+	engine, err := query.NewEngine("infratographer", spiceClient, store, query.WithPolicy(policy), query.WithLogger(logger))
+	if err != nil {
+		logger.Fatalw("error creating engine", "error", err)
+	}
+
+	subjectID, err := gidx.Parse(subjectIDStr)
+	if err != nil {
+		logger.Fatalw("error parsing subject ID", "error", err)
+	}
+
+	subject, err := engine.NewResourceFromID(subjectID)
+	if err != nil {
+		logger.Fatalw("error creating subject resource", "error", err)
+	}
+
+	resource, err := engine.ResolveResource(ctx, resourceIDStr)
+	if err != nil {
+		logger.Fatalw("error resolving resource", "error", err)
+	}
+
+	err = engine.SubjectHasPermission(ctx, subject, action, resource)
+
+	switch {
+	case err == nil:
+		fmt.Printf("allowed: %s may %s on %s\n", subject.ID, action, resource.ID)
+	case errors.Is(err, query.ErrActionNotAssigned):
+		fmt.Printf("denied: %s may not %s on %s\n", subject.ID, action, resource.ID)
+	default:
+		logger.Fatalw("error checking permission", "error", err)
+	}
+
+	allowed := err == nil
+
+	if explain {
+		path, err := engine.ExplainPermission(ctx, subject, action, resource)
+
+		switch {
+		case err == nil:
+			fmt.Println("granted by:")
+
+			for _, step := range path {
+				if step.Relation == "" {
+					fmt.Printf("  %s %s\n", step.Type, step.ID)
+				} else {
+					fmt.Printf("  %s %s #%s\n", step.Type, step.ID, step.Relation)
+				}
+			}
+		case errors.Is(err, query.ErrActionNotAssigned):
+			fmt.Println("no relations grant the action")
+		default:
+			logger.Fatalw("error explaining permission", "error", err)
+		}
+	}
+
+	if !allowed {
+		os.Exit(1)
+	}
+}
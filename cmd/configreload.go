@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/loggingx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"go.infratographer.com/permissions-api/internal/api"
+	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/storage"
+)
+
+// logLevel is the level of the logger, which is changed when the
+// configuration is reloaded.
+var logLevel = zap.NewAtomicLevel()
+
+// levelFor returns the log level of the logging config.
+func levelFor(cfg loggingx.Config) zapcore.Level {
+	if cfg.Debug {
+		return zapcore.DebugLevel
+	}
+
+	return zapcore.InfoLevel
+}
+
+// levelCore filters the entries of a core by a level which can be changed
+// while the logger is in use.
+type levelCore struct {
+	zapcore.Core
+
+	level zap.AtomicLevel
+}
+
+func (c levelCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level) && c.Core.Enabled(level)
+}
+
+func (c levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(entry.Level) {
+		return checked
+	}
+
+	return c.Core.Check(entry, checked)
+}
+
+// reloadableSettings are the components whose operational settings are
+// changed when the configuration is reloaded. Other settings, like endpoints
+// or enabling features, require a restart.
+type reloadableSettings struct {
+	router    *api.Router
+	engines   []*query.ReloadableEngine
+	store     storage.Storage
+	slowCalls *spicedbx.SlowCallLog
+	breaker   *spicedbx.Breaker
+}
+
+// apply changes the operational settings to the ones of the config.
+func (s reloadableSettings) apply(cfg *config.AppConfig) {
+	logLevel.SetLevel(levelFor(cfg.Logging))
+
+	s.router.SetRateLimit(cfg.RateLimit)
+
+	for _, engine := range s.engines {
+		engine.SetCheckCacheTTL(cfg.CheckCache.TTL)
+		engine.SetDegradedCacheTTL(cfg.Degraded.CacheTTL)
+	}
+
+	s.store.SetSlowQueryThreshold(cfg.Storage.SlowQueryThreshold)
+	s.slowCalls.SetThreshold(cfg.SpiceDB.SlowCallThreshold)
+
+	if s.breaker != nil {
+		s.breaker.SetConfig(cfg.SpiceDB.Breaker)
+	}
+}
+
+// watchConfig reloads the configuration file on SIGHUP and, if interval is
+// set, whenever the file changes, applying the operational settings to the
+// running server. A file which fails to load is refused, the error is logged
+// and the current settings are kept.
+func watchConfig(ctx context.Context, settings reloadableSettings, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	file := viper.ConfigFileUsed()
+	current := configFileHash(file)
+
+	reload := func(force bool) {
+		hash := configFileHash(file)
+		if !force && bytes.Equal(hash, current) {
+			return
+		}
+
+		current = hash
+
+		if err := viper.ReadInConfig(); err != nil {
+			logger.Errorw("unable to read config, keeping the current settings", "file", file, "error", err)
+
+			return
+		}
+
+		var cfg config.AppConfig
+
+		if err := viper.Unmarshal(&cfg); err != nil {
+			logger.Errorw("invalid config, keeping the current settings", "file", file, "error", err)
+
+			return
+		}
+
+		settings.apply(&cfg)
+
+		logger.Infow("config reloaded", "file", file)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			reload(true)
+		case <-tick:
+			reload(false)
+		}
+	}
+}
+
+// configFileHash returns the hash of the content of the config file, nil if
+// it cannot be read.
+func configFileHash(file string) []byte {
+	if file == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+
+	hash := sha256.Sum256(content)
+
+	return hash[:]
+}
@@ -10,6 +10,7 @@ import (
 	"go.infratographer.com/x/viperx"
 
 	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/encryption"
 	"go.infratographer.com/permissions-api/internal/iapl"
 	"go.infratographer.com/permissions-api/internal/query"
 	"go.infratographer.com/permissions-api/internal/spicedbx"
@@ -69,7 +70,12 @@ func createRole(ctx context.Context, cfg *config.AppConfig) {
 		logger.Fatalw("unable to initialize permissions-api database", "error", err)
 	}
 
-	store := storage.New(db, storage.WithLogger(logger))
+	encryptor, err := encryption.NewEncryptorFromConfig(cfg.Encryption)
+	if err != nil {
+		logger.Fatalw("unable to initialize encryption", "error", err)
+	}
+
+	store := storage.New(db, storage.WithLogger(logger), storage.WithEncryptor(encryptor))
 
 	var policy iapl.Policy
 
@@ -98,7 +104,7 @@ func createRole(ctx context.Context, cfg *config.AppConfig) {
 		logger.Fatalw("error parsing subject ID", "error", err)
 	}
 
-	engine, err := query.NewEngine("infratographer", spiceClient, store, query.WithPolicy(policy), query.WithLogger(logger))
+	engine, err := query.NewEngine("infratographer", spiceClient, store, query.WithPolicy(policy), query.WithLogger(logger), query.WithoutDelegation())
 	if err != nil {
 		logger.Fatalw("error creating engine", "error", err)
 	}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/otelx"
+
+	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/pubsub"
+)
+
+var (
+	dlqCmd = &cobra.Command{
+		Use:   "dlq",
+		Short: "inspect and replay relationship events which failed to be processed",
+	}
+
+	dlqInspectCmd = &cobra.Command{
+		Use:   "inspect",
+		Short: "print the events in the dead-letter topic, keeping them in the topic",
+		Run: func(cmd *cobra.Command, _ []string) {
+			inspectDeadLetters(cmd.Context(), globalCfg)
+		},
+	}
+
+	dlqReplayCmd = &cobra.Command{
+		Use:   "replay",
+		Short: "send the events in the dead-letter topic to the worker again, removing them from the topic",
+		Run: func(cmd *cobra.Command, _ []string) {
+			replayDeadLetters(cmd.Context(), globalCfg)
+		},
+	}
+
+	dlqTopic string
+	dlqWait  time.Duration
+)
+
+// dlqOutput is a dead-lettered event as printed by the dlq commands.
+type dlqOutput struct {
+	ID string `json:"id"`
+	pubsub.DeadLetter
+	// ReplayFailed is set if the replayed event failed again, it is then
+	// dead-lettered again by the worker with the new errors.
+	ReplayFailed bool `json:"replayFailed,omitempty"`
+}
+
+func init() {
+	rootCmd.AddCommand(dlqCmd)
+	dlqCmd.AddCommand(dlqInspectCmd, dlqReplayCmd)
+
+	flags := dlqCmd.PersistentFlags()
+	flags.StringVar(&dlqTopic, "topic", pubsub.DefaultDeadLetterTopic, "dead-letter topic failed events are published to")
+	flags.DurationVar(&dlqWait, "wait", 5*time.Second, "stop once no event has been received for this long")
+
+	otelx.MustViperFlags(viper.GetViper(), flags)
+	events.MustViperFlags(viper.GetViper(), flags, appName)
+}
+
+// readDeadLetters calls fn for every dead-lettered event until no event is
+// received within the wait time.
+func readDeadLetters(ctx context.Context, cfg *config.AppConfig, fn func(events.Connection, events.Message[events.EventMessage])) {
+	if err := otelx.InitTracer(cfg.Tracing, appName, logger); err != nil {
+		logger.Fatalw("unable to initialize tracing system", "error", err)
+	}
+
+	conn, err := events.NewConnection(cfg.Events.Config, events.WithLogger(logger))
+	if err != nil {
+		logger.Fatalw("failed to initialize events", "error", err)
+	}
+
+	defer func() {
+		if err := conn.Shutdown(context.Background()); err != nil {
+			logger.Errorw("failed to shutdown events gracefully", "error", err)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	messages, err := conn.SubscribeEvents(ctx, pubsub.DeadLetterEventType+"."+dlqTopic)
+	if err != nil {
+		logger.Fatalw("failed to subscribe to dead-letter topic", "topic", dlqTopic, "error", err)
+	}
+
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			fn(conn, msg)
+		case <-time.After(dlqWait):
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func decodeDeadLetter(msg events.Message[events.EventMessage]) (dlqOutput, bool) {
+	if err := msg.Error(); err != nil {
+		logger.Errorw("failed to decode dead-letter event", "id", msg.ID(), "error", err)
+
+		return dlqOutput{}, false
+	}
+
+	dl, err := pubsub.DeadLetterFromEvent(msg.Message())
+	if err != nil {
+		logger.Errorw("failed to decode dead-letter event", "id", msg.ID(), "error", err)
+
+		return dlqOutput{}, false
+	}
+
+	return dlqOutput{ID: msg.ID(), DeadLetter: dl}, true
+}
+
+func inspectDeadLetters(ctx context.Context, cfg *config.AppConfig) {
+	var seen []events.Message[events.EventMessage]
+
+	enc := json.NewEncoder(os.Stdout)
+
+	readDeadLetters(ctx, cfg, func(_ events.Connection, msg events.Message[events.EventMessage]) {
+		seen = append(seen, msg)
+
+		if out, ok := decodeDeadLetter(msg); ok {
+			if err := enc.Encode(out); err != nil {
+				logger.Fatalw("failed to print dead-letter", "error", err)
+			}
+		}
+	})
+
+	// return the inspected events to the topic
+	for _, msg := range seen {
+		if err := msg.Nak(0); err != nil {
+			logger.Warnw("failed to return dead-letter to topic", "id", msg.ID(), "error", err)
+		}
+	}
+}
+
+func replayDeadLetters(ctx context.Context, cfg *config.AppConfig) {
+	enc := json.NewEncoder(os.Stdout)
+
+	readDeadLetters(ctx, cfg, func(conn events.Connection, msg events.Message[events.EventMessage]) {
+		out, ok := decodeDeadLetter(msg)
+		if !ok {
+			return
+		}
+
+		// the replayed request continues the trace of the original request
+		resp, err := conn.PublishAuthRelationshipRequest(out.Request.GetTraceContext(ctx), out.Topic, out.Request)
+		if err != nil {
+			// keep the event in the topic, the worker may not be running
+			logger.Errorw("failed to replay dead-letter", "id", out.ID, "error", err)
+
+			if err := msg.Nak(dlqWait); err != nil {
+				logger.Warnw("failed to return dead-letter to topic", "id", out.ID, "error", err)
+			}
+
+			return
+		}
+
+		out.ReplayFailed = resp.Error() != nil || len(resp.Message().Errors) != 0
+
+		if err := msg.Ack(); err != nil {
+			logger.Errorw("failed to remove dead-letter from topic", "id", out.ID, "error", err)
+		}
+
+		if err := enc.Encode(out); err != nil {
+			logger.Fatalw("failed to print dead-letter", "error", err)
+		}
+	})
+}
@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.infratographer.com/x/crdbx"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/encryption"
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+var (
+	migrateRolesCmd = &cobra.Command{
+		Use:   "migrate-roles",
+		Short: "replace v1 roles and their assignments with v2 roles and role-bindings",
+		Long: `Replace the v1 roles of every resource with v2 roles of the same name and
+actions owned by the resource, binding each v2 role on the resource to the
+subjects the v1 role was assigned to.
+
+Resources are migrated in batches and the last migrated resource is recorded in
+the progress file, an interrupted migration is resumed when it is run again.
+Use --dry-run to report the roles which would be migrated without changing them.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			migrateRoles(cmd.Context(), globalCfg)
+		},
+	}
+
+	migrateRolesActor        string
+	migrateRolesResources    []string
+	migrateRolesDryRun       bool
+	migrateRolesBatchSize    int
+	migrateRolesProgressFile string
+	migrateRolesResume       bool
+)
+
+// migrateRolesProgress records the last resource migrated, so an interrupted migration can be resumed.
+type migrateRolesProgress struct {
+	LastResourceID gidx.PrefixedID `json:"last_resource_id"`
+}
+
+func init() {
+	rootCmd.AddCommand(migrateRolesCmd)
+
+	flags := migrateRolesCmd.Flags()
+	flags.StringVar(&migrateRolesActor, "actor", "", "ID of the subject recorded as the creator of the v2 roles and role-bindings")
+	flags.StringSliceVar(&migrateRolesResources, "resources", nil, "only migrate the roles of the given resource IDs")
+	flags.BoolVar(&migrateRolesDryRun, "dry-run", false, "report the roles which would be migrated without changing them")
+	flags.IntVar(&migrateRolesBatchSize, "batch-size", 100, "number of resources listed and migrated at once")
+	flags.StringVar(&migrateRolesProgressFile, "progress-file", "migrate-roles.progress", "file recording the progress of the migration")
+	flags.BoolVar(&migrateRolesResume, "resume", true, "resume an interrupted migration from the progress file")
+}
+
+func migrateRoles(ctx context.Context, cfg *config.AppConfig) {
+	if migrateRolesActor == "" {
+		logger.Fatal("--actor is required")
+	}
+
+	if migrateRolesBatchSize < 1 {
+		logger.Fatal("--batch-size must be at least 1")
+	}
+
+	spiceClient, err := spicedbx.NewClient(cfg.SpiceDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize spicedb client", "error", err)
+	}
+
+	db, err := crdbx.NewDB(cfg.CRDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize permissions-api database", "error", err)
+	}
+
+	encryptor, err := encryption.NewEncryptorFromConfig(cfg.Encryption)
+	if err != nil {
+		logger.Fatalw("unable to initialize encryption", "error", err)
+	}
+
+	store := storage.New(db, storage.WithLogger(logger), storage.WithEncryptor(encryptor))
+
+	var policy iapl.Policy
+
+	if cfg.SpiceDB.PolicyDir != "" {
+		policy, err = iapl.NewPolicyFromDirectory(cfg.SpiceDB.PolicyDir)
+		if err != nil {
+			logger.Fatalw("unable to load new policy from schema directory", "policy_dir", cfg.SpiceDB.PolicyDir, "error", err)
+		}
+	} else {
+		logger.Warn("no spicedb policy defined, using default policy")
+
+		policy = iapl.DefaultPolicy()
+	}
+
+	if err = policy.Validate(); err != nil {
+		logger.Fatalw("invalid spicedb policy", "error", err)
+	}
+
+	engine, err := query.NewEngine("infratographer", spiceClient, store, query.WithPolicy(policy), query.WithLogger(logger))
+	if err != nil {
+		logger.Fatalw("error creating engine", "error", err)
+	}
+
+	actor, err := migrateRolesResource(engine, migrateRolesActor)
+	if err != nil {
+		logger.Fatalw("invalid actor", "actor", migrateRolesActor, "error", err)
+	}
+
+	m := &roleMigrator{ctx: ctx, engine: engine, actor: actor}
+
+	if len(migrateRolesResources) != 0 {
+		for _, id := range migrateRolesResources {
+			resource, err := migrateRolesResource(engine, id)
+			if err != nil {
+				logger.Fatalw("invalid resource", "resource", id, "error", err)
+			}
+
+			m.migrate(resource)
+		}
+
+		m.finish()
+
+		return
+	}
+
+	var after gidx.PrefixedID
+
+	// dry runs change nothing, so they always start from the beginning and
+	// record no progress.
+	if migrateRolesResume && !migrateRolesDryRun {
+		progress, err := readMigrateRolesProgress(migrateRolesProgressFile)
+		if err != nil {
+			logger.Fatalw("unable to read migration progress", "file", migrateRolesProgressFile, "error", err)
+		}
+
+		after = progress.LastResourceID
+
+		if after != "" {
+			logger.Infow("resuming migration", "last_resource_id", after)
+		}
+	}
+
+	for {
+		resources, err := engine.ListRoleV1Resources(ctx, after, migrateRolesBatchSize)
+		if err != nil {
+			logger.Fatalw("unable to list resources with v1 roles", "error", err)
+		}
+
+		if len(resources) == 0 {
+			break
+		}
+
+		for _, resource := range resources {
+			m.migrate(resource)
+		}
+
+		after = resources[len(resources)-1].ID
+
+		if migrateRolesDryRun {
+			continue
+		}
+
+		if err := writeMigrateRolesProgress(migrateRolesProgressFile, migrateRolesProgress{LastResourceID: after}); err != nil {
+			logger.Fatalw("unable to write migration progress", "file", migrateRolesProgressFile, "error", err)
+		}
+	}
+
+	// roles which failed to migrate are still v1 roles, so a new migration
+	// started from the beginning only revisits their resources.
+	if !migrateRolesDryRun {
+		if err := os.Remove(migrateRolesProgressFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Warnw("unable to remove migration progress", "file", migrateRolesProgressFile, "error", err)
+		}
+	}
+
+	m.finish()
+}
+
+// roleMigrator migrates the roles of resources, printing a report line for every role.
+type roleMigrator struct {
+	ctx    context.Context
+	engine query.Engine
+	actor  types.Resource
+
+	resources int
+	migrated  int
+	failed    int
+}
+
+func (m *roleMigrator) migrate(resource types.Resource) {
+	m.resources++
+
+	results, err := m.engine.MigrateRolesV1(m.ctx, m.actor, resource, migrateRolesDryRun)
+	if err != nil {
+		m.failed++
+
+		fmt.Printf("%s  error listing roles: %s\n", resource.ID, err)
+
+		return
+	}
+
+	for _, result := range results {
+		subjectIDs := make([]string, len(result.Subjects))
+
+		for i, subject := range result.Subjects {
+			subjectIDs[i] = subject.ID.String()
+		}
+
+		if result.Err != nil {
+			m.failed++
+
+			fmt.Printf("%s  %s  FAILED: %s\n", resource.ID, result.V1Role.Name, result.Err)
+
+			continue
+		}
+
+		m.migrated++
+
+		if migrateRolesDryRun {
+			fmt.Printf("%s  %s  actions=%s subjects=%s\n", resource.ID, result.V1Role.Name,
+				strings.Join(result.V1Role.Actions, ","), strings.Join(subjectIDs, ","))
+
+			continue
+		}
+
+		fmt.Printf("%s  %s  %s -> %s role-binding=%s subjects=%s\n", resource.ID, result.V1Role.Name,
+			result.V1Role.ID, result.V2Role.ID, result.RoleBinding.ID, strings.Join(subjectIDs, ","))
+	}
+}
+
+func (m *roleMigrator) finish() {
+	verb := "migrated"
+	if migrateRolesDryRun {
+		verb = "to migrate"
+	}
+
+	fmt.Printf("\n%d resources, %d roles %s, %d failed\n", m.resources, m.migrated, verb, m.failed)
+
+	if m.failed != 0 {
+		os.Exit(1)
+	}
+}
+
+func migrateRolesResource(engine query.Engine, idStr string) (types.Resource, error) {
+	id, err := gidx.Parse(idStr)
+	if err != nil {
+		return types.Resource{}, err
+	}
+
+	return engine.NewResourceFromID(id)
+}
+
+func readMigrateRolesProgress(file string) (migrateRolesProgress, error) {
+	var progress migrateRolesProgress
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, nil
+	}
+
+	if err != nil {
+		return progress, err
+	}
+
+	err = json.Unmarshal(data, &progress)
+
+	return progress, err
+}
+
+// writeMigrateRolesProgress replaces the progress file, so it is never partially written.
+func writeMigrateRolesProgress(file string, progress migrateRolesProgress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+
+	tmpFile := file + ".tmp"
+
+	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, file)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/policysource"
+	"go.infratographer.com/permissions-api/internal/query"
+)
+
+// watchPolicy reloads the policy from the policy source on SIGHUP and, if
+// interval is set, whenever the policy of the source changes. current is the
+// policy document loaded at startup. Policies which fail to load or validate
+// are refused and the current policy is kept. onReload, if set, is called
+// after every reload.
+func watchPolicy(ctx context.Context, engine *query.ReloadableEngine, source policysource.Source, current iapl.PolicyDocument, interval time.Duration, onReload func(context.Context)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	reload := func(force bool) {
+		document, err := source.Load(ctx, force)
+		if errors.Is(err, policysource.ErrNotModified) {
+			return
+		}
+
+		if err != nil {
+			logger.Errorw("unable to load policy, keeping the current policy", "policy_source", source.String(), "error", err)
+
+			return
+		}
+
+		if !force && reflect.DeepEqual(document, current) {
+			return
+		}
+
+		if err := engine.ReloadPolicy(iapl.NewPolicy(document)); err != nil {
+			logger.Errorw("invalid policy, keeping the current policy", "policy_source", source.String(), "error", err)
+
+			// the same invalid policy is not reported again on every tick.
+			current = document
+
+			return
+		}
+
+		current = document
+
+		logger.Infow("policy reloaded", "policy_source", source.String())
+
+		if onReload != nil {
+			onReload(ctx)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			reload(true)
+		case <-tick:
+			reload(false)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.infratographer.com/x/crdbx"
+
+	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/encryption"
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/storage"
+)
+
+var (
+	purgeTenantCmd = &cobra.Command{
+		Use:   "purge-tenant",
+		Short: "delete the authorization footprint of an offboarded tenant",
+		Long: `Delete the roles, role-bindings, groups and relationships of a tenant and of
+every resource descending from it, such as its child tenants and their resources.
+
+Resources are deleted in batches, deepest first, an interrupted purge is resumed
+when it is run again. Once deleted, anything left of the tenant's footprint is
+reported and the command fails. Use --dry-run to report the footprint without
+deleting it.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			purgeTenant(cmd.Context(), globalCfg)
+		},
+	}
+
+	purgeTenantID        string
+	purgeTenantBatchSize int
+	purgeTenantDryRun    bool
+)
+
+func init() {
+	rootCmd.AddCommand(purgeTenantCmd)
+
+	flags := purgeTenantCmd.Flags()
+	flags.StringVar(&purgeTenantID, "tenant", "", "ID of the tenant to purge")
+	flags.IntVar(&purgeTenantBatchSize, "batch-size", 100, "number of resources deleted in a pass")
+	flags.BoolVar(&purgeTenantDryRun, "dry-run", false, "report the footprint of the tenant without deleting it")
+}
+
+func purgeTenant(ctx context.Context, cfg *config.AppConfig) {
+	if purgeTenantID == "" {
+		logger.Fatal("--tenant is required")
+	}
+
+	if purgeTenantBatchSize < 1 {
+		logger.Fatal("--batch-size must be at least 1")
+	}
+
+	spiceClient, err := spicedbx.NewClient(cfg.SpiceDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize spicedb client", "error", err)
+	}
+
+	db, err := crdbx.NewDB(cfg.CRDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize permissions-api database", "error", err)
+	}
+
+	encryptor, err := encryption.NewEncryptorFromConfig(cfg.Encryption)
+	if err != nil {
+		logger.Fatalw("unable to initialize encryption", "error", err)
+	}
+
+	store := storage.New(db, storage.WithLogger(logger), storage.WithEncryptor(encryptor))
+
+	var policy iapl.Policy
+
+	if cfg.SpiceDB.PolicyDir != "" {
+		policy, err = iapl.NewPolicyFromDirectory(cfg.SpiceDB.PolicyDir)
+		if err != nil {
+			logger.Fatalw("unable to load new policy from schema directory", "policy_dir", cfg.SpiceDB.PolicyDir, "error", err)
+		}
+	} else {
+		logger.Warn("no spicedb policy defined, using default policy")
+
+		policy = iapl.DefaultPolicy()
+	}
+
+	if err = policy.Validate(); err != nil {
+		logger.Fatalw("invalid spicedb policy", "error", err)
+	}
+
+	engine, err := query.NewEngine("infratographer", spiceClient, store, query.WithPolicy(policy), query.WithLogger(logger))
+	if err != nil {
+		logger.Fatalw("error creating engine", "error", err)
+	}
+
+	tenant, err := migrateRolesResource(engine, purgeTenantID)
+	if err != nil {
+		logger.Fatalw("invalid tenant", "tenant", purgeTenantID, "error", err)
+	}
+
+	report, err := engine.PurgeTenant(ctx, tenant, purgeTenantBatchSize, purgeTenantDryRun)
+	if err != nil {
+		logger.Fatalw("error purging tenant", "tenant", tenant.ID, "purged", len(report.Resources), "error", err)
+	}
+
+	for _, resource := range report.Resources {
+		fmt.Printf("%s  %s\n", resource.ID, resource.Type)
+	}
+
+	if purgeTenantDryRun {
+		for _, item := range report.Remaining {
+			fmt.Printf("%s  %s\n", tenant.ID, item)
+		}
+
+		fmt.Printf("\n%d resources and %d items of %s to purge\n", len(report.Resources), len(report.Remaining), tenant.ID)
+
+		return
+	}
+
+	fmt.Printf("\n%d resources purged in %d passes\n", len(report.Resources), report.Passes)
+
+	if len(report.Remaining) != 0 {
+		fmt.Printf("\nverification FAILED, %d items of %s remain:\n", len(report.Remaining), tenant.ID)
+
+		for _, item := range report.Remaining {
+			fmt.Printf("%s  %s\n", tenant.ID, item)
+		}
+
+		os.Exit(1)
+	}
+
+	fmt.Printf("verified, nothing of %s remains\n", tenant.ID)
+}
@@ -13,8 +13,11 @@ import (
 	"go.infratographer.com/x/versionx"
 	"go.infratographer.com/x/viperx"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/encryption"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
 	"go.infratographer.com/permissions-api/internal/storage"
 )
 
@@ -60,6 +63,12 @@ func init() {
 
 	rootCmd.PersistentFlags().String("spicedb-endpoint", "spicedb:50051", "spicedb endpoint (host:port)")
 	viperx.MustBindFlag(viper.GetViper(), "spicedb.endpoint", rootCmd.PersistentFlags().Lookup("spicedb-endpoint"))
+	rootCmd.PersistentFlags().StringSlice("spicedb-fallback-endpoints", []string{}, "spicedb endpoints (host:port) used when the spicedb endpoint is unhealthy, in order")
+	viperx.MustBindFlag(viper.GetViper(), "spicedb.endpoints.fallbacks", rootCmd.PersistentFlags().Lookup("spicedb-fallback-endpoints"))
+	rootCmd.PersistentFlags().Bool("spicedb-balance-endpoints", false, "spread spicedb calls across all healthy endpoints instead of using the first healthy one")
+	viperx.MustBindFlag(viper.GetViper(), "spicedb.endpoints.balance", rootCmd.PersistentFlags().Lookup("spicedb-balance-endpoints"))
+	rootCmd.PersistentFlags().Duration("spicedb-probe-interval", spicedbx.DefaultProbeInterval, "interval at which the health of spicedb endpoints is probed when fallback endpoints are configured")
+	viperx.MustBindFlag(viper.GetViper(), "spicedb.endpoints.probeinterval", rootCmd.PersistentFlags().Lookup("spicedb-probe-interval"))
 	rootCmd.PersistentFlags().String("spicedb-key", "", "spicedb auth key")
 	viperx.MustBindFlag(viper.GetViper(), "spicedb.key", rootCmd.PersistentFlags().Lookup("spicedb-key"))
 	rootCmd.PersistentFlags().Bool("spicedb-insecure", false, "spicedb insecure connection")
@@ -70,6 +79,15 @@ func init() {
 	viperx.MustBindFlag(viper.GetViper(), "spicedb.prefix", rootCmd.PersistentFlags().Lookup("spicedb-prefix"))
 	rootCmd.PersistentFlags().String("spicedb-policydir", "", "spicedb policy directory")
 	viperx.MustBindFlag(viper.GetViper(), "spicedb.policyDir", rootCmd.PersistentFlags().Lookup("spicedb-policydir"))
+	rootCmd.PersistentFlags().Int("spicedb-retry-max-attempts", spicedbx.DefaultRetryConfig.MaxAttempts, "attempts of idempotent spicedb calls failing with a transient error (retries are disabled when 1 or less)")
+	viperx.MustBindFlag(viper.GetViper(), "spicedb.retry.maxattempts", rootCmd.PersistentFlags().Lookup("spicedb-retry-max-attempts"))
+	rootCmd.PersistentFlags().Duration("spicedb-retry-initial-backoff", spicedbx.DefaultRetryConfig.InitialBackoff, "time to wait before retrying a spicedb call, doubled after every attempt")
+	viperx.MustBindFlag(viper.GetViper(), "spicedb.retry.initialbackoff", rootCmd.PersistentFlags().Lookup("spicedb-retry-initial-backoff"))
+	rootCmd.PersistentFlags().Duration("spicedb-retry-max-backoff", spicedbx.DefaultRetryConfig.MaxBackoff, "maximum time to wait between attempts of a spicedb call")
+	viperx.MustBindFlag(viper.GetViper(), "spicedb.retry.maxbackoff", rootCmd.PersistentFlags().Lookup("spicedb-retry-max-backoff"))
+
+	// Encryption Flags
+	encryption.MustViperFlags(viper.GetViper(), rootCmd.PersistentFlags())
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -96,7 +114,16 @@ func initConfig() {
 		log.Fatalf("unable to process app config, error: %s", err.Error())
 	}
 
-	logger = loggingx.InitLogger(appName, settings.Logging)
+	logLevel.SetLevel(levelFor(settings.Logging))
+
+	// the logger is built at the debug level and filtered by logLevel, so the
+	// level can be changed when the configuration is reloaded.
+	logConfig := settings.Logging
+	logConfig.Debug = true
+
+	logger = loggingx.InitLogger(appName, logConfig).WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return levelCore{Core: core, level: logLevel}
+	}))
 
 	// errcheck for ReadInConfig, but we have to initialize the logger and
 	if err == nil {
@@ -3,11 +3,15 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/authzed-go/v1"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.infratographer.com/x/otelx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"go.infratographer.com/permissions-api/internal/config"
 	"go.infratographer.com/permissions-api/internal/iapl"
@@ -19,17 +23,29 @@ var (
 		Use:   "schema",
 		Short: "write the schema into SpiceDB",
 		Run: func(cmd *cobra.Command, _ []string) {
-			writeSchema(cmd.Context(), dryRun, globalCfg)
+			if verifySchema {
+				checkSchema(cmd.Context(), globalCfg)
+
+				return
+			}
+
+			writeSchema(cmd.Context(), dryRun, resourceTypes, globalCfg)
 		},
 	}
 
-	dryRun bool
+	dryRun          bool
+	resourceTypes   []string
+	verifySchema    bool
+	schemaNamespace string
 )
 
 func init() {
 	rootCmd.AddCommand(schemaCmd)
 
 	schemaCmd.Flags().BoolVar(&dryRun, "dry-run", false, "dry run: print the schema instead of applying it")
+	schemaCmd.Flags().BoolVar(&verifySchema, "verify", false, "verify the schema applied to SpiceDB matches the policy instead of applying it, exiting with an error on mismatch")
+	schemaCmd.Flags().StringVar(&schemaNamespace, "namespace", defaultNamespace, "namespace to apply or verify the schema of, other than the default namespace it must be configured in spicedb.namespaces")
+	schemaCmd.Flags().StringSliceVar(&resourceTypes, "resource-types", nil, "only apply the definitions of the given resource types, keeping the definitions of all other types in SpiceDB")
 
 	schemaCmd.Flags().Bool("mermaid", false, "outputs the policy as a mermaid chart definition")
 	schemaCmd.Flags().Bool("mermaid-markdown", false, "outputs the policy as a markdown mermaid chart definition")
@@ -43,42 +59,42 @@ func init() {
 	}
 }
 
-func writeSchema(_ context.Context, dryRun bool, cfg *config.AppConfig) {
-	var (
-		err    error
-		policy iapl.Policy
-	)
-
-	if cfg.SpiceDB.PolicyDir != "" {
-		policy, err = iapl.NewPolicyFromDirectory(cfg.SpiceDB.PolicyDir)
-		if err != nil {
-			logger.Fatalw("unable to load new policy from schema directory", "policy_dir", cfg.SpiceDB.PolicyDir, "error", err)
-		}
-	} else {
-		logger.Warn("no spicedb policy defined, using default policy")
-
-		policy = iapl.DefaultPolicy()
-	}
-
-	if err = policy.Validate(); err != nil {
-		logger.Fatalw("invalid spicedb policy", "error", err)
-	}
+func writeSchema(_ context.Context, dryRun bool, resourceTypes []string, cfg *config.AppConfig) {
+	policy := loadSchemaPolicy(cfg)
 
-	schemaStr, err := spicedbx.GenerateSchema("infratographer", policy.Schema())
+	schemaStr, err := spicedbx.GenerateSchema(schemaNamespace, policy.Schema(), policy.Caveats()...)
 	if err != nil {
 		logger.Fatalw("failed to generate schema from policy", "error", err)
 	}
 
 	if viper.GetBool("mermaid") || viper.GetBool("mermaid-markdown") {
-		if policyDir := cfg.SpiceDB.PolicyDir; policyDir != "" {
+		if policyDir := schemaPolicyDir(cfg); policyDir != "" {
 			outputPolicyMermaid(policyDir, viper.GetBool("mermaid-markdown"))
 		}
 
 		return
 	}
 
+	var segments []spicedbx.SchemaSegment
+
+	if len(resourceTypes) > 0 {
+		segments, err = spicedbx.GenerateSchemaSegments(schemaNamespace, policy.Schema(), resourceTypes)
+		if err != nil {
+			logger.Fatalw("failed to generate schema segments from policy", "resource_types", resourceTypes, "error", err)
+		}
+	}
+
 	if dryRun {
+		if segments != nil {
+			for _, segment := range segments {
+				fmt.Printf("%s\n", segment.Definition)
+			}
+
+			return
+		}
+
 		fmt.Printf("%s", schemaStr)
+
 		return
 	}
 
@@ -92,6 +108,14 @@ func writeSchema(_ context.Context, dryRun bool, cfg *config.AppConfig) {
 		logger.Fatalw("unable to initialize spicedb client", "error", err)
 	}
 
+	if segments != nil {
+		schemaStr = mergeSchemaSegments(client, segments)
+
+		logger.Infow("applying partial schema", "resource_types", resourceTypes)
+	} else {
+		schemaStr = mergeNamespaceSchema(client, schemaStr)
+	}
+
 	logger.Debugw("Writing schema to DB", "schema", schemaStr)
 
 	_, err = client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{Schema: schemaStr})
@@ -101,3 +125,146 @@ func writeSchema(_ context.Context, dryRun bool, cfg *config.AppConfig) {
 
 	logger.Info("schema applied to SpiceDB")
 }
+
+// mergeSchemaSegments returns the schema currently applied to SpiceDB with the
+// definitions of the segments replaced.
+func mergeSchemaSegments(client *authzed.Client, segments []spicedbx.SchemaSegment) string {
+	var current string
+
+	resp, err := client.ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+
+	switch {
+	case status.Code(err) == codes.NotFound:
+		logger.Warn("no schema applied to SpiceDB yet, applying the given resource types only")
+	case err != nil:
+		logger.Fatalw("error reading schema from SpiceDB", "error", err)
+	default:
+		current = resp.SchemaText
+	}
+
+	schemaStr, err := spicedbx.MergeSchema(current, segments)
+	if err != nil {
+		logger.Fatalw("error merging schema segments", "error", err)
+	}
+
+	return schemaStr
+}
+
+// mergeNamespaceSchema returns the schema currently applied to SpiceDB with
+// the definitions of the namespace replaced by schema, so the definitions of
+// other namespaces served by the same SpiceDB are kept.
+func mergeNamespaceSchema(client *authzed.Client, schema string) string {
+	var current string
+
+	resp, err := client.ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+
+	switch {
+	case status.Code(err) == codes.NotFound:
+	case err != nil:
+		logger.Fatalw("error reading schema from SpiceDB", "error", err)
+	default:
+		current = resp.SchemaText
+	}
+
+	schemaStr, err := spicedbx.ReplaceNamespaceSchema(current, schemaNamespace, schema)
+	if err != nil {
+		logger.Fatalw("error merging namespace schema", "namespace", schemaNamespace, "error", err)
+	}
+
+	return schemaStr
+}
+
+// schemaPolicyDir returns the policy directory of the namespace the schema
+// command applies to.
+func schemaPolicyDir(cfg *config.AppConfig) string {
+	if schemaNamespace == defaultNamespace {
+		return cfg.SpiceDB.PolicyDir
+	}
+
+	for _, ns := range cfg.SpiceDB.Namespaces {
+		if ns.Name == schemaNamespace {
+			return ns.PolicyDir
+		}
+	}
+
+	logger.Fatalw("namespace is not configured in spicedb.namespaces", "namespace", schemaNamespace)
+
+	return ""
+}
+
+// loadSchemaPolicy loads and validates the policy from the policy directory,
+// falling back to the default policy.
+func loadSchemaPolicy(cfg *config.AppConfig) iapl.Policy {
+	var (
+		err    error
+		policy iapl.Policy
+	)
+
+	if policyDir := schemaPolicyDir(cfg); policyDir != "" {
+		policy, err = iapl.NewPolicyFromDirectory(policyDir)
+		if err != nil {
+			logger.Fatalw("unable to load new policy from schema directory", "policy_dir", policyDir, "error", err)
+		}
+	} else {
+		logger.Warn("no spicedb policy defined, using default policy")
+
+		policy = iapl.DefaultPolicy()
+	}
+
+	if err = policy.Validate(); err != nil {
+		logger.Fatalw("invalid spicedb policy", "error", err)
+	}
+
+	return policy
+}
+
+// checkSchema prints the hashes of the policy, the schema generated from it
+// and the schema applied to SpiceDB, exiting with an error if the schemas differ.
+func checkSchema(ctx context.Context, cfg *config.AppConfig) {
+	policy := loadSchemaPolicy(cfg)
+
+	schemaStr, err := spicedbx.GenerateSchema(schemaNamespace, policy.Schema(), policy.Caveats()...)
+	if err != nil {
+		logger.Fatalw("failed to generate schema from policy", "error", err)
+	}
+
+	schemaHash, err := spicedbx.SchemaHash(schemaStr)
+	if err != nil {
+		logger.Fatalw("failed to hash schema", "error", err)
+	}
+
+	client, err := spicedbx.NewClient(cfg.SpiceDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize spicedb client", "error", err)
+	}
+
+	var liveHash string
+
+	resp, err := client.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+
+	switch {
+	case status.Code(err) == codes.NotFound:
+		logger.Warn("no schema applied to SpiceDB yet")
+	case err != nil:
+		logger.Fatalw("error reading schema from SpiceDB", "error", err)
+	default:
+		liveSchema, err := spicedbx.NamespaceSchema(resp.SchemaText, schemaNamespace)
+		if err != nil {
+			logger.Fatalw("failed to read namespace from SpiceDB schema", "error", err)
+		}
+
+		liveHash, err = spicedbx.SchemaHash(liveSchema)
+		if err != nil {
+			logger.Fatalw("failed to hash SpiceDB schema", "error", err)
+		}
+	}
+
+	fmt.Printf("policy hash:      %s\n", policy.Hash())
+	fmt.Printf("schema hash:      %s\n", schemaHash)
+	fmt.Printf("live schema hash: %s\n", liveHash)
+	fmt.Printf("schema matches:   %t\n", liveHash == schemaHash)
+
+	if liveHash != schemaHash {
+		os.Exit(1)
+	}
+}
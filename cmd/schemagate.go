@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.infratographer.com/permissions-api/internal/health"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+)
+
+// schemaGate compares the schema generated from the loaded policy with the
+// schema applied to SpiceDB on startup and after every policy reload.
+type schemaGate struct {
+	engine query.Engine
+
+	// mu serializes comparisons, so the result of an earlier comparison never
+	// replaces the result of a later one.
+	mu sync.Mutex
+	// err is the result of the last comparison.
+	err error
+}
+
+func newSchemaGate(engine query.Engine) *schemaGate {
+	return &schemaGate{engine: engine}
+}
+
+// verify compares the schemas, logging a warning if they differ.
+func (g *schemaGate) verify(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, health.DefaultTimeout)
+	defer cancel()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.compare(ctx); err != nil {
+		logger.Warnw("spicedb schema does not match the loaded policy, apply the schema with the schema command", "error", err)
+	}
+}
+
+// compare compares the schemas and records the result, mu must be held.
+func (g *schemaGate) compare(ctx context.Context) error {
+	info, err := g.engine.PolicyInfo(ctx)
+
+	switch {
+	case err != nil:
+		g.err = fmt.Errorf("unable to compare schemas: %w", err)
+	case !info.SchemaMatches:
+		g.err = fmt.Errorf("%w: policy schema %s, spicedb schema %s", spicedbx.ErrorSchemaMismatch, info.SchemaHash, info.LiveSchemaHash)
+	default:
+		g.err = nil
+	}
+
+	return g.err
+}
+
+// HealthCheck fails while the schemas differ. Until they match the schemas
+// are compared again on every check, so applying the schema makes the server
+// ready without a restart.
+func (g *schemaGate) HealthCheck(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.err == nil {
+		return nil
+	}
+
+	return g.compare(ctx)
+}
@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.infratographer.com/x/crdbx"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/encryption"
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+var (
+	seedCmd = &cobra.Command{
+		Use:   "seed",
+		Short: "create sample tenants, roles, groups and role-bindings for local development",
+		Long: `Create a sample tenant hierarchy with users, roles, groups and role-bindings
+derived from the loaded policy, and print the IDs of everything created, so the
+API can be exercised without creating the data by hand.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			seed(cmd.Context(), globalCfg, seedTenants, seedUsers)
+		},
+	}
+
+	seedTenants int
+	seedUsers   int
+)
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+
+	seedCmd.Flags().IntVar(&seedTenants, "tenants", 3, "number of child tenants to create below the root tenant")
+	seedCmd.Flags().IntVar(&seedUsers, "users", 5, "number of users to create")
+}
+
+// seeder creates the sample data, printing everything it creates.
+type seeder struct {
+	ctx    context.Context
+	engine query.Engine
+	actor  types.Resource
+}
+
+func (s *seeder) resource(prefix string) types.Resource {
+	resource, err := s.engine.NewResourceFromID(gidx.MustNewID(prefix))
+	if err != nil {
+		logger.Fatalw("error creating resource", "prefix", prefix, "error", err)
+	}
+
+	return resource
+}
+
+func (s *seeder) createRole(owner types.Resource, name string, actions []string, v2 bool) types.Role {
+	var (
+		role types.Role
+		err  error
+	)
+
+	if v2 {
+		role, err = s.engine.CreateRoleV2(s.ctx, s.actor, owner, name, actions)
+	} else {
+		role, err = s.engine.CreateRole(s.ctx, s.actor, owner, name, actions)
+	}
+
+	if err != nil {
+		logger.Fatalw("error creating role", "role", name, "error", err)
+	}
+
+	fmt.Printf("role          %s  %s on %s\n", role.ID, name, owner.ID)
+
+	return role
+}
+
+func (s *seeder) bind(resource types.Resource, role types.Role, subjects ...types.Resource) {
+	roleResource, err := s.engine.NewResourceFromID(role.ID)
+	if err != nil {
+		logger.Fatalw("error creating role resource", "error", err)
+	}
+
+	rbSubjects := make([]types.RoleBindingSubject, len(subjects))
+	subjectIDs := make([]string, len(subjects))
+
+	for i, subject := range subjects {
+		rbSubjects[i] = types.RoleBindingSubject{SubjectResource: subject}
+		subjectIDs[i] = subject.ID.String()
+	}
+
+	rb, err := s.engine.CreateRoleBinding(s.ctx, s.actor, resource, roleResource, rbSubjects)
+	if err != nil {
+		logger.Fatalw("error creating role-binding", "role", role.Name, "resource", resource.ID, "error", err)
+	}
+
+	fmt.Printf("role-binding  %s  %s on %s to %s\n", rb.ID, role.Name, resource.ID, strings.Join(subjectIDs, ","))
+}
+
+func seed(ctx context.Context, cfg *config.AppConfig, tenants, users int) {
+	if users < 1 {
+		logger.Fatal("at least one user is required")
+	}
+
+	spiceClient, err := spicedbx.NewClient(cfg.SpiceDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize spicedb client", "error", err)
+	}
+
+	db, err := crdbx.NewDB(cfg.CRDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize permissions-api database", "error", err)
+	}
+
+	encryptor, err := encryption.NewEncryptorFromConfig(cfg.Encryption)
+	if err != nil {
+		logger.Fatalw("unable to initialize encryption", "error", err)
+	}
+
+	store := storage.New(db, storage.WithLogger(logger), storage.WithEncryptor(encryptor))
+
+	var policy iapl.Policy
+
+	if cfg.SpiceDB.PolicyDir != "" {
+		policy, err = iapl.NewPolicyFromDirectory(cfg.SpiceDB.PolicyDir)
+		if err != nil {
+			logger.Fatalw("unable to load new policy from schema directory", "policy_dir", cfg.SpiceDB.PolicyDir, "error", err)
+		}
+	} else {
+		logger.Warn("no spicedb policy defined, using default policy")
+
+		policy = iapl.DefaultPolicy()
+	}
+
+	if err = policy.Validate(); err != nil {
+		logger.Fatalw("invalid spicedb policy", "error", err)
+	}
+
+	engine, err := query.NewEngine("infratographer", spiceClient, store, query.WithPolicy(policy), query.WithLogger(logger), query.WithoutDelegation())
+	if err != nil {
+		logger.Fatalw("error creating engine", "error", err)
+	}
+
+	// roles are owned by the first role owner of the policy, policies without
+	// RBAC v2 use v1 roles on tenants.
+	rbac := policy.RBAC()
+	v2 := rbac != nil && len(rbac.RoleOwners) != 0
+
+	ownerTypeName, subjectTypeName := "tenant", "user"
+
+	if v2 {
+		ownerTypeName = rbac.RoleOwners[0]
+
+		if subjectTypes := rbac.DirectRoleBindingSubjectTypes(); len(subjectTypes) != 0 {
+			subjectTypeName = subjectTypes[0]
+		}
+	}
+
+	ownerType := engine.GetResourceType(ownerTypeName)
+	if ownerType == nil {
+		logger.Fatalw("policy has no role owner type", "type", ownerTypeName)
+	}
+
+	subjectType := engine.GetResourceType(subjectTypeName)
+	if subjectType == nil {
+		logger.Fatalw("policy has no subject type", "type", subjectTypeName)
+	}
+
+	// child tenants are related to their parent through the relation of the
+	// owner type targeting the owner type itself, e.g. parent.
+	var parentRelation string
+
+	for _, rel := range ownerType.Relationships {
+		for _, target := range rel.Types {
+			if target.Name == ownerType.Name {
+				parentRelation = rel.Relation
+			}
+		}
+	}
+
+	s := &seeder{ctx: ctx, engine: engine}
+
+	subjects := make([]types.Resource, users)
+
+	for i := range subjects {
+		subjects[i] = s.resource(subjectType.IDPrefix)
+
+		fmt.Printf("%-13s %s\n", subjectType.Name, subjects[i].ID)
+	}
+
+	// the first user administers everything and is the actor of all changes.
+	s.actor = subjects[0]
+
+	root := s.resource(ownerType.IDPrefix)
+
+	fmt.Printf("%-13s %s  root\n", ownerType.Name, root.ID)
+
+	children := make([]types.Resource, tenants)
+
+	for i := range children {
+		children[i] = s.resource(ownerType.IDPrefix)
+
+		if parentRelation == "" {
+			fmt.Printf("%-13s %s\n", ownerType.Name, children[i].ID)
+
+			continue
+		}
+
+		err := engine.CreateRelationships(ctx, []types.Relationship{{
+			Resource: children[i],
+			Relation: parentRelation,
+			Subject:  root,
+		}})
+		if err != nil {
+			logger.Fatalw("error creating tenant relationship", "error", err)
+		}
+
+		fmt.Printf("%-13s %s  %s %s\n", ownerType.Name, children[i].ID, parentRelation, root.ID)
+	}
+
+	if !v2 {
+		seedV1Roles(s, ownerType, root, children, subjects)
+
+		return
+	}
+
+	seedV2Roles(s, rbac, root, children, subjects)
+}
+
+// seedV1Roles creates an admin and a viewer role on the root tenant, assigned
+// to the first user and all other users.
+func seedV1Roles(s *seeder, ownerType *types.ResourceType, root types.Resource, _ []types.Resource, subjects []types.Resource) {
+	var all, read []string
+
+	for _, action := range ownerType.Actions {
+		all = append(all, action.Name)
+
+		if isReadAction(action.Name) {
+			read = append(read, action.Name)
+		}
+	}
+
+	roles := []types.Role{s.createRole(root, "admin", all, false)}
+
+	if len(read) != 0 {
+		roles = append(roles, s.createRole(root, "viewer", read, false))
+	}
+
+	for i, subject := range subjects {
+		role := roles[min(i, len(roles)-1)]
+
+		if err := s.engine.AssignSubjectRole(s.ctx, subject, role); err != nil {
+			logger.Fatalw("error assigning role", "role", role.Name, "error", err)
+		}
+
+		fmt.Printf("assignment    %s  %s to %s\n", role.ID, role.Name, subject.ID)
+	}
+}
+
+// seedV2Roles creates an admin role, a viewer role and a role per action group
+// on the root tenant. The first user administers the root tenant, every child
+// tenant gets a group of the other users bound to the viewer role and one user
+// bound to an action group role directly.
+func seedV2Roles(s *seeder, rbac *iapl.RBAC, root types.Resource, children []types.Resource, subjects []types.Resource) {
+	allActions := s.engine.AllActions()
+
+	var read []string
+
+	for _, action := range allActions {
+		if isReadAction(action) {
+			read = append(read, action)
+		}
+	}
+
+	admin := s.createRole(root, "admin", allActions, true)
+
+	viewer := admin
+	if len(read) != 0 {
+		viewer = s.createRole(root, "viewer", read, true)
+	}
+
+	var groupRoles []types.Role
+
+	for _, group := range s.engine.AllActionGroups() {
+		groupRoles = append(groupRoles, s.createRole(root, group.Name, []string{group.Name}, true))
+	}
+
+	s.bind(root, admin, s.actor)
+
+	members := subjects[1:]
+
+	for i, child := range children {
+		if rbac.GroupResource != nil && len(members) != 0 {
+			group, err := s.engine.CreateGroup(s.ctx, s.actor, child, "viewers", "sample group of viewers")
+			if err != nil {
+				logger.Fatalw("error creating group", "owner", child.ID, "error", err)
+			}
+
+			if err := s.engine.AddGroupMembers(s.ctx, group.ID, members...); err != nil {
+				logger.Fatalw("error adding group members", "group", group.ID, "error", err)
+			}
+
+			fmt.Printf("%-13s %s  viewers of %s with %d members\n", rbac.GroupResource.Name, group.ID, child.ID, len(members))
+
+			groupResource, err := s.engine.NewResourceFromID(group.ID)
+			if err != nil {
+				logger.Fatalw("error creating group resource", "error", err)
+			}
+
+			s.bind(child, viewer, groupResource)
+		}
+
+		if len(groupRoles) != 0 && len(members) != 0 {
+			s.bind(child, groupRoles[i%len(groupRoles)], members[i%len(members)])
+		}
+	}
+}
+
+// isReadAction reports whether an action only reads, by the naming convention of actions.
+func isReadAction(action string) bool {
+	return strings.HasSuffix(action, "_get") || strings.HasSuffix(action, "_list")
+}
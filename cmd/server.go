@@ -2,26 +2,41 @@ package cmd
 
 import (
 	"context"
+	"os"
+	"slices"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.infratographer.com/x/crdbx"
 	"go.infratographer.com/x/echojwtx"
 	"go.infratographer.com/x/echox"
+	"go.infratographer.com/x/events"
 	"go.infratographer.com/x/otelx"
 	"go.infratographer.com/x/versionx"
+	"go.infratographer.com/x/viperx"
 	"go.uber.org/zap"
 
 	"go.infratographer.com/permissions-api/internal/api"
 	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/encryption"
+	"go.infratographer.com/permissions-api/internal/graphapi"
+	"go.infratographer.com/permissions-api/internal/grpcapi"
+	"go.infratographer.com/permissions-api/internal/health"
 	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/policysource"
+	"go.infratographer.com/permissions-api/internal/pubsub"
 	"go.infratographer.com/permissions-api/internal/query"
 	"go.infratographer.com/permissions-api/internal/spicedbx"
 	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/webhooks"
 )
 
 var apiDefaultListen = "0.0.0.0:7602"
 
+// defaultNamespace is the namespace served to requests not selecting one.
+const defaultNamespace = "infratographer"
+
 var serverCmd = &cobra.Command{
 	Use:   "server",
 	Short: "starts the permissions-api server",
@@ -38,15 +53,127 @@ func init() {
 	echox.MustViperFlags(v, serverCmd.Flags(), apiDefaultListen)
 	otelx.MustViperFlags(v, serverCmd.Flags())
 	echojwtx.MustViperFlags(v, serverCmd.Flags())
+	api.MustViperFlags(v, serverCmd.Flags())
+
+	serverCmd.Flags().Bool("usage-enabled", false, "track when roles and role-bindings were last used")
+	viperx.MustBindFlag(v, "usage.enabled", serverCmd.Flags().Lookup("usage-enabled"))
+	serverCmd.Flags().Duration("usage-flush-interval", time.Minute, "interval at which role and role-binding usage is recorded")
+	viperx.MustBindFlag(v, "usage.flushinterval", serverCmd.Flags().Lookup("usage-flush-interval"))
+
+	serverCmd.Flags().Int("groups-max-depth", query.DefaultMaxGroupDepth, "maximum number of levels groups may be nested")
+	viperx.MustBindFlag(v, "groups.maxdepth", serverCmd.Flags().Lookup("groups-max-depth"))
+
+	serverCmd.Flags().Bool("audit-enabled", false, "publish audit events of permission changes")
+	viperx.MustBindFlag(v, "audit.enabled", serverCmd.Flags().Lookup("audit-enabled"))
+	serverCmd.Flags().String("audit-topic", query.DefaultAuditTopic, "topic audit events are published to")
+	viperx.MustBindFlag(v, "audit.topic", serverCmd.Flags().Lookup("audit-topic"))
+	events.MustViperFlags(v, serverCmd.Flags(), appName)
+
+	serverCmd.Flags().Bool("spicedb-budget-enabled", false, "account SpiceDB calls per caller")
+	viperx.MustBindFlag(v, "spicedb.budget.enabled", serverCmd.Flags().Lookup("spicedb-budget-enabled"))
+	serverCmd.Flags().Duration("spicedb-budget-window", spicedbx.DefaultBudgetWindow, "period over which SpiceDB calls of a caller are counted")
+	viperx.MustBindFlag(v, "spicedb.budget.window", serverCmd.Flags().Lookup("spicedb-budget-window"))
+	serverCmd.Flags().Int64("spicedb-budget-request-limit", 0, "maximum SpiceDB calls per request (unlimited when 0)")
+	viperx.MustBindFlag(v, "spicedb.budget.requestlimit", serverCmd.Flags().Lookup("spicedb-budget-request-limit"))
+	serverCmd.Flags().Int64("spicedb-budget-window-limit", 0, "maximum SpiceDB calls per caller within the window (unlimited when 0)")
+	viperx.MustBindFlag(v, "spicedb.budget.windowlimit", serverCmd.Flags().Lookup("spicedb-budget-window-limit"))
+
+	serverCmd.Flags().Duration("spicedb-slow-call-threshold", 0, "log SpiceDB calls taking longer than this with their request (disabled when 0)")
+	viperx.MustBindFlag(v, "spicedb.slowcallthreshold", serverCmd.Flags().Lookup("spicedb-slow-call-threshold"))
+	serverCmd.Flags().Duration("storage-slow-query-threshold", 0, "log database queries taking longer than this with their arguments (disabled when 0)")
+	viperx.MustBindFlag(v, "storage.slowquerythreshold", serverCmd.Flags().Lookup("storage-slow-query-threshold"))
+
+	serverCmd.Flags().Bool("spicedb-breaker-enabled", false, "fail SpiceDB calls fast while SpiceDB is unavailable")
+	viperx.MustBindFlag(v, "spicedb.breaker.enabled", serverCmd.Flags().Lookup("spicedb-breaker-enabled"))
+	serverCmd.Flags().Int("spicedb-breaker-failure-threshold", spicedbx.DefaultBreakerFailureThreshold, "consecutive failed SpiceDB calls after which the circuit opens")
+	viperx.MustBindFlag(v, "spicedb.breaker.failurethreshold", serverCmd.Flags().Lookup("spicedb-breaker-failure-threshold"))
+	serverCmd.Flags().Duration("spicedb-breaker-open-timeout", spicedbx.DefaultBreakerOpenTimeout, "time the circuit stays open before SpiceDB is probed again")
+	viperx.MustBindFlag(v, "spicedb.breaker.opentimeout", serverCmd.Flags().Lookup("spicedb-breaker-open-timeout"))
+
+	serverCmd.Flags().StringSlice("degraded-fail-open-actions", []string{}, "low-risk actions allowed while the SpiceDB circuit is open")
+	viperx.MustBindFlag(v, "degraded.failopenactions", serverCmd.Flags().Lookup("degraded-fail-open-actions"))
+	serverCmd.Flags().Bool("degraded-cache-decisions", false, "serve cached decisions while the SpiceDB circuit is open")
+	viperx.MustBindFlag(v, "degraded.cachedecisions", serverCmd.Flags().Lookup("degraded-cache-decisions"))
+	serverCmd.Flags().Duration("degraded-cache-ttl", query.DefaultDecisionCacheTTL, "time a cached decision may be served for")
+	viperx.MustBindFlag(v, "degraded.cachettl", serverCmd.Flags().Lookup("degraded-cache-ttl"))
+	serverCmd.Flags().Int("degraded-cache-size", query.DefaultDecisionCacheSize, "maximum number of cached decisions")
+	viperx.MustBindFlag(v, "degraded.cachesize", serverCmd.Flags().Lookup("degraded-cache-size"))
+
+	serverCmd.Flags().Bool("check-cache-enabled", false, "cache permission check results until relationships change")
+	viperx.MustBindFlag(v, "checkcache.enabled", serverCmd.Flags().Lookup("check-cache-enabled"))
+	serverCmd.Flags().Duration("check-cache-ttl", query.DefaultCheckCacheTTL, "maximum time a permission check result is cached for")
+	viperx.MustBindFlag(v, "checkcache.ttl", serverCmd.Flags().Lookup("check-cache-ttl"))
+	serverCmd.Flags().Int("check-cache-size", query.DefaultCheckCacheSize, "maximum number of cached permission check results")
+	viperx.MustBindFlag(v, "checkcache.size", serverCmd.Flags().Lookup("check-cache-size"))
+
+	serverCmd.Flags().Bool("decision-log-enabled", false, "record the outcome of every permission check")
+	viperx.MustBindFlag(v, "decisionlog.enabled", serverCmd.Flags().Lookup("decision-log-enabled"))
+	serverCmd.Flags().String("decision-log-sink", query.DecisionLogSinkEvents, "where decisions are recorded: events, file or db")
+	viperx.MustBindFlag(v, "decisionlog.sink", serverCmd.Flags().Lookup("decision-log-sink"))
+	serverCmd.Flags().String("decision-log-topic", query.DefaultDecisionLogTopic, "topic decisions are published to by the events sink")
+	viperx.MustBindFlag(v, "decisionlog.topic", serverCmd.Flags().Lookup("decision-log-topic"))
+	serverCmd.Flags().String("decision-log-file", "", "file decisions are appended to by the file sink")
+	viperx.MustBindFlag(v, "decisionlog.file", serverCmd.Flags().Lookup("decision-log-file"))
+	serverCmd.Flags().Float64("decision-log-sample-rate", 1, "fraction of allowed decisions recorded, denied decisions are always recorded")
+	viperx.MustBindFlag(v, "decisionlog.samplerate", serverCmd.Flags().Lookup("decision-log-sample-rate"))
+
+	serverCmd.Flags().Int("webhooks-max-attempts", webhooks.DefaultMaxAttempts, "attempts to deliver a change to a webhook before the delivery is failed")
+	viperx.MustBindFlag(v, "webhooks.maxattempts", serverCmd.Flags().Lookup("webhooks-max-attempts"))
+	serverCmd.Flags().Duration("webhooks-initial-backoff", webhooks.DefaultInitialBackoff, "time to wait before retrying a failed webhook delivery, doubled after every attempt")
+	viperx.MustBindFlag(v, "webhooks.initialbackoff", serverCmd.Flags().Lookup("webhooks-initial-backoff"))
+	serverCmd.Flags().Duration("webhooks-max-backoff", webhooks.DefaultMaxBackoff, "maximum time to wait between attempts of a webhook delivery")
+	viperx.MustBindFlag(v, "webhooks.maxbackoff", serverCmd.Flags().Lookup("webhooks-max-backoff"))
+	serverCmd.Flags().Duration("webhooks-timeout", webhooks.DefaultTimeout, "timeout of a webhook delivery request")
+	viperx.MustBindFlag(v, "webhooks.timeout", serverCmd.Flags().Lookup("webhooks-timeout"))
+
+	serverCmd.Flags().Duration("spicedb-policy-reload-interval", 0, "how often the policy directory is checked for changes to reload (disabled when 0)")
+	viperx.MustBindFlag(v, "spicedb.policyreloadinterval", serverCmd.Flags().Lookup("spicedb-policy-reload-interval"))
+	serverCmd.Flags().Duration("config-reload-interval", 0, "how often the config file is checked for changes to reload operational settings (disabled when 0)")
+	viperx.MustBindFlag(v, "reload.interval", serverCmd.Flags().Lookup("config-reload-interval"))
+	serverCmd.Flags().String("spicedb-policy-url", "", "http(s) url the policy is loaded from instead of the policy directory (http requires a public key)")
+	viperx.MustBindFlag(v, "spicedb.policysource.url", serverCmd.Flags().Lookup("spicedb-policy-url"))
+	serverCmd.Flags().String("spicedb-policy-signature-url", "", "url of the ed25519 signature of the policy (defaults to the policy url with a .sig suffix)")
+	viperx.MustBindFlag(v, "spicedb.policysource.signatureurl", serverCmd.Flags().Lookup("spicedb-policy-signature-url"))
+	serverCmd.Flags().String("spicedb-policy-public-key", "", "PEM ed25519 public key file the policy signature is verified with (unverified when unset)")
+	viperx.MustBindFlag(v, "spicedb.policysource.publickeyfile", serverCmd.Flags().Lookup("spicedb-policy-public-key"))
+	serverCmd.Flags().Duration("spicedb-policy-timeout", policysource.DefaultTimeout, "timeout to fetch the policy from its url")
+	viperx.MustBindFlag(v, "spicedb.policysource.timeout", serverCmd.Flags().Lookup("spicedb-policy-timeout"))
+	serverCmd.Flags().String("spicedb-schema-check", spicedbx.SchemaCheckWarn, "action when the SpiceDB schema does not match the policy: warn or block readiness")
+	viperx.MustBindFlag(v, "spicedb.schemacheck", serverCmd.Flags().Lookup("spicedb-schema-check"))
+	grpcapi.MustViperFlags(v, serverCmd.Flags())
+	graphapi.MustViperFlags(v, serverCmd.Flags())
 }
 
-func serve(_ context.Context, cfg *config.AppConfig) {
+func serve(ctx context.Context, cfg *config.AppConfig) {
 	err := otelx.InitTracer(cfg.Tracing, appName, logger)
 	if err != nil {
 		logger.Fatalw("unable to initialize tracing system", "error", err)
 	}
 
-	spiceClient, err := spicedbx.NewClient(cfg.SpiceDB, cfg.Tracing.Enabled)
+	switch cfg.SpiceDB.SchemaCheck {
+	case spicedbx.SchemaCheckWarn, spicedbx.SchemaCheckBlock:
+	default:
+		logger.Fatalw("invalid spicedb schema check, must be warn or block", "schema_check", cfg.SpiceDB.SchemaCheck)
+	}
+
+	var budget *spicedbx.Budget
+
+	if cfg.SpiceDB.Budget.Enabled {
+		budget = spicedbx.NewBudget(cfg.SpiceDB.Budget)
+	}
+
+	var breaker *spicedbx.Breaker
+
+	if cfg.SpiceDB.Breaker.Enabled {
+		breaker = spicedbx.NewBreaker(cfg.SpiceDB.Breaker)
+	}
+
+	slowCalls := spicedbx.NewSlowCallLog(cfg.SpiceDB.SlowCallThreshold, logger)
+
+	dialOpts := append(budget.DialOptions(), breaker.DialOptions()...)
+	dialOpts = append(dialOpts, slowCalls.DialOptions()...)
+
+	spiceClient, err := spicedbx.NewClient(cfg.SpiceDB, cfg.Tracing.Enabled, dialOpts...)
 	if err != nil {
 		logger.Fatalw("unable to initialize spicedb client", "error", err)
 	}
@@ -56,16 +183,48 @@ func serve(_ context.Context, cfg *config.AppConfig) {
 		logger.Fatalw("unable to initialize permissions-api database", "error", err)
 	}
 
-	store := storage.New(db, storage.WithLogger(logger))
+	encryptor, err := encryption.NewEncryptorFromConfig(cfg.Encryption)
+	if err != nil {
+		logger.Fatalw("unable to initialize encryption", "error", err)
+	}
+
+	store := storage.New(db,
+		storage.WithLogger(logger),
+		storage.WithEncryptor(encryptor),
+		storage.WithSlowQueryThreshold(cfg.Storage.SlowQueryThreshold),
+	)
 
-	var policy iapl.Policy
+	var (
+		policy         iapl.Policy
+		policySource   policysource.Source
+		policyDocument iapl.PolicyDocument
+	)
 
-	if cfg.SpiceDB.PolicyDir != "" {
-		policy, err = iapl.NewPolicyFromDirectory(cfg.SpiceDB.PolicyDir)
+	switch {
+	case cfg.SpiceDB.PolicyDir != "" && cfg.SpiceDB.PolicySource.URL != "":
+		logger.Fatal("spicedb policy directory and policy url are mutually exclusive")
+	case cfg.SpiceDB.PolicySource.URL != "":
+		source, err := policysource.NewHTTP(cfg.SpiceDB.PolicySource)
+		if err != nil {
+			logger.Fatalw("unable to initialize policy source", "error", err)
+		}
+
+		policyDocument, err = source.Load(ctx, true)
+		if err != nil {
+			logger.Fatalw("unable to load policy from url", "policy_url", source.String(), "error", err)
+		}
+
+		policy = iapl.NewPolicy(policyDocument)
+		policySource = source
+	case cfg.SpiceDB.PolicyDir != "":
+		policyDocument, err = iapl.LoadPolicyDocumentFromDirectory(cfg.SpiceDB.PolicyDir)
 		if err != nil {
 			logger.Fatalw("unable to load new policy from schema directory", "policy_dir", cfg.SpiceDB.PolicyDir, "error", err)
 		}
-	} else {
+
+		policy = iapl.NewPolicy(policyDocument)
+		policySource = policysource.Directory(cfg.SpiceDB.PolicyDir)
+	default:
 		logger.Warn("no spicedb policy defined, using default policy")
 
 		policy = iapl.DefaultPolicy()
@@ -75,11 +234,153 @@ func serve(_ context.Context, cfg *config.AppConfig) {
 		logger.Fatalw("invalid spicedb policy", "error", err)
 	}
 
-	engine, err := query.NewEngine("infratographer", spiceClient, store, query.WithPolicy(policy), query.WithLogger(logger))
+	checker := health.NewChecker(health.WithLogger(logger))
+
+	engineOpts := []query.Option{
+		query.WithPolicy(policy),
+		query.WithLogger(logger),
+		query.WithMaxGroupDepth(cfg.Groups.MaxDepth),
+		query.WithCheckCache(cfg.CheckCache),
+	}
+
+	if breaker != nil {
+		engineOpts = append(engineOpts, query.WithDegradedMode(cfg.Degraded))
+	}
+
+	if cfg.Usage.Enabled {
+		engineOpts = append(engineOpts, query.WithUsageTracking(cfg.Usage.FlushInterval))
+	}
+
+	var eventsConn events.Connection
+
+	if cfg.Audit.Enabled || (cfg.DecisionLog.Enabled && cfg.DecisionLog.Sink == query.DecisionLogSinkEvents) {
+		eventsConn, err = events.NewConnection(cfg.Events.Config, events.WithLogger(logger))
+		if err != nil {
+			logger.Fatalw("failed to initialize events", "error", err)
+		}
+
+		defer func() {
+			if err := eventsConn.Shutdown(context.Background()); err != nil {
+				logger.Errorw("failed to shutdown events gracefully", "error", err)
+			}
+		}()
+
+		checker.AddCheck("nats", pubsub.Healthcheck(eventsConn))
+	}
+
+	if cfg.Audit.Enabled {
+		engineOpts = append(engineOpts, query.WithAuditEvents(eventsConn, cfg.Audit.Topic))
+	}
+
+	if cfg.DecisionLog.Enabled {
+		var sink query.DecisionSink
+
+		switch cfg.DecisionLog.Sink {
+		case query.DecisionLogSinkEvents:
+			sink = query.NewEventsDecisionSink(eventsConn, cfg.DecisionLog.Topic)
+		case query.DecisionLogSinkFile:
+			f, err := os.OpenFile(cfg.DecisionLog.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+			if err != nil {
+				logger.Fatalw("unable to open decision log file", "file", cfg.DecisionLog.File, "error", err)
+			}
+
+			defer f.Close()
+
+			sink = query.NewFileDecisionSink(f)
+		case query.DecisionLogSinkDB:
+			sink = query.NewStorageDecisionSink(store)
+		default:
+			logger.Fatalw("invalid decision log sink, must be events, file or db", "sink", cfg.DecisionLog.Sink)
+		}
+
+		engineOpts = append(engineOpts, query.WithDecisionLog(sink, cfg.DecisionLog.SampleRate))
+	}
+
+	if len(cfg.Webhooks.Endpoints) != 0 {
+		dispatcher, err := webhooks.NewDispatcher(store, cfg.Webhooks, webhooks.WithLogger(logger))
+		if err != nil {
+			logger.Fatalw("unable to initialize webhooks", "error", err)
+		}
+
+		go dispatcher.Run(ctx)
+
+		engineOpts = append(engineOpts, query.WithWebhooks(dispatcher))
+	}
+
+	engine, err := query.NewReloadableEngine(defaultNamespace, spiceClient, store, engineOpts...)
 	if err != nil {
 		logger.Fatalw("error creating engine", "error", err)
 	}
 
+	gate := newSchemaGate(engine)
+	gate.verify(ctx)
+
+	if cfg.SpiceDB.SchemaCheck == spicedbx.SchemaCheckBlock {
+		checker.AddReadinessCheck("schema", gate.HealthCheck)
+	}
+
+	if policySource != nil {
+		go watchPolicy(ctx, engine, policySource, policyDocument, cfg.SpiceDB.PolicyReloadInterval, gate.verify)
+	}
+
+	routerOpts := []api.Option{
+		api.WithLogger(logger),
+		api.WithGraphQL(cfg.GraphQL),
+		api.WithRateLimit(cfg.RateLimit),
+		api.WithImpersonation(cfg.Impersonation),
+		api.WithAdmin(cfg.Admin),
+		api.WithAccessRequests(cfg.AccessRequests),
+		api.WithFilter(cfg.Filter),
+		api.WithLimits(cfg.Limits),
+		api.WithSpiceDBBudget(budget),
+	}
+
+	engines := []*query.ReloadableEngine{engine}
+
+	if len(cfg.SpiceDB.Namespaces) != 0 {
+		routerOpts = append(routerOpts, api.WithNamespace(defaultNamespace, engine))
+	}
+
+	for _, ns := range cfg.SpiceDB.Namespaces {
+		nsDocument, err := iapl.LoadPolicyDocumentFromDirectory(ns.PolicyDir)
+		if err != nil {
+			logger.Fatalw("unable to load namespace policy", "namespace", ns.Name, "policy_dir", ns.PolicyDir, "error", err)
+		}
+
+		nsPolicy := iapl.NewPolicy(nsDocument)
+
+		if err := nsPolicy.Validate(); err != nil {
+			logger.Fatalw("invalid namespace policy", "namespace", ns.Name, "error", err)
+		}
+
+		nsEngine, err := query.NewReloadableEngine(ns.Name, spiceClient, store, append(slices.Clone(engineOpts), query.WithPolicy(nsPolicy))...)
+		if err != nil {
+			logger.Fatalw("error creating namespace engine", "namespace", ns.Name, "error", err)
+		}
+
+		nsGate := newSchemaGate(nsEngine)
+		nsGate.verify(ctx)
+
+		if cfg.SpiceDB.SchemaCheck == spicedbx.SchemaCheckBlock {
+			checker.AddReadinessCheck("schema-"+ns.Name, nsGate.HealthCheck)
+		}
+
+		go watchPolicy(ctx, nsEngine, policysource.Directory(ns.PolicyDir), nsDocument, cfg.SpiceDB.PolicyReloadInterval, nsGate.verify)
+
+		routerOpts = append(routerOpts, api.WithNamespace(ns.Name, nsEngine))
+		engines = append(engines, nsEngine)
+	}
+
+	// engines are stopped once the servers stopped, before the store and the
+	// decision log sinks are closed, flushing pending usage and decisions.
+	defer func() {
+		for _, e := range engines {
+			if err := e.Stop(); err != nil {
+				logger.Errorw("error stopping engine", "error", err)
+			}
+		}
+	}()
+
 	srv, err := echox.NewServer(
 		logger.Desugar(),
 		echox.ConfigFromViper(viper.GetViper()),
@@ -89,14 +390,48 @@ func serve(_ context.Context, cfg *config.AppConfig) {
 		logger.Fatal("failed to initialize new server", zap.Error(err))
 	}
 
-	r, err := api.NewRouter(cfg.OIDC, engine, api.WithLogger(logger))
+	r, err := api.NewRouter(cfg.OIDC, engine, routerOpts...)
 	if err != nil {
 		logger.Fatalw("unable to initialize router", "error", err)
 	}
 
+	go watchConfig(ctx, reloadableSettings{
+		router:    r,
+		engines:   engines,
+		store:     store,
+		slowCalls: slowCalls,
+		breaker:   breaker,
+	}, cfg.Reload.Interval)
+
+	checker.AddCheck("spicedb", spicedbx.Healthcheck(spiceClient))
+	checker.AddCheck("storage", store.HealthCheck)
+
+	if breaker != nil {
+		checker.AddCheck("spicedb-circuit", breaker.HealthCheck)
+	}
+
 	srv.AddHandler(r)
-	srv.AddReadinessCheck("spicedb", spicedbx.Healthcheck(spiceClient))
-	srv.AddReadinessCheck("storage", store.HealthCheck)
+	srv.AddHandler(checker)
+
+	if cfg.GRPC.Listen != "" {
+		// requests share the payload and rate limits of the REST API
+		grpcSrv, err := grpcapi.NewServer(cfg.OIDC, engine,
+			grpcapi.WithLogger(logger),
+			grpcapi.WithLimits(r.Limits()),
+			grpcapi.WithRateLimiter(r),
+		)
+		if err != nil {
+			logger.Fatalw("unable to initialize grpc server", "error", err)
+		}
+
+		go func() {
+			if err := grpcSrv.ListenAndServe(cfg.GRPC.Listen); err != nil {
+				logger.Fatalw("failed to run grpc server", "error", err)
+			}
+		}()
+
+		defer grpcSrv.GracefulStop()
+	}
 
 	if err := srv.Run(); err != nil {
 		logger.Fatal("failed to run server", zap.Error(err))
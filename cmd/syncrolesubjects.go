@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.infratographer.com/x/crdbx"
+
+	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/encryption"
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/storage"
+)
+
+var syncRoleSubjectsCmd = &cobra.Command{
+	Use:   "sync-role-subjects",
+	Short: "grant the actions of existing roles to all role subject types of the policy",
+	Long: `Roles grant their actions to the role subject types of the policy when they
+are created or updated. After a subject type, such as a service account, is added
+to the policy, run this command to grant the actions of the existing roles to it
+as well. Running it again changes nothing.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		syncRoleSubjects(cmd.Context(), globalCfg)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncRoleSubjectsCmd)
+}
+
+func syncRoleSubjects(ctx context.Context, cfg *config.AppConfig) {
+	spiceClient, err := spicedbx.NewClient(cfg.SpiceDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize spicedb client", "error", err)
+	}
+
+	db, err := crdbx.NewDB(cfg.CRDB, cfg.Tracing.Enabled)
+	if err != nil {
+		logger.Fatalw("unable to initialize permissions-api database", "error", err)
+	}
+
+	encryptor, err := encryption.NewEncryptorFromConfig(cfg.Encryption)
+	if err != nil {
+		logger.Fatalw("unable to initialize encryption", "error", err)
+	}
+
+	store := storage.New(db, storage.WithLogger(logger), storage.WithEncryptor(encryptor))
+
+	var policy iapl.Policy
+
+	if cfg.SpiceDB.PolicyDir != "" {
+		policy, err = iapl.NewPolicyFromDirectory(cfg.SpiceDB.PolicyDir)
+		if err != nil {
+			logger.Fatalw("unable to load new policy from schema directory", "policy_dir", cfg.SpiceDB.PolicyDir, "error", err)
+		}
+	} else {
+		logger.Warn("no spicedb policy defined, using default policy")
+
+		policy = iapl.DefaultPolicy()
+	}
+
+	if err = policy.Validate(); err != nil {
+		logger.Fatalw("invalid spicedb policy", "error", err)
+	}
+
+	engine, err := query.NewEngine("infratographer", spiceClient, store, query.WithPolicy(policy), query.WithLogger(logger))
+	if err != nil {
+		logger.Fatalw("error creating engine", "error", err)
+	}
+
+	created, err := engine.SyncRoleSubjectTypes(ctx)
+	if err != nil {
+		logger.Fatalw("error syncing role subject types", "created", created, "error", err)
+	}
+
+	fmt.Printf("granted %d role permissions\n", created)
+}
@@ -17,6 +17,8 @@ import (
 	"go.uber.org/zap"
 
 	"go.infratographer.com/permissions-api/internal/config"
+	"go.infratographer.com/permissions-api/internal/encryption"
+	"go.infratographer.com/permissions-api/internal/health"
 	"go.infratographer.com/permissions-api/internal/iapl"
 	"go.infratographer.com/permissions-api/internal/pubsub"
 	"go.infratographer.com/permissions-api/internal/query"
@@ -64,7 +66,12 @@ func worker(ctx context.Context, cfg *config.AppConfig) {
 		logger.Fatalw("unable to initialize permissions-api database", "error", err)
 	}
 
-	store := storage.New(db, storage.WithLogger(logger))
+	encryptor, err := encryption.NewEncryptorFromConfig(cfg.Encryption)
+	if err != nil {
+		logger.Fatalw("unable to initialize encryption", "error", err)
+	}
+
+	store := storage.New(db, storage.WithLogger(logger), storage.WithEncryptor(encryptor))
 
 	var policy iapl.Policy
 
@@ -88,9 +95,18 @@ func worker(ctx context.Context, cfg *config.AppConfig) {
 		logger.Fatalw("error creating engine", "error", err)
 	}
 
-	subscriber, err := pubsub.NewSubscriber(ctx, eventsConn, engine,
+	subscriberOpts := []pubsub.SubscriberOption{
 		pubsub.WithLogger(logger),
-	)
+		pubsub.WithRetryPolicy(cfg.Events.Retry),
+		pubsub.WithDeadLetter(eventsConn, cfg.Events.DeadLetterTopic),
+		pubsub.WithBatching(cfg.Events.Batch),
+	}
+
+	if cfg.Events.CleanupDeletions {
+		subscriberOpts = append(subscriberOpts, pubsub.WithResourceDeletions(eventsConn))
+	}
+
+	subscriber, err := pubsub.NewSubscriber(ctx, eventsConn, engine, subscriberOpts...)
 	if err != nil {
 		logger.Fatalw("unable to initialize subscriber", "error", err)
 	}
@@ -112,13 +128,31 @@ func worker(ctx context.Context, cfg *config.AppConfig) {
 		}
 	}
 
+	if cfg.Events.CleanupDeletions {
+		for _, rt := range policy.Schema() {
+			if err := subscriber.SubscribeDeletions(rt.Name); err != nil {
+				logger.Fatalw("failed to subscribe to deletions topic", "topic", rt.Name, "error", err)
+			}
+		}
+	}
+
+	if cfg.Events.RelationshipChangesTopic != "" {
+		changes := pubsub.NewChangePublisher(engine, eventsConn, cfg.Events.RelationshipChangesTopic, logger)
+
+		go changes.Run(ctx)
+	}
+
 	srv, err := echox.NewServer(logger.Desugar(), cfg.Server, versionx.BuildDetails())
 	if err != nil {
 		logger.Fatal("failed to initialize new server", zap.Error(err))
 	}
 
-	srv.AddReadinessCheck("spicedb", spicedbx.Healthcheck(spiceClient))
-	srv.AddReadinessCheck("storage", store.HealthCheck)
+	checker := health.NewChecker(health.WithLogger(logger))
+	checker.AddCheck("spicedb", spicedbx.Healthcheck(spiceClient))
+	checker.AddCheck("storage", store.HealthCheck)
+	checker.AddCheck("nats", pubsub.Healthcheck(eventsConn))
+
+	srv.AddHandler(checker)
 
 	quit := make(chan os.Signal, 1)
 
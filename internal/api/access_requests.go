@@ -0,0 +1,285 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/gidx"
+	"go.infratographer.com/x/viperx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// DefaultAccessRequestApproverAction is the policy action required on a
+// resource to approve or deny access requests for it.
+const DefaultAccessRequestApproverAction = string(iapl.RoleBindingActionCreate)
+
+// AccessRequestConfig is the configuration of access requests, which let
+// subjects request a role-binding to be approved by an approver.
+type AccessRequestConfig struct {
+	// Enabled serves the access request API.
+	Enabled bool
+	// ApproverAction is the policy action an approver must have on the
+	// resource of an access request to decide it.
+	ApproverAction string
+}
+
+// accessRequests authorizes deciding access requests.
+type accessRequests struct {
+	approverAction string
+}
+
+// WithAccessRequests serves the access request API when enabled in the config.
+func WithAccessRequests(config AccessRequestConfig) Option {
+	return func(r *Router) error {
+		if !config.Enabled {
+			return nil
+		}
+
+		action := config.ApproverAction
+		if action == "" {
+			action = DefaultAccessRequestApproverAction
+		}
+
+		r.accessRequests = &accessRequests{
+			approverAction: action,
+		}
+
+		return nil
+	}
+}
+
+// accessRequestsMW rejects requests to the access request API unless enabled.
+func (r *Router) accessRequestsMW(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if r.accessRequests == nil {
+			return echo.NewHTTPError(http.StatusNotFound, "access requests are not enabled")
+		}
+
+		return next(c)
+	}
+}
+
+// accessRequestCreate records a request by the authenticated subject to be
+// bound to a role on a resource. Any subject can request access.
+func (r *Router) accessRequestCreate(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "api.accessRequestCreate")
+	defer span.End()
+
+	var body accessRequestRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	if body.Reason == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "reason is required")
+	}
+
+	resourceID, err := gidx.Parse(body.ResourceID)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	roleID, err := gidx.Parse(body.RoleID)
+	if err != nil {
+		return r.errorResponse("error parsing role ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	roleResource, err := r.engine.NewResourceFromID(roleID)
+	if err != nil {
+		return r.errorResponse("error creating role resource", err)
+	}
+
+	subject, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	req, err := r.engine.CreateAccessRequest(ctx, subject, resource, roleResource, body.Reason)
+	if err != nil {
+		return r.errorResponse("error creating access request", err)
+	}
+
+	return c.JSON(http.StatusCreated, newAccessRequestResponse(req))
+}
+
+func (r *Router) accessRequestsList(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.accessRequestsList",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, r.accessRequests.approverAction, resource); err != nil {
+		return err
+	}
+
+	requests, err := r.engine.ListAccessRequests(ctx, resource, types.AccessRequestStatus(c.QueryParam("status")))
+	if err != nil {
+		return r.errorResponse("error listing access requests", err)
+	}
+
+	resp := listAccessRequestsResponse{
+		Data: make([]accessRequestResponse, len(requests)),
+	}
+
+	for i, req := range requests {
+		resp.Data[i] = newAccessRequestResponse(req)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// accessRequestGet returns an access request to its subject or an approver.
+func (r *Router) accessRequestGet(c echo.Context) error {
+	requestIDStr := c.Param("request_id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.accessRequestGet",
+		trace.WithAttributes(attribute.String("id", requestIDStr)),
+	)
+	defer span.End()
+
+	requestID, err := gidx.Parse(requestIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing access request ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	req, err := r.engine.GetAccessRequest(ctx, requestID)
+	if err != nil {
+		return r.errorResponse("error getting access request", err)
+	}
+
+	if req.SubjectID != actor.ID {
+		resource, err := r.engine.NewResourceFromID(req.ResourceID)
+		if err != nil {
+			return r.errorResponse("error creating resource", err)
+		}
+
+		if err := r.checkActionWithResponse(ctx, actor, r.accessRequests.approverAction, resource); err != nil {
+			return err
+		}
+	}
+
+	return c.JSON(http.StatusOK, newAccessRequestResponse(req))
+}
+
+func (r *Router) accessRequestApprove(c echo.Context) error {
+	return r.accessRequestDecide(c, true)
+}
+
+func (r *Router) accessRequestDeny(c echo.Context) error {
+	return r.accessRequestDecide(c, false)
+}
+
+// accessRequestDecide approves or denies an access request, approving creates
+// its role-binding on behalf of the approver.
+func (r *Router) accessRequestDecide(c echo.Context, approve bool) error {
+	requestIDStr := c.Param("request_id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.accessRequestDecide",
+		trace.WithAttributes(
+			attribute.String("id", requestIDStr),
+			attribute.Bool("approve", approve),
+		),
+	)
+	defer span.End()
+
+	requestID, err := gidx.Parse(requestIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing access request ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	var body accessRequestDecisionRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	req, err := r.engine.GetAccessRequest(ctx, requestID)
+	if err != nil {
+		return r.errorResponse("error getting access request", err)
+	}
+
+	resource, err := r.engine.NewResourceFromID(req.ResourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, r.accessRequests.approverAction, resource); err != nil {
+		return err
+	}
+
+	req, err = r.engine.DecideAccessRequest(ctx, actor, requestID, approve, body.Reason)
+	if err != nil {
+		return r.errorResponse("error deciding access request", err)
+	}
+
+	return c.JSON(http.StatusOK, newAccessRequestResponse(req))
+}
+
+func newAccessRequestResponse(req types.AccessRequest) accessRequestResponse {
+	return accessRequestResponse{
+		ID:             req.ID,
+		ResourceID:     req.ResourceID,
+		RoleID:         req.RoleID,
+		SubjectID:      req.SubjectID,
+		Reason:         req.Reason,
+		Status:         string(req.Status),
+		CreatedAt:      req.CreatedAt.Format(time.RFC3339),
+		DecidedBy:      req.DecidedBy,
+		DecidedAt:      formatLastUsed(req.DecidedAt),
+		DecisionReason: req.DecisionReason,
+		RoleBindingID:  req.RoleBindingID,
+	}
+}
+
+// accessRequestsViperFlags sets the cobra flags and viper config for access requests.
+func accessRequestsViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Bool("access-requests-enabled", false, "allow subjects to request role-bindings to be approved by an approver")
+	viperx.MustBindFlag(v, "accessrequests.enabled", flags.Lookup("access-requests-enabled"))
+
+	flags.String("access-requests-approver-action", DefaultAccessRequestApproverAction, "policy action required on a resource to approve or deny access requests for it")
+	viperx.MustBindFlag(v, "accessrequests.approveraction", flags.Lookup("access-requests-approver-action"))
+}
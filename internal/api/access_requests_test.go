@@ -0,0 +1,264 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testauth"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestAccessRequests(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	type input struct {
+		enabled bool
+		path    string
+		body    map[string]any
+	}
+
+	createInput := input{
+		enabled: true,
+		path:    "/api/v2/access-requests",
+		body:    map[string]any{"resource_id": "tnntten-abc123", "role_id": "permrol-abc123", "reason": "on call"},
+	}
+
+	approveInput := input{
+		enabled: true,
+		path:    "/api/v2/access-requests/permacr-abc123/approve",
+		body:    map[string]any{"reason": "approved for the incident"},
+	}
+
+	pending := types.AccessRequest{
+		ID:         "permacr-abc123",
+		ResourceID: "tnntten-abc123",
+		RoleID:     "permrol-abc123",
+		SubjectID:  "idntusr-requester",
+		Reason:     "on call",
+		Status:     types.AccessRequestPending,
+		CreatedAt:  time.Now(),
+	}
+
+	testCases := []testingx.TestCase[input, *httptest.ResponseRecorder]{
+		{
+			Name: "Disabled",
+			Input: input{
+				path: createInput.path,
+				body: createInput.body,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertNotCalled(t, "CreateAccessRequest")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusNotFound, res.Success.Code)
+			},
+		},
+		{
+			Name: "CreateMissingReason",
+			Input: input{
+				enabled: true,
+				path:    createInput.path,
+				body:    map[string]any{"resource_id": "tnntten-abc123", "role_id": "permrol-abc123"},
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+		{
+			Name:  "CreateSuccess",
+			Input: createInput,
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("CreateAccessRequest").Return(pending, nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNotCalled(t, "SubjectHasPermission")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusCreated, res.Success.Code)
+
+				var resp accessRequestResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&resp))
+
+				assert.Equal(t, "permacr-abc123", resp.ID.String())
+				assert.Equal(t, "pending", resp.Status)
+			},
+		},
+		{
+			Name:  "ApprovePermissionDenied",
+			Input: approveInput,
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("GetAccessRequest").Return(pending, nil)
+				engine.On("SubjectHasPermission").Return(query.ErrActionNotAssigned)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNotCalled(t, "DecideAccessRequest")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
+		{
+			Name:  "ApproveAlreadyDecided",
+			Input: approveInput,
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("GetAccessRequest").Return(pending, nil)
+				engine.On("SubjectHasPermission").Return(nil)
+				engine.On("DecideAccessRequest").Return(types.AccessRequest{}, query.ErrAccessRequestDecided)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusConflict, res.Success.Code)
+			},
+		},
+		{
+			Name:  "ApproveSuccess",
+			Input: approveInput,
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				approved := pending
+				approved.Status = types.AccessRequestApproved
+				approved.DecidedBy = "idntusr-abc123"
+				approved.DecisionReason = "approved for the incident"
+				approved.RoleBindingID = "permrbn-abc123"
+
+				engine.On("GetAccessRequest").Return(pending, nil)
+				engine.On("SubjectHasPermission").Return(nil)
+				engine.On("DecideAccessRequest").Return(approved, nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var resp accessRequestResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&resp))
+
+				assert.Equal(t, "approved", resp.Status)
+				assert.Equal(t, "permrbn-abc123", resp.RoleBindingID.String())
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in input) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(
+			echojwtx.AuthConfig{Issuer: authsrv.Issuer},
+			engine,
+			WithAccessRequests(AccessRequestConfig{Enabled: in.enabled}),
+		)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		body, err := json.Marshal(in.body)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://127.0.0.1"+in.path, bytes.NewBuffer(body))
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
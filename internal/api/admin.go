@@ -0,0 +1,107 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/gidx"
+	"go.infratographer.com/x/viperx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// DefaultAdminAction is the policy action required to use the admin API.
+const DefaultAdminAction = "iam_admin"
+
+// AdminConfig is the configuration of the admin API, used by trusted
+// services and operators to work with relationships directly.
+type AdminConfig struct {
+	// Enabled serves the admin API.
+	Enabled bool
+	// Action is the policy action the caller must have on ResourceID to use the admin API.
+	Action string
+	// ResourceID is the resource, usually the root tenant, on which Action is checked.
+	ResourceID gidx.PrefixedID
+}
+
+// admin authorizes requests to the admin API.
+type admin struct {
+	action   string
+	resource types.Resource
+}
+
+// WithAdmin serves the admin API to subjects with the configured action when
+// enabled in the config.
+func WithAdmin(config AdminConfig) Option {
+	return func(r *Router) error {
+		if !config.Enabled {
+			return nil
+		}
+
+		resource, err := r.engine.NewResourceFromID(config.ResourceID)
+		if err != nil {
+			return fmt.Errorf("invalid admin resource %q: %w", config.ResourceID, err)
+		}
+
+		action := config.Action
+		if action == "" {
+			action = DefaultAdminAction
+		}
+
+		r.admin = &admin{
+			action:   action,
+			resource: resource,
+		}
+
+		return nil
+	}
+}
+
+// adminMW only lets subjects authorized to use the admin API through. Every
+// request to the admin API is recorded in the audit log.
+func (r *Router) adminMW(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if r.admin == nil {
+			return echo.NewHTTPError(http.StatusNotFound, "admin API is not enabled")
+		}
+
+		actor, err := r.currentSubject(c)
+		if err != nil {
+			return err
+		}
+
+		audit := query.ContextLogger(c.Request().Context(), r.logger).Named("audit")
+
+		if err := r.checkActionWithResponse(c.Request().Context(), actor, r.admin.action, r.admin.resource); err != nil {
+			audit.Warnw("admin request denied",
+				"actor", actor.ID,
+				"method", c.Request().Method, "path", c.Request().URL.Path,
+			)
+
+			return err
+		}
+
+		audit.Infow("admin request",
+			"actor", actor.ID,
+			"method", c.Request().Method, "path", c.Request().URL.Path,
+		)
+
+		return next(c)
+	}
+}
+
+// adminViperFlags sets the cobra flags and viper config for the admin API.
+func adminViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Bool("admin-enabled", false, "serve the admin API to authorized subjects")
+	viperx.MustBindFlag(v, "admin.enabled", flags.Lookup("admin-enabled"))
+
+	flags.String("admin-action", DefaultAdminAction, "policy action required to use the admin API")
+	viperx.MustBindFlag(v, "admin.action", flags.Lookup("admin-action"))
+
+	flags.String("admin-resource-id", "", "resource on which the admin action is checked, usually the root tenant")
+	viperx.MustBindFlag(v, "admin.resourceid", flags.Lookup("admin-resource-id"))
+}
@@ -0,0 +1,502 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testauth"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestRelationshipsBulkWrite(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	type testInput struct {
+		enabled bool
+		body    string
+	}
+
+	const body = `{
+		"writes": [
+			{"operation": "create", "resource_id": "loadbal-lb", "relation": "owner", "subject_id": "tnntten-child"},
+			{"operation": "delete", "resource_id": "tnntten-child", "relation": "parent", "subject_id": "tnntten-old"}
+		],
+		"preconditions": [
+			{"resource_id": "tnntten-child", "relation": "parent", "subject_id": "tnntten-root", "must_exist": true}
+		]
+	}`
+
+	newResource := func(id gidx.PrefixedID) types.Resource {
+		res, err := (&mock.Engine{}).NewResourceFromID(id)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	writes := []types.RelationshipWrite{
+		{
+			Operation:    types.RelationshipOperationCreate,
+			Relationship: types.Relationship{Resource: newResource("loadbal-lb"), Relation: "owner", Subject: newResource("tnntten-child")},
+		},
+		{
+			Operation:    types.RelationshipOperationDelete,
+			Relationship: types.Relationship{Resource: newResource("tnntten-child"), Relation: "parent", Subject: newResource("tnntten-old")},
+		},
+	}
+
+	preconditions := []types.RelationshipPrecondition{
+		{
+			Relationship: types.Relationship{Resource: newResource("tnntten-child"), Relation: "parent", Subject: newResource("tnntten-root")},
+			MustExist:    true,
+		},
+	}
+
+	testCases := []testingx.TestCase[testInput, *httptest.ResponseRecorder]{
+		{
+			Name: "Disabled",
+			Input: testInput{
+				body: body,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertNotCalled(t, "WriteRelationships")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusNotFound, res.Success.Code)
+			},
+		},
+		{
+			Name: "NotAuthorized",
+			Input: testInput{
+				enabled: true,
+				body:    body,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(query.ErrActionNotAssigned).Once()
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNotCalled(t, "WriteRelationships")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
+		{
+			Name: "InvalidID",
+			Input: testInput{
+				enabled: true,
+				body:    `{"writes": [{"operation": "create", "resource_id": "not-an-id", "relation": "owner", "subject_id": "tnntten-child"}]}`,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(nil).Once()
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertNotCalled(t, "WriteRelationships")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+		{
+			Name: "Written",
+			Input: testInput{
+				enabled: true,
+				body:    body,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(nil).Once()
+				engine.On("WriteRelationships", writes, preconditions).Return(2, nil).Once()
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+				assert.JSONEq(t, `{"written": 2}`, res.Success.Body.String())
+			},
+		},
+		{
+			Name: "PreconditionFailed",
+			Input: testInput{
+				enabled: true,
+				body:    body,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(nil).Once()
+				engine.On("WriteRelationships", writes, preconditions).Return(0, query.ErrInvalidArgument).Once()
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+				assert.Contains(t, res.Success.Body.String(), "0 of 2 writes applied")
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input testInput) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine,
+			WithAdmin(AdminConfig{
+				Enabled:    input.enabled,
+				ResourceID: "tnntten-root",
+			}),
+		)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://127.0.0.1/api/v2/admin/relationships/bulk", strings.NewReader(input.body))
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+func TestRelationshipsRead(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	type testInput struct {
+		enabled bool
+		query   string
+	}
+
+	filter := types.RelationshipFilter{
+		ResourceType: "loadbalancer",
+		Relation:     "owner",
+		SubjectID:    "tnntten-root",
+	}
+
+	testCases := []testingx.TestCase[testInput, *httptest.ResponseRecorder]{
+		{
+			Name: "Disabled",
+			Input: testInput{
+				query: "resource_type=loadbalancer",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertNotCalled(t, "ReadRelationships")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusNotFound, res.Success.Code)
+			},
+		},
+		{
+			Name: "MissingResourceType",
+			Input: testInput{
+				enabled: true,
+				query:   "relation=owner",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(nil).Once()
+				engine.On("ReadRelationships", types.RelationshipFilter{Relation: "owner"}, DefaultPaginationSize, "").
+					Return([]types.RelationshipRecord(nil), "", query.ErrInvalidArgument).Once()
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+		{
+			Name: "Read",
+			Input: testInput{
+				enabled: true,
+				query:   "resource_type=loadbalancer&relation=owner&subject_id=tnntten-root&limit=2&cursor=abc",
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(nil).Once()
+				engine.On("ReadRelationships", filter, 2, "abc").Return([]types.RelationshipRecord{
+					{ResourceType: "loadbalancer", ResourceID: "loadbal-lba", Relation: "owner", SubjectType: "tenant", SubjectID: "tnntten-root"},
+					{ResourceType: "loadbalancer", ResourceID: "loadbal-lbb", Relation: "owner", SubjectType: "tenant", SubjectID: "tnntten-root"},
+				}, "def", nil).Once()
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+				assert.JSONEq(t, `{
+					"data": [
+						{"resource_type": "loadbalancer", "resource_id": "loadbal-lba", "relation": "owner", "subject_type": "tenant", "subject_id": "tnntten-root"},
+						{"resource_type": "loadbalancer", "resource_id": "loadbal-lbb", "relation": "owner", "subject_type": "tenant", "subject_id": "tnntten-root"}
+					],
+					"next_cursor": "def"
+				}`, res.Success.Body.String())
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input testInput) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine,
+			WithAdmin(AdminConfig{
+				Enabled:    input.enabled,
+				ResourceID: "tnntten-root",
+			}),
+		)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://127.0.0.1/api/v2/admin/relationships?"+input.query, nil)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+func TestRelationshipChangesStream(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	rel := types.RelationshipRecord{ResourceType: "loadbalancer", ResourceID: "loadbal-lba", Relation: "owner", SubjectType: "tenant", SubjectID: "tnntten-root"}
+
+	changes := []types.RelationshipChange{
+		{Operation: types.RelationshipChangeCreated, Relationship: rel, ZedToken: "first"},
+		{Operation: types.RelationshipChangeDeleted, Relationship: rel, ZedToken: "second"},
+	}
+
+	testCases := []testingx.TestCase[string, *httptest.ResponseRecorder]{
+		{
+			Name:  "Streamed",
+			Input: "abc",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(nil).Once()
+				engine.On("WatchRelationshipChanges", "abc").Return(changes, nil).Once()
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+				assert.Equal(t, "text/event-stream", res.Success.Header().Get(echo.HeaderContentType))
+
+				events := strings.Split(strings.TrimSpace(res.Success.Body.String()), "\n\n")
+				require.Len(t, events, 2)
+
+				assert.Equal(t, "id: first\nevent: created\n"+
+					`data: {"operation":"created","resource_type":"loadbalancer","resource_id":"loadbal-lba","relation":"owner","subject_type":"tenant","subject_id":"tnntten-root","zedtoken":"first"}`,
+					events[0])
+				assert.True(t, strings.HasPrefix(events[1], "id: second\nevent: deleted\n"))
+			},
+		},
+		{
+			Name:  "WatchFailed",
+			Input: "expired",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(nil).Once()
+				engine.On("WatchRelationshipChanges", "expired").Return([]types.RelationshipChange(nil), query.ErrInvalidArgument).Once()
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+				assert.Contains(t, res.Success.Body.String(), "event: error\n")
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, lastEventID string) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine,
+			WithAdmin(AdminConfig{
+				Enabled:    true,
+				ResourceID: "tnntten-root",
+			}),
+		)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://127.0.0.1/api/v2/admin/relationships/changes", nil)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+		req.Header.Set("Last-Event-ID", lastEventID)
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
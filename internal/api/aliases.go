@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// resolveResource returns the resource for a prefixed ID or a registered alias.
+func (r *Router) resolveResource(ctx context.Context, id string) (types.Resource, error) {
+	resource, err := r.engine.ResolveResource(ctx, id)
+	if err != nil {
+		if errors.Is(err, query.ErrResourceAliasNotFound) {
+			return types.Resource{}, echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("resource alias '%s' not found", id)).SetInternal(err)
+		}
+
+		return types.Resource{}, echo.NewHTTPError(http.StatusBadRequest, "error processing resource ID").SetInternal(err)
+	}
+
+	return resource, nil
+}
+
+// resourceAliasCreate registers an alias for a resource. Aliases change the
+// resource checks resolve to, so managing them requires the same permissions
+// as managing role-bindings.
+func (r *Router) resourceAliasCreate(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.resourceAliasCreate",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	var body resourceAliasRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, string(iapl.RoleBindingActionCreate), resource); err != nil {
+		return err
+	}
+
+	alias, err := r.engine.CreateResourceAlias(ctx, actor, resource, body.Alias)
+	if err != nil {
+		return r.errorResponse("error creating resource alias", err)
+	}
+
+	return c.JSON(http.StatusCreated, newResourceAliasResponse(alias))
+}
+
+func (r *Router) resourceAliasesList(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.resourceAliasesList",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, string(iapl.RoleBindingActionList), resource); err != nil {
+		return err
+	}
+
+	aliases, err := r.engine.ListResourceAliases(ctx, resource)
+	if err != nil {
+		return r.errorResponse("error listing resource aliases", err)
+	}
+
+	resp := listResourceAliasesResponse{
+		Data: make([]resourceAliasResponse, len(aliases)),
+	}
+
+	for i, alias := range aliases {
+		resp.Data[i] = newResourceAliasResponse(alias)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (r *Router) resourceAliasDelete(c echo.Context) error {
+	aliasStr, err := url.PathUnescape(c.Param("alias"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "error parsing resource alias").SetInternal(err)
+	}
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.resourceAliasDelete",
+		trace.WithAttributes(attribute.String("alias", aliasStr)),
+	)
+	defer span.End()
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	alias, err := r.engine.GetResourceAlias(ctx, aliasStr)
+	if err != nil {
+		return r.errorResponse("error getting resource alias", err)
+	}
+
+	resource, err := r.engine.NewResourceFromID(alias.ResourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, string(iapl.RoleBindingActionDelete), resource); err != nil {
+		return err
+	}
+
+	if err := r.engine.DeleteResourceAlias(ctx, alias.Alias); err != nil {
+		return r.errorResponse("error deleting resource alias", err)
+	}
+
+	return c.JSON(http.StatusOK, deleteResourceAliasResponse{Success: true})
+}
+
+func newResourceAliasResponse(alias types.ResourceAlias) resourceAliasResponse {
+	return resourceAliasResponse{
+		Alias:      alias.Alias,
+		ResourceID: alias.ResourceID,
+		CreatedBy:  alias.CreatedBy,
+		CreatedAt:  alias.CreatedAt.Format(time.RFC3339),
+	}
+}
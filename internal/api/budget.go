@@ -0,0 +1,64 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/echojwtx"
+
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+)
+
+const (
+	// SpiceDBCallsHeader is the response header with the number of SpiceDB calls made for the request.
+	SpiceDBCallsHeader = "X-SpiceDB-Calls"
+	// SpiceDBWindowCallsHeader is the response header with the number of SpiceDB calls made for the caller in the current window.
+	SpiceDBWindowCallsHeader = "X-SpiceDB-Calls-Window"
+	// SpiceDBBudgetRemainingHeader is the response header with the number of SpiceDB calls left for the caller in the current window.
+	SpiceDBBudgetRemainingHeader = "X-SpiceDB-Budget-Remaining"
+)
+
+// WithSpiceDBBudget accounts the SpiceDB calls made for each request to the
+// authenticated subject, the budget interceptors must be installed on the
+// engine's SpiceDB client.
+func WithSpiceDBBudget(budget *spicedbx.Budget) Option {
+	return func(r *Router) error {
+		r.budget = budget
+
+		return nil
+	}
+}
+
+// budgetMW accounts the SpiceDB calls made for the request to the
+// authenticated subject and reports them in the response headers, it must run
+// after the auth middleware.
+func (r *Router) budgetMW(next echo.HandlerFunc) echo.HandlerFunc {
+	if r.budget == nil {
+		return next
+	}
+
+	return func(c echo.Context) error {
+		subject := echojwtx.Actor(c)
+		if subject == "" {
+			return next(c)
+		}
+
+		ctx, account := spicedbx.WithCaller(c.Request().Context(), subject)
+
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		c.Response().Before(func() {
+			windowCalls := r.budget.WindowCalls(subject)
+
+			header := c.Response().Header()
+			header.Set(SpiceDBCallsHeader, strconv.FormatInt(account.Calls(), 10))
+			header.Set(SpiceDBWindowCallsHeader, strconv.FormatInt(windowCalls, 10))
+
+			if limit := r.budget.WindowLimit(); limit > 0 {
+				header.Set(SpiceDBBudgetRemainingHeader, strconv.FormatInt(max(limit-windowCalls, 0), 10))
+			}
+		})
+
+		return next(c)
+	}
+}
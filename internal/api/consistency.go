@@ -0,0 +1,31 @@
+package api
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+)
+
+// ConsistencyTokenHeader is the request header with a consistency token the
+// reads of the request must be at least as fresh as, and the response header
+// with the token of the relationships written by the request.
+const ConsistencyTokenHeader = "X-Consistency-Token"
+
+// consistencyMiddleware evaluates the reads of the request at least as fresh
+// as the consistency token passed by the caller, and returns the token of the
+// relationships written by the request, so callers can read their own writes.
+func consistencyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx, consistency := spicedbx.WithConsistency(c.Request().Context(), c.Request().Header.Get(ConsistencyTokenHeader))
+
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		c.Response().Before(func() {
+			if token := consistency.Written(); token != "" {
+				c.Response().Header().Set(ConsistencyTokenHeader, token)
+			}
+		})
+
+		return next(c)
+	}
+}
@@ -7,4 +7,10 @@ var (
 	ErrInvalidID = errors.New("invalid ID")
 	// ErrParsingRequestBody is returned when failing to parse the request body
 	ErrParsingRequestBody = errors.New("error parsing request body")
+	// ErrInvalidBulkRequest is returned when a bulk request is invalid
+	ErrInvalidBulkRequest = errors.New("invalid bulk request")
+	// ErrInvalidNamespace is returned when a namespace is misconfigured
+	ErrInvalidNamespace = errors.New("invalid namespace")
+	// ErrLimitExceeded is returned when a request exceeds a configured limit
+	ErrLimitExceeded = errors.New("request limit exceeded")
 )
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+)
+
+// setETag sets the ETag response header to the entity tag of the stored
+// version of a role or role-binding. It reports whether the If-None-Match
+// request header matches it, in which case the handler responds with 304 Not
+// Modified, so clients can cheaply revalidate cached responses.
+func setETag(c echo.Context, id gidx.PrefixedID, updatedAt time.Time) bool {
+	etag := query.ETag(id, updatedAt)
+
+	c.Response().Header().Set("ETag", etag)
+
+	ifNoneMatch := c.Request().Header.Get("If-None-Match")
+
+	return ifNoneMatch != "" && query.ETagMatches(ifNoneMatch, etag)
+}
+
+// ifMatchContext returns the context of an update which is only applied when
+// the If-Match request header, if any, matches the stored version, so clients
+// don't overwrite changes they haven't seen.
+func ifMatchContext(ctx context.Context, c echo.Context) context.Context {
+	if ifMatch := c.Request().Header.Get("If-Match"); ifMatch != "" {
+		return query.ContextWithIfMatch(ctx, ifMatch)
+	}
+
+	return ctx
+}
+
+// notModified responds to a request whose If-None-Match header matched.
+func notModified(c echo.Context) error {
+	return c.NoContent(http.StatusNotModified)
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/gidx"
+	"go.infratographer.com/x/viperx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// DefaultMaxFilterResources is the default maximum number of resources
+// filtered by a single request.
+const DefaultMaxFilterResources = 1000
+
+// FilterConfig configures filtering resources by permission.
+type FilterConfig struct {
+	// MaxResources is the maximum number of resources filtered by a single
+	// request, DefaultMaxFilterResources when unset.
+	MaxResources int
+}
+
+// WithFilter configures filtering resources by permission.
+func WithFilter(config FilterConfig) Option {
+	return func(r *Router) error {
+		if config.MaxResources > 0 {
+			r.maxFilterResources = config.MaxResources
+		}
+
+		return nil
+	}
+}
+
+type filterResourcesRequest struct {
+	// SubjectID is the subject whose access is filtered, the caller by default.
+	SubjectID   gidx.PrefixedID   `json:"subject_id,omitempty"`
+	Action      string            `json:"action" binding:"required"`
+	ResourceIDs []gidx.PrefixedID `json:"resource_ids" binding:"required"`
+	// Context holds values used to evaluate the caveats of role-bindings.
+	Context map[string]any `json:"context,omitempty"`
+}
+
+type filterResourcesResponse struct {
+	SubjectID   gidx.PrefixedID   `json:"subject_id"`
+	Action      string            `json:"action"`
+	ResourceIDs []gidx.PrefixedID `json:"resource_ids"`
+}
+
+// filterResources returns the subset of the given resources the subject is
+// allowed to do the action on, so services can post-filter their list results
+// in a single call. Subjects may filter by their own access, filtering by the
+// access of other subjects is limited to the resources the caller may list the
+// role-bindings of.
+func (r *Router) filterResources(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "api.filterResources")
+	defer span.End()
+
+	var reqBody filterResourcesRequest
+
+	if err := c.Bind(&reqBody); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	span.SetAttributes(
+		attribute.String("action", reqBody.Action),
+		attribute.Int("resources", len(reqBody.ResourceIDs)),
+	)
+
+	if reqBody.Action == "" {
+		return r.errorResponse("error filtering resources", fmt.Errorf("%w: action is required", ErrInvalidBulkRequest))
+	}
+
+	if err := checkLimit(LimitFilterResources, r.maxFilterResources, len(reqBody.ResourceIDs)); err != nil {
+		return r.errorResponse("error filtering resources", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	subject := actor
+
+	if reqBody.SubjectID != "" {
+		if subject, err = r.engine.NewResourceFromID(reqBody.SubjectID); err != nil {
+			return r.errorResponse("error creating subject resource", err)
+		}
+	}
+
+	resources := make([]types.Resource, len(reqBody.ResourceIDs))
+
+	for i, id := range reqBody.ResourceIDs {
+		if resources[i], err = r.engine.NewResourceFromID(id); err != nil {
+			return r.errorResponse("error creating resource", err)
+		}
+	}
+
+	if reqBody.Context != nil {
+		ctx = query.ContextWithCaveatContext(ctx, reqBody.Context)
+	}
+
+	if actor.ID != subject.ID {
+		span.SetAttributes(attribute.Stringer("subject_id", subject.ID))
+
+		if resources, err = r.engine.FilterAllowedResources(ctx, actor, string(iapl.RoleBindingActionList), resources); err != nil {
+			return r.errorResponse("error filtering resources", err)
+		}
+	}
+
+	allowed, err := r.engine.FilterAllowedResources(ctx, subject, reqBody.Action, resources)
+	if err != nil {
+		return r.errorResponse("error filtering resources", err)
+	}
+
+	resp := filterResourcesResponse{
+		SubjectID:   subject.ID,
+		Action:      reqBody.Action,
+		ResourceIDs: make([]gidx.PrefixedID, len(allowed)),
+	}
+
+	for i, resource := range allowed {
+		resp.ResourceIDs[i] = resource.ID
+	}
+
+	span.SetAttributes(attribute.Int("allowed", len(allowed)))
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// filterViperFlags sets the cobra flags and viper config for filtering resources.
+func filterViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Int("filter-max-resources", DefaultMaxFilterResources, "maximum number of resources filtered by permission in a single request")
+	viperx.MustBindFlag(v, "filter.maxresources", flags.Lookup("filter-max-resources"))
+}
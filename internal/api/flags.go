@@ -0,0 +1,16 @@
+package api
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// MustViperFlags sets the cobra flags and viper config for the API router.
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	rateLimitViperFlags(v, flags)
+	impersonationViperFlags(v, flags)
+	adminViperFlags(v, flags)
+	filterViperFlags(v, flags)
+	accessRequestsViperFlags(v, flags)
+	limitsViperFlags(v, flags)
+}
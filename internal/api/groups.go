@@ -0,0 +1,360 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func (r *Router) groupCreate(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.groupCreate",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	var body groupRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, string(iapl.GroupActionCreate), resource); err != nil {
+		return err
+	}
+
+	group, err := r.engine.CreateGroup(ctx, actor, resource, body.Name, body.Description)
+	if err != nil {
+		return r.errorResponse("error creating group", err)
+	}
+
+	return c.JSON(http.StatusCreated, newGroupResponse(group))
+}
+
+func (r *Router) groupsList(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.groupsList",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, string(iapl.GroupActionList), resource); err != nil {
+		return err
+	}
+
+	groups, err := r.engine.ListGroups(ctx, resource)
+	if err != nil {
+		return r.errorResponse("error listing groups", err)
+	}
+
+	resp := listGroupsResponse{
+		Data: make([]groupResponse, len(groups)),
+	}
+
+	for i, group := range groups {
+		resp.Data[i] = newGroupResponse(group)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// groupAuthorize parses the group ID of the request and checks the current
+// subject can perform the action on the group.
+func (r *Router) groupAuthorize(ctx context.Context, c echo.Context, action iapl.GroupAction) (types.Resource, gidx.PrefixedID, error) {
+	groupID, err := gidx.Parse(c.Param("group_id"))
+	if err != nil {
+		return types.Resource{}, "", r.errorResponse("error parsing group ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	group, err := r.engine.NewResourceFromID(groupID)
+	if err != nil {
+		return types.Resource{}, "", r.errorResponse("error creating group resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return types.Resource{}, "", err
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, string(action), group); err != nil {
+		return types.Resource{}, "", err
+	}
+
+	return actor, groupID, nil
+}
+
+func (r *Router) groupGet(c echo.Context) error {
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.groupGet",
+		trace.WithAttributes(attribute.String("id", c.Param("group_id"))),
+	)
+	defer span.End()
+
+	_, groupID, err := r.groupAuthorize(ctx, c, iapl.GroupActionGet)
+	if err != nil {
+		return err
+	}
+
+	group, err := r.engine.GetGroup(ctx, groupID)
+	if err != nil {
+		return r.errorResponse("error getting group", err)
+	}
+
+	return c.JSON(http.StatusOK, newGroupResponse(group))
+}
+
+func (r *Router) groupUpdate(c echo.Context) error {
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.groupUpdate",
+		trace.WithAttributes(attribute.String("id", c.Param("group_id"))),
+	)
+	defer span.End()
+
+	actor, groupID, err := r.groupAuthorize(ctx, c, iapl.GroupActionUpdate)
+	if err != nil {
+		return err
+	}
+
+	var body updateGroupRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	group, err := r.engine.GetGroup(ctx, groupID)
+	if err != nil {
+		return r.errorResponse("error getting group", err)
+	}
+
+	if body.Name != "" {
+		group.Name = body.Name
+	}
+
+	if body.Description != nil {
+		group.Description = *body.Description
+	}
+
+	group, err = r.engine.UpdateGroup(ctx, actor, groupID, group.Name, group.Description)
+	if err != nil {
+		return r.errorResponse("error updating group", err)
+	}
+
+	return c.JSON(http.StatusOK, newGroupResponse(group))
+}
+
+func (r *Router) groupDelete(c echo.Context) error {
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.groupDelete",
+		trace.WithAttributes(attribute.String("id", c.Param("group_id"))),
+	)
+	defer span.End()
+
+	_, groupID, err := r.groupAuthorize(ctx, c, iapl.GroupActionDelete)
+	if err != nil {
+		return err
+	}
+
+	if err := r.engine.DeleteGroup(ctx, groupID); err != nil {
+		return r.errorResponse("error deleting group", err)
+	}
+
+	return c.JSON(http.StatusOK, deleteGroupResponse{Success: true})
+}
+
+func (r *Router) groupMembersList(c echo.Context) error {
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.groupMembersList",
+		trace.WithAttributes(attribute.String("id", c.Param("group_id"))),
+	)
+	defer span.End()
+
+	_, groupID, err := r.groupAuthorize(ctx, c, iapl.GroupActionGet)
+	if err != nil {
+		return err
+	}
+
+	return r.groupMembersResponse(ctx, c, groupID)
+}
+
+// groupMembersExpand lists all direct and transitive members of a group, one
+// page at a time, so membership can be compared with an external source.
+func (r *Router) groupMembersExpand(c echo.Context) error {
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.groupMembersExpand",
+		trace.WithAttributes(attribute.String("id", c.Param("group_id"))),
+	)
+	defer span.End()
+
+	_, groupID, err := r.groupAuthorize(ctx, c, iapl.GroupActionGet)
+	if err != nil {
+		return err
+	}
+
+	members, err := r.engine.ExpandGroupMembers(ctx, groupID)
+	if err != nil {
+		return r.errorResponse("error expanding group members", err)
+	}
+
+	pagination := ParsePagination(c)
+	start, end := pagination.paginate(len(members))
+
+	resp := expandedGroupMembersResponse{
+		Data: make([]expandedGroupMemberResponse, 0, end-start),
+	}
+
+	for _, member := range members[start:end] {
+		resp.Data = append(resp.Data, expandedGroupMemberResponse{
+			ID:     member.Subject.ID,
+			Type:   member.Subject.Type,
+			Direct: slices.Contains(member.Groups, groupID),
+			Groups: member.Groups,
+		})
+	}
+
+	pagination.SetHeaders(c, len(members))
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (r *Router) groupMembersAdd(c echo.Context) error {
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.groupMembersAdd",
+		trace.WithAttributes(attribute.String("id", c.Param("group_id"))),
+	)
+	defer span.End()
+
+	_, groupID, err := r.groupAuthorize(ctx, c, iapl.GroupActionUpdate)
+	if err != nil {
+		return err
+	}
+
+	members, err := r.groupMembersFromRequest(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.engine.AddGroupMembers(ctx, groupID, members...); err != nil {
+		return r.errorResponse("error adding group members", err)
+	}
+
+	return r.groupMembersResponse(ctx, c, groupID)
+}
+
+func (r *Router) groupMembersRemove(c echo.Context) error {
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.groupMembersRemove",
+		trace.WithAttributes(attribute.String("id", c.Param("group_id"))),
+	)
+	defer span.End()
+
+	_, groupID, err := r.groupAuthorize(ctx, c, iapl.GroupActionUpdate)
+	if err != nil {
+		return err
+	}
+
+	members, err := r.groupMembersFromRequest(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.engine.RemoveGroupMembers(ctx, groupID, members...); err != nil {
+		return r.errorResponse("error removing group members", err)
+	}
+
+	return r.groupMembersResponse(ctx, c, groupID)
+}
+
+func (r *Router) groupMembersFromRequest(c echo.Context) ([]types.Resource, error) {
+	var body groupMembersRequest
+
+	if err := c.Bind(&body); err != nil {
+		return nil, r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	members := make([]types.Resource, len(body.MemberIDs))
+
+	for i, id := range body.MemberIDs {
+		member, err := r.engine.NewResourceFromID(id)
+		if err != nil {
+			return nil, r.errorResponse("error creating member resource", err)
+		}
+
+		members[i] = member
+	}
+
+	return members, nil
+}
+
+func (r *Router) groupMembersResponse(ctx context.Context, c echo.Context, groupID gidx.PrefixedID) error {
+	members, err := r.engine.ListGroupMembers(ctx, groupID)
+	if err != nil {
+		return r.errorResponse("error listing group members", err)
+	}
+
+	resp := groupMembersResponse{
+		Data: make([]gidx.PrefixedID, len(members)),
+	}
+
+	for i, member := range members {
+		resp.Data[i] = member.ID
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func newGroupResponse(group types.Group) groupResponse {
+	return groupResponse{
+		ID:          group.ID,
+		OwnerID:     group.OwnerID,
+		Name:        group.Name,
+		Description: group.Description,
+		CreatedBy:   group.CreatedBy,
+		UpdatedBy:   group.UpdatedBy,
+		CreatedAt:   group.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   group.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,64 @@
+package api_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/pkg/client"
+	"go.infratographer.com/permissions-api/pkg/permissionstest"
+)
+
+func TestHarnessRoles(t *testing.T) {
+	ctx := context.Background()
+
+	h := permissionstest.New(t, permissionstest.WithPolicyFile("../../policies/policy.example.yaml"))
+
+	engine := h.Engine()
+
+	var (
+		admin  = gidx.PrefixedID("idntusr-admin")
+		viewer = gidx.PrefixedID("idntusr-viewer")
+		tenant = gidx.PrefixedID("tnntten-root")
+		lb     = gidx.PrefixedID("loadbal-abc123")
+	)
+
+	require.NoError(t, engine.CreateRelationship(ctx, lb, "owner", tenant))
+
+	adminRole, err := engine.CreateRole(ctx, admin, tenant, "admin", []string{"role_create", "role_get", "loadbalancer_get"})
+	require.NoError(t, err)
+
+	_, err = engine.CreateRoleBinding(ctx, admin, tenant, adminRole, []gidx.PrefixedID{admin})
+	require.NoError(t, err)
+
+	adminClient, err := client.New(h.URL, client.WithToken(h.Token(t, admin.String())))
+	require.NoError(t, err)
+
+	viewerRole, err := adminClient.CreateRole(ctx, tenant, "viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
+	_, err = engine.CreateRoleBinding(ctx, admin, tenant, viewerRole.ID, []gidx.PrefixedID{viewer})
+	require.NoError(t, err)
+
+	viewerClient, err := client.New(h.URL, client.WithToken(h.Token(t, viewer.String())))
+	require.NoError(t, err)
+
+	allowed, err := viewerClient.Check(ctx, lb, "loadbalancer_get")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = viewerClient.Check(ctx, lb, "loadbalancer_delete")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	_, err = viewerClient.CreateRole(ctx, tenant, "other", []string{"loadbalancer_get"})
+	assert.ErrorIs(t, err, client.ErrPermissionDenied)
+
+	// roles created through the API are seen by the engine
+	allowed, err = engine.SubjectHasPermission(ctx, viewer, "loadbalancer_get", lb)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/gidx"
+	"go.infratographer.com/x/viperx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// ImpersonateSubjectHeader is the request header used to perform permission checks as another subject.
+	ImpersonateSubjectHeader = "X-Impersonate-Subject"
+
+	// DefaultImpersonationAction is the policy action required to impersonate subjects.
+	DefaultImpersonationAction = "iam_impersonate"
+
+	impersonatedSubjectKey = "permissions-api.impersonated-subject"
+)
+
+// ImpersonationConfig is the configuration for impersonating subjects in permission checks.
+type ImpersonationConfig struct {
+	// Enabled allows permission checks to be performed as another subject.
+	Enabled bool
+	// Action is the policy action the caller must have on ResourceID to impersonate subjects.
+	Action string
+	// ResourceID is the resource, usually the root tenant, on which Action is checked.
+	ResourceID gidx.PrefixedID
+}
+
+// impersonation authorizes requests to impersonate other subjects.
+type impersonation struct {
+	action   string
+	resource types.Resource
+}
+
+// WithImpersonation allows subjects with the configured action to perform
+// permission checks as another subject when enabled in the config.
+func WithImpersonation(config ImpersonationConfig) Option {
+	return func(r *Router) error {
+		if !config.Enabled {
+			return nil
+		}
+
+		resource, err := r.engine.NewResourceFromID(config.ResourceID)
+		if err != nil {
+			return fmt.Errorf("invalid impersonation resource %q: %w", config.ResourceID, err)
+		}
+
+		action := config.Action
+		if action == "" {
+			action = DefaultImpersonationAction
+		}
+
+		r.impersonation = &impersonation{
+			action:   action,
+			resource: resource,
+		}
+
+		return nil
+	}
+}
+
+// impersonationMW replaces the current subject with the subject in the
+// ImpersonateSubjectHeader header once the caller is authorized to impersonate
+// subjects. Every impersonated request is recorded in the audit log.
+func (r *Router) impersonationMW(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		targetIDStr := c.Request().Header.Get(ImpersonateSubjectHeader)
+		if targetIDStr == "" {
+			return next(c)
+		}
+
+		if r.impersonation == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "impersonation is not enabled")
+		}
+
+		ctx, span := tracer.Start(c.Request().Context(), "api.impersonate", trace.WithAttributes(attribute.String("subject_id", targetIDStr)))
+		defer span.End()
+
+		actor, err := r.currentSubject(c)
+		if err != nil {
+			return err
+		}
+
+		targetID, err := gidx.Parse(targetIDStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "error parsing impersonated subject ID").SetInternal(err)
+		}
+
+		target, err := r.engine.NewResourceFromID(targetID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "error processing impersonated subject ID").SetInternal(err)
+		}
+
+		audit := query.ContextLogger(c.Request().Context(), r.logger).Named("audit")
+
+		if err := r.checkActionWithResponse(ctx, actor, r.impersonation.action, r.impersonation.resource); err != nil {
+			audit.Warnw("impersonation denied",
+				"actor", actor.ID, "subject", target.ID,
+				"method", c.Request().Method, "path", c.Request().URL.Path,
+			)
+
+			return err
+		}
+
+		audit.Infow("impersonating subject",
+			"actor", actor.ID, "subject", target.ID,
+			"method", c.Request().Method, "path", c.Request().URL.Path,
+		)
+
+		c.Set(impersonatedSubjectKey, target)
+
+		return next(c)
+	}
+}
+
+// impersonationViperFlags sets the cobra flags and viper config for impersonation.
+func impersonationViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Bool("impersonation-enabled", false, "allow authorized subjects to perform permission checks as another subject")
+	viperx.MustBindFlag(v, "impersonation.enabled", flags.Lookup("impersonation-enabled"))
+
+	flags.String("impersonation-action", DefaultImpersonationAction, "policy action required to impersonate subjects")
+	viperx.MustBindFlag(v, "impersonation.action", flags.Lookup("impersonation-action"))
+
+	flags.String("impersonation-resource-id", "", "resource on which the impersonation action is checked, usually the root tenant")
+	viperx.MustBindFlag(v, "impersonation.resourceid", flags.Lookup("impersonation-resource-id"))
+}
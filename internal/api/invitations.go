@@ -0,0 +1,233 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// defaultInvitationDays is the number of days an invitation can be
+	// redeemed for, unless specified in the request.
+	defaultInvitationDays = 7
+
+	// maxInvitationDays is the maximum number of days an invitation can be
+	// redeemed for.
+	maxInvitationDays = 30
+)
+
+// invitationCreate creates an invitation to bind a role on a resource. The
+// response includes the invitation token, which is not returned again.
+func (r *Router) invitationCreate(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.invitationCreate",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	var body invitationRequest
+
+	err = c.Bind(&body)
+	if err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	days := defaultInvitationDays
+
+	if body.ExpiresInDays != 0 {
+		days = body.ExpiresInDays
+	}
+
+	if days < 1 || days > maxInvitationDays {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("expires_in_days must be between 1 and %d", maxInvitationDays))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	// redeeming an invitation creates a role-binding, so inviting requires the same permission
+	if err := r.checkActionWithResponse(ctx, actor, string(iapl.RoleBindingActionCreate), resource); err != nil {
+		return err
+	}
+
+	roleID, err := gidx.Parse(body.RoleID)
+	if err != nil {
+		return r.errorResponse("error parsing role ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	roleResource, err := r.engine.NewResourceFromID(roleID)
+	if err != nil {
+		return r.errorResponse("error creating role resource", err)
+	}
+
+	inv, token, err := r.engine.CreateInvitation(ctx, actor, resource, roleResource, body.Email, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		return r.errorResponse("error creating invitation", err)
+	}
+
+	resp := newInvitationResponse(inv)
+	resp.Token = token
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+func (r *Router) invitationsList(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.invitationsList",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, string(iapl.RoleBindingActionList), resource); err != nil {
+		return err
+	}
+
+	invitations, err := r.engine.ListInvitations(ctx, resource)
+	if err != nil {
+		return r.errorResponse("error listing invitations", err)
+	}
+
+	resp := listInvitationsResponse{
+		Data: make([]invitationResponse, len(invitations)),
+	}
+
+	for i, inv := range invitations {
+		resp.Data[i] = newInvitationResponse(inv)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (r *Router) invitationDelete(c echo.Context) error {
+	invitationIDStr := c.Param("invitation_id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.invitationDelete",
+		trace.WithAttributes(attribute.String("id", invitationIDStr)),
+	)
+	defer span.End()
+
+	invitationID, err := gidx.Parse(invitationIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing invitation ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	inv, err := r.engine.GetInvitation(ctx, invitationID)
+	if err != nil {
+		return r.errorResponse("error getting invitation", err)
+	}
+
+	resource, err := r.engine.NewResourceFromID(inv.ResourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, string(iapl.RoleBindingActionDelete), resource); err != nil {
+		return err
+	}
+
+	if err := r.engine.DeleteInvitation(ctx, invitationID); err != nil {
+		return r.errorResponse("error deleting invitation", err)
+	}
+
+	return c.JSON(http.StatusOK, deleteInvitationResponse{Success: true})
+}
+
+// invitationRedeem creates the role-binding of an invitation for the
+// authenticated subject. Holding the token is the authorization to redeem it.
+func (r *Router) invitationRedeem(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "api.invitationRedeem")
+	defer span.End()
+
+	var body redeemInvitationRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	subject, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	rb, err := r.engine.RedeemInvitation(ctx, subject, body.Token)
+	if err != nil {
+		return r.errorResponse("error redeeming invitation", err)
+	}
+
+	return c.JSON(
+		http.StatusCreated,
+		roleBindingResponse{
+			ID:         rb.ID,
+			ResourceID: rb.ResourceID,
+			SubjectIDs: rb.SubjectIDs,
+			RoleID:     rb.RoleID,
+			Caveat:     newRoleBindingCaveatResponse(rb.Caveat),
+
+			CreatedBy:  rb.CreatedBy,
+			UpdatedBy:  rb.UpdatedBy,
+			CreatedAt:  rb.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:  rb.UpdatedAt.Format(time.RFC3339),
+			LastUsedAt: formatLastUsed(rb.LastUsedAt),
+		},
+	)
+}
+
+func newInvitationResponse(inv types.Invitation) invitationResponse {
+	return invitationResponse{
+		ID:            inv.ID,
+		ResourceID:    inv.ResourceID,
+		RoleID:        inv.RoleID,
+		Email:         inv.Email,
+		CreatedBy:     inv.CreatedBy,
+		CreatedAt:     inv.CreatedAt.Format(time.RFC3339),
+		ExpiresAt:     inv.ExpiresAt.Format(time.RFC3339),
+		RedeemedBy:    inv.RedeemedBy,
+		RedeemedAt:    formatLastUsed(inv.RedeemedAt),
+		RoleBindingID: inv.RoleBindingID,
+	}
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/viperx"
+)
+
+const (
+	// DefaultMaxRoleActions is the default maximum number of actions of a role.
+	DefaultMaxRoleActions = 500
+	// DefaultMaxRoleNameLength is the default maximum length of a role name,
+	// the length of the name column in the database.
+	DefaultMaxRoleNameLength = 64
+	// DefaultMaxRoleBindingSubjects is the default maximum number of subjects
+	// of a single role-binding.
+	DefaultMaxRoleBindingSubjects = 1000
+	// DefaultMaxBulkRoleBindings is the default maximum number of role-bindings
+	// created and deleted in a single bulk request.
+	DefaultMaxBulkRoleBindings = 1000
+	// DefaultMaxBulkRelationshipWrites is the default maximum number of
+	// relationships written in a single bulk request, they are applied in chunks.
+	DefaultMaxBulkRelationshipWrites = 50000
+)
+
+// Limit names reported by LimitExceededError.
+const (
+	LimitRoleActions            = "role_actions"
+	LimitRoleNameLength         = "role_name_length"
+	LimitRoleBindingSubjects    = "role_binding_subjects"
+	LimitBulkRoleBindings       = "bulk_role_bindings"
+	LimitBulkRelationshipWrites = "bulk_relationship_writes"
+	LimitFilterResources        = "filter_resources"
+)
+
+// LimitsConfig configures the limits of request payloads, so oversized
+// requests are rejected before any SpiceDB write is made. Unset limits use
+// their defaults.
+type LimitsConfig struct {
+	// MaxRoleActions is the maximum number of actions of a role.
+	MaxRoleActions int
+	// MaxRoleNameLength is the maximum number of characters of a role name.
+	MaxRoleNameLength int
+	// MaxRoleBindingSubjects is the maximum number of subjects of a role-binding.
+	MaxRoleBindingSubjects int
+	// MaxBulkRoleBindings is the maximum number of role-bindings created and
+	// deleted in a single bulk request.
+	MaxBulkRoleBindings int
+	// MaxBulkRelationshipWrites is the maximum number of relationships written
+	// in a single bulk request.
+	MaxBulkRelationshipWrites int
+}
+
+// defaultLimits are the limits applied when not configured.
+var defaultLimits = LimitsConfig{
+	MaxRoleActions:            DefaultMaxRoleActions,
+	MaxRoleNameLength:         DefaultMaxRoleNameLength,
+	MaxRoleBindingSubjects:    DefaultMaxRoleBindingSubjects,
+	MaxBulkRoleBindings:       DefaultMaxBulkRoleBindings,
+	MaxBulkRelationshipWrites: DefaultMaxBulkRelationshipWrites,
+}
+
+// DefaultLimits returns the limits applied when not configured.
+func DefaultLimits() LimitsConfig {
+	return defaultLimits
+}
+
+// WithLimits configures the limits of request payloads.
+func WithLimits(config LimitsConfig) Option {
+	return func(r *Router) error {
+		setLimit(&r.limits.MaxRoleActions, config.MaxRoleActions)
+		setLimit(&r.limits.MaxRoleNameLength, config.MaxRoleNameLength)
+		setLimit(&r.limits.MaxRoleBindingSubjects, config.MaxRoleBindingSubjects)
+		setLimit(&r.limits.MaxBulkRoleBindings, config.MaxBulkRoleBindings)
+		setLimit(&r.limits.MaxBulkRelationshipWrites, config.MaxBulkRelationshipWrites)
+
+		return nil
+	}
+}
+
+// Limits returns the limits of request payloads, so other transports, such as
+// gRPC, enforce the same limits.
+func (r *Router) Limits() LimitsConfig {
+	return r.limits
+}
+
+// setLimit sets the limit to value when it is positive.
+func setLimit(limit *int, value int) {
+	if value > 0 {
+		*limit = value
+	}
+}
+
+// LimitExceededError is returned when a request exceeds a configured limit,
+// it wraps ErrLimitExceeded.
+type LimitExceededError struct {
+	// Limit is the name of the exceeded limit, e.g. role_actions.
+	Limit string
+	// Max is the maximum allowed.
+	Max int
+	// Actual is the size of the request.
+	Actual int
+}
+
+// Error implements the error interface.
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s: %s is %d, at most %d allowed", ErrLimitExceeded, e.Limit, e.Actual, e.Max)
+}
+
+// Unwrap returns ErrLimitExceeded.
+func (e *LimitExceededError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// checkLimit returns a LimitExceededError when actual exceeds maxAllowed.
+func checkLimit(limit string, maxAllowed, actual int) error {
+	if actual <= maxAllowed {
+		return nil
+	}
+
+	return &LimitExceededError{Limit: limit, Max: maxAllowed, Actual: actual}
+}
+
+// checkRoleLimits checks the name and actions of a role against the limits.
+func (r *Router) checkRoleLimits(name string, actions []string) error {
+	return r.limits.CheckRole(name, actions)
+}
+
+// CheckRole checks the name and actions of a role against the limits.
+func (c LimitsConfig) CheckRole(name string, actions []string) error {
+	if err := checkLimit(LimitRoleNameLength, c.MaxRoleNameLength, utf8.RuneCountInString(strings.TrimSpace(name))); err != nil {
+		return err
+	}
+
+	return checkLimit(LimitRoleActions, c.MaxRoleActions, len(actions))
+}
+
+// CheckRoleBindingSubjects checks the number of subjects of a role-binding
+// against the limits.
+func (c LimitsConfig) CheckRoleBindingSubjects(subjects int) error {
+	return checkLimit(LimitRoleBindingSubjects, c.MaxRoleBindingSubjects, subjects)
+}
+
+// limitsViperFlags sets the cobra flags and viper config for request limits.
+func limitsViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Int("limits-max-role-actions", DefaultMaxRoleActions, "maximum number of actions of a role")
+	viperx.MustBindFlag(v, "limits.maxroleactions", flags.Lookup("limits-max-role-actions"))
+
+	flags.Int("limits-max-role-name-length", DefaultMaxRoleNameLength, "maximum number of characters of a role name")
+	viperx.MustBindFlag(v, "limits.maxrolenamelength", flags.Lookup("limits-max-role-name-length"))
+
+	flags.Int("limits-max-role-binding-subjects", DefaultMaxRoleBindingSubjects, "maximum number of subjects of a role-binding")
+	viperx.MustBindFlag(v, "limits.maxrolebindingsubjects", flags.Lookup("limits-max-role-binding-subjects"))
+
+	flags.Int("limits-max-bulk-role-bindings", DefaultMaxBulkRoleBindings, "maximum number of role-bindings changed in a single bulk request")
+	viperx.MustBindFlag(v, "limits.maxbulkrolebindings", flags.Lookup("limits-max-bulk-role-bindings"))
+
+	flags.Int("limits-max-bulk-relationship-writes", DefaultMaxBulkRelationshipWrites, "maximum number of relationships written in a single bulk request")
+	viperx.MustBindFlag(v, "limits.maxbulkrelationshipwrites", flags.Lookup("limits-max-bulk-relationship-writes"))
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"go.infratographer.com/permissions-api/internal/query"
+)
+
+// NamespaceHeader is the request header selecting the namespace serving the request.
+const NamespaceHeader = "X-Permissions-Namespace"
+
+// namespacePathPrefix is the path prefix of routes served by a namespace, followed by its name.
+const namespacePathPrefix = "ns/"
+
+// WithNamespace serves the API of an additional namespace, backed by the
+// given engine, under /ns/<name>/api and to requests with the namespace
+// header set to the name. Requests without either are served by the
+// router's engine.
+func WithNamespace(name string, engine query.Engine) Option {
+	return func(r *Router) error {
+		if name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("%w: invalid namespace name %q", ErrInvalidNamespace, name)
+		}
+
+		if _, ok := r.namespaces[name]; ok {
+			return fmt.Errorf("%w: namespace %s defined more than once", ErrInvalidNamespace, name)
+		}
+
+		if r.namespaces == nil {
+			r.namespaces = make(map[string]query.Engine)
+		}
+
+		r.namespaces[name] = engine
+
+		return nil
+	}
+}
+
+// namespaceRoutes registers the API versions of every namespace under its path prefix.
+// The namespace routers share all configuration with r except for the engine.
+func (r *Router) namespaceRoutes(rg *echo.Group, validator *requestValidator) {
+	names := make([]string, 0, len(r.namespaces))
+
+	for name := range r.namespaces {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		nr := *r
+		nr.engine = r.namespaces[name]
+		nr.namespaces = nil
+
+		nr.versionRoutes(rg.Group(namespacePathPrefix+name+"/"), validator)
+	}
+}
+
+// namespaceMW serves requests with the namespace header set using the
+// routes of the namespace, as if they were made to its path prefix.
+func (r *Router) namespaceMW(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		name := c.Request().Header.Get(NamespaceHeader)
+		if name == "" {
+			return next(c)
+		}
+
+		if _, ok := r.namespaces[name]; !ok {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("unknown namespace %s", name))
+		}
+
+		path := "/" + namespacePathPrefix + name + "/" + strings.TrimPrefix(echo.GetPath(c.Request()), "/")
+
+		c.Echo().Router().Find(c.Request().Method, path, c)
+
+		return c.Handler()(c)
+	}
+}
+
+// routePath returns the route path of the request without its namespace prefix, e.g. /api/v2/policy.
+func routePath(c echo.Context) string {
+	path := "/" + strings.TrimPrefix(c.Path(), "/")
+
+	if rest, ok := strings.CutPrefix(path, "/"+namespacePathPrefix); ok {
+		if _, route, ok := strings.Cut(rest, "/"); ok {
+			return "/" + route
+		}
+	}
+
+	return path
+}
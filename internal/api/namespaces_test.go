@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testauth"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestNamespaceRouting(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	newEngine := func(namespace string) *mock.Engine {
+		engine := &mock.Engine{
+			Namespace: namespace,
+		}
+
+		engine.On("PolicyInfo").Return(types.PolicyInfo{
+			PolicyHash:     namespace,
+			SchemaHash:     namespace,
+			LiveSchemaHash: namespace,
+		}, nil)
+
+		return engine
+	}
+
+	router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, newEngine("infratographer"),
+		WithNamespace("infratographer", newEngine("infratographer")),
+		WithNamespace("staging", newEngine("staging")),
+	)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(echoTestLogger(t, e))
+
+	router.Routes(e.Group(""))
+
+	type testinput struct {
+		path      string
+		namespace string
+	}
+
+	type result struct {
+		code       int
+		policyHash string
+	}
+
+	testCases := []testingx.TestCase[testinput, result]{
+		{
+			Name: "Default",
+			Input: testinput{
+				path: "/api/v2/policy",
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusOK, res.Success.code)
+				assert.Equal(t, "infratographer", res.Success.policyHash)
+			},
+		},
+		{
+			Name: "PathPrefix",
+			Input: testinput{
+				path: "/ns/staging/api/v2/policy",
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusOK, res.Success.code)
+				assert.Equal(t, "staging", res.Success.policyHash)
+			},
+		},
+		{
+			Name: "Header",
+			Input: testinput{
+				path:      "/api/v3/policy",
+				namespace: "staging",
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusOK, res.Success.code)
+				assert.Equal(t, "staging", res.Success.policyHash)
+			},
+		},
+		{
+			Name: "UnknownHeader",
+			Input: testinput{
+				path:      "/api/v2/policy",
+				namespace: "production",
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusNotFound, res.Success.code)
+			},
+		},
+		{
+			Name: "UnknownPathPrefix",
+			Input: testinput{
+				path: "/ns/production/api/v2/policy",
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusNotFound, res.Success.code)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input testinput) testingx.TestResult[result] {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://127.0.0.1"+input.path, nil)
+		if err != nil {
+			return testingx.TestResult[result]{Err: err}
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+
+		if input.namespace != "" {
+			req.Header.Set(NamespaceHeader, input.namespace)
+		}
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		res := result{code: resp.Code}
+
+		if resp.Code == http.StatusOK {
+			var body policyResponse
+
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				return testingx.TestResult[result]{Err: err}
+			}
+
+			res.policyHash = body.PolicyHash
+		}
+
+		return testingx.TestResult[result]{Success: res}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+func TestWithNamespaceInvalid(t *testing.T) {
+	authsrv := testauth.NewServer(t)
+
+	engine := &mock.Engine{Namespace: "test"}
+
+	_, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine, WithNamespace("", engine))
+	assert.ErrorIs(t, err, ErrInvalidNamespace)
+
+	_, err = NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine, WithNamespace("a/b", engine))
+	assert.ErrorIs(t, err, ErrInvalidNamespace)
+
+	_, err = NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine,
+		WithNamespace("staging", engine),
+		WithNamespace("staging", engine),
+	)
+	assert.ErrorIs(t, err, ErrInvalidNamespace)
+}
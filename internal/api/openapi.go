@@ -0,0 +1,371 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+	"go.infratographer.com/x/versionx"
+)
+
+const openAPIVersion = "3.1.0"
+
+// apiOperation documents a single REST route. The OpenAPI specification
+// served by the API is generated from these operations and the typed
+// request / response structs, TestOpenAPIRoutesDocumented ensures every route
+// registered in Routes is listed here.
+type apiOperation struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Query       []string
+	Request     any
+	Response    any
+	Status      int
+}
+
+var apiOperations = []apiOperation{
+	// v1
+	{http.MethodPost, "/api/v1/resources/:id/roles", "createRole", "Create a role on a resource", nil, createRoleRequest{}, roleResponse{}, http.StatusCreated},
+	{http.MethodGet, "/api/v1/resources/:id/roles", "listRoles", "List roles on a resource", nil, nil, listRolesResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v1/resources/:id/relationships", "listResourceRelationships", "List relationships from a resource", nil, nil, listRelationshipsResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v1/relationships/from/:id", "listRelationshipsFrom", "List relationships from a resource", nil, nil, listRelationshipsResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v1/relationships/to/:id", "listRelationshipsTo", "List relationships to a resource", nil, nil, listRelationshipsResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v1/roles/:role_id", "getRole", "Get a role", nil, nil, roleResponse{}, http.StatusOK},
+	{http.MethodPatch, "/api/v1/roles/:role_id", "updateRole", "Update a role", nil, updateRoleRequest{}, roleResponse{}, http.StatusOK},
+	{http.MethodDelete, "/api/v1/roles/:id", "deleteRole", "Delete a role", nil, nil, deleteRoleResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v1/roles/:role_id/resource", "getRoleResource", "Get the resource a role belongs to", nil, nil, resourceResponse{}, http.StatusOK},
+	{http.MethodPost, "/api/v1/roles/:role_id/assignments", "createAssignment", "Assign a subject to a role", nil, createAssignmentRequest{}, createAssignmentResponse{}, http.StatusCreated},
+	{http.MethodDelete, "/api/v1/roles/:role_id/assignments", "deleteAssignment", "Unassign a subject from a role", nil, deleteAssignmentRequest{}, deleteAssignmentResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v1/roles/:role_id/assignments", "listAssignments", "List the subjects assigned to a role", nil, nil, listAssignmentsResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v1/allow", "checkAction", "Check a single action on a resource", []string{"resource", "action"}, nil, map[string]any{}, http.StatusOK},
+	{http.MethodPost, "/api/v1/allow", "checkAllActions", "Check multiple actions on resources", nil, checkPermissionsRequest{}, map[string]any{}, http.StatusOK},
+
+	// v2
+	{http.MethodPost, "/api/v2/resources/:id/roles", "createRoleV2", "Create a role owned by a resource", nil, createRoleRequest{}, roleResponse{}, http.StatusCreated},
+	{http.MethodGet, "/api/v2/resources/:id/roles", "listRolesV2", "List roles available to a resource", nil, nil, listRolesV2Response{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/roles/:role_id", "getRoleV2", "Get a role", nil, nil, roleResponse{}, http.StatusOK},
+	{http.MethodPatch, "/api/v2/roles/:role_id", "updateRoleV2", "Update a role", nil, updateRoleRequest{}, roleResponse{}, http.StatusOK},
+	{http.MethodDelete, "/api/v2/roles/:id", "deleteRoleV2", "Delete a role", nil, nil, deleteRoleResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/resources/:id/role-bindings", "listRoleBindings", "List role-bindings on a resource", nil, nil, listRoleBindingsResponse{}, http.StatusOK},
+	{http.MethodPost, "/api/v2/resources/:id/role-bindings", "createRoleBinding", "Create a role-binding on a resource", nil, roleBindingRequest{}, roleBindingResponse{}, http.StatusCreated},
+	{http.MethodPost, "/api/v2/resources/:id/role-bindings/bulk", "bulkRoleBindings", "Create and delete many role-bindings on a resource", nil, bulkRoleBindingsRequest{}, bulkRoleBindingsResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/resources/:id/role-bindings/stale", "listStaleRoleBindings", "List role-bindings on a resource unused for a number of days", []string{"days"}, nil, listStaleRoleBindingsResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/resources/:id/role-bindings/suggestions", "listRoleBindingSuggestions", "List role-bindings on a resource granting actions unused for a number of days", nil, nil, listRoleBindingSuggestionsResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/resources/:id/subjects/:subject_id/permissions", "listSubjectPermissions", "List all actions a subject can perform on a resource", nil, nil, subjectPermissionsResponse{}, http.StatusOK},
+	{http.MethodPost, "/api/v2/allow/filter", "filterResources", "Filter resources by the access of a subject to an action", nil, filterResourcesRequest{}, filterResourcesResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/role-bindings/:rb_id", "getRoleBinding", "Get a role-binding", nil, nil, roleBindingResponse{}, http.StatusOK},
+	{http.MethodDelete, "/api/v2/role-bindings/:rb_id", "deleteRoleBinding", "Delete a role-binding", nil, nil, deleteRoleBindingResponse{}, http.StatusOK},
+	{http.MethodPatch, "/api/v2/role-bindings/:rb_id", "updateRoleBinding", "Update the subjects of a role-binding", nil, rolebindingUpdateRequest{}, roleBindingResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/subjects/:id/role-bindings", "listSubjectRoleBindings", "List the role-bindings of a subject across resources", nil, nil, listRoleBindingsResponse{}, http.StatusOK},
+	{http.MethodPost, "/api/v2/resources/:id/invitations", "createInvitation", "Create an invitation to bind a role on a resource", nil, invitationRequest{}, invitationResponse{}, http.StatusCreated},
+	{http.MethodGet, "/api/v2/resources/:id/invitations", "listInvitations", "List the invitations on a resource", nil, nil, listInvitationsResponse{}, http.StatusOK},
+	{http.MethodDelete, "/api/v2/invitations/:invitation_id", "deleteInvitation", "Delete an invitation", nil, nil, deleteInvitationResponse{}, http.StatusOK},
+	{http.MethodPost, "/api/v2/invitations/redeem", "redeemInvitation", "Redeem an invitation, binding its role to the authenticated subject", nil, redeemInvitationRequest{}, roleBindingResponse{}, http.StatusCreated},
+	{http.MethodPost, "/api/v2/access-requests", "createAccessRequest", "Request a role on a resource for the authenticated subject", nil, accessRequestRequest{}, accessRequestResponse{}, http.StatusCreated},
+	{http.MethodGet, "/api/v2/resources/:id/access-requests", "listAccessRequests", "List the access requests for a resource", nil, nil, listAccessRequestsResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/access-requests/:request_id", "getAccessRequest", "Get an access request", nil, nil, accessRequestResponse{}, http.StatusOK},
+	{http.MethodPost, "/api/v2/access-requests/:request_id/approve", "approveAccessRequest", "Approve an access request, creating its role-binding", nil, accessRequestDecisionRequest{}, accessRequestResponse{}, http.StatusOK},
+	{http.MethodPost, "/api/v2/access-requests/:request_id/deny", "denyAccessRequest", "Deny an access request", nil, accessRequestDecisionRequest{}, accessRequestResponse{}, http.StatusOK},
+	{http.MethodPost, "/api/v2/resources/:id/groups", "createGroup", "Create a group owned by a resource", nil, groupRequest{}, groupResponse{}, http.StatusCreated},
+	{http.MethodGet, "/api/v2/resources/:id/groups", "listGroups", "List the groups owned by a resource", nil, nil, listGroupsResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/groups/:group_id", "getGroup", "Get a group", nil, nil, groupResponse{}, http.StatusOK},
+	{http.MethodPatch, "/api/v2/groups/:group_id", "updateGroup", "Update the name and description of a group", nil, updateGroupRequest{}, groupResponse{}, http.StatusOK},
+	{http.MethodDelete, "/api/v2/groups/:group_id", "deleteGroup", "Delete a group", nil, nil, deleteGroupResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/groups/:group_id/members", "listGroupMembers", "List the direct members of a group", nil, nil, groupMembersResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/groups/:group_id/members/expanded", "expandGroupMembers", "List the direct and transitive members of a group, paginated with page and limit", nil, nil, expandedGroupMembersResponse{}, http.StatusOK},
+	{http.MethodPost, "/api/v2/groups/:group_id/members", "addGroupMembers", "Add members to a group", nil, groupMembersRequest{}, groupMembersResponse{}, http.StatusOK},
+	{http.MethodDelete, "/api/v2/groups/:group_id/members", "removeGroupMembers", "Remove members from a group", nil, groupMembersRequest{}, groupMembersResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/resources/:id/settings", "getTenantSettings", "Get the RBAC settings of a tenant", nil, nil, tenantSettingsResponse{}, http.StatusOK},
+	{http.MethodPatch, "/api/v2/resources/:id/settings", "updateTenantSettings", "Update the RBAC settings of a tenant", nil, tenantSettingsRequest{}, tenantSettingsResponse{}, http.StatusOK},
+	{http.MethodPost, "/api/v2/resources/:id/aliases", "createResourceAlias", "Register an alias, such as an external URN, for a resource", nil, resourceAliasRequest{}, resourceAliasResponse{}, http.StatusCreated},
+	{http.MethodGet, "/api/v2/resources/:id/aliases", "listResourceAliases", "List the aliases of a resource", nil, nil, listResourceAliasesResponse{}, http.StatusOK},
+	{http.MethodDelete, "/api/v2/aliases/:alias", "deleteResourceAlias", "Delete a resource alias", nil, nil, deleteResourceAliasResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/actions", "listActions", "List all actions defined by the policy", nil, nil, []string{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/actions/groups", "listActionGroups", "List the action groups defined by the policy", nil, nil, []actionGroupResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/policy", "getPolicy", "Get the hashes of the loaded policy and its schema, and whether SpiceDB's schema matches", nil, nil, policyResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/admin/relationships", "readRelationships", "Read a page of the relationships stored in SpiceDB matching the filters", []string{"resource_type", "resource_id", "relation", "subject_type", "subject_id", "limit", "cursor"}, nil, readRelationshipsResponse{}, http.StatusOK},
+	{http.MethodPost, "/api/v2/admin/relationships/bulk", "bulkWriteRelationships", "Create and delete many relationships, applied in chunks if the preconditions hold", nil, bulkRelationshipsRequest{}, bulkRelationshipsResponse{}, http.StatusOK},
+	{http.MethodGet, "/api/v2/admin/relationships/changes", "streamRelationshipChanges", "Stream relationship changes as server-sent events, resuming after the Last-Event-ID header or after query parameter", nil, nil, relationshipChangeResponse{}, http.StatusOK},
+}
+
+// documentedOperations are all operations included in the OpenAPI specification,
+// v3 serves the v2 handlers so its operations are derived from v2.
+var documentedOperations = append(append([]apiOperation{}, apiOperations...), versionedOperations(apiOperations, "v2", "v3")...)
+
+// versionedOperations copies the operations of the from API version to the to API version.
+func versionedOperations(operations []apiOperation, from, to string) []apiOperation {
+	fromPrefix := "/api/" + from + "/"
+	suffix := strings.ToUpper(to)
+
+	var out []apiOperation
+
+	for _, op := range operations {
+		if !strings.HasPrefix(op.Path, fromPrefix) {
+			continue
+		}
+
+		op.Path = "/api/" + to + "/" + strings.TrimPrefix(op.Path, fromPrefix)
+		op.OperationID = strings.TrimSuffix(op.OperationID, strings.ToUpper(from)) + suffix
+		op.Query = append([]string{}, op.Query...)
+
+		out = append(out, op)
+	}
+
+	return out
+}
+
+// errorResponse returns the error response body of the operation's API version.
+func (op apiOperation) errorResponse() any {
+	if strings.HasPrefix(op.Path, "/api/v1/") || strings.HasPrefix(op.Path, "/api/v2/") {
+		return ErrorResponse{}
+	}
+
+	return StructuredErrorResponse{}
+}
+
+var (
+	openAPISpecOnce sync.Once
+	openAPISpec     map[string]any
+)
+
+// openAPI serves the generated OpenAPI specification.
+func (r *Router) openAPI(c echo.Context) error {
+	openAPISpecOnce.Do(func() {
+		openAPISpec = buildOpenAPISpec(documentedOperations)
+	})
+
+	return c.JSON(http.StatusOK, openAPISpec)
+}
+
+func buildOpenAPISpec(operations []apiOperation) map[string]any {
+	gen := &schemaGenerator{components: map[string]any{}}
+
+	paths := map[string]map[string]any{}
+
+	for _, op := range operations {
+		path, params := openAPIPath(op.Path)
+
+		for _, q := range op.Query {
+			params = append(params, map[string]any{
+				"name":     q,
+				"in":       "query",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+
+		operation := map[string]any{
+			"operationId": op.OperationID,
+			"summary":     op.Summary,
+			"security":    []map[string][]string{{"bearerAuth": {}}},
+		}
+
+		operation["responses"] = map[string]any{
+			strconv.Itoa(op.Status): map[string]any{
+				"description": http.StatusText(op.Status),
+				"content": map[string]any{
+					echo.MIMEApplicationJSON: map[string]any{"schema": gen.schema(reflect.TypeOf(op.Response))},
+				},
+			},
+			"default": map[string]any{
+				"description": "error",
+				"content": map[string]any{
+					echo.MIMEApplicationJSON: map[string]any{"schema": gen.schema(reflect.TypeOf(op.errorResponse()))},
+				},
+			},
+		}
+
+		if len(params) != 0 {
+			operation["parameters"] = params
+		}
+
+		if op.Request != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					echo.MIMEApplicationJSON: map[string]any{"schema": gen.schema(reflect.TypeOf(op.Request))},
+				},
+			}
+		}
+
+		if _, ok := paths[path]; !ok {
+			paths[path] = map[string]any{}
+		}
+
+		paths[path][strings.ToLower(op.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":       "Permissions API",
+			"description": "Permissions API is an API to manage permissions for infratographer.",
+			"version":     versionx.BuildDetails().Version,
+			"license": map[string]any{
+				"name": "Apache 2.0",
+				"url":  "https://www.apache.org/licenses/LICENSE-2.0.html",
+			},
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": gen.components,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+			},
+		},
+	}
+}
+
+// openAPIPath converts an echo path into an OpenAPI path and its path parameters.
+func openAPIPath(path string) (string, []map[string]any) {
+	parts := strings.Split(path, "/")
+	params := []map[string]any{}
+
+	for i, part := range parts {
+		if !strings.HasPrefix(part, ":") {
+			continue
+		}
+
+		name := strings.TrimPrefix(part, ":")
+		parts[i] = "{" + name + "}"
+
+		params = append(params, map[string]any{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "string"},
+		})
+	}
+
+	return strings.Join(parts, "/"), params
+}
+
+type schemaGenerator struct {
+	components map[string]any
+}
+
+var (
+	prefixedIDType = reflect.TypeOf(gidx.PrefixedID(""))
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// schema returns the JSON schema for the given type, struct types are added to
+// the components and referenced.
+func (g *schemaGenerator) schema(t reflect.Type) map[string]any {
+	if t == nil {
+		return map[string]any{"type": "object"}
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == prefixedIDType:
+		return map[string]any{"type": "string", "description": "prefixed ID"}
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.structRef(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+func (g *schemaGenerator) structRef(t reflect.Type) map[string]any {
+	name := componentName(t.Name())
+	ref := map[string]any{"$ref": "#/components/schemas/" + name}
+
+	if _, ok := g.components[name]; ok {
+		return ref
+	}
+
+	// reserve the name to support recursive types
+	g.components[name] = nil
+
+	properties := map[string]any{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		switch jsonName {
+		case "-":
+			continue
+		case "":
+			jsonName = field.Name
+		}
+
+		fieldSchema := g.schema(field.Type)
+
+		// required strings must not be empty
+		if field.Tag.Get("binding") == "required" && field.Type.Kind() == reflect.String {
+			fieldSchema["minLength"] = 1
+		}
+
+		properties[jsonName] = fieldSchema
+
+		// only fields the handlers require are required, fields without
+		// omitempty are optional in requests all the same
+		if field.Tag.Get("binding") == "required" {
+			required = append(required, jsonName)
+		}
+	}
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	if len(required) != 0 {
+		schema["required"] = required
+	}
+
+	g.components[name] = schema
+
+	return ref
+}
+
+func componentName(name string) string {
+	if name == "" {
+		return name
+	}
+
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+
+	return string(r)
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testauth"
+)
+
+func TestOpenAPIRoutesDocumented(t *testing.T) {
+	authsrv := testauth.NewServer(t)
+
+	router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, &mock.Engine{})
+	require.NoError(t, err)
+
+	e := echo.New()
+	router.Routes(e.Group(""))
+
+	documented := map[string]bool{}
+
+	for _, op := range documentedOperations {
+		documented[op.Method+" "+op.Path] = true
+	}
+
+	for _, route := range e.Routes() {
+		path := "/" + strings.TrimPrefix(route.Path, "/")
+
+		if route.Method == echo.RouteNotFound || strings.HasSuffix(path, "/*") || strings.HasSuffix(path, "openapi.json") {
+			continue
+		}
+
+		if !strings.HasPrefix(path, "/api/") {
+			continue
+		}
+
+		assert.True(t, documented[route.Method+" "+path], "route %s %s is not documented in apiOperations", route.Method, path)
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	authsrv := testauth.NewServer(t)
+
+	router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, &mock.Engine{})
+	require.NoError(t, err)
+
+	e := echo.New()
+	router.Routes(e.Group(""))
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/api/v1/openapi.json", nil)
+	require.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+
+	e.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var spec struct {
+		OpenAPI string                               `json:"openapi"`
+		Paths   map[string]map[string]map[string]any `json:"paths"`
+	}
+
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&spec))
+
+	assert.Equal(t, openAPIVersion, spec.OpenAPI)
+	require.Contains(t, spec.Paths, "/api/v2/roles/{role_id}")
+	assert.Contains(t, spec.Paths["/api/v2/roles/{role_id}"], "patch")
+	assert.Contains(t, spec.Paths["/api/v2/roles/{role_id}"]["patch"], "requestBody")
+	require.Contains(t, spec.Paths, "/api/v3/roles/{role_id}")
+	assert.Equal(t, "getRoleV3", spec.Paths["/api/v3/roles/{role_id}"]["get"]["operationId"])
+}
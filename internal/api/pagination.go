@@ -78,14 +78,22 @@ func parseLimit(l int) int {
 	return limit
 }
 
-// func (p *Pagination) offset() int {
-// 	page := p.Page
-// 	if page == 0 {
-// 		page = 1
-// 	}
-//
-// 	return (page - 1) * p.Limit
-// }
+func (p *Pagination) offset() int {
+	page := p.Page
+	if page <= 0 {
+		page = 1
+	}
+
+	return (page - 1) * p.Limit
+}
+
+// paginate returns the bounds of the current page in a list of count records.
+func (p *Pagination) paginate(count int) (int, int) {
+	start := min(p.offset(), count)
+	end := min(start+p.Limit, count)
+
+	return start, end
+}
 
 // SetHeaders sets the pagination headers on a response
 func (p *Pagination) SetHeaders(c echo.Context, count int) {
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -9,10 +10,14 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/multierr"
 
+	"go.infratographer.com/permissions-api/internal/iapl"
 	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
 	"go.infratographer.com/permissions-api/internal/types"
 )
 
@@ -38,33 +43,24 @@ var (
 // Note that this expects a JWT token to be present in the request. This token must
 // contain the subject of the request in the "sub" claim.
 //
-// The following query parameters are required:
+// The following query parameters are required, their presence is validated by
+// the request validator:
 // - resource: the resource ID to check
 // - action: the action to check
+//
+// The optional context query parameter holds a JSON object of values used to
+// evaluate the caveats of role-bindings.
 func (r *Router) checkAction(c echo.Context) error {
 	ctx, span := tracer.Start(c.Request().Context(), "api.checkAction")
 	defer span.End()
 
-	action, hasQuery := getParam(c, "action")
-	if !hasQuery {
-		return echo.NewHTTPError(http.StatusBadRequest, "missing action query parameter")
-	}
-
-	// Optional query parameters
-	resourceIDStr, hasResourceParam := getParam(c, "resource")
-	if !hasResourceParam {
-		return echo.NewHTTPError(http.StatusBadRequest, "missing resource query parameter")
-	}
+	action := c.QueryParam("action")
+	resourceIDStr := c.QueryParam("resource")
 
-	// Query parameter validation
-	resourceID, err := gidx.Parse(resourceIDStr)
+	// Query parameter validation, the resource may be a registered alias
+	resource, err := r.resolveResource(ctx, resourceIDStr)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "error processing resource ID").SetInternal(err)
-	}
-
-	resource, err := r.engine.NewResourceFromID(resourceID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "error processing tenant resource ID").SetInternal(err)
+		return err
 	}
 
 	// Subject validation
@@ -73,6 +69,16 @@ func (r *Router) checkAction(c echo.Context) error {
 		return err
 	}
 
+	if contextStr := c.QueryParam("context"); contextStr != "" {
+		var caveatContext map[string]any
+
+		if err := json.Unmarshal([]byte(contextStr), &caveatContext); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "error parsing context").SetInternal(err)
+		}
+
+		ctx = query.ContextWithCaveatContext(ctx, caveatContext)
+	}
+
 	// Check the permissions
 	if err := r.checkActionWithResponse(ctx, subjectResource, action, resource); err != nil {
 		return err
@@ -102,6 +108,12 @@ func (r *Router) checkActionWithResponse(ctx context.Context, subjectResource ty
 		)
 
 		return echo.NewHTTPError(http.StatusBadRequest, msg).SetInternal(err)
+	case errors.Is(err, query.ErrInvalidArgument):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	case errors.Is(err, spicedbx.ErrorBudgetExceeded):
+		return echo.NewHTTPError(http.StatusTooManyRequests, err.Error()).SetInternal(err)
+	case errors.Is(err, spicedbx.ErrorCircuitOpen):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error()).SetInternal(err)
 	case err != nil:
 		return echo.NewHTTPError(http.StatusInternalServerError, "an error occurred checking permissions").SetInternal(err)
 	default:
@@ -109,13 +121,85 @@ func (r *Router) checkActionWithResponse(ctx context.Context, subjectResource ty
 	}
 }
 
+// subjectPermissions returns all actions a subject can do on a resource.
+// Subjects may list their own actions, listing the actions of other subjects
+// requires permission to list the role-bindings on the resource.
+func (r *Router) subjectPermissions(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+	subjectIDStr := c.Param("subject_id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.subjectPermissions",
+		trace.WithAttributes(
+			attribute.String("id", resourceIDStr),
+			attribute.String("subject_id", subjectIDStr),
+		),
+	)
+	defer span.End()
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	subjectID, err := gidx.Parse(subjectIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing subject ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	subject, err := r.engine.NewResourceFromID(subjectID)
+	if err != nil {
+		return r.errorResponse("error creating subject resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if actor.ID != subject.ID {
+		if err := r.checkActionWithResponse(ctx, actor, string(iapl.RoleBindingActionList), resource); err != nil {
+			return err
+		}
+	}
+
+	actions, err := r.engine.SubjectAllowedActions(ctx, subject, resource)
+	if err != nil {
+		return r.errorResponse("error listing permissions", err)
+	}
+
+	resp := subjectPermissionsResponse{
+		ResourceID: resource.ID,
+		SubjectID:  subject.ID,
+		Actions:    actions,
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+const (
+	// checkModeAll requires all actions of a check request to be allowed.
+	checkModeAll = "all"
+	// checkModeAny requires at least one action of a check request to be allowed.
+	checkModeAny = "any"
+)
+
 type checkPermissionsRequest struct {
 	Actions []checkAction `json:"actions"`
+	// Mode is either "all" (the default) or "any".
+	Mode string `json:"mode,omitempty"`
+	// Context holds values used to evaluate the caveats of role-bindings.
+	Context map[string]any `json:"context,omitempty"`
 }
 
 type checkAction struct {
-	ResourceID string `json:"resource_id"`
-	Action     string `json:"action"`
+	ResourceID string `json:"resource_id" binding:"required"`
+	Action     string `json:"action" binding:"required"`
 }
 
 type checkRequest struct {
@@ -131,9 +215,10 @@ type checkResult struct {
 
 // checkAllActions will check if a subject is allowed to perform an action on a list of resources.
 // This is the permissions check endpoint.
-// It will return a 200 if the subject is allowed to perform all requested resource actions.
+// It will return a 200 if the subject is allowed to perform all requested resource actions,
+// or any of them when the request mode is "any".
 // It will return a 400 if the request is invalid.
-// It will return a 403 if the subject is not allowed to perform all requested resource actions.
+// It will return a 403 if the subject is not allowed to perform the requested resource actions.
 //
 // Note that this expects a JWT token to be present in the request. This token must
 // contain the subject of the request in the "sub" claim.
@@ -153,6 +238,16 @@ func (r *Router) checkAllActions(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "error parsing request body").SetInternal(err)
 	}
 
+	switch reqBody.Mode {
+	case "", checkModeAll, checkModeAny:
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid check mode '%s'", reqBody.Mode))
+	}
+
+	if reqBody.Context != nil {
+		ctx = query.ContextWithCaveatContext(ctx, reqBody.Context)
+	}
+
 	var errs []error
 
 	requestsCh := make(chan checkRequest, len(reqBody.Actions))
@@ -164,16 +259,9 @@ func (r *Router) checkAllActions(c echo.Context) error {
 			continue
 		}
 
-		resourceID, err := gidx.Parse(check.ResourceID)
-		if err != nil {
-			errs = append(errs, fmt.Errorf("check %d: %w: error parsing resource id: %s", i, err, check.ResourceID))
-
-			continue
-		}
-
-		resource, err := r.engine.NewResourceFromID(resourceID)
+		resource, err := r.engine.ResolveResource(ctx, check.ResourceID)
 		if err != nil {
-			errs = append(errs, fmt.Errorf("check %d: %w: error creating resource from id: %s", i, err, resourceID.String()))
+			errs = append(errs, fmt.Errorf("check %d: %w: error resolving resource id: %s", i, err, check.ResourceID))
 
 			continue
 		}
@@ -229,13 +317,18 @@ func (r *Router) checkAllActions(c echo.Context) error {
 		badRequestErrors   int
 		unauthorizedErrors int
 		internalErrors     int
+		budgetErrors       int
+		unavailableErrors  int
+		allowed            int
 		allErrors          []error
 	)
 
 	for i := 0; i < len(reqBody.Actions); i++ {
 		select {
 		case result := <-resultsCh:
-			if result.Error != nil {
+			if result.Error == nil {
+				allowed++
+			} else {
 				switch {
 				case errors.Is(result.Error, query.ErrActionNotAssigned):
 					err := fmt.Errorf(
@@ -249,7 +342,7 @@ func (r *Router) checkAllActions(c echo.Context) error {
 					unauthorizedErrors++
 
 					allErrors = append(allErrors, err)
-				case errors.Is(result.Error, query.ErrInvalidAction):
+				case errors.Is(result.Error, query.ErrInvalidAction), errors.Is(result.Error, query.ErrInvalidArgument):
 					err := fmt.Errorf(
 						"%w: invalid action '%s' for resource '%s'",
 						result.Error,
@@ -259,6 +352,18 @@ func (r *Router) checkAllActions(c echo.Context) error {
 
 					badRequestErrors++
 
+					allErrors = append(allErrors, err)
+				case errors.Is(result.Error, spicedbx.ErrorBudgetExceeded):
+					err := fmt.Errorf("check %d: %w", result.Request.Index, result.Error)
+
+					budgetErrors++
+
+					allErrors = append(allErrors, err)
+				case errors.Is(result.Error, spicedbx.ErrorCircuitOpen):
+					err := fmt.Errorf("check %d: %w", result.Request.Index, result.Error)
+
+					unavailableErrors++
+
 					allErrors = append(allErrors, err)
 				default:
 					err := fmt.Errorf("check %d: %w", result.Request.Index, result.Error)
@@ -277,6 +382,15 @@ func (r *Router) checkAllActions(c echo.Context) error {
 		}
 	}
 
+	// a single allowed action is enough, whatever happened to the others
+	if reqBody.Mode == checkModeAny && allowed != 0 {
+		return nil
+	}
+
+	if reqBody.Mode == checkModeAny && len(allErrors) == 0 {
+		return echo.NewHTTPError(http.StatusForbidden, "no actions requested")
+	}
+
 	if internalErrors != 0 {
 		combined := multierr.Combine(allErrors...)
 		span.SetStatus(codes.Error, combined.Error())
@@ -284,6 +398,14 @@ func (r *Router) checkAllActions(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "an error occurred checking permissions").SetInternal(combined)
 	}
 
+	if unavailableErrors != 0 {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, spicedbx.ErrorCircuitOpen.Error()).SetInternal(multierr.Combine(allErrors...))
+	}
+
+	if budgetErrors != 0 {
+		return echo.NewHTTPError(http.StatusTooManyRequests, spicedbx.ErrorBudgetExceeded.Error()).SetInternal(multierr.Combine(allErrors...))
+	}
+
 	if unauthorizedErrors != 0 {
 		msg := fmt.Sprintf(
 			"subject '%s' does not have permission to the requested resource actions",
@@ -299,16 +421,3 @@ func (r *Router) checkAllActions(c echo.Context) error {
 
 	return nil
 }
-
-func getParam(c echo.Context, name string) (string, bool) {
-	values, ok := c.QueryParams()[name]
-	if !ok {
-		return "", ok
-	}
-
-	if len(values) == 0 {
-		return "", true
-	}
-
-	return values[0], true
-}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testauth"
+	"go.infratographer.com/permissions-api/internal/testingx"
+)
+
+func TestSubjectPermissions(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	testCases := []testingx.TestCase[string, *httptest.ResponseRecorder]{
+		{
+			Name:  "Self",
+			Input: "idntusr-abc123",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectAllowedActions").Return([]string{"loadbalancer_get", "loadbalancer_list"}, nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNotCalled(t, "SubjectHasPermission")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var resp subjectPermissionsResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&resp))
+
+				assert.Equal(t, "tnntten-abc123", resp.ResourceID.String())
+				assert.Equal(t, "idntusr-abc123", resp.SubjectID.String())
+				assert.Equal(t, []string{"loadbalancer_get", "loadbalancer_list"}, resp.Actions)
+			},
+		},
+		{
+			Name:  "OtherSubjectDenied",
+			Input: "idntusr-other",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(query.ErrActionNotAssigned)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNotCalled(t, "SubjectAllowedActions")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
+		{
+			Name:  "OtherSubjectAllowed",
+			Input: "idntusr-other",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(nil)
+				engine.On("SubjectAllowedActions").Return([]string{}, nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, subjectID string) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://127.0.0.1/api/v2/resources/tnntten-abc123/subjects/"+subjectID+"/permissions", nil)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+func TestCheckAllActionsMode(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	// one of the two requested actions is allowed
+	setupFn := func(ctx context.Context, _ *testing.T) context.Context {
+		engine := mock.Engine{
+			Namespace: "test",
+		}
+
+		engine.On("SubjectHasPermission").Return(query.ErrActionNotAssigned).Once()
+		engine.On("SubjectHasPermission").Return(nil).Once()
+
+		return context.WithValue(ctx, contextKeyEngine, &engine)
+	}
+
+	expectStatus := func(status int) func(context.Context, *testing.T, testingx.TestResult[*httptest.ResponseRecorder]) {
+		return func(_ context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+			require.NoError(t, res.Err)
+			require.NotNil(t, res.Success)
+
+			assert.Equal(t, status, res.Success.Code)
+		}
+	}
+
+	testCases := []testingx.TestCase[string, *httptest.ResponseRecorder]{
+		{
+			Name:    "Default",
+			Input:   "",
+			SetupFn: setupFn,
+			CheckFn: expectStatus(http.StatusForbidden),
+		},
+		{
+			Name:    "All",
+			Input:   "all",
+			SetupFn: setupFn,
+			CheckFn: expectStatus(http.StatusForbidden),
+		},
+		{
+			Name:    "Any",
+			Input:   "any",
+			SetupFn: setupFn,
+			CheckFn: expectStatus(http.StatusOK),
+		},
+		{
+			Name:    "InvalidMode",
+			Input:   "some",
+			SetupFn: setupFn,
+			CheckFn: expectStatus(http.StatusBadRequest),
+		},
+	}
+
+	testFn := func(ctx context.Context, mode string) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		body, err := json.Marshal(checkPermissionsRequest{
+			Actions: []checkAction{
+				{ResourceID: "tnntten-abc123", Action: "loadbalancer_update"},
+				{ResourceID: "tnntten-abc123", Action: "loadbalancer_get"},
+			},
+			Mode: mode,
+		})
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://127.0.0.1/api/v1/allow", bytes.NewReader(body))
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
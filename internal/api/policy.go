@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// policyGet reports the policy loaded by this replica and whether the schema
+// generated from it is the schema applied to SpiceDB.
+func (r *Router) policyGet(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "api.policyGet")
+	defer span.End()
+
+	info, err := r.engine.PolicyInfo(ctx)
+	if err != nil {
+		return r.errorResponse("error getting policy info", err)
+	}
+
+	return c.JSON(http.StatusOK, policyResponse{
+		PolicyHash:     info.PolicyHash,
+		SchemaHash:     info.SchemaHash,
+		LiveSchemaHash: info.LiveSchemaHash,
+		SchemaMatches:  info.SchemaMatches,
+		LoadedAt:       info.LoadedAt.Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/viperx"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultRateLimitRequestsPerSecond = 50
+	defaultRateLimitBurst             = 100
+
+	// rateLimitExpiry is how long an idle subject limiter is kept.
+	rateLimitExpiry = 10 * time.Minute
+)
+
+// RouteClass groups routes which share a rate limit.
+type RouteClass string
+
+const (
+	// RouteClassDefault is the class of all routes not limited separately.
+	RouteClassDefault RouteClass = "default"
+	// RouteClassChecks is the class of permission check routes.
+	RouteClassChecks RouteClass = "checks"
+	// RouteClassMutations is the class of routes creating, updating or deleting data.
+	RouteClassMutations RouteClass = "mutations"
+)
+
+// checkRoutes are the route paths of permission checks, without namespace prefix.
+var checkRoutes = map[string]bool{
+	"/api/v1/allow":        true,
+	"/api/v2/allow/filter": true,
+	"/api/v3/allow/filter": true,
+}
+
+// readOnlyRoutes are the route paths accepting POST requests without changing
+// any data, they are not limited as mutations. GraphQL mutations share the
+// default limit with GraphQL queries.
+var readOnlyRoutes = map[string]bool{
+	"/query": true,
+}
+
+// RateLimit defines a token bucket limit.
+type RateLimit struct {
+	// RequestsPerSecond is the rate at which requests are allowed.
+	RequestsPerSecond float64
+	// Burst is the number of requests which may be made at once.
+	Burst int
+}
+
+func (l RateLimit) enabled() bool {
+	return l.RequestsPerSecond > 0
+}
+
+// RateLimitConfig is the configuration for per-subject rate limiting.
+type RateLimitConfig struct {
+	// Enabled enables rate limiting of authenticated requests.
+	Enabled bool
+	// RateLimit is the limit for all requests of a subject.
+	RateLimit `mapstructure:",squash"`
+	// Checks, when set, limits permission checks separately from other requests.
+	Checks RateLimit
+	// Mutations, when set, limits create, update and delete requests separately from other requests.
+	Mutations RateLimit
+}
+
+type subjectLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimiter tracks token buckets per subject and route class.
+type rateLimiter struct {
+	mu          sync.Mutex
+	config      RateLimitConfig
+	limiters    map[string]*subjectLimiter
+	lastCleanup time.Time
+
+	now func() time.Time
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	return &rateLimiter{
+		config:      config,
+		limiters:    make(map[string]*subjectLimiter),
+		lastCleanup: time.Now(),
+		now:         time.Now,
+	}
+}
+
+// setConfig changes the limits, e.g. when the configuration is reloaded. The
+// token buckets of all subjects are dropped and start full with the new limits.
+func (l *rateLimiter) setConfig(config RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.config = config
+	l.limiters = make(map[string]*subjectLimiter)
+}
+
+// classify returns the route class of the request.
+func classify(c echo.Context) RouteClass {
+	path := routePath(c)
+
+	switch {
+	case checkRoutes[path]:
+		return RouteClassChecks
+	case readOnlyRoutes[path]:
+		return RouteClassDefault
+	}
+
+	switch c.Request().Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return RouteClassMutations
+	default:
+		return RouteClassDefault
+	}
+}
+
+// limitFor returns the limit applying to the route class, classes without a
+// configured limit share the default limit. l.mu must be held.
+func (l *rateLimiter) limitFor(class RouteClass) (RouteClass, RateLimit) {
+	switch {
+	case class == RouteClassChecks && l.config.Checks.enabled():
+		return class, l.config.Checks
+	case class == RouteClassMutations && l.config.Mutations.enabled():
+		return class, l.config.Mutations
+	default:
+		return RouteClassDefault, l.config.RateLimit
+	}
+}
+
+// reserve takes a token for the subject, returning how long the subject must
+// wait before retrying if no token is available.
+func (l *rateLimiter) reserve(subject string, class RouteClass) (bool, time.Duration) {
+	now := l.now()
+
+	l.mu.Lock()
+
+	if !l.config.Enabled {
+		l.mu.Unlock()
+
+		return true, 0
+	}
+
+	class, limit := l.limitFor(class)
+	key := subject + "|" + string(class)
+
+	if now.Sub(l.lastCleanup) > rateLimitExpiry {
+		for k, sl := range l.limiters {
+			if now.Sub(sl.lastSeen) > rateLimitExpiry {
+				delete(l.limiters, k)
+			}
+		}
+
+		l.lastCleanup = now
+	}
+
+	sl, ok := l.limiters[key]
+	if !ok {
+		sl = &subjectLimiter{limiter: rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), limit.Burst)}
+		l.limiters[key] = sl
+	}
+
+	sl.lastSeen = now
+
+	l.mu.Unlock()
+
+	reservation := sl.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Second
+	}
+
+	delay := reservation.DelayFrom(now)
+	if delay == 0 {
+		return true, 0
+	}
+
+	reservation.CancelAt(now)
+
+	return false, delay
+}
+
+// middleware limits requests by the authenticated subject, it must run after
+// the auth middleware.
+func (l *rateLimiter) middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		subject := echojwtx.Actor(c)
+		if subject == "" {
+			return next(c)
+		}
+
+		allowed, retryAfter := l.reserve(subject, classify(c))
+		if !allowed {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+
+			return echo.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+		}
+
+		return next(c)
+	}
+}
+
+// ReserveRateLimit takes a rate limit token for a request of the subject made
+// outside of the REST API, such as over gRPC, so subjects share one rate limit
+// across transports. It returns how long to wait before retrying when the
+// subject is limited.
+func (r *Router) ReserveRateLimit(subject string, class RouteClass) (bool, time.Duration) {
+	return r.rateLimiter.reserve(subject, class)
+}
+
+// rateLimitViperFlags sets the cobra flags and viper config for rate limiting.
+func rateLimitViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Bool("ratelimit-enabled", false, "enable per-subject rate limiting")
+	viperx.MustBindFlag(v, "ratelimit.enabled", flags.Lookup("ratelimit-enabled"))
+
+	flags.Float64("ratelimit-rps", defaultRateLimitRequestsPerSecond, "requests per second allowed per subject")
+	viperx.MustBindFlag(v, "ratelimit.requestspersecond", flags.Lookup("ratelimit-rps"))
+
+	flags.Int("ratelimit-burst", defaultRateLimitBurst, "request burst allowed per subject")
+	viperx.MustBindFlag(v, "ratelimit.burst", flags.Lookup("ratelimit-burst"))
+
+	flags.Float64("ratelimit-checks-rps", 0, "permission checks per second allowed per subject (shares the default limit when 0)")
+	viperx.MustBindFlag(v, "ratelimit.checks.requestspersecond", flags.Lookup("ratelimit-checks-rps"))
+
+	flags.Int("ratelimit-checks-burst", 0, "permission check burst allowed per subject")
+	viperx.MustBindFlag(v, "ratelimit.checks.burst", flags.Lookup("ratelimit-checks-burst"))
+
+	flags.Float64("ratelimit-mutations-rps", 0, "mutations per second allowed per subject (shares the default limit when 0)")
+	viperx.MustBindFlag(v, "ratelimit.mutations.requestspersecond", flags.Lookup("ratelimit-mutations-rps"))
+
+	flags.Int("ratelimit-mutations-burst", 0, "mutation burst allowed per subject")
+	viperx.MustBindFlag(v, "ratelimit.mutations.burst", flags.Lookup("ratelimit-mutations-burst"))
+}
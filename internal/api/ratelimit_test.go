@@ -0,0 +1,197 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	type testinput struct {
+		config   RateLimitConfig
+		requests []string
+	}
+
+	type testresult struct {
+		codes      []int
+		retryAfter string
+	}
+
+	testCases := []testingx.TestCase[testinput, testresult]{
+		{
+			Name: "WithinLimit",
+			Input: testinput{
+				config:   RateLimitConfig{Enabled: true, RateLimit: RateLimit{RequestsPerSecond: 1, Burst: 2}},
+				requests: []string{"GET /api/v2/roles/permrv2-abc", "GET /api/v2/roles/permrv2-abc"},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[testresult]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, []int{http.StatusOK, http.StatusOK}, res.Success.codes)
+			},
+		},
+		{
+			Name: "LimitExceeded",
+			Input: testinput{
+				config:   RateLimitConfig{Enabled: true, RateLimit: RateLimit{RequestsPerSecond: 0.5, Burst: 1}},
+				requests: []string{"GET /api/v2/roles/permrv2-abc", "GET /api/v1/allow"},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[testresult]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, res.Success.codes)
+				assert.Equal(t, "2", res.Success.retryAfter)
+			},
+		},
+		{
+			Name: "ChecksLimitedSeparately",
+			Input: testinput{
+				config: RateLimitConfig{
+					Enabled:   true,
+					RateLimit: RateLimit{RequestsPerSecond: 1, Burst: 1},
+					Checks:    RateLimit{RequestsPerSecond: 1, Burst: 2},
+				},
+				requests: []string{"POST /api/v2/roles/permrv2-abc", "GET /api/v1/allow", "POST /api/v1/allow", "GET /api/v1/allow"},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[testresult]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, res.Success.codes)
+			},
+		},
+		{
+			Name: "Disabled",
+			Input: testinput{
+				config:   RateLimitConfig{RateLimit: RateLimit{RequestsPerSecond: 1, Burst: 1}},
+				requests: []string{"GET /api/v2/roles/permrv2-abc", "GET /api/v2/roles/permrv2-abc", "GET /api/v2/roles/permrv2-abc"},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[testresult]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusOK}, res.Success.codes)
+			},
+		},
+		{
+			Name: "MutationsLimitedSeparately",
+			Input: testinput{
+				config: RateLimitConfig{
+					Enabled:   true,
+					RateLimit: RateLimit{RequestsPerSecond: 1, Burst: 1},
+					Mutations: RateLimit{RequestsPerSecond: 1, Burst: 1},
+				},
+				requests: []string{"DELETE /api/v2/roles/permrv2-abc", "GET /api/v2/roles/permrv2-abc", "PATCH /api/v2/roles/permrv2-abc"},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[testresult]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, res.Success.codes)
+			},
+		},
+		{
+			Name: "FilterAndGraphQLNotMutations",
+			Input: testinput{
+				config: RateLimitConfig{
+					Enabled:   true,
+					RateLimit: RateLimit{RequestsPerSecond: 1, Burst: 2},
+					Mutations: RateLimit{RequestsPerSecond: 1, Burst: 1},
+				},
+				requests: []string{"POST /api/v2/roles/permrv2-abc", "POST /api/v2/allow/filter", "POST /query", "PATCH /api/v2/roles/permrv2-abc"},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[testresult]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, res.Success.codes)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input testinput) testingx.TestResult[testresult] {
+		var result testingx.TestResult[testresult]
+
+		limiter := newRateLimiter(input.config)
+
+		now := time.Now()
+		limiter.now = func() time.Time { return now }
+
+		e := echo.New()
+
+		setActor := func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				c.Set(echojwtx.ActorKey, "idntusr-test")
+
+				return next(c)
+			}
+		}
+
+		ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+
+		g := e.Group("", setActor, limiter.middleware)
+		g.Any("/api/v2/roles/:role_id", ok)
+		g.Any("/api/v1/allow", ok)
+		g.Any("/api/v2/allow/filter", ok)
+		g.Any("/query", ok)
+
+		for _, request := range input.requests {
+			var method, path string
+
+			if _, err := fmt.Sscan(request, &method, &path); err != nil {
+				result.Err = err
+
+				return result
+			}
+
+			req, err := http.NewRequestWithContext(ctx, method, path, nil)
+			if err != nil {
+				result.Err = err
+
+				return result
+			}
+
+			resp := httptest.NewRecorder()
+
+			e.ServeHTTP(resp, req)
+
+			result.Success.codes = append(result.Success.codes, resp.Code)
+
+			if resp.Code == http.StatusTooManyRequests {
+				result.Success.retryAfter = resp.Header().Get("Retry-After")
+			}
+		}
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+func TestRateLimiterSetConfig(t *testing.T) {
+	limiter := newRateLimiter(RateLimitConfig{Enabled: true, RateLimit: RateLimit{RequestsPerSecond: 1, Burst: 1}})
+
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	allowed, _ := limiter.reserve("idntusr-test", RouteClassDefault)
+	assert.True(t, allowed)
+
+	allowed, _ = limiter.reserve("idntusr-test", RouteClassDefault)
+	assert.False(t, allowed)
+
+	// new limits apply immediately, with full buckets
+	limiter.setConfig(RateLimitConfig{Enabled: true, RateLimit: RateLimit{RequestsPerSecond: 1, Burst: 2}})
+
+	for i := 0; i < 2; i++ {
+		allowed, _ = limiter.reserve("idntusr-test", RouteClassDefault)
+		assert.True(t, allowed)
+	}
+
+	limiter.setConfig(RateLimitConfig{})
+
+	allowed, _ = limiter.reserve("idntusr-test", RouteClassDefault)
+	assert.True(t, allowed, "requests are not limited once disabled")
+}
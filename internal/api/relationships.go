@@ -1,12 +1,16 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
 	"go.infratographer.com/x/gidx"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/types"
 )
 
 func (r *Router) relationshipListFrom(c echo.Context) error {
@@ -25,6 +29,22 @@ func (r *Router) relationshipListFrom(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "error listing relationships").SetInternal(err)
 	}
 
+	if wantsStream(c) {
+		w := newNDJSONWriter(c)
+
+		err := r.engine.StreamRelationshipsFrom(ctx, resource, func(rel types.Relationship) error {
+			return w.Write(relationshipItem{
+				Relation:  rel.Relation,
+				SubjectID: rel.Subject.ID.String(),
+			})
+		})
+		if err := w.Close(err); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "error listing relationships").SetInternal(err)
+		}
+
+		return nil
+	}
+
 	rels, err := r.engine.ListRelationshipsFrom(ctx, resource)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "error listing relationships").SetInternal(err)
@@ -62,6 +82,22 @@ func (r *Router) relationshipListTo(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "error listing relationships").SetInternal(err)
 	}
 
+	if wantsStream(c) {
+		w := newNDJSONWriter(c)
+
+		err := r.engine.StreamRelationshipsTo(ctx, resource, func(rel types.Relationship) error {
+			return w.Write(relationshipItem{
+				ResourceID: rel.Resource.ID.String(),
+				Relation:   rel.Relation,
+			})
+		})
+		if err := w.Close(err); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "error listing relationships").SetInternal(err)
+		}
+
+		return nil
+	}
+
 	rels, err := r.engine.ListRelationshipsTo(ctx, resource)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "error listing relationships").SetInternal(err)
@@ -82,3 +118,177 @@ func (r *Router) relationshipListTo(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, out)
 }
+
+func (r *Router) relationshipsBulkWrite(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "api.relationshipsBulkWrite")
+	defer span.End()
+
+	var body bulkRelationshipsRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	if err := checkLimit(LimitBulkRelationshipWrites, r.limits.MaxBulkRelationshipWrites, len(body.Writes)); err != nil {
+		return r.errorResponse("error processing bulk request", err)
+	}
+
+	span.SetAttributes(attribute.Int("writes", len(body.Writes)))
+
+	writes := make([]types.RelationshipWrite, len(body.Writes))
+
+	for i, write := range body.Writes {
+		rel, err := r.newRelationship(write.ResourceID, write.Relation, write.SubjectID)
+		if err != nil {
+			return r.errorResponse(fmt.Sprintf("error processing write %d", i), err)
+		}
+
+		writes[i] = types.RelationshipWrite{
+			Operation:    types.RelationshipOperation(write.Operation),
+			Relationship: rel,
+		}
+	}
+
+	preconditions := make([]types.RelationshipPrecondition, len(body.Preconditions))
+
+	for i, precondition := range body.Preconditions {
+		rel, err := r.newRelationship(precondition.ResourceID, precondition.Relation, precondition.SubjectID)
+		if err != nil {
+			return r.errorResponse(fmt.Sprintf("error processing precondition %d", i), err)
+		}
+
+		preconditions[i] = types.RelationshipPrecondition{
+			Relationship: rel,
+			MustExist:    precondition.MustExist,
+		}
+	}
+
+	written, err := r.engine.WriteRelationships(ctx, writes, preconditions)
+	if err != nil {
+		return r.errorResponse("error writing relationships", fmt.Errorf("%w (%d of %d writes applied)", err, written, len(writes)))
+	}
+
+	return c.JSON(http.StatusOK, bulkRelationshipsResponse{Written: written})
+}
+
+func (r *Router) relationshipsRead(c echo.Context) error {
+	filter := types.RelationshipFilter{
+		ResourceType: c.QueryParam("resource_type"),
+		ResourceID:   c.QueryParam("resource_id"),
+		Relation:     c.QueryParam("relation"),
+		SubjectType:  c.QueryParam("subject_type"),
+		SubjectID:    c.QueryParam("subject_id"),
+	}
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.relationshipsRead",
+		trace.WithAttributes(
+			attribute.String("resource_type", filter.ResourceType),
+			attribute.String("resource_id", filter.ResourceID),
+		),
+	)
+	defer span.End()
+
+	pagination := ParsePagination(c)
+
+	records, cursor, err := r.engine.ReadRelationships(ctx, filter, pagination.Limit, c.QueryParam("cursor"))
+	if err != nil {
+		return r.errorResponse("error reading relationships", err)
+	}
+
+	resp := readRelationshipsResponse{
+		Data:       make([]relationshipRecordResponse, len(records)),
+		NextCursor: cursor,
+	}
+
+	for i, rec := range records {
+		resp.Data[i] = relationshipRecordResponse{
+			ResourceType:    rec.ResourceType,
+			ResourceID:      rec.ResourceID,
+			Relation:        rec.Relation,
+			SubjectType:     rec.SubjectType,
+			SubjectID:       rec.SubjectID,
+			SubjectRelation: rec.SubjectRelation,
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// newRelationship returns the relationship between the resource and subject IDs.
+func (r *Router) newRelationship(resourceIDStr, relation, subjectIDStr string) (types.Relationship, error) {
+	resource, err := r.newResourceFromIDString(resourceIDStr)
+	if err != nil {
+		return types.Relationship{}, fmt.Errorf("resource: %w", err)
+	}
+
+	subject, err := r.newResourceFromIDString(subjectIDStr)
+	if err != nil {
+		return types.Relationship{}, fmt.Errorf("subject: %w", err)
+	}
+
+	return types.Relationship{
+		Resource: resource,
+		Relation: relation,
+		Subject:  subject,
+	}, nil
+}
+
+// newResourceFromIDString parses the ID and returns its resource.
+func (r *Router) newResourceFromIDString(idStr string) (types.Resource, error) {
+	id, err := gidx.Parse(idStr)
+	if err != nil {
+		return types.Resource{}, fmt.Errorf("%w: %s", ErrInvalidID, err.Error())
+	}
+
+	return r.engine.NewResourceFromID(id)
+}
+
+// relationshipChangesStream streams the relationship changes made in SpiceDB
+// as server-sent events until the client disconnects. The ID of every event
+// is the ZedToken of the change, clients reconnecting with the Last-Event-ID
+// header, or the after query parameter, receive the changes made after it.
+func (r *Router) relationshipChangesStream(c echo.Context) error {
+	after := c.Request().Header.Get("Last-Event-ID")
+	if after == "" {
+		after = c.QueryParam("after")
+	}
+
+	ctx, span := tracer.Start(c.Request().Context(), "api.relationshipChangesStream", trace.WithAttributes(attribute.String("after", after)))
+	defer span.End()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := newSSEWriter(c)
+
+	go w.keepAlive(ctx)
+
+	err := r.engine.WatchRelationshipChanges(ctx, after, func(change types.RelationshipChange) error {
+		rel := change.Relationship
+
+		return w.Write(change.ZedToken, string(change.Operation), relationshipChangeResponse{
+			Operation:       string(change.Operation),
+			ResourceType:    rel.ResourceType,
+			ResourceID:      rel.ResourceID,
+			Relation:        rel.Relation,
+			SubjectType:     rel.SubjectType,
+			SubjectID:       rel.SubjectID,
+			SubjectRelation: rel.SubjectRelation,
+			ZedToken:        change.ZedToken,
+		})
+	})
+
+	// the client disconnecting ends the stream
+	if err == nil || ctx.Err() != nil {
+		return nil
+	}
+
+	c.Logger().Error(err)
+
+	if err := w.Write(after, "error", streamError{Error: "error watching relationship changes"}); err != nil {
+		c.Logger().Error(err)
+	}
+
+	return nil
+}
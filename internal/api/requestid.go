@@ -0,0 +1,65 @@
+package api
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/query"
+)
+
+// requestIDMiddleware correlates everything done for a request with its ID:
+// log lines, permission checks, audit events, the request trace and error
+// responses. The ID is the one assigned by the server's request ID
+// middleware, or else the one passed by the caller, or else a generated one,
+// and is returned in the X-Request-Id response header.
+func requestIDMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+		if requestID == "" {
+			requestID = c.Request().Header.Get(echo.HeaderXRequestID)
+		}
+
+		if requestID == "" {
+			requestID = query.NewRequestID()
+		}
+
+		c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+
+		ctx := query.ContextWithRequestID(c.Request().Context(), requestID)
+
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request_id", requestID))
+
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		err := next(c)
+		if err == nil || c.Response().Committed {
+			return err
+		}
+
+		return errorWithRequestID(err, requestID)
+	}
+}
+
+// errorWithRequestID adds the request ID to the body of an error response.
+func errorWithRequestID(err error, requestID string) error {
+	he, ok := err.(*echo.HTTPError)
+	if !ok {
+		he = echo.ErrInternalServerError.WithInternal(err)
+	}
+
+	msg, ok := he.Message.(string)
+	if !ok {
+		return he
+	}
+
+	// echo's predefined errors are shared, so a new error is returned
+	return &echo.HTTPError{
+		Code: he.Code,
+		Message: echo.Map{
+			"message":    msg,
+			"request_id": requestID,
+		},
+		Internal: he.Internal,
+	}
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/testingx"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	e := echo.New()
+	e.Use(echoTestLogger(t, e))
+	e.Use(requestIDMiddleware, errorMiddleware)
+
+	e.GET("/test", func(c echo.Context) error {
+		requestID := query.RequestIDFromContext(c.Request().Context())
+
+		switch c.QueryParam("error") {
+		case "echo":
+			return echo.ErrNotFound
+		case "other":
+			return context.DeadlineExceeded
+		}
+
+		return c.JSON(http.StatusOK, map[string]string{"request_id": requestID})
+	})
+
+	type testinput struct {
+		path      string
+		requestID string
+	}
+
+	type result struct {
+		code      int
+		header    string
+		requestID string
+		message   string
+	}
+
+	testCases := []testingx.TestCase[testinput, result]{
+		{
+			Name:  "Generated",
+			Input: testinput{path: "/test"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusOK, res.Success.code)
+				assert.NotEmpty(t, res.Success.header)
+				assert.Equal(t, res.Success.header, res.Success.requestID)
+			},
+		},
+		{
+			Name:  "Propagated",
+			Input: testinput{path: "/test", requestID: "caller-id"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusOK, res.Success.code)
+				assert.Equal(t, "caller-id", res.Success.header)
+				assert.Equal(t, "caller-id", res.Success.requestID)
+			},
+		},
+		{
+			Name:  "EchoError",
+			Input: testinput{path: "/test?error=echo", requestID: "caller-id"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusNotFound, res.Success.code)
+				assert.Equal(t, "caller-id", res.Success.requestID)
+				assert.Equal(t, http.StatusText(http.StatusNotFound), res.Success.message)
+
+				// shared echo errors are not modified
+				assert.Equal(t, http.StatusText(http.StatusNotFound), echo.ErrNotFound.Message)
+			},
+		},
+		{
+			Name:  "OtherError",
+			Input: testinput{path: "/test?error=other", requestID: "caller-id"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusInternalServerError, res.Success.code)
+				assert.Equal(t, "caller-id", res.Success.requestID)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input testinput) testingx.TestResult[result] {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, input.path, nil)
+		if err != nil {
+			return testingx.TestResult[result]{Err: err}
+		}
+
+		if input.requestID != "" {
+			req.Header.Set(echo.HeaderXRequestID, input.requestID)
+		}
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		var body struct {
+			RequestID string `json:"request_id"`
+			Message   string `json:"message"`
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return testingx.TestResult[result]{Err: err}
+		}
+
+		return testingx.TestResult[result]{
+			Success: result{
+				code:      resp.Code,
+				header:    resp.Header().Get(echo.HeaderXRequestID),
+				requestID: body.RequestID,
+				message:   body.Message,
+			},
+		}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
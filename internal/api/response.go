@@ -10,6 +10,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
 	"go.infratographer.com/permissions-api/internal/storage"
 )
 
@@ -41,18 +42,43 @@ func (r *Router) errorResponse(basemsg string, err error) *echo.HTTPError {
 		errors.Is(err, query.ErrInvalidAction),
 		errors.Is(err, query.ErrInvalidNamespace),
 		errors.Is(err, ErrInvalidID),
+		errors.Is(err, ErrInvalidBulkRequest),
+		errors.Is(err, ErrLimitExceeded),
 		status.Code(err) == codes.InvalidArgument,
 		status.Code(err) == codes.FailedPrecondition:
 		httpstatus = http.StatusBadRequest
 	case
 		errors.Is(err, storage.ErrNoRoleFound),
 		errors.Is(err, query.ErrRoleNotFound),
-		errors.Is(err, query.ErrRoleBindingNotFound):
+		errors.Is(err, query.ErrRoleBindingNotFound),
+		errors.Is(err, query.ErrInvitationNotFound),
+		errors.Is(err, query.ErrAccessRequestNotFound),
+		errors.Is(err, query.ErrGroupNotFound),
+		errors.Is(err, query.ErrResourceAliasNotFound):
 		httpstatus = http.StatusNotFound
+	case
+		errors.Is(err, query.ErrInvitationExpired),
+		errors.Is(err, query.ErrInvitationRedeemed):
+		httpstatus = http.StatusGone
 	case
 		errors.Is(err, storage.ErrRoleAlreadyExists),
-		errors.Is(err, storage.ErrRoleNameTaken):
+		errors.Is(err, storage.ErrRoleNameTaken),
+		errors.Is(err, storage.ErrGroupNameTaken),
+		errors.Is(err, storage.ErrResourceAliasExists),
+		errors.Is(err, query.ErrAccessRequestDecided):
 		httpstatus = http.StatusConflict
+	case
+		errors.Is(err, query.ErrAccessRequestSelfDecision),
+		errors.Is(err, query.ErrRoleDelegationExceeded):
+		httpstatus = http.StatusForbidden
+	case errors.Is(err, query.ErrPreconditionFailed):
+		httpstatus = http.StatusPreconditionFailed
+	case errors.Is(err, query.ErrGroupsNotConfigured):
+		httpstatus = http.StatusNotImplemented
+	case errors.Is(err, spicedbx.ErrorBudgetExceeded):
+		httpstatus = http.StatusTooManyRequests
+	case errors.Is(err, spicedbx.ErrorCircuitOpen):
+		httpstatus = http.StatusServiceUnavailable
 	default:
 		msg = basemsg
 	}
@@ -1,8 +1,11 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -11,9 +14,20 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
 	"go.infratographer.com/permissions-api/internal/types"
 )
 
+const (
+	// defaultStaleRoleBindingDays is the number of days without use after which a
+	// role-binding is reported as stale, unless specified in the request.
+	defaultStaleRoleBindingDays = 90
+
+	// defaultSuggestionDays is the number of days of usage compared with the
+	// actions granted by role-bindings, unless specified in the request.
+	defaultSuggestionDays = 30
+)
+
 func (r *Router) roleBindingCreate(c echo.Context) error {
 	resourceIDStr := c.Param("id")
 
@@ -35,6 +49,10 @@ func (r *Router) roleBindingCreate(c echo.Context) error {
 		return r.errorResponse(err.Error(), ErrParsingRequestBody)
 	}
 
+	if err := r.limits.CheckRoleBindingSubjects(len(body.SubjectIDs)); err != nil {
+		return r.errorResponse("error creating role-binding", err)
+	}
+
 	resource, err := r.engine.NewResourceFromID(resourceID)
 	if err != nil {
 		return r.errorResponse("error creating resource", err)
@@ -63,7 +81,7 @@ func (r *Router) roleBindingCreate(c echo.Context) error {
 	subjects := make([]types.RoleBindingSubject, len(body.SubjectIDs))
 
 	for i, sid := range body.SubjectIDs {
-		subj, err := r.engine.NewResourceFromID(sid)
+		subj, err := r.engine.ResolveResource(ctx, sid.String())
 		if err != nil {
 			return r.errorResponse("error creating subject resource", err)
 		}
@@ -73,7 +91,17 @@ func (r *Router) roleBindingCreate(c echo.Context) error {
 		}
 	}
 
-	rb, err := r.engine.CreateRoleBinding(ctx, actor, resource, roleResource, subjects)
+	var rb types.RoleBinding
+
+	if body.Caveat != nil {
+		rb, err = r.engine.CreateRoleBindingWithCaveat(ctx, actor, resource, roleResource, subjects, types.RoleBindingCaveat{
+			Name:    body.Caveat.Name,
+			Context: body.Caveat.Context,
+		})
+	} else {
+		rb, err = r.engine.CreateRoleBinding(ctx, actor, resource, roleResource, subjects)
+	}
+
 	if err != nil {
 		return r.errorResponse("error creating role-binding", err)
 	}
@@ -85,11 +113,13 @@ func (r *Router) roleBindingCreate(c echo.Context) error {
 			ResourceID: rb.ResourceID,
 			SubjectIDs: rb.SubjectIDs,
 			RoleID:     rb.RoleID,
+			Caveat:     newRoleBindingCaveatResponse(rb.Caveat),
 
-			CreatedBy: rb.CreatedBy,
-			UpdatedBy: rb.UpdatedBy,
-			CreatedAt: rb.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: rb.UpdatedAt.Format(time.RFC3339),
+			CreatedBy:  rb.CreatedBy,
+			UpdatedBy:  rb.UpdatedBy,
+			CreatedAt:  rb.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:  rb.UpdatedAt.Format(time.RFC3339),
+			LastUsedAt: formatLastUsed(rb.LastUsedAt),
 		},
 	)
 }
@@ -122,6 +152,31 @@ func (r *Router) roleBindingsList(c echo.Context) error {
 		return err
 	}
 
+	if wantsStream(c) {
+		w := newNDJSONWriter(c)
+
+		err := r.engine.StreamRoleBindings(ctx, resource, func(rb types.RoleBinding) error {
+			return w.Write(roleBindingResponse{
+				ID:         rb.ID,
+				ResourceID: rb.ResourceID,
+				SubjectIDs: rb.SubjectIDs,
+				RoleID:     rb.RoleID,
+				Caveat:     newRoleBindingCaveatResponse(rb.Caveat),
+
+				CreatedBy:  rb.CreatedBy,
+				UpdatedBy:  rb.UpdatedBy,
+				CreatedAt:  rb.CreatedAt.Format(time.RFC3339),
+				UpdatedAt:  rb.UpdatedAt.Format(time.RFC3339),
+				LastUsedAt: formatLastUsed(rb.LastUsedAt),
+			})
+		})
+		if err := w.Close(err); err != nil {
+			return r.errorResponse("error listing role-binding", err)
+		}
+
+		return nil
+	}
+
 	rbs, err := r.engine.ListRoleBindings(ctx, resource, nil)
 	if err != nil {
 		return r.errorResponse("error listing role-binding", err)
@@ -137,17 +192,102 @@ func (r *Router) roleBindingsList(c echo.Context) error {
 			ResourceID: rb.ResourceID,
 			SubjectIDs: rb.SubjectIDs,
 			RoleID:     rb.RoleID,
+			Caveat:     newRoleBindingCaveatResponse(rb.Caveat),
 
-			CreatedBy: rb.CreatedBy,
-			UpdatedBy: rb.UpdatedBy,
-			CreatedAt: rb.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: rb.UpdatedAt.Format(time.RFC3339),
+			CreatedBy:  rb.CreatedBy,
+			UpdatedBy:  rb.UpdatedBy,
+			CreatedAt:  rb.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:  rb.UpdatedAt.Format(time.RFC3339),
+			LastUsedAt: formatLastUsed(rb.LastUsedAt),
 		}
 	}
 
 	return c.JSON(http.StatusOK, resp)
 }
 
+// roleBindingsListBySubject lists the role-bindings of a subject across
+// resources. Only role-bindings on resources the caller may list role-bindings
+// on are returned.
+func (r *Router) roleBindingsListBySubject(c echo.Context) error {
+	subjectIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.roleBindingsListBySubject",
+		trace.WithAttributes(attribute.String("id", subjectIDStr)),
+	)
+	defer span.End()
+
+	subjectID, err := gidx.Parse(subjectIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing subject ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	subject, err := r.engine.NewResourceFromID(subjectID)
+	if err != nil {
+		return r.errorResponse("error creating subject resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	rbs, err := r.engine.ListSubjectRoleBindings(ctx, subject)
+	if err != nil {
+		return r.errorResponse("error listing role-bindings", err)
+	}
+
+	resp := listRoleBindingsResponse{
+		Data: make([]roleBindingResponse, 0, len(rbs)),
+	}
+
+	// allowed caches the permission checks of resources with many role-bindings
+	allowed := make(map[gidx.PrefixedID]bool)
+
+	for _, rb := range rbs {
+		ok, checked := allowed[rb.ResourceID]
+		if !checked {
+			resource, err := r.engine.NewResourceFromID(rb.ResourceID)
+			if err != nil {
+				return r.errorResponse("error creating resource", err)
+			}
+
+			err = r.engine.SubjectHasPermission(ctx, actor, string(iapl.RoleBindingActionList), resource)
+
+			switch {
+			case err == nil:
+				ok = true
+			case errors.Is(err, query.ErrActionNotAssigned):
+				ok = false
+			default:
+				return r.errorResponse("error checking permissions", err)
+			}
+
+			allowed[rb.ResourceID] = ok
+		}
+
+		if !ok {
+			continue
+		}
+
+		resp.Data = append(resp.Data, roleBindingResponse{
+			ID:         rb.ID,
+			ResourceID: rb.ResourceID,
+			SubjectIDs: rb.SubjectIDs,
+			RoleID:     rb.RoleID,
+			Caveat:     newRoleBindingCaveatResponse(rb.Caveat),
+
+			CreatedBy:  rb.CreatedBy,
+			UpdatedBy:  rb.UpdatedBy,
+			CreatedAt:  rb.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:  rb.UpdatedAt.Format(time.RFC3339),
+			LastUsedAt: formatLastUsed(rb.LastUsedAt),
+		})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
 func (r *Router) roleBindingDelete(c echo.Context) error {
 	rbID := c.Param("rb_id")
 
@@ -235,6 +375,10 @@ func (r *Router) roleBindingGet(c echo.Context) error {
 		return err
 	}
 
+	if setETag(c, rb.ID, rb.UpdatedAt) {
+		return notModified(c)
+	}
+
 	return c.JSON(
 		http.StatusOK,
 		roleBindingResponse{
@@ -242,11 +386,13 @@ func (r *Router) roleBindingGet(c echo.Context) error {
 			ResourceID: rb.ResourceID,
 			SubjectIDs: rb.SubjectIDs,
 			RoleID:     rb.RoleID,
+			Caveat:     newRoleBindingCaveatResponse(rb.Caveat),
 
-			CreatedBy: rb.CreatedBy,
-			UpdatedBy: rb.UpdatedBy,
-			CreatedAt: rb.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: rb.UpdatedAt.Format(time.RFC3339),
+			CreatedBy:  rb.CreatedBy,
+			UpdatedBy:  rb.UpdatedBy,
+			CreatedAt:  rb.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:  rb.UpdatedAt.Format(time.RFC3339),
+			LastUsedAt: formatLastUsed(rb.LastUsedAt),
 		},
 	)
 }
@@ -296,10 +442,14 @@ func (r *Router) roleBindingUpdate(c echo.Context) error {
 		return r.errorResponse(err.Error(), ErrParsingRequestBody)
 	}
 
+	if err := r.limits.CheckRoleBindingSubjects(len(body.SubjectIDs)); err != nil {
+		return r.errorResponse("error updating role-binding", err)
+	}
+
 	subjects := make([]types.RoleBindingSubject, len(body.SubjectIDs))
 
 	for i, sid := range body.SubjectIDs {
-		subj, err := r.engine.NewResourceFromID(sid)
+		subj, err := r.engine.ResolveResource(ctx, sid.String())
 		if err != nil {
 			return r.errorResponse("error creating subject resource", err)
 		}
@@ -309,11 +459,13 @@ func (r *Router) roleBindingUpdate(c echo.Context) error {
 		}
 	}
 
-	rb, err := r.engine.UpdateRoleBinding(ctx, actor, rbRes, subjects)
+	rb, err := r.engine.UpdateRoleBinding(ifMatchContext(ctx, c), actor, rbRes, subjects)
 	if err != nil {
 		return r.errorResponse("error updating role-binding", err)
 	}
 
+	setETag(c, rb.ID, rb.UpdatedAt)
+
 	return c.JSON(
 		http.StatusOK,
 		roleBindingResponse{
@@ -321,11 +473,380 @@ func (r *Router) roleBindingUpdate(c echo.Context) error {
 			ResourceID: rb.ResourceID,
 			SubjectIDs: rb.SubjectIDs,
 			RoleID:     rb.RoleID,
+			Caveat:     newRoleBindingCaveatResponse(rb.Caveat),
 
-			CreatedBy: rb.CreatedBy,
-			UpdatedBy: rb.UpdatedBy,
-			CreatedAt: rb.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: rb.UpdatedAt.Format(time.RFC3339),
+			CreatedBy:  rb.CreatedBy,
+			UpdatedBy:  rb.UpdatedBy,
+			CreatedAt:  rb.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:  rb.UpdatedAt.Format(time.RFC3339),
+			LastUsedAt: formatLastUsed(rb.LastUsedAt),
 		},
 	)
 }
+
+func (r *Router) roleBindingsListStale(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.roleBindingsListStale",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	days := defaultStaleRoleBindingDays
+
+	if daysStr := c.QueryParam("days"); daysStr != "" {
+		var err error
+
+		days, err = strconv.Atoi(daysStr)
+		if err != nil || days < 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, "days must be a positive integer")
+		}
+	}
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	subjectResource, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkActionWithResponse(ctx, subjectResource, string(iapl.RoleBindingActionList), resource); err != nil {
+		return err
+	}
+
+	unusedSince := time.Now().AddDate(0, 0, -days)
+
+	rbs, err := r.engine.ListStaleRoleBindings(ctx, resource, unusedSince)
+	if err != nil {
+		return r.errorResponse("error listing stale role-bindings", err)
+	}
+
+	resp := listStaleRoleBindingsResponse{
+		UnusedSince: unusedSince.Format(time.RFC3339),
+		Data:        make([]roleBindingResponse, len(rbs)),
+	}
+
+	for i, rb := range rbs {
+		resp.Data[i] = roleBindingResponse{
+			ID:         rb.ID,
+			ResourceID: rb.ResourceID,
+			SubjectIDs: rb.SubjectIDs,
+			RoleID:     rb.RoleID,
+			Caveat:     newRoleBindingCaveatResponse(rb.Caveat),
+
+			CreatedBy:  rb.CreatedBy,
+			UpdatedBy:  rb.UpdatedBy,
+			CreatedAt:  rb.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:  rb.UpdatedAt.Format(time.RFC3339),
+			LastUsedAt: formatLastUsed(rb.LastUsedAt),
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// roleBindingsBulk creates and deletes many role-bindings on a resource in a
+// single request. Every role-binding is reported individually, so a failure
+// of some role-bindings does not fail the request.
+func (r *Router) roleBindingsBulk(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.roleBindingsBulk",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	var body bulkRoleBindingsRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	if err := checkLimit(LimitBulkRoleBindings, r.limits.MaxBulkRoleBindings, len(body.Create)+len(body.Delete)); err != nil {
+		return r.errorResponse("error processing bulk request", err)
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	resp := bulkRoleBindingsResponse{
+		Created: []bulkRoleBindingResult{},
+		Deleted: []bulkRoleBindingResult{},
+	}
+
+	if len(body.Create) != 0 {
+		if err := r.checkActionWithResponse(ctx, actor, string(iapl.RoleBindingActionCreate), resource); err != nil {
+			return err
+		}
+
+		resp.Created = r.bulkCreateRoleBindings(ctx, actor, resource, body.Create)
+	}
+
+	if len(body.Delete) != 0 {
+		if err := r.checkActionWithResponse(ctx, actor, string(iapl.RoleBindingActionDelete), resource); err != nil {
+			return err
+		}
+
+		resp.Deleted = r.bulkDeleteRoleBindings(ctx, resource, body.Delete)
+	}
+
+	for _, res := range append(resp.Created, resp.Deleted...) {
+		if res.Error != "" {
+			resp.Failed++
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (r *Router) bulkCreateRoleBindings(ctx context.Context, actor, resource types.Resource, creates []roleBindingRequest) []bulkRoleBindingResult {
+	results := make([]bulkRoleBindingResult, len(creates))
+	requests := make([]types.RoleBindingRequest, 0, len(creates))
+	// indexes maps the engine requests to the request body
+	indexes := make([]int, 0, len(creates))
+
+	for i, create := range creates {
+		req, err := r.newRoleBindingRequest(ctx, create)
+		if err != nil {
+			results[i] = bulkRoleBindingError(err)
+
+			continue
+		}
+
+		requests = append(requests, req)
+		indexes = append(indexes, i)
+	}
+
+	for i, res := range r.engine.CreateRoleBindings(ctx, actor, resource, requests) {
+		if res.Err != nil {
+			results[indexes[i]] = bulkRoleBindingError(r.errorResponse("error creating role-binding", res.Err))
+
+			continue
+		}
+
+		rb := res.RoleBinding
+
+		results[indexes[i]] = bulkRoleBindingResult{
+			ID:     rb.ID,
+			Status: http.StatusCreated,
+			RoleBinding: &roleBindingResponse{
+				ID:         rb.ID,
+				ResourceID: rb.ResourceID,
+				SubjectIDs: rb.SubjectIDs,
+				RoleID:     rb.RoleID,
+				Caveat:     newRoleBindingCaveatResponse(rb.Caveat),
+
+				CreatedBy:  rb.CreatedBy,
+				UpdatedBy:  rb.UpdatedBy,
+				CreatedAt:  rb.CreatedAt.Format(time.RFC3339),
+				UpdatedAt:  rb.UpdatedAt.Format(time.RFC3339),
+				LastUsedAt: formatLastUsed(rb.LastUsedAt),
+			},
+		}
+	}
+
+	return results
+}
+
+func (r *Router) bulkDeleteRoleBindings(ctx context.Context, resource types.Resource, ids []gidx.PrefixedID) []bulkRoleBindingResult {
+	results := make([]bulkRoleBindingResult, len(ids))
+	rolebindings := make([]types.Resource, 0, len(ids))
+	// indexes maps the engine role-bindings to the request body
+	indexes := make([]int, 0, len(ids))
+
+	for i, id := range ids {
+		rbRes, err := r.engine.NewResourceFromID(id)
+		if err != nil {
+			results[i] = bulkRoleBindingError(r.errorResponse("error creating resource", err))
+			results[i].ID = id
+
+			continue
+		}
+
+		rolebindings = append(rolebindings, rbRes)
+		indexes = append(indexes, i)
+	}
+
+	for i, res := range r.engine.DeleteRoleBindings(ctx, resource, rolebindings) {
+		if res.Err != nil {
+			results[indexes[i]] = bulkRoleBindingError(r.errorResponse("error deleting role-binding", res.Err))
+			results[indexes[i]].ID = res.RoleBinding.ID
+
+			continue
+		}
+
+		results[indexes[i]] = bulkRoleBindingResult{
+			ID:     res.RoleBinding.ID,
+			Status: http.StatusOK,
+		}
+	}
+
+	return results
+}
+
+// newRoleBindingRequest converts a role-binding in a request body to an engine request.
+func (r *Router) newRoleBindingRequest(ctx context.Context, body roleBindingRequest) (types.RoleBindingRequest, error) {
+	if err := r.limits.CheckRoleBindingSubjects(len(body.SubjectIDs)); err != nil {
+		return types.RoleBindingRequest{}, r.errorResponse("error creating role-binding", err)
+	}
+
+	roleID, err := gidx.Parse(body.RoleID)
+	if err != nil {
+		return types.RoleBindingRequest{}, r.errorResponse("error parsing role ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	roleResource, err := r.engine.NewResourceFromID(roleID)
+	if err != nil {
+		return types.RoleBindingRequest{}, r.errorResponse("error creating role resource", err)
+	}
+
+	req := types.RoleBindingRequest{
+		Role:     roleResource,
+		Subjects: make([]types.RoleBindingSubject, len(body.SubjectIDs)),
+	}
+
+	if body.Caveat != nil {
+		req.Caveat = &types.RoleBindingCaveat{
+			Name:    body.Caveat.Name,
+			Context: body.Caveat.Context,
+		}
+	}
+
+	for i, sid := range body.SubjectIDs {
+		subj, err := r.engine.ResolveResource(ctx, sid.String())
+		if err != nil {
+			return types.RoleBindingRequest{}, r.errorResponse("error creating subject resource", err)
+		}
+
+		req.Subjects[i] = types.RoleBindingSubject{
+			SubjectResource: subj,
+		}
+	}
+
+	return req, nil
+}
+
+// newRoleBindingCaveatResponse converts the caveat of a role-binding to its response, nil if unset.
+func newRoleBindingCaveatResponse(caveat *types.RoleBindingCaveat) *roleBindingCaveat {
+	if caveat == nil {
+		return nil
+	}
+
+	return &roleBindingCaveat{
+		Name:    caveat.Name,
+		Context: caveat.Context,
+	}
+}
+
+func bulkRoleBindingError(err error) bulkRoleBindingResult {
+	he, ok := err.(*echo.HTTPError)
+	if !ok {
+		he = echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return bulkRoleBindingResult{
+		Status: he.Code,
+		Error:  fmt.Sprint(he.Message),
+	}
+}
+
+// roleBindingsSuggestions suggests reducing the role-bindings on a resource
+// which granted actions that were not used within a number of days.
+func (r *Router) roleBindingsSuggestions(c echo.Context) error {
+	resourceIDStr := c.Param("id")
+
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.roleBindingsSuggestions",
+		trace.WithAttributes(attribute.String("id", resourceIDStr)),
+	)
+	defer span.End()
+
+	days := defaultSuggestionDays
+
+	if daysStr := c.QueryParam("days"); daysStr != "" {
+		var err error
+
+		days, err = strconv.Atoi(daysStr)
+		if err != nil || days < 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, "days must be a positive integer")
+		}
+	}
+
+	resourceID, err := gidx.Parse(resourceIDStr)
+	if err != nil {
+		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	resource, err := r.engine.NewResourceFromID(resourceID)
+	if err != nil {
+		return r.errorResponse("error creating resource", err)
+	}
+
+	subjectResource, err := r.currentSubject(c)
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkActionWithResponse(ctx, subjectResource, string(iapl.RoleBindingActionList), resource); err != nil {
+		return err
+	}
+
+	usedSince := time.Now().AddDate(0, 0, -days)
+
+	suggestions, err := r.engine.SuggestRoleBindingReductions(ctx, resource, usedSince)
+	if err != nil {
+		return r.errorResponse("error listing role-binding suggestions", err)
+	}
+
+	resp := listRoleBindingSuggestionsResponse{
+		UsedSince: usedSince.Format(time.RFC3339),
+		Data:      make([]roleBindingSuggestionResponse, len(suggestions)),
+	}
+
+	for i, s := range suggestions {
+		rb := s.RoleBinding
+
+		resp.Data[i] = roleBindingSuggestionResponse{
+			RoleBinding: roleBindingResponse{
+				ID:         rb.ID,
+				ResourceID: rb.ResourceID,
+				SubjectIDs: rb.SubjectIDs,
+				RoleID:     rb.RoleID,
+				Caveat:     newRoleBindingCaveatResponse(rb.Caveat),
+
+				CreatedBy:  rb.CreatedBy,
+				UpdatedBy:  rb.UpdatedBy,
+				CreatedAt:  rb.CreatedAt.Format(time.RFC3339),
+				UpdatedAt:  rb.UpdatedAt.Format(time.RFC3339),
+				LastUsedAt: formatLastUsed(rb.LastUsedAt),
+			},
+			UsedActions:   s.UsedActions,
+			UnusedActions: s.UnusedActions,
+			Remove:        len(s.UsedActions) == 0,
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testauth"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestRoleBindingsBulk(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	testCases := []testingx.TestCase[map[string]any, *httptest.ResponseRecorder]{
+		{
+			Name: "PermissionDenied",
+			Input: map[string]any{
+				"create": []map[string]any{
+					{"role_id": "permrol-abc123", "subject_ids": []string{"idntusr-abc123"}},
+				},
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(query.ErrActionNotAssigned)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNotCalled(t, "CreateRoleBindings")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
+		{
+			Name: "InvalidBody",
+			Input: map[string]any{
+				"create": []map[string]any{
+					{"role_id": "permrol-abc123", "subject_ids": []string{"idntusr-abc123"}},
+					{"subject_ids": []string{"idntusr-abc123"}},
+				},
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+				assert.Contains(t, res.Success.Body.String(), "/create/1/role_id")
+			},
+		},
+		{
+			Name: "PartialFailure",
+			Input: map[string]any{
+				"create": []map[string]any{
+					{"role_id": "bad-id", "subject_ids": []string{"idntusr-abc123"}},
+					{"role_id": "permrol-abc123", "subject_ids": []string{"idntusr-abc123"}},
+					{"role_id": "permrol-def456", "subject_ids": []string{"idntusr-abc123"}},
+				},
+				"delete": []string{"permrbn-abc123"},
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(nil)
+				engine.On("CreateRoleBindings").Return([]types.RoleBindingResult{
+					{
+						RoleBinding: types.RoleBinding{
+							ID:         "permrbn-created",
+							ResourceID: "tnntten-abc123",
+							RoleID:     "permrol-abc123",
+							SubjectIDs: []gidx.PrefixedID{"idntusr-abc123"},
+							CreatedAt:  time.Now(),
+							UpdatedAt:  time.Now(),
+						},
+					},
+					{Err: query.ErrRoleNotFound},
+				})
+				engine.On("DeleteRoleBindings").Return([]types.RoleBindingResult{})
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var resp bulkRoleBindingsResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&resp))
+
+				assert.Equal(t, 3, resp.Failed)
+				require.Len(t, resp.Created, 3)
+				require.Len(t, resp.Deleted, 1)
+
+				assert.Equal(t, http.StatusBadRequest, resp.Created[0].Status)
+				assert.Equal(t, http.StatusCreated, resp.Created[1].Status)
+				assert.Equal(t, "permrbn-created", resp.Created[1].ID.String())
+				require.NotNil(t, resp.Created[1].RoleBinding)
+				assert.Equal(t, "permrol-abc123", resp.Created[1].RoleBinding.RoleID.String())
+				assert.Equal(t, http.StatusNotFound, resp.Created[2].Status)
+				assert.NotEmpty(t, resp.Created[2].Error)
+
+				// the default policy has no role-binding resource type
+				assert.NotEmpty(t, resp.Deleted[0].Error)
+				assert.Equal(t, "permrbn-abc123", resp.Deleted[0].ID.String())
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input map[string]any) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		body, err := json.Marshal(input)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://127.0.0.1/api/v2/resources/tnntten-abc123/role-bindings/bulk", bytes.NewBuffer(body))
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+func TestRoleBindingsListBySubject(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	rbs := []types.RoleBinding{
+		{ID: "permrbn-one", ResourceID: "tnntten-allowed", RoleID: "permrv2-viewer", SubjectIDs: []gidx.PrefixedID{"idntusr-subj"}},
+		{ID: "permrbn-two", ResourceID: "tnntten-denied", RoleID: "permrv2-viewer", SubjectIDs: []gidx.PrefixedID{"idntusr-subj"}},
+		{ID: "permrbn-three", ResourceID: "tnntten-allowed", RoleID: "permrv2-editor", SubjectIDs: []gidx.PrefixedID{"idntusr-subj"}},
+	}
+
+	testCases := []testingx.TestCase[string, *httptest.ResponseRecorder]{
+		{
+			Name:  "InvalidSubject",
+			Input: "not-an-id",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+			},
+		},
+		{
+			Name:  "FilteredByPermission",
+			Input: "idntusr-subj",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("ListSubjectRoleBindings").Return(rbs, nil)
+				engine.On("SubjectHasPermission").Return(nil).Once()
+				engine.On("SubjectHasPermission").Return(query.ErrActionNotAssigned).Once()
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNumberOfCalls(t, "SubjectHasPermission", 2)
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				var resp listRoleBindingsResponse
+
+				require.NoError(t, json.NewDecoder(res.Success.Body).Decode(&resp))
+				require.Len(t, resp.Data, 2)
+
+				assert.Equal(t, "permrbn-one", resp.Data[0].ID.String())
+				assert.Equal(t, "permrbn-three", resp.Data[1].ID.String())
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, subjectID string) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://127.0.0.1/api/v2/subjects/"+subjectID+"/role-bindings", nil)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
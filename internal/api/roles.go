@@ -34,6 +34,10 @@ func (r *Router) roleCreate(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "error parsing request body").SetInternal(err)
 	}
 
+	if err := r.checkRoleLimits(reqBody.Name, reqBody.Actions); err != nil {
+		return r.errorResponse("error creating role", err)
+	}
+
 	subjectResource, err := r.currentSubject(c)
 	if err != nil {
 		return err
@@ -56,9 +60,9 @@ func (r *Router) roleCreate(c echo.Context) error {
 	switch {
 	case err == nil:
 	case errors.Is(err, query.ErrInvalidAction):
-		return echo.NewHTTPError(http.StatusBadRequest, "error creating resource: "+err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, "error creating resource: "+err.Error()).SetInternal(err)
 	case errors.Is(err, storage.ErrRoleAlreadyExists), errors.Is(err, storage.ErrRoleNameTaken):
-		return echo.NewHTTPError(http.StatusConflict, "error creating resource: "+err.Error())
+		return echo.NewHTTPError(http.StatusConflict, "error creating resource: "+err.Error()).SetInternal(err)
 	default:
 		return echo.NewHTTPError(http.StatusInternalServerError, "error creating resource").SetInternal(err)
 	}
@@ -95,6 +99,10 @@ func (r *Router) roleUpdate(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "error parsing request body").SetInternal(err)
 	}
 
+	if err := r.checkRoleLimits(reqBody.Name, reqBody.Actions); err != nil {
+		return r.errorResponse("error updating role", err)
+	}
+
 	subjectResource, err := r.currentSubject(c)
 	if err != nil {
 		return err
@@ -129,9 +137,9 @@ func (r *Router) roleUpdate(c echo.Context) error {
 	switch {
 	case err == nil:
 	case errors.Is(err, query.ErrInvalidAction):
-		return echo.NewHTTPError(http.StatusBadRequest, "error updating resource: "+err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, "error updating resource: "+err.Error()).SetInternal(err)
 	case errors.Is(err, storage.ErrRoleNameTaken):
-		return echo.NewHTTPError(http.StatusConflict, "error updating resource: "+err.Error())
+		return echo.NewHTTPError(http.StatusConflict, "error updating resource: "+err.Error()).SetInternal(err)
 	default:
 		return echo.NewHTTPError(http.StatusInternalServerError, "error updating resource").SetInternal(err)
 	}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -70,6 +71,35 @@ func TestRoleCreate(t *testing.T) {
 				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
 			},
 		},
+		{
+			Name: "NameTooLong",
+			Input: testInput{
+				path: "/api/v1/resources/tnntten-abc123/roles",
+				json: map[string]interface{}{
+					"name": strings.Repeat("a", DefaultMaxRoleNameLength+1),
+					"actions": []string{
+						"action1",
+					},
+				},
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertNotCalled(t, "CreateRole")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.Code)
+				assert.Contains(t, res.Success.Body.String(), LimitRoleNameLength)
+			},
+		},
 		{
 			Name: "ErrRoleAlreadyExists",
 			Input: testInput{
@@ -134,6 +164,38 @@ func TestRoleCreate(t *testing.T) {
 				assert.Equal(t, http.StatusConflict, res.Success.Code)
 			},
 		},
+		{
+			Name: "PermissionDenied",
+			Input: testInput{
+				path: "/api/v1/resources/tnntten-abc123/roles",
+				json: map[string]interface{}{
+					"name": "my role",
+					"actions": []string{
+						"action1",
+						"action2",
+					},
+				},
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("SubjectHasPermission").Return(query.ErrActionNotAssigned)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNotCalled(t, "CreateRole")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
 		{
 			Name: "RoleCreated",
 			Input: testInput{
@@ -352,6 +414,35 @@ func TestRoleUpdate(t *testing.T) {
 				assert.Equal(t, http.StatusNotFound, res.Success.Code)
 			},
 		},
+		{
+			Name: "PermissionDenied",
+			Input: testInput{
+				path: "/api/v1/roles/permrol-abc123",
+				json: map[string]interface{}{
+					"name": "my role",
+				},
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("GetRoleResource").Return(types.Resource{}, nil)
+				engine.On("SubjectHasPermission").Return(query.ErrActionNotAssigned)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNotCalled(t, "UpdateRole")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
 		{
 			Name: "RoleUpdated",
 			Input: testInput{
@@ -636,6 +727,30 @@ func TestRoleDelete(t *testing.T) {
 				assert.Equal(t, http.StatusNotFound, res.Success.Code)
 			},
 		},
+		{
+			Name:  "PermissionDenied",
+			Input: "/api/v1/roles/permrol-abc123",
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("GetRoleResource").Return(types.Resource{}, nil)
+				engine.On("SubjectHasPermission").Return(query.ErrActionNotAssigned)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+				engine.AssertNotCalled(t, "DeleteRole")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusForbidden, res.Success.Code)
+			},
+		},
 		{
 			Name:  "RoleDeleted",
 			Input: "/api/v1/roles/permrol-abc123",
@@ -3,6 +3,7 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,6 +33,10 @@ func (r *Router) roleV2Create(c echo.Context) error {
 		return r.errorResponse(err.Error(), ErrParsingRequestBody)
 	}
 
+	if err := r.checkRoleLimits(reqBody.Name, reqBody.Actions); err != nil {
+		return r.errorResponse("error creating role", err)
+	}
+
 	subjectResource, err := r.currentSubject(c)
 	if err != nil {
 		return err
@@ -63,6 +68,7 @@ func (r *Router) roleV2Create(c echo.Context) error {
 		UpdatedBy:  role.UpdatedBy,
 		CreatedAt:  role.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:  role.UpdatedAt.Format(time.RFC3339),
+		LastUsedAt: formatLastUsed(role.LastUsedAt),
 	}
 
 	return c.JSON(http.StatusCreated, resp)
@@ -86,6 +92,10 @@ func (r *Router) roleV2Update(c echo.Context) error {
 		return r.errorResponse(err.Error(), ErrParsingRequestBody)
 	}
 
+	if err := r.checkRoleLimits(reqBody.Name, reqBody.Actions); err != nil {
+		return r.errorResponse("error updating role", err)
+	}
+
 	subjectResource, err := r.currentSubject(c)
 	if err != nil {
 		return err
@@ -103,13 +113,15 @@ func (r *Router) roleV2Update(c echo.Context) error {
 	}
 
 	role, err := r.engine.UpdateRoleV2(
-		ctx, subjectResource, roleResource,
+		ifMatchContext(ctx, c), subjectResource, roleResource,
 		strings.TrimSpace(reqBody.Name), reqBody.Actions,
 	)
 	if err != nil {
 		return r.errorResponse("error updating role", err)
 	}
 
+	setETag(c, role.ID, role.UpdatedAt)
+
 	resp := roleResponse{
 		ID:         role.ID,
 		Name:       role.Name,
@@ -119,6 +131,7 @@ func (r *Router) roleV2Update(c echo.Context) error {
 		UpdatedBy:  role.UpdatedBy,
 		CreatedAt:  role.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:  role.UpdatedAt.Format(time.RFC3339),
+		LastUsedAt: formatLastUsed(role.LastUsedAt),
 	}
 
 	return c.JSON(http.StatusOK, resp)
@@ -156,6 +169,10 @@ func (r *Router) roleV2Get(c echo.Context) error {
 		return r.errorResponse("error getting role", err)
 	}
 
+	if setETag(c, role.ID, role.UpdatedAt) {
+		return notModified(c)
+	}
+
 	resp := roleResponse{
 		ID:         role.ID,
 		Name:       role.Name,
@@ -165,6 +182,7 @@ func (r *Router) roleV2Get(c echo.Context) error {
 		UpdatedBy:  role.UpdatedBy,
 		CreatedAt:  role.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:  role.UpdatedAt.Format(time.RFC3339),
+		LastUsedAt: formatLastUsed(role.LastUsedAt),
 	}
 
 	return c.JSON(http.StatusOK, resp)
@@ -206,8 +224,9 @@ func (r *Router) roleV2sList(c echo.Context) error {
 
 	for _, role := range roles {
 		roleResp := listRolesV2Role{
-			ID:   role.ID,
-			Name: role.Name,
+			ID:         role.ID,
+			Name:       role.Name,
+			LastUsedAt: formatLastUsed(role.LastUsedAt),
 		}
 
 		resp.Data = append(resp.Data, roleResp)
@@ -227,6 +246,15 @@ func (r *Router) roleV2Delete(c echo.Context) error {
 		return r.errorResponse("error parsing resource ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
 	}
 
+	var force bool
+
+	if forceStr := c.QueryParam("force"); forceStr != "" {
+		force, err = strconv.ParseBool(forceStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "force must be a boolean")
+		}
+	}
+
 	subjectResource, err := r.currentSubject(c)
 	if err != nil {
 		return err
@@ -243,18 +271,40 @@ func (r *Router) roleV2Delete(c echo.Context) error {
 		return err
 	}
 
-	err = r.engine.DeleteRoleV2(ctx, roleResource)
-	if err != nil {
-		return r.errorResponse("error deleting role", err)
-	}
-
 	resp := deleteRoleResponse{
 		Success: true,
 	}
 
+	if force {
+		resp.DeletedRoleBindings, err = r.engine.ForceDeleteRoleV2(ctx, roleResource)
+		span.SetAttributes(attribute.Int("rolebindings.deleted", resp.DeletedRoleBindings))
+	} else {
+		err = r.engine.DeleteRoleV2(ctx, roleResource)
+	}
+
+	if err != nil {
+		return r.errorResponse("error deleting role", err)
+	}
+
 	return c.JSON(http.StatusOK, resp)
 }
 
 func (r *Router) listActions(c echo.Context) error {
 	return c.JSON(http.StatusOK, r.engine.AllActions())
 }
+
+func (r *Router) listActionGroups(c echo.Context) error {
+	groups := r.engine.AllActionGroups()
+
+	resp := make([]actionGroupResponse, len(groups))
+
+	for i, group := range groups {
+		resp[i] = actionGroupResponse{
+			Name:        group.Name,
+			Description: group.Description,
+			Actions:     group.Actions,
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
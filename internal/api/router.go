@@ -11,7 +11,9 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 
+	"go.infratographer.com/permissions-api/internal/graphapi"
 	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
 	"go.infratographer.com/permissions-api/internal/types"
 )
 
@@ -23,7 +25,18 @@ type Router struct {
 	engine query.Engine
 	logger *zap.SugaredLogger
 
-	concurrentChecks int
+	concurrentChecks   int
+	maxFilterResources int
+	limits             LimitsConfig
+	graphQL            graphapi.Config
+	rateLimiter        *rateLimiter
+	impersonation      *impersonation
+	admin              *admin
+	accessRequests     *accessRequests
+	budget             *spicedbx.Budget
+
+	// namespaces are the engines of additional namespaces by name.
+	namespaces map[string]query.Engine
 }
 
 // NewRouter returns a new api router
@@ -38,7 +51,10 @@ func NewRouter(authCfg echojwtx.AuthConfig, engine query.Engine, options ...Opti
 		engine: engine,
 		logger: zap.NewNop().Sugar(),
 
-		concurrentChecks: defaultMaxCheckConcurrency,
+		concurrentChecks:   defaultMaxCheckConcurrency,
+		rateLimiter:        newRateLimiter(RateLimitConfig{}),
+		maxFilterResources: DefaultMaxFilterResources,
+		limits:             defaultLimits,
 	}
 
 	for _, opt := range options {
@@ -52,47 +68,36 @@ func NewRouter(authCfg echojwtx.AuthConfig, engine query.Engine, options ...Opti
 
 // Routes will add the routes for this API version to a router group
 func (r *Router) Routes(rg *echo.Group) {
-	rg.Use(errorMiddleware)
-
-	v1 := rg.Group("api/v1")
-	{
-		v1.Use(r.authMW)
-
-		v1.POST("/resources/:id/roles", r.roleCreate)
-		v1.GET("/resources/:id/roles", r.rolesList)
-		v1.GET("/resources/:id/relationships", r.relationshipListFrom)
-		v1.GET("/relationships/from/:id", r.relationshipListFrom)
-		v1.GET("/relationships/to/:id", r.relationshipListTo)
-		v1.GET("/roles/:role_id", r.roleGet)
-		v1.PATCH("/roles/:role_id", r.roleUpdate)
-		v1.DELETE("/roles/:id", r.roleDelete)
-		v1.GET("/roles/:role_id/resource", r.roleGetResource)
-		v1.POST("/roles/:role_id/assignments", r.assignmentCreate)
-		v1.DELETE("/roles/:role_id/assignments", r.assignmentDelete)
-		v1.GET("/roles/:role_id/assignments", r.assignmentsList)
-
-		// /allow is the permissions check endpoint
-		v1.GET("/allow", r.checkAction)
-		v1.POST("/allow", r.checkAllActions)
-	}
+	rg.Use(requestIDMiddleware, errorMiddleware)
+
+	// the OpenAPI specification is public so clients can be generated from it
+	rg.GET("api/v1/openapi.json", r.openAPI)
+
+	validator := newRequestValidator(documentedOperations)
+
+	r.versionRoutes(rg, validator, r.namespaceMW)
+	r.namespaceRoutes(rg, validator)
 
-	v2 := rg.Group("api/v2")
-	{
-		v2.Use(r.authMW)
+	if r.graphQL.Enabled {
+		gql := graphapi.NewHandler(r.engine, r.logger, r.graphQL)
+
+		rg.GET("query", gql.Handle, r.authMW, r.rateLimitMW, r.budgetMW)
+		rg.POST("query", gql.Handle, r.authMW, r.rateLimitMW, r.budgetMW)
+	}
+}
 
-		v2.POST("/resources/:id/roles", r.roleV2Create)
-		v2.GET("/resources/:id/roles", r.roleV2sList)
-		v2.GET("/roles/:role_id", r.roleV2Get)
-		v2.PATCH("/roles/:role_id", r.roleV2Update)
-		v2.DELETE("/roles/:id", r.roleV2Delete)
+// versionRoutes registers the routes of all API versions, applying the
+// given middleware before any other.
+func (r *Router) versionRoutes(rg *echo.Group, validator *requestValidator, middleware ...echo.MiddlewareFunc) {
+	for _, version := range r.apiVersions() {
+		g := rg.Group("api/" + version.name)
 
-		v2.GET("/resources/:id/role-bindings", r.roleBindingsList)
-		v2.POST("/resources/:id/role-bindings", r.roleBindingCreate)
-		v2.GET("/role-bindings/:rb_id", r.roleBindingGet)
-		v2.DELETE("/role-bindings/:rb_id", r.roleBindingDelete)
-		v2.PATCH("/role-bindings/:rb_id", r.roleBindingUpdate)
+		g.Use(middleware...)
+		g.Use(versionHeaderMiddleware(version.name), consistencyMiddleware)
+		g.Use(version.middleware...)
+		g.Use(r.authMW, r.rateLimitMW, r.budgetMW, validator.middleware)
 
-		v2.GET("/actions", r.listActions)
+		version.routes(g)
 	}
 }
 
@@ -143,7 +148,40 @@ func WithCheckConcurrency(count int) Option {
 	}
 }
 
+// WithGraphQL enables the GraphQL query endpoint when enabled in the config.
+func WithGraphQL(config graphapi.Config) Option {
+	return func(r *Router) error {
+		r.graphQL = config
+
+		return nil
+	}
+}
+
+// WithRateLimit enables per-subject rate limiting when enabled in the config.
+func WithRateLimit(config RateLimitConfig) Option {
+	return func(r *Router) error {
+		r.rateLimiter.setConfig(config)
+
+		return nil
+	}
+}
+
+// SetRateLimit changes the per-subject rate limits of a running router,
+// enabling or disabling rate limiting as set in the config.
+func (r *Router) SetRateLimit(config RateLimitConfig) {
+	r.rateLimiter.setConfig(config)
+}
+
+// rateLimitMW applies the configured rate limit, if enabled.
+func (r *Router) rateLimitMW(next echo.HandlerFunc) echo.HandlerFunc {
+	return r.rateLimiter.middleware(next)
+}
+
 func (r *Router) currentSubject(c echo.Context) (types.Resource, error) {
+	if subject, ok := c.Get(impersonatedSubjectKey).(types.Resource); ok {
+		return subject, nil
+	}
+
 	subjectStr := echojwtx.Actor(c)
 
 	subject, err := gidx.Parse(subjectStr)
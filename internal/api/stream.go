@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// MIMEApplicationNDJSON is the media type of newline delimited JSON. List
+	// endpoints supporting streaming write one item per line when requested
+	// with this media type in the Accept header.
+	MIMEApplicationNDJSON = "application/x-ndjson"
+
+	// streamFlushSize is the number of items written before the response is flushed.
+	streamFlushSize = 100
+)
+
+// streamError is written as the last line of a stream which failed after
+// the response was started.
+type streamError struct {
+	Error string `json:"error"`
+}
+
+// wantsStream reports whether the client accepts a newline delimited JSON response.
+func wantsStream(c echo.Context) bool {
+	for _, accept := range strings.Split(c.Request().Header.Get(echo.HeaderAccept), ",") {
+		mediaType, _, _ := strings.Cut(strings.TrimSpace(accept), ";")
+
+		if mediaType == MIMEApplicationNDJSON {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ndjsonWriter writes items of a list as newline delimited JSON. The response
+// is started with the first item, so errors before it are returned as usual.
+type ndjsonWriter struct {
+	c       echo.Context
+	enc     *json.Encoder
+	started bool
+	pending int
+}
+
+func newNDJSONWriter(c echo.Context) *ndjsonWriter {
+	return &ndjsonWriter{
+		c:   c,
+		enc: json.NewEncoder(c.Response()),
+	}
+}
+
+func (w *ndjsonWriter) start() {
+	if w.started {
+		return
+	}
+
+	w.started = true
+
+	w.c.Response().Header().Set(echo.HeaderContentType, MIMEApplicationNDJSON)
+	w.c.Response().WriteHeader(http.StatusOK)
+}
+
+// Write writes an item as a single line.
+func (w *ndjsonWriter) Write(item any) error {
+	w.start()
+
+	if err := w.enc.Encode(item); err != nil {
+		return err
+	}
+
+	w.pending++
+
+	if w.pending >= streamFlushSize {
+		w.c.Response().Flush()
+
+		w.pending = 0
+	}
+
+	return nil
+}
+
+// Close completes the stream. If the stream failed after the response was
+// started, the error is logged and written as the last line, otherwise the
+// error is returned to be handled as any other error.
+func (w *ndjsonWriter) Close(err error) error {
+	if err != nil && !w.started {
+		return err
+	}
+
+	w.start()
+
+	if err != nil {
+		w.c.Logger().Error(err)
+
+		if encErr := w.enc.Encode(streamError{Error: "error streaming response"}); encErr != nil {
+			w.c.Logger().Error(encErr)
+		}
+	}
+
+	w.c.Response().Flush()
+
+	return nil
+}
+
+// sseKeepAliveInterval is how often a comment is written to idle event
+// streams, so proxies do not close them.
+const sseKeepAliveInterval = 30 * time.Second
+
+// sseWriter writes server-sent events. Writes are serialized as keep-alive
+// comments are written concurrently with events.
+type sseWriter struct {
+	mu sync.Mutex
+	c  echo.Context
+}
+
+func newSSEWriter(c echo.Context) *sseWriter {
+	c.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
+	c.Response().WriteHeader(http.StatusOK)
+	c.Response().Flush()
+
+	return &sseWriter{c: c}
+}
+
+// Write writes an event with the given ID and type, the data is encoded as JSON.
+func (w *sseWriter) Write(id, event string, data any) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w.c.Response(), "id: %s\nevent: %s\ndata: %s\n\n", id, event, body); err != nil {
+		return err
+	}
+
+	w.c.Response().Flush()
+
+	return nil
+}
+
+// keepAlive writes a comment every sseKeepAliveInterval until the context is done.
+func (w *sseWriter) keepAlive(ctx context.Context) {
+	ticker := time.NewTicker(sseKeepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		w.mu.Lock()
+
+		if _, err := io.WriteString(w.c.Response(), ": keep-alive\n\n"); err == nil {
+			w.c.Response().Flush()
+		}
+
+		w.mu.Unlock()
+	}
+}
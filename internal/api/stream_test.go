@@ -0,0 +1,181 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testauth"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestRelationshipListStream(t *testing.T) {
+	ctx := context.Background()
+
+	authsrv := testauth.NewServer(t)
+
+	rels := []types.Relationship{
+		{Relation: "parent", Subject: types.Resource{Type: "tenant", ID: "tnntten-parent"}},
+		{Relation: "owner", Subject: types.Resource{Type: "tenant", ID: "tnntten-owner"}},
+	}
+
+	errStream := errors.New("stream failed")
+
+	readLines := func(t *testing.T, res *httptest.ResponseRecorder) []map[string]string {
+		var lines []map[string]string
+
+		scanner := bufio.NewScanner(res.Body)
+
+		for scanner.Scan() {
+			var line map[string]string
+
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+
+			lines = append(lines, line)
+		}
+
+		return lines
+	}
+
+	testCases := []testingx.TestCase[string, *httptest.ResponseRecorder]{
+		{
+			Name:  "Streamed",
+			Input: MIMEApplicationNDJSON,
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("StreamRelationshipsFrom").Return(rels, nil)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+				assert.Equal(t, MIMEApplicationNDJSON, res.Success.Header().Get(echo.HeaderContentType))
+
+				assert.Equal(t, []map[string]string{
+					{"relation": "parent", "subject_id": "tnntten-parent"},
+					{"relation": "owner", "subject_id": "tnntten-owner"},
+				}, readLines(t, res.Success))
+			},
+		},
+		{
+			Name:  "FailedAfterStart",
+			Input: MIMEApplicationNDJSON,
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("StreamRelationshipsFrom").Return(rels[:1], errStream)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+
+				lines := readLines(t, res.Success)
+
+				require.Len(t, lines, 2)
+				assert.NotEmpty(t, lines[1]["error"])
+			},
+		},
+		{
+			Name:  "FailedBeforeStart",
+			Input: MIMEApplicationNDJSON,
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				engine.On("StreamRelationshipsFrom").Return([]types.Relationship{}, errStream)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusInternalServerError, res.Success.Code)
+			},
+		},
+		{
+			Name:  "NotRequested",
+			Input: echo.MIMEApplicationJSON,
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				engine := mock.Engine{
+					Namespace: "test",
+				}
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[*httptest.ResponseRecorder]) {
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertNotCalled(t, "StreamRelationshipsFrom")
+
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success)
+
+				assert.Equal(t, http.StatusOK, res.Success.Code)
+				assert.Contains(t, res.Success.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, accept string) testingx.TestResult[*httptest.ResponseRecorder] {
+		result := testingx.TestResult[*httptest.ResponseRecorder]{}
+
+		engine := ctx.Value(contextKeyEngine).(query.Engine)
+
+		router, err := NewRouter(echojwtx.AuthConfig{Issuer: authsrv.Issuer}, engine)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		e := echo.New()
+		e.Use(echoTestLogger(t, e))
+
+		router.Routes(e.Group(""))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://127.0.0.1/api/v1/relationships/from/tnntten-abc123", nil)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		req.Header.Set(echo.HeaderAccept, accept)
+		req.Header.Set("Authorization", "Bearer "+authsrv.TSignSubject(t, "idntusr-abc123"))
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		result.Success = resp
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
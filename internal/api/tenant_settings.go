@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// tenantSettingsAuthorize resolves the tenant of the request and ensures the
+// current subject may perform the action on its settings.
+func (r *Router) tenantSettingsAuthorize(ctx context.Context, c echo.Context, action iapl.TenantSettingsAction) (types.Resource, types.Resource, error) {
+	tenantID, err := gidx.Parse(c.Param("id"))
+	if err != nil {
+		return types.Resource{}, types.Resource{}, r.errorResponse("error parsing tenant ID", fmt.Errorf("%w: %s", ErrInvalidID, err.Error()))
+	}
+
+	tenant, err := r.engine.NewResourceFromID(tenantID)
+	if err != nil {
+		return types.Resource{}, types.Resource{}, r.errorResponse("error creating tenant resource", err)
+	}
+
+	actor, err := r.currentSubject(c)
+	if err != nil {
+		return types.Resource{}, types.Resource{}, err
+	}
+
+	if err := r.checkActionWithResponse(ctx, actor, string(action), tenant); err != nil {
+		return types.Resource{}, types.Resource{}, err
+	}
+
+	return actor, tenant, nil
+}
+
+func (r *Router) tenantSettingsGet(c echo.Context) error {
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.tenantSettingsGet",
+		trace.WithAttributes(attribute.String("id", c.Param("id"))),
+	)
+	defer span.End()
+
+	_, tenant, err := r.tenantSettingsAuthorize(ctx, c, iapl.TenantSettingsActionGet)
+	if err != nil {
+		return err
+	}
+
+	settings, err := r.engine.GetTenantSettings(ctx, tenant)
+	if err != nil {
+		return r.errorResponse("error getting tenant settings", err)
+	}
+
+	return c.JSON(http.StatusOK, newTenantSettingsResponse(settings))
+}
+
+// tenantSettingsUpdate updates the settings of a tenant, settings missing
+// from the request are kept as they are.
+func (r *Router) tenantSettingsUpdate(c echo.Context) error {
+	ctx, span := tracer.Start(
+		c.Request().Context(), "api.tenantSettingsUpdate",
+		trace.WithAttributes(attribute.String("id", c.Param("id"))),
+	)
+	defer span.End()
+
+	var body tenantSettingsRequest
+
+	if err := c.Bind(&body); err != nil {
+		return r.errorResponse(err.Error(), ErrParsingRequestBody)
+	}
+
+	actor, tenant, err := r.tenantSettingsAuthorize(ctx, c, iapl.TenantSettingsActionUpdate)
+	if err != nil {
+		return err
+	}
+
+	settings, err := r.engine.GetTenantSettings(ctx, tenant)
+	if err != nil {
+		return r.errorResponse("error getting tenant settings", err)
+	}
+
+	if body.AllowGroupBindings != nil {
+		settings.AllowGroupBindings = *body.AllowGroupBindings
+	}
+
+	if body.InheritParentRoles != nil {
+		settings.InheritParentRoles = *body.InheritParentRoles
+	}
+
+	settings, err = r.engine.UpdateTenantSettings(ctx, actor, tenant, settings)
+	if err != nil {
+		return r.errorResponse("error updating tenant settings", err)
+	}
+
+	return c.JSON(http.StatusOK, newTenantSettingsResponse(settings))
+}
+
+func newTenantSettingsResponse(settings types.TenantSettings) tenantSettingsResponse {
+	resp := tenantSettingsResponse{
+		TenantID:           settings.TenantID,
+		AllowGroupBindings: settings.AllowGroupBindings,
+		InheritParentRoles: settings.InheritParentRoles,
+		UpdatedBy:          settings.UpdatedBy,
+	}
+
+	if !settings.UpdatedAt.IsZero() {
+		resp.UpdatedAt = settings.UpdatedAt.Format(time.RFC3339)
+	}
+
+	return resp
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"time"
+
 	"go.infratographer.com/x/gidx"
 )
 
@@ -10,8 +12,8 @@ type createRoleRequest struct {
 }
 
 type updateRoleRequest struct {
-	Name    string   `json:"name"`
-	Actions []string `json:"actions"`
+	Name    string   `json:"name,omitempty"`
+	Actions []string `json:"actions,omitempty"`
 }
 
 type roleResponse struct {
@@ -24,6 +26,7 @@ type roleResponse struct {
 	UpdatedBy  gidx.PrefixedID `json:"updated_by"`
 	CreatedAt  string          `json:"created_at"`
 	UpdatedAt  string          `json:"updated_at"`
+	LastUsedAt string          `json:"last_used_at,omitempty"`
 }
 
 type resourceResponse struct {
@@ -32,6 +35,9 @@ type resourceResponse struct {
 
 type deleteRoleResponse struct {
 	Success bool `json:"success"`
+	// DeletedRoleBindings is the number of role-bindings deleted with the role
+	// when force deleting it.
+	DeletedRoleBindings int `json:"deleted_role_bindings,omitempty"`
 }
 
 type listRolesResponse struct {
@@ -48,6 +54,54 @@ type listRelationshipsResponse struct {
 	Data []relationshipItem `json:"data"`
 }
 
+type relationshipWriteRequest struct {
+	Operation  string `json:"operation"`
+	ResourceID string `json:"resource_id"`
+	Relation   string `json:"relation"`
+	SubjectID  string `json:"subject_id"`
+}
+
+type relationshipPreconditionRequest struct {
+	ResourceID string `json:"resource_id"`
+	Relation   string `json:"relation"`
+	SubjectID  string `json:"subject_id"`
+	MustExist  bool   `json:"must_exist"`
+}
+
+type bulkRelationshipsRequest struct {
+	Writes        []relationshipWriteRequest        `json:"writes"`
+	Preconditions []relationshipPreconditionRequest `json:"preconditions,omitempty"`
+}
+
+type bulkRelationshipsResponse struct {
+	Written int `json:"written"`
+}
+
+type relationshipRecordResponse struct {
+	ResourceType    string `json:"resource_type"`
+	ResourceID      string `json:"resource_id"`
+	Relation        string `json:"relation"`
+	SubjectType     string `json:"subject_type"`
+	SubjectID       string `json:"subject_id"`
+	SubjectRelation string `json:"subject_relation,omitempty"`
+}
+
+type readRelationshipsResponse struct {
+	Data       []relationshipRecordResponse `json:"data"`
+	NextCursor string                       `json:"next_cursor,omitempty"`
+}
+
+type relationshipChangeResponse struct {
+	Operation       string `json:"operation"`
+	ResourceType    string `json:"resource_type"`
+	ResourceID      string `json:"resource_id"`
+	Relation        string `json:"relation"`
+	SubjectType     string `json:"subject_type"`
+	SubjectID       string `json:"subject_id"`
+	SubjectRelation string `json:"subject_relation,omitempty"`
+	ZedToken        string `json:"zedtoken"`
+}
+
 type createAssignmentRequest struct {
 	SubjectID string `json:"subject_id" binding:"required"`
 }
@@ -77,15 +131,44 @@ type listRolesV2Response struct {
 }
 
 type listRolesV2Role struct {
-	ID   gidx.PrefixedID `json:"id"`
-	Name string          `json:"name"`
+	ID         gidx.PrefixedID `json:"id"`
+	Name       string          `json:"name"`
+	LastUsedAt string          `json:"last_used_at,omitempty"`
+}
+
+type subjectPermissionsResponse struct {
+	ResourceID gidx.PrefixedID `json:"resource_id"`
+	SubjectID  gidx.PrefixedID `json:"subject_id"`
+	Actions    []string        `json:"actions"`
+}
+
+type actionGroupResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Actions     []string `json:"actions"`
+}
+
+type policyResponse struct {
+	PolicyHash     string `json:"policy_hash"`
+	SchemaHash     string `json:"schema_hash"`
+	LiveSchemaHash string `json:"live_schema_hash,omitempty"`
+	SchemaMatches  bool   `json:"schema_matches"`
+	LoadedAt       string `json:"loaded_at"`
 }
 
 // RoleBindings
 
 type roleBindingRequest struct {
-	RoleID     string            `json:"role_id" binding:"required"`
-	SubjectIDs []gidx.PrefixedID `json:"subject_ids" binding:"required"`
+	RoleID     string             `json:"role_id" binding:"required"`
+	SubjectIDs []gidx.PrefixedID  `json:"subject_ids" binding:"required"`
+	Caveat     *roleBindingCaveat `json:"caveat,omitempty"`
+}
+
+// roleBindingCaveat is a policy caveat a role-binding is conditioned on,
+// with the context values fixed by the role-binding.
+type roleBindingCaveat struct {
+	Name    string         `json:"name" binding:"required"`
+	Context map[string]any `json:"context,omitempty"`
 }
 
 type rolebindingUpdateRequest struct {
@@ -93,10 +176,148 @@ type rolebindingUpdateRequest struct {
 }
 
 type roleBindingResponse struct {
-	ID         gidx.PrefixedID   `json:"id"`
-	ResourceID gidx.PrefixedID   `json:"resource_id"`
-	RoleID     gidx.PrefixedID   `json:"role_id"`
-	SubjectIDs []gidx.PrefixedID `json:"subject_ids"`
+	ID         gidx.PrefixedID    `json:"id"`
+	ResourceID gidx.PrefixedID    `json:"resource_id"`
+	RoleID     gidx.PrefixedID    `json:"role_id"`
+	SubjectIDs []gidx.PrefixedID  `json:"subject_ids"`
+	Caveat     *roleBindingCaveat `json:"caveat,omitempty"`
+
+	CreatedBy  gidx.PrefixedID `json:"created_by"`
+	UpdatedBy  gidx.PrefixedID `json:"updated_by"`
+	CreatedAt  string          `json:"created_at"`
+	UpdatedAt  string          `json:"updated_at"`
+	LastUsedAt string          `json:"last_used_at,omitempty"`
+}
+
+type listRoleBindingsResponse struct {
+	Data []roleBindingResponse `json:"data"`
+}
+
+type deleteRoleBindingResponse struct {
+	Success bool `json:"success"`
+}
+
+type bulkRoleBindingsRequest struct {
+	Create []roleBindingRequest `json:"create,omitempty"`
+	Delete []gidx.PrefixedID    `json:"delete,omitempty"`
+}
+
+type bulkRoleBindingResult struct {
+	ID          gidx.PrefixedID      `json:"id,omitempty"`
+	RoleBinding *roleBindingResponse `json:"role_binding,omitempty"`
+	Status      int                  `json:"status"`
+	Error       string               `json:"error,omitempty"`
+}
+
+type bulkRoleBindingsResponse struct {
+	Failed  int                     `json:"failed"`
+	Created []bulkRoleBindingResult `json:"created"`
+	Deleted []bulkRoleBindingResult `json:"deleted"`
+}
+
+type listStaleRoleBindingsResponse struct {
+	UnusedSince string                `json:"unused_since"`
+	Data        []roleBindingResponse `json:"data"`
+}
+
+type roleBindingSuggestionResponse struct {
+	RoleBinding   roleBindingResponse `json:"role_binding"`
+	UsedActions   []string            `json:"used_actions"`
+	UnusedActions []string            `json:"unused_actions"`
+	// Remove is set when none of the actions granted by the role-binding were used.
+	Remove bool `json:"remove"`
+}
+
+type listRoleBindingSuggestionsResponse struct {
+	UsedSince string                          `json:"used_since"`
+	Data      []roleBindingSuggestionResponse `json:"data"`
+}
+
+// Invitations
+
+type invitationRequest struct {
+	RoleID        string `json:"role_id" binding:"required"`
+	Email         string `json:"email,omitempty"`
+	ExpiresInDays int    `json:"expires_in_days,omitempty"`
+}
+
+type invitationResponse struct {
+	ID         gidx.PrefixedID `json:"id"`
+	ResourceID gidx.PrefixedID `json:"resource_id"`
+	RoleID     gidx.PrefixedID `json:"role_id"`
+	Email      string          `json:"email,omitempty"`
+	// Token is only returned when the invitation is created.
+	Token string `json:"token,omitempty"`
+
+	CreatedBy gidx.PrefixedID `json:"created_by"`
+	CreatedAt string          `json:"created_at"`
+	ExpiresAt string          `json:"expires_at"`
+
+	RedeemedBy    gidx.PrefixedID `json:"redeemed_by,omitempty"`
+	RedeemedAt    string          `json:"redeemed_at,omitempty"`
+	RoleBindingID gidx.PrefixedID `json:"role_binding_id,omitempty"`
+}
+
+type listInvitationsResponse struct {
+	Data []invitationResponse `json:"data"`
+}
+
+type deleteInvitationResponse struct {
+	Success bool `json:"success"`
+}
+
+type redeemInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Access requests
+
+type accessRequestRequest struct {
+	ResourceID string `json:"resource_id" binding:"required"`
+	RoleID     string `json:"role_id" binding:"required"`
+	Reason     string `json:"reason" binding:"required"`
+}
+
+type accessRequestDecisionRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type accessRequestResponse struct {
+	ID         gidx.PrefixedID `json:"id"`
+	ResourceID gidx.PrefixedID `json:"resource_id"`
+	RoleID     gidx.PrefixedID `json:"role_id"`
+	SubjectID  gidx.PrefixedID `json:"subject_id"`
+	Reason     string          `json:"reason"`
+	Status     string          `json:"status"`
+	CreatedAt  string          `json:"created_at"`
+
+	DecidedBy      gidx.PrefixedID `json:"decided_by,omitempty"`
+	DecidedAt      string          `json:"decided_at,omitempty"`
+	DecisionReason string          `json:"decision_reason,omitempty"`
+	RoleBindingID  gidx.PrefixedID `json:"role_binding_id,omitempty"`
+}
+
+type listAccessRequestsResponse struct {
+	Data []accessRequestResponse `json:"data"`
+}
+
+// Groups
+
+type groupRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+type updateGroupRequest struct {
+	Name        string  `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+type groupResponse struct {
+	ID          gidx.PrefixedID `json:"id"`
+	OwnerID     gidx.PrefixedID `json:"owner_id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
 
 	CreatedBy gidx.PrefixedID `json:"created_by"`
 	UpdatedBy gidx.PrefixedID `json:"updated_by"`
@@ -104,10 +325,77 @@ type roleBindingResponse struct {
 	UpdatedAt string          `json:"updated_at"`
 }
 
-type listRoleBindingsResponse struct {
-	Data []roleBindingResponse `json:"data"`
+type listGroupsResponse struct {
+	Data []groupResponse `json:"data"`
 }
 
-type deleteRoleBindingResponse struct {
+type deleteGroupResponse struct {
 	Success bool `json:"success"`
 }
+
+type groupMembersRequest struct {
+	MemberIDs []gidx.PrefixedID `json:"member_ids" binding:"required"`
+}
+
+type groupMembersResponse struct {
+	Data []gidx.PrefixedID `json:"data"`
+}
+
+type expandedGroupMemberResponse struct {
+	ID     gidx.PrefixedID   `json:"id"`
+	Type   string            `json:"type"`
+	Direct bool              `json:"direct"`
+	Groups []gidx.PrefixedID `json:"groups"`
+}
+
+type expandedGroupMembersResponse struct {
+	Data []expandedGroupMemberResponse `json:"data"`
+}
+
+// Tenant settings
+
+type tenantSettingsRequest struct {
+	AllowGroupBindings *bool `json:"allow_group_bindings"`
+	InheritParentRoles *bool `json:"inherit_parent_roles"`
+}
+
+type tenantSettingsResponse struct {
+	TenantID           gidx.PrefixedID `json:"tenant_id"`
+	AllowGroupBindings bool            `json:"allow_group_bindings"`
+	InheritParentRoles bool            `json:"inherit_parent_roles"`
+
+	UpdatedBy gidx.PrefixedID `json:"updated_by,omitempty"`
+	UpdatedAt string          `json:"updated_at,omitempty"`
+}
+
+// Resource aliases
+
+type resourceAliasRequest struct {
+	Alias string `json:"alias" binding:"required"`
+}
+
+type resourceAliasResponse struct {
+	Alias      string          `json:"alias"`
+	ResourceID gidx.PrefixedID `json:"resource_id"`
+
+	CreatedBy gidx.PrefixedID `json:"created_by"`
+	CreatedAt string          `json:"created_at"`
+}
+
+type listResourceAliasesResponse struct {
+	Data []resourceAliasResponse `json:"data"`
+}
+
+type deleteResourceAliasResponse struct {
+	Success bool `json:"success"`
+}
+
+// formatLastUsed formats a last used time, roles and role-bindings which were
+// never used have no last used time.
+func formatLastUsed(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// maxValidationErrors limits the number of validation errors reported in a response.
+const maxValidationErrors = 10
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// requestValidator validates requests against the schemas of the documented
+// API operations, so handlers only receive well formed requests.
+type requestValidator struct {
+	components map[string]any
+	operations map[string]validatedOperation
+}
+
+type validatedOperation struct {
+	query []string
+	body  map[string]any
+}
+
+func newRequestValidator(operations []apiOperation) *requestValidator {
+	gen := &schemaGenerator{components: map[string]any{}}
+
+	v := &requestValidator{
+		components: gen.components,
+		operations: make(map[string]validatedOperation, len(operations)),
+	}
+
+	for _, op := range operations {
+		vop := validatedOperation{query: op.Query}
+
+		if op.Request != nil {
+			vop.body = gen.schema(reflect.TypeOf(op.Request))
+		}
+
+		v.operations[op.Method+" "+op.Path] = vop
+	}
+
+	return v
+}
+
+// middleware rejects requests with missing query parameters, request bodies
+// which are not JSON or not matching the operation's schema. Errors reference the invalid value with
+// a JSON pointer, e.g. /actions/0/action.
+func (v *requestValidator) middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		op, ok := v.operations[c.Request().Method+" "+routePath(c)]
+		if !ok {
+			return next(c)
+		}
+
+		for _, name := range op.query {
+			if !c.QueryParams().Has(name) {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("missing %s query parameter", name))
+			}
+		}
+
+		if op.body == nil {
+			return next(c)
+		}
+
+		// bodies are only validated as JSON, other content types would be
+		// bound by the handlers without being validated.
+		if ctype := c.Request().Header.Get(echo.HeaderContentType); ctype != "" && !strings.HasPrefix(ctype, echo.MIMEApplicationJSON) {
+			return echo.NewHTTPError(http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported content type %s, expected %s", ctype, echo.MIMEApplicationJSON))
+		}
+
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "error reading request body").SetInternal(err)
+		}
+
+		c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+		if len(bytes.TrimSpace(body)) == 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "request body is required")
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+
+		var value any
+
+		if err := dec.Decode(&value); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "error parsing request body").SetInternal(err)
+		}
+
+		if errs := v.validate(op.body, value, ""); len(errs) != 0 {
+			if len(errs) > maxValidationErrors {
+				errs = errs[:maxValidationErrors]
+			}
+
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid request body: "+strings.Join(errs, "; "))
+		}
+
+		return next(c)
+	}
+}
+
+// validate checks value against the schema, returning an error for every
+// invalid value prefixed with its JSON pointer.
+func (v *requestValidator) validate(schema map[string]any, value any, pointer string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		component, _ := v.components[strings.TrimPrefix(ref, "#/components/schemas/")].(map[string]any)
+
+		return v.validate(component, value, pointer)
+	}
+
+	invalid := func(msg string) []string {
+		if pointer == "" {
+			return []string{"/: " + msg}
+		}
+
+		return []string{pointer + ": " + msg}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return invalid("expected object")
+		}
+
+		var errs []string
+
+		required, _ := schema["required"].([]string)
+
+		for _, name := range required {
+			if _, ok := obj[name]; !ok {
+				errs = append(errs, pointer+"/"+jsonPointerEscaper.Replace(name)+": missing required property")
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]any)
+		additional, _ := schema["additionalProperties"].(map[string]any)
+
+		names := maps.Keys(obj)
+		slices.Sort(names)
+
+		for _, name := range names {
+			val := obj[name]
+
+			propSchema, ok := properties[name].(map[string]any)
+			if !ok {
+				if additional == nil {
+					continue
+				}
+
+				propSchema = additional
+			}
+
+			// optional properties may be null
+			if val == nil && !slices.Contains(required, name) {
+				continue
+			}
+
+			errs = append(errs, v.validate(propSchema, val, pointer+"/"+jsonPointerEscaper.Replace(name))...)
+		}
+
+		return errs
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			return invalid("expected array")
+		}
+
+		items, _ := schema["items"].(map[string]any)
+
+		var errs []string
+
+		for i, item := range arr {
+			errs = append(errs, v.validate(items, item, fmt.Sprintf("%s/%d", pointer, i))...)
+		}
+
+		return errs
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return invalid("expected string")
+		}
+
+		if minLength, ok := schema["minLength"].(int); ok && len(str) < minLength {
+			return invalid(fmt.Sprintf("must be at least %d characters", minLength))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return invalid("expected boolean")
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return invalid("expected integer")
+		}
+
+		if _, err := n.Int64(); err != nil {
+			return invalid("expected integer")
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return invalid("expected number")
+		}
+	}
+
+	return nil
+}
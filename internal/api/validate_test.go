@@ -0,0 +1,233 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+)
+
+func TestRequestValidator(t *testing.T) {
+	ctx := context.Background()
+
+	validator := newRequestValidator([]apiOperation{
+		{http.MethodPost, "/allow", "checkAllActions", "", nil, checkPermissionsRequest{}, nil, http.StatusOK},
+		{http.MethodGet, "/allow", "checkAction", "", []string{"resource", "action"}, nil, nil, http.StatusOK},
+	})
+
+	e := echo.New()
+	e.Use(echoTestLogger(t, e))
+	e.Use(validator.middleware)
+
+	handler := func(c echo.Context) error {
+		var body checkPermissionsRequest
+
+		if c.Request().Method == http.MethodPost {
+			if err := c.Bind(&body); err != nil {
+				return err
+			}
+		}
+
+		return c.JSON(http.StatusOK, body)
+	}
+
+	e.GET("/allow", handler)
+	e.POST("/allow", handler)
+
+	type testInput struct {
+		method      string
+		path        string
+		body        string
+		contentType string
+	}
+
+	type testResult struct {
+		code int
+		body string
+	}
+
+	testCases := []testingx.TestCase[testInput, testResult]{
+		{
+			Name:  "MissingQueryParam",
+			Input: testInput{method: http.MethodGet, path: "/allow?resource=tnntten-abc123"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[testResult]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.code)
+				assert.Contains(t, res.Success.body, "missing action query parameter")
+			},
+		},
+		{
+			Name:  "QueryParams",
+			Input: testInput{method: http.MethodGet, path: "/allow?resource=tnntten-abc123&action=role_get"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[testResult]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusOK, res.Success.code)
+			},
+		},
+		{
+			Name:  "MissingBody",
+			Input: testInput{method: http.MethodPost, path: "/allow"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[testResult]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.code)
+				assert.Contains(t, res.Success.body, "request body is required")
+			},
+		},
+		{
+			Name:  "InvalidType",
+			Input: testInput{method: http.MethodPost, path: "/allow", body: `{"actions": {}}`},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[testResult]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.code)
+				assert.Contains(t, res.Success.body, "/actions: expected array")
+			},
+		},
+		{
+			Name:  "InvalidItems",
+			Input: testInput{method: http.MethodPost, path: "/allow", body: `{"actions": [{"resource_id": "tnntten-abc123", "action": "role_get"}, {"resource_id": 1, "action": ""}, {}]}`},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[testResult]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.code)
+				assert.Contains(t, res.Success.body, "/actions/1/action: must be at least 1 characters")
+				assert.Contains(t, res.Success.body, "/actions/1/resource_id: expected string")
+				assert.Contains(t, res.Success.body, "/actions/2/resource_id: missing required property")
+				assert.NotContains(t, res.Success.body, "/actions/0")
+			},
+		},
+		{
+			Name: "UnsupportedContentType",
+			Input: testInput{
+				method:      http.MethodPost,
+				path:        "/allow",
+				body:        "actions[0][resource_id]=tnntten-abc123&actions[0][action]=",
+				contentType: echo.MIMEApplicationForm,
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[testResult]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusUnsupportedMediaType, res.Success.code)
+			},
+		},
+		{
+			Name:  "Valid",
+			Input: testInput{method: http.MethodPost, path: "/allow", body: `{"actions": [{"resource_id": "tnntten-abc123", "action": "role_get"}]}`},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[testResult]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusOK, res.Success.code)
+				assert.Contains(t, res.Success.body, "tnntten-abc123")
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input testInput) testingx.TestResult[testResult] {
+		req, err := http.NewRequestWithContext(ctx, input.method, input.path, strings.NewReader(input.body))
+		if err != nil {
+			return testingx.TestResult[testResult]{Err: err}
+		}
+
+		contentType := input.contentType
+		if contentType == "" {
+			contentType = echo.MIMEApplicationJSON
+		}
+
+		req.Header.Set(echo.HeaderContentType, contentType)
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		return testingx.TestResult[testResult]{Success: testResult{code: resp.Code, body: resp.Body.String()}}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
+
+// TestRequestValidatorMinimalPayloads ensures payloads omitting optional
+// properties, which handlers have always accepted, are not rejected.
+func TestRequestValidatorMinimalPayloads(t *testing.T) {
+	ctx := context.Background()
+
+	validator := newRequestValidator(documentedOperations)
+
+	type testInput struct {
+		operation string
+		body      string
+	}
+
+	testCases := []testingx.TestCase[testInput, []string]{
+		{
+			Name:  "CheckWithoutActions",
+			Input: testInput{operation: "POST /api/v1/allow", body: `{}`},
+		},
+		{
+			Name:  "CheckWithActions",
+			Input: testInput{operation: "POST /api/v1/allow", body: `{"actions": [{"resource_id": "tnntten-abc123", "action": "role_get"}]}`},
+		},
+		{
+			Name:  "UpdateRoleName",
+			Input: testInput{operation: "PATCH /api/v2/roles/:role_id", body: `{"name": "viewer"}`},
+		},
+		{
+			Name:  "TenantSettings",
+			Input: testInput{operation: "PATCH /api/v2/resources/:id/settings", body: `{"allow_group_bindings": true}`},
+		},
+		{
+			Name: "BulkRelationshipsWithoutMustExist",
+			Input: testInput{
+				operation: "POST /api/v2/admin/relationships/bulk",
+				body: `{
+					"writes": [{"operation": "create", "resource_id": "tnntten-abc123", "relation": "parent", "subject_id": "tnntten-def456"}],
+					"preconditions": [{"resource_id": "tnntten-abc123", "relation": "parent", "subject_id": "tnntten-ghi789"}]
+				}`,
+			},
+		},
+		{
+			Name:  "AccessRequestDecisionWithoutReason",
+			Input: testInput{operation: "POST /api/v2/access-requests/:request_id/approve", body: `{}`},
+		},
+	}
+
+	for i := range testCases {
+		testCases[i].CheckFn = func(_ context.Context, t *testing.T, res testingx.TestResult[[]string]) {
+			require.NoError(t, res.Err)
+
+			assert.Empty(t, res.Success)
+		}
+	}
+
+	testFn := func(_ context.Context, input testInput) testingx.TestResult[[]string] {
+		op, ok := validator.operations[input.operation]
+		if !ok || op.body == nil {
+			return testingx.TestResult[[]string]{Err: fmt.Errorf("no request schema for %s", input.operation)}
+		}
+
+		dec := json.NewDecoder(bytes.NewReader([]byte(input.body)))
+		dec.UseNumber()
+
+		var value any
+
+		if err := dec.Decode(&value); err != nil {
+			return testingx.TestResult[[]string]{Err: err}
+		}
+
+		return testingx.TestResult[[]string]{Success: validator.validate(op.body, value, "")}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
@@ -0,0 +1,258 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/storage"
+)
+
+// APIVersionHeader is the response header reporting the API version which served the request.
+const APIVersionHeader = "X-API-Version"
+
+// apiVersion registers the routes of a single API version under /api/<name>.
+// Versions may share handlers, breaking changes to the shape of responses are
+// introduced as version specific middleware or handlers in a new version, so
+// existing versions remain stable for their clients.
+type apiVersion struct {
+	// name is the path prefix of the version, e.g. v2
+	name string
+	// middleware is applied to all routes of the version, before authentication.
+	middleware []echo.MiddlewareFunc
+	// routes registers the handlers of the version.
+	routes func(g *echo.Group)
+}
+
+// apiVersions returns the API versions served by the router, in order.
+func (r *Router) apiVersions() []apiVersion {
+	return []apiVersion{
+		{
+			name:   "v1",
+			routes: r.v1Routes,
+		},
+		{
+			name:   "v2",
+			routes: r.v2Routes,
+		},
+		{
+			// v3 serves the v2 handlers with structured error responses.
+			name:       "v3",
+			middleware: []echo.MiddlewareFunc{r.structuredErrorMiddleware},
+			routes:     r.v2Routes,
+		},
+	}
+}
+
+func (r *Router) v1Routes(v1 *echo.Group) {
+	v1.POST("/resources/:id/roles", r.roleCreate)
+	v1.GET("/resources/:id/roles", r.rolesList)
+	v1.GET("/resources/:id/relationships", r.relationshipListFrom)
+	v1.GET("/relationships/from/:id", r.relationshipListFrom)
+	v1.GET("/relationships/to/:id", r.relationshipListTo)
+	v1.GET("/roles/:role_id", r.roleGet)
+	v1.PATCH("/roles/:role_id", r.roleUpdate)
+	v1.DELETE("/roles/:id", r.roleDelete)
+	v1.GET("/roles/:role_id/resource", r.roleGetResource)
+	v1.POST("/roles/:role_id/assignments", r.assignmentCreate)
+	v1.DELETE("/roles/:role_id/assignments", r.assignmentDelete)
+	v1.GET("/roles/:role_id/assignments", r.assignmentsList)
+
+	// /allow is the permissions check endpoint
+	v1.GET("/allow", r.checkAction, r.impersonationMW)
+	v1.POST("/allow", r.checkAllActions, r.impersonationMW)
+}
+
+func (r *Router) v2Routes(v2 *echo.Group) {
+	v2.POST("/resources/:id/roles", r.roleV2Create)
+	v2.GET("/resources/:id/roles", r.roleV2sList)
+	v2.GET("/roles/:role_id", r.roleV2Get)
+	v2.PATCH("/roles/:role_id", r.roleV2Update)
+	v2.DELETE("/roles/:id", r.roleV2Delete)
+
+	v2.GET("/resources/:id/role-bindings", r.roleBindingsList)
+	v2.POST("/resources/:id/role-bindings", r.roleBindingCreate)
+	v2.POST("/resources/:id/role-bindings/bulk", r.roleBindingsBulk)
+	v2.GET("/resources/:id/role-bindings/stale", r.roleBindingsListStale)
+	v2.GET("/resources/:id/role-bindings/suggestions", r.roleBindingsSuggestions)
+	v2.GET("/resources/:id/subjects/:subject_id/permissions", r.subjectPermissions)
+	v2.POST("/allow/filter", r.filterResources, r.impersonationMW)
+	v2.GET("/role-bindings/:rb_id", r.roleBindingGet)
+	v2.DELETE("/role-bindings/:rb_id", r.roleBindingDelete)
+	v2.PATCH("/role-bindings/:rb_id", r.roleBindingUpdate)
+	v2.GET("/subjects/:id/role-bindings", r.roleBindingsListBySubject)
+
+	v2.POST("/resources/:id/invitations", r.invitationCreate)
+	v2.GET("/resources/:id/invitations", r.invitationsList)
+	v2.DELETE("/invitations/:invitation_id", r.invitationDelete)
+	v2.POST("/invitations/redeem", r.invitationRedeem)
+
+	v2.POST("/access-requests", r.accessRequestCreate, r.accessRequestsMW)
+	v2.GET("/resources/:id/access-requests", r.accessRequestsList, r.accessRequestsMW)
+	v2.GET("/access-requests/:request_id", r.accessRequestGet, r.accessRequestsMW)
+	v2.POST("/access-requests/:request_id/approve", r.accessRequestApprove, r.accessRequestsMW)
+	v2.POST("/access-requests/:request_id/deny", r.accessRequestDeny, r.accessRequestsMW)
+
+	v2.POST("/resources/:id/groups", r.groupCreate)
+	v2.GET("/resources/:id/groups", r.groupsList)
+	v2.GET("/groups/:group_id", r.groupGet)
+	v2.PATCH("/groups/:group_id", r.groupUpdate)
+	v2.DELETE("/groups/:group_id", r.groupDelete)
+	v2.GET("/groups/:group_id/members", r.groupMembersList)
+	v2.GET("/groups/:group_id/members/expanded", r.groupMembersExpand)
+	v2.POST("/groups/:group_id/members", r.groupMembersAdd)
+	v2.DELETE("/groups/:group_id/members", r.groupMembersRemove)
+
+	v2.GET("/resources/:id/settings", r.tenantSettingsGet)
+	v2.PATCH("/resources/:id/settings", r.tenantSettingsUpdate)
+
+	v2.POST("/resources/:id/aliases", r.resourceAliasCreate)
+	v2.GET("/resources/:id/aliases", r.resourceAliasesList)
+	v2.DELETE("/aliases/:alias", r.resourceAliasDelete)
+
+	v2.GET("/actions", r.listActions)
+	v2.GET("/actions/groups", r.listActionGroups)
+
+	v2.GET("/policy", r.policyGet)
+
+	v2.GET("/admin/relationships", r.relationshipsRead, r.adminMW)
+	v2.POST("/admin/relationships/bulk", r.relationshipsBulkWrite, r.adminMW)
+	v2.GET("/admin/relationships/changes", r.relationshipChangesStream, r.adminMW)
+}
+
+// versionHeaderMiddleware reports the API version serving the request.
+func versionHeaderMiddleware(version string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set(APIVersionHeader, version)
+
+			return next(c)
+		}
+	}
+}
+
+// StructuredError describes an error returned by v3 and later API versions.
+type StructuredError struct {
+	// Code is a stable, machine readable error code, e.g. invalid_action,
+	// derived from the HTTP status for errors without a specific code.
+	Code string `json:"code"`
+	// Status is the HTTP status code of the response.
+	Status int `json:"status"`
+	// Message is a human readable description of the error.
+	Message string `json:"message"`
+	// Details describes errors with a specific code, e.g. the invalid
+	// actions of an invalid_action error.
+	Details map[string]any `json:"details,omitempty"`
+	// RequestID is the ID of the request, to correlate the error with the
+	// server's logs and decisions.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// StructuredErrorResponse is the error response body of v3 and later API versions.
+type StructuredErrorResponse struct {
+	Error StructuredError `json:"error"`
+}
+
+// structuredErrorMiddleware renders errors as a StructuredErrorResponse.
+func (r *Router) structuredErrorMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	next = errorMiddleware(next)
+
+	return func(c echo.Context) error {
+		err := next(c)
+		if err == nil || c.Response().Committed {
+			return err
+		}
+
+		he, ok := err.(*echo.HTTPError)
+		if !ok {
+			he = echo.ErrInternalServerError.WithInternal(err)
+		}
+
+		message := http.StatusText(he.Code)
+		if msg, ok := he.Message.(string); ok && msg != "" {
+			message = msg
+		}
+
+		resp := StructuredErrorResponse{
+			Error: StructuredError{
+				Code:      errorCode(he.Code),
+				Status:    he.Code,
+				Message:   message,
+				RequestID: query.RequestIDFromContext(c.Request().Context()),
+			},
+		}
+
+		if he.Internal != nil {
+			if code, details := typedError(he.Internal); code != "" {
+				resp.Error.Code = code
+				resp.Error.Details = details
+			}
+		}
+
+		if he.Internal != nil {
+			// Log the internal error, the response is written here so echo's
+			// error handler is not called.
+			query.ContextLogger(c.Request().Context(), r.logger).Errorw("internal error", "error", he.Internal, "status", he.Code)
+		}
+
+		if c.Request().Method == http.MethodHead {
+			return c.NoContent(he.Code)
+		}
+
+		return c.JSON(he.Code, resp)
+	}
+}
+
+// typedError returns the specific error code and details of typed errors,
+// the code is empty for all other errors.
+func typedError(err error) (string, map[string]any) {
+	var (
+		invalidActions  *query.InvalidActionsError
+		invalidIDPrefix *query.InvalidIDPrefixError
+		invalidSubject  *query.InvalidRoleBindingSubjectError
+		limitExceeded   *LimitExceededError
+	)
+
+	switch {
+	case errors.As(err, &invalidActions):
+		return "invalid_action", map[string]any{
+			"resource_type": invalidActions.ResourceType,
+			"actions":       invalidActions.Actions,
+		}
+	case errors.As(err, &invalidIDPrefix):
+		return "invalid_id_prefix", map[string]any{
+			"id":             invalidIDPrefix.ID,
+			"resource_type":  invalidIDPrefix.ResourceType,
+			"expected_types": invalidIDPrefix.Expected,
+		}
+	case errors.As(err, &invalidSubject):
+		return "invalid_subject_type", map[string]any{
+			"subject_id":    invalidSubject.SubjectID,
+			"subject_type":  invalidSubject.SubjectType,
+			"allowed_types": invalidSubject.Allowed,
+		}
+	case errors.As(err, &limitExceeded):
+		return "limit_exceeded", map[string]any{
+			"limit":  limitExceeded.Limit,
+			"max":    limitExceeded.Max,
+			"actual": limitExceeded.Actual,
+		}
+	case errors.Is(err, storage.ErrRoleNameTaken):
+		return "role_exists", nil
+	default:
+		return "", nil
+	}
+}
+
+// errorCode converts an HTTP status to an error code, e.g. 404 becomes not_found.
+func errorCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/testingx"
+)
+
+func TestStructuredErrorMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	core, logs := observer.New(zap.ErrorLevel)
+	router := &Router{logger: zap.New(core).Sugar()}
+
+	e := echo.New()
+	e.Use(echoTestLogger(t, e))
+	e.Use(versionHeaderMiddleware("v3"), router.structuredErrorMiddleware)
+
+	e.GET("/test", func(c echo.Context) error {
+		switch c.QueryParam("error") {
+		case "echo":
+			return echo.NewHTTPError(http.StatusNotFound, "role not found")
+		case "conflict":
+			err := fmt.Errorf("%w: admins", storage.ErrRoleNameTaken)
+
+			return echo.NewHTTPError(http.StatusConflict, err.Error()).SetInternal(err)
+		case "other":
+			return io.ErrUnexpectedEOF
+		case "typed":
+			err := &query.InvalidActionsError{ResourceType: "rolev2", Actions: []string{"foo_get"}}
+
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		case "prefix":
+			err := &query.InvalidIDPrefixError{ID: "tnntten-abc", ResourceType: "tenant", Expected: []string{"rolev2"}}
+
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		case "subject":
+			err := &query.InvalidRoleBindingSubjectError{SubjectID: "tnntten-abc", SubjectType: "tenant", Allowed: []string{"user", "group"}}
+
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		case "limit":
+			err := &LimitExceededError{Limit: LimitRoleActions, Max: 500, Actual: 612}
+
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+
+		return c.JSON(http.StatusOK, map[string]string{"ok": "true"})
+	})
+
+	type result struct {
+		code    int
+		version string
+		body    StructuredErrorResponse
+	}
+
+	testCases := []testingx.TestCase[string, result]{
+		{
+			Name:  "NoError",
+			Input: "/test",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusOK, res.Success.code)
+				assert.Equal(t, "v3", res.Success.version)
+			},
+		},
+		{
+			Name:  "EchoError",
+			Input: "/test?error=echo",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusNotFound, res.Success.code)
+				assert.Equal(t, "v3", res.Success.version)
+				assert.Equal(t, StructuredError{Code: "not_found", Status: http.StatusNotFound, Message: "role not found"}, res.Success.body.Error)
+			},
+		},
+		{
+			Name:  "TypedError",
+			Input: "/test?error=typed",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.code)
+				assert.Equal(t, "invalid_action", res.Success.body.Error.Code)
+				assert.Equal(t, map[string]any{
+					"resource_type": "rolev2",
+					"actions":       []any{"foo_get"},
+				}, res.Success.body.Error.Details)
+			},
+		},
+		{
+			Name:  "InvalidIDPrefix",
+			Input: "/test?error=prefix",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.code)
+				assert.Equal(t, "invalid_id_prefix", res.Success.body.Error.Code)
+				assert.Equal(t, map[string]any{
+					"id":             "tnntten-abc",
+					"resource_type":  "tenant",
+					"expected_types": []any{"rolev2"},
+				}, res.Success.body.Error.Details)
+			},
+		},
+		{
+			Name:  "InvalidSubjectType",
+			Input: "/test?error=subject",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.code)
+				assert.Equal(t, "invalid_subject_type", res.Success.body.Error.Code)
+				assert.Equal(t, map[string]any{
+					"subject_id":    "tnntten-abc",
+					"subject_type":  "tenant",
+					"allowed_types": []any{"user", "group"},
+				}, res.Success.body.Error.Details)
+			},
+		},
+		{
+			Name:  "LimitExceeded",
+			Input: "/test?error=limit",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusBadRequest, res.Success.code)
+				assert.Equal(t, "limit_exceeded", res.Success.body.Error.Code)
+				assert.Equal(t, map[string]any{
+					"limit":  "role_actions",
+					"max":    float64(500),
+					"actual": float64(612),
+				}, res.Success.body.Error.Details)
+			},
+		},
+		{
+			Name:  "RoleExists",
+			Input: "/test?error=conflict",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusConflict, res.Success.code)
+				assert.Equal(t, "role_exists", res.Success.body.Error.Code)
+				assert.Empty(t, res.Success.body.Error.Details)
+			},
+		},
+		{
+			Name:  "OtherError",
+			Input: "/test?error=other",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, http.StatusInternalServerError, res.Success.code)
+				assert.Equal(t, "internal_server_error", res.Success.body.Error.Code)
+				assert.Equal(t, http.StatusInternalServerError, res.Success.body.Error.Status)
+
+				logged := logs.FilterMessage("internal error").FilterField(zap.Any("error", io.ErrUnexpectedEOF))
+				assert.Equal(t, 1, logged.Len())
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, path string) testingx.TestResult[result] {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return testingx.TestResult[result]{Err: err}
+		}
+
+		resp := httptest.NewRecorder()
+
+		e.ServeHTTP(resp, req)
+
+		out := result{
+			code:    resp.Code,
+			version: resp.Header().Get(APIVersionHeader),
+		}
+
+		if resp.Code != http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&out.body); err != nil {
+				return testingx.TestResult[result]{Err: err}
+			}
+		}
+
+		return testingx.TestResult[result]{Success: out}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
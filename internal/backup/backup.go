@@ -0,0 +1,439 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/authzed-go/v1"
+)
+
+const (
+	// Version is the version of the backup format written by Export.
+	Version = 1
+
+	// DefaultBatchSize is the default number of relationships written to SpiceDB at once by Import.
+	DefaultBatchSize = 500
+)
+
+// Header describes the contents of a backup.
+type Header struct {
+	Version int `json:"version"`
+	// Namespace is the SpiceDB namespace the relationships were exported from.
+	Namespace string `json:"namespace"`
+	// ResourceTypes are the resource types of the exported relationships.
+	ResourceTypes []string `json:"resource_types"`
+	// Revision is the SpiceDB revision all relationships were read at.
+	Revision  string    `json:"revision,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Relationship is an exported relationship, the types are not prefixed with
+// the namespace so relationships can be imported into another namespace.
+type Relationship struct {
+	ResourceType    string `json:"resource_type"`
+	ResourceID      string `json:"resource_id"`
+	Relation        string `json:"relation"`
+	SubjectType     string `json:"subject_type"`
+	SubjectID       string `json:"subject_id"`
+	SubjectRelation string `json:"subject_relation,omitempty"`
+}
+
+// Trailer closes a backup.
+type Trailer struct {
+	// Count is the number of relationships in the backup.
+	Count int64 `json:"count"`
+	// SHA256 is the hex encoded checksum of all lines before the trailer.
+	SHA256 string `json:"sha256"`
+}
+
+// record is a single line of a backup, exactly one field is set.
+type record struct {
+	Header       *Header       `json:"header,omitempty"`
+	Relationship *Relationship `json:"relationship,omitempty"`
+	Trailer      *Trailer      `json:"trailer,omitempty"`
+}
+
+// ExportOptions selects the relationships to export.
+type ExportOptions struct {
+	// Namespace is the SpiceDB namespace to export.
+	Namespace string
+	// ResourceTypes are the resource types to export the relationships of.
+	ResourceTypes []string
+}
+
+// writer writes records, hashing all lines but the trailer.
+type writer struct {
+	w     *bufio.Writer
+	hash  hash.Hash
+	count int64
+}
+
+func newWriter(w io.Writer) *writer {
+	return &writer{
+		w:    bufio.NewWriter(w),
+		hash: sha256.New(),
+	}
+}
+
+// close writes the trailer and flushes the backup.
+func (w *writer) close() (Trailer, error) {
+	trailer := Trailer{
+		Count:  w.count,
+		SHA256: hex.EncodeToString(w.hash.Sum(nil)),
+	}
+
+	if err := w.write(record{Trailer: &trailer}, false); err != nil {
+		return Trailer{}, err
+	}
+
+	return trailer, w.w.Flush()
+}
+
+func (w *writer) write(rec record, hashed bool) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	line = append(line, '\n')
+
+	if hashed {
+		w.hash.Write(line)
+	}
+
+	_, err = w.w.Write(line)
+
+	return err
+}
+
+// Export writes the relationships of the resource types in the namespace to
+// w. All relationships are read at the same SpiceDB revision, so the backup is
+// consistent, and streamed, so they are never all held in memory.
+func Export(ctx context.Context, client *authzed.Client, w io.Writer, opts ExportOptions) (Trailer, error) {
+	schema, err := client.ReadSchema(ctx, &pb.ReadSchemaRequest{})
+	if err != nil {
+		return Trailer{}, fmt.Errorf("reading revision: %w", err)
+	}
+
+	out := newWriter(w)
+
+	header := Header{
+		Version:       Version,
+		Namespace:     opts.Namespace,
+		ResourceTypes: opts.ResourceTypes,
+		Revision:      schema.GetReadAt().GetToken(),
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	if err := out.write(record{Header: &header}, true); err != nil {
+		return Trailer{}, err
+	}
+
+	consistency := &pb.Consistency{
+		Requirement: &pb.Consistency_FullyConsistent{FullyConsistent: true},
+	}
+
+	if schema.ReadAt != nil {
+		consistency = &pb.Consistency{
+			Requirement: &pb.Consistency_AtExactSnapshot{AtExactSnapshot: schema.ReadAt},
+		}
+	}
+
+	for _, resourceType := range opts.ResourceTypes {
+		if err := exportType(ctx, client, out, consistency, opts.Namespace, resourceType); err != nil {
+			return Trailer{}, fmt.Errorf("exporting %s relationships: %w", resourceType, err)
+		}
+	}
+
+	return out.close()
+}
+
+func exportType(ctx context.Context, client *authzed.Client, out *writer, consistency *pb.Consistency, namespace, resourceType string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.ReadRelationships(ctx, &pb.ReadRelationshipsRequest{
+		Consistency: consistency,
+		RelationshipFilter: &pb.RelationshipFilter{
+			ResourceType: namespace + "/" + resourceType,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	prefix := namespace + "/"
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		rel := resp.Relationship
+
+		if err := out.write(record{Relationship: &Relationship{
+			ResourceType:    strings.TrimPrefix(rel.Resource.ObjectType, prefix),
+			ResourceID:      rel.Resource.ObjectId,
+			Relation:        rel.Relation,
+			SubjectType:     strings.TrimPrefix(rel.Subject.Object.ObjectType, prefix),
+			SubjectID:       rel.Subject.Object.ObjectId,
+			SubjectRelation: rel.Subject.OptionalRelation,
+		}}, true); err != nil {
+			return err
+		}
+
+		out.count++
+	}
+}
+
+// Reader reads the relationships of a backup.
+type Reader struct {
+	r    *bufio.Reader
+	hash hash.Hash
+
+	header  Header
+	count   int64
+	trailer *Trailer
+}
+
+// NewReader reads the header of the backup.
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{
+		r:    bufio.NewReader(r),
+		hash: sha256.New(),
+	}
+
+	rec, err := reader.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if rec.Header == nil {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidBackup)
+	}
+
+	if rec.Header.Version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, rec.Header.Version)
+	}
+
+	reader.header = *rec.Header
+
+	return reader, nil
+}
+
+// Header returns the header of the backup.
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+func (r *Reader) read() (record, error) {
+	line, err := r.r.ReadBytes('\n')
+
+	switch {
+	case errors.Is(err, io.EOF) && len(line) == 0:
+		return record{}, fmt.Errorf("%w: missing trailer", ErrInvalidBackup)
+	case err != nil && !errors.Is(err, io.EOF):
+		return record{}, err
+	}
+
+	var rec record
+
+	if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+		return record{}, fmt.Errorf("%w: %s", ErrInvalidBackup, err.Error())
+	}
+
+	if rec.Trailer == nil {
+		r.hash.Write(line)
+	}
+
+	return rec, nil
+}
+
+// Next returns the next relationship, io.EOF once the trailer was read and
+// the backup was verified against it.
+func (r *Reader) Next() (Relationship, error) {
+	if r.trailer != nil {
+		return Relationship{}, io.EOF
+	}
+
+	rec, err := r.read()
+	if err != nil {
+		return Relationship{}, err
+	}
+
+	switch {
+	case rec.Relationship != nil:
+		r.count++
+
+		return *rec.Relationship, nil
+	case rec.Trailer != nil:
+		r.trailer = rec.Trailer
+
+		if err := r.verify(); err != nil {
+			return Relationship{}, err
+		}
+
+		return Relationship{}, io.EOF
+	default:
+		return Relationship{}, fmt.Errorf("%w: unexpected record", ErrInvalidBackup)
+	}
+}
+
+func (r *Reader) verify() error {
+	sum := hex.EncodeToString(r.hash.Sum(nil))
+
+	if r.trailer.Count != r.count || r.trailer.SHA256 != sum {
+		return fmt.Errorf("%w: expected %d relationships with checksum %s, read %d with checksum %s",
+			ErrChecksumMismatch, r.trailer.Count, r.trailer.SHA256, r.count, sum)
+	}
+
+	return nil
+}
+
+// Verify reads the whole backup and checks it against its trailer, returning the trailer.
+func Verify(r io.Reader) (Trailer, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return Trailer{}, err
+	}
+
+	for {
+		_, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return *reader.trailer, nil
+		}
+
+		if err != nil {
+			return Trailer{}, err
+		}
+	}
+}
+
+// ImportOptions configures an import.
+type ImportOptions struct {
+	// Namespace is the SpiceDB namespace to import into, it may differ from the exported namespace.
+	Namespace string
+	// ResourceTypes, if set, limits the import to relationships of these resource types.
+	ResourceTypes []string
+	// BatchSize is the number of relationships written to SpiceDB at once.
+	BatchSize int
+	// Skip is the number of relationships of the backup already imported by an
+	// earlier, interrupted import, they are not written again.
+	Skip int64
+	// Progress, if set, is called after each batch with the number of
+	// relationships of the backup imported so far, including skipped ones, so
+	// an interrupted import can be resumed.
+	Progress func(imported int64) error
+}
+
+// Import writes the relationships of the backup to SpiceDB. Relationships are
+// touched, so importing relationships which already exist is not an error and
+// an interrupted import may be repeated. The backup should be verified before
+// it is imported, as relationships are written as they are read. The number
+// of relationships of the backup processed is returned, on errors only those
+// written to SpiceDB are counted.
+func Import(ctx context.Context, client *authzed.Client, r io.Reader, opts ImportOptions) (int64, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	reader, err := NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		read    int64
+		updates []*pb.RelationshipUpdate
+	)
+
+	flush := func() error {
+		if len(updates) != 0 {
+			if _, err := client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{Updates: updates}); err != nil {
+				return fmt.Errorf("writing relationships: %w", err)
+			}
+
+			updates = updates[:0]
+		}
+
+		if opts.Progress != nil {
+			return opts.Progress(read)
+		}
+
+		return nil
+	}
+
+	for {
+		rel, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return read, err
+		}
+
+		read++
+
+		if read <= opts.Skip {
+			continue
+		}
+
+		if len(opts.ResourceTypes) != 0 && !slices.Contains(opts.ResourceTypes, rel.ResourceType) {
+			continue
+		}
+
+		updates = append(updates, &pb.RelationshipUpdate{
+			Operation:    pb.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: rel.toSpiceDB(opts.Namespace),
+		})
+
+		if len(updates) >= opts.BatchSize {
+			if err := flush(); err != nil {
+				return read - int64(len(updates)), err
+			}
+		}
+	}
+
+	pending := int64(len(updates))
+
+	if err := flush(); err != nil {
+		return read - pending, err
+	}
+
+	return read, nil
+}
+
+func (r Relationship) toSpiceDB(namespace string) *pb.Relationship {
+	return &pb.Relationship{
+		Resource: &pb.ObjectReference{
+			ObjectType: namespace + "/" + r.ResourceType,
+			ObjectId:   r.ResourceID,
+		},
+		Relation: r.Relation,
+		Subject: &pb.SubjectReference{
+			Object: &pb.ObjectReference{
+				ObjectType: namespace + "/" + r.SubjectType,
+				ObjectId:   r.SubjectID,
+			},
+			OptionalRelation: r.SubjectRelation,
+		},
+	}
+}
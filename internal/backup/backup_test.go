@@ -0,0 +1,157 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/spicedbx/testspicedb"
+)
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	rels := []Relationship{
+		{ResourceType: "tenant", ResourceID: "tnntten-a", Relation: "parent", SubjectType: "tenant", SubjectID: "tnntten-b"},
+		{ResourceType: "rolebinding", ResourceID: "permrbn-a", Relation: "subject", SubjectType: "group", SubjectID: "idntgrp-a", SubjectRelation: "member"},
+	}
+
+	writeTestBackup(t, &buf, rels)
+
+	reader, err := NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, "infratographer", reader.Header().Namespace)
+
+	var read []Relationship
+
+	for {
+		rel, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+
+		read = append(read, rel)
+	}
+
+	assert.Equal(t, rels, read)
+
+	trailer, err := Verify(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), trailer.Count)
+
+	// modified backups are detected
+	modified := bytes.Replace(buf.Bytes(), []byte("tnntten-b"), []byte("tnntten-c"), 1)
+
+	_, err = Verify(bytes.NewReader(modified))
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	// truncated backups are detected
+	truncated := buf.Bytes()[:bytes.LastIndex(buf.Bytes()[:buf.Len()-1], []byte("\n"))+1]
+
+	_, err = Verify(bytes.NewReader(truncated))
+	assert.ErrorIs(t, err, ErrInvalidBackup)
+}
+
+func writeTestBackup(t *testing.T, w io.Writer, rels []Relationship) {
+	t.Helper()
+
+	out := newWriter(w)
+
+	require.NoError(t, out.write(record{Header: &Header{Version: Version, Namespace: "infratographer"}}, true))
+
+	for i := range rels {
+		require.NoError(t, out.write(record{Relationship: &rels[i]}, true))
+
+		out.count++
+	}
+
+	_, err := out.close()
+	require.NoError(t, err)
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+
+	schema := iapl.DefaultPolicy().Schema()
+
+	client, source := testspicedb.NewTestSpiceDB(ctx, t, "backupsource", schema)
+	_, target := testspicedb.NewTestSpiceDB(ctx, t, "backuptarget", schema)
+
+	parentID := gidx.MustNewID("tnntten")
+
+	var updates []*pb.RelationshipUpdate
+
+	for i := 0; i < 5; i++ {
+		rel := Relationship{
+			ResourceType: "tenant",
+			ResourceID:   gidx.MustNewID("tnntten").String(),
+			Relation:     "parent",
+			SubjectType:  "tenant",
+			SubjectID:    parentID.String(),
+		}
+
+		updates = append(updates, &pb.RelationshipUpdate{
+			Operation:    pb.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: rel.toSpiceDB(source),
+		})
+	}
+
+	_, err := client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{Updates: updates})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	trailer, err := Export(ctx, client, &buf, ExportOptions{Namespace: source, ResourceTypes: []string{"tenant"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), trailer.Count)
+
+	var progress []int64
+
+	// the first two relationships were imported by an interrupted import
+	imported, err := Import(ctx, client, bytes.NewReader(buf.Bytes()), ImportOptions{
+		Namespace: target,
+		BatchSize: 2,
+		Skip:      2,
+		Progress: func(imported int64) error {
+			progress = append(progress, imported)
+
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), imported)
+	assert.Equal(t, []int64{4, 5}, progress)
+
+	stream, err := client.ReadRelationships(ctx, &pb.ReadRelationshipsRequest{
+		Consistency:        &pb.Consistency{Requirement: &pb.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: &pb.RelationshipFilter{ResourceType: target + "/tenant"},
+	})
+	require.NoError(t, err)
+
+	var count int
+
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+
+		count++
+	}
+
+	assert.Equal(t, 3, count)
+}
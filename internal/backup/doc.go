@@ -0,0 +1,9 @@
+// Package backup exports the relationships stored in SpiceDB to a file and
+// imports them again, e.g. for disaster recovery drills or to clone an
+// environment into another SpiceDB namespace.
+//
+// A backup is a JSON lines file starting with a header, followed by one line
+// per relationship and ending with a trailer holding the number of
+// relationships and the SHA-256 checksum of all preceding lines, so truncated
+// or modified backups are detected before any relationship is imported.
+package backup
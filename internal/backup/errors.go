@@ -0,0 +1,15 @@
+package backup
+
+import "errors"
+
+var (
+	// ErrInvalidBackup is returned when a backup file can not be read.
+	ErrInvalidBackup = errors.New("invalid backup")
+
+	// ErrChecksumMismatch is returned when the checksum or relationship count
+	// of a backup does not match its trailer.
+	ErrChecksumMismatch = errors.New("backup checksum mismatch")
+
+	// ErrUnsupportedVersion is returned when a backup was written by an unsupported format version.
+	ErrUnsupportedVersion = errors.New("unsupported backup version")
+)
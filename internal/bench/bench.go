@@ -0,0 +1,243 @@
+// Package bench drives a mix of workloads concurrently and reports their latencies.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Workload is a kind of request driven by a benchmark.
+type Workload struct {
+	Name string
+	// Weight is the share of requests of this workload, relative to the
+	// weights of the other workloads.
+	Weight int
+	// Fn performs a single request, rnd is owned by the calling worker.
+	Fn func(ctx context.Context, rnd *rand.Rand) error
+}
+
+// Options configures a benchmark.
+type Options struct {
+	// Concurrency is the number of requests in flight at once.
+	Concurrency int
+	// Duration is how long requests are sent for.
+	Duration time.Duration
+	// Requests, if set, stops the benchmark once this many requests were sent.
+	Requests int
+}
+
+// Result reports the requests of a workload.
+type Result struct {
+	Name     string
+	Requests int
+	Errors   int
+	// Throughput is the number of requests per second.
+	Throughput float64
+
+	Mean time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+}
+
+// recorder collects the latencies of the requests of a workload.
+type recorder struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    int
+}
+
+func (r *recorder) record(latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.latencies = append(r.latencies, latency)
+
+	if err != nil {
+		r.errors++
+	}
+}
+
+// Run sends requests of the workloads, picked at random by weight, until the
+// duration passed, the number of requests was sent or ctx is canceled, and
+// returns a result for every workload in the given order.
+func Run(ctx context.Context, opts Options, workloads []Workload) ([]Result, error) {
+	total := 0
+
+	for _, w := range workloads {
+		if w.Weight < 0 {
+			return nil, fmt.Errorf("%w: negative weight for %s", ErrInvalidWorkload, w.Name)
+		}
+
+		total += w.Weight
+	}
+
+	if total == 0 {
+		return nil, ErrNoWorkloads
+	}
+
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	recorders := make([]*recorder, len(workloads))
+
+	for i := range recorders {
+		recorders[i] = &recorder{}
+	}
+
+	// tickets limits the number of requests, when set.
+	var tickets chan struct{}
+
+	if opts.Requests > 0 {
+		tickets = make(chan struct{}, opts.Requests)
+
+		for i := 0; i < opts.Requests; i++ {
+			tickets <- struct{}{}
+		}
+
+		close(tickets)
+	}
+
+	var wg sync.WaitGroup
+
+	start := time.Now()
+
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+
+		go func(seed uint64) {
+			defer wg.Done()
+
+			rnd := rand.New(rand.NewPCG(seed, uint64(start.UnixNano())))
+
+			for ctx.Err() == nil {
+				if tickets != nil {
+					if _, ok := <-tickets; !ok {
+						return
+					}
+				}
+
+				idx := pick(rnd, workloads, total)
+
+				reqStart := time.Now()
+				err := workloads[idx].Fn(ctx, rnd)
+
+				// requests interrupted by the end of the benchmark are not counted
+				if ctx.Err() != nil {
+					return
+				}
+
+				recorders[idx].record(time.Since(reqStart), err)
+			}
+		}(uint64(i))
+	}
+
+	wg.Wait()
+
+	elapsed := time.Since(start)
+
+	results := make([]Result, len(workloads))
+
+	for i, w := range workloads {
+		results[i] = summarize(w.Name, recorders[i], elapsed)
+	}
+
+	return results, nil
+}
+
+// pick returns the index of a random workload, weighted by the workload weights.
+func pick(rnd *rand.Rand, workloads []Workload, total int) int {
+	n := rnd.IntN(total)
+
+	for i, w := range workloads {
+		if n < w.Weight {
+			return i
+		}
+
+		n -= w.Weight
+	}
+
+	return len(workloads) - 1
+}
+
+func summarize(name string, r *recorder, elapsed time.Duration) Result {
+	result := Result{
+		Name:     name,
+		Requests: len(r.latencies),
+		Errors:   r.errors,
+	}
+
+	if len(r.latencies) == 0 {
+		return result
+	}
+
+	latencies := slices.Clone(r.latencies)
+	slices.Sort(latencies)
+
+	var sum time.Duration
+
+	for _, l := range latencies {
+		sum += l
+	}
+
+	result.Throughput = float64(len(latencies)) / elapsed.Seconds()
+	result.Mean = sum / time.Duration(len(latencies))
+	result.P50 = percentile(latencies, 50)
+	result.P90 = percentile(latencies, 90)
+	result.P99 = percentile(latencies, 99)
+	result.Max = latencies[len(latencies)-1]
+
+	return result
+}
+
+// percentile returns the p-th percentile of the sorted latencies, using the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := (p*len(sorted) + 99) / 100
+
+	return sorted[max(rank, 1)-1]
+}
+
+// ParseMix parses workload weights in the form name=weight,name=weight, e.g. check=8,list-roles=2.
+func ParseMix(mix string) (map[string]int, error) {
+	weights := make(map[string]int)
+
+	for _, part := range strings.Split(mix, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, weightStr, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidWorkload, part)
+		}
+
+		weight, err := strconv.Atoi(weightStr)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("%w: invalid weight for %s: %s", ErrInvalidWorkload, name, weightStr)
+		}
+
+		weights[strings.TrimSpace(name)] = weight
+	}
+
+	return weights, nil
+}
@@ -0,0 +1,85 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	errFailed := errors.New("failed")
+
+	workloads := []Workload{
+		{
+			Name:   "ok",
+			Weight: 3,
+			Fn: func(context.Context, *rand.Rand) error {
+				return nil
+			},
+		},
+		{
+			Name:   "failing",
+			Weight: 1,
+			Fn: func(context.Context, *rand.Rand) error {
+				return errFailed
+			},
+		},
+		{
+			Name:   "disabled",
+			Weight: 0,
+			Fn: func(context.Context, *rand.Rand) error {
+				panic("disabled workloads must not run")
+			},
+		},
+	}
+
+	results, err := Run(context.Background(), Options{Concurrency: 4, Requests: 400}, workloads)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, 400, results[0].Requests+results[1].Requests)
+	assert.Greater(t, results[0].Requests, results[1].Requests)
+	assert.Zero(t, results[0].Errors)
+	assert.Equal(t, results[1].Requests, results[1].Errors)
+	assert.Zero(t, results[2].Requests)
+
+	_, err = Run(context.Background(), Options{Requests: 1}, workloads[2:])
+	assert.ErrorIs(t, err, ErrNoWorkloads)
+}
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+
+	latencies := make([]time.Duration, 100)
+
+	for i := range latencies {
+		latencies[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	assert.Equal(t, 50*time.Millisecond, percentile(latencies, 50))
+	assert.Equal(t, 99*time.Millisecond, percentile(latencies, 99))
+	assert.Equal(t, 100*time.Millisecond, percentile(latencies, 100))
+	assert.Equal(t, time.Millisecond, percentile(latencies[:1], 50))
+	assert.Zero(t, percentile(nil, 50))
+}
+
+func TestParseMix(t *testing.T) {
+	t.Parallel()
+
+	mix, err := ParseMix("check=8, list-roles=2,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"check": 8, "list-roles": 2}, mix)
+
+	_, err = ParseMix("check")
+	assert.ErrorIs(t, err, ErrInvalidWorkload)
+
+	_, err = ParseMix("check=-1")
+	assert.ErrorIs(t, err, ErrInvalidWorkload)
+}
@@ -0,0 +1,11 @@
+package bench
+
+import "errors"
+
+var (
+	// ErrNoWorkloads is returned when a benchmark has no workloads with a weight.
+	ErrNoWorkloads = errors.New("no workloads")
+
+	// ErrInvalidWorkload is returned when a workload or workload mix is invalid.
+	ErrInvalidWorkload = errors.New("invalid workload")
+)
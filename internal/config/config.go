@@ -2,6 +2,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"go.infratographer.com/x/crdbx"
@@ -12,25 +14,82 @@ import (
 	"go.infratographer.com/x/otelx"
 	"go.infratographer.com/x/viperx"
 
+	"go.infratographer.com/permissions-api/internal/api"
+	"go.infratographer.com/permissions-api/internal/encryption"
+	"go.infratographer.com/permissions-api/internal/graphapi"
+	"go.infratographer.com/permissions-api/internal/grpcapi"
+	"go.infratographer.com/permissions-api/internal/pubsub"
+	"go.infratographer.com/permissions-api/internal/query"
 	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/webhooks"
 )
 
 // EventsConfig stores the configuration for a load-balancer-api events config
 type EventsConfig struct {
-	events.Config  `mapstructure:",squash"`
-	Topics         []string
-	ZedTokenBucket string
+	events.Config            `mapstructure:",squash"`
+	Topics                   []string
+	ZedTokenBucket           string
+	Retry                    pubsub.RetryPolicy
+	DeadLetterTopic          string
+	Batch                    pubsub.BatchConfig
+	CleanupDeletions         bool
+	RelationshipChangesTopic string
+}
+
+// UsageConfig stores the configuration for tracking when roles and role-bindings were last used
+type UsageConfig struct {
+	Enabled       bool
+	FlushInterval time.Duration
+}
+
+// GroupsConfig stores the configuration for managing groups
+type GroupsConfig struct {
+	MaxDepth int
+}
+
+// StorageConfig stores the configuration for the permissions-api database
+type StorageConfig struct {
+	SlowQueryThreshold time.Duration
+}
+
+// ReloadConfig stores the configuration for reloading operational settings while running
+type ReloadConfig struct {
+	Interval time.Duration
+}
+
+// AuditConfig stores the configuration for publishing audit events of permission changes
+type AuditConfig struct {
+	Enabled bool
+	Topic   string
 }
 
 // AppConfig is the struct used for configuring the app
 type AppConfig struct {
-	CRDB    crdbx.Config
-	OIDC    echojwtx.AuthConfig
-	Logging loggingx.Config
-	Server  echox.Config
-	SpiceDB spicedbx.Config
-	Tracing otelx.Config
-	Events  EventsConfig
+	CRDB           crdbx.Config
+	Storage        StorageConfig
+	Reload         ReloadConfig
+	OIDC           echojwtx.AuthConfig
+	Logging        loggingx.Config
+	Server         echox.Config
+	SpiceDB        spicedbx.Config
+	Tracing        otelx.Config
+	Events         EventsConfig
+	GRPC           grpcapi.Config
+	GraphQL        graphapi.Config
+	Encryption     encryption.Config
+	RateLimit      api.RateLimitConfig
+	Impersonation  api.ImpersonationConfig
+	Admin          api.AdminConfig
+	AccessRequests api.AccessRequestConfig
+	Filter         api.FilterConfig
+	Limits         api.LimitsConfig
+	Usage          UsageConfig
+	Groups         GroupsConfig
+	Audit          AuditConfig
+	Degraded       query.DegradedConfig
+	CheckCache     query.CheckCacheConfig
+	DecisionLog    query.DecisionLogConfig
+	Webhooks       webhooks.Config
 }
 
 // MustViperFlags sets the cobra flags and viper config for events.
@@ -40,4 +99,28 @@ func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
 
 	flags.String("events-zedtokenbucket", "", "NATS KV bucket to use for caching ZedTokens")
 	viperx.MustBindFlag(v, "events.zedtokenbucket", flags.Lookup("events-zedtokenbucket"))
+
+	flags.Int("events-retry-max-attempts", pubsub.DefaultRetryPolicy.MaxAttempts, "attempts to write the relationships of an event before it is considered failed")
+	viperx.MustBindFlag(v, "events.retry.maxattempts", flags.Lookup("events-retry-max-attempts"))
+
+	flags.Duration("events-retry-initial-backoff", pubsub.DefaultRetryPolicy.InitialBackoff, "time to wait before retrying a failed event, doubled after every attempt")
+	viperx.MustBindFlag(v, "events.retry.initialbackoff", flags.Lookup("events-retry-initial-backoff"))
+
+	flags.Duration("events-retry-max-backoff", pubsub.DefaultRetryPolicy.MaxBackoff, "maximum time to wait between attempts of a failed event")
+	viperx.MustBindFlag(v, "events.retry.maxbackoff", flags.Lookup("events-retry-max-backoff"))
+
+	flags.String("events-dead-letter-topic", pubsub.DefaultDeadLetterTopic, "topic failed events are published to (disabled when empty)")
+	viperx.MustBindFlag(v, "events.deadlettertopic", flags.Lookup("events-dead-letter-topic"))
+
+	flags.Int("events-batch-size", 0, "maximum number of write events written to SpiceDB in a single call (batching is disabled when 1 or less)")
+	viperx.MustBindFlag(v, "events.batch.size", flags.Lookup("events-batch-size"))
+
+	flags.Duration("events-batch-window", pubsub.DefaultBatchWindow, "maximum time write events are accumulated for before being written")
+	viperx.MustBindFlag(v, "events.batch.window", flags.Lookup("events-batch-window"))
+
+	flags.Bool("events-cleanup-deletions", false, "remove the roles, role-bindings and relationships of resources when their delete change event is received")
+	viperx.MustBindFlag(v, "events.cleanupdeletions", flags.Lookup("events-cleanup-deletions"))
+
+	flags.String("events-relationship-changes-topic", "", "topic relationship changes made in SpiceDB are republished to (disabled when empty)")
+	viperx.MustBindFlag(v, "events.relationshipchangestopic", flags.Lookup("events-relationship-changes-topic"))
 }
@@ -0,0 +1,52 @@
+package encryption
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/viperx"
+)
+
+// ProviderLocal is the key provider using keys from the configuration.
+const ProviderLocal = "local"
+
+// Config is the configuration for encrypting sensitive values at rest.
+type Config struct {
+	// Provider is the key provider to use, encryption is disabled when empty.
+	Provider string
+	// PrimaryKey is the ID of the key used to wrap new data keys.
+	PrimaryKey string
+	// Keys are the local key encryption keys in the form id=base64key.
+	Keys []string
+}
+
+// MustViperFlags sets the cobra flags and viper config for encryption.
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.String("encryption-provider", "", "key provider used to encrypt sensitive values at rest (disabled when empty, supported: local)")
+	viperx.MustBindFlag(v, "encryption.provider", flags.Lookup("encryption-provider"))
+
+	flags.String("encryption-primary-key", "", "ID of the key encryption key used for new values")
+	viperx.MustBindFlag(v, "encryption.primarykey", flags.Lookup("encryption-primary-key"))
+
+	flags.StringSlice("encryption-keys", []string{}, "key encryption keys for the local provider, in the form id=base64key")
+	viperx.MustBindFlag(v, "encryption.keys", flags.Lookup("encryption-keys"))
+}
+
+// NewEncryptorFromConfig returns the Encryptor for the configured key provider,
+// or nil if encryption is disabled.
+func NewEncryptorFromConfig(cfg Config) (*Encryptor, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case ProviderLocal:
+		provider, err := NewLocalKeyProvider(cfg.PrimaryKey, cfg.Keys)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewEncryptor(provider), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, cfg.Provider)
+	}
+}
@@ -0,0 +1,9 @@
+// Package encryption provides application-level envelope encryption for
+// sensitive values stored in the permissions-api database.
+//
+// Each value is encrypted with a freshly generated data encryption key (DEK)
+// using AES-256-GCM. The DEK is then wrapped by a key encryption key (KEK)
+// managed by a KeyProvider, such as LocalKeyProvider, and stored alongside the
+// ciphertext. Only the wrapped DEK and the ID of the KEK used to wrap it are
+// persisted, allowing KEKs to be rotated without re-encrypting existing data.
+package encryption
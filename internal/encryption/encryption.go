@@ -0,0 +1,151 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+const (
+	// envelopePrefix identifies values encrypted by this package and the envelope format version.
+	envelopePrefix = "enc:v1:"
+
+	// envelopeParts is the number of fields in an envelope: key ID, wrapped data key and data.
+	envelopeParts = 3
+
+	dekSize = 32
+)
+
+// KeyProvider wraps and unwraps data encryption keys using key encryption keys it manages.
+type KeyProvider interface {
+	// WrapKey encrypts the data key with the current key encryption key,
+	// returning the ID of the key used.
+	WrapKey(ctx context.Context, dek []byte) (keyID string, wrapped []byte, err error)
+
+	// UnwrapKey decrypts a data key previously wrapped with the given key encryption key.
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Encryptor encrypts and decrypts values using envelope encryption.
+type Encryptor struct {
+	provider KeyProvider
+}
+
+// NewEncryptor creates a new Encryptor using the provided key provider.
+func NewEncryptor(provider KeyProvider) *Encryptor {
+	return &Encryptor{
+		provider: provider,
+	}
+}
+
+// Encrypt encrypts the plaintext, returning a string safe for storage in a text column.
+// The additional data is authenticated but not stored, the same value must be provided
+// on decryption, binding the ciphertext to, for example, the ID of the row it is stored in.
+func (e *Encryptor) Encrypt(ctx context.Context, plaintext, additionalData []byte) (string, error) {
+	dek := make([]byte, dekSize)
+
+	if _, err := rand.Read(dek); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	sealed, err := seal(dek, plaintext, additionalData)
+	if err != nil {
+		return "", err
+	}
+
+	keyID, wrapped, err := e.provider.WrapKey(ctx, dek)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	return envelopePrefix + strings.Join([]string{
+		keyID,
+		base64.RawStdEncoding.EncodeToString(wrapped),
+		base64.RawStdEncoding.EncodeToString(sealed),
+	}, ":"), nil
+}
+
+// Decrypt decrypts a value previously returned by Encrypt.
+func (e *Encryptor) Decrypt(ctx context.Context, ciphertext string, additionalData []byte) ([]byte, error) {
+	if !IsEncrypted(ciphertext) {
+		return nil, fmt.Errorf("%w: missing envelope prefix", ErrInvalidCiphertext)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(ciphertext, envelopePrefix), ":")
+	if len(parts) != envelopeParts {
+		return nil, fmt.Errorf("%w: malformed envelope", ErrInvalidCiphertext)
+	}
+
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed data key", ErrInvalidCiphertext)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed data", ErrInvalidCiphertext)
+	}
+
+	dek, err := e.provider.UnwrapKey(ctx, parts[0], wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	return open(dek, sealed, additionalData)
+}
+
+// IsEncrypted reports whether the value is an envelope produced by Encrypt.
+// This allows columns to be migrated to encrypted values incrementally.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, envelopePrefix)
+}
+
+// seal encrypts the plaintext with AES-GCM, returning the nonce followed by the ciphertext.
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts a value produced by seal.
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: data too short", ErrInvalidCiphertext)
+	}
+
+	nonce, data := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, data, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCiphertext, err.Error())
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err.Error())
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,107 @@
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), dekSize)))
+}
+
+func TestEncryptor(t *testing.T) {
+	ctx := context.Background()
+
+	oldProvider, err := NewLocalKeyProvider("k1", []string{"k1=" + testKey('a')})
+	require.NoError(t, err)
+
+	rotatedProvider, err := NewLocalKeyProvider("k2", []string{"k1=" + testKey('a'), "k2=" + testKey('b')})
+	require.NoError(t, err)
+
+	otherProvider, err := NewLocalKeyProvider("k1", []string{"k1=" + testKey('c')})
+	require.NoError(t, err)
+
+	ciphertext, err := NewEncryptor(oldProvider).Encrypt(ctx, []byte("secret justification"), []byte("permrbn-abc"))
+	require.NoError(t, err)
+
+	assert.True(t, IsEncrypted(ciphertext))
+	assert.NotContains(t, ciphertext, "secret")
+
+	type input struct {
+		encryptor      *Encryptor
+		ciphertext     string
+		additionalData string
+	}
+
+	testCases := []testingx.TestCase[input, []byte]{
+		{
+			Name:  "RoundTrip",
+			Input: input{NewEncryptor(oldProvider), ciphertext, "permrbn-abc"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]byte]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, "secret justification", string(res.Success))
+			},
+		},
+		{
+			Name:  "RotatedPrimaryKey",
+			Input: input{NewEncryptor(rotatedProvider), ciphertext, "permrbn-abc"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]byte]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, "secret justification", string(res.Success))
+			},
+		},
+		{
+			Name:  "WrongAdditionalData",
+			Input: input{NewEncryptor(oldProvider), ciphertext, "permrbn-other"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]byte]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidCiphertext)
+			},
+		},
+		{
+			Name:  "WrongKey",
+			Input: input{NewEncryptor(otherProvider), ciphertext, "permrbn-abc"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]byte]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidCiphertext)
+			},
+		},
+		{
+			Name:  "Plaintext",
+			Input: input{NewEncryptor(oldProvider), "secret justification", "permrbn-abc"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]byte]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidCiphertext)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in input) testingx.TestResult[[]byte] {
+		plaintext, err := in.encryptor.Decrypt(ctx, in.ciphertext, []byte(in.additionalData))
+
+		return testingx.TestResult[[]byte]{Success: plaintext, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+
+	ciphertext, err = NewEncryptor(rotatedProvider).Encrypt(ctx, []byte("new"), nil)
+	require.NoError(t, err)
+
+	_, err = NewEncryptor(oldProvider).Decrypt(ctx, ciphertext, nil)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestNewLocalKeyProvider(t *testing.T) {
+	_, err := NewLocalKeyProvider("k1", []string{"k1=" + base64.StdEncoding.EncodeToString([]byte("short"))})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = NewLocalKeyProvider("k1", []string{"k:1=" + testKey('a')})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = NewLocalKeyProvider("k2", []string{"k1=" + testKey('a')})
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
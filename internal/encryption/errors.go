@@ -0,0 +1,17 @@
+package encryption
+
+import "errors"
+
+var (
+	// ErrInvalidCiphertext is returned when a value is not a valid envelope or fails authentication.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+	// ErrUnknownKey is returned when a value was wrapped with a key the provider does not hold.
+	ErrUnknownKey = errors.New("unknown key")
+
+	// ErrInvalidKey is returned when a configured key is malformed.
+	ErrInvalidKey = errors.New("invalid key")
+
+	// ErrUnknownProvider is returned when the configured key provider is not supported.
+	ErrUnknownProvider = errors.New("unknown key provider")
+)
@@ -0,0 +1,68 @@
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// LocalKeyProvider wraps data keys with AES-256 key encryption keys held in memory.
+// Older keys may be kept to unwrap existing values after the primary key is rotated.
+type LocalKeyProvider struct {
+	primary string
+	keys    map[string][]byte
+}
+
+// NewLocalKeyProvider creates a key provider from keys in the form "id=base64key".
+// The primary key is used to wrap new data keys, all keys are used for unwrapping.
+func NewLocalKeyProvider(primary string, keys []string) (*LocalKeyProvider, error) {
+	p := &LocalKeyProvider{
+		primary: primary,
+		keys:    make(map[string][]byte, len(keys)),
+	}
+
+	for _, key := range keys {
+		id, encoded, ok := strings.Cut(key, "=")
+		if !ok || id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("%w: keys must be in the form id=base64key and ids must not contain ':'", ErrInvalidKey)
+		}
+
+		material, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %s: %s", ErrInvalidKey, id, err.Error())
+		}
+
+		if len(material) != dekSize {
+			return nil, fmt.Errorf("%w: key %s must be %d bytes", ErrInvalidKey, id, dekSize)
+		}
+
+		p.keys[id] = material
+	}
+
+	if _, ok := p.keys[primary]; !ok {
+		return nil, fmt.Errorf("%w: primary key %q", ErrUnknownKey, primary)
+	}
+
+	return p, nil
+}
+
+// WrapKey encrypts the data key with the primary key.
+func (p *LocalKeyProvider) WrapKey(_ context.Context, dek []byte) (string, []byte, error) {
+	wrapped, err := seal(p.keys[p.primary], dek, []byte(p.primary))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return p.primary, wrapped, nil
+}
+
+// UnwrapKey decrypts a data key wrapped with the given key.
+func (p *LocalKeyProvider) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	kek, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+
+	return open(kek, wrapped, []byte(keyID))
+}
@@ -0,0 +1,44 @@
+package graphapi
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/viperx"
+)
+
+const (
+	// DefaultMaxDepth is the default maximum nesting of fields in a query.
+	DefaultMaxDepth = 10
+	// DefaultMaxFields is the default maximum number of fields selected by a
+	// query, with its fragments expanded.
+	DefaultMaxFields = 500
+	// DefaultMaxBodyBytes is the default maximum size of a request body.
+	DefaultMaxBodyBytes = 1 << 20
+)
+
+// Config is the configuration for the GraphQL endpoint
+type Config struct {
+	// Enabled serves the GraphQL endpoint at /query when true.
+	Enabled bool
+	// MaxDepth is the maximum nesting of fields in a query.
+	MaxDepth int
+	// MaxFields is the maximum number of fields selected by a query.
+	MaxFields int
+	// MaxBodyBytes is the maximum size of a request body.
+	MaxBodyBytes int64
+}
+
+// MustViperFlags sets the cobra flags and viper config for the GraphQL endpoint.
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Bool("graphql-enabled", false, "serve the GraphQL query endpoint at /query")
+	viperx.MustBindFlag(v, "graphql.enabled", flags.Lookup("graphql-enabled"))
+
+	flags.Int("graphql-max-depth", DefaultMaxDepth, "maximum nesting of fields in a GraphQL query")
+	viperx.MustBindFlag(v, "graphql.maxdepth", flags.Lookup("graphql-max-depth"))
+
+	flags.Int("graphql-max-fields", DefaultMaxFields, "maximum number of fields selected by a GraphQL query, with fragments expanded")
+	viperx.MustBindFlag(v, "graphql.maxfields", flags.Lookup("graphql-max-fields"))
+
+	flags.Int64("graphql-max-body-bytes", DefaultMaxBodyBytes, "maximum size of a GraphQL request body")
+	viperx.MustBindFlag(v, "graphql.maxbodybytes", flags.Lookup("graphql-max-body-bytes"))
+}
@@ -0,0 +1,16 @@
+package graphapi
+
+import "errors"
+
+var (
+	// ErrInvalidQuery is returned when the GraphQL document cannot be parsed or executed
+	ErrInvalidQuery = errors.New("invalid query")
+	// ErrInvalidArgument is returned when a field argument is invalid
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrUnknownField is returned when a requested field does not exist
+	ErrUnknownField = errors.New("unknown field")
+	// ErrAccessDenied is returned when the subject is not allowed to access a field
+	ErrAccessDenied = errors.New("access denied")
+	// ErrQueryTooComplex is returned when a query is nested too deeply or selects too many fields
+	ErrQueryTooComplex = errors.New("query too complex")
+)
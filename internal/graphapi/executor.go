@@ -0,0 +1,256 @@
+package graphapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// object is implemented by all GraphQL object types.
+type object interface {
+	typeName() string
+	resolveField(ctx context.Context, name string, args map[string]any) (any, error)
+}
+
+// gqlError is a GraphQL response error.
+type gqlError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// orderedMap preserves the order of selected fields in the response.
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: map[string]any{}}
+}
+
+func (m *orderedMap) set(key string, v any) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+
+	m.values[key] = v
+}
+
+// MarshalJSON implements json.Marshaler
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, key := range m.keys {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// limits bound the work a single request may cause.
+type limits struct {
+	maxDepth     int
+	maxFields    int
+	maxBodyBytes int64
+}
+
+type executor struct {
+	doc       *document
+	variables map[string]any
+	errors    []gqlError
+}
+
+// execute runs the named operation of the document against the root object,
+// operations over the limits are rejected before any field is resolved.
+func execute(ctx context.Context, doc *document, operationName string, variables map[string]any, root object, limits limits) (any, []gqlError) {
+	var op *operation
+
+	for _, o := range doc.operations {
+		if operationName == "" || o.name == operationName {
+			op = o
+
+			break
+		}
+	}
+
+	switch {
+	case op == nil:
+		return nil, []gqlError{{Message: fmt.Sprintf("operation %q not found", operationName)}}
+	case operationName == "" && len(doc.operations) > 1:
+		return nil, []gqlError{{Message: "operationName is required when multiple operations are defined"}}
+	case op.kind != "query":
+		return nil, []gqlError{{Message: fmt.Sprintf("%s operations are not supported", op.kind)}}
+	}
+
+	ex := &executor{doc: doc, variables: variables}
+
+	fields := 0
+
+	if err := ex.checkComplexity(op.selectionSet, 1, &fields, map[string]bool{}, limits); err != nil {
+		return nil, []gqlError{{Message: err.Error()}}
+	}
+
+	data := ex.selectionSet(ctx, root, op.selectionSet, nil)
+
+	return data, ex.errors
+}
+
+func (ex *executor) addError(path []any, err error) {
+	ex.errors = append(ex.errors, gqlError{
+		Message: err.Error(),
+		Path:    append([]any{}, path...),
+	})
+}
+
+// checkComplexity returns an error when the selection set, with its fragments
+// expanded, nests fields deeper than the max depth or selects more than the
+// max fields. fields counts the fields selected so far, fragments being
+// expanded are marked in visited so cycles are not followed.
+func (ex *executor) checkComplexity(set []selection, depth int, fields *int, visited map[string]bool, limits limits) error {
+	if depth > limits.maxDepth {
+		return fmt.Errorf("%w: fields are nested deeper than %d", ErrQueryTooComplex, limits.maxDepth)
+	}
+
+	for _, sel := range set {
+		switch {
+		case sel.field != nil:
+			*fields++
+
+			if *fields > limits.maxFields {
+				return fmt.Errorf("%w: more than %d fields are selected", ErrQueryTooComplex, limits.maxFields)
+			}
+
+			if sel.field.selectionSet != nil {
+				if err := ex.checkComplexity(sel.field.selectionSet, depth+1, fields, visited, limits); err != nil {
+					return err
+				}
+			}
+		case sel.inlineFragment != nil:
+			if err := ex.checkComplexity(sel.inlineFragment.selectionSet, depth, fields, visited, limits); err != nil {
+				return err
+			}
+		case sel.fragmentSpread != "":
+			frag, ok := ex.doc.fragments[sel.fragmentSpread]
+			if !ok || visited[frag.name] {
+				continue
+			}
+
+			visited[frag.name] = true
+
+			if err := ex.checkComplexity(frag.selectionSet, depth, fields, visited, limits); err != nil {
+				return err
+			}
+
+			delete(visited, frag.name)
+		}
+	}
+
+	return nil
+}
+
+// collectFields flattens the fragments of a selection set into the fields
+// applicable to the given type.
+func (ex *executor) collectFields(typeName string, set []selection, visited map[string]bool) []*field {
+	var fields []*field
+
+	for _, sel := range set {
+		switch {
+		case sel.field != nil:
+			fields = append(fields, sel.field)
+		case sel.inlineFragment != nil:
+			if sel.inlineFragment.typeCondition == "" || sel.inlineFragment.typeCondition == typeName {
+				fields = append(fields, ex.collectFields(typeName, sel.inlineFragment.selectionSet, visited)...)
+			}
+		case sel.fragmentSpread != "":
+			frag, ok := ex.doc.fragments[sel.fragmentSpread]
+			if !ok || visited[frag.name] || frag.typeCondition != typeName {
+				continue
+			}
+
+			visited[frag.name] = true
+
+			fields = append(fields, ex.collectFields(typeName, frag.selectionSet, visited)...)
+		}
+	}
+
+	return fields
+}
+
+func (ex *executor) selectionSet(ctx context.Context, obj object, set []selection, path []any) *orderedMap {
+	out := newOrderedMap()
+
+	for _, f := range ex.collectFields(obj.typeName(), set, map[string]bool{}) {
+		key := f.responseKey()
+		fieldPath := append(append([]any{}, path...), key)
+
+		if f.name == "__typename" {
+			out.set(key, obj.typeName())
+
+			continue
+		}
+
+		args := make(map[string]any, len(f.arguments))
+		for name, v := range f.arguments {
+			args[name] = v.resolve(ex.variables)
+		}
+
+		result, err := obj.resolveField(ctx, f.name, args)
+		if err != nil {
+			ex.addError(fieldPath, err)
+			out.set(key, nil)
+
+			continue
+		}
+
+		out.set(key, ex.complete(ctx, f, result, fieldPath))
+	}
+
+	return out
+}
+
+// complete resolves the selection set of object results, scalars are returned as is.
+func (ex *executor) complete(ctx context.Context, f *field, result any, path []any) any {
+	switch v := result.(type) {
+	case nil:
+		return nil
+	case object:
+		if f.selectionSet == nil {
+			ex.addError(path, fmt.Errorf("%w: field %q of type %s must have a selection of subfields", ErrInvalidQuery, f.name, v.typeName()))
+
+			return nil
+		}
+
+		return ex.selectionSet(ctx, v, f.selectionSet, path)
+	case []object:
+		out := make([]any, len(v))
+
+		for i, item := range v {
+			out[i] = ex.complete(ctx, f, item, append(append([]any{}, path...), i))
+		}
+
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,149 @@
+package graphapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+)
+
+type testObject struct {
+	name     string
+	children []object
+}
+
+func (testObject) typeName() string { return "Test" }
+
+func (o testObject) resolveField(_ context.Context, name string, args map[string]any) (any, error) {
+	switch name {
+	case "name":
+		return o.name, nil
+	case "echo":
+		return args["value"], nil
+	case "children":
+		return o.children, nil
+	case "fail":
+		return nil, fmt.Errorf("%w: failed", ErrAccessDenied)
+	default:
+		return nil, fmt.Errorf("%w: %q on type Test", ErrUnknownField, name)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	root := testObject{
+		name: "root",
+		children: []object{
+			testObject{name: "a"},
+			testObject{name: "b"},
+		},
+	}
+
+	type input struct {
+		query     string
+		operation string
+		variables map[string]any
+	}
+
+	type result struct {
+		data   string
+		errors []gqlError
+	}
+
+	testCases := []testingx.TestCase[input, result]{
+		{
+			Name:  "InvalidQuery",
+			Input: input{query: "{ name"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidQuery)
+			},
+		},
+		{
+			Name:  "AliasesAndNesting",
+			Input: input{query: `query { n: name children { name __typename } }`},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+				assert.Empty(t, res.Success.errors)
+				assert.JSONEq(t, `{"n":"root","children":[{"name":"a","__typename":"Test"},{"name":"b","__typename":"Test"}]}`, res.Success.data)
+			},
+		},
+		{
+			Name: "VariablesAndFragments",
+			Input: input{
+				query:     `query Q($v: String!) { echo(value: $v) ...F } fragment F on Test { name }`,
+				operation: "Q",
+				variables: map[string]any{"v": "hello"},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+				assert.JSONEq(t, `{"echo":"hello","name":"root"}`, res.Success.data)
+			},
+		},
+		{
+			Name:  "FieldErrors",
+			Input: input{query: `{ name fail }`},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+				assert.JSONEq(t, `{"name":"root","fail":null}`, res.Success.data)
+				require.Len(t, res.Success.errors, 1)
+				assert.Equal(t, []any{"fail"}, res.Success.errors[0].Path)
+			},
+		},
+		{
+			Name:  "MutationUnsupported",
+			Input: input{query: `mutation { name }`},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success.errors, 1)
+				assert.Contains(t, res.Success.errors[0].Message, "not supported")
+			},
+		},
+		{
+			Name:  "TooDeep",
+			Input: input{query: `{ children { children { children { name } } } }`},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, "null", res.Success.data)
+				require.Len(t, res.Success.errors, 1)
+				assert.Contains(t, res.Success.errors[0].Message, ErrQueryTooComplex.Error())
+			},
+		},
+		{
+			Name: "TooManyFields",
+			// every spread of a fragment counts its fields again
+			Input: input{query: `{ ...A ...A } fragment A on Test { ...B ...B } fragment B on Test { name n2: name n3: name }`},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success.errors, 1)
+				assert.Contains(t, res.Success.errors[0].Message, "more than 10 fields")
+			},
+		},
+		{
+			Name:  "NestedTooDeeply",
+			Input: input{query: "{ echo(value: " + strings.Repeat("[", maxNesting) + strings.Repeat("]", maxNesting) + ") }"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[result]) {
+				assert.ErrorIs(t, res.Err, ErrQueryTooComplex)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in input) testingx.TestResult[result] {
+		doc, err := parseDocument(in.query)
+		if err != nil {
+			return testingx.TestResult[result]{Err: err}
+		}
+
+		data, errs := execute(ctx, doc, in.operation, in.variables, root, limits{maxDepth: 3, maxFields: 10})
+
+		out, err := json.Marshal(data)
+
+		return testingx.TestResult[result]{Success: result{data: string(out), errors: errs}, Err: err}
+	}
+
+	testingx.RunTests(context.Background(), t, testCases, testFn)
+}
@@ -0,0 +1,135 @@
+// Package graphapi provides a read-only GraphQL endpoint for roles, role-bindings
+// and permission checks, allowing clients to fetch related objects, such as a
+// role with its owner and bindings, in a single request.
+//
+// The schema served is defined in schema.graphql at the root of the repository.
+package graphapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/permissions-api/internal/query"
+)
+
+var tracer = otel.Tracer("go.infratographer.com/permissions-api/internal/graphapi")
+
+// Engine is the part of the query engine the GraphQL API resolves fields with.
+type Engine interface {
+	query.Checker
+	query.RoleManager
+	query.BindingManager
+
+	AllActions() []string
+}
+
+// Handler serves GraphQL requests.
+type Handler struct {
+	engine Engine
+	logger *zap.SugaredLogger
+	limits limits
+}
+
+// NewHandler returns a new GraphQL handler, limiting queries as set in the
+// config. Unset limits use their defaults.
+func NewHandler(engine Engine, logger *zap.SugaredLogger, config Config) *Handler {
+	h := &Handler{
+		engine: engine,
+		logger: logger.Named("graphapi"),
+		limits: limits{
+			maxDepth:     DefaultMaxDepth,
+			maxFields:    DefaultMaxFields,
+			maxBodyBytes: DefaultMaxBodyBytes,
+		},
+	}
+
+	if config.MaxDepth > 0 {
+		h.limits.maxDepth = config.MaxDepth
+	}
+
+	if config.MaxFields > 0 {
+		h.limits.maxFields = config.MaxFields
+	}
+
+	if config.MaxBodyBytes > 0 {
+		h.limits.maxBodyBytes = config.MaxBodyBytes
+	}
+
+	return h
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type response struct {
+	Data   any        `json:"data,omitempty"`
+	Errors []gqlError `json:"errors,omitempty"`
+}
+
+// Handle serves a GraphQL request, queries may be sent as a JSON POST body or
+// using the query, operationName and variables query parameters. Requests must
+// be authenticated, all fields are authorized for the token subject.
+func (h *Handler) Handle(c echo.Context) error {
+	var req request
+
+	if c.Request().Method == http.MethodGet {
+		req.Query = c.QueryParam("query")
+		req.OperationName = c.QueryParam("operationName")
+
+		if vars := c.QueryParam("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				return c.JSON(http.StatusBadRequest, response{Errors: []gqlError{{Message: "invalid variables: " + err.Error()}}})
+			}
+		}
+	} else if err := json.NewDecoder(http.MaxBytesReader(c.Response(), c.Request().Body, h.limits.maxBodyBytes)).Decode(&req); err != nil {
+		if maxErr := new(http.MaxBytesError); errors.As(err, &maxErr) {
+			return c.JSON(http.StatusRequestEntityTooLarge, response{Errors: []gqlError{{
+				Message: fmt.Sprintf("request body larger than %d bytes", maxErr.Limit),
+			}}})
+		}
+
+		return c.JSON(http.StatusBadRequest, response{Errors: []gqlError{{Message: "invalid request body: " + err.Error()}}})
+	}
+
+	ctx, span := tracer.Start(c.Request().Context(), "graphapi.Handle", trace.WithAttributes(
+		attribute.String("operation", req.OperationName),
+	))
+	defer span.End()
+
+	subjectID, err := gidx.Parse(echojwtx.Actor(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "failed to get the subject").SetInternal(err)
+	}
+
+	subject, err := h.engine.NewResourceFromID(subjectID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "error processing subject ID").SetInternal(err)
+	}
+
+	doc, err := parseDocument(req.Query)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, response{Errors: []gqlError{{Message: err.Error()}}})
+	}
+
+	root := queryObject{resolver: &resolver{engine: h.engine, subject: subject}}
+
+	data, errs := execute(ctx, doc, req.OperationName, req.Variables, root, h.limits)
+	if len(errs) != 0 {
+		h.logger.Debugw("graphql request completed with errors", "operation", req.OperationName, "errors", errs)
+	}
+
+	return c.JSON(http.StatusOK, response{Data: data, Errors: errs})
+}
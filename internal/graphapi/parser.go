@@ -0,0 +1,637 @@
+package graphapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// document is a parsed GraphQL request document. Only the query subset of the
+// language used by the permissions-api schema is supported: operations,
+// fields, aliases, arguments, variables and fragments.
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	kind         string
+	name         string
+	selectionSet []selection
+}
+
+type fragment struct {
+	name          string
+	typeCondition string
+	selectionSet  []selection
+}
+
+// selection is either a field, a fragment spread or an inline fragment.
+type selection struct {
+	field          *field
+	fragmentSpread string
+	inlineFragment *fragment
+}
+
+type field struct {
+	alias        string
+	name         string
+	arguments    map[string]value
+	selectionSet []selection
+}
+
+func (f *field) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+
+	return f.name
+}
+
+// value is an unresolved argument value, variables are resolved at execution.
+type value struct {
+	variable string
+	literal  any
+	list     []value
+	object   map[string]value
+}
+
+func (v value) resolve(variables map[string]any) any {
+	switch {
+	case v.variable != "":
+		return variables[v.variable]
+	case v.list != nil:
+		out := make([]any, len(v.list))
+		for i, item := range v.list {
+			out[i] = item.resolve(variables)
+		}
+
+		return out
+	case v.object != nil:
+		out := make(map[string]any, len(v.object))
+		for k, item := range v.object {
+			out[k] = item.resolve(variables)
+		}
+
+		return out
+	default:
+		return v.literal
+	}
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+
+	i := 0
+
+	for i < len(src) {
+		c := rune(src[i])
+
+		switch {
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case unicode.IsSpace(c) || c == ',' || c == '\ufeff':
+			i++
+		case strings.ContainsRune("!$():=@[]{}|", c):
+			tokens = append(tokens, token{tokenPunct, string(c), i})
+			i++
+		case c == '.':
+			if !strings.HasPrefix(src[i:], "...") {
+				return nil, fmt.Errorf("%w: unexpected '.' at %d", ErrInvalidQuery, i)
+			}
+
+			tokens = append(tokens, token{tokenPunct, "...", i})
+			i += 3
+		case c == '"':
+			start := i
+			i++
+
+			for i < len(src) && src[i] != '"' {
+				if src[i] == '\\' {
+					i++
+				}
+
+				i++
+			}
+
+			if i >= len(src) {
+				return nil, fmt.Errorf("%w: unterminated string at %d", ErrInvalidQuery, start)
+			}
+
+			str, err := strconv.Unquote(src[start : i+1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid string at %d", ErrInvalidQuery, start)
+			}
+
+			tokens = append(tokens, token{tokenString, str, start})
+			i++
+		case c == '-' || unicode.IsDigit(c):
+			start := i
+			kind := tokenInt
+			i++
+
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || strings.ContainsRune(".eE+-", rune(src[i]))) {
+				if !unicode.IsDigit(rune(src[i])) {
+					kind = tokenFloat
+				}
+
+				i++
+			}
+
+			tokens = append(tokens, token{kind, src[start:i], start})
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+
+			tokens = append(tokens, token{tokenName, src[start:i], start})
+		default:
+			return nil, fmt.Errorf("%w: unexpected character %q at %d", ErrInvalidQuery, c, i)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+// maxNesting is the maximum nesting of selection sets and values the parser
+// recurses into, deeper documents are rejected before they are executed.
+const maxNesting = 64
+
+type parser struct {
+	tokens []token
+	pos    int
+	// nesting is the number of selection sets and values being parsed.
+	nesting int
+}
+
+func parseDocument(src string) (*document, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	doc := &document{fragments: map[string]*fragment{}}
+
+	for p.peek().kind != tokenEOF {
+		switch {
+		case p.peekPunct("{"):
+			set, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+
+			doc.operations = append(doc.operations, &operation{kind: "query", selectionSet: set})
+		case p.peekName("fragment"):
+			frag, err := p.fragmentDefinition()
+			if err != nil {
+				return nil, err
+			}
+
+			doc.fragments[frag.name] = frag
+		case p.peekName("query"), p.peekName("mutation"), p.peekName("subscription"):
+			op, err := p.operationDefinition()
+			if err != nil {
+				return nil, err
+			}
+
+			doc.operations = append(doc.operations, op)
+		default:
+			return nil, p.unexpected()
+		}
+	}
+
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("%w: no operations defined", ErrInvalidQuery)
+	}
+
+	return doc, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) peekPunct(v string) bool {
+	t := p.peek()
+
+	return t.kind == tokenPunct && t.value == v
+}
+
+func (p *parser) peekName(v string) bool {
+	t := p.peek()
+
+	return t.kind == tokenName && t.value == v
+}
+
+func (p *parser) unexpected() error {
+	t := p.peek()
+
+	if t.kind == tokenEOF {
+		return fmt.Errorf("%w: unexpected end of document", ErrInvalidQuery)
+	}
+
+	return fmt.Errorf("%w: unexpected %q at %d", ErrInvalidQuery, t.value, t.pos)
+}
+
+func (p *parser) expectPunct(v string) error {
+	if !p.peekPunct(v) {
+		return p.unexpected()
+	}
+
+	p.next()
+
+	return nil
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.peek().kind != tokenName {
+		return "", p.unexpected()
+	}
+
+	return p.next().value, nil
+}
+
+func (p *parser) operationDefinition() (*operation, error) {
+	op := &operation{kind: p.next().value}
+
+	if p.peek().kind == tokenName {
+		op.name = p.next().value
+	}
+
+	// variable definitions are only used for validation by full
+	// implementations, their types are skipped here.
+	if p.peekPunct("(") {
+		if err := p.skipBalanced("(", ")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.skipDirectives(); err != nil {
+		return nil, err
+	}
+
+	set, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+
+	op.selectionSet = set
+
+	return op, nil
+}
+
+func (p *parser) fragmentDefinition() (*fragment, error) {
+	p.next()
+
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.peekName("on") {
+		return nil, p.unexpected()
+	}
+
+	p.next()
+
+	typeCondition, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.skipDirectives(); err != nil {
+		return nil, err
+	}
+
+	set, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+
+	return &fragment{name: name, typeCondition: typeCondition, selectionSet: set}, nil
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.nest(); err != nil {
+		return nil, err
+	}
+
+	defer p.unnest()
+
+	if err := p.expectPunct("{"); err != nil {
+		return nil, err
+	}
+
+	var set []selection
+
+	for !p.peekPunct("}") {
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+
+		set = append(set, sel)
+	}
+
+	p.next()
+
+	return set, nil
+}
+
+func (p *parser) selection() (selection, error) {
+	if !p.peekPunct("...") {
+		f, err := p.field()
+		if err != nil {
+			return selection{}, err
+		}
+
+		return selection{field: f}, nil
+	}
+
+	p.next()
+
+	if p.peek().kind == tokenName && !p.peekName("on") {
+		name := p.next().value
+
+		if err := p.skipDirectives(); err != nil {
+			return selection{}, err
+		}
+
+		return selection{fragmentSpread: name}, nil
+	}
+
+	frag := &fragment{}
+
+	if p.peekName("on") {
+		p.next()
+
+		typeCondition, err := p.expectName()
+		if err != nil {
+			return selection{}, err
+		}
+
+		frag.typeCondition = typeCondition
+	}
+
+	if err := p.skipDirectives(); err != nil {
+		return selection{}, err
+	}
+
+	set, err := p.selectionSet()
+	if err != nil {
+		return selection{}, err
+	}
+
+	frag.selectionSet = set
+
+	return selection{inlineFragment: frag}, nil
+}
+
+func (p *parser) field() (*field, error) {
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+
+	f := &field{name: name}
+
+	if p.peekPunct(":") {
+		p.next()
+
+		f.alias = name
+
+		if f.name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peekPunct("(") {
+		if f.arguments, err = p.arguments(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.skipDirectives(); err != nil {
+		return nil, err
+	}
+
+	if p.peekPunct("{") {
+		if f.selectionSet, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+func (p *parser) arguments() (map[string]value, error) {
+	p.next()
+
+	args := map[string]value{}
+
+	for !p.peekPunct(")") {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expectPunct(":"); err != nil {
+			return nil, err
+		}
+
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		args[name] = v
+	}
+
+	p.next()
+
+	return args, nil
+}
+
+func (p *parser) value() (value, error) {
+	if err := p.nest(); err != nil {
+		return value{}, err
+	}
+
+	defer p.unnest()
+
+	t := p.next()
+
+	switch t.kind {
+	case tokenString:
+		return value{literal: t.value}, nil
+	case tokenInt:
+		i, err := strconv.ParseInt(t.value, 10, 64)
+		if err != nil {
+			return value{}, fmt.Errorf("%w: invalid int %q", ErrInvalidQuery, t.value)
+		}
+
+		return value{literal: i}, nil
+	case tokenFloat:
+		f, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return value{}, fmt.Errorf("%w: invalid float %q", ErrInvalidQuery, t.value)
+		}
+
+		return value{literal: f}, nil
+	case tokenName:
+		switch t.value {
+		case "true":
+			return value{literal: true}, nil
+		case "false":
+			return value{literal: false}, nil
+		case "null":
+			return value{}, nil
+		default:
+			// enum values are passed to resolvers as strings
+			return value{literal: t.value}, nil
+		}
+	case tokenPunct:
+		switch t.value {
+		case "$":
+			name, err := p.expectName()
+			if err != nil {
+				return value{}, err
+			}
+
+			return value{variable: name}, nil
+		case "[":
+			list := []value{}
+
+			for !p.peekPunct("]") {
+				item, err := p.value()
+				if err != nil {
+					return value{}, err
+				}
+
+				list = append(list, item)
+			}
+
+			p.next()
+
+			return value{list: list}, nil
+		case "{":
+			obj := map[string]value{}
+
+			for !p.peekPunct("}") {
+				name, err := p.expectName()
+				if err != nil {
+					return value{}, err
+				}
+
+				if err := p.expectPunct(":"); err != nil {
+					return value{}, err
+				}
+
+				item, err := p.value()
+				if err != nil {
+					return value{}, err
+				}
+
+				obj[name] = item
+			}
+
+			p.next()
+
+			return value{object: obj}, nil
+		}
+	}
+
+	if t.kind != tokenEOF {
+		p.pos--
+	}
+
+	return value{}, p.unexpected()
+}
+
+// nest enters a selection set or value, failing when nested too deeply.
+func (p *parser) nest() error {
+	p.nesting++
+
+	if p.nesting > maxNesting {
+		return fmt.Errorf("%w: document is nested deeper than %d", ErrQueryTooComplex, maxNesting)
+	}
+
+	return nil
+}
+
+func (p *parser) unnest() {
+	p.nesting--
+}
+
+// skipDirectives skips any directives, they are not supported by this implementation.
+func (p *parser) skipDirectives() error {
+	for p.peekPunct("@") {
+		p.next()
+
+		if _, err := p.expectName(); err != nil {
+			return err
+		}
+
+		if p.peekPunct("(") {
+			if err := p.skipBalanced("(", ")"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *parser) skipBalanced(open, closing string) error {
+	depth := 0
+
+	for {
+		t := p.next()
+
+		switch {
+		case t.kind == tokenEOF:
+			return p.unexpected()
+		case t.kind == tokenPunct && t.value == open:
+			depth++
+		case t.kind == tokenPunct && t.value == closing:
+			depth--
+
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package graphapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// resolver holds the state shared by all objects of a single request.
+type resolver struct {
+	engine  Engine
+	subject types.Resource
+}
+
+func (r *resolver) resource(v any) (types.Resource, error) {
+	idStr, ok := v.(string)
+	if !ok || idStr == "" {
+		return types.Resource{}, fmt.Errorf("%w: expected an ID", ErrInvalidArgument)
+	}
+
+	id, err := gidx.Parse(idStr)
+	if err != nil {
+		return types.Resource{}, fmt.Errorf("%w: %s", ErrInvalidArgument, err.Error())
+	}
+
+	return r.engine.NewResourceFromID(id)
+}
+
+// authorize checks whether the request subject may perform the action on the resource.
+func (r *resolver) authorize(ctx context.Context, action string, resource types.Resource) error {
+	err := r.engine.SubjectHasPermission(ctx, r.subject, action, resource)
+	if errors.Is(err, query.ErrActionNotAssigned) {
+		return fmt.Errorf("%w: subject '%s' does not have permission to perform action '%s' on resource '%s'",
+			ErrAccessDenied, r.subject.ID, action, resource.ID)
+	}
+
+	return err
+}
+
+type queryObject struct {
+	*resolver
+}
+
+func (queryObject) typeName() string { return "Query" }
+
+func (q queryObject) resolveField(ctx context.Context, name string, args map[string]any) (any, error) {
+	switch name {
+	case "role":
+		role, err := q.resource(args["id"])
+		if err != nil {
+			return nil, err
+		}
+
+		return q.getRole(ctx, role)
+	case "roles":
+		resource, err := q.resource(args["resourceID"])
+		if err != nil {
+			return nil, err
+		}
+
+		if err := q.authorize(ctx, string(iapl.RoleActionList), resource); err != nil {
+			return nil, err
+		}
+
+		roles, err := q.engine.ListRolesV2(ctx, resource)
+		if err != nil {
+			return nil, err
+		}
+
+		out := make([]object, len(roles))
+		for i, role := range roles {
+			out[i] = &roleObject{resolver: q.resolver, role: role}
+		}
+
+		return out, nil
+	case "roleBinding":
+		rb, err := q.resource(args["id"])
+		if err != nil {
+			return nil, err
+		}
+
+		resource, err := q.engine.GetRoleBindingResource(ctx, rb)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := q.authorize(ctx, string(iapl.RoleBindingActionGet), resource); err != nil {
+			return nil, err
+		}
+
+		binding, err := q.engine.GetRoleBinding(ctx, rb)
+		if err != nil {
+			return nil, err
+		}
+
+		return &roleBindingObject{resolver: q.resolver, rb: binding}, nil
+	case "roleBindings":
+		resource, err := q.resource(args["resourceID"])
+		if err != nil {
+			return nil, err
+		}
+
+		var role *types.Resource
+
+		if args["roleID"] != nil {
+			roleRes, err := q.resource(args["roleID"])
+			if err != nil {
+				return nil, err
+			}
+
+			role = &roleRes
+		}
+
+		return q.roleBindings(ctx, resource, role)
+	case "check":
+		resource, err := q.resource(args["resourceID"])
+		if err != nil {
+			return nil, err
+		}
+
+		action, _ := args["action"].(string)
+		if action == "" {
+			return nil, fmt.Errorf("%w: action is required", ErrInvalidArgument)
+		}
+
+		err = q.authorize(ctx, action, resource)
+
+		switch {
+		case errors.Is(err, ErrAccessDenied):
+			return false, nil
+		case err != nil:
+			return nil, err
+		default:
+			return true, nil
+		}
+	case "actions":
+		return q.engine.AllActions(), nil
+	default:
+		return nil, fmt.Errorf("%w: %q on type Query", ErrUnknownField, name)
+	}
+}
+
+func (r *resolver) getRole(ctx context.Context, roleResource types.Resource) (any, error) {
+	if err := r.authorize(ctx, string(iapl.RoleActionGet), roleResource); err != nil {
+		return nil, err
+	}
+
+	role, err := r.engine.GetRoleV2(ctx, roleResource)
+	if err != nil {
+		return nil, err
+	}
+
+	return &roleObject{resolver: r, role: role, loaded: true}, nil
+}
+
+func (r *resolver) roleBindings(ctx context.Context, resource types.Resource, role *types.Resource) ([]object, error) {
+	if err := r.authorize(ctx, string(iapl.RoleBindingActionList), resource); err != nil {
+		return nil, err
+	}
+
+	rbs, err := r.engine.ListRoleBindings(ctx, resource, role)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]object, len(rbs))
+	for i, rb := range rbs {
+		out[i] = &roleBindingObject{resolver: r, rb: rb}
+	}
+
+	return out, nil
+}
+
+type roleObject struct {
+	*resolver
+	role   types.Role
+	loaded bool
+}
+
+func (*roleObject) typeName() string { return "Role" }
+
+// load fetches the full role when the object was created from a listing.
+func (o *roleObject) load(ctx context.Context) error {
+	if o.loaded {
+		return nil
+	}
+
+	roleResource, err := o.engine.NewResourceFromID(o.role.ID)
+	if err != nil {
+		return err
+	}
+
+	role, err := o.engine.GetRoleV2(ctx, roleResource)
+	if err != nil {
+		return err
+	}
+
+	o.role = role
+	o.loaded = true
+
+	return nil
+}
+
+func (o *roleObject) resolveField(ctx context.Context, name string, args map[string]any) (any, error) {
+	switch name {
+	case "id":
+		return o.role.ID.String(), nil
+	case "name":
+		return o.role.Name, nil
+	}
+
+	if err := o.load(ctx); err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "actions":
+		return o.role.Actions, nil
+	case "owner":
+		return o.resourceObject(o.role.ResourceID)
+	case "bindings":
+		resource, err := o.engine.NewResourceFromID(o.role.ResourceID)
+		if err != nil {
+			return nil, err
+		}
+
+		if args["resourceID"] != nil {
+			if resource, err = o.resource(args["resourceID"]); err != nil {
+				return nil, err
+			}
+		}
+
+		roleResource, err := o.engine.NewResourceFromID(o.role.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return o.roleBindings(ctx, resource, &roleResource)
+	case "createdBy":
+		return o.role.CreatedBy.String(), nil
+	case "updatedBy":
+		return o.role.UpdatedBy.String(), nil
+	case "createdAt":
+		return o.role.CreatedAt.Format(time.RFC3339), nil
+	case "updatedAt":
+		return o.role.UpdatedAt.Format(time.RFC3339), nil
+	case "lastUsedAt":
+		return formatTime(o.role.LastUsedAt), nil
+	default:
+		return nil, fmt.Errorf("%w: %q on type Role", ErrUnknownField, name)
+	}
+}
+
+type roleBindingObject struct {
+	*resolver
+	rb types.RoleBinding
+}
+
+func (*roleBindingObject) typeName() string { return "RoleBinding" }
+
+func (o *roleBindingObject) resolveField(ctx context.Context, name string, _ map[string]any) (any, error) {
+	switch name {
+	case "id":
+		return o.rb.ID.String(), nil
+	case "role":
+		role, err := o.engine.NewResourceFromID(o.rb.RoleID)
+		if err != nil {
+			return nil, err
+		}
+
+		return o.getRole(ctx, role)
+	case "resource":
+		return o.resourceObject(o.rb.ResourceID)
+	case "subjects":
+		out := make([]object, len(o.rb.SubjectIDs))
+
+		for i, id := range o.rb.SubjectIDs {
+			subj, err := o.resourceObject(id)
+			if err != nil {
+				return nil, err
+			}
+
+			out[i] = subj
+		}
+
+		return out, nil
+	case "createdBy":
+		return o.rb.CreatedBy.String(), nil
+	case "updatedBy":
+		return o.rb.UpdatedBy.String(), nil
+	case "createdAt":
+		return o.rb.CreatedAt.Format(time.RFC3339), nil
+	case "updatedAt":
+		return o.rb.UpdatedAt.Format(time.RFC3339), nil
+	case "lastUsedAt":
+		return formatTime(o.rb.LastUsedAt), nil
+	default:
+		return nil, fmt.Errorf("%w: %q on type RoleBinding", ErrUnknownField, name)
+	}
+}
+
+func (r *resolver) resourceObject(id gidx.PrefixedID) (object, error) {
+	resource, err := r.engine.NewResourceFromID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resourceObject{resource: resource}, nil
+}
+
+type resourceObject struct {
+	resource types.Resource
+}
+
+func (*resourceObject) typeName() string { return "Resource" }
+
+func (o *resourceObject) resolveField(_ context.Context, name string, _ map[string]any) (any, error) {
+	switch name {
+	case "id":
+		return o.resource.ID.String(), nil
+	case "type":
+		return o.resource.Type, nil
+	default:
+		return nil, fmt.Errorf("%w: %q on type Resource", ErrUnknownField, name)
+	}
+}
+
+// formatTime formats an optional time, nil times resolve to null.
+func formatTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+
+	return t.Format(time.RFC3339)
+}
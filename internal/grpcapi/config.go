@@ -0,0 +1,19 @@
+package grpcapi
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/viperx"
+)
+
+// Config is the configuration for the gRPC server
+type Config struct {
+	// Listen is the address the gRPC server listens on, the server is disabled when empty.
+	Listen string
+}
+
+// MustViperFlags sets the cobra flags and viper config for the gRPC server.
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.String("grpc-listen", "", "address to listen on for gRPC requests (disabled when empty)")
+	viperx.MustBindFlag(v, "grpc.listen", flags.Lookup("grpc-listen"))
+}
@@ -0,0 +1,8 @@
+// Package grpcapi provides a gRPC surface for the permissions-api engine,
+// exposing permission checks, roles and role-bindings to internal services
+// without the JSON/HTTP overhead of the REST API.
+//
+// The service contract is defined by the protobuf definitions in
+// pkg/proto/permissions/v1, clients can use the generated Go client in that
+// package or generate their own from the .proto file.
+package grpcapi
@@ -0,0 +1,54 @@
+package grpcapi
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/permissions-api/internal/api"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/storage"
+)
+
+// errorStatus converts an error into a grpc status error, mirroring the
+// status codes returned by the REST API.
+func errorStatus(basemsg string, err error) error {
+	msg := fmt.Sprintf("%s: %s", basemsg, err.Error())
+	code := codes.Internal
+
+	switch {
+	case
+		errors.Is(err, query.ErrInvalidType),
+		errors.Is(err, query.ErrInvalidArgument),
+		errors.Is(err, query.ErrInvalidAction),
+		errors.Is(err, query.ErrInvalidNamespace),
+		errors.Is(err, api.ErrLimitExceeded),
+		status.Code(err) == codes.InvalidArgument,
+		status.Code(err) == codes.FailedPrecondition:
+		code = codes.InvalidArgument
+	case
+		errors.Is(err, query.ErrActionNotAssigned),
+		errors.Is(err, query.ErrRoleDelegationExceeded):
+		code = codes.PermissionDenied
+	case
+		errors.Is(err, storage.ErrNoRoleFound),
+		errors.Is(err, query.ErrRoleNotFound),
+		errors.Is(err, query.ErrRoleBindingNotFound),
+		errors.Is(err, query.ErrResourceAliasNotFound):
+		code = codes.NotFound
+	case
+		errors.Is(err, storage.ErrRoleAlreadyExists),
+		errors.Is(err, storage.ErrRoleNameTaken),
+		errors.Is(err, storage.ErrResourceAliasExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, spicedbx.ErrorCircuitOpen):
+		code = codes.Unavailable
+	default:
+		msg = basemsg
+	}
+
+	return status.Error(code, msg)
+}
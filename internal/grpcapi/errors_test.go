@@ -0,0 +1,76 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/testingx"
+)
+
+func TestErrorStatus(t *testing.T) {
+	ctx := context.Background()
+
+	type expected struct {
+		code codes.Code
+		msg  string
+	}
+
+	testCases := []testingx.TestCase[error, expected]{
+		{
+			Name:  "InvalidArgument",
+			Input: fmt.Errorf("%w: bad name", query.ErrInvalidArgument),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[expected]) {
+				assert.Equal(t, codes.InvalidArgument, res.Success.code)
+				assert.Contains(t, res.Success.msg, "bad name")
+			},
+		},
+		{
+			Name:  "NotFound",
+			Input: query.ErrRoleNotFound,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[expected]) {
+				assert.Equal(t, codes.NotFound, res.Success.code)
+			},
+		},
+		{
+			Name:  "DelegationExceeded",
+			Input: fmt.Errorf("%w: loadbalancer_delete", query.ErrRoleDelegationExceeded),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[expected]) {
+				assert.Equal(t, codes.PermissionDenied, res.Success.code)
+				assert.Contains(t, res.Success.msg, "loadbalancer_delete")
+			},
+		},
+		{
+			Name:  "AlreadyExists",
+			Input: storage.ErrRoleAlreadyExists,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[expected]) {
+				assert.Equal(t, codes.AlreadyExists, res.Success.code)
+			},
+		},
+		{
+			Name:  "Internal",
+			Input: io.ErrUnexpectedEOF,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[expected]) {
+				assert.Equal(t, codes.Internal, res.Success.code)
+				assert.Equal(t, "base", res.Success.msg)
+			},
+		},
+	}
+
+	testFn := func(_ context.Context, err error) testingx.TestResult[expected] {
+		st := status.Convert(errorStatus("base", err))
+
+		return testingx.TestResult[expected]{
+			Success: expected{code: st.Code(), msg: st.Message()},
+		}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
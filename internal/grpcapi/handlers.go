@@ -0,0 +1,478 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/types"
+	permissionsv1 "go.infratographer.com/permissions-api/pkg/proto/permissions/v1"
+)
+
+// Check checks whether the authenticated subject may perform an action on a resource.
+func (s *Server) Check(ctx context.Context, req *permissionsv1.CheckRequest) (*permissionsv1.CheckResponse, error) {
+	ctx, span := tracer.Start(ctx, "grpcapi.Check", trace.WithAttributes(attribute.String("id", req.GetResourceId())))
+	defer span.End()
+
+	if req.GetAction() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing action")
+	}
+
+	subject, err := s.currentSubject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.resolveResource(ctx, req.GetResourceId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAction(ctx, subject, req.GetAction(), resource); err != nil {
+		return nil, err
+	}
+
+	return &permissionsv1.CheckResponse{Allowed: true}, nil
+}
+
+// CreateRole creates a v2 role owned by the given resource.
+func (s *Server) CreateRole(ctx context.Context, req *permissionsv1.CreateRoleRequest) (*permissionsv1.Role, error) {
+	ctx, span := tracer.Start(ctx, "grpcapi.CreateRole", trace.WithAttributes(attribute.String("id", req.GetResourceId())))
+	defer span.End()
+
+	if err := s.limits.CheckRole(req.GetName(), req.GetActions()); err != nil {
+		return nil, errorStatus("error creating role", err)
+	}
+
+	subject, err := s.currentSubject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.resourceFromID(req.GetResourceId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAction(ctx, subject, string(iapl.RoleActionCreate), resource); err != nil {
+		return nil, err
+	}
+
+	role, err := s.roles.CreateRoleV2(ctx, subject, resource, strings.TrimSpace(req.GetName()), req.GetActions())
+	if err != nil {
+		return nil, errorStatus("error creating role", err)
+	}
+
+	return roleMessage(role), nil
+}
+
+// GetRole fetches a v2 role.
+func (s *Server) GetRole(ctx context.Context, req *permissionsv1.GetRoleRequest) (*permissionsv1.Role, error) {
+	ctx, span := tracer.Start(ctx, "grpcapi.GetRole", trace.WithAttributes(attribute.String("id", req.GetId())))
+	defer span.End()
+
+	subject, err := s.currentSubject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roleResource, err := s.resourceFromID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAction(ctx, subject, string(iapl.RoleActionGet), roleResource); err != nil {
+		return nil, err
+	}
+
+	role, err := s.roles.GetRoleV2(ctx, roleResource)
+	if err != nil {
+		return nil, errorStatus("error getting role", err)
+	}
+
+	return roleMessage(role), nil
+}
+
+// UpdateRole updates the name and actions of a v2 role.
+func (s *Server) UpdateRole(ctx context.Context, req *permissionsv1.UpdateRoleRequest) (*permissionsv1.Role, error) {
+	ctx, span := tracer.Start(ctx, "grpcapi.UpdateRole", trace.WithAttributes(attribute.String("id", req.GetId())))
+	defer span.End()
+
+	if err := s.limits.CheckRole(req.GetName(), req.GetActions()); err != nil {
+		return nil, errorStatus("error updating role", err)
+	}
+
+	subject, err := s.currentSubject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roleResource, err := s.resourceFromID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAction(ctx, subject, string(iapl.RoleActionUpdate), roleResource); err != nil {
+		return nil, err
+	}
+
+	role, err := s.roles.UpdateRoleV2(ctx, subject, roleResource, strings.TrimSpace(req.GetName()), req.GetActions())
+	if err != nil {
+		return nil, errorStatus("error updating role", err)
+	}
+
+	return roleMessage(role), nil
+}
+
+// DeleteRole deletes a v2 role.
+func (s *Server) DeleteRole(ctx context.Context, req *permissionsv1.DeleteRoleRequest) (*permissionsv1.DeleteResponse, error) {
+	ctx, span := tracer.Start(ctx, "grpcapi.DeleteRole", trace.WithAttributes(attribute.String("id", req.GetId())))
+	defer span.End()
+
+	subject, err := s.currentSubject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roleResource, err := s.resourceFromID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAction(ctx, subject, string(iapl.RoleActionDelete), roleResource); err != nil {
+		return nil, err
+	}
+
+	if err := s.roles.DeleteRoleV2(ctx, roleResource); err != nil {
+		return nil, errorStatus("error deleting role", err)
+	}
+
+	return &permissionsv1.DeleteResponse{Success: true}, nil
+}
+
+// ListRoles streams the v2 roles owned by a resource.
+func (s *Server) ListRoles(req *permissionsv1.ListRolesRequest, stream permissionsv1.PermissionsService_ListRolesServer) error {
+	ctx, span := tracer.Start(stream.Context(), "grpcapi.ListRoles", trace.WithAttributes(attribute.String("id", req.GetResourceId())))
+	defer span.End()
+
+	subject, err := s.currentSubject(ctx)
+	if err != nil {
+		return err
+	}
+
+	resource, err := s.resourceFromID(req.GetResourceId())
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkAction(ctx, subject, string(iapl.RoleActionList), resource); err != nil {
+		return err
+	}
+
+	roles, err := s.roles.ListRolesV2(ctx, resource)
+	if err != nil {
+		return errorStatus("error listing roles", err)
+	}
+
+	for _, role := range roles {
+		if err := stream.Send(roleMessage(role)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateRoleBinding binds a role to subjects on a resource.
+func (s *Server) CreateRoleBinding(ctx context.Context, req *permissionsv1.CreateRoleBindingRequest) (*permissionsv1.RoleBinding, error) {
+	ctx, span := tracer.Start(ctx, "grpcapi.CreateRoleBinding", trace.WithAttributes(attribute.String("id", req.GetResourceId())))
+	defer span.End()
+
+	if err := s.limits.CheckRoleBindingSubjects(len(req.GetSubjectIds())); err != nil {
+		return nil, errorStatus("error creating role-binding", err)
+	}
+
+	subject, err := s.currentSubject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := s.resourceFromID(req.GetResourceId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAction(ctx, subject, string(iapl.RoleBindingActionCreate), resource); err != nil {
+		return nil, err
+	}
+
+	roleResource, err := s.resourceFromID(req.GetRoleId())
+	if err != nil {
+		return nil, err
+	}
+
+	subjects, err := s.roleBindingSubjects(ctx, req.GetSubjectIds())
+	if err != nil {
+		return nil, err
+	}
+
+	rb, err := s.bindings.CreateRoleBinding(ctx, subject, resource, roleResource, subjects)
+	if err != nil {
+		return nil, errorStatus("error creating role-binding", err)
+	}
+
+	return roleBindingMessage(rb), nil
+}
+
+// GetRoleBinding fetches a role-binding.
+func (s *Server) GetRoleBinding(ctx context.Context, req *permissionsv1.GetRoleBindingRequest) (*permissionsv1.RoleBinding, error) {
+	ctx, span := tracer.Start(ctx, "grpcapi.GetRoleBinding", trace.WithAttributes(attribute.String("id", req.GetId())))
+	defer span.End()
+
+	rbResource, err := s.authorizeRoleBinding(ctx, req.GetId(), iapl.RoleBindingActionGet)
+	if err != nil {
+		return nil, err
+	}
+
+	rb, err := s.bindings.GetRoleBinding(ctx, rbResource)
+	if err != nil {
+		return nil, errorStatus("error getting role-binding", err)
+	}
+
+	return roleBindingMessage(rb), nil
+}
+
+// UpdateRoleBinding replaces the subjects of a role-binding.
+func (s *Server) UpdateRoleBinding(ctx context.Context, req *permissionsv1.UpdateRoleBindingRequest) (*permissionsv1.RoleBinding, error) {
+	ctx, span := tracer.Start(ctx, "grpcapi.UpdateRoleBinding", trace.WithAttributes(attribute.String("id", req.GetId())))
+	defer span.End()
+
+	if err := s.limits.CheckRoleBindingSubjects(len(req.GetSubjectIds())); err != nil {
+		return nil, errorStatus("error updating role-binding", err)
+	}
+
+	rbResource, err := s.authorizeRoleBinding(ctx, req.GetId(), iapl.RoleBindingActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects, err := s.roleBindingSubjects(ctx, req.GetSubjectIds())
+	if err != nil {
+		return nil, err
+	}
+
+	actor, err := s.currentSubject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rb, err := s.bindings.UpdateRoleBinding(ctx, actor, rbResource, subjects)
+	if err != nil {
+		return nil, errorStatus("error updating role-binding", err)
+	}
+
+	return roleBindingMessage(rb), nil
+}
+
+// DeleteRoleBinding deletes a role-binding.
+func (s *Server) DeleteRoleBinding(ctx context.Context, req *permissionsv1.DeleteRoleBindingRequest) (*permissionsv1.DeleteResponse, error) {
+	ctx, span := tracer.Start(ctx, "grpcapi.DeleteRoleBinding", trace.WithAttributes(attribute.String("id", req.GetId())))
+	defer span.End()
+
+	rbResource, err := s.authorizeRoleBinding(ctx, req.GetId(), iapl.RoleBindingActionDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.bindings.DeleteRoleBinding(ctx, rbResource); err != nil {
+		return nil, errorStatus("error deleting role-binding", err)
+	}
+
+	return &permissionsv1.DeleteResponse{Success: true}, nil
+}
+
+// ListRoleBindings streams the role-bindings of a resource, optionally filtered by role.
+func (s *Server) ListRoleBindings(req *permissionsv1.ListRoleBindingsRequest, stream permissionsv1.PermissionsService_ListRoleBindingsServer) error {
+	ctx, span := tracer.Start(stream.Context(), "grpcapi.ListRoleBindings", trace.WithAttributes(attribute.String("id", req.GetResourceId())))
+	defer span.End()
+
+	subject, err := s.currentSubject(ctx)
+	if err != nil {
+		return err
+	}
+
+	resource, err := s.resourceFromID(req.GetResourceId())
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkAction(ctx, subject, string(iapl.RoleBindingActionList), resource); err != nil {
+		return err
+	}
+
+	var optionalRole *types.Resource
+
+	if req.GetRoleId() != "" {
+		roleResource, err := s.resourceFromID(req.GetRoleId())
+		if err != nil {
+			return err
+		}
+
+		optionalRole = &roleResource
+	}
+
+	rbs, err := s.bindings.ListRoleBindings(ctx, resource, optionalRole)
+	if err != nil {
+		return errorStatus("error listing role-bindings", err)
+	}
+
+	for _, rb := range rbs {
+		if err := stream.Send(roleBindingMessage(rb)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// authorizeRoleBinding checks the given action on the resource a role-binding
+// belongs to, and returns the role-binding resource.
+func (s *Server) authorizeRoleBinding(ctx context.Context, id string, action iapl.RoleBindingAction) (types.Resource, error) {
+	subject, err := s.currentSubject(ctx)
+	if err != nil {
+		return types.Resource{}, err
+	}
+
+	rbResource, err := s.resourceFromID(id)
+	if err != nil {
+		return types.Resource{}, err
+	}
+
+	resource, err := s.bindings.GetRoleBindingResource(ctx, rbResource)
+	if err != nil {
+		return types.Resource{}, errorStatus("error getting role-binding resource", err)
+	}
+
+	if err := s.checkAction(ctx, subject, string(action), resource); err != nil {
+		return types.Resource{}, err
+	}
+
+	return rbResource, nil
+}
+
+func (s *Server) checkAction(ctx context.Context, subject types.Resource, action string, resource types.Resource) error {
+	err := s.checker.SubjectHasPermission(ctx, subject, action, resource)
+
+	switch {
+	case errors.Is(err, query.ErrActionNotAssigned):
+		return status.Errorf(
+			codes.PermissionDenied,
+			"subject '%s' does not have permission to perform action '%s' on resource '%s'",
+			subject.ID.String(), action, resource.ID.String(),
+		)
+	case errors.Is(err, query.ErrInvalidAction):
+		return status.Errorf(codes.InvalidArgument, "invalid action '%s' for resource '%s'", action, resource.ID.String())
+	case err != nil:
+		return status.Error(codes.Internal, "an error occurred checking permissions")
+	default:
+		return nil
+	}
+}
+
+func (s *Server) currentSubject(ctx context.Context) (types.Resource, error) {
+	actor, _ := ctx.Value(echojwtx.ActorCtxKey).(string)
+
+	subjectID, err := gidx.Parse(actor)
+	if err != nil {
+		return types.Resource{}, status.Error(codes.Unauthenticated, "failed to get the subject")
+	}
+
+	subject, err := s.checker.NewResourceFromID(subjectID)
+	if err != nil {
+		return types.Resource{}, status.Errorf(codes.InvalidArgument, "error processing subject ID: %s", err.Error())
+	}
+
+	return subject, nil
+}
+
+func (s *Server) resourceFromID(idStr string) (types.Resource, error) {
+	id, err := gidx.Parse(idStr)
+	if err != nil {
+		return types.Resource{}, status.Errorf(codes.InvalidArgument, "error parsing ID %q: %s", idStr, err.Error())
+	}
+
+	resource, err := s.checker.NewResourceFromID(id)
+	if err != nil {
+		return types.Resource{}, errorStatus(fmt.Sprintf("error creating resource %q", idStr), err)
+	}
+
+	return resource, nil
+}
+
+// resolveResource returns the resource for a prefixed ID or a registered alias.
+func (s *Server) resolveResource(ctx context.Context, idStr string) (types.Resource, error) {
+	resource, err := s.checker.ResolveResource(ctx, idStr)
+	if err != nil {
+		return types.Resource{}, errorStatus(fmt.Sprintf("error resolving resource %q", idStr), err)
+	}
+
+	return resource, nil
+}
+
+func (s *Server) roleBindingSubjects(ctx context.Context, ids []string) ([]types.RoleBindingSubject, error) {
+	subjects := make([]types.RoleBindingSubject, len(ids))
+
+	for i, id := range ids {
+		subj, err := s.resolveResource(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		subjects[i] = types.RoleBindingSubject{SubjectResource: subj}
+	}
+
+	return subjects, nil
+}
+
+func roleMessage(role types.Role) *permissionsv1.Role {
+	return &permissionsv1.Role{
+		Id:         role.ID.String(),
+		Name:       role.Name,
+		Actions:    role.Actions,
+		ResourceId: role.ResourceID.String(),
+		CreatedBy:  role.CreatedBy.String(),
+		UpdatedBy:  role.UpdatedBy.String(),
+		CreatedAt:  role.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  role.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func roleBindingMessage(rb types.RoleBinding) *permissionsv1.RoleBinding {
+	subjectIDs := make([]string, len(rb.SubjectIDs))
+
+	for i, id := range rb.SubjectIDs {
+		subjectIDs[i] = id.String()
+	}
+
+	return &permissionsv1.RoleBinding{
+		Id:         rb.ID.String(),
+		ResourceId: rb.ResourceID.String(),
+		RoleId:     rb.RoleID.String(),
+		SubjectIds: subjectIDs,
+		CreatedBy:  rb.CreatedBy.String(),
+		UpdatedBy:  rb.UpdatedBy.String(),
+		CreatedAt:  rb.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  rb.UpdatedAt.Format(time.RFC3339),
+	}
+}
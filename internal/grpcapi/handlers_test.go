@@ -0,0 +1,221 @@
+package grpcapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/gidx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/permissions-api/internal/api"
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mocks"
+	"go.infratographer.com/permissions-api/internal/spicedbx/testspicedb"
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+	permissionsv1 "go.infratographer.com/permissions-api/pkg/proto/permissions/v1"
+)
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+
+	subject := types.Resource{Type: "user", ID: "idntusr-abc123"}
+	resource := types.Resource{Type: "tenant", ID: "tnntten-abc123"}
+
+	checker := mocks.NewChecker(t)
+
+	checker.On("NewResourceFromID", subject.ID).Return(subject, nil)
+	checker.On("ResolveResource", mock.Anything, "urn:partner:tenant:abc123").Return(resource, nil)
+	checker.On("SubjectHasPermission", mock.Anything, subject, "loadbalancer_get", resource).Return(nil)
+	checker.On("SubjectHasPermission", mock.Anything, subject, "loadbalancer_delete", resource).Return(query.ErrActionNotAssigned)
+	checker.On("SubjectHasPermission", mock.Anything, subject, "loadbalancer_fly", resource).Return(query.ErrInvalidAction)
+
+	// checks only need the checker
+	srv := &Server{checker: checker}
+
+	tc := []testingx.TestCase[string, *CheckResponse]{
+		{
+			Name:  "Allowed",
+			Input: "loadbalancer_get",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[*CheckResponse]) {
+				require.NoError(t, res.Err)
+				assert.True(t, res.Success.Allowed)
+			},
+		},
+		{
+			Name:  "Denied",
+			Input: "loadbalancer_delete",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[*CheckResponse]) {
+				assert.Equal(t, codes.PermissionDenied, status.Code(res.Err))
+			},
+		},
+		{
+			Name:  "InvalidAction",
+			Input: "loadbalancer_fly",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[*CheckResponse]) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(res.Err))
+			},
+		},
+		{
+			Name:  "MissingAction",
+			Input: "",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[*CheckResponse]) {
+				assert.Equal(t, codes.InvalidArgument, status.Code(res.Err))
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, action string) testingx.TestResult[*CheckResponse] {
+		ctx = context.WithValue(ctx, echojwtx.ActorCtxKey, subject.ID.String())
+
+		resp, err := srv.Check(ctx, &permissionsv1.CheckRequest{ResourceId: "urn:partner:tenant:abc123", Action: action})
+
+		return testingx.TestResult[*CheckResponse]{Success: resp, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
+
+func TestDeleteRole(t *testing.T) {
+	subject := types.Resource{Type: "user", ID: "idntusr-abc123"}
+	role := types.Resource{Type: "rolev2", ID: "permrv2-abc123"}
+
+	ctx := context.WithValue(context.Background(), echojwtx.ActorCtxKey, subject.ID.String())
+
+	checker := mocks.NewChecker(t)
+
+	checker.On("NewResourceFromID", subject.ID).Return(subject, nil)
+	checker.On("NewResourceFromID", role.ID).Return(role, nil)
+	checker.On("SubjectHasPermission", mock.Anything, subject, string(iapl.RoleActionDelete), role).Return(nil)
+
+	roles := mocks.NewRoleManager(t)
+
+	roles.On("DeleteRoleV2", mock.Anything, role).Return(nil).Once()
+
+	srv := &Server{checker: checker, roles: roles}
+
+	resp, err := srv.DeleteRole(ctx, &permissionsv1.DeleteRoleRequest{Id: role.ID.String()})
+	require.NoError(t, err)
+
+	assert.True(t, resp.Success)
+}
+
+func TestRoleDelegation(t *testing.T) {
+	ctx := context.Background()
+
+	doc, err := iapl.LoadPolicyDocumentFromFiles("../../policies/policy.example.yaml")
+	require.NoError(t, err)
+
+	doc.RBAC.Delegation = &iapl.RBACDelegation{AdminAction: "iam_admin"}
+
+	policy := iapl.NewPolicy(doc)
+	require.NoError(t, policy.Validate())
+
+	client, namespace := testspicedb.NewTestSpiceDB(ctx, t, "testgrpcdelegation", policy.Schema(), policy.Caveats()...)
+
+	store, cleanStore := teststore.NewTestStorage(t)
+
+	t.Cleanup(cleanStore)
+
+	// the roles of the delegate are set up without delegation restrictions
+	setup, err := query.NewEngine(namespace, client, store, query.WithPolicy(policy), query.WithoutDelegation())
+	require.NoError(t, err)
+
+	engine, err := query.NewEngine(namespace, client, store, query.WithPolicy(policy))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, setup.Stop())
+		assert.NoError(t, engine.Stop())
+	})
+
+	tenant, err := engine.NewResourceFromID(gidx.PrefixedID("tnntten-root"))
+	require.NoError(t, err)
+	delegate, err := engine.NewResourceFromID(gidx.PrefixedID("idntusr-delegate"))
+	require.NoError(t, err)
+
+	delegateRole, err := setup.CreateRoleV2(ctx, delegate, tenant, "delegate", []string{
+		string(iapl.RoleActionCreate),
+		string(iapl.RoleActionUpdate),
+		string(iapl.RoleBindingActionCreate),
+		"loadbalancer_get",
+	})
+	require.NoError(t, err)
+
+	adminRole, err := setup.CreateRoleV2(ctx, delegate, tenant, "admin", []string{"iam_admin", "loadbalancer_delete"})
+	require.NoError(t, err)
+
+	delegateRoleRes, err := engine.NewResourceFromID(delegateRole.ID)
+	require.NoError(t, err)
+
+	_, err = setup.CreateRoleBinding(ctx, delegate, tenant, delegateRoleRes, []types.RoleBindingSubject{{SubjectResource: delegate}})
+	require.NoError(t, err)
+
+	srv := &Server{checker: engine, roles: engine, bindings: engine, limits: api.DefaultLimits()}
+
+	tc := []testingx.TestCase[func(ctx context.Context) error, any]{
+		{
+			Name: "CreateRoleHeldActions",
+			Input: func(ctx context.Context) error {
+				_, err := srv.CreateRole(ctx, &permissionsv1.CreateRoleRequest{ResourceId: tenant.ID.String(), Name: "lb_viewer", Actions: []string{"loadbalancer_get"}})
+
+				return err
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.NoError(t, res.Err)
+			},
+		},
+		{
+			Name: "CreateRole",
+			Input: func(ctx context.Context) error {
+				_, err := srv.CreateRole(ctx, &permissionsv1.CreateRoleRequest{ResourceId: tenant.ID.String(), Name: "lb_deleter", Actions: []string{"loadbalancer_delete"}})
+
+				return err
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.Equal(t, codes.PermissionDenied, status.Code(res.Err))
+			},
+		},
+		{
+			Name: "UpdateRole",
+			Input: func(ctx context.Context) error {
+				_, err := srv.UpdateRole(ctx, &permissionsv1.UpdateRoleRequest{Id: delegateRole.ID.String(), Name: "delegate", Actions: append(delegateRole.Actions, "loadbalancer_delete")})
+
+				return err
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.Equal(t, codes.PermissionDenied, status.Code(res.Err))
+			},
+		},
+		{
+			Name: "CreateRoleBinding",
+			Input: func(ctx context.Context) error {
+				_, err := srv.CreateRoleBinding(ctx, &permissionsv1.CreateRoleBindingRequest{
+					ResourceId: tenant.ID.String(),
+					RoleId:     adminRole.ID.String(),
+					SubjectIds: []string{"idntusr-other"},
+				})
+
+				return err
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.Equal(t, codes.PermissionDenied, status.Code(res.Err))
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, call func(ctx context.Context) error) testingx.TestResult[any] {
+		ctx = context.WithValue(ctx, echojwtx.ActorCtxKey, delegate.ID.String())
+
+		return testingx.TestResult[any]{Err: call(ctx)}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
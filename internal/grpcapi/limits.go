@@ -0,0 +1,74 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.infratographer.com/x/echojwtx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/permissions-api/internal/api"
+)
+
+// RateLimiter takes rate limit tokens for the requests of subjects. The REST
+// API router implements it, so subjects share one rate limit across transports.
+type RateLimiter interface {
+	ReserveRateLimit(subject string, class api.RouteClass) (bool, time.Duration)
+}
+
+// WithRateLimiter limits the requests of authenticated subjects.
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(s *Server) error {
+		s.rateLimiter = limiter
+
+		return nil
+	}
+}
+
+// WithLimits sets the limits of request payloads, the REST API limits are
+// used so both transports reject the same requests.
+func WithLimits(limits api.LimitsConfig) Option {
+	return func(s *Server) error {
+		s.limits = limits
+
+		return nil
+	}
+}
+
+// methodClass returns the rate limit class of a method, grouped as REST routes are.
+func methodClass(fullMethod string) api.RouteClass {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+
+	switch {
+	case method == "Check":
+		return api.RouteClassChecks
+	case
+		strings.HasPrefix(method, "Create"),
+		strings.HasPrefix(method, "Update"),
+		strings.HasPrefix(method, "Delete"):
+		return api.RouteClassMutations
+	default:
+		return api.RouteClassDefault
+	}
+}
+
+// rateLimit takes a rate limit token for the authenticated subject of ctx.
+func (s *Server) rateLimit(ctx context.Context, fullMethod string) error {
+	if s.rateLimiter == nil {
+		return nil
+	}
+
+	subject, _ := ctx.Value(echojwtx.ActorCtxKey).(string)
+	if subject == "" {
+		return nil
+	}
+
+	allowed, retryAfter := s.rateLimiter.ReserveRateLimit(subject, methodClass(fullMethod))
+	if !allowed {
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry after %s", retryAfter.Round(time.Second))
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.infratographer.com/x/echojwtx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/permissions-api/internal/api"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	permissionsv1 "go.infratographer.com/permissions-api/pkg/proto/permissions/v1"
+)
+
+// testRateLimiter allows requests until the subject used its tokens.
+type testRateLimiter struct {
+	tokens  int
+	classes []api.RouteClass
+}
+
+func (l *testRateLimiter) ReserveRateLimit(_ string, class api.RouteClass) (bool, time.Duration) {
+	l.classes = append(l.classes, class)
+
+	if l.tokens == 0 {
+		return false, 2 * time.Second
+	}
+
+	l.tokens--
+
+	return true, 0
+}
+
+func TestLimits(t *testing.T) {
+	ctx := context.Background()
+
+	// limits are checked before the engine is used
+	srv := &Server{limits: api.LimitsConfig{MaxRoleActions: 2, MaxRoleNameLength: 8, MaxRoleBindingSubjects: 1}}
+
+	tc := []testingx.TestCase[func(ctx context.Context) error, any]{
+		{
+			Name: "CreateRoleActions",
+			Input: func(ctx context.Context) error {
+				_, err := srv.CreateRole(ctx, &permissionsv1.CreateRoleRequest{Name: "lb", Actions: []string{"a", "b", "c"}})
+
+				return err
+			},
+		},
+		{
+			Name: "UpdateRoleName",
+			Input: func(ctx context.Context) error {
+				_, err := srv.UpdateRole(ctx, &permissionsv1.UpdateRoleRequest{Name: strings.Repeat("x", 9)})
+
+				return err
+			},
+		},
+		{
+			Name: "CreateRoleBindingSubjects",
+			Input: func(ctx context.Context) error {
+				_, err := srv.CreateRoleBinding(ctx, &permissionsv1.CreateRoleBindingRequest{SubjectIds: []string{"idntusr-a", "idntusr-b"}})
+
+				return err
+			},
+		},
+		{
+			Name: "UpdateRoleBindingSubjects",
+			Input: func(ctx context.Context) error {
+				_, err := srv.UpdateRoleBinding(ctx, &permissionsv1.UpdateRoleBindingRequest{SubjectIds: []string{"idntusr-a", "idntusr-b"}})
+
+				return err
+			},
+		},
+	}
+
+	for i := range tc {
+		tc[i].CheckFn = func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+			assert.Equal(t, codes.InvalidArgument, status.Code(res.Err))
+			assert.Contains(t, res.Err.Error(), api.ErrLimitExceeded.Error())
+		}
+	}
+
+	testFn := func(ctx context.Context, call func(ctx context.Context) error) testingx.TestResult[any] {
+		return testingx.TestResult[any]{Err: call(ctx)}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
+
+func TestRateLimit(t *testing.T) {
+	limiter := &testRateLimiter{tokens: 2}
+	srv := &Server{rateLimiter: limiter}
+
+	ctx := context.WithValue(context.Background(), echojwtx.ActorCtxKey, "idntusr-test")
+
+	assert.NoError(t, srv.rateLimit(ctx, permissionsv1.PermissionsService_Check_FullMethodName))
+	assert.NoError(t, srv.rateLimit(ctx, permissionsv1.PermissionsService_CreateRoleBinding_FullMethodName))
+
+	err := srv.rateLimit(ctx, permissionsv1.PermissionsService_ListRoles_FullMethodName)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, err.Error(), "retry after 2s")
+
+	assert.Equal(t, []api.RouteClass{api.RouteClassChecks, api.RouteClassMutations, api.RouteClassDefault}, limiter.classes)
+
+	// unauthenticated requests are rejected before being limited
+	assert.NoError(t, srv.rateLimit(context.Background(), permissionsv1.PermissionsService_Check_FullMethodName))
+}
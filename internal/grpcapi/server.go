@@ -0,0 +1,213 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/echojwtx"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/permissions-api/internal/api"
+	"go.infratographer.com/permissions-api/internal/query"
+	permissionsv1 "go.infratographer.com/permissions-api/pkg/proto/permissions/v1"
+)
+
+var tracer = otel.Tracer("go.infratographer.com/permissions-api/internal/grpcapi")
+
+// Engine is the part of the query engine served by the gRPC API.
+type Engine interface {
+	query.Checker
+	query.RoleManager
+	query.BindingManager
+}
+
+// Server serves the permissions service over gRPC.
+type Server struct {
+	checker  query.Checker
+	roles    query.RoleManager
+	bindings query.BindingManager
+	logger   *zap.SugaredLogger
+
+	authMW      echo.MiddlewareFunc
+	echo        *echo.Echo
+	grpcOptions []grpc.ServerOption
+
+	grpc *grpc.Server
+
+	permissionsv1.UnimplementedPermissionsServiceServer
+
+	limits      api.LimitsConfig
+	rateLimiter RateLimiter
+}
+
+var _ permissionsv1.PermissionsServiceServer = (*Server)(nil)
+
+// NewServer returns a new gRPC server for the given engine. Requests are
+// authenticated with the same OIDC configuration as the REST API, tokens are
+// read from the "authorization" metadata key.
+func NewServer(authCfg echojwtx.AuthConfig, engine Engine, options ...Option) (*Server, error) {
+	auth, err := echojwtx.NewAuth(context.Background(), authCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		checker:  engine,
+		roles:    engine,
+		bindings: engine,
+		logger:   zap.NewNop().Sugar(),
+		authMW:   auth.Middleware(),
+		echo:     echo.New(),
+		limits:   api.DefaultLimits(),
+	}
+
+	for _, opt := range options {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	grpcOptions := append([]grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(s.unaryAuthInterceptor),
+		grpc.ChainStreamInterceptor(s.streamAuthInterceptor),
+	}, s.grpcOptions...)
+
+	s.grpc = grpc.NewServer(grpcOptions...)
+
+	permissionsv1.RegisterPermissionsServiceServer(s.grpc, s)
+
+	return s, nil
+}
+
+// Serve accepts connections on the given listener, it blocks until the server is stopped.
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpc.Serve(lis)
+}
+
+// ListenAndServe listens on the given address and serves requests.
+func (s *Server) ListenAndServe(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infow("starting grpc server", "address", lis.Addr().String())
+
+	return s.Serve(lis)
+}
+
+// GracefulStop stops the server once all pending requests have completed.
+func (s *Server) GracefulStop() {
+	s.grpc.GracefulStop()
+}
+
+// Option defines a server option function.
+type Option func(s *Server) error
+
+// WithLogger sets the logger for the server.
+func WithLogger(logger *zap.SugaredLogger) Option {
+	return func(s *Server) error {
+		s.logger = logger.Named("grpcapi")
+
+		return nil
+	}
+}
+
+// WithGRPCServerOptions appends additional options to the underlying grpc server.
+func WithGRPCServerOptions(opts ...grpc.ServerOption) Option {
+	return func(s *Server) error {
+		s.grpcOptions = append(s.grpcOptions, opts...)
+
+		return nil
+	}
+}
+
+// authenticate validates the bearer token from the incoming metadata using the
+// echo JWT middleware, and returns a context carrying the token subject.
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to authenticate request")
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, value := range md.Get("authorization") {
+			req.Header.Add(echo.HeaderAuthorization, value)
+		}
+	}
+
+	var actor string
+
+	c := s.echo.NewContext(req, discardResponseWriter{})
+
+	err = s.authMW(func(c echo.Context) error {
+		actor = echojwtx.Actor(c)
+
+		return nil
+	})(c)
+	if err != nil {
+		s.logger.Debugw("grpc request authentication failed", "error", err)
+
+		return nil, status.Error(codes.Unauthenticated, "invalid or missing credentials")
+	}
+
+	return context.WithValue(ctx, echojwtx.ActorCtxKey, actor), nil
+}
+
+func (s *Server) unaryAuthInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.rateLimit(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *Server) streamAuthInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+
+	if err := s.rateLimit(ctx, info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// discardResponseWriter satisfies the http.ResponseWriter required by the
+// echo context used to run the authentication middleware.
+type discardResponseWriter struct{}
+
+func (discardResponseWriter) Header() http.Header {
+	return http.Header{}
+}
+
+func (discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (discardResponseWriter) WriteHeader(int) {}
@@ -0,0 +1,3 @@
+// Package health serves health and readiness probes reporting the status of
+// every component the service depends on.
+package health
@@ -0,0 +1,188 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultTimeout is the default time a single component check may take.
+	DefaultTimeout = 5 * time.Second
+
+	// StatusUp reports a working component or service.
+	StatusUp = "up"
+	// StatusDown reports a failing component or service.
+	StatusDown = "down"
+)
+
+// CheckFunc verifies a component is working, returning an error if it is not.
+type CheckFunc func(ctx context.Context) error
+
+// ComponentStatus is the status of a single component.
+type ComponentStatus struct {
+	Status string `json:"status"`
+	// Latency is how long the check took, in milliseconds.
+	Latency float64 `json:"latency_ms"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// Report is the status of the service and all its components.
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+type check struct {
+	name string
+	fn   CheckFunc
+	// readiness checks are only run by the readiness probe.
+	readiness bool
+}
+
+// Checker runs the checks of the components of a service.
+type Checker struct {
+	logger  *zap.SugaredLogger
+	timeout time.Duration
+	checks  []check
+}
+
+// Option is a functional option for the Checker.
+type Option func(c *Checker)
+
+// WithLogger sets the logger failed checks are logged to.
+func WithLogger(logger *zap.SugaredLogger) Option {
+	return func(c *Checker) {
+		c.logger = logger
+	}
+}
+
+// WithTimeout sets the time a single component check may take.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Checker) {
+		if timeout > 0 {
+			c.timeout = timeout
+		}
+	}
+}
+
+// NewChecker creates a new Checker without any checks.
+func NewChecker(options ...Option) *Checker {
+	c := &Checker{
+		logger:  zap.NewNop().Sugar(),
+		timeout: DefaultTimeout,
+	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	return c
+}
+
+// AddCheck adds a check of the named component, checks must be added before
+// the checker serves requests.
+func (c *Checker) AddCheck(name string, fn CheckFunc) *Checker {
+	c.checks = append(c.checks, check{name: name, fn: fn})
+
+	return c
+}
+
+// AddReadinessCheck adds a check of the named component which is only run by
+// the readiness probe, for conditions which should stop traffic to the
+// service without reporting it unhealthy.
+func (c *Checker) AddReadinessCheck(name string, fn CheckFunc) *Checker {
+	c.checks = append(c.checks, check{name: name, fn: fn, readiness: true})
+
+	return c
+}
+
+// Run runs the checks concurrently and reports their status, the service is
+// down if any component is down. Readiness checks are only run if readiness
+// is set.
+func (c *Checker) Run(ctx context.Context, readiness bool) Report {
+	report := Report{
+		Status:     StatusUp,
+		Components: make(map[string]ComponentStatus, len(c.checks)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for _, chk := range c.checks {
+		if chk.readiness && !readiness {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(chk check) {
+			defer wg.Done()
+
+			status := c.runCheck(ctx, chk)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Components[chk.name] = status
+
+			if status.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(chk)
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) runCheck(ctx context.Context, chk check) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	err := chk.fn(ctx)
+
+	status := ComponentStatus{
+		Status:  StatusUp,
+		Latency: float64(time.Since(start)) / float64(time.Millisecond),
+	}
+
+	if err != nil {
+		c.logger.Warnw("health check failed", "component", chk.name, "error", err)
+
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+
+	return status
+}
+
+// Routes registers the /healthz and /readyz probes, replacing the readiness
+// probe of the echox server which only reports check errors.
+func (c *Checker) Routes(rg *echo.Group) {
+	rg.GET("/healthz", c.handler(false))
+	rg.GET("/readyz", c.handler(true))
+}
+
+// handler responds with the report of the checks, with 503 Service
+// Unavailable if any component is down.
+func (c *Checker) handler(readiness bool) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		report := c.Run(ctx.Request().Context(), readiness)
+
+		if report.Status != StatusUp {
+			return ctx.JSON(http.StatusServiceUnavailable, report)
+		}
+
+		return ctx.JSON(http.StatusOK, report)
+	}
+}
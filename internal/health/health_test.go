@@ -0,0 +1,131 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker(t *testing.T) {
+	t.Parallel()
+
+	errUnreachable := errors.New("unreachable")
+
+	testCases := []struct {
+		name       string
+		checks     map[string]CheckFunc
+		readiness  map[string]CheckFunc
+		path       string
+		wantCode   int
+		wantStatus map[string]string
+	}{
+		{
+			name: "Up",
+			checks: map[string]CheckFunc{
+				"spicedb": func(context.Context) error { return nil },
+				"storage": func(context.Context) error { return nil },
+			},
+			path:       "/readyz",
+			wantCode:   http.StatusOK,
+			wantStatus: map[string]string{"spicedb": StatusUp, "storage": StatusUp},
+		},
+		{
+			name: "ComponentDown",
+			checks: map[string]CheckFunc{
+				"spicedb": func(context.Context) error { return nil },
+				"nats":    func(context.Context) error { return errUnreachable },
+			},
+			path:       "/healthz",
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: map[string]string{"spicedb": StatusUp, "nats": StatusDown},
+		},
+		{
+			name: "Timeout",
+			checks: map[string]CheckFunc{
+				"storage": func(ctx context.Context) error {
+					<-ctx.Done()
+
+					return ctx.Err()
+				},
+			},
+			path:       "/readyz",
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: map[string]string{"storage": StatusDown},
+		},
+		{
+			name: "ReadinessCheckSkippedByHealth",
+			checks: map[string]CheckFunc{
+				"spicedb": func(context.Context) error { return nil },
+			},
+			readiness: map[string]CheckFunc{
+				"schema": func(context.Context) error { return errUnreachable },
+			},
+			path:       "/healthz",
+			wantCode:   http.StatusOK,
+			wantStatus: map[string]string{"spicedb": StatusUp},
+		},
+		{
+			name: "ReadinessCheckDown",
+			checks: map[string]CheckFunc{
+				"spicedb": func(context.Context) error { return nil },
+			},
+			readiness: map[string]CheckFunc{
+				"schema": func(context.Context) error { return errUnreachable },
+			},
+			path:       "/readyz",
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: map[string]string{"spicedb": StatusUp, "schema": StatusDown},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			checker := NewChecker(WithTimeout(50 * time.Millisecond))
+
+			for name, fn := range tc.checks {
+				checker.AddCheck(name, fn)
+			}
+
+			for name, fn := range tc.readiness {
+				checker.AddReadinessCheck(name, fn)
+			}
+
+			e := echo.New()
+
+			checker.Routes(e.Group(""))
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			resp := httptest.NewRecorder()
+
+			e.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.wantCode, resp.Code)
+
+			var report Report
+
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+
+			statuses := make(map[string]string, len(report.Components))
+
+			for name, component := range report.Components {
+				statuses[name] = component.Status
+
+				if component.Status == StatusDown {
+					assert.NotEmpty(t, component.Error)
+				}
+			}
+
+			assert.Equal(t, tc.wantStatus, statuses)
+		})
+	}
+}
@@ -7,6 +7,10 @@ var (
 	ErrorTypeExists = errors.New("type already exists")
 	// ErrorActionBindingExists represents an error where a duplicate binding between a type and action was declared.
 	ErrorActionBindingExists = errors.New("action binding already exists")
+	// ErrorActionGroupExists represents an error where a duplicate action group, or one named after an action, was declared.
+	ErrorActionGroupExists = errors.New("action group already exists")
+	// ErrorRoleTemplateExists represents an error where a duplicate role template was declared.
+	ErrorRoleTemplateExists = errors.New("role template already exists")
 	// ErrorUnknownType represents an error where a resource type is unknown in the authorization policy.
 	ErrorUnknownType = errors.New("unknown resource type")
 	// ErrorInvalidCondition represents an error where an action binding condition is invalid.
@@ -17,6 +21,12 @@ var (
 	ErrorUnknownAction = errors.New("unknown action")
 	// ErrorMissingRelationship represents an error where a mandatory relationship is missing.
 	ErrorMissingRelationship = errors.New("missing relationship")
+	// ErrorCaveatExists represents an error where a duplicate caveat was declared.
+	ErrorCaveatExists = errors.New("caveat already exists")
+	// ErrorInvalidCaveat represents an error where a caveat is missing its expression or has invalid parameters.
+	ErrorInvalidCaveat = errors.New("invalid caveat")
+	// ErrorInvalidRoleBindingSubject represents an error where a role-binding subject can't be granted role actions.
+	ErrorInvalidRoleBindingSubject = errors.New("invalid role-binding subject")
 	// ErrorDuplicateRBACDefinition represents an error where a duplicate RBAC definition was declared.
 	ErrorDuplicateRBACDefinition = errors.New("duplicated RBAC definition")
 )
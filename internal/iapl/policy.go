@@ -1,12 +1,16 @@
 package iapl
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"go.infratographer.com/permissions-api/internal/types"
@@ -21,6 +25,9 @@ type PolicyDocument struct {
 	Unions         []Union
 	Actions        []Action
 	ActionBindings []ActionBinding
+	ActionGroups   []ActionGroup
+	RoleTemplates  []RoleTemplate
+	Caveats        []Caveat
 	RBAC           *RBAC
 }
 
@@ -49,6 +56,36 @@ type Action struct {
 	Name string
 }
 
+// ActionGroup represents a named bundle of actions, e.g. read-only access to
+// load balancers. Action groups may be used in place of their actions when
+// creating or updating roles.
+type ActionGroup struct {
+	Name        string
+	Description string
+	Actions     []string
+}
+
+// RoleTemplate represents a standard role, e.g. a viewer role, which is
+// provisioned for every new role owner.
+type RoleTemplate struct {
+	Name string
+	// Actions are the actions of the role, action groups are expanded to
+	// their actions.
+	Actions []string
+	// Owners are the role owner types the role is provisioned for, the role is
+	// provisioned for all role owners if empty.
+	Owners []string
+}
+
+// Caveat represents a named condition role bindings may be conditioned on,
+// e.g. only allowing requests from a CIDR range. The expression is written in
+// the SpiceDB caveat language over the parameters of the caveat.
+type Caveat struct {
+	Name       string
+	Parameters []types.CaveatParameter
+	Expression string
+}
+
 // ActionBinding represents a binding of an action to a resource type or union.
 type ActionBinding struct {
 	ActionName    string
@@ -84,6 +121,12 @@ type Policy interface {
 	Validate() error
 	Schema() []types.ResourceType
 	RBAC() *RBAC
+	ActionGroups() []ActionGroup
+	RoleTemplates() []RoleTemplate
+	Caveats() []types.Caveat
+	// Hash returns the hex encoded SHA-256 hash of the policy document, so
+	// loaded policies can be compared.
+	Hash() string
 }
 
 var _ Policy = &policy{}
@@ -115,6 +158,14 @@ func NewPolicy(p PolicyDocument) Policy {
 		ac[a.Name] = a
 	}
 
+	// role subject types default to the role-binding subjects bound directly,
+	// the document is copied so the caller's RBAC is left unchanged.
+	if p.RBAC != nil && len(p.RBAC.RoleSubjectTypes) == 0 {
+		rbac := *p.RBAC
+		rbac.RoleSubjectTypes = rbac.DirectRoleBindingSubjectTypes()
+		p.RBAC = &rbac
+	}
+
 	out := policy{
 		rt: rt,
 		un: un,
@@ -148,6 +199,12 @@ func (p PolicyDocument) MergeWithPolicyDocument(other PolicyDocument) PolicyDocu
 
 	p.ActionBindings = append(p.ActionBindings, other.ActionBindings...)
 
+	p.ActionGroups = append(p.ActionGroups, other.ActionGroups...)
+
+	p.RoleTemplates = append(p.RoleTemplates, other.RoleTemplates...)
+
+	p.Caveats = append(p.Caveats, other.Caveats...)
+
 	if other.RBAC != nil {
 		p.RBAC = other.RBAC
 	}
@@ -163,25 +220,31 @@ func loadPolicyDocumentFromFile(filePath string) (PolicyDocument, error) {
 
 	defer file.Close()
 
+	return LoadPolicyDocument(filePath, file)
+}
+
+// LoadPolicyDocument reads all YAML policy documents from r, merges them, and
+// returns a new merged PolicyDocument. The name identifies r in errors.
+func LoadPolicyDocument(name string, r io.Reader) (PolicyDocument, error) {
 	var (
 		finalPolicyDocument = PolicyDocument{}
-		decoder             = yaml.NewDecoder(file)
+		decoder             = yaml.NewDecoder(r)
 		documentIndex       int
 	)
 
 	for {
 		var policyDocument PolicyDocument
 
-		if err = decoder.Decode(&policyDocument); err != nil {
+		if err := decoder.Decode(&policyDocument); err != nil {
 			if !errors.Is(err, io.EOF) {
-				return PolicyDocument{}, fmt.Errorf("%s document %d: %w", filePath, documentIndex, err)
+				return PolicyDocument{}, fmt.Errorf("%s document %d: %w", name, documentIndex, err)
 			}
 
 			break
 		}
 
 		if finalPolicyDocument.RBAC != nil && policyDocument.RBAC != nil {
-			return PolicyDocument{}, fmt.Errorf("%s document %d: %w", filePath, documentIndex, ErrorDuplicateRBACDefinition)
+			return PolicyDocument{}, fmt.Errorf("%s document %d: %w", name, documentIndex, ErrorDuplicateRBACDefinition)
 		}
 
 		finalPolicyDocument = finalPolicyDocument.MergeWithPolicyDocument(policyDocument)
@@ -416,6 +479,160 @@ func (v *policy) validateActionBindings() error {
 	return nil
 }
 
+// validateActionGroups validates action groups to ensure that:
+//   - action group names are unique and do not conflict with action names
+//   - action groups only contain defined actions
+func (v *policy) validateActionGroups() error {
+	groups := make(map[string]struct{}, len(v.p.ActionGroups))
+
+	for _, group := range v.p.ActionGroups {
+		if _, ok := groups[group.Name]; ok {
+			return fmt.Errorf("%s: %w", group.Name, ErrorActionGroupExists)
+		}
+
+		if _, ok := v.ac[group.Name]; ok {
+			return fmt.Errorf("%s: %w", group.Name, ErrorActionGroupExists)
+		}
+
+		groups[group.Name] = struct{}{}
+
+		for _, action := range group.Actions {
+			if _, ok := v.ac[action]; !ok {
+				return fmt.Errorf("%s: actions: %s: %w", group.Name, action, ErrorUnknownAction)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateRoleTemplates validates role templates to ensure that:
+//   - role template names are unique
+//   - role templates only contain defined actions or action groups
+//   - role templates are only provisioned for role owners
+func (v *policy) validateRoleTemplates() error {
+	if len(v.p.RoleTemplates) == 0 {
+		return nil
+	}
+
+	if v.p.RBAC == nil {
+		return fmt.Errorf("%w: role templates require RBAC", ErrorUnknownType)
+	}
+
+	groups := make(map[string]struct{}, len(v.p.ActionGroups))
+
+	for _, group := range v.p.ActionGroups {
+		groups[group.Name] = struct{}{}
+	}
+
+	templates := make(map[string]struct{}, len(v.p.RoleTemplates))
+
+	for _, template := range v.p.RoleTemplates {
+		if _, ok := templates[template.Name]; ok {
+			return fmt.Errorf("%s: %w", template.Name, ErrorRoleTemplateExists)
+		}
+
+		templates[template.Name] = struct{}{}
+
+		for _, action := range template.Actions {
+			_, isAction := v.ac[action]
+			_, isGroup := groups[action]
+
+			if !isAction && !isGroup {
+				return fmt.Errorf("%s: actions: %s: %w", template.Name, action, ErrorUnknownAction)
+			}
+		}
+
+		for _, owner := range template.Owners {
+			if _, ok := v.p.RBAC.RoleOwnersSet()[owner]; !ok {
+				return fmt.Errorf("%s: owners: %w: %s is not a role owner", template.Name, ErrorUnknownType, owner)
+			}
+		}
+	}
+
+	return nil
+}
+
+// caveatParameterTypes are the parameter types supported by SpiceDB caveats,
+// list and map parameters are validated by their element type.
+var caveatParameterTypes = map[string]struct{}{
+	"any":       {},
+	"int":       {},
+	"uint":      {},
+	"bool":      {},
+	"string":    {},
+	"double":    {},
+	"bytes":     {},
+	"duration":  {},
+	"timestamp": {},
+	"ipaddress": {},
+}
+
+// validateCaveatParameterType checks a caveat parameter type is supported,
+// including the element types of lists and maps like list<string>.
+func validateCaveatParameterType(typ string) bool {
+	for _, generic := range []string{"list", "map"} {
+		if elem, ok := strings.CutPrefix(typ, generic+"<"); ok {
+			elem, ok = strings.CutSuffix(elem, ">")
+
+			return ok && validateCaveatParameterType(elem)
+		}
+	}
+
+	_, ok := caveatParameterTypes[typ]
+
+	return ok
+}
+
+// validateCaveats validates caveats to ensure that:
+//   - caveat names are unique
+//   - caveats have an expression and at least one parameter
+//   - parameter names are unique and their types are supported by SpiceDB
+//   - caveats are only declared with RBAC, as they condition role-bindings
+func (v *policy) validateCaveats() error {
+	if len(v.p.Caveats) == 0 {
+		return nil
+	}
+
+	if v.p.RBAC == nil {
+		return fmt.Errorf("%w: caveats require RBAC", ErrorUnknownType)
+	}
+
+	caveats := make(map[string]struct{}, len(v.p.Caveats))
+
+	for _, caveat := range v.p.Caveats {
+		if _, ok := caveats[caveat.Name]; ok {
+			return fmt.Errorf("%s: %w", caveat.Name, ErrorCaveatExists)
+		}
+
+		caveats[caveat.Name] = struct{}{}
+
+		if strings.TrimSpace(caveat.Expression) == "" {
+			return fmt.Errorf("%s: %w: missing expression", caveat.Name, ErrorInvalidCaveat)
+		}
+
+		if len(caveat.Parameters) == 0 {
+			return fmt.Errorf("%s: %w: missing parameters", caveat.Name, ErrorInvalidCaveat)
+		}
+
+		params := make(map[string]struct{}, len(caveat.Parameters))
+
+		for _, param := range caveat.Parameters {
+			if _, ok := params[param.Name]; ok || param.Name == "" {
+				return fmt.Errorf("%s: parameters: %w: duplicate or empty parameter name '%s'", caveat.Name, ErrorInvalidCaveat, param.Name)
+			}
+
+			params[param.Name] = struct{}{}
+
+			if !validateCaveatParameterType(param.Type) {
+				return fmt.Errorf("%s: parameters: %s: %w: unsupported type '%s'", caveat.Name, param.Name, ErrorInvalidCaveat, param.Type)
+			}
+		}
+	}
+
+	return nil
+}
+
 // validateRoles validates V2 role resource types to ensure that:
 //   - role resource type has a valid owner relationship
 func (v *policy) validateRoles() error {
@@ -435,6 +652,91 @@ func (v *policy) validateRoles() error {
 	return nil
 }
 
+// validateRoleBindingSubjects validates the role-binding subjects to ensure that:
+//   - every subject type exists and is listed once, as bindings find the
+//     subject relation by the type of the subject
+//   - subject relations, like group#member, exist on the subject type
+//   - subjects bound directly are role subject types, as roles only grant
+//     their actions to role subject types
+func (v *policy) validateRoleBindingSubjects() error {
+	if v.p.RBAC == nil {
+		return nil
+	}
+
+	seen := make(map[string]struct{}, len(v.p.RBAC.RoleBindingSubjects))
+
+	for _, subject := range v.p.RBAC.RoleBindingSubjects {
+		rt, ok := v.rt[subject.Name]
+		if !ok {
+			return fmt.Errorf("%s: %w", subject.Name, ErrorUnknownType)
+		}
+
+		if _, ok := seen[subject.Name]; ok {
+			return fmt.Errorf("%w: %s is listed more than once", ErrorInvalidRoleBindingSubject, subject.Name)
+		}
+
+		seen[subject.Name] = struct{}{}
+
+		if subject.SubjectRelation == "" {
+			if !slices.Contains(v.p.RBAC.RoleSubjectTypes, subject.Name) {
+				return fmt.Errorf("%w: %s is not a role subject type", ErrorInvalidRoleBindingSubject, subject.Name)
+			}
+
+			continue
+		}
+
+		if !v.findRelationship(rt.Relationships, subject.SubjectRelation) && !v.findActionBinding(subject.SubjectRelation, subject.Name) {
+			return fmt.Errorf("%s#%s: %w", subject.Name, subject.SubjectRelation, ErrorUnknownRelation)
+		}
+	}
+
+	return nil
+}
+
+// validateGroups validates the group resource type to ensure that:
+//   - group resource type exists
+//   - group resource type has the owner, member and subgroup relationships
+func (v *policy) validateGroups() error {
+	if v.p.RBAC == nil || v.p.RBAC.GroupResource == nil {
+		return nil
+	}
+
+	group := v.p.RBAC.GroupResource
+
+	rt, ok := v.rt[group.Name]
+	if !ok {
+		return fmt.Errorf("%w: group resource %s does not exist", ErrorUnknownType, group.Name)
+	}
+
+	relations := []string{group.OwnerRelation, group.MemberRelation}
+
+	if group.SubgroupRelation != "" {
+		relations = append(relations, group.SubgroupRelation)
+	}
+
+	for _, relation := range relations {
+		if !v.findRelationship(rt.Relationships, relation) {
+			return fmt.Errorf("%s: %s: %w", group.Name, relation, ErrorUnknownRelation)
+		}
+	}
+
+	return nil
+}
+
+// validateDelegation validates the delegation of role administration to
+// ensure that the admin action exists
+func (v *policy) validateDelegation() error {
+	if v.p.RBAC == nil || v.p.RBAC.Delegation == nil {
+		return nil
+	}
+
+	if _, ok := v.ac[v.p.RBAC.Delegation.AdminAction]; !ok {
+		return fmt.Errorf("adminAction: %s: %w", v.p.RBAC.Delegation.AdminAction, ErrorUnknownAction)
+	}
+
+	return nil
+}
+
 func (v *policy) expandActionBindings() {
 	for _, bn := range v.p.ActionBindings {
 		if u, ok := v.un[bn.TypeName]; ok {
@@ -567,6 +869,14 @@ func (v *policy) createRoleBindingResourceType() {
 		},
 	}
 
+	// role-bindings may be conditioned on any caveat of the policy
+	for _, caveat := range v.p.Caveats {
+		role.TargetTypes = append(role.TargetTypes, types.TargetType{
+			Name:   v.p.RBAC.RoleResource.Name,
+			Caveat: caveat.Name,
+		})
+	}
+
 	// 2. create relationship to subjects
 	subjects := Relationship{
 		Relation:    RolebindingSubjectRelation,
@@ -731,6 +1041,10 @@ func (v *policy) Validate() error {
 		return fmt.Errorf("unions: %w", err)
 	}
 
+	if err := v.validateRoleBindingSubjects(); err != nil {
+		return fmt.Errorf("rbac: roleBindingSubjects: %w", err)
+	}
+
 	if err := v.validateResourceTypes(); err != nil {
 		return fmt.Errorf("resourceTypes: %w", err)
 	}
@@ -739,10 +1053,30 @@ func (v *policy) Validate() error {
 		return fmt.Errorf("actionBindings: %w", err)
 	}
 
+	if err := v.validateActionGroups(); err != nil {
+		return fmt.Errorf("actionGroups: %w", err)
+	}
+
+	if err := v.validateRoleTemplates(); err != nil {
+		return fmt.Errorf("roleTemplates: %w", err)
+	}
+
+	if err := v.validateCaveats(); err != nil {
+		return fmt.Errorf("caveats: %w", err)
+	}
+
 	if err := v.validateRoles(); err != nil {
 		return fmt.Errorf("roles: %w", err)
 	}
 
+	if err := v.validateGroups(); err != nil {
+		return fmt.Errorf("groups: %w", err)
+	}
+
+	if err := v.validateDelegation(); err != nil {
+		return fmt.Errorf("rbac: delegation: %w", err)
+	}
+
 	return nil
 }
 
@@ -838,6 +1172,38 @@ func (v *policy) RBAC() *RBAC {
 	return v.p.RBAC
 }
 
+func (v *policy) ActionGroups() []ActionGroup {
+	return v.p.ActionGroups
+}
+
+func (v *policy) RoleTemplates() []RoleTemplate {
+	return v.p.RoleTemplates
+}
+
+func (v *policy) Caveats() []types.Caveat {
+	caveats := make([]types.Caveat, len(v.p.Caveats))
+
+	for i, caveat := range v.p.Caveats {
+		caveats[i] = types.Caveat{
+			Name:       caveat.Name,
+			Parameters: caveat.Parameters,
+			Expression: caveat.Expression,
+		}
+	}
+
+	return caveats
+}
+
+func (v *policy) Hash() string {
+	// the document only contains slices, structs and strings, so encoding it
+	// never fails and always encodes the same policy the same way.
+	data, _ := json.Marshal(v.p)
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
 func (v *policy) findRelationship(rels []Relationship, name string) bool {
 	for _, rel := range rels {
 		if rel.Relation == name {
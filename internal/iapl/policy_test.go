@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.infratographer.com/x/gidx"
 
@@ -445,6 +446,147 @@ func TestPolicy(t *testing.T) {
 				require.ErrorIs(t, res.Err, ErrorUnknownType)
 			},
 		},
+		{
+			Name: "UnknownActionInActionGroup",
+			Input: PolicyDocument{
+				Actions: []Action{
+					{Name: "qux"},
+				},
+				ActionGroups: []ActionGroup{
+					{
+						Name:    "quxes",
+						Actions: []string{"qux", "baz"},
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorUnknownAction)
+			},
+		},
+		{
+			Name: "ActionGroupNamedAfterAction",
+			Input: PolicyDocument{
+				Actions: []Action{
+					{Name: "qux"},
+				},
+				ActionGroups: []ActionGroup{
+					{
+						Name:    "qux",
+						Actions: []string{"qux"},
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorActionGroupExists)
+			},
+		},
+		{
+			Name: "DuplicateActionGroup",
+			Input: PolicyDocument{
+				Actions: []Action{
+					{Name: "qux"},
+				},
+				ActionGroups: []ActionGroup{
+					{
+						Name:    "quxes",
+						Actions: []string{"qux"},
+					},
+					{
+						Name:    "quxes",
+						Actions: []string{"qux"},
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorActionGroupExists)
+			},
+		},
+		{
+			Name: "ActionGroupOK",
+			Input: PolicyDocument{
+				Actions: []Action{
+					{Name: "qux"},
+					{Name: "baz"},
+				},
+				ActionGroups: []ActionGroup{
+					{
+						Name:    "quxes",
+						Actions: []string{"qux", "baz"},
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success.ActionGroups(), 1)
+			},
+		},
+		{
+			Name: "GroupRelationMissing",
+			Input: PolicyDocument{
+				RBAC: &RBAC{
+					RoleResource:        RBACResourceDefinition{"rolev2", "permrv2"},
+					RoleBindingResource: RBACResourceDefinition{"role_binding", "permrbn"},
+					RoleSubjectTypes:    []string{"user"},
+					RoleOwners:          []string{"tenant"},
+					RoleBindingSubjects: []types.TargetType{{Name: "user"}},
+					GroupResource: &RBACGroupDefinition{
+						Name:           "group",
+						OwnerRelation:  "parent",
+						MemberRelation: "member",
+					},
+				},
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+					{
+						Name:     "group",
+						IDPrefix: "idntgrp",
+						Relationships: []Relationship{
+							{
+								Relation:    "parent",
+								TargetTypes: []types.TargetType{{Name: "tenant"}},
+							},
+						},
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				// unknown relation: group has no member relationship
+				require.ErrorIs(t, res.Err, ErrorUnknownRelation)
+			},
+		},
+		{
+			Name: "DelegationUnknownAdminAction",
+			Input: PolicyDocument{
+				RBAC: &RBAC{
+					RoleResource:        RBACResourceDefinition{"rolev2", "permrv2"},
+					RoleBindingResource: RBACResourceDefinition{"role_binding", "permrbn"},
+					RoleSubjectTypes:    []string{"user"},
+					RoleOwners:          []string{"tenant"},
+					RoleBindingSubjects: []types.TargetType{{Name: "user"}},
+					Delegation:          &RBACDelegation{AdminAction: "iam_admin"},
+				},
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorUnknownAction)
+			},
+		},
 		{
 			Name: "RBAC_OK",
 			Input: PolicyDocument{
@@ -471,6 +613,488 @@ func TestPolicy(t *testing.T) {
 				require.NotNil(t, res.Success.RBAC())
 			},
 		},
+		{
+			Name: "RoleBindingSubjectNotRoleSubjectType",
+			Input: PolicyDocument{
+				RBAC: &RBAC{
+					RoleResource:        RBACResourceDefinition{"rolev2", "permrv2"},
+					RoleBindingResource: RBACResourceDefinition{"role_binding", "permrbn"},
+					RoleSubjectTypes:    []string{"user"},
+					RoleOwners:          []string{"tenant"},
+					RoleBindingSubjects: []types.TargetType{{Name: "user"}, {Name: "serviceaccount"}},
+				},
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+					{
+						Name:     "serviceaccount",
+						IDPrefix: "idntsac",
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorInvalidRoleBindingSubject)
+			},
+		},
+		{
+			Name: "RoleBindingSubjectRelationMissing",
+			Input: PolicyDocument{
+				RBAC: &RBAC{
+					RoleResource:        RBACResourceDefinition{"rolev2", "permrv2"},
+					RoleBindingResource: RBACResourceDefinition{"role_binding", "permrbn"},
+					RoleSubjectTypes:    []string{"user"},
+					RoleOwners:          []string{"tenant"},
+					RoleBindingSubjects: []types.TargetType{{Name: "user"}, {Name: "group", SubjectRelation: "member"}},
+				},
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+					{
+						Name:     "group",
+						IDPrefix: "idntgrp",
+						Relationships: []Relationship{
+							{
+								Relation:    "direct_member",
+								TargetTypes: []types.TargetType{{Name: "user"}},
+							},
+						},
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				// unknown relation: group has no member relationship or action
+				require.ErrorIs(t, res.Err, ErrorUnknownRelation)
+			},
+		},
+		{
+			Name: "RoleBindingSubjectDuplicate",
+			Input: PolicyDocument{
+				RBAC: &RBAC{
+					RoleResource:        RBACResourceDefinition{"rolev2", "permrv2"},
+					RoleBindingResource: RBACResourceDefinition{"role_binding", "permrbn"},
+					RoleSubjectTypes:    []string{"user", "group"},
+					RoleOwners:          []string{"tenant"},
+					RoleBindingSubjects: []types.TargetType{{Name: "user"}, {Name: "group"}, {Name: "group", SubjectRelation: "member"}},
+				},
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+					{
+						Name:     "group",
+						IDPrefix: "idntgrp",
+						Relationships: []Relationship{
+							{
+								Relation:    "member",
+								TargetTypes: []types.TargetType{{Name: "user"}},
+							},
+						},
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorInvalidRoleBindingSubject)
+			},
+		},
+		{
+			Name: "RoleSubjectTypesDefault",
+			Input: PolicyDocument{
+				RBAC: &RBAC{
+					RoleResource:        RBACResourceDefinition{"rolev2", "permrv2"},
+					RoleBindingResource: RBACResourceDefinition{"role_binding", "permrbn"},
+					RoleOwners:          []string{"tenant"},
+					RoleBindingSubjects: []types.TargetType{{Name: "user"}, {Name: "serviceaccount"}},
+				},
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+					{
+						Name:     "serviceaccount",
+						IDPrefix: "idntsac",
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, []string{"user", "serviceaccount"}, res.Success.RBAC().RoleSubjectTypes)
+			},
+		},
+		{
+			Name: "RoleTemplateWithoutRBAC",
+			Input: PolicyDocument{
+				RBAC: nil,
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+				},
+				Actions: []Action{
+					{Name: "qux"},
+				},
+				ActionGroups: []ActionGroup{
+					{
+						Name:    "quxes",
+						Actions: []string{"qux"},
+					},
+				},
+				RoleTemplates: []RoleTemplate{
+					{Name: "viewer", Actions: []string{"qux"}},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorUnknownType)
+			},
+		},
+		{
+			Name: "UnknownActionInRoleTemplate",
+			Input: PolicyDocument{
+				RBAC: &RBAC{
+					RoleResource:        RBACResourceDefinition{"rolev2", "permrv2"},
+					RoleBindingResource: RBACResourceDefinition{"role_binding", "permrbn"},
+					RoleSubjectTypes:    []string{"user"},
+					RoleOwners:          []string{"tenant"},
+					RoleBindingSubjects: []types.TargetType{{Name: "user"}},
+				},
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+				},
+				Actions: []Action{
+					{Name: "qux"},
+				},
+				ActionGroups: []ActionGroup{
+					{
+						Name:    "quxes",
+						Actions: []string{"qux"},
+					},
+				},
+				RoleTemplates: []RoleTemplate{
+					{Name: "viewer", Actions: []string{"qux", "baz"}},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorUnknownAction)
+			},
+		},
+		{
+			Name: "RoleTemplateOwnerNotRoleOwner",
+			Input: PolicyDocument{
+				RBAC: &RBAC{
+					RoleResource:        RBACResourceDefinition{"rolev2", "permrv2"},
+					RoleBindingResource: RBACResourceDefinition{"role_binding", "permrbn"},
+					RoleSubjectTypes:    []string{"user"},
+					RoleOwners:          []string{"tenant"},
+					RoleBindingSubjects: []types.TargetType{{Name: "user"}},
+				},
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+				},
+				Actions: []Action{
+					{Name: "qux"},
+				},
+				ActionGroups: []ActionGroup{
+					{
+						Name:    "quxes",
+						Actions: []string{"qux"},
+					},
+				},
+				RoleTemplates: []RoleTemplate{
+					{Name: "viewer", Actions: []string{"qux"}, Owners: []string{"user"}},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorUnknownType)
+			},
+		},
+		{
+			Name: "DuplicateRoleTemplate",
+			Input: PolicyDocument{
+				RBAC: &RBAC{
+					RoleResource:        RBACResourceDefinition{"rolev2", "permrv2"},
+					RoleBindingResource: RBACResourceDefinition{"role_binding", "permrbn"},
+					RoleSubjectTypes:    []string{"user"},
+					RoleOwners:          []string{"tenant"},
+					RoleBindingSubjects: []types.TargetType{{Name: "user"}},
+				},
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+				},
+				Actions: []Action{
+					{Name: "qux"},
+				},
+				ActionGroups: []ActionGroup{
+					{
+						Name:    "quxes",
+						Actions: []string{"qux"},
+					},
+				},
+				RoleTemplates: []RoleTemplate{
+					{Name: "viewer", Actions: []string{"qux"}},
+					{Name: "viewer", Actions: []string{"quxes"}},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorRoleTemplateExists)
+			},
+		},
+		{
+			Name: "RoleTemplateOK",
+			Input: PolicyDocument{
+				RBAC: &RBAC{
+					RoleResource:        RBACResourceDefinition{"rolev2", "permrv2"},
+					RoleBindingResource: RBACResourceDefinition{"role_binding", "permrbn"},
+					RoleSubjectTypes:    []string{"user"},
+					RoleOwners:          []string{"tenant"},
+					RoleBindingSubjects: []types.TargetType{{Name: "user"}},
+				},
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+				},
+				Actions: []Action{
+					{Name: "qux"},
+				},
+				ActionGroups: []ActionGroup{
+					{
+						Name:    "quxes",
+						Actions: []string{"qux"},
+					},
+				},
+				RoleTemplates: []RoleTemplate{
+					{Name: "viewer", Actions: []string{"qux"}},
+					{Name: "admin", Actions: []string{"quxes"}, Owners: []string{"tenant"}},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success.RoleTemplates(), 2)
+			},
+		},
+		{
+			Name: "CaveatWithoutRBAC",
+			Input: PolicyDocument{
+				RBAC: nil,
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+				},
+				Caveats: []Caveat{
+					{
+						Name:       "on_vpn",
+						Parameters: []types.CaveatParameter{{Name: "source_ip", Type: "ipaddress"}, {Name: "cidrs", Type: "list<string>"}},
+						Expression: "cidrs.exists(cidr, source_ip.in_cidr(cidr))",
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorUnknownType)
+			},
+		},
+		{
+			Name: "DuplicateCaveat",
+			Input: PolicyDocument{
+				RBAC: &RBAC{
+					RoleResource:        RBACResourceDefinition{"rolev2", "permrv2"},
+					RoleBindingResource: RBACResourceDefinition{"role_binding", "permrbn"},
+					RoleSubjectTypes:    []string{"user"},
+					RoleOwners:          []string{"tenant"},
+					RoleBindingSubjects: []types.TargetType{{Name: "user"}},
+				},
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+				},
+				Caveats: []Caveat{
+					{
+						Name:       "on_vpn",
+						Parameters: []types.CaveatParameter{{Name: "source_ip", Type: "ipaddress"}, {Name: "cidrs", Type: "list<string>"}},
+						Expression: "cidrs.exists(cidr, source_ip.in_cidr(cidr))",
+					},
+					{
+						Name:       "on_vpn",
+						Parameters: []types.CaveatParameter{{Name: "source_ip", Type: "ipaddress"}, {Name: "cidrs", Type: "list<string>"}},
+						Expression: "cidrs.exists(cidr, source_ip.in_cidr(cidr))",
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorCaveatExists)
+			},
+		},
+		{
+			Name: "CaveatWithoutExpression",
+			Input: PolicyDocument{
+				RBAC: &RBAC{
+					RoleResource:        RBACResourceDefinition{"rolev2", "permrv2"},
+					RoleBindingResource: RBACResourceDefinition{"role_binding", "permrbn"},
+					RoleSubjectTypes:    []string{"user"},
+					RoleOwners:          []string{"tenant"},
+					RoleBindingSubjects: []types.TargetType{{Name: "user"}},
+				},
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+				},
+				Caveats: []Caveat{
+					{
+						Name:       "on_vpn",
+						Parameters: []types.CaveatParameter{{Name: "source_ip", Type: "ipaddress"}},
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorInvalidCaveat)
+			},
+		},
+		{
+			Name: "CaveatUnsupportedParameterType",
+			Input: PolicyDocument{
+				RBAC: &RBAC{
+					RoleResource:        RBACResourceDefinition{"rolev2", "permrv2"},
+					RoleBindingResource: RBACResourceDefinition{"role_binding", "permrbn"},
+					RoleSubjectTypes:    []string{"user"},
+					RoleOwners:          []string{"tenant"},
+					RoleBindingSubjects: []types.TargetType{{Name: "user"}},
+				},
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+				},
+				Caveats: []Caveat{
+					{
+						Name:       "on_vpn",
+						Parameters: []types.CaveatParameter{{Name: "cidrs", Type: "list<cidr>"}},
+						Expression: "cidrs.size() > 0",
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.ErrorIs(t, res.Err, ErrorInvalidCaveat)
+			},
+		},
+		{
+			Name: "Caveats_OK",
+			Input: PolicyDocument{
+				RBAC: &RBAC{
+					RoleResource:        RBACResourceDefinition{"rolev2", "permrv2"},
+					RoleBindingResource: RBACResourceDefinition{"role_binding", "permrbn"},
+					RoleSubjectTypes:    []string{"user"},
+					RoleOwners:          []string{"tenant"},
+					RoleBindingSubjects: []types.TargetType{{Name: "user"}},
+				},
+				ResourceTypes: []ResourceType{
+					{
+						Name:     "tenant",
+						IDPrefix: "tnntten",
+					},
+					{
+						Name:     "user",
+						IDPrefix: "idntusr",
+					},
+				},
+				Caveats: []Caveat{
+					{
+						Name:       "on_vpn",
+						Parameters: []types.CaveatParameter{{Name: "source_ip", Type: "ipaddress"}, {Name: "cidrs", Type: "list<string>"}},
+						Expression: "cidrs.exists(cidr, source_ip.in_cidr(cidr))",
+					},
+				},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[Policy]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success.Caveats(), 1)
+
+				for _, rt := range res.Success.Schema() {
+					if rt.Name != "role_binding" {
+						continue
+					}
+
+					for _, rel := range rt.Relationships {
+						if rel.Relation == RolebindingRoleRelation {
+							assert.Contains(t, rel.Types, types.TargetType{Name: "rolev2", Caveat: "on_vpn"})
+						}
+					}
+				}
+			},
+		},
 	}
 
 	testFn := func(_ context.Context, doc PolicyDocument) testingx.TestResult[Policy] {
@@ -1,6 +1,8 @@
 package iapl
 
 import (
+	"slices"
+
 	"go.infratographer.com/permissions-api/internal/types"
 )
 
@@ -58,6 +60,32 @@ const (
 	RoleBindingActionList RoleBindingAction = "iam_rolebinding_list"
 )
 
+// GroupAction is the list of actions that can be performed on a group resource
+type GroupAction string
+
+const (
+	// GroupActionCreate is the action name to create a group
+	GroupActionCreate GroupAction = "iam_group_create"
+	// GroupActionGet is the action name to get a group and its members
+	GroupActionGet GroupAction = "iam_group_get"
+	// GroupActionList is the action name to list groups
+	GroupActionList GroupAction = "iam_group_list"
+	// GroupActionUpdate is the action name to update a group and its members
+	GroupActionUpdate GroupAction = "iam_group_update"
+	// GroupActionDelete is the action name to delete a group
+	GroupActionDelete GroupAction = "iam_group_delete"
+)
+
+// TenantSettingsAction is the list of actions that can be performed on the settings of a tenant
+type TenantSettingsAction string
+
+const (
+	// TenantSettingsActionGet is the action name to get the settings of a tenant
+	TenantSettingsActionGet TenantSettingsAction = "iam_tenantsettings_get"
+	// TenantSettingsActionUpdate is the action name to update the settings of a tenant
+	TenantSettingsActionUpdate TenantSettingsAction = "iam_tenantsettings_update"
+)
+
 // ResourceRoleBindingV2 describes the relationships that will be created
 // for a resource to support role-binding V2
 type ResourceRoleBindingV2 struct {
@@ -126,7 +154,9 @@ type RBAC struct {
 	// RoleBindingResource is the name of the resource type that represents a role binding.
 	RoleBindingResource RBACResourceDefinition
 	// RoleSubjectTypes is a list of subject types that the relationships in a
-	// role resource will contain, see the example above.
+	// role resource will contain, see the example above. Roles only grant
+	// their actions to these types, so every role-binding subject without a
+	// subject relation must be listed. Defaults to those role-binding subjects.
 	RoleSubjectTypes []string
 	// RoleOwners is the list of resource types that can own a role.
 	// These resources should be (but not limited to) organizational resources
@@ -140,6 +170,13 @@ type RBAC struct {
 	// RoleBindingSubjects is the names of the resource types that can be subjects in a role binding.
 	// e.g. rolebinding_create, rolebinding_list, rolebinding_delete
 	RoleBindingSubjects []types.TargetType
+	// GroupResource is the resource type managed as a group through the
+	// groups API, groups are not managed by permissions-api if unset.
+	GroupResource *RBACGroupDefinition
+	// Delegation restricts role administration delegated to subjects, role
+	// administration is unrestricted for anyone with the role and
+	// role-binding actions on a resource if unset.
+	Delegation *RBACDelegation
 
 	roleownersset map[string]struct{}
 }
@@ -151,6 +188,31 @@ type RBACResourceDefinition struct {
 	IDPrefix string
 }
 
+// RBACGroupDefinition defines the resource type that represents a group and
+// the relations used to manage it.
+type RBACGroupDefinition struct {
+	// Name is the name of the resource type that represents a group.
+	Name string
+	// OwnerRelation is the relation connecting a group to the resource owning it.
+	OwnerRelation string
+	// MemberRelation is the relation connecting a group to its members.
+	MemberRelation string
+	// SubgroupRelation is the relation connecting a group to its member groups,
+	// member groups use MemberRelation if unset.
+	SubgroupRelation string
+}
+
+// RBACDelegation restricts delegated role administration. Subjects allowed
+// to manage roles and role-bindings on a resource, but without AdminAction,
+// can only create roles with and bind roles of actions they hold themselves
+// on the resource, so role administration delegated for a sub-tree cannot
+// grant more than the delegate has.
+type RBACDelegation struct {
+	// AdminAction is the action granting unrestricted role administration on
+	// a resource and the resources below it, e.g. to tenant admins.
+	AdminAction string
+}
+
 // CreateRoleBindingConditionsForAction creates the conditions that is used for role binding v2,
 // for a given action name. e.g. for a doc_read action, it will create the following conditions:
 // doc_read = grant->doc_read + from[0]->doc_read + ... from[n]->doc_read
@@ -225,6 +287,20 @@ func (r *RBAC) RoleBindingActions() []Action {
 	return actions
 }
 
+// DirectRoleBindingSubjectTypes returns the names of the role-binding subject
+// types bound directly, rather than through a subject relation like group#member.
+func (r *RBAC) DirectRoleBindingSubjectTypes() []string {
+	var out []string
+
+	for _, subject := range r.RoleBindingSubjects {
+		if subject.SubjectRelation == "" && !slices.Contains(out, subject.Name) {
+			out = append(out, subject.Name)
+		}
+	}
+
+	return out
+}
+
 // RoleOwnersSet returns the set of role owners for easy role owner lookups
 func (r *RBAC) RoleOwnersSet() map[string]struct{} {
 	if r.roleownersset == nil {
@@ -0,0 +1,254 @@
+package policysource
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+)
+
+const (
+	// DefaultTimeout is the default limit to fetch a policy document and its
+	// signature.
+	DefaultTimeout = 30 * time.Second
+
+	// maxDocumentSize limits the size of fetched documents and signatures.
+	maxDocumentSize = 10 << 20
+)
+
+// Config configures a remote policy source.
+type Config struct {
+	// URL is the HTTP(S) URL of the policy document, such as an object storage
+	// URL. Like policy files, the document may contain several YAML documents.
+	// Plain http URLs require PublicKeyFile.
+	URL string
+
+	// SignatureURL is the URL of the detached ed25519 signature of the
+	// document, raw or base64 encoded. Defaults to URL with a .sig suffix.
+	SignatureURL string
+
+	// PublicKeyFile is the PEM encoded ed25519 public key signatures are
+	// verified with. Documents are not verified when unset. Signatures only
+	// cover the document, so an older validly signed document may be served
+	// again in place of the current one.
+	PublicKeyFile string
+
+	// Timeout limits fetching the document and its signature, DefaultTimeout
+	// when unset.
+	Timeout time.Duration
+}
+
+// Option configures an HTTP source.
+type Option func(*HTTP)
+
+// WithHTTPClient sets the client documents are fetched with.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *HTTP) {
+		s.client = client
+	}
+}
+
+// HTTP loads the policy document from an HTTP(S) URL. The ETag of the last
+// loaded document is sent with every request, unchanged documents are not
+// downloaded again.
+type HTTP struct {
+	url          string
+	signatureURL string
+	timeout      time.Duration
+	client       *http.Client
+	key          ed25519.PublicKey
+
+	mu   sync.Mutex
+	etag string
+}
+
+// NewHTTP creates a new HTTP source from the config.
+func NewHTTP(cfg Config, opts ...Option) (*HTTP, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: policy url must be an http or https url", ErrInvalidConfig)
+	}
+
+	// documents fetched over plain http could be replaced by anyone on the
+	// network path, so they are only accepted when their signature is verified.
+	if u.Scheme == "http" && cfg.PublicKeyFile == "" {
+		return nil, fmt.Errorf("%w: a public key is required to load the policy from an http url", ErrInvalidConfig)
+	}
+
+	s := &HTTP{
+		url:          cfg.URL,
+		signatureURL: cfg.SignatureURL,
+		timeout:      cfg.Timeout,
+		client:       http.DefaultClient,
+	}
+
+	if s.signatureURL == "" {
+		sig := *u
+		sig.Path += ".sig"
+		sig.RawPath = ""
+
+		s.signatureURL = sig.String()
+	}
+
+	if s.timeout <= 0 {
+		s.timeout = DefaultTimeout
+	}
+
+	if cfg.PublicKeyFile != "" {
+		if s.key, err = loadPublicKey(cfg.PublicKeyFile); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+// String returns the URL of the document without its query, which may hold
+// credentials of presigned URLs.
+func (s *HTTP) String() string {
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return "invalid url"
+	}
+
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u.String()
+}
+
+// Load fetches the policy document, verifying its signature when a public key
+// is configured. Unless force is set, ErrNotModified is returned when the
+// server reports the document has not changed since it was last loaded.
+func (s *HTTP) Load(ctx context.Context, force bool) (iapl.PolicyDocument, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	etag := s.etag
+	if force {
+		etag = ""
+	}
+
+	body, newETag, err := s.fetch(ctx, s.url, etag)
+	if err != nil {
+		return iapl.PolicyDocument{}, err
+	}
+
+	if s.key != nil {
+		sig, _, err := s.fetch(ctx, s.signatureURL, "")
+		if err != nil {
+			return iapl.PolicyDocument{}, fmt.Errorf("signature: %w", err)
+		}
+
+		if !ed25519.Verify(s.key, body, decodeSignature(sig)) {
+			return iapl.PolicyDocument{}, fmt.Errorf("%w: %s", ErrInvalidSignature, s)
+		}
+	}
+
+	document, err := iapl.LoadPolicyDocument(s.String(), bytes.NewReader(body))
+	if err != nil {
+		return iapl.PolicyDocument{}, err
+	}
+
+	// the etag is only kept once the document loaded, so a document which
+	// failed is fetched again.
+	s.etag = newETag
+
+	return document, nil
+}
+
+func (s *HTTP) fetch(ctx context.Context, target, etag string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrFetchFailed, err)
+	}
+
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrFetchFailed, err)
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, "", ErrNotModified
+	default:
+		return nil, "", fmt.Errorf("%w: %s: %s", ErrFetchFailed, s, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrFetchFailed, err)
+	}
+
+	if len(body) > maxDocumentSize {
+		return nil, "", fmt.Errorf("%w: document larger than %d bytes", ErrFetchFailed, maxDocumentSize)
+	}
+
+	return body, resp.Header.Get("ETag"), nil
+}
+
+// decodeSignature returns the raw signature, signatures may be stored raw or
+// base64 encoded.
+func decodeSignature(sig []byte) []byte {
+	if len(sig) == ed25519.SignatureSize {
+		return sig
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return nil
+	}
+
+	return decoded
+}
+
+func loadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: public key: %w", ErrInvalidConfig, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: public key %s is not PEM encoded", ErrInvalidConfig, path)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: public key: %w", ErrInvalidConfig, err)
+	}
+
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: public key %s is not an ed25519 key", ErrInvalidConfig, path)
+	}
+
+	return edKey, nil
+}
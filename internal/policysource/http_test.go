@@ -0,0 +1,174 @@
+package policysource
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `resourcetypes:
+  - name: tenant
+    idprefix: tnntten
+---
+actions:
+  - name: tenant_get
+`
+
+func TestHTTPLoad(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		document = []byte(testPolicy)
+		etag     = `"v1"`
+		fetches  atomic.Int32
+	)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write(document)
+	}))
+	defer srv.Close()
+
+	source, err := NewHTTP(Config{URL: srv.URL + "/policy.yaml?token=secret"}, WithHTTPClient(srv.Client()))
+	require.NoError(t, err)
+
+	assert.Equal(t, srv.URL+"/policy.yaml", source.String())
+
+	doc, err := source.Load(ctx, false)
+	require.NoError(t, err)
+
+	require.Len(t, doc.ResourceTypes, 1)
+	assert.Equal(t, "tenant", doc.ResourceTypes[0].Name)
+	require.Len(t, doc.Actions, 1)
+
+	// unchanged documents are not loaded again
+	_, err = source.Load(ctx, false)
+	assert.ErrorIs(t, err, ErrNotModified)
+
+	// forced loads ignore the etag
+	_, err = source.Load(ctx, true)
+	require.NoError(t, err)
+
+	etag = `"v2"`
+
+	_, err = source.Load(ctx, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(4), fetches.Load())
+}
+
+func TestHTTPLoadErrors(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing.yaml":
+			http.NotFound(w, r)
+		case "/invalid.yaml":
+			w.Header().Set("ETag", `"invalid"`)
+			_, _ = w.Write([]byte("resourcetypes: {"))
+		}
+	}))
+	defer srv.Close()
+
+	_, err := NewHTTP(Config{URL: "file:///etc/policy.yaml"})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	// unsigned documents must not be loaded over plain http
+	_, err = NewHTTP(Config{URL: "http://policies.example.com/policy.yaml"})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	source, err := NewHTTP(Config{URL: srv.URL + "/missing.yaml"}, WithHTTPClient(srv.Client()))
+	require.NoError(t, err)
+
+	_, err = source.Load(ctx, false)
+	assert.ErrorIs(t, err, ErrFetchFailed)
+
+	source, err = NewHTTP(Config{URL: srv.URL + "/invalid.yaml"}, WithHTTPClient(srv.Client()))
+	require.NoError(t, err)
+
+	_, err = source.Load(ctx, false)
+	require.Error(t, err)
+
+	// the etag of a document which failed to load is not kept
+	assert.Empty(t, source.etag)
+}
+
+func TestHTTPLoadSignature(t *testing.T) {
+	ctx := context.Background()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "policy.pub")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	document := []byte(testPolicy)
+	signature := ed25519.Sign(priv, document)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/policy.yaml":
+			_, _ = w.Write(document)
+		case "/tampered.yaml":
+			_, _ = w.Write([]byte(testPolicy + "  - name: tenant_delete\n"))
+		case "/policy.yaml.sig", "/tampered.yaml.sig":
+			_, _ = w.Write(signature)
+		case "/policy.b64":
+			_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(signature) + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	source, err := NewHTTP(Config{URL: srv.URL + "/policy.yaml", PublicKeyFile: keyFile})
+	require.NoError(t, err)
+
+	_, err = source.Load(ctx, false)
+	require.NoError(t, err)
+
+	source, err = NewHTTP(Config{URL: srv.URL + "/policy.yaml", SignatureURL: srv.URL + "/policy.b64", PublicKeyFile: keyFile})
+	require.NoError(t, err)
+
+	_, err = source.Load(ctx, false)
+	require.NoError(t, err)
+
+	source, err = NewHTTP(Config{URL: srv.URL + "/tampered.yaml", PublicKeyFile: keyFile})
+	require.NoError(t, err)
+
+	_, err = source.Load(ctx, false)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	source, err = NewHTTP(Config{URL: srv.URL + "/policy.yaml", SignatureURL: srv.URL + "/missing.sig", PublicKeyFile: keyFile})
+	require.NoError(t, err)
+
+	_, err = source.Load(ctx, false)
+	assert.ErrorIs(t, err, ErrFetchFailed)
+
+	_, err = NewHTTP(Config{URL: srv.URL + "/policy.yaml", PublicKeyFile: filepath.Join(t.TempDir(), "missing.pub")})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
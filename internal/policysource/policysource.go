@@ -0,0 +1,53 @@
+// Package policysource loads policy documents from a local directory or a
+// remote HTTP(S) URL, so the server can reload its policy when it changes.
+package policysource
+
+import (
+	"context"
+	"errors"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+)
+
+var (
+	// ErrNotModified is returned when the policy document has not changed
+	// since it was last loaded.
+	ErrNotModified = errors.New("policy not modified")
+
+	// ErrInvalidSignature is returned when the signature of a policy document
+	// does not verify.
+	ErrInvalidSignature = errors.New("invalid policy signature")
+
+	// ErrFetchFailed is returned when a policy document could not be fetched.
+	ErrFetchFailed = errors.New("failed to fetch policy")
+
+	// ErrInvalidConfig is returned when a policy source is misconfigured.
+	ErrInvalidConfig = errors.New("invalid policy source config")
+)
+
+// Source loads a policy document.
+type Source interface {
+	// Load returns the policy document. Unless force is set, ErrNotModified
+	// may be returned when the document has not changed since it was last
+	// loaded.
+	Load(ctx context.Context, force bool) (iapl.PolicyDocument, error)
+
+	// String describes the source in logs.
+	String() string
+}
+
+// Directory returns a source loading the policy from the YAML files in dir.
+// Directories are always loaded in full.
+func Directory(dir string) Source {
+	return directory(dir)
+}
+
+type directory string
+
+func (d directory) Load(_ context.Context, _ bool) (iapl.PolicyDocument, error) {
+	return iapl.LoadPolicyDocumentFromDirectory(string(d))
+}
+
+func (d directory) String() string {
+	return string(d)
+}
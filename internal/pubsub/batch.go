@@ -0,0 +1,212 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+
+	"go.infratographer.com/x/events"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// maxBatchRelationships is the maximum number of relationships written in a
+// single batch, matching the update limit of a SpiceDB WriteRelationships call.
+const maxBatchRelationships = 1000
+
+// DefaultBatchWindow is the default time write requests are accumulated for
+// when batching is enabled.
+const DefaultBatchWindow = 100 * time.Millisecond
+
+// BatchConfig configures accumulating write requests to write their
+// relationships to SpiceDB in a single call. Batching is disabled unless Size
+// is greater than one and Window is set.
+type BatchConfig struct {
+	// Size is the maximum number of requests written in a single batch.
+	Size int
+	// Window is the maximum time a request waits for the batch to fill.
+	Window time.Duration
+}
+
+func (c BatchConfig) enabled() bool {
+	return c.Size > 1 && c.Window > 0
+}
+
+// WithBatching accumulates write requests and writes their relationships in a single call
+func WithBatching(c BatchConfig) SubscriberOption {
+	return func(s *Subscriber) {
+		s.batch = c
+	}
+}
+
+// batchedRequest is a validated write request waiting to be written.
+type batchedRequest struct {
+	ctx           context.Context
+	logger        *zap.SugaredLogger
+	msg           events.Request[events.AuthRelationshipRequest, events.AuthRelationshipResponse]
+	relationships []types.Relationship
+}
+
+// listenBatched listens for messages on a channel, accumulating write requests
+// until the batch is full or the batch window has passed. Any other message
+// flushes the pending batch first, so requests are applied in the order they
+// were received.
+func (s *Subscriber) listenBatched(messages <-chan events.Request[events.AuthRelationshipRequest, events.AuthRelationshipResponse]) {
+	var (
+		batch         []batchedRequest
+		relationships int
+	)
+
+	timer := time.NewTimer(s.batch.Window)
+	timer.Stop()
+
+	flush := func() {
+		timer.Stop()
+
+		s.writeBatch(batch)
+
+		batch = nil
+		relationships = 0
+	}
+
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				flush()
+
+				return
+			}
+
+			if msg.Error() != nil || msg.Message().Action != events.WriteAuthRelationshipAction {
+				flush()
+
+				s.handleMessage(msg)
+
+				continue
+			}
+
+			req, ok := s.prepareBatchedRequest(msg)
+			if !ok {
+				continue
+			}
+
+			if relationships+len(req.relationships) > maxBatchRelationships {
+				flush()
+			}
+
+			if len(batch) == 0 {
+				timer.Reset(s.batch.Window)
+			}
+
+			batch = append(batch, req)
+			relationships += len(req.relationships)
+
+			if len(batch) >= s.batch.Size {
+				flush()
+			}
+		case <-timer.C:
+			s.writeBatch(batch)
+
+			batch = nil
+			relationships = 0
+		}
+	}
+}
+
+// prepareBatchedRequest validates a write request to be added to the batch.
+// Invalid requests are responded to immediately and false is returned.
+func (s *Subscriber) prepareBatchedRequest(msg events.Request[events.AuthRelationshipRequest, events.AuthRelationshipResponse]) (batchedRequest, bool) {
+	elogger := s.logger.With(
+		"event.message.topic", msg.Topic(),
+		"event.message.action", msg.Message().Action,
+		"event.message.object.id", msg.Message().ObjectID.String(),
+		"event.message.relations", len(msg.Message().Relations),
+	)
+
+	request := msg.Message()
+
+	ctx := request.GetTraceContext(context.Background())
+
+	ctx, span := tracer.Start(ctx, "pubsub.receive", consumerSpanOptions(msg.Topic(), request.ObjectID)...)
+
+	defer span.End()
+
+	elogger.Debugw("received message")
+
+	relationships, errors := s.createEventRelationships(elogger, msg)
+	if len(errors) != 0 {
+		s.acknowledge(msg, s.respondRequest(ctx, elogger, msg, errors...))
+
+		return batchedRequest{}, false
+	}
+
+	return batchedRequest{
+		ctx:           ctx,
+		logger:        elogger,
+		msg:           msg,
+		relationships: relationships,
+	}, true
+}
+
+// writeBatch writes the relationships of all requests in the batch in a single
+// call and responds to each request. If the batch fails to be written, each
+// request is written on its own so failures are reported for, and
+// dead-lettered with, the requests which caused them.
+func (s *Subscriber) writeBatch(batch []batchedRequest) {
+	if len(batch) == 0 {
+		return
+	}
+
+	var (
+		relationships []types.Relationship
+		links         = make([]trace.Link, len(batch))
+	)
+
+	for i, req := range batch {
+		relationships = append(relationships, req.relationships...)
+		links[i] = trace.LinkFromContext(req.ctx)
+	}
+
+	// a batch of a single request continues the trace of the request, larger
+	// batches link to the traces of all their requests
+	parent := context.Background()
+	if len(batch) == 1 {
+		parent = batch[0].ctx
+	}
+
+	ctx, span := tracer.Start(parent, "pubsub.writeBatch",
+		trace.WithLinks(links...),
+		trace.WithAttributes(
+			attribute.Int("pubsub.batch.requests", len(batch)),
+			attribute.Int("pubsub.batch.relationships", len(relationships)),
+		),
+	)
+
+	defer span.End()
+
+	err := s.createRelationships(ctx, relationships)
+	if err != nil && len(batch) > 1 {
+		span.RecordError(err)
+
+		s.logger.Warnw("error writing batch, writing requests individually",
+			"batch.requests", len(batch),
+			"batch.relationships", len(relationships),
+			"error", err,
+		)
+
+		for _, req := range batch {
+			err := s.createRelationships(req.ctx, req.relationships)
+
+			s.acknowledge(req.msg, s.respondRequest(req.ctx, req.logger, req.msg, err))
+		}
+
+		return
+	}
+
+	for _, req := range batch {
+		s.acknowledge(req.msg, s.respondRequest(req.ctx, req.logger, req.msg, err))
+	}
+}
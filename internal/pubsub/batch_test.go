@@ -0,0 +1,106 @@
+package pubsub
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/query/mock"
+)
+
+func publishBatch(t *testing.T, pub events.AuthRelationshipPublisher, subject string, n int) []events.Message[events.AuthRelationshipResponse] {
+	t.Helper()
+
+	var (
+		wg        sync.WaitGroup
+		responses = make([]events.Message[events.AuthRelationshipResponse], n)
+		errs      = make([]error, n)
+	)
+
+	for i := 0; i < n; i++ {
+		request := events.AuthRelationshipRequest{
+			Action:   events.WriteAuthRelationshipAction,
+			ObjectID: gidx.MustNewID("loadbal"),
+			Relations: []events.AuthRelationshipRelation{
+				{
+					Relation:  "owner",
+					SubjectID: gidx.PrefixedID("tnntten-gd6RExwAz353UqHLzjC1n"),
+				},
+			},
+		}
+
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			responses[i], errs[i] = pub.PublishAuthRelationshipRequest(context.Background(), subject, request)
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	return responses
+}
+
+func TestBatching(t *testing.T) {
+	var engine mock.Engine
+	engine.On("CreateRelationships").Return(nil).Once()
+
+	_, pub, sub := setupEvents(t, &engine,
+		WithBatching(BatchConfig{Size: 3, Window: time.Second}),
+	)
+
+	require.NoError(t, sub.Subscribe("*.batch.loadbalancer"))
+
+	go func() {
+		_ = sub.Listen()
+	}()
+
+	// Allow time for the listener to to start
+	time.Sleep(time.Second)
+
+	for _, resp := range publishBatch(t, pub, "create.batch.loadbalancer", 3) {
+		require.Empty(t, resp.Message().Errors)
+	}
+
+	// all three requests are written in a single call
+	engine.AssertExpectations(t)
+}
+
+func TestBatchingFallback(t *testing.T) {
+	var engine mock.Engine
+	engine.On("CreateRelationships").Return(io.ErrUnexpectedEOF).Once()
+	engine.On("CreateRelationships").Return(nil).Twice()
+
+	_, pub, sub := setupEvents(t, &engine,
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithBatching(BatchConfig{Size: 2, Window: time.Second}),
+	)
+
+	require.NoError(t, sub.Subscribe("*.batchfallback.loadbalancer"))
+
+	go func() {
+		_ = sub.Listen()
+	}()
+
+	// Allow time for the listener to to start
+	time.Sleep(time.Second)
+
+	for _, resp := range publishBatch(t, pub, "create.batchfallback.loadbalancer", 2) {
+		require.Empty(t, resp.Message().Errors)
+	}
+
+	// the failed batch is written again per request
+	engine.AssertExpectations(t)
+}
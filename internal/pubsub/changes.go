@@ -0,0 +1,107 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// RelationshipChangeEventPrefix prefixes the operation of a relationship
+	// change to form the event type, e.g. relationship_created.
+	RelationshipChangeEventPrefix = "relationship_"
+
+	// changeWatchRetryInterval is the time waited before watching SpiceDB again after the watch failed.
+	changeWatchRetryInterval = 5 * time.Second
+)
+
+// ChangePublisher republishes the relationship changes made in SpiceDB as
+// events, so other services can keep their indexes and caches in sync with
+// authorization changes.
+type ChangePublisher struct {
+	logger    *zap.SugaredLogger
+	engine    query.Engine
+	publisher events.Publisher
+	topic     string
+
+	// after is the revision up to which all changes have been published, the
+	// watch resumes from it. revision is the revision of the last published change,
+	// which may have further changes yet to be published.
+	after    string
+	revision string
+}
+
+// NewChangePublisher returns a publisher of the relationship changes watched
+// by the engine to the topic.
+func NewChangePublisher(engine query.Engine, publisher events.Publisher, topic string, logger *zap.SugaredLogger) *ChangePublisher {
+	return &ChangePublisher{
+		logger:    logger.With("topic", topic),
+		engine:    engine,
+		publisher: publisher,
+		topic:     topic,
+	}
+}
+
+// Run publishes relationship changes until the context is done, watching
+// again from the last published change after a failure. Changes made while
+// the publisher is not running are not published.
+func (p *ChangePublisher) Run(ctx context.Context) {
+	for {
+		err := p.engine.WatchRelationshipChanges(ctx, p.after, func(change types.RelationshipChange) error {
+			return p.publish(ctx, change)
+		})
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		p.logger.Warnw("watching spicedb relationship changes failed", "after", p.after, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(changeWatchRetryInterval):
+		}
+	}
+}
+
+// publish publishes a single change. A change which fails to publish fails
+// the watch, which resumes from the previous revision, so the changes of a
+// revision may be published more than once.
+func (p *ChangePublisher) publish(ctx context.Context, change types.RelationshipChange) error {
+	rel := change.Relationship
+
+	msg := events.EventMessage{
+		SubjectID:            gidx.PrefixedID(rel.ResourceID),
+		AdditionalSubjectIDs: []gidx.PrefixedID{gidx.PrefixedID(rel.SubjectID)},
+		EventType:            RelationshipChangeEventPrefix + string(change.Operation),
+		Timestamp:            time.Now().UTC(),
+		Data: map[string]any{
+			"operation":        string(change.Operation),
+			"resource_type":    rel.ResourceType,
+			"resource_id":      rel.ResourceID,
+			"relation":         rel.Relation,
+			"subject_type":     rel.SubjectType,
+			"subject_id":       rel.SubjectID,
+			"subject_relation": rel.SubjectRelation,
+			"zedtoken":         change.ZedToken,
+		},
+	}
+
+	if _, err := p.publisher.PublishEvent(ctx, p.topic, msg); err != nil {
+		return err
+	}
+
+	if change.ZedToken != p.revision {
+		p.after = p.revision
+		p.revision = change.ZedToken
+	}
+
+	return nil
+}
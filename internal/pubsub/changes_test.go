@@ -0,0 +1,59 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestChangePublisher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rel := types.RelationshipRecord{
+		ResourceType: "loadbalancer",
+		ResourceID:   "loadbal-UCN7pxJO57BV_5pNiV95B",
+		Relation:     "owner",
+		SubjectType:  "tenant",
+		SubjectID:    "tnntten-gd6RExwAz353UqHLzjC1n",
+	}
+
+	changes := []types.RelationshipChange{
+		{Operation: types.RelationshipChangeCreated, Relationship: rel, ZedToken: "first"},
+		{Operation: types.RelationshipChangeDeleted, Relationship: rel, ZedToken: "second"},
+	}
+
+	var engine mock.Engine
+	engine.On("WatchRelationshipChanges", "").Return(changes, context.Canceled).Run(func(testifymock.Arguments) {
+		cancel()
+	})
+
+	publisher := &testDeadLetterPublisher{}
+
+	p := NewChangePublisher(&engine, publisher, "permissions-changes", zap.NewNop().Sugar())
+	p.Run(ctx)
+
+	engine.AssertExpectations(t)
+
+	require.Len(t, publisher.events, 2)
+
+	created := publisher.events[0]
+	assert.Equal(t, "relationship_created", created.EventType)
+	assert.Equal(t, gidx.PrefixedID(rel.ResourceID), created.SubjectID)
+	assert.Equal(t, []gidx.PrefixedID{gidx.PrefixedID(rel.SubjectID)}, created.AdditionalSubjectIDs)
+	assert.Equal(t, "owner", created.Data["relation"])
+	assert.Equal(t, "first", created.Data["zedtoken"])
+
+	assert.Equal(t, "relationship_deleted", publisher.events[1].EventType)
+
+	// the watch resumes from the last revision all changes were published of
+	assert.Equal(t, "first", p.after)
+}
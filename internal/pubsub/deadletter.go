@@ -0,0 +1,120 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.infratographer.com/x/events"
+	"go.uber.org/zap"
+)
+
+const (
+	// DeadLetterEventType is the event type of dead-lettered messages.
+	DeadLetterEventType = "dead_letter"
+	// DefaultDeadLetterTopic is the default topic failed messages are published to.
+	DefaultDeadLetterTopic = "permissions-dlq"
+)
+
+// DeadLetter is a relationship request which could not be processed.
+type DeadLetter struct {
+	// Subject is the subject the request was received on.
+	Subject string `json:"subject"`
+	// Topic is the topic the request was published to, e.g. loadbalancer.
+	Topic string `json:"topic"`
+	// Request is the failed request.
+	Request events.AuthRelationshipRequest `json:"request"`
+	// Errors are the errors processing the request.
+	Errors []string `json:"errors"`
+	// FailedAt is the time processing the request failed.
+	FailedAt time.Time `json:"failedAt"`
+}
+
+// DeadLetterFromEvent decodes a dead-letter from the event it was published as.
+func DeadLetterFromEvent(msg events.EventMessage) (DeadLetter, error) {
+	var dl DeadLetter
+
+	data, err := json.Marshal(msg.Data)
+	if err != nil {
+		return DeadLetter{}, err
+	}
+
+	if err := json.Unmarshal(data, &dl); err != nil {
+		return DeadLetter{}, fmt.Errorf("error decoding dead-letter: %w", err)
+	}
+
+	return dl, nil
+}
+
+// requestTopic returns the topic a relationship request was published to
+// from the subject it was received on.
+func requestTopic(subject string, action events.AuthRelationshipAction) string {
+	prefix := "auth.relationships." + string(action) + "."
+
+	if _, topic, ok := strings.Cut(subject, prefix); ok {
+		return topic
+	}
+
+	return subject
+}
+
+// deadLetterPublisher publishes failed requests to the dead-letter topic.
+type deadLetterPublisher struct {
+	publisher events.Publisher
+	topic     string
+}
+
+// publishDeadLetter publishes the failed request with its errors to the
+// dead-letter topic, if one is configured.
+func (s *Subscriber) publishDeadLetter(
+	ctx context.Context,
+	logger *zap.SugaredLogger,
+	msg events.Request[events.AuthRelationshipRequest, events.AuthRelationshipResponse],
+	errs []error,
+) {
+	if s.deadLetter == nil {
+		return
+	}
+
+	dl := DeadLetter{
+		Subject:  msg.Topic(),
+		Topic:    requestTopic(msg.Topic(), msg.Message().Action),
+		Request:  msg.Message(),
+		Errors:   make([]string, len(errs)),
+		FailedAt: time.Now().UTC(),
+	}
+
+	for i, err := range errs {
+		dl.Errors[i] = err.Error()
+	}
+
+	var data map[string]any
+
+	raw, err := json.Marshal(dl)
+	if err == nil {
+		err = json.Unmarshal(raw, &data)
+	}
+
+	if err != nil {
+		logger.Errorw("error encoding dead-letter", "error", err)
+
+		return
+	}
+
+	event := events.EventMessage{
+		SubjectID: dl.Request.ObjectID,
+		EventType: DeadLetterEventType,
+		Timestamp: dl.FailedAt,
+		Data:      data,
+	}
+
+	if _, err := s.deadLetter.publisher.PublishEvent(ctx, s.deadLetter.topic, event); err != nil {
+		logger.Errorw("error publishing dead-letter", "error", err)
+
+		return
+	}
+
+	logger.Warnw("request published to dead-letter topic", "dead_letter.topic", s.deadLetter.topic)
+}
@@ -0,0 +1,104 @@
+package pubsub
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/query/mock"
+)
+
+// testDeadLetterPublisher records published dead-letters.
+type testDeadLetterPublisher struct {
+	mu     sync.Mutex
+	events []events.EventMessage
+}
+
+func (p *testDeadLetterPublisher) PublishChange(context.Context, string, events.ChangeMessage) (events.Message[events.ChangeMessage], error) {
+	return nil, nil
+}
+
+func (p *testDeadLetterPublisher) PublishEvent(_ context.Context, _ string, msg events.EventMessage) (events.Message[events.EventMessage], error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, msg)
+
+	return nil, nil
+}
+
+func TestDeadLetter(t *testing.T) {
+	ctx := context.Background()
+
+	request := events.AuthRelationshipRequest{
+		Action:   events.WriteAuthRelationshipAction,
+		ObjectID: gidx.PrefixedID("loadbal-UCN7pxJO57BV_5pNiV95B"),
+		Relations: []events.AuthRelationshipRelation{
+			{
+				Relation:  "owner",
+				SubjectID: gidx.PrefixedID("tnntten-gd6RExwAz353UqHLzjC1n"),
+			},
+		},
+	}
+
+	var engine mock.Engine
+	engine.On("CreateRelationships").Return(io.ErrUnexpectedEOF).Times(testRetryPolicy.MaxAttempts)
+
+	deadLetters := &testDeadLetterPublisher{}
+
+	_, pub, sub := setupEvents(t, &engine,
+		WithRetryPolicy(testRetryPolicy),
+		WithDeadLetter(deadLetters, DefaultDeadLetterTopic),
+	)
+
+	require.NoError(t, sub.Subscribe("*.deadletter.loadbalancer"))
+
+	go func() {
+		_ = sub.Listen()
+	}()
+
+	// Allow time for the listener to to start
+	time.Sleep(time.Second)
+
+	resp, err := pub.PublishAuthRelationshipRequest(ctx, "deadletter.loadbalancer", request)
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Message().Errors)
+
+	engine.AssertExpectations(t)
+
+	deadLetters.mu.Lock()
+	defer deadLetters.mu.Unlock()
+
+	require.Len(t, deadLetters.events, 1)
+
+	event := deadLetters.events[0]
+	assert.Equal(t, DeadLetterEventType, event.EventType)
+	assert.Equal(t, request.ObjectID, event.SubjectID)
+
+	dl, err := DeadLetterFromEvent(event)
+	require.NoError(t, err)
+
+	assert.Equal(t, "deadletter.loadbalancer", dl.Topic)
+	assert.Equal(t, request.ObjectID, dl.Request.ObjectID)
+	assert.Equal(t, request.Relations, dl.Request.Relations)
+	require.Len(t, dl.Errors, 1)
+	assert.Contains(t, dl.Errors[0], io.ErrUnexpectedEOF.Error())
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	assert.Equal(t, time.Second, policy.backoff(1))
+	assert.Equal(t, 2*time.Second, policy.backoff(2))
+	assert.Equal(t, 4*time.Second, policy.backoff(3))
+	assert.Equal(t, 5*time.Second, policy.backoff(4))
+}
@@ -0,0 +1,114 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.infratographer.com/x/events"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ErrDeletionsNotConfigured is returned when subscribing to resource deletions
+// without a change subscriber.
+var ErrDeletionsNotConfigured = errors.New("resource deletion subscriber not configured")
+
+// WithResourceDeletions cleans up after resources deleted by other services,
+// received as delete change events from the given subscriber
+func WithResourceDeletions(subscriber events.Subscriber) SubscriberOption {
+	return func(s *Subscriber) {
+		s.changes = subscriber
+	}
+}
+
+// SubscribeDeletions subscribes to delete change events of a topic, e.g. loadbalancer
+func (s *Subscriber) SubscribeDeletions(topic string) error {
+	if s.changes == nil {
+		return ErrDeletionsNotConfigured
+	}
+
+	msgChan, err := s.changes.SubscribeChanges(s.ctx, string(events.DeleteChangeType)+"."+topic)
+	if err != nil {
+		return err
+	}
+
+	s.deletionChannels = append(s.deletionChannels, msgChan)
+
+	return nil
+}
+
+// listenDeletions listens for delete change events on a channel
+func (s *Subscriber) listenDeletions(messages <-chan events.Message[events.ChangeMessage], wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for msg := range messages {
+		elogger := s.logger.With(
+			"event.message.topic", msg.Topic(),
+			"event.message.subject.id", msg.Message().SubjectID.String(),
+		)
+
+		if err := s.processDeletion(msg); err != nil {
+			elogger.Errorw("failed to process deletion", "error", err)
+
+			if nakErr := msg.Nak(nakDelay); nakErr != nil {
+				elogger.Warnw("error occurred while naking", "error", nakErr)
+			}
+		} else if ackErr := msg.Ack(); ackErr != nil {
+			elogger.Errorw("error occurred while acking", "error", ackErr)
+		}
+	}
+}
+
+// processDeletion removes the roles, role-bindings and relationships left
+// behind by the deleted resource of a delete change event.
+func (s *Subscriber) processDeletion(msg events.Message[events.ChangeMessage]) error {
+	elogger := s.logger.With(
+		"event.message.topic", msg.Topic(),
+		"event.message.subject.id", msg.Message().SubjectID.String(),
+	)
+
+	if msg.Error() != nil {
+		elogger.Errorw("message contains error:", "error", msg.Error())
+
+		return msg.Error()
+	}
+
+	change := msg.Message()
+
+	if change.EventType != string(events.DeleteChangeType) {
+		elogger.Debugw("ignoring msg, not a delete event", "event.message.type", change.EventType)
+
+		return nil
+	}
+
+	resource, err := s.qe.NewResourceFromID(change.SubjectID)
+	if err != nil {
+		// the resource is unknown to the policy, so nothing references it
+		elogger.Warnw("ignoring deletion of unknown resource", "error", err)
+
+		return nil
+	}
+
+	ctx := change.GetTraceContext(context.Background())
+
+	ctx, span := tracer.Start(ctx, "pubsub.deletion", consumerSpanOptions(msg.Topic(), change.SubjectID)...)
+
+	defer span.End()
+
+	elogger.Debugw("received deletion")
+
+	err = s.retry(ctx, func(ctx context.Context) error {
+		return s.qe.DeleteResource(ctx, resource)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return fmt.Errorf("%w: error cleaning up deleted resource", err)
+	}
+
+	elogger.Infow("cleaned up deleted resource")
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package pubsub
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+	"go.infratographer.com/x/testing/eventtools"
+
+	"go.infratographer.com/permissions-api/internal/query/mock"
+	"go.infratographer.com/permissions-api/internal/testingx"
+)
+
+func TestDeletions(t *testing.T) {
+	deleteMsg := events.ChangeMessage{
+		SubjectID: gidx.PrefixedID("loadbal-UCN7pxJO57BV_5pNiV95B"),
+		EventType: string(events.DeleteChangeType),
+	}
+
+	updateMsg := events.ChangeMessage{
+		SubjectID: gidx.PrefixedID("loadbal-UCN7pxJO57BV_5pNiV95B"),
+		EventType: string(events.UpdateChangeType),
+	}
+
+	unknownResourceMsg := events.ChangeMessage{
+		SubjectID: gidx.PrefixedID("notfoun-UCN7pxJO57BV_5pNiV95B"),
+		EventType: string(events.DeleteChangeType),
+	}
+
+	testCases := []testingx.TestCase[events.ChangeMessage, *eventtools.MockMessage[events.ChangeMessage]]{
+		{
+			Name:  "delete",
+			Input: deleteMsg,
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				var engine mock.Engine
+				engine.On("DeleteResource").Return(nil).Once()
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, result testingx.TestResult[*eventtools.MockMessage[events.ChangeMessage]]) {
+				require.NoError(t, result.Err)
+
+				ctx.Value(contextKeyEngine).(*mock.Engine).AssertExpectations(t)
+				result.Success.AssertCalled(t, "Ack")
+			},
+		},
+		{
+			Name:  "update",
+			Input: updateMsg,
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				var engine mock.Engine
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, result testingx.TestResult[*eventtools.MockMessage[events.ChangeMessage]]) {
+				require.NoError(t, result.Err)
+
+				ctx.Value(contextKeyEngine).(*mock.Engine).AssertNotCalled(t, "DeleteResource")
+				result.Success.AssertCalled(t, "Ack")
+			},
+		},
+		{
+			Name:  "unknownresource",
+			Input: unknownResourceMsg,
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				var engine mock.Engine
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, result testingx.TestResult[*eventtools.MockMessage[events.ChangeMessage]]) {
+				require.NoError(t, result.Err)
+
+				ctx.Value(contextKeyEngine).(*mock.Engine).AssertNotCalled(t, "DeleteResource")
+				result.Success.AssertCalled(t, "Ack")
+			},
+		},
+		{
+			Name:  "failed",
+			Input: deleteMsg,
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				var engine mock.Engine
+				engine.On("DeleteResource").Return(io.ErrUnexpectedEOF).Times(testRetryPolicy.MaxAttempts)
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, result testingx.TestResult[*eventtools.MockMessage[events.ChangeMessage]]) {
+				require.NoError(t, result.Err)
+
+				ctx.Value(contextKeyEngine).(*mock.Engine).AssertExpectations(t)
+				result.Success.AssertCalled(t, "Nak", nakDelay)
+				result.Success.AssertNotCalled(t, "Ack")
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input events.ChangeMessage) testingx.TestResult[*eventtools.MockMessage[events.ChangeMessage]] {
+		engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+
+		msg := &eventtools.MockMessage[events.ChangeMessage]{}
+		msg.On("Topic").Return("changes." + input.EventType + ".loadbalancer")
+		msg.On("Message").Return(input)
+		msg.On("Error").Return(nil)
+		msg.On("Ack").Return(nil).Maybe()
+		msg.On("Nak", nakDelay).Return(nil).Maybe()
+
+		messages := make(chan events.Message[events.ChangeMessage], 1)
+		messages <- msg
+
+		close(messages)
+
+		conn := &eventtools.MockConnection{}
+		conn.On("SubscribeChanges", "delete.loadbalancer").Return((<-chan events.Message[events.ChangeMessage])(messages), nil)
+
+		sub, err := NewSubscriber(ctx, conn, engine,
+			WithRetryPolicy(testRetryPolicy),
+			WithResourceDeletions(conn),
+		)
+		if err != nil {
+			return testingx.TestResult[*eventtools.MockMessage[events.ChangeMessage]]{Err: err}
+		}
+
+		if err := sub.SubscribeDeletions("loadbalancer"); err != nil {
+			return testingx.TestResult[*eventtools.MockMessage[events.ChangeMessage]]{Err: err}
+		}
+
+		// returns once the message channel is drained
+		err = sub.Listen()
+
+		return testingx.TestResult[*eventtools.MockMessage[events.ChangeMessage]]{
+			Success: msg,
+			Err:     err,
+		}
+	}
+
+	testingx.RunTests(context.Background(), t, testCases, testFn)
+}
@@ -0,0 +1,25 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+
+	"go.infratographer.com/x/events"
+)
+
+// flusher is implemented by NATS connections, flushing round trips to the server.
+type flusher interface {
+	FlushWithContext(ctx context.Context) error
+}
+
+// Healthcheck flushes the events connection to check the server is reachable.
+func Healthcheck(conn events.Connection) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		source, ok := conn.Source().(flusher)
+		if !ok {
+			return fmt.Errorf("%w: %T", ErrUnsupportedConnection, conn.Source())
+		}
+
+		return source.FlushWithContext(ctx)
+	}
+}
@@ -0,0 +1,85 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.infratographer.com/permissions-api/internal/query"
+)
+
+// DefaultRetryPolicy is the retry policy used when none is configured.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+}
+
+// RetryPolicy configures how often writing the relationships of a message is
+// attempted before the message is considered failed. The backoff between
+// attempts doubles after every attempt, up to MaxBackoff.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the time waited before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum time waited between attempts.
+	MaxBackoff time.Duration
+}
+
+// backoff returns the time to wait after the given failed attempt, starting at 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+
+		if p.MaxBackoff > 0 && backoff >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+
+	return backoff
+}
+
+// retryable reports whether an error writing relationships may be resolved by
+// trying again. Errors caused by the message itself are not retried.
+func retryable(err error) bool {
+	switch {
+	case errors.Is(err, context.Canceled),
+		errors.Is(err, query.ErrInvalidArgument),
+		errors.Is(err, query.ErrInvalidType),
+		errors.Is(err, query.ErrInvalidNamespace),
+		errors.Is(err, query.ErrInvalidReference),
+		errors.Is(err, query.ErrInvalidRelationship):
+		return false
+	default:
+		return true
+	}
+}
+
+// retry calls fn until it succeeds, fails with an error which is not
+// retryable or the attempts of the retry policy are exhausted.
+func (s *Subscriber) retry(ctx context.Context, fn func(context.Context) error) error {
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil || !retryable(err) || attempt >= s.retryPolicy.MaxAttempts {
+			return err
+		}
+
+		backoff := s.retryPolicy.backoff(attempt)
+
+		s.logger.Warnw("error writing relationships, retrying",
+			"attempt", attempt,
+			"backoff", backoff.String(),
+			"error", err,
+		)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -26,6 +27,9 @@ var (
 
 	// ErrUnknownResourceType is returned when the corresponding resource type is not found for a resource id.
 	ErrUnknownResourceType = errors.New("unknown resource type")
+
+	// ErrUnsupportedConnection is returned when the health of an events connection cannot be checked.
+	ErrUnsupportedConnection = errors.New("unsupported events connection")
 )
 
 // Subscriber is the subscriber client
@@ -35,6 +39,12 @@ type Subscriber struct {
 	logger         *zap.SugaredLogger
 	subscriber     events.AuthRelationshipSubscriber
 	qe             query.Engine
+	retryPolicy    RetryPolicy
+	deadLetter     *deadLetterPublisher
+	batch          BatchConfig
+
+	changes          events.Subscriber
+	deletionChannels []<-chan events.Message[events.ChangeMessage]
 }
 
 // SubscriberOption is a functional option for the Subscriber
@@ -47,13 +57,39 @@ func WithLogger(l *zap.SugaredLogger) SubscriberOption {
 	}
 }
 
+// WithRetryPolicy sets how often writing the relationships of a message is attempted
+func WithRetryPolicy(p RetryPolicy) SubscriberOption {
+	return func(s *Subscriber) {
+		if p.MaxAttempts <= 0 {
+			return
+		}
+
+		s.retryPolicy = p
+	}
+}
+
+// WithDeadLetter publishes requests which could not be processed to the given topic
+func WithDeadLetter(publisher events.Publisher, topic string) SubscriberOption {
+	return func(s *Subscriber) {
+		if publisher == nil || topic == "" {
+			return
+		}
+
+		s.deadLetter = &deadLetterPublisher{
+			publisher: publisher,
+			topic:     topic,
+		}
+	}
+}
+
 // NewSubscriber creates a new Subscriber
 func NewSubscriber(ctx context.Context, subscriber events.AuthRelationshipSubscriber, engine query.Engine, opts ...SubscriberOption) (*Subscriber, error) {
 	s := &Subscriber{
-		ctx:        ctx,
-		logger:     zap.NewNop().Sugar(),
-		qe:         engine,
-		subscriber: subscriber,
+		ctx:         ctx,
+		logger:      zap.NewNop().Sugar(),
+		qe:          engine,
+		subscriber:  subscriber,
+		retryPolicy: DefaultRetryPolicy,
 	}
 
 	for _, opt := range opts {
@@ -86,6 +122,12 @@ func (s Subscriber) Listen() error {
 		go s.listen(ch, wg)
 	}
 
+	for _, ch := range s.deletionChannels {
+		wg.Add(1)
+
+		go s.listenDeletions(ch, wg)
+	}
+
 	wg.Wait()
 
 	return nil
@@ -95,23 +137,53 @@ func (s Subscriber) Listen() error {
 func (s Subscriber) listen(messages <-chan events.Request[events.AuthRelationshipRequest, events.AuthRelationshipResponse], wg *sync.WaitGroup) {
 	defer wg.Done()
 
+	if s.batch.enabled() {
+		s.listenBatched(messages)
+
+		return
+	}
+
 	for msg := range messages {
-		elogger := s.logger.With(
-			"event.message.topic", msg.Topic(),
-			"event.message.action", msg.Message().Action,
-			"event.message.object.id", msg.Message().ObjectID.String(),
-			"event.message.relations", len(msg.Message().Relations),
-		)
-
-		if err := s.processEvent(msg); err != nil {
-			elogger.Errorw("failed to process msg", "error", err)
-
-			if nakErr := msg.Nak(nakDelay); nakErr != nil {
-				elogger.Warnw("error occurred while naking", "error", nakErr)
-			}
-		} else if ackErr := msg.Ack(); ackErr != nil {
-			elogger.Errorw("error occurred while acking", "error", ackErr)
+		s.handleMessage(msg)
+	}
+}
+
+// handleMessage processes a single message and acknowledges it
+func (s *Subscriber) handleMessage(msg events.Request[events.AuthRelationshipRequest, events.AuthRelationshipResponse]) {
+	s.acknowledge(msg, s.processEvent(msg))
+}
+
+// acknowledge acks the message if it was processed, otherwise the message is naked
+func (s *Subscriber) acknowledge(msg events.Request[events.AuthRelationshipRequest, events.AuthRelationshipResponse], err error) {
+	elogger := s.logger.With(
+		"event.message.topic", msg.Topic(),
+		"event.message.action", msg.Message().Action,
+		"event.message.object.id", msg.Message().ObjectID.String(),
+		"event.message.relations", len(msg.Message().Relations),
+	)
+
+	if err != nil {
+		elogger.Errorw("failed to process msg", "error", err)
+
+		if nakErr := msg.Nak(nakDelay); nakErr != nil {
+			elogger.Warnw("error occurred while naking", "error", nakErr)
 		}
+	} else if ackErr := msg.Ack(); ackErr != nil {
+		elogger.Errorw("error occurred while acking", "error", ackErr)
+	}
+}
+
+// consumerSpanOptions returns the options of the span processing a received
+// message. The span continues the trace of the service publishing the message,
+// propagated in the trace context of the message.
+func consumerSpanOptions(topic string, subjectID gidx.PrefixedID) []trace.SpanStartOption {
+	return []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("pubsub.subject", subjectID.String()),
+			attribute.String("messaging.system", "nats"),
+			attribute.String("messaging.destination.name", topic),
+		),
 	}
 }
 
@@ -134,7 +206,7 @@ func (s *Subscriber) processEvent(msg events.Request[events.AuthRelationshipRequ
 
 	ctx := request.GetTraceContext(context.Background())
 
-	ctx, span := tracer.Start(ctx, "pubsub.receive", trace.WithAttributes(attribute.String("pubsub.subject", request.ObjectID.String())))
+	ctx, span := tracer.Start(ctx, "pubsub.receive", consumerSpanOptions(msg.Topic(), request.ObjectID)...)
 
 	defer span.End()
 
@@ -160,7 +232,10 @@ func (s *Subscriber) processEvent(msg events.Request[events.AuthRelationshipRequ
 
 func (s *Subscriber) createRelationships(ctx context.Context, relationships []types.Relationship) error {
 	// Attempt to create the relationships in SpiceDB.
-	if err := s.qe.CreateRelationships(ctx, relationships); err != nil {
+	err := s.retry(ctx, func(ctx context.Context) error {
+		return s.qe.CreateRelationships(ctx, relationships)
+	})
+	if err != nil {
 		return fmt.Errorf("%w: error creating relationships", err)
 	}
 
@@ -168,7 +243,10 @@ func (s *Subscriber) createRelationships(ctx context.Context, relationships []ty
 }
 
 func (s *Subscriber) deleteRelationships(ctx context.Context, relationships []types.Relationship) error {
-	if err := s.qe.DeleteRelationships(ctx, relationships...); err != nil {
+	err := s.retry(ctx, func(ctx context.Context) error {
+		return s.qe.DeleteRelationships(ctx, relationships...)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -183,6 +261,18 @@ func (s *Subscriber) handleCreateEvent(ctx context.Context, msg events.Request[e
 		"event.message.relations", len(msg.Message().Relations),
 	)
 
+	relationships, errors := s.createEventRelationships(elogger, msg)
+	if len(errors) != 0 {
+		return s.respondRequest(ctx, elogger, msg, errors...)
+	}
+
+	err := s.createRelationships(ctx, relationships)
+
+	return s.respondRequest(ctx, elogger, msg, err)
+}
+
+// createEventRelationships validates a write request and returns the relationships to create
+func (s *Subscriber) createEventRelationships(elogger *zap.SugaredLogger, msg events.Request[events.AuthRelationshipRequest, events.AuthRelationshipResponse]) ([]types.Relationship, []error) {
 	var errors []error
 
 	if err := msg.Message().Validate(); err != nil {
@@ -193,14 +283,14 @@ func (s *Subscriber) handleCreateEvent(ctx context.Context, msg events.Request[e
 	if err != nil {
 		elogger.Warnw("error parsing resource ID", "error", err.Error())
 
-		return respondRequest(ctx, elogger, msg, err)
+		return nil, []error{err}
 	}
 
 	rType := s.qe.GetResourceType(resource.Type)
 	if rType == nil {
-		elogger.Warnw("error finding resource type", "error", err.Error())
+		elogger.Warnw("error finding resource type", "resource.type", resource.Type)
 
-		return respondRequest(ctx, elogger, msg, fmt.Errorf("%w: resource: %s", ErrUnknownResourceType, resource.Type))
+		return nil, []error{fmt.Errorf("%w: resource: %s", ErrUnknownResourceType, resource.Type)}
 	}
 
 	relationships := make([]types.Relationship, len(msg.Message().Relations))
@@ -231,13 +321,7 @@ func (s *Subscriber) handleCreateEvent(ctx context.Context, msg events.Request[e
 		}
 	}
 
-	if len(errors) != 0 {
-		return respondRequest(ctx, elogger, msg, errors...)
-	}
-
-	err = s.createRelationships(ctx, relationships)
-
-	return respondRequest(ctx, elogger, msg, err)
+	return relationships, errors
 }
 
 func (s *Subscriber) handleDeleteEvent(ctx context.Context, msg events.Request[events.AuthRelationshipRequest, events.AuthRelationshipResponse]) error {
@@ -297,16 +381,18 @@ func (s *Subscriber) handleDeleteEvent(ctx context.Context, msg events.Request[e
 	}
 
 	if len(errors) != 0 {
-		return respondRequest(ctx, elogger, msg, errors...)
+		return s.respondRequest(ctx, elogger, msg, errors...)
 	}
 
 	err = s.deleteRelationships(ctx, relationships)
 
-	return respondRequest(ctx, elogger, msg, err)
+	return s.respondRequest(ctx, elogger, msg, err)
 }
 
-func respondRequest(ctx context.Context, logger *zap.SugaredLogger, msg events.Request[events.AuthRelationshipRequest, events.AuthRelationshipResponse], errors ...error) error {
-	ctx, span := tracer.Start(ctx, "pubsub.respond")
+// respondRequest replies to the request with any errors processing it, failed
+// requests are published to the dead-letter topic.
+func (s *Subscriber) respondRequest(ctx context.Context, logger *zap.SugaredLogger, msg events.Request[events.AuthRelationshipRequest, events.AuthRelationshipResponse], errors ...error) error {
+	ctx, span := tracer.Start(ctx, "pubsub.respond", trace.WithSpanKind(trace.SpanKindProducer))
 
 	defer span.End()
 
@@ -326,6 +412,8 @@ func respondRequest(ctx context.Context, logger *zap.SugaredLogger, msg events.R
 		err := multierr.Combine(filteredErrors...)
 
 		logger.Errorw("error processing relationship, sending error response", "error", err)
+
+		s.publishDeadLetter(ctx, logger, msg, filteredErrors)
 	} else {
 		logger.Debug("relationship successfully processed, sending response")
 	}
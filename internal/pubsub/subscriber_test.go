@@ -19,7 +19,10 @@ import (
 
 var contextKeyEngine = struct{}{}
 
-func setupEvents(t *testing.T, engine query.Engine) (*eventtools.TestNats, events.AuthRelationshipPublisher, *Subscriber) {
+// testRetryPolicy retries failed events without slowing down tests.
+var testRetryPolicy = RetryPolicy{MaxAttempts: 2, InitialBackoff: 10 * time.Millisecond}
+
+func setupEvents(t *testing.T, engine query.Engine, opts ...SubscriberOption) (*eventtools.TestNats, events.AuthRelationshipPublisher, *Subscriber) {
 	ctx := context.Background()
 
 	nats, err := eventtools.NewNatsServer()
@@ -30,7 +33,7 @@ func setupEvents(t *testing.T, engine query.Engine) (*eventtools.TestNats, event
 
 	require.NoError(t, err)
 
-	subscriber, err := NewSubscriber(ctx, eventHandler, engine)
+	subscriber, err := NewSubscriber(ctx, eventHandler, engine, opts...)
 
 	require.NoError(t, err)
 
@@ -140,6 +143,28 @@ func TestNATS(t *testing.T) {
 				require.NotEmpty(t, result.Success.Message().Errors)
 			},
 		},
+		{
+			Name: "retrycreate",
+			Input: testInput{
+				subject: "retrycreate.loadbalancer",
+				request: createMsg,
+			},
+			SetupFn: func(ctx context.Context, _ *testing.T) context.Context {
+				var engine mock.Engine
+				engine.On("CreateRelationships").Return(io.ErrUnexpectedEOF).Once()
+				engine.On("CreateRelationships").Return(nil).Once()
+
+				return context.WithValue(ctx, contextKeyEngine, &engine)
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, result testingx.TestResult[events.Message[events.AuthRelationshipResponse]]) {
+				require.NoError(t, result.Err)
+				require.NotNil(t, result.Success)
+				require.Empty(t, result.Success.Message().Errors)
+
+				engine := ctx.Value(contextKeyEngine).(*mock.Engine)
+				engine.AssertExpectations(t)
+			},
+		},
 		{
 			Name: "nocreate",
 			Input: testInput{
@@ -201,7 +226,7 @@ func TestNATS(t *testing.T) {
 	testFn := func(ctx context.Context, input testInput) testingx.TestResult[events.Message[events.AuthRelationshipResponse]] {
 		engine := ctx.Value(contextKeyEngine).(query.Engine)
 
-		_, pub, sub := setupEvents(t, engine)
+		_, pub, sub := setupEvents(t, engine, WithRetryPolicy(testRetryPolicy))
 
 		err := sub.Subscribe("*." + input.subject)
 
@@ -0,0 +1,86 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/query/mock"
+)
+
+func TestTracePropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var engine mock.Engine
+	engine.On("CreateRelationships").Return(nil)
+
+	_, pub, sub := setupEvents(t, &engine)
+
+	require.NoError(t, sub.Subscribe("*.trace.loadbalancer"))
+
+	go func() {
+		_ = sub.Listen()
+	}()
+
+	// Allow time for the listener to to start
+	time.Sleep(time.Second)
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "publish")
+
+	resp, err := pub.PublishAuthRelationshipRequest(ctx, "create.trace.loadbalancer", events.AuthRelationshipRequest{
+		Action:   events.WriteAuthRelationshipAction,
+		ObjectID: gidx.PrefixedID("loadbal-UCN7pxJO57BV_5pNiV95B"),
+		Relations: []events.AuthRelationshipRelation{
+			{
+				Relation:  "owner",
+				SubjectID: gidx.PrefixedID("tnntten-gd6RExwAz353UqHLzjC1n"),
+			},
+		},
+	})
+
+	span.End()
+
+	require.NoError(t, err)
+	require.Empty(t, resp.Message().Errors)
+
+	// the response continues the trace as well
+	assert.Equal(t, span.SpanContext().TraceID(), trace.SpanContextFromContext(resp.Message().GetTraceContext(context.Background())).TraceID())
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+
+	// the spans end after the response is sent
+	require.Eventually(t, func() bool {
+		for _, s := range recorder.Ended() {
+			spans[s.Name()] = s
+		}
+
+		_, received := spans["pubsub.receive"]
+		_, responded := spans["pubsub.respond"]
+
+		return received && responded
+	}, time.Second, 10*time.Millisecond)
+
+	receive := spans["pubsub.receive"]
+
+	assert.Equal(t, trace.SpanKindConsumer, receive.SpanKind())
+	assert.Equal(t, span.SpanContext().TraceID(), receive.Parent().TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), receive.Parent().SpanID())
+
+	respond := spans["pubsub.respond"]
+
+	assert.Equal(t, receive.SpanContext().SpanID(), respond.Parent().SpanID())
+}
@@ -0,0 +1,291 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// AccessRequestIDPrefix is the ID prefix of access requests.
+const AccessRequestIDPrefix = "permacr"
+
+func (e *engine) CreateAccessRequest(
+	ctx context.Context,
+	subject, resource, roleResource types.Resource,
+	reason string,
+) (types.AccessRequest, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.CreateAccessRequest",
+		trace.WithAttributes(
+			attribute.Stringer("subject_id", subject.ID),
+			attribute.Stringer("role_id", roleResource.ID),
+			attribute.Stringer("resource_id", resource.ID),
+		),
+	)
+	defer span.End()
+
+	fail := func(err error) (types.AccessRequest, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessRequest{}, err
+	}
+
+	if err := e.isRoleBindable(ctx, roleResource, resource); err != nil {
+		return fail(err)
+	}
+
+	dbrole, err := e.store.GetRoleByID(ctx, roleResource.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoRoleFound) {
+			err = fmt.Errorf("%w: role %s", ErrRoleNotFound, roleResource.ID)
+		}
+
+		return fail(err)
+	}
+
+	// requests which could never be approved are rejected up front
+	if err := e.checkTenantSettings(ctx, resource, dbrole.ResourceID, []types.RoleBindingSubject{{SubjectResource: subject}}); err != nil {
+		return fail(err)
+	}
+
+	id, err := gidx.NewID(AccessRequestIDPrefix)
+	if err != nil {
+		return fail(err)
+	}
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	req, err := e.store.CreateAccessRequest(dbCtx, types.AccessRequest{
+		ID:         id,
+		ResourceID: resource.ID,
+		RoleID:     roleResource.ID,
+		SubjectID:  subject.ID,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(err)
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(err)
+	}
+
+	e.publishAuditEvent(ctx, auditEvent{
+		eventType:  AuditEventAccessRequestCreated,
+		actor:      subject,
+		subjectID:  req.ID,
+		resourceID: req.ResourceID,
+		relatedIDs: []gidx.PrefixedID{req.RoleID, req.SubjectID},
+		after:      auditAccessRequest(req),
+	})
+
+	return req, nil
+}
+
+func (e *engine) GetAccessRequest(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.GetAccessRequest",
+		trace.WithAttributes(attribute.Stringer("access_request_id", id)),
+	)
+	defer span.End()
+
+	req, err := e.store.GetAccessRequestByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrAccessRequestNotFound) {
+			err = fmt.Errorf("%w: %s", ErrAccessRequestNotFound, id)
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessRequest{}, err
+	}
+
+	return req, nil
+}
+
+func (e *engine) ListAccessRequests(
+	ctx context.Context,
+	resource types.Resource,
+	status types.AccessRequestStatus,
+) ([]types.AccessRequest, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListAccessRequests",
+		trace.WithAttributes(
+			attribute.Stringer("resource_id", resource.ID),
+			attribute.String("status", string(status)),
+		),
+	)
+	defer span.End()
+
+	switch status {
+	case "", types.AccessRequestPending, types.AccessRequestApproved, types.AccessRequestDenied:
+	default:
+		err := fmt.Errorf("%w: unknown access request status %q", ErrInvalidArgument, status)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	requests, err := e.store.ListResourceAccessRequests(ctx, resource.ID, status)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// DecideAccessRequest approves or denies a pending access request. Approving
+// creates the role binding of the request, with the approver as its creator.
+// The request stays locked until the decision is recorded, so a request is
+// only decided once.
+func (e *engine) DecideAccessRequest(
+	ctx context.Context,
+	actor types.Resource,
+	id gidx.PrefixedID,
+	approve bool,
+	reason string,
+) (types.AccessRequest, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.DecideAccessRequest",
+		trace.WithAttributes(
+			attribute.Stringer("access_request_id", id),
+			attribute.Bool("approve", approve),
+		),
+	)
+	defer span.End()
+
+	fail := func(err error) (types.AccessRequest, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.AccessRequest{}, err
+	}
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	before, err := e.store.LockAccessRequest(dbCtx, id)
+	if err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		if errors.Is(err, storage.ErrAccessRequestNotFound) {
+			err = fmt.Errorf("%w: %s", ErrAccessRequestNotFound, id)
+		}
+
+		return fail(err)
+	}
+
+	switch {
+	case before.Status != types.AccessRequestPending:
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(fmt.Errorf("%w: %s is %s", ErrAccessRequestDecided, id, before.Status))
+	case before.SubjectID == actor.ID:
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(fmt.Errorf("%w: %s", ErrAccessRequestSelfDecision, id))
+	}
+
+	status := types.AccessRequestDenied
+	eventType := AuditEventAccessRequestDenied
+
+	var rbID gidx.PrefixedID
+
+	if approve {
+		status = types.AccessRequestApproved
+		eventType = AuditEventAccessRequestApproved
+
+		rb, err := e.createAccessRequestRoleBinding(ctx, actor, before)
+		if err != nil {
+			logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+			return fail(err)
+		}
+
+		rbID = rb.ID
+	}
+
+	// the role binding is removed again if the decision cannot be recorded
+	rollbackRoleBinding := func() {
+		if rbID == "" {
+			return
+		}
+
+		rbResource := types.Resource{Type: e.rbac.RoleBindingResource.Name, ID: rbID}
+
+		logRollbackErr(e.contextLogger(ctx), e.DeleteRoleBinding(ctx, rbResource))
+	}
+
+	req, err := e.store.DecideAccessRequest(dbCtx, id, status, actor.ID, reason, rbID)
+	if err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		rollbackRoleBinding()
+
+		return fail(err)
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		rollbackRoleBinding()
+
+		return fail(err)
+	}
+
+	e.publishAuditEvent(ctx, auditEvent{
+		eventType:  eventType,
+		actor:      actor,
+		subjectID:  req.ID,
+		resourceID: req.ResourceID,
+		relatedIDs: []gidx.PrefixedID{req.RoleID, req.SubjectID},
+		before:     auditAccessRequest(before),
+		after:      auditAccessRequest(req),
+	})
+
+	return req, nil
+}
+
+// createAccessRequestRoleBinding binds the role of an access request to its
+// subject on behalf of the approver.
+func (e *engine) createAccessRequestRoleBinding(ctx context.Context, actor types.Resource, req types.AccessRequest) (types.RoleBinding, error) {
+	resource, err := e.NewResourceFromID(req.ResourceID)
+	if err != nil {
+		return types.RoleBinding{}, err
+	}
+
+	roleResource, err := e.NewResourceFromID(req.RoleID)
+	if err != nil {
+		return types.RoleBinding{}, err
+	}
+
+	subject, err := e.NewResourceFromID(req.SubjectID)
+	if err != nil {
+		return types.RoleBinding{}, err
+	}
+
+	return e.CreateRoleBinding(ctx, actor, resource, roleResource, []types.RoleBindingSubject{{SubjectResource: subject}})
+}
@@ -0,0 +1,123 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestDecideAccessRequest(t *testing.T) {
+	namespace := "testaccessrequests"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	approver, err := e.NewResourceFromIDString("idntusr-approver")
+	require.NoError(t, err)
+	requester, err := e.NewResourceFromIDString("idntusr-requester")
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, approver, tenant, "lb_viewer", []string{"loadbalancer_list", "loadbalancer_get"})
+	require.NoError(t, err)
+
+	roleRes, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	approved, err := e.CreateAccessRequest(ctx, requester, tenant, roleRes, "investigating an incident")
+	require.NoError(t, err)
+	assert.Equal(t, types.AccessRequestPending, approved.Status)
+	assert.Equal(t, requester.ID, approved.SubjectID)
+
+	denied, err := e.CreateAccessRequest(ctx, requester, tenant, roleRes, "curious")
+	require.NoError(t, err)
+
+	pending, err := e.ListAccessRequests(ctx, tenant, types.AccessRequestPending)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	_, err = e.ListAccessRequests(ctx, tenant, "unknown")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	type decision struct {
+		actor   types.Resource
+		id      gidx.PrefixedID
+		approve bool
+	}
+
+	tc := []testingx.TestCase[decision, types.AccessRequest]{
+		{
+			Name:  "Unknown",
+			Input: decision{approver, gidx.MustNewID(AccessRequestIDPrefix), true},
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.AccessRequest]) {
+				assert.ErrorIs(t, res.Err, ErrAccessRequestNotFound)
+			},
+		},
+		{
+			Name:  "SelfApproval",
+			Input: decision{requester, approved.ID, true},
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.AccessRequest]) {
+				assert.ErrorIs(t, res.Err, ErrAccessRequestSelfDecision)
+			},
+		},
+		{
+			Name:  "Approved",
+			Input: decision{approver, approved.ID, true},
+			Sync:  true,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.AccessRequest]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, types.AccessRequestApproved, res.Success.Status)
+				assert.Equal(t, approver.ID, res.Success.DecidedBy)
+				require.NotEmpty(t, res.Success.RoleBindingID)
+
+				rb, err := e.GetRoleBinding(ctx, types.Resource{Type: e.rbac.RoleBindingResource.Name, ID: res.Success.RoleBindingID})
+				require.NoError(t, err)
+				assert.Equal(t, role.ID, rb.RoleID)
+				assert.Equal(t, []gidx.PrefixedID{requester.ID}, rb.SubjectIDs)
+				assert.Equal(t, approver.ID, rb.CreatedBy)
+
+				err = e.SubjectHasPermission(ctx, requester, "loadbalancer_get", tenant)
+				assert.NoError(t, err)
+			},
+		},
+		{
+			Name:  "AlreadyDecided",
+			Input: decision{approver, approved.ID, false},
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.AccessRequest]) {
+				assert.ErrorIs(t, res.Err, ErrAccessRequestDecided)
+			},
+		},
+		{
+			Name:  "Denied",
+			Input: decision{approver, denied.ID, false},
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.AccessRequest]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, types.AccessRequestDenied, res.Success.Status)
+				assert.Equal(t, "not needed", res.Success.DecisionReason)
+				assert.Empty(t, res.Success.RoleBindingID)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in decision) testingx.TestResult[types.AccessRequest] {
+		req, err := e.DecideAccessRequest(ctx, in.actor, in.id, in.approve, "not needed")
+
+		return testingx.TestResult[types.AccessRequest]{Success: req, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+
+	pending, err = e.ListAccessRequests(ctx, tenant, types.AccessRequestPending)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
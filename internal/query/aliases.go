@@ -0,0 +1,204 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// resourceAliasScheme is the scheme of resource aliases, IDs with this
+	// scheme are resolved as aliases rather than parsed as prefixed IDs.
+	resourceAliasScheme = "urn:"
+
+	// maxResourceAliasLength is the maximum length of a resource alias.
+	maxResourceAliasLength = 255
+)
+
+// IsResourceAlias reports whether the ID is an alias of a resource, e.g.
+// urn:partner:account:1234, rather than a prefixed ID.
+func IsResourceAlias(id string) bool {
+	return len(id) >= len(resourceAliasScheme) && strings.EqualFold(id[:len(resourceAliasScheme)], resourceAliasScheme)
+}
+
+// validateResourceAlias ensures the alias is a URN with a namespace and a
+// namespace specific string.
+func validateResourceAlias(alias string) error {
+	if !IsResourceAlias(alias) {
+		return fmt.Errorf("%w: alias must start with %s", ErrInvalidResourceAlias, resourceAliasScheme)
+	}
+
+	if len(alias) > maxResourceAliasLength {
+		return fmt.Errorf("%w: alias must be at most %d characters", ErrInvalidResourceAlias, maxResourceAliasLength)
+	}
+
+	nid, nss, _ := strings.Cut(alias[len(resourceAliasScheme):], ":")
+	if nid == "" || nss == "" {
+		return fmt.Errorf("%w: alias must have the form urn:<namespace>:<id>", ErrInvalidResourceAlias)
+	}
+
+	return nil
+}
+
+// ResolveResource returns the resource for a prefixed ID or a registered alias.
+func (e *engine) ResolveResource(ctx context.Context, id string) (types.Resource, error) {
+	if !IsResourceAlias(id) {
+		prefixedID, err := gidx.Parse(id)
+		if err != nil {
+			return types.Resource{}, fmt.Errorf("%w: %s", ErrInvalidArgument, err.Error())
+		}
+
+		return e.NewResourceFromID(prefixedID)
+	}
+
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ResolveResource",
+		trace.WithAttributes(attribute.String("alias", id)),
+	)
+	defer span.End()
+
+	alias, err := e.GetResourceAlias(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Resource{}, err
+	}
+
+	span.SetAttributes(attribute.Stringer("resource_id", alias.ResourceID))
+
+	return e.NewResourceFromID(alias.ResourceID)
+}
+
+func (e *engine) CreateResourceAlias(ctx context.Context, actor, resource types.Resource, alias string) (types.ResourceAlias, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.CreateResourceAlias",
+		trace.WithAttributes(
+			attribute.Stringer("resource_id", resource.ID),
+			attribute.String("alias", alias),
+		),
+	)
+	defer span.End()
+
+	fail := func(err error) (types.ResourceAlias, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.ResourceAlias{}, err
+	}
+
+	if err := validateResourceAlias(alias); err != nil {
+		return fail(err)
+	}
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	out, err := e.store.CreateResourceAlias(dbCtx, types.ResourceAlias{
+		Alias:      alias,
+		ResourceID: resource.ID,
+		CreatedBy:  actor.ID,
+	})
+	if err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(err)
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(err)
+	}
+
+	return out, nil
+}
+
+func (e *engine) GetResourceAlias(ctx context.Context, alias string) (types.ResourceAlias, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.GetResourceAlias",
+		trace.WithAttributes(attribute.String("alias", alias)),
+	)
+	defer span.End()
+
+	out, err := e.store.GetResourceAlias(ctx, alias)
+	if err != nil {
+		if errors.Is(err, storage.ErrResourceAliasNotFound) {
+			err = fmt.Errorf("%w: %s", ErrResourceAliasNotFound, alias)
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.ResourceAlias{}, err
+	}
+
+	return out, nil
+}
+
+func (e *engine) ListResourceAliases(ctx context.Context, resource types.Resource) ([]types.ResourceAlias, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListResourceAliases",
+		trace.WithAttributes(attribute.Stringer("resource_id", resource.ID)),
+	)
+	defer span.End()
+
+	aliases, err := e.store.ListResourceAliases(ctx, resource.ID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	return aliases, nil
+}
+
+func (e *engine) DeleteResourceAlias(ctx context.Context, alias string) error {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.DeleteResourceAlias",
+		trace.WithAttributes(attribute.String("alias", alias)),
+	)
+	defer span.End()
+
+	fail := func(err error) error {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	if err := e.store.DeleteResourceAlias(dbCtx, alias); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		if errors.Is(err, storage.ErrResourceAliasNotFound) {
+			err = fmt.Errorf("%w: %s", ErrResourceAliasNotFound, alias)
+		}
+
+		return fail(err)
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestResolveResource(t *testing.T) {
+	namespace := "testaliases"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	alias, err := e.CreateResourceAlias(ctx, actor, tenant, "urn:partner:account:1234")
+	require.NoError(t, err)
+	assert.Equal(t, tenant.ID, alias.ResourceID)
+
+	_, err = e.CreateResourceAlias(ctx, actor, tenant, "urn:partner:account:1234")
+	assert.ErrorIs(t, err, storage.ErrResourceAliasExists)
+
+	_, err = e.CreateResourceAlias(ctx, actor, tenant, "urn:partner")
+	assert.ErrorIs(t, err, ErrInvalidResourceAlias)
+
+	_, err = e.CreateResourceAlias(ctx, actor, tenant, "partner:account:1234")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	tc := []testingx.TestCase[string, types.Resource]{
+		{
+			Name:  "PrefixedID",
+			Input: "tnntten-root",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Resource]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, tenant, res.Success)
+			},
+		},
+		{
+			Name:  "InvalidID",
+			Input: "not-an-id",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Resource]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidArgument)
+			},
+		},
+		{
+			Name:  "AliasCaseSensitive",
+			Input: "URN:partner:account:1234",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Resource]) {
+				assert.ErrorIs(t, res.Err, ErrResourceAliasNotFound, "aliases are matched exactly")
+			},
+		},
+		{
+			Name:  "RegisteredAlias",
+			Input: "urn:partner:account:1234",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Resource]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, tenant, res.Success)
+			},
+		},
+		{
+			Name:  "UnknownAlias",
+			Input: "urn:partner:account:unknown",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Resource]) {
+				assert.ErrorIs(t, res.Err, ErrResourceAliasNotFound)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, id string) testingx.TestResult[types.Resource] {
+		res, err := e.ResolveResource(ctx, id)
+
+		return testingx.TestResult[types.Resource]{Success: res, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+
+	require.NoError(t, e.DeleteResourceAlias(ctx, "urn:partner:account:1234"))
+
+	_, err = e.ResolveResource(ctx, "urn:partner:account:1234")
+	assert.ErrorIs(t, err, ErrResourceAliasNotFound)
+}
@@ -0,0 +1,234 @@
+package query
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/types"
+	"go.infratographer.com/permissions-api/internal/webhooks"
+)
+
+const (
+	// DefaultAuditTopic is the default topic audit events are published to.
+	DefaultAuditTopic = "permissions-audit"
+
+	// AuditEventRoleCreated is published when a role is created.
+	AuditEventRoleCreated = "role_created"
+	// AuditEventRoleUpdated is published when the name or actions of a role are updated.
+	AuditEventRoleUpdated = "role_updated"
+	// AuditEventRoleDeleted is published when a role is deleted.
+	AuditEventRoleDeleted = "role_deleted"
+	// AuditEventRoleAssigned is published when a role is assigned to a subject.
+	AuditEventRoleAssigned = "role_assigned"
+	// AuditEventRoleUnassigned is published when a role is removed from a subject.
+	AuditEventRoleUnassigned = "role_unassigned"
+	// AuditEventRoleBindingCreated is published when a role-binding is created.
+	AuditEventRoleBindingCreated = "rolebinding_created"
+	// AuditEventRoleBindingUpdated is published when the subjects of a role-binding are updated.
+	AuditEventRoleBindingUpdated = "rolebinding_updated"
+	// AuditEventRoleBindingDeleted is published when a role-binding is deleted.
+	AuditEventRoleBindingDeleted = "rolebinding_deleted"
+	// AuditEventRelationshipCreated is published when a relationship is written.
+	AuditEventRelationshipCreated = "relationship_created"
+	// AuditEventRelationshipDeleted is published when a relationship is deleted.
+	AuditEventRelationshipDeleted = "relationship_deleted"
+	// AuditEventAccessRequestCreated is published when a subject requests access.
+	AuditEventAccessRequestCreated = "access_request_created"
+	// AuditEventAccessRequestApproved is published when an access request is approved.
+	AuditEventAccessRequestApproved = "access_request_approved"
+	// AuditEventAccessRequestDenied is published when an access request is denied.
+	AuditEventAccessRequestDenied = "access_request_denied"
+)
+
+// auditPublisher publishes the audit events of permission changes.
+type auditPublisher struct {
+	publisher events.Publisher
+	topic     string
+}
+
+// auditEvent is a change of permissions made by an actor.
+type auditEvent struct {
+	eventType string
+	// actor made the change, if unset the actor of the request is used.
+	actor types.Resource
+	// subjectID is the ID of the changed object, e.g. a role.
+	subjectID gidx.PrefixedID
+	// resourceID is the ID of the resource the changed object belongs to.
+	resourceID gidx.PrefixedID
+	// relatedIDs are the IDs of other objects affected by the change, e.g.
+	// the subjects of a role-binding.
+	relatedIDs []gidx.PrefixedID
+	before     map[string]any
+	after      map[string]any
+}
+
+// publishAuditEvent counts the change, publishes the event to the audit
+// topic, if audit events are enabled, and notifies webhooks of role and
+// role-binding changes, if webhooks are configured. The change has already
+// been made, so errors publishing the event are logged rather than returned.
+func (e *engine) publishAuditEvent(ctx context.Context, event auditEvent) {
+	mutationsTotal.WithLabelValues(event.eventType).Inc()
+
+	if e.audit == nil && e.webhooks == nil {
+		return
+	}
+
+	actorID := event.actor.ID
+	if actorID == "" {
+		actor, _ := ctx.Value(echojwtx.ActorCtxKey).(string)
+		actorID = gidx.PrefixedID(actor)
+	}
+
+	e.notifyWebhooks(ctx, event, actorID)
+
+	if e.audit == nil {
+		return
+	}
+
+	msg := events.EventMessage{
+		SubjectID: event.subjectID,
+		EventType: event.eventType,
+		Timestamp: time.Now().UTC(),
+		Data: map[string]any{
+			"actor":    actorID.String(),
+			"resource": event.resourceID.String(),
+			"before":   event.before,
+			"after":    event.after,
+		},
+	}
+
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		msg.Data["request_id"] = requestID
+	}
+
+	if event.resourceID != "" && event.resourceID != event.subjectID {
+		msg.AdditionalSubjectIDs = append(msg.AdditionalSubjectIDs, event.resourceID)
+	}
+
+	msg.AdditionalSubjectIDs = append(msg.AdditionalSubjectIDs, event.relatedIDs...)
+
+	if _, err := e.audit.publisher.PublishEvent(ctx, e.audit.topic, msg); err != nil {
+		e.contextLogger(ctx).Errorw("failed to publish audit event",
+			"event_type", event.eventType,
+			"subject_id", event.subjectID.String(),
+			"error", err,
+		)
+	}
+}
+
+// notifyWebhooks stores a delivery of role and role-binding changes to the
+// configured webhooks, relationship changes are not delivered.
+func (e *engine) notifyWebhooks(ctx context.Context, event auditEvent, actorID gidx.PrefixedID) {
+	if e.webhooks == nil || !strings.HasPrefix(event.eventType, "role") {
+		return
+	}
+
+	err := e.webhooks.Enqueue(ctx, webhooks.Event{
+		Type:       event.eventType,
+		SubjectID:  event.subjectID,
+		ResourceID: event.resourceID,
+		ActorID:    actorID,
+		Before:     event.before,
+		After:      event.after,
+		Timestamp:  time.Now().UTC(),
+	})
+	if err != nil {
+		e.contextLogger(ctx).Errorw("failed to enqueue webhook deliveries",
+			"event_type", event.eventType,
+			"subject_id", event.subjectID.String(),
+			"error", err,
+		)
+	}
+}
+
+func auditRole(role types.Role) map[string]any {
+	return map[string]any{
+		"id":          role.ID.String(),
+		"name":        role.Name,
+		"actions":     role.Actions,
+		"resource_id": role.ResourceID.String(),
+	}
+}
+
+func auditRoleBinding(rb types.RoleBinding) map[string]any {
+	subjectIDs := make([]string, len(rb.SubjectIDs))
+
+	for i, id := range rb.SubjectIDs {
+		subjectIDs[i] = id.String()
+	}
+
+	return map[string]any{
+		"id":          rb.ID.String(),
+		"resource_id": rb.ResourceID.String(),
+		"role_id":     rb.RoleID.String(),
+		"subject_ids": subjectIDs,
+	}
+}
+
+func auditAccessRequest(req types.AccessRequest) map[string]any {
+	return map[string]any{
+		"id":              req.ID.String(),
+		"resource_id":     req.ResourceID.String(),
+		"role_id":         req.RoleID.String(),
+		"subject_id":      req.SubjectID.String(),
+		"reason":          req.Reason,
+		"status":          string(req.Status),
+		"decision_reason": req.DecisionReason,
+		"rolebinding_id":  req.RoleBindingID.String(),
+	}
+}
+
+// auditRoleBindingRelationships fills the role and subjects of a role-binding
+// from its relationships, relationships with unexpected IDs are skipped.
+func auditRoleBindingRelationships(rb types.RoleBinding, rels []*pb.Relationship) types.RoleBinding {
+	for _, rel := range rels {
+		id, err := gidx.Parse(rel.Subject.Object.ObjectId)
+		if err != nil {
+			continue
+		}
+
+		switch rel.Relation {
+		case iapl.RolebindingSubjectRelation:
+			rb.SubjectIDs = append(rb.SubjectIDs, id)
+		case iapl.RolebindingRoleRelation:
+			rb.RoleID = id
+		}
+	}
+
+	return rb
+}
+
+func auditRelationship(rel types.Relationship) map[string]any {
+	return map[string]any{
+		"resource_id": rel.Resource.ID.String(),
+		"relation":    rel.Relation,
+		"subject_id":  rel.Subject.ID.String(),
+	}
+}
+
+// publishRelationshipAuditEvents publishes an event for every relationship.
+func (e *engine) publishRelationshipAuditEvents(ctx context.Context, eventType string, rels []types.Relationship) {
+	for _, rel := range rels {
+		event := auditEvent{
+			eventType:  eventType,
+			subjectID:  rel.Resource.ID,
+			resourceID: rel.Resource.ID,
+			relatedIDs: []gidx.PrefixedID{rel.Subject.ID},
+		}
+
+		if eventType == AuditEventRelationshipDeleted {
+			event.before = auditRelationship(rel)
+		} else {
+			event.after = auditRelationship(rel)
+		}
+
+		e.publishAuditEvent(ctx, event)
+	}
+}
@@ -0,0 +1,202 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// testAuditPublisher records published events.
+type testAuditPublisher struct {
+	mu     sync.Mutex
+	events []events.EventMessage
+}
+
+func (p *testAuditPublisher) PublishChange(context.Context, string, events.ChangeMessage) (events.Message[events.ChangeMessage], error) {
+	return nil, nil
+}
+
+func (p *testAuditPublisher) PublishEvent(_ context.Context, _ string, msg events.EventMessage) (events.Message[events.EventMessage], error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, msg)
+
+	return nil, nil
+}
+
+// published returns the events published since the first n events.
+func (p *testAuditPublisher) published(n int) []events.EventMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.events[n:]
+}
+
+func TestAuditEvents(t *testing.T) {
+	namespace := "testaudit"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	publisher := &testAuditPublisher{}
+	WithAuditEvents(publisher, DefaultAuditTopic)(e)
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	child, err := e.NewResourceFromIDString("tnntten-child")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+	user, err := e.NewResourceFromIDString("idntusr-user")
+	require.NoError(t, err)
+
+	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
+		Updates: rbacV2CreateParentRel(tenant, child, e.namespace),
+	})
+	require.NoError(t, err)
+
+	var (
+		role    types.Role
+		roleRes types.Resource
+		rb      types.RoleBinding
+		rbRes   types.Resource
+	)
+
+	// requestCtx carries the actor and request ID as set by the API middleware.
+	requestCtx := ContextWithRequestID(context.WithValue(ctx, echojwtx.ActorCtxKey, actor.ID.String()), "request-id")
+
+	tc := []testingx.TestCase[func(context.Context) error, events.EventMessage]{
+		{
+			Name: "RoleCreated",
+			Sync: true,
+			Input: func(ctx context.Context) (err error) {
+				role, err = e.CreateRoleV2(ctx, actor, tenant, "lb_viewer", []string{"loadbalancer_get"})
+				if err == nil {
+					roleRes, err = e.NewResourceFromID(role.ID)
+				}
+
+				return err
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[events.EventMessage]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, AuditEventRoleCreated, res.Success.EventType)
+				assert.Equal(t, role.ID, res.Success.SubjectID)
+				assert.Equal(t, []gidx.PrefixedID{tenant.ID}, res.Success.AdditionalSubjectIDs)
+				assert.Equal(t, actor.ID.String(), res.Success.Data["actor"])
+				assert.Nil(t, res.Success.Data["before"])
+				assert.Equal(t, auditRole(role), res.Success.Data["after"])
+				assert.NotContains(t, res.Success.Data, "request_id")
+			},
+		},
+		{
+			Name: "RoleUpdated",
+			Sync: true,
+			Input: func(ctx context.Context) (err error) {
+				role, err = e.UpdateRoleV2(ctx, actor, roleRes, "lb_editor", []string{"loadbalancer_get", "loadbalancer_update"})
+
+				return err
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[events.EventMessage]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, AuditEventRoleUpdated, res.Success.EventType)
+
+				before, ok := res.Success.Data["before"].(map[string]any)
+				require.True(t, ok)
+				assert.Equal(t, "lb_viewer", before["name"])
+				assert.ElementsMatch(t, []string{"loadbalancer_get"}, before["actions"])
+
+				assert.Equal(t, auditRole(role), res.Success.Data["after"])
+			},
+		},
+		{
+			Name: "RoleBindingCreated",
+			Sync: true,
+			Input: func(ctx context.Context) (err error) {
+				rb, err = e.CreateRoleBinding(ctx, actor, child, roleRes, []types.RoleBindingSubject{{SubjectResource: user}})
+				if err == nil {
+					rbRes, err = e.NewResourceFromID(rb.ID)
+				}
+
+				return err
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[events.EventMessage]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, AuditEventRoleBindingCreated, res.Success.EventType)
+				assert.Equal(t, rb.ID, res.Success.SubjectID)
+				assert.Equal(t, []gidx.PrefixedID{child.ID, user.ID}, res.Success.AdditionalSubjectIDs)
+				assert.Equal(t, auditRoleBinding(rb), res.Success.Data["after"])
+			},
+		},
+		{
+			Name: "RoleBindingDeleted",
+			Sync: true,
+			Input: func(_ context.Context) error {
+				return e.DeleteRoleBinding(requestCtx, rbRes)
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[events.EventMessage]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, AuditEventRoleBindingDeleted, res.Success.EventType)
+				assert.Equal(t, actor.ID.String(), res.Success.Data["actor"])
+				assert.Equal(t, auditRoleBinding(rb), res.Success.Data["before"])
+				assert.Nil(t, res.Success.Data["after"])
+				assert.Equal(t, "request-id", res.Success.Data["request_id"])
+			},
+		},
+		{
+			Name: "RoleDeleted",
+			Sync: true,
+			Input: func(_ context.Context) error {
+				return e.DeleteRoleV2(requestCtx, roleRes)
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[events.EventMessage]) {
+				require.NoError(t, res.Err)
+
+				assert.Equal(t, AuditEventRoleDeleted, res.Success.EventType)
+
+				before, ok := res.Success.Data["before"].(map[string]any)
+				require.True(t, ok)
+				assert.Equal(t, role.ID.String(), before["id"])
+				assert.ElementsMatch(t, role.Actions, before["actions"])
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, fn func(context.Context) error) testingx.TestResult[events.EventMessage] {
+		n := len(publisher.published(0))
+
+		if err := fn(ctx); err != nil {
+			return testingx.TestResult[events.EventMessage]{Err: err}
+		}
+
+		published := publisher.published(n)
+		if len(published) != 1 {
+			return testingx.TestResult[events.EventMessage]{Err: fmt.Errorf("expected 1 event, got %d", len(published))}
+		}
+
+		return testingx.TestResult[events.EventMessage]{Success: published[0]}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+
+	// failed changes are not published
+	n := len(publisher.published(0))
+
+	err = e.DeleteRoleV2(ctx, roleRes)
+	require.Error(t, err)
+	assert.Empty(t, publisher.published(n))
+}
@@ -0,0 +1,50 @@
+package query
+
+import (
+	"context"
+	"sync"
+)
+
+// backgroundTasks runs the background goroutines of an engine, such as usage
+// tracking and the decision log, until the engine is stopped. It is shared by
+// the engines of a ReloadableEngine, whose policies replace each other.
+type backgroundTasks struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newBackgroundTasks() *backgroundTasks {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &backgroundTasks{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// run calls fn in a goroutine, fn must return once ctx is canceled.
+func (b *backgroundTasks) run(fn func(ctx context.Context)) {
+	b.wg.Add(1)
+
+	go func() {
+		defer b.wg.Done()
+
+		fn(b.ctx)
+	}()
+}
+
+// stop cancels the background goroutines and waits for them to return, they
+// flush what is pending before returning.
+func (b *backgroundTasks) stop() {
+	b.cancel()
+	b.wg.Wait()
+}
+
+// Stop stops the background goroutines of the engine, flushing the pending
+// usage and decisions. The engine must not be used once stopped.
+func (e *engine) Stop() error {
+	e.background.stop()
+
+	return nil
+}
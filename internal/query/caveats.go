@@ -0,0 +1,266 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+type caveatContextKey struct{}
+
+// ContextWithCaveatContext returns a context whose permission checks provide
+// the given values to SpiceDB for evaluating the caveats of role-bindings,
+// e.g. the IP address a request comes from.
+func ContextWithCaveatContext(ctx context.Context, values map[string]any) context.Context {
+	return context.WithValue(ctx, caveatContextKey{}, values)
+}
+
+// CaveatContextFromContext returns the caveat context of permission checks
+// made with ctx, nil if none was set.
+func CaveatContextFromContext(ctx context.Context) map[string]any {
+	values, _ := ctx.Value(caveatContextKey{}).(map[string]any)
+
+	return values
+}
+
+// checkCaveatContext returns the caveat context of permission checks made
+// with ctx as expected by SpiceDB, after validating it against the parameters
+// of the caveats of the policy.
+func (e *engine) checkCaveatContext(ctx context.Context) (*structpb.Struct, error) {
+	values := CaveatContextFromContext(ctx)
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	if err := e.validateCaveatContext(values); err != nil {
+		return nil, err
+	}
+
+	out, err := structpb.NewStruct(values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: caveat context: %s", ErrInvalidArgument, err)
+	}
+
+	return out, nil
+}
+
+// validateCaveatContext ensures every value of a permission check caveat
+// context is a parameter of a caveat of the policy, and matches the type the
+// parameter is declared with by one of the caveats.
+func (e *engine) validateCaveatContext(values map[string]any) error {
+	for name, value := range values {
+		var declared []string
+
+		for _, caveat := range e.caveats {
+			for _, param := range caveat.Parameters {
+				if param.Name == name {
+					declared = append(declared, param.Type)
+				}
+			}
+		}
+
+		if len(declared) == 0 {
+			return fmt.Errorf("%w: no caveat has a parameter %s", ErrInvalidCaveatContext, name)
+		}
+
+		if !slices.ContainsFunc(declared, func(typ string) bool { return caveatValueMatches(typ, value) }) {
+			return fmt.Errorf("%w: %s must be of type %s", ErrInvalidCaveatContext, name, strings.Join(declared, " or "))
+		}
+	}
+
+	return nil
+}
+
+// caveatValueMatches reports whether a JSON value can be used as a value of
+// the caveat parameter type, including the elements of lists and maps.
+func caveatValueMatches(typ string, value any) bool {
+	if elem, ok := strings.CutPrefix(typ, "list<"); ok {
+		list, isList := value.([]any)
+		if !isList {
+			return false
+		}
+
+		elem = strings.TrimSuffix(elem, ">")
+
+		for _, v := range list {
+			if !caveatValueMatches(elem, v) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if elem, ok := strings.CutPrefix(typ, "map<"); ok {
+		m, isMap := value.(map[string]any)
+		if !isMap {
+			return false
+		}
+
+		elem = strings.TrimSuffix(elem, ">")
+
+		for _, v := range m {
+			if !caveatValueMatches(elem, v) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	switch typ {
+	case "any":
+		return true
+	case "bool":
+		_, ok := value.(bool)
+
+		return ok
+	case "double":
+		_, ok := caveatNumber(value)
+
+		return ok
+	case "int":
+		n, ok := caveatNumber(value)
+
+		return ok && n == math.Trunc(n)
+	case "uint":
+		n, ok := caveatNumber(value)
+
+		return ok && n == math.Trunc(n) && n >= 0
+	case "string", "bytes":
+		_, ok := value.(string)
+
+		return ok
+	case "duration":
+		str, ok := value.(string)
+		if !ok {
+			return false
+		}
+
+		_, err := time.ParseDuration(str)
+
+		return err == nil
+	case "timestamp":
+		str, ok := value.(string)
+		if !ok {
+			return false
+		}
+
+		_, err := time.Parse(time.RFC3339, str)
+
+		return err == nil
+	case "ipaddress":
+		str, ok := value.(string)
+		if !ok {
+			return false
+		}
+
+		_, err := netip.ParseAddr(str)
+
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// caveatNumber returns the numeric value of a caveat context value, numbers
+// are decoded from JSON as float64 but may be set as integers by callers.
+func caveatNumber(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// validateRoleBindingCaveat ensures the caveat is defined by the policy and
+// its context only holds parameters of the caveat.
+func (e *engine) validateRoleBindingCaveat(caveat *types.RoleBindingCaveat) error {
+	if caveat == nil {
+		return nil
+	}
+
+	defined, ok := e.caveatMap[caveat.Name]
+	if !ok {
+		return fmt.Errorf("%w: caveat %s is not defined", ErrInvalidCaveat, caveat.Name)
+	}
+
+	for name, value := range caveat.Context {
+		idx := slices.IndexFunc(defined.Parameters, func(param types.CaveatParameter) bool {
+			return param.Name == name
+		})
+
+		if idx == -1 {
+			return fmt.Errorf("%w: caveat %s has no parameter %s", ErrInvalidCaveat, caveat.Name, name)
+		}
+
+		if typ := defined.Parameters[idx].Type; !caveatValueMatches(typ, value) {
+			return fmt.Errorf("%w: caveat %s parameter %s must be of type %s", ErrInvalidCaveat, caveat.Name, name, typ)
+		}
+	}
+
+	return nil
+}
+
+// spicedbCaveat converts a role-binding caveat to a SpiceDB caveat with its context.
+func (e *engine) spicedbCaveat(caveat *types.RoleBindingCaveat) (*pb.ContextualizedCaveat, error) {
+	if caveat == nil {
+		return nil, nil
+	}
+
+	out := &pb.ContextualizedCaveat{
+		CaveatName: e.namespaced(caveat.Name),
+	}
+
+	if len(caveat.Context) != 0 {
+		values, err := structpb.NewStruct(caveat.Context)
+		if err != nil {
+			return nil, fmt.Errorf("%w: caveat %s: %s", ErrInvalidCaveat, caveat.Name, err)
+		}
+
+		out.Context = values
+	}
+
+	return out, nil
+}
+
+// roleBindingCaveat converts a SpiceDB caveat back to a role-binding caveat.
+func (e *engine) roleBindingCaveat(caveat *pb.ContextualizedCaveat) *types.RoleBindingCaveat {
+	if caveat == nil {
+		return nil
+	}
+
+	out := &types.RoleBindingCaveat{
+		Name: strings.TrimPrefix(caveat.CaveatName, e.namespace+"/"),
+	}
+
+	if caveat.Context != nil {
+		out.Context = caveat.Context.AsMap()
+	}
+
+	return out
+}
@@ -0,0 +1,167 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func caveatTestPolicy() iapl.Policy {
+	doc := DefaultPolicyDocumentV2()
+	doc.Caveats = []iapl.Caveat{
+		{
+			Name: "business_hours",
+			Parameters: []types.CaveatParameter{
+				{Name: "hour", Type: "int"},
+				{Name: "max_hour", Type: "int"},
+			},
+			Expression: "hour <= max_hour",
+		},
+	}
+
+	p := iapl.NewPolicy(doc)
+	if err := p.Validate(); err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+func TestRoleBindingCaveats(t *testing.T) {
+	namespace := "testcaveats"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, caveatTestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+	user, err := e.NewResourceFromIDString("idntusr-user")
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, actor, tenant, "lb_viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
+	roleRes, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	subjects := []types.RoleBindingSubject{{SubjectResource: user}}
+
+	tc := []testingx.TestCase[types.RoleBindingCaveat, types.RoleBinding]{
+		{
+			Name:  "UndefinedCaveat",
+			Input: types.RoleBindingCaveat{Name: "weekdays"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidCaveat)
+			},
+		},
+		{
+			Name: "UnknownParameter",
+			Input: types.RoleBindingCaveat{
+				Name:    "business_hours",
+				Context: map[string]any{"min_hour": 8},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidCaveat)
+			},
+		},
+		{
+			Name: "InvalidParameterType",
+			Input: types.RoleBindingCaveat{
+				Name:    "business_hours",
+				Context: map[string]any{"max_hour": "five"},
+			},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidCaveat)
+			},
+		},
+		{
+			Name: "Success",
+			Input: types.RoleBindingCaveat{
+				Name:    "business_hours",
+				Context: map[string]any{"max_hour": 17},
+			},
+			Sync: true,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+				require.NoError(t, res.Err)
+				require.NotNil(t, res.Success.Caveat)
+				assert.Equal(t, "business_hours", res.Success.Caveat.Name)
+
+				rbRes, err := e.NewResourceFromID(res.Success.ID)
+				require.NoError(t, err)
+
+				rb, err := e.GetRoleBinding(ctx, rbRes)
+				require.NoError(t, err)
+				require.NotNil(t, rb.Caveat)
+				assert.Equal(t, "business_hours", rb.Caveat.Name)
+				assert.EqualValues(t, 17, rb.Caveat.Context["max_hour"])
+
+				err = e.SubjectHasPermission(ContextWithCaveatContext(ctx, map[string]any{"hour": 9}), user, "loadbalancer_get", tenant)
+				assert.NoError(t, err)
+
+				err = e.SubjectHasPermission(ContextWithCaveatContext(ctx, map[string]any{"hour": 20}), user, "loadbalancer_get", tenant)
+				assert.ErrorIs(t, err, ErrActionNotAssigned)
+
+				// without the hour the caveat can't be evaluated
+				err = e.SubjectHasPermission(ctx, user, "loadbalancer_get", tenant)
+				assert.ErrorIs(t, err, ErrActionNotAssigned)
+
+				// context values are validated against the caveat parameters
+				err = e.SubjectHasPermission(ContextWithCaveatContext(ctx, map[string]any{"hour": "nine"}), user, "loadbalancer_get", tenant)
+				assert.ErrorIs(t, err, ErrInvalidCaveatContext)
+
+				err = e.SubjectHasPermission(ContextWithCaveatContext(ctx, map[string]any{"region": "eu"}), user, "loadbalancer_get", tenant)
+				assert.ErrorIs(t, err, ErrInvalidCaveatContext)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, caveat types.RoleBindingCaveat) testingx.TestResult[types.RoleBinding] {
+		rb, err := e.CreateRoleBindingWithCaveat(ctx, actor, tenant, roleRes, subjects, caveat)
+
+		return testingx.TestResult[types.RoleBinding]{Success: rb, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
+
+func TestCaveatValueMatches(t *testing.T) {
+	testCases := []struct {
+		typ     string
+		value   any
+		matches bool
+	}{
+		{"int", float64(9), true},
+		{"int", 9, true},
+		{"int", 9.5, false},
+		{"int", "9", false},
+		{"uint", float64(-1), false},
+		{"double", 9.5, true},
+		{"bool", true, true},
+		{"bool", "true", false},
+		{"string", "eu-west", true},
+		{"duration", "1h30m", true},
+		{"duration", "90 minutes", false},
+		{"timestamp", "2024-01-02T03:04:05Z", true},
+		{"timestamp", "yesterday", false},
+		{"ipaddress", "10.1.2.3", true},
+		{"ipaddress", "::1", true},
+		{"ipaddress", "10.1.2.0/24", false},
+		{"list<string>", []any{"10.0.0.0/8"}, true},
+		{"list<string>", []any{"10.0.0.0/8", float64(1)}, false},
+		{"list<string>", "10.0.0.0/8", false},
+		{"map<int>", map[string]any{"a": float64(1)}, true},
+		{"map<int>", map[string]any{"a": "1"}, false},
+		{"any", nil, true},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.matches, caveatValueMatches(tc.typ, tc.value), "%s %v", tc.typ, tc.value)
+	}
+}
@@ -0,0 +1,94 @@
+package query
+
+import (
+	"context"
+	"strings"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// WatchRelationshipChanges calls fn for every relationship of the engine's
+// namespace created or deleted after the given revision, or after the current
+// revision when none is given. It returns once the context is done, fn fails
+// or the watch fails; callers resume from the ZedToken of the last change.
+func (e *engine) WatchRelationshipChanges(ctx context.Context, after string, fn func(types.RelationshipChange) error) error {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.WatchRelationshipChanges",
+		trace.WithAttributes(
+			attribute.String("after", after),
+		),
+	)
+	defer span.End()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req := &pb.WatchRequest{}
+
+	if after != "" {
+		req.OptionalStartCursor = &pb.ZedToken{Token: after}
+	}
+
+	stream, err := e.client.Watch(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return err
+		}
+
+		for _, update := range resp.Updates {
+			change, ok := e.relationshipChange(update, resp.ChangesThrough)
+			if !ok {
+				continue
+			}
+
+			if err := fn(change); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// relationshipChange converts a SpiceDB relationship update, updates of
+// other namespaces are skipped.
+func (e *engine) relationshipChange(update *pb.RelationshipUpdate, revision *pb.ZedToken) (types.RelationshipChange, bool) {
+	rel := update.GetRelationship()
+	if rel == nil || !strings.HasPrefix(rel.Resource.ObjectType, e.namespace+"/") {
+		return types.RelationshipChange{}, false
+	}
+
+	change := types.RelationshipChange{
+		Relationship: e.relationshipRecord(rel),
+		ZedToken:     revision.GetToken(),
+	}
+
+	switch update.Operation {
+	case pb.RelationshipUpdate_OPERATION_CREATE, pb.RelationshipUpdate_OPERATION_TOUCH:
+		change.Operation = types.RelationshipChangeCreated
+	case pb.RelationshipUpdate_OPERATION_DELETE:
+		change.Operation = types.RelationshipChangeDeleted
+	default:
+		return types.RelationshipChange{}, false
+	}
+
+	return change, true
+}
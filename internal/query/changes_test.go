@@ -0,0 +1,86 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestWatchRelationshipChanges(t *testing.T) {
+	namespace := "testwatchrelationshipchanges"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	child, err := e.NewResourceFromIDString("tnntten-child")
+	require.NoError(t, err)
+
+	schema, err := e.client.ReadSchema(ctx, &pb.ReadSchemaRequest{})
+	require.NoError(t, err)
+
+	rel := types.Relationship{Resource: child, Relation: "parent", Subject: root}
+
+	require.NoError(t, e.CreateRelationships(ctx, []types.Relationship{rel}))
+	require.NoError(t, e.DeleteRelationships(ctx, rel))
+
+	errDone := errors.New("done")
+
+	var changes []types.RelationshipChange
+
+	err = e.WatchRelationshipChanges(ctx, schema.ReadAt.Token, func(change types.RelationshipChange) error {
+		changes = append(changes, change)
+
+		if len(changes) == 2 {
+			return errDone
+		}
+
+		return nil
+	})
+	require.ErrorIs(t, err, errDone)
+
+	expected := types.RelationshipRecord{
+		ResourceType: "tenant",
+		ResourceID:   "tnntten-child",
+		Relation:     "parent",
+		SubjectType:  "tenant",
+		SubjectID:    "tnntten-root",
+	}
+
+	assert.Equal(t, types.RelationshipChangeCreated, changes[0].Operation)
+	assert.Equal(t, expected, changes[0].Relationship)
+	assert.NotEmpty(t, changes[0].ZedToken)
+
+	assert.Equal(t, types.RelationshipChangeDeleted, changes[1].Operation)
+	assert.Equal(t, expected, changes[1].Relationship)
+	assert.NotEqual(t, changes[0].ZedToken, changes[1].ZedToken)
+}
+
+func TestRelationshipChangeOtherNamespace(t *testing.T) {
+	e := &engine{namespace: "permissions"}
+
+	update := &pb.RelationshipUpdate{
+		Operation: pb.RelationshipUpdate_OPERATION_TOUCH,
+		Relationship: &pb.Relationship{
+			Resource: &pb.ObjectReference{ObjectType: "permissions/tenant", ObjectId: "tnntten-child"},
+			Relation: "parent",
+			Subject:  &pb.SubjectReference{Object: &pb.ObjectReference{ObjectType: "permissions/tenant", ObjectId: "tnntten-root"}},
+		},
+	}
+
+	change, ok := e.relationshipChange(update, &pb.ZedToken{Token: "revision"})
+	require.True(t, ok)
+	assert.Equal(t, types.RelationshipChangeCreated, change.Operation)
+	assert.Equal(t, "revision", change.ZedToken)
+
+	update.Relationship.Resource.ObjectType = "other/tenant"
+
+	_, ok = e.relationshipChange(update, &pb.ZedToken{Token: "revision"})
+	assert.False(t, ok)
+}
@@ -0,0 +1,217 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// DefaultCheckCacheTTL is the default maximum time a permission check result is cached for.
+	DefaultCheckCacheTTL = time.Minute
+
+	// DefaultCheckCacheSize is the default maximum number of cached permission check results.
+	DefaultCheckCacheSize = 10000
+
+	// checkCacheReconnectInterval is the time waited before watching SpiceDB again after the watch failed.
+	checkCacheReconnectInterval = 5 * time.Second
+)
+
+// CheckCacheConfig configures caching permission check results.
+type CheckCacheConfig struct {
+	// Enabled enables caching permission check results.
+	Enabled bool
+	// TTL is the maximum time a result is cached for.
+	TTL time.Duration
+	// Size is the maximum number of cached results.
+	Size int
+}
+
+// checkCache caches the results of permission checks for as long as no
+// relationship changed, which is learned by watching SpiceDB. Every change
+// starts a new generation, dropping all cached results, as a change may affect
+// any permission through the relationship graph. Cached checks are evaluated
+// at least as fresh as the last watched revision, so a cached result is never
+// older than the last change seen. While SpiceDB is not being watched nothing
+// is cached.
+type checkCache struct {
+	decisions *decisionCache
+
+	mu         sync.Mutex
+	generation uint64
+	// revision is the last watched revision, nil while not watching.
+	revision *pb.ZedToken
+}
+
+func newCheckCache(config CheckCacheConfig) *checkCache {
+	ttl := config.TTL
+	if ttl <= 0 {
+		ttl = DefaultCheckCacheTTL
+	}
+
+	size := config.Size
+	if size <= 0 {
+		size = DefaultCheckCacheSize
+	}
+
+	return &checkCache{
+		decisions: newDecisionCache(ttl, size),
+	}
+}
+
+// window returns the current generation and revision, the revision is nil
+// when results must not be cached.
+func (c *checkCache) window() (uint64, *pb.ZedToken) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation, c.revision
+}
+
+// store caches a result if no relationship changed since the check started.
+func (c *checkCache) store(key decisionKey, allowed bool, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.revision == nil || generation != c.generation {
+		return
+	}
+
+	c.decisions.set(key, allowed)
+}
+
+// invalidate drops all cached results and starts a new generation at the
+// given revision, a nil revision disables caching until the next one.
+func (c *checkCache) invalidate(revision *pb.ZedToken) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.revision = revision
+
+	c.decisions.clear()
+}
+
+// reset drops all cached results, keeping the watched revision.
+func (c *checkCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	c.decisions.clear()
+}
+
+// cachedCheckPermission checks a permission, serving the result from the
+// check cache when possible. Checks requiring a specific consistency, e.g.
+// after the resource was updated or when the caller passed a consistency
+// token, bypass the cache, as do checks with a caveat context. The revision
+// the result is at least as fresh as is returned with it.
+func (e *engine) cachedCheckPermission(
+	ctx context.Context,
+	req *pb.CheckPermissionRequest,
+	consistencyName string,
+	subject types.Resource,
+	action string,
+	resource types.Resource,
+) (*pb.ZedToken, error) {
+	if e.checkCache == nil || consistencyName != consistencyMinimizeLatency || spicedbx.ConsistencyFromContext(ctx).AtLeastAsFresh() != "" || req.Context != nil {
+		return e.checkPermissionAt(ctx, req)
+	}
+
+	generation, revision := e.checkCache.window()
+	if revision == nil {
+		return e.checkPermissionAt(ctx, req)
+	}
+
+	key := newDecisionKey(subject, action, resource)
+
+	if allowed, ok := e.checkCache.decisions.get(key); ok {
+		checkCacheLookups.WithLabelValues("hit").Inc()
+
+		if allowed {
+			return revision, nil
+		}
+
+		return revision, ErrActionNotAssigned
+	}
+
+	checkCacheLookups.WithLabelValues("miss").Inc()
+
+	req.Consistency = &pb.Consistency{
+		Requirement: &pb.Consistency_AtLeastAsFresh{
+			AtLeastAsFresh: revision,
+		},
+	}
+
+	checkedAt, err := e.checkPermissionAt(ctx, req)
+
+	switch {
+	case err == nil:
+		e.checkCache.store(key, true, generation)
+	case errors.Is(err, ErrActionNotAssigned):
+		e.checkCache.store(key, false, generation)
+	}
+
+	return checkedAt, err
+}
+
+// watchRelationships invalidates the check cache on every relationship change
+// until ctx is canceled, watching again after a failure.
+func (e *engine) watchRelationships(ctx context.Context) {
+	for {
+		err := e.watchRelationshipChanges(ctx)
+
+		e.checkCache.invalidate(nil)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		e.logger.Warnw("watching spicedb relationship changes failed, check cache disabled", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(checkCacheReconnectInterval):
+		}
+	}
+}
+
+// watchRelationshipChanges watches relationship changes starting at the
+// current revision until the watch fails.
+func (e *engine) watchRelationshipChanges(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	schema, err := e.client.ReadSchema(ctx, &pb.ReadSchemaRequest{})
+	if err != nil {
+		return err
+	}
+
+	stream, err := e.client.Watch(ctx, &pb.WatchRequest{
+		OptionalStartCursor: schema.ReadAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	e.checkCache.invalidate(schema.ReadAt)
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		if len(resp.Updates) != 0 {
+			e.checkCache.invalidate(resp.ChangesThrough)
+		}
+	}
+}
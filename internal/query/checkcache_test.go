@@ -0,0 +1,59 @@
+package query
+
+import (
+	"testing"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCache(t *testing.T) {
+	t.Parallel()
+
+	cache := newCheckCache(CheckCacheConfig{Enabled: true})
+
+	key := decisionKey{subject: "idntusr-subject", action: "loadbalancer_get", resource: "tnntten-resource"}
+
+	// nothing is cached before watching
+	generation, revision := cache.window()
+	assert.Nil(t, revision)
+
+	cache.store(key, true, generation)
+
+	_, ok := cache.decisions.get(key)
+	assert.False(t, ok)
+
+	cache.invalidate(&pb.ZedToken{Token: "first"})
+
+	generation, revision = cache.window()
+	require.NotNil(t, revision)
+	assert.Equal(t, "first", revision.Token)
+
+	cache.store(key, true, generation)
+
+	allowed, ok := cache.decisions.get(key)
+	assert.True(t, ok)
+	assert.True(t, allowed)
+
+	// a change drops all cached results
+	cache.invalidate(&pb.ZedToken{Token: "second"})
+
+	_, ok = cache.decisions.get(key)
+	assert.False(t, ok)
+
+	// results of checks started before the change are not cached
+	cache.store(key, false, generation)
+
+	_, ok = cache.decisions.get(key)
+	assert.False(t, ok)
+
+	// nothing is cached once the watch failed
+	generation, _ = cache.window()
+
+	cache.invalidate(nil)
+	cache.store(key, false, generation+1)
+
+	_, ok = cache.decisions.get(key)
+	assert.False(t, ok)
+}
@@ -0,0 +1,121 @@
+package query
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// DeleteResource removes everything left behind by a deleted resource: the
+// role-bindings on the resource, the roles and groups it owns, including the
+// role-bindings on other resources using those roles, and all relationships
+// the resource is either the resource or the subject of.
+// Deleting a resource which was already cleaned up is not an error.
+func (e *engine) DeleteResource(ctx context.Context, resource types.Resource) error {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.DeleteResource",
+		trace.WithAttributes(attribute.Stringer("resource_id", resource.ID)),
+	)
+	defer span.End()
+
+	fail := func(err error) error {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	// 1. role-bindings on the resource
+	if resourceHasRoleBindingV2(e.schemaTypeMap[resource.Type]) != nil {
+		rbs, err := e.ListRoleBindings(ctx, resource, nil)
+		if err != nil {
+			return fail(err)
+		}
+
+		for _, rb := range rbs {
+			if err := e.deleteRoleBindingIfExists(ctx, rb.ID.String()); err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	// 2. roles owned by the resource
+	roles, err := e.store.ListResourceRoles(ctx, resource.ID)
+	if err != nil {
+		return fail(err)
+	}
+
+	for _, dbRole := range roles {
+		roleResource, err := e.NewResourceFromID(dbRole.ID)
+		if err != nil {
+			return fail(err)
+		}
+
+		if roleResource.Type != e.rbac.RoleResource.Name {
+			err = e.DeleteRole(ctx, roleResource)
+		} else {
+			_, err = e.ForceDeleteRoleV2(ctx, roleResource)
+		}
+
+		if err != nil && !errors.Is(err, ErrRoleNotFound) && !errors.Is(err, storage.ErrNoRoleFound) {
+			return fail(err)
+		}
+	}
+
+	// 3. groups owned by the resource
+	groups, err := e.store.ListOwnerGroups(ctx, resource.ID)
+	if err != nil {
+		return fail(err)
+	}
+
+	for _, group := range groups {
+		if err := e.DeleteGroup(ctx, group.ID); err != nil && !errors.Is(err, ErrGroupNotFound) {
+			return fail(err)
+		}
+	}
+
+	// 4. relationships from and to the resource
+	if err := e.DeleteResourceRelationships(ctx, resource); err != nil {
+		return fail(err)
+	}
+
+	for _, resTypes := range e.schemaSubjectRelationMap[resource.Type] {
+		for _, resType := range resTypes {
+			err := e.deleteRelationships(ctx, &pb.RelationshipFilter{
+				ResourceType: e.namespaced(resType),
+				OptionalSubjectFilter: &pb.SubjectFilter{
+					SubjectType:       e.namespaced(resource.Type),
+					OptionalSubjectId: resource.ID.String(),
+				},
+			})
+			if err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// deleteRoleBindingIfExists deletes a role-binding, ignoring role-bindings
+// which no longer exist.
+func (e *engine) deleteRoleBindingIfExists(ctx context.Context, id string) error {
+	rb, err := e.NewResourceFromIDString(id)
+	if err != nil {
+		return err
+	}
+
+	err = e.DeleteRoleBinding(ctx, rb)
+	if err != nil && !errors.Is(err, ErrRoleBindingNotFound) && !errors.Is(err, storage.ErrRoleBindingNotFound) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestDeleteResource(t *testing.T) {
+	namespace := "testcleanup"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	child, err := e.NewResourceFromIDString("tnntten-child")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
+		Updates: rbacV2CreateParentRel(root, child, e.namespace),
+	})
+	require.NoError(t, err)
+
+	rootRole, err := e.CreateRoleV2(ctx, actor, root, "lb_viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+	rootRoleRes, err := e.NewResourceFromID(rootRole.ID)
+	require.NoError(t, err)
+
+	childRole, err := e.CreateRoleV2(ctx, actor, child, "lb_editor", []string{"loadbalancer_update"})
+	require.NoError(t, err)
+	childRoleRes, err := e.NewResourceFromID(childRole.ID)
+	require.NoError(t, err)
+
+	rootRB, err := e.CreateRoleBinding(ctx, actor, root, rootRoleRes, []types.RoleBindingSubject{{SubjectResource: actor}})
+	require.NoError(t, err)
+
+	childRB, err := e.CreateRoleBinding(ctx, actor, child, rootRoleRes, []types.RoleBindingSubject{{SubjectResource: actor}})
+	require.NoError(t, err)
+
+	group, err := e.CreateGroup(ctx, actor, child, "editors", "")
+	require.NoError(t, err)
+
+	require.NoError(t, e.DeleteResource(ctx, child))
+
+	// role-bindings on the resource are deleted
+	_, err = e.GetRoleBinding(ctx, types.Resource{Type: e.rbac.RoleBindingResource.Name, ID: childRB.ID})
+	assert.ErrorIs(t, err, ErrRoleBindingNotFound)
+
+	// roles and groups owned by the resource are deleted
+	_, err = e.GetRoleV2(ctx, childRoleRes)
+	assert.Error(t, err)
+
+	_, err = e.GetGroup(ctx, group.ID)
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+
+	// relationships from and to the resource are deleted
+	from, err := e.ListRelationshipsFrom(ctx, child)
+	require.NoError(t, err)
+	assert.Empty(t, from)
+
+	to, err := e.ListRelationshipsTo(ctx, child)
+	require.NoError(t, err)
+	assert.Empty(t, to)
+
+	// roles and role-bindings of other resources are kept
+	_, err = e.GetRoleV2(ctx, rootRoleRes)
+	assert.NoError(t, err)
+
+	_, err = e.GetRoleBinding(ctx, types.Resource{Type: e.rbac.RoleBindingResource.Name, ID: rootRB.ID})
+	assert.NoError(t, err)
+
+	// cleaning up again does nothing
+	assert.NoError(t, e.DeleteResource(ctx, child))
+}
@@ -0,0 +1,228 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// DefaultDecisionLogTopic is the default topic decisions are published to by the events sink.
+	DefaultDecisionLogTopic = "permissions-decisions"
+
+	// DecisionLogSinkEvents publishes decisions as events.
+	DecisionLogSinkEvents = "events"
+	// DecisionLogSinkFile writes decisions to a file as JSON lines.
+	DecisionLogSinkFile = "file"
+	// DecisionLogSinkDB stores decisions in the decision_log table.
+	DecisionLogSinkDB = "db"
+
+	// decisionEventType is the event type of published decisions.
+	decisionEventType = "permission_decision"
+
+	// decisionLogBufferSize is the number of decisions which may be queued
+	// before new decisions are dropped.
+	decisionLogBufferSize = 4096
+
+	// decisionLogBatchSize is the maximum number of decisions written to the sink at once.
+	decisionLogBatchSize = 500
+
+	decisionLogFlushInterval = time.Second
+	decisionLogFlushTimeout  = 10 * time.Second
+)
+
+// DecisionLogConfig configures recording the outcome of permission checks.
+type DecisionLogConfig struct {
+	// Enabled enables the decision log.
+	Enabled bool
+	// Sink is where decisions are recorded: events, file or db.
+	Sink string
+	// Topic is the topic decisions are published to by the events sink.
+	Topic string
+	// File is the path of the file decisions are appended to by the file sink.
+	File string
+	// SampleRate is the fraction of allowed decisions recorded, between 0 and 1.
+	// Denied and failed decisions are always recorded.
+	SampleRate float64
+}
+
+// DecisionSink records permission check decisions.
+type DecisionSink interface {
+	WriteDecisions(ctx context.Context, decisions []types.Decision) error
+}
+
+// decisionLog records decisions to a sink. Decisions are queued without
+// blocking permission checks and written in batches in the background, so
+// the log may lag checks by the flush interval.
+type decisionLog struct {
+	sink       DecisionSink
+	sampleRate float64
+	decisions  chan types.Decision
+}
+
+// logDecision queues a decision, filling in the request ID from the context.
+// Allowed decisions are sampled, and decisions are dropped if the queue is full.
+func (e *engine) logDecision(ctx context.Context, decision types.Decision) {
+	if e.decisions == nil {
+		return
+	}
+
+	if decision.Outcome == outcomeAllowed && e.decisions.sampleRate < 1 && rand.Float64() >= e.decisions.sampleRate { //nolint:gosec // sampling needs no secure randomness
+		return
+	}
+
+	decision.RequestID = RequestIDFromContext(ctx)
+
+	select {
+	case e.decisions.decisions <- decision:
+	default:
+		decisionsDropped.Inc()
+
+		e.contextLogger(ctx).Debugw("decision log queue full, dropping decision",
+			"subject", decision.SubjectID,
+			"action", decision.Action,
+			"resource", decision.ResourceID,
+		)
+	}
+}
+
+// runDecisionLog writes queued decisions to the sink until ctx is canceled,
+// the decisions queued then are written before returning.
+func (e *engine) runDecisionLog(ctx context.Context) {
+	ticker := time.NewTicker(decisionLogFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]types.Decision, 0, decisionLogBatchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		flushCtx, cancel := context.WithTimeout(context.Background(), decisionLogFlushTimeout)
+		defer cancel()
+
+		if err := e.decisions.sink.WriteDecisions(flushCtx, batch); err != nil {
+			decisionsDropped.Add(float64(len(batch)))
+
+			e.logger.Errorw("failed to write decisions", "decisions", len(batch), "error", err)
+		}
+
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case decision := <-e.decisions.decisions:
+			batch = append(batch, decision)
+
+			if len(batch) >= decisionLogBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			for len(e.decisions.decisions) != 0 {
+				if batch = append(batch, <-e.decisions.decisions); len(batch) >= decisionLogBatchSize {
+					flush()
+				}
+			}
+
+			flush()
+
+			return
+		}
+	}
+}
+
+// decisionData returns the fields of a decision as recorded by the events and file sinks.
+func decisionData(d types.Decision) map[string]any {
+	return map[string]any{
+		"subject_id":  d.SubjectID.String(),
+		"resource_id": d.ResourceID.String(),
+		"action":      d.Action,
+		"outcome":     d.Outcome,
+		"latency_ms":  float64(d.Latency) / float64(time.Millisecond),
+		"zedtoken":    d.ZedToken,
+		"request_id":  d.RequestID,
+		"decided_at":  d.DecidedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+type eventsDecisionSink struct {
+	publisher events.Publisher
+	topic     string
+}
+
+// NewEventsDecisionSink returns a sink publishing every decision as an event to the topic.
+func NewEventsDecisionSink(publisher events.Publisher, topic string) DecisionSink {
+	return &eventsDecisionSink{
+		publisher: publisher,
+		topic:     topic,
+	}
+}
+
+func (s *eventsDecisionSink) WriteDecisions(ctx context.Context, decisions []types.Decision) error {
+	for _, d := range decisions {
+		msg := events.EventMessage{
+			SubjectID:            d.SubjectID,
+			EventType:            decisionEventType,
+			AdditionalSubjectIDs: []gidx.PrefixedID{d.ResourceID},
+			Timestamp:            d.DecidedAt.UTC(),
+			Data:                 decisionData(d),
+		}
+
+		if _, err := s.publisher.PublishEvent(ctx, s.topic, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type fileDecisionSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewFileDecisionSink returns a sink writing every decision to w as a line of JSON.
+func NewFileDecisionSink(w io.Writer) DecisionSink {
+	return &fileDecisionSink{w: w}
+}
+
+func (s *fileDecisionSink) WriteDecisions(_ context.Context, decisions []types.Decision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enc := json.NewEncoder(s.w)
+
+	for _, d := range decisions {
+		if err := enc.Encode(decisionData(d)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type storageDecisionSink struct {
+	store storage.DecisionLogService
+}
+
+// NewStorageDecisionSink returns a sink storing decisions in the decision_log table.
+func NewStorageDecisionSink(store storage.DecisionLogService) DecisionSink {
+	return &storageDecisionSink{store: store}
+}
+
+func (s *storageDecisionSink) WriteDecisions(ctx context.Context, decisions []types.Decision) error {
+	return s.store.RecordDecisions(ctx, decisions...)
+}
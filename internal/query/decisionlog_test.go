@@ -0,0 +1,154 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestDecisionLog(t *testing.T) {
+	namespace := "testdecisionlog"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+	user, err := e.NewResourceFromIDString("idntusr-user")
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, actor, tenant, "lb_viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
+	roleRes, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	_, err = e.CreateRoleBinding(ctx, actor, tenant, roleRes, []types.RoleBindingSubject{{SubjectResource: user}})
+	require.NoError(t, err)
+
+	type testInput struct {
+		sampleRate float64
+		action     string
+	}
+
+	tc := []testingx.TestCase[testInput, []types.Decision]{
+		{
+			Name:  "Allowed",
+			Input: testInput{sampleRate: 1, action: "loadbalancer_get"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Decision]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 1)
+
+				d := res.Success[0]
+				assert.Equal(t, user.ID, d.SubjectID)
+				assert.Equal(t, tenant.ID, d.ResourceID)
+				assert.Equal(t, "loadbalancer_get", d.Action)
+				assert.Equal(t, outcomeAllowed, d.Outcome)
+				assert.NotEmpty(t, d.ZedToken)
+				assert.Equal(t, "request-id", d.RequestID)
+				assert.Positive(t, d.Latency)
+			},
+		},
+		{
+			Name:  "AllowedNotSampled",
+			Input: testInput{sampleRate: 0, action: "loadbalancer_get"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Decision]) {
+				assert.Empty(t, res.Success)
+			},
+		},
+		{
+			Name:  "DeniedAlwaysRecorded",
+			Input: testInput{sampleRate: 0, action: "loadbalancer_delete"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Decision]) {
+				require.Len(t, res.Success, 1)
+				assert.Equal(t, outcomeDenied, res.Success[0].Outcome)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, input testInput) testingx.TestResult[[]types.Decision] {
+		// each case logs to its own queue, which is read directly rather than
+		// written to a sink.
+		next := *e
+		WithDecisionLog(NewFileDecisionSink(&bytes.Buffer{}), input.sampleRate)(&next)
+
+		ctx = ContextWithRequestID(ctx, "request-id")
+
+		_ = next.SubjectHasPermission(ctx, user, input.action, tenant)
+
+		close(next.decisions.decisions)
+
+		var out []types.Decision
+
+		for d := range next.decisions.decisions {
+			out = append(out, d)
+		}
+
+		return testingx.TestResult[[]types.Decision]{Success: out}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
+
+func TestDecisionLogStop(t *testing.T) {
+	namespace := "testdecisionlogstop"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	user, err := e.NewResourceFromIDString("idntusr-user")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	logged, err := NewEngine(e.namespace, e.client, e.store, WithPolicy(rbacv2TestPolicy()), WithDecisionLog(NewFileDecisionSink(&buf), 1))
+	require.NoError(t, err)
+
+	_ = logged.SubjectHasPermission(ctx, user, "loadbalancer_get", tenant)
+
+	// decisions queued when the engine is stopped are written before it returns
+	require.NoError(t, logged.Stop())
+
+	var decision map[string]any
+
+	require.NoError(t, json.NewDecoder(&buf).Decode(&decision))
+	assert.Equal(t, "loadbalancer_get", decision["action"])
+	assert.Equal(t, outcomeDenied, decision["outcome"])
+}
+
+func TestFileDecisionSink(t *testing.T) {
+	var buf bytes.Buffer
+
+	sink := NewFileDecisionSink(&buf)
+
+	decidedAt := time.Date(2024, 7, 10, 12, 0, 0, 0, time.UTC)
+
+	err := sink.WriteDecisions(context.Background(), []types.Decision{
+		{SubjectID: "idntusr-user", ResourceID: "tnntten-root", Action: "loadbalancer_get", Outcome: outcomeAllowed, Latency: 2 * time.Millisecond, DecidedAt: decidedAt},
+		{SubjectID: "idntusr-user", ResourceID: "tnntten-root", Action: "loadbalancer_delete", Outcome: outcomeDenied, DecidedAt: decidedAt},
+	})
+	require.NoError(t, err)
+
+	dec := json.NewDecoder(&buf)
+
+	var first, second map[string]any
+
+	require.NoError(t, dec.Decode(&first))
+	require.NoError(t, dec.Decode(&second))
+
+	assert.Equal(t, "loadbalancer_get", first["action"])
+	assert.Equal(t, outcomeAllowed, first["outcome"])
+	assert.EqualValues(t, 2, first["latency_ms"])
+	assert.Equal(t, "2024-07-10T12:00:00Z", first["decided_at"])
+	assert.Equal(t, outcomeDenied, second["outcome"])
+}
@@ -33,6 +33,11 @@ func DefaultPolicyDocumentV2() iapl.PolicyDocument {
 				{Name: "client"},
 				{Name: "group", SubjectRelation: "member"},
 			},
+			GroupResource: &iapl.RBACGroupDefinition{
+				Name:           "group",
+				OwnerRelation:  "parent",
+				MemberRelation: "member",
+			},
 		},
 		Unions: []iapl.Union{
 			{
@@ -138,6 +143,18 @@ func DefaultPolicyDocumentV2() iapl.PolicyDocument {
 			{Name: "loadbalancer_list"},
 			{Name: "loadbalancer_update"},
 			{Name: "loadbalancer_delete"},
+			{Name: "iam_group_create"},
+			{Name: "iam_group_get"},
+			{Name: "iam_group_list"},
+			{Name: "iam_group_update"},
+			{Name: "iam_group_delete"},
+		},
+		ActionGroups: []iapl.ActionGroup{
+			{
+				Name:        "loadbalancer_viewer",
+				Description: "Read-only access to load balancers",
+				Actions:     []string{"loadbalancer_get", "loadbalancer_list"},
+			},
 		},
 		ActionBindings: []iapl.ActionBinding{
 			{
@@ -291,6 +308,56 @@ func DefaultPolicyDocumentV2() iapl.PolicyDocument {
 				TypeName:   "group",
 				Conditions: []iapl.Condition{rbv2WithInheritFromParent},
 			},
+			{
+				ActionName: "iam_group_create",
+				TypeName:   "resourceowner",
+				Conditions: []iapl.Condition{rbv2WithInheritFromParent},
+			},
+			{
+				ActionName: "iam_group_create",
+				TypeName:   "group",
+				Conditions: []iapl.Condition{rbv2WithInheritFromParent},
+			},
+			{
+				ActionName: "iam_group_get",
+				TypeName:   "resourceowner",
+				Conditions: []iapl.Condition{rbv2WithInheritFromParent},
+			},
+			{
+				ActionName: "iam_group_get",
+				TypeName:   "group",
+				Conditions: []iapl.Condition{rbv2WithInheritFromParent},
+			},
+			{
+				ActionName: "iam_group_list",
+				TypeName:   "resourceowner",
+				Conditions: []iapl.Condition{rbv2WithInheritFromParent},
+			},
+			{
+				ActionName: "iam_group_list",
+				TypeName:   "group",
+				Conditions: []iapl.Condition{rbv2WithInheritFromParent},
+			},
+			{
+				ActionName: "iam_group_update",
+				TypeName:   "resourceowner",
+				Conditions: []iapl.Condition{rbv2WithInheritFromParent},
+			},
+			{
+				ActionName: "iam_group_update",
+				TypeName:   "group",
+				Conditions: []iapl.Condition{rbv2WithInheritFromParent},
+			},
+			{
+				ActionName: "iam_group_delete",
+				TypeName:   "resourceowner",
+				Conditions: []iapl.Condition{rbv2WithInheritFromParent},
+			},
+			{
+				ActionName: "iam_group_delete",
+				TypeName:   "group",
+				Conditions: []iapl.Condition{rbv2WithInheritFromParent},
+			},
 		},
 	}
 }
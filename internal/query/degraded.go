@@ -0,0 +1,217 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// DefaultDecisionCacheTTL is the default time a cached decision may be served while SpiceDB is unavailable.
+	DefaultDecisionCacheTTL = 5 * time.Minute
+
+	// DefaultDecisionCacheSize is the default maximum number of cached decisions.
+	DefaultDecisionCacheSize = 10000
+
+	degradedSourceFailOpen = "fail_open"
+	degradedSourceCache    = "cache"
+	degradedSourceClosed   = "fail_closed"
+)
+
+// DegradedConfig configures how permission checks are answered while the
+// SpiceDB circuit breaker is open. Checks fail closed unless the action is
+// listed in FailOpenActions or a cached decision is available.
+type DegradedConfig struct {
+	// FailOpenActions are low-risk actions which are allowed while SpiceDB is unavailable.
+	FailOpenActions []string
+	// CacheDecisions caches recent decisions so they can be served while SpiceDB is unavailable.
+	CacheDecisions bool
+	// CacheTTL is the time a cached decision may be served for.
+	CacheTTL time.Duration
+	// CacheSize is the maximum number of cached decisions.
+	CacheSize int
+}
+
+// degradedMode answers permission checks while the SpiceDB circuit is open.
+type degradedMode struct {
+	failOpen map[string]bool
+	cache    *decisionCache
+}
+
+// decisionKey identifies a permission decision.
+type decisionKey struct {
+	subject  string
+	action   string
+	resource string
+}
+
+func newDecisionKey(subject types.Resource, action string, resource types.Resource) decisionKey {
+	return decisionKey{
+		subject:  subject.ID.String(),
+		action:   action,
+		resource: resource.ID.String(),
+	}
+}
+
+type cachedDecision struct {
+	allowed bool
+	expires time.Time
+}
+
+// decisionCache is a bounded cache of recent permission decisions.
+type decisionCache struct {
+	ttl  time.Duration
+	size int
+
+	mu        sync.Mutex
+	decisions map[decisionKey]cachedDecision
+
+	now func() time.Time
+}
+
+func newDecisionCache(ttl time.Duration, size int) *decisionCache {
+	if ttl <= 0 {
+		ttl = DefaultDecisionCacheTTL
+	}
+
+	if size <= 0 {
+		size = DefaultDecisionCacheSize
+	}
+
+	return &decisionCache{
+		ttl:       ttl,
+		size:      size,
+		decisions: make(map[decisionKey]cachedDecision),
+		now:       time.Now,
+	}
+}
+
+// set caches a decision, making room by dropping expired decisions, or an
+// arbitrary one if none expired, once the cache is full.
+func (c *decisionCache) set(key decisionKey, allowed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+
+	if _, ok := c.decisions[key]; !ok && len(c.decisions) >= c.size {
+		for k, decision := range c.decisions {
+			if now.After(decision.expires) {
+				delete(c.decisions, k)
+			}
+		}
+
+		for k := range c.decisions {
+			if len(c.decisions) < c.size {
+				break
+			}
+
+			delete(c.decisions, k)
+		}
+	}
+
+	c.decisions[key] = cachedDecision{allowed: allowed, expires: now.Add(c.ttl)}
+}
+
+// get returns a cached decision which has not expired.
+func (c *decisionCache) get(key decisionKey) (allowed, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	decision, ok := c.decisions[key]
+	if !ok || c.now().After(decision.expires) {
+		return false, false
+	}
+
+	return decision.allowed, true
+}
+
+// setTTL changes the time decisions are cached for, dropping the cached
+// decisions when it changes so none outlives the new TTL.
+func (c *decisionCache) setTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ttl == c.ttl {
+		return
+	}
+
+	c.ttl = ttl
+	c.decisions = make(map[decisionKey]cachedDecision)
+}
+
+// clear drops all cached decisions.
+func (c *decisionCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.decisions = make(map[decisionKey]cachedDecision)
+}
+
+// degradedDecision caches the decision of a permission check and, when the
+// check was rejected because the SpiceDB circuit is open, replaces the error
+// with the configured fallback decision.
+func (e *engine) degradedDecision(ctx context.Context, subject types.Resource, action string, resource types.Resource, err error) error {
+	if e.degraded == nil {
+		return err
+	}
+
+	key := newDecisionKey(subject, action, resource)
+
+	switch {
+	case err == nil:
+		if e.degraded.cache != nil {
+			e.degraded.cache.set(key, true)
+		}
+
+		return nil
+	case errors.Is(err, ErrActionNotAssigned):
+		if e.degraded.cache != nil {
+			e.degraded.cache.set(key, false)
+		}
+
+		return err
+	case !errors.Is(err, spicedbx.ErrorCircuitOpen):
+		return err
+	}
+
+	source := degradedSourceClosed
+
+	defer func() {
+		degradedDecisions.WithLabelValues(source).Inc()
+
+		trace.SpanFromContext(ctx).SetAttributes(
+			attribute.String(
+				"permissions.degraded",
+				source,
+			),
+		)
+	}()
+
+	if e.degraded.failOpen[action] {
+		source = degradedSourceFailOpen
+
+		return nil
+	}
+
+	if e.degraded.cache != nil {
+		if allowed, ok := e.degraded.cache.get(key); ok {
+			source = degradedSourceCache
+
+			if allowed {
+				return nil
+			}
+
+			return ErrActionNotAssigned
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,94 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestDegradedDecision(t *testing.T) {
+	t.Parallel()
+
+	e := &engine{}
+
+	WithDegradedMode(DegradedConfig{
+		FailOpenActions: []string{"loadbalancer_get"},
+		CacheDecisions:  true,
+	})(e)
+
+	subject := types.Resource{Type: "user", ID: gidx.PrefixedID("idntusr-degraded")}
+	allowedResource := types.Resource{Type: "tenant", ID: gidx.PrefixedID("tnntten-allowed")}
+	deniedResource := types.Resource{Type: "tenant", ID: gidx.PrefixedID("tnntten-denied")}
+	unknownResource := types.Resource{Type: "tenant", ID: gidx.PrefixedID("tnntten-unknown")}
+
+	circuitOpen := fmt.Errorf("%w: test", spicedbx.ErrorCircuitOpen)
+
+	ctx := context.Background()
+
+	// decisions made while SpiceDB is available are cached
+	assert.NoError(t, e.degradedDecision(ctx, subject, "loadbalancer_update", allowedResource, nil))
+	assert.ErrorIs(t, e.degradedDecision(ctx, subject, "loadbalancer_update", deniedResource, ErrActionNotAssigned), ErrActionNotAssigned)
+
+	assert.NoError(t, e.degradedDecision(ctx, subject, "loadbalancer_update", allowedResource, circuitOpen))
+	assert.ErrorIs(t, e.degradedDecision(ctx, subject, "loadbalancer_update", deniedResource, circuitOpen), ErrActionNotAssigned)
+
+	// fail-open actions are allowed without a cached decision
+	assert.NoError(t, e.degradedDecision(ctx, subject, "loadbalancer_get", unknownResource, circuitOpen))
+
+	// anything else fails closed
+	assert.ErrorIs(t, e.degradedDecision(ctx, subject, "loadbalancer_update", unknownResource, circuitOpen), spicedbx.ErrorCircuitOpen)
+}
+
+func TestDecisionCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	cache := newDecisionCache(time.Minute, 2)
+	cache.now = func() time.Time { return now }
+
+	first := decisionKey{subject: "s", action: "a", resource: "first"}
+	second := decisionKey{subject: "s", action: "a", resource: "second"}
+	third := decisionKey{subject: "s", action: "a", resource: "third"}
+
+	cache.set(first, true)
+
+	allowed, ok := cache.get(first)
+	assert.True(t, ok)
+	assert.True(t, allowed)
+
+	now = now.Add(time.Minute + time.Second)
+
+	_, ok = cache.get(first)
+	assert.False(t, ok, "expired decisions are not served")
+
+	cache.set(second, false)
+	cache.set(third, false)
+
+	// the expired decision made room for the new ones
+	assert.Len(t, cache.decisions, 2)
+
+	_, ok = cache.get(third)
+	assert.True(t, ok)
+
+	// changing the TTL drops the cached decisions
+	cache.setTTL(time.Minute)
+	assert.Len(t, cache.decisions, 2)
+
+	cache.setTTL(time.Second)
+	assert.Empty(t, cache.decisions)
+
+	cache.set(first, true)
+
+	now = now.Add(2 * time.Second)
+
+	_, ok = cache.get(first)
+	assert.False(t, ok)
+}
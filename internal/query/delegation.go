@@ -0,0 +1,155 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// CheckRoleDelegation checks the actor may grant the actions on the resource,
+// or on the owner of the resource if it is a role, when the policy restricts
+// delegated role administration. Actors with the admin action of the policy
+// may grant any action, others only actions they hold on the resource.
+// It is checked by CreateRoleV2 and UpdateRoleV2.
+func (e *engine) CheckRoleDelegation(ctx context.Context, actor, resource types.Resource, actions []string) error {
+	if e.rbac.Delegation == nil || e.delegationDisabled {
+		return nil
+	}
+
+	ctx, span := e.tracer.Start(
+		ctx, "engine.CheckRoleDelegation",
+		trace.WithAttributes(
+			attribute.Stringer("actor_id", actor.ID),
+			attribute.Stringer("resource_id", resource.ID),
+		),
+	)
+	defer span.End()
+
+	if resource.Type == e.rbac.RoleResource.Name {
+		dbrole, err := e.store.GetRoleByID(ctx, resource.ID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNoRoleFound) {
+				err = fmt.Errorf("%w: role %s", ErrRoleNotFound, resource.ID)
+			}
+
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return err
+		}
+
+		resource, err = e.NewResourceFromID(dbrole.ResourceID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return err
+		}
+	}
+
+	if err := e.checkDelegatedActions(ctx, actor, resource, e.expandActionGroups(actions)); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	return nil
+}
+
+// CheckRoleBindingDelegation checks the actor may bind the role, or add
+// subjects to the role-binding, on the resource when the policy restricts
+// delegated role administration, see CheckRoleDelegation.
+func (e *engine) CheckRoleBindingDelegation(ctx context.Context, actor, resource, roleResource types.Resource) error {
+	if e.rbac.Delegation == nil || e.delegationDisabled {
+		return nil
+	}
+
+	ctx, span := e.tracer.Start(
+		ctx, "engine.CheckRoleBindingDelegation",
+		trace.WithAttributes(
+			attribute.Stringer("actor_id", actor.ID),
+			attribute.Stringer("resource_id", resource.ID),
+			attribute.Stringer("role_id", roleResource.ID),
+		),
+	)
+	defer span.End()
+
+	if roleResource.Type == e.rbac.RoleBindingResource.Name {
+		rb, err := e.GetRoleBinding(ctx, roleResource)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return err
+		}
+
+		roleResource = types.Resource{Type: e.rbac.RoleResource.Name, ID: rb.RoleID}
+	}
+
+	if err := checkResourceType(roleResource, e.rbac.RoleResource.Name); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	actions, err := e.listRoleV2Actions(ctx, types.Role{ID: roleResource.ID})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	if err := e.checkDelegatedActions(ctx, actor, resource, actions); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	return nil
+}
+
+// checkDelegatedActions returns ErrRoleDelegationExceeded, listing the
+// actions the actor does not hold on the resource, unless the actor has the
+// admin action of the policy on the resource.
+func (e *engine) checkDelegatedActions(ctx context.Context, actor, resource types.Resource, actions []string) error {
+	err := e.SubjectHasPermission(ctx, actor, e.rbac.Delegation.AdminAction, resource)
+
+	switch {
+	case err == nil:
+		return nil
+	case !errors.Is(err, ErrActionNotAssigned) && !errors.Is(err, ErrInvalidAction):
+		return err
+	}
+
+	var missing []string
+
+	for _, action := range actions {
+		err := e.SubjectHasPermission(ctx, actor, action, resource)
+
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrActionNotAssigned), errors.Is(err, ErrInvalidAction):
+			missing = append(missing, action)
+		default:
+			return err
+		}
+	}
+
+	if len(missing) != 0 {
+		return fmt.Errorf("%w: %s", ErrRoleDelegationExceeded, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
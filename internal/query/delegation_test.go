@@ -0,0 +1,176 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func delegationTestPolicy() iapl.Policy {
+	doc := DefaultPolicyDocumentV2()
+	doc.Actions = append(doc.Actions, iapl.Action{Name: "iam_admin"})
+	doc.ActionBindings = append(doc.ActionBindings, iapl.ActionBinding{
+		ActionName: "iam_admin",
+		TypeName:   "resourceowner",
+		Conditions: []iapl.Condition{{RoleBindingV2: &iapl.ConditionRoleBindingV2{}}},
+	})
+	doc.RBAC.Delegation = &iapl.RBACDelegation{AdminAction: "iam_admin"}
+
+	p := iapl.NewPolicy(doc)
+	if err := p.Validate(); err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+func TestCheckRoleDelegation(t *testing.T) {
+	namespace := "testdelegation"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, delegationTestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	child, err := e.NewResourceFromIDString("tnntten-child")
+	require.NoError(t, err)
+	admin, err := e.NewResourceFromIDString("idntusr-admin")
+	require.NoError(t, err)
+	delegate, err := e.NewResourceFromIDString("idntusr-delegate")
+	require.NoError(t, err)
+
+	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
+		Updates: rbacV2CreateParentRel(root, child, e.namespace),
+	})
+	require.NoError(t, err)
+
+	// roles are set up without checking their delegation, nobody holds any action yet
+	adminRole, err := e.createRoleV2(ctx, admin, root, "admin", []string{"iam_admin", "loadbalancer_get", "loadbalancer_delete"})
+	require.NoError(t, err)
+
+	adminRoleRes, err := e.NewResourceFromID(adminRole.ID)
+	require.NoError(t, err)
+
+	_, err = e.createRoleBinding(ctx, admin, root, adminRoleRes, []types.RoleBindingSubject{{SubjectResource: admin}}, nil)
+	require.NoError(t, err)
+
+	viewerRole, err := e.CreateRoleV2(ctx, admin, root, "lb_viewer", []string{"loadbalancer_get", "loadbalancer_list"})
+	require.NoError(t, err)
+
+	viewerRoleRes, err := e.NewResourceFromID(viewerRole.ID)
+	require.NoError(t, err)
+
+	_, err = e.CreateRoleBinding(ctx, admin, child, viewerRoleRes, []types.RoleBindingSubject{{SubjectResource: delegate}})
+	require.NoError(t, err)
+
+	type input struct {
+		actor    types.Resource
+		resource types.Resource
+		actions  []string
+	}
+
+	tc := []testingx.TestCase[input, any]{
+		{
+			Name:  "HeldActions",
+			Input: input{delegate, child, []string{"loadbalancer_get"}},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.NoError(t, res.Err)
+			},
+		},
+		{
+			Name:  "HeldActionGroup",
+			Input: input{delegate, child, []string{"loadbalancer_viewer"}},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.NoError(t, res.Err)
+			},
+		},
+		{
+			Name:  "ActionNotHeld",
+			Input: input{delegate, child, []string{"loadbalancer_get", "loadbalancer_delete"}},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				require.ErrorIs(t, res.Err, ErrRoleDelegationExceeded)
+				assert.Contains(t, res.Err.Error(), "loadbalancer_delete")
+				assert.NotContains(t, res.Err.Error(), "loadbalancer_get")
+			},
+		},
+		{
+			Name:  "OutsideSubtree",
+			Input: input{delegate, root, []string{"loadbalancer_get"}},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.ErrorIs(t, res.Err, ErrRoleDelegationExceeded)
+			},
+		},
+		{
+			Name:  "RoleOwner",
+			Input: input{delegate, viewerRoleRes, []string{"loadbalancer_get"}},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				// the role is owned by the root tenant, outside the delegated sub-tree
+				assert.ErrorIs(t, res.Err, ErrRoleDelegationExceeded)
+			},
+		},
+		{
+			Name:  "Admin",
+			Input: input{admin, child, []string{"loadbalancer_delete", "loadbalancer_update"}},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.NoError(t, res.Err)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in input) testingx.TestResult[any] {
+		return testingx.TestResult[any]{Err: e.CheckRoleDelegation(ctx, in.actor, in.resource, in.actions)}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+
+	err = e.CheckRoleBindingDelegation(ctx, delegate, child, viewerRoleRes)
+	assert.NoError(t, err)
+
+	err = e.CheckRoleBindingDelegation(ctx, delegate, child, adminRoleRes)
+	assert.ErrorIs(t, err, ErrRoleDelegationExceeded)
+
+	err = e.CheckRoleBindingDelegation(ctx, admin, child, adminRoleRes)
+	assert.NoError(t, err)
+
+	// role and role-binding changes are checked by the engine itself
+	_, err = e.CreateRoleV2(ctx, delegate, child, "lb_deleter", []string{"loadbalancer_delete"})
+	assert.ErrorIs(t, err, ErrRoleDelegationExceeded)
+
+	_, err = e.UpdateRoleV2(ctx, delegate, viewerRoleRes, "lb_viewer", []string{"loadbalancer_get", "loadbalancer_delete"})
+	assert.ErrorIs(t, err, ErrRoleDelegationExceeded)
+
+	_, err = e.CreateRoleBinding(ctx, delegate, child, adminRoleRes, []types.RoleBindingSubject{{SubjectResource: delegate}})
+	assert.ErrorIs(t, err, ErrRoleDelegationExceeded)
+
+	results := e.CreateRoleBindings(ctx, delegate, child, []types.RoleBindingRequest{
+		{Role: viewerRoleRes, Subjects: []types.RoleBindingSubject{{SubjectResource: admin}}},
+		{Role: adminRoleRes, Subjects: []types.RoleBindingSubject{{SubjectResource: delegate}}},
+	})
+	require.Len(t, results, 2)
+	assert.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, ErrRoleDelegationExceeded)
+
+	_, _, err = e.CreateInvitation(ctx, delegate, child, adminRoleRes, "delegate@example.com", time.Hour)
+	assert.ErrorIs(t, err, ErrRoleDelegationExceeded)
+}
+
+func TestCheckRoleDelegationUnrestricted(t *testing.T) {
+	namespace := "testdelegationunrestricted"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	err = e.CheckRoleDelegation(ctx, actor, tenant, []string{"loadbalancer_delete"})
+	assert.NoError(t, err)
+}
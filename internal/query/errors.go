@@ -3,6 +3,9 @@ package query
 import (
 	"errors"
 	"fmt"
+	"strings"
+
+	"go.infratographer.com/x/gidx"
 )
 
 var (
@@ -50,6 +53,14 @@ var (
 	// ErrRoleAlreadyExists represents an error when a role already exists
 	ErrRoleAlreadyExists = fmt.Errorf("%w: role already exists", ErrInvalidArgument)
 
+	// ErrInvalidCaveat represents an error when a role binding caveat is not defined by the policy
+	// or its context does not match the caveat parameters
+	ErrInvalidCaveat = fmt.Errorf("%w: invalid caveat", ErrInvalidArgument)
+
+	// ErrInvalidCaveatContext represents an error when the caveat context of a permission check
+	// holds values which are not parameters of a caveat or don't match their type
+	ErrInvalidCaveatContext = fmt.Errorf("%w: invalid caveat context", ErrInvalidArgument)
+
 	// ErrInvalidRoleBindingSubjectType represents an error when a role binding subject type is invalid
 	ErrInvalidRoleBindingSubjectType = fmt.Errorf("%w: invalid role binding subject type", ErrInvalidArgument)
 
@@ -64,4 +75,136 @@ var (
 	// ErrRoleBindingHasNoRelationships represents an internal error when a
 	// role binding has no relationships
 	ErrRoleBindingHasNoRelationships = errors.New("role binding has no relationships")
+
+	// ErrInvitationNotFound represents an error when no matching invitation was found
+	ErrInvitationNotFound = errors.New("invitation not found")
+
+	// ErrInvitationExpired represents an error when an invitation is redeemed after it expired
+	ErrInvitationExpired = errors.New("invitation expired")
+
+	// ErrInvitationRedeemed represents an error when an invitation is redeemed more than once
+	ErrInvitationRedeemed = errors.New("invitation already redeemed")
+
+	// ErrAccessRequestNotFound represents an error when no matching access request was found
+	ErrAccessRequestNotFound = errors.New("access request not found")
+
+	// ErrAccessRequestDecided represents an error when an access request is decided more than once
+	ErrAccessRequestDecided = errors.New("access request already decided")
+
+	// ErrAccessRequestSelfDecision represents an error when a subject decides its own access request
+	ErrAccessRequestSelfDecision = errors.New("access requests cannot be decided by their subject")
+
+	// ErrRoleDelegationExceeded represents an error when a delegated role
+	// administrator grants actions it does not hold itself
+	ErrRoleDelegationExceeded = errors.New("actions exceed delegated role administration")
+
+	// ErrGroupsNotConfigured represents an error when groups are managed but
+	// the policy does not define a group resource
+	ErrGroupsNotConfigured = errors.New("group resource not defined")
+
+	// ErrGroupNotFound represents an error when no matching group was found
+	ErrGroupNotFound = errors.New("group not found")
+
+	// ErrInvalidGroupMemberType represents an error when a subject cannot be a member of a group
+	ErrInvalidGroupMemberType = fmt.Errorf("%w: invalid group member type", ErrInvalidArgument)
+
+	// ErrGroupCycle represents an error when a group membership would nest a group within itself
+	ErrGroupCycle = fmt.Errorf("%w: group membership would create a cycle", ErrInvalidArgument)
+
+	// ErrGroupNestingTooDeep represents an error when a group membership would exceed the maximum nesting depth
+	ErrGroupNestingTooDeep = fmt.Errorf("%w: groups nested too deep", ErrInvalidArgument)
+
+	// ErrDeniedByTenantSettings represents an error when a role-binding is not allowed by the settings of a tenant
+	ErrDeniedByTenantSettings = fmt.Errorf("%w: denied by tenant settings", ErrInvalidArgument)
+
+	// ErrResourceAliasNotFound represents an error when no matching resource alias was found
+	ErrResourceAliasNotFound = errors.New("resource alias not found")
+
+	// ErrInvalidResourceAlias represents an error when a resource alias is not a valid URN
+	ErrInvalidResourceAlias = fmt.Errorf("%w: invalid resource alias", ErrInvalidArgument)
+
+	// ErrRoleNotMigratable represents an error when a v1 role cannot be migrated to a v2 role
+	ErrRoleNotMigratable = fmt.Errorf("%w: role cannot be migrated to v2", ErrInvalidArgument)
+
+	// ErrInvalidIDPrefix represents an error when the prefix of an ID is not registered in the
+	// policy or belongs to a resource type other than the expected ones
+	ErrInvalidIDPrefix = fmt.Errorf("%w: invalid ID prefix", ErrInvalidArgument)
+
+	// ErrPreconditionFailed represents an error when a role or role-binding was modified since
+	// the version the update was made against
+	ErrPreconditionFailed = errors.New("precondition failed")
 )
+
+// InvalidActionsError is returned when actions are not defined by the policy
+// for a resource type, it wraps ErrInvalidAction.
+type InvalidActionsError struct {
+	// ResourceType is the resource type the actions were requested for.
+	ResourceType string
+	// Actions are the requested actions which are not defined.
+	Actions []string
+}
+
+// Error implements the error interface.
+func (e *InvalidActionsError) Error() string {
+	return fmt.Sprintf("%s: %s for %s", ErrInvalidAction, strings.Join(e.Actions, ","), e.ResourceType)
+}
+
+// Unwrap returns ErrInvalidAction.
+func (e *InvalidActionsError) Unwrap() error {
+	return ErrInvalidAction
+}
+
+// InvalidIDPrefixError is returned when an ID does not carry the prefix of
+// one of the expected resource types. It wraps ErrInvalidIDPrefix, and
+// ErrInvalidNamespace or ErrInvalidType depending on whether the prefix is
+// registered in the policy.
+type InvalidIDPrefixError struct {
+	// ID is the invalid ID.
+	ID gidx.PrefixedID
+	// ResourceType is the resource type registered for the prefix of ID,
+	// empty if the prefix is not registered.
+	ResourceType string
+	// Expected are the resource types accepted, empty if any registered
+	// resource type is.
+	Expected []string
+}
+
+// Error implements the error interface.
+func (e *InvalidIDPrefixError) Error() string {
+	if e.ResourceType == "" {
+		return fmt.Sprintf("%s: %s is not registered in the policy", ErrInvalidIDPrefix, e.ID.Prefix())
+	}
+
+	return fmt.Sprintf("%s: %s is a %s, expected %s", ErrInvalidIDPrefix, e.ID, e.ResourceType, strings.Join(e.Expected, " or "))
+}
+
+// Unwrap returns ErrInvalidIDPrefix and ErrInvalidNamespace or ErrInvalidType.
+func (e *InvalidIDPrefixError) Unwrap() []error {
+	if e.ResourceType == "" {
+		return []error{ErrInvalidIDPrefix, ErrInvalidNamespace}
+	}
+
+	return []error{ErrInvalidIDPrefix, ErrInvalidType}
+}
+
+// InvalidRoleBindingSubjectError is returned when the type of a role-binding
+// subject is not one of the role-binding subject types of the policy, it
+// wraps ErrInvalidRoleBindingSubjectType.
+type InvalidRoleBindingSubjectError struct {
+	// SubjectID is the ID of the invalid subject.
+	SubjectID gidx.PrefixedID
+	// SubjectType is the resource type of the subject.
+	SubjectType string
+	// Allowed are the subject types role-bindings may have.
+	Allowed []string
+}
+
+// Error implements the error interface.
+func (e *InvalidRoleBindingSubjectError) Error() string {
+	return fmt.Sprintf("%s: %s is a %s, expected %s", ErrInvalidRoleBindingSubjectType, e.SubjectID, e.SubjectType, strings.Join(e.Allowed, " or "))
+}
+
+// Unwrap returns ErrInvalidRoleBindingSubjectType.
+func (e *InvalidRoleBindingSubjectError) Unwrap() error {
+	return ErrInvalidRoleBindingSubjectType
+}
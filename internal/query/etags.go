@@ -0,0 +1,63 @@
+package query
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.infratographer.com/x/gidx"
+)
+
+// etagBytes is the number of bytes of the version hash in entity tags.
+const etagBytes = 12
+
+type ifMatchKey struct{}
+
+// ETag returns the entity tag of the stored version of a role or role-binding,
+// derived from its ID and the time it was last updated.
+func ETag(id gidx.PrefixedID, updatedAt time.Time) string {
+	sum := sha256.Sum256([]byte(id.String() + "@" + strconv.FormatInt(updatedAt.UnixNano(), 10)))
+
+	return `"` + hex.EncodeToString(sum[:etagBytes]) + `"`
+}
+
+// ETagMatches reports whether the If-Match or If-None-Match header value
+// matches the entity tag, either listing it or being "*". Weak tags never
+// match, as the entity tags of roles and role-bindings are strong.
+func ETagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ContextWithIfMatch returns a context whose role and role-binding updates are
+// only applied when the entity tag of the stored version matches the If-Match
+// header value, so concurrent updates are not overwritten.
+func ContextWithIfMatch(ctx context.Context, header string) context.Context {
+	return context.WithValue(ctx, ifMatchKey{}, header)
+}
+
+// checkIfMatch returns ErrPreconditionFailed when the stored version doesn't
+// match the If-Match header value of ctx, if any.
+func checkIfMatch(ctx context.Context, id gidx.PrefixedID, updatedAt time.Time) error {
+	header, _ := ctx.Value(ifMatchKey{}).(string)
+	if header == "" {
+		return nil
+	}
+
+	if !ETagMatches(header, ETag(id, updatedAt)) {
+		return fmt.Errorf("%w: %s was modified", ErrPreconditionFailed, id)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+)
+
+func TestETagMatches(t *testing.T) {
+	id := gidx.PrefixedID("permrv2-etag")
+	now := time.Now()
+	etag := ETag(id, now)
+
+	assert.Equal(t, etag, ETag(id, now))
+	assert.NotEqual(t, etag, ETag(id, now.Add(time.Microsecond)))
+	assert.NotEqual(t, etag, ETag(gidx.PrefixedID("permrv2-other"), now))
+
+	assert.True(t, ETagMatches(etag, etag))
+	assert.True(t, ETagMatches(`"stale", `+etag, etag))
+	assert.True(t, ETagMatches("*", etag))
+	assert.False(t, ETagMatches(`"stale"`, etag))
+	assert.False(t, ETagMatches("W/"+etag, etag))
+
+	ctx := context.Background()
+
+	assert.NoError(t, checkIfMatch(ctx, id, now))
+	assert.NoError(t, checkIfMatch(ContextWithIfMatch(ctx, etag), id, now))
+	assert.ErrorIs(t, checkIfMatch(ContextWithIfMatch(ctx, `"stale"`), id, now), ErrPreconditionFailed)
+}
+
+func TestUpdateRoleV2IfMatch(t *testing.T) {
+	namespace := "testetags"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-etags")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-etags")
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, actor, tenant, "lb_viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
+	roleRes, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	etag := ETag(role.ID, role.UpdatedAt)
+
+	updated, err := e.UpdateRoleV2(ContextWithIfMatch(ctx, etag), actor, roleRes, "lb_reader", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
+	// the role was modified since the first etag
+	_, err = e.UpdateRoleV2(ContextWithIfMatch(ctx, etag), actor, roleRes, "lb_getter", []string{"loadbalancer_get"})
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	current, err := e.GetRoleV2(ctx, roleRes)
+	require.NoError(t, err)
+	assert.Equal(t, "lb_reader", current.Name)
+
+	_, err = e.UpdateRoleV2(ContextWithIfMatch(ctx, ETag(updated.ID, updated.UpdatedAt)), actor, roleRes, "lb_getter", []string{"loadbalancer_get"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,196 @@
+package query
+
+import (
+	"context"
+	"strings"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// maxExplainDepth limits the number of relations followed to find a grant path.
+const maxExplainDepth = 32
+
+// explainer searches the permission trees expanded by SpiceDB for a path to a subject.
+type explainer struct {
+	e       *engine
+	subject *pb.ObjectReference
+	visited map[types.GrantStep]bool
+}
+
+// ExplainPermission returns a grant path of the action on the resource to the
+// subject, from the resource to the subject, by expanding the permission in
+// SpiceDB. ErrActionNotAssigned is returned if no path is found. Permission
+// trees are expanded fully consistent, so the path reflects the current
+// relationships, this makes explaining a permission expensive and it is meant
+// for debugging access issues only.
+func (e *engine) ExplainPermission(ctx context.Context, subject types.Resource, action string, resource types.Resource) ([]types.GrantStep, error) {
+	ctx, span := e.tracer.Start(
+		ctx,
+		"engine.ExplainPermission",
+		trace.WithAttributes(
+			attribute.Stringer("permissions.actor", subject.ID),
+			attribute.String("permissions.action", action),
+			attribute.Stringer("permissions.resource", resource.ID),
+		),
+	)
+
+	defer span.End()
+
+	if err := e.validateResourceActions(resource, action); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	x := &explainer{
+		e:       e,
+		subject: resourceToSpiceDBRef(e.namespace, subject),
+		visited: make(map[types.GrantStep]bool),
+	}
+
+	path, err := x.explain(ctx, resourceToSpiceDBRef(e.namespace, resource), action, 0)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	if path == nil {
+		return nil, ErrActionNotAssigned
+	}
+
+	return path, nil
+}
+
+// step returns the grant step of the relation of the object.
+func (x *explainer) step(object *pb.ObjectReference, relation string) types.GrantStep {
+	return types.GrantStep{
+		Type:     strings.TrimPrefix(object.ObjectType, x.e.namespace+"/"),
+		ID:       object.ObjectId,
+		Relation: relation,
+	}
+}
+
+// explain expands the relation of the object and returns the path to the
+// subject, nil if the subject is not reachable through it.
+func (x *explainer) explain(ctx context.Context, object *pb.ObjectReference, relation string, depth int) ([]types.GrantStep, error) {
+	step := x.step(object, relation)
+
+	if depth > maxExplainDepth || x.visited[step] {
+		return nil, nil
+	}
+
+	x.visited[step] = true
+
+	resp, err := x.e.client.ExpandPermissionTree(ctx, &pb.ExpandPermissionTreeRequest{
+		Consistency: &pb.Consistency{
+			Requirement: &pb.Consistency_FullyConsistent{FullyConsistent: true},
+		},
+		Resource:   object,
+		Permission: relation,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := x.explainTree(ctx, resp.TreeRoot, step, depth)
+	if err != nil || path == nil {
+		return nil, err
+	}
+
+	return append([]types.GrantStep{step}, path...), nil
+}
+
+// explainTree returns the path to the subject within an expanded tree.
+// Relations of other objects expanded within the tree, e.g. through the
+// parent of a resource, are added to the path as they are passed.
+func (x *explainer) explainTree(ctx context.Context, tree *pb.PermissionRelationshipTree, current types.GrantStep, depth int) ([]types.GrantStep, error) {
+	if tree == nil {
+		return nil, nil
+	}
+
+	var prefix []types.GrantStep
+
+	if tree.ExpandedObject != nil {
+		if step := x.step(tree.ExpandedObject, tree.ExpandedRelation); step != current {
+			prefix = append(prefix, step)
+			current = step
+		}
+	}
+
+	var (
+		path []types.GrantStep
+		err  error
+	)
+
+	switch node := tree.TreeType.(type) {
+	case *pb.PermissionRelationshipTree_Leaf:
+		path, err = x.explainSubjects(ctx, node.Leaf.GetSubjects(), depth)
+	case *pb.PermissionRelationshipTree_Intermediate:
+		path, err = x.explainOperation(ctx, node.Intermediate, current, depth)
+	}
+
+	if err != nil || path == nil {
+		return nil, err
+	}
+
+	return append(prefix, path...), nil
+}
+
+// explainSubjects returns the path to the subject through the subjects of a relation.
+func (x *explainer) explainSubjects(ctx context.Context, subjects []*pb.SubjectReference, depth int) ([]types.GrantStep, error) {
+	for _, subject := range subjects {
+		if subject.OptionalRelation != "" {
+			continue
+		}
+
+		if subject.Object.ObjectType == x.subject.ObjectType &&
+			(subject.Object.ObjectId == x.subject.ObjectId || subject.Object.ObjectId == "*") {
+			return []types.GrantStep{x.step(subject.Object, "")}, nil
+		}
+	}
+
+	// subject sets, e.g. group members, are only expanded if the subject is
+	// not a direct subject of the relation.
+	for _, subject := range subjects {
+		if subject.OptionalRelation == "" {
+			continue
+		}
+
+		path, err := x.explain(ctx, subject.Object, subject.OptionalRelation, depth+1)
+		if err != nil || path != nil {
+			return path, err
+		}
+	}
+
+	return nil, nil
+}
+
+// explainOperation returns the path to the subject through a set operation.
+// The first child of an intersection or exclusion is reported, as the
+// decision itself is made by the permission check.
+func (x *explainer) explainOperation(ctx context.Context, node *pb.AlgebraicSubjectSet, current types.GrantStep, depth int) ([]types.GrantStep, error) {
+	children := node.GetChildren()
+
+	switch node.Operation {
+	case pb.AlgebraicSubjectSet_OPERATION_UNION:
+	case pb.AlgebraicSubjectSet_OPERATION_INTERSECTION, pb.AlgebraicSubjectSet_OPERATION_EXCLUSION:
+		children = children[:min(len(children), 1)]
+	default:
+		return nil, nil
+	}
+
+	for _, child := range children {
+		path, err := x.explainTree(ctx, child, current, depth)
+		if err != nil || path != nil {
+			return path, err
+		}
+	}
+
+	return nil, nil
+}
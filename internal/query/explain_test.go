@@ -0,0 +1,90 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestExplainPermission(t *testing.T) {
+	namespace := "infratestexplain"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, testPolicy())
+
+	tenRes, err := e.NewResourceFromID(gidx.MustNewID("tnntten"))
+	require.NoError(t, err)
+	childRes, err := e.NewResourceFromID(gidx.MustNewID("tnntten"))
+	require.NoError(t, err)
+	subjRes, err := e.NewResourceFromID(gidx.MustNewID("idntusr"))
+	require.NoError(t, err)
+
+	err = e.CreateRelationships(ctx, []types.Relationship{
+		{
+			Resource: childRes,
+			Relation: "parent",
+			Subject:  tenRes,
+		},
+	})
+	require.NoError(t, err)
+
+	role, err := e.CreateRole(ctx, subjRes, tenRes, "test", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
+	err = e.AssignSubjectRole(ctx, subjRes, role)
+	require.NoError(t, err)
+
+	testCases := []testingx.TestCase[string, []types.GrantStep]{
+		{
+			Name:  "Granted",
+			Input: "loadbalancer_get",
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]types.GrantStep]) {
+				require.NoError(t, res.Err)
+				require.GreaterOrEqual(t, len(res.Success), 3)
+
+				assert.Equal(t, types.GrantStep{Type: "tenant", ID: childRes.ID.String(), Relation: "loadbalancer_get"}, res.Success[0])
+				assert.Equal(t, types.GrantStep{Type: "user", ID: subjRes.ID.String()}, res.Success[len(res.Success)-1])
+
+				var throughParent bool
+
+				for _, step := range res.Success {
+					if step.Type == "tenant" && step.ID == tenRes.ID.String() {
+						throughParent = true
+					}
+				}
+
+				assert.True(t, throughParent, "grant path should pass the parent tenant")
+			},
+		},
+		{
+			Name:  "NotGranted",
+			Input: "loadbalancer_delete",
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]types.GrantStep]) {
+				assert.ErrorIs(t, res.Err, ErrActionNotAssigned)
+			},
+		},
+		{
+			Name:  "InvalidAction",
+			Input: "bad_action",
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]types.GrantStep]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidAction)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, action string) testingx.TestResult[[]types.GrantStep] {
+		path, err := e.ExplainPermission(ctx, subjRes, action, childRes)
+
+		return testingx.TestResult[[]types.GrantStep]{
+			Success: path,
+			Err:     err,
+		}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
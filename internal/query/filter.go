@@ -0,0 +1,144 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// bulkCheckBatchSize is the maximum number of checks sent to SpiceDB in a
+// single CheckBulkPermissions request.
+const bulkCheckBatchSize = 100
+
+// FilterAllowedResources returns the resources the subject is allowed to do
+// the action on, in the order they were given. Resources whose type does not
+// define the action are not allowed. The resources are checked with SpiceDB
+// bulk checks, at least as fresh as the latest write to any of them.
+func (e *engine) FilterAllowedResources(ctx context.Context, subject types.Resource, action string, resources []types.Resource) ([]types.Resource, error) {
+	ctx, span := e.tracer.Start(
+		ctx,
+		"engine.FilterAllowedResources",
+		trace.WithAttributes(
+			attribute.Stringer("permissions.actor", subject.ID),
+			attribute.String("permissions.action", action),
+			attribute.Int("permissions.resources", len(resources)),
+		),
+	)
+	defer span.End()
+
+	if len(resources) == 0 {
+		return []types.Resource{}, nil
+	}
+
+	caveatContext, err := e.checkCaveatContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	consistency, consName := e.determineConsistency(ctx, resources...)
+	span.SetAttributes(attribute.String("permissions.consistency", consName))
+
+	start := time.Now()
+	allowed := make([]types.Resource, 0, len(resources))
+
+	// only resources defining the action are checked, the others are denied.
+	checked := make([]types.Resource, 0, len(resources))
+
+	for _, resource := range resources {
+		if e.validateResourceActions(resource, action) != nil {
+			e.logFilterDecision(ctx, subject, action, resource, outcomeDenied, "", start)
+
+			continue
+		}
+
+		checked = append(checked, resource)
+	}
+
+	for batchStart := 0; batchStart < len(checked); batchStart += bulkCheckBatchSize {
+		batch := checked[batchStart:min(batchStart+bulkCheckBatchSize, len(checked))]
+
+		items := make([]*pb.CheckBulkPermissionsRequestItem, len(batch))
+
+		for i, resource := range batch {
+			items[i] = &pb.CheckBulkPermissionsRequestItem{
+				Resource:   resourceToSpiceDBRef(e.namespace, resource),
+				Permission: action,
+				Subject: &pb.SubjectReference{
+					Object: resourceToSpiceDBRef(e.namespace, subject),
+				},
+				Context: caveatContext,
+			}
+		}
+
+		resp, err := e.client.CheckBulkPermissions(ctx, &pb.CheckBulkPermissionsRequest{
+			Consistency: consistency,
+			Items:       items,
+		})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, err
+		}
+
+		// pairs are returned in the order of the request items, so allowed
+		// resources keep the order they were given in.
+		for i, pair := range resp.Pairs[:min(len(resp.Pairs), len(batch))] {
+			resource := batch[i]
+
+			if pairErr := pair.GetError(); pairErr != nil {
+				err := fmt.Errorf("checking %s: %s", resource.ID, pairErr.GetMessage())
+
+				e.logFilterDecision(ctx, subject, action, resource, outcomeError, resp.CheckedAt.GetToken(), start)
+
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+
+				return nil, err
+			}
+
+			// A conditional permission means the caveat context was missing
+			// values required by a caveat, which is treated as denied.
+			if pair.GetItem().GetPermissionship() != pb.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION {
+				e.logFilterDecision(ctx, subject, action, resource, outcomeDenied, resp.CheckedAt.GetToken(), start)
+
+				continue
+			}
+
+			e.recordUsage(subject, action, resource)
+			e.logFilterDecision(ctx, subject, action, resource, outcomeAllowed, resp.CheckedAt.GetToken(), start)
+
+			allowed = append(allowed, resource)
+		}
+	}
+
+	span.SetAttributes(attribute.Int("permissions.allowed", len(allowed)))
+
+	return allowed, nil
+}
+
+// logFilterDecision records the metrics and decision log entry of a resource
+// checked by FilterAllowedResources.
+func (e *engine) logFilterDecision(ctx context.Context, subject types.Resource, action string, resource types.Resource, outcome, zedToken string, start time.Time) {
+	observeCheck(e.checkActionLabel(resource, action), outcome, start)
+
+	e.logDecision(ctx, types.Decision{
+		SubjectID:  subject.ID,
+		ResourceID: resource.ID,
+		Action:     action,
+		Outcome:    outcome,
+		Latency:    time.Since(start),
+		ZedToken:   zedToken,
+		DecidedAt:  start,
+	})
+}
@@ -0,0 +1,77 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestFilterAllowedResources(t *testing.T) {
+	namespace := "testfilter"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	allowedTenant, err := e.NewResourceFromIDString("tnntten-allowed")
+	require.NoError(t, err)
+	childTenant, err := e.NewResourceFromIDString("tnntten-child")
+	require.NoError(t, err)
+	deniedTenant, err := e.NewResourceFromIDString("tnntten-denied")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
+		Updates: rbacV2CreateParentRel(allowedTenant, childTenant, e.namespace),
+	})
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, actor, allowedTenant, "lb_viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
+	roleRes, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	_, err = e.CreateRoleBinding(ctx, actor, allowedTenant, roleRes, []types.RoleBindingSubject{{SubjectResource: actor}})
+	require.NoError(t, err)
+
+	tc := []testingx.TestCase[[]types.Resource, []types.Resource]{
+		{
+			Name:  "Empty",
+			Input: []types.Resource{},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Resource]) {
+				require.NoError(t, res.Err)
+				assert.Empty(t, res.Success)
+			},
+		},
+		{
+			Name:  "Filtered",
+			Input: []types.Resource{deniedTenant, childTenant, allowedTenant},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Resource]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, []types.Resource{childTenant, allowedTenant}, res.Success)
+			},
+		},
+		{
+			Name:  "ActionNotDefined",
+			Input: []types.Resource{actor, allowedTenant},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Resource]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, []types.Resource{allowedTenant}, res.Success)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, resources []types.Resource) testingx.TestResult[[]types.Resource] {
+		out, err := e.FilterAllowedResources(ctx, actor, "loadbalancer_get", resources)
+
+		return testingx.TestResult[[]types.Resource]{Success: out, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
@@ -0,0 +1,741 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// groupDefinition returns the group definition of the policy, an
+// ErrGroupsNotConfigured error is returned if the policy does not define one.
+func (e *engine) groupDefinition() (*iapl.RBACGroupDefinition, error) {
+	if e.rbac.GroupResource == nil {
+		return nil, ErrGroupsNotConfigured
+	}
+
+	return e.rbac.GroupResource, nil
+}
+
+// groupRelationship creates the relationship of a group along the given
+// relation to the subject. The subject must be one of the target types of the
+// relation, a target type with a subject relation is used for subjects which
+// are not directly related, e.g. group#member.
+func (e *engine) groupRelationship(group types.Resource, relation string, subject types.Resource) (*pb.Relationship, error) {
+	var (
+		target types.TargetType
+		found  bool
+	)
+
+	for _, rel := range e.schemaTypeMap[group.Type].Relationships {
+		if rel.Relation != relation {
+			continue
+		}
+
+		for _, tt := range rel.Types {
+			if tt.Name != subject.Type {
+				continue
+			}
+
+			// prefer direct relationships
+			if !found || tt.SubjectRelation == "" {
+				target = tt
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%w: %s cannot be related to %s as %s", ErrInvalidRelationship, subject.ID, group.Type, relation)
+	}
+
+	return &pb.Relationship{
+		Resource: resourceToSpiceDBRef(e.namespace, group),
+		Relation: relation,
+		Subject: &pb.SubjectReference{
+			Object:           resourceToSpiceDBRef(e.namespace, subject),
+			OptionalRelation: target.SubjectRelation,
+		},
+	}, nil
+}
+
+// groupMemberRelationship creates the relationship between a group and a
+// member, member groups use the subgroup relation if one is defined.
+func (e *engine) groupMemberRelationship(def *iapl.RBACGroupDefinition, group, member types.Resource) (*pb.Relationship, error) {
+	if member.ID == group.ID {
+		return nil, fmt.Errorf("%w: group %s cannot be a member of itself", ErrInvalidGroupMemberType, group.ID)
+	}
+
+	relation := def.MemberRelation
+
+	if member.Type == def.Name && def.SubgroupRelation != "" {
+		relation = def.SubgroupRelation
+	}
+
+	rel, err := e.groupRelationship(group, relation, member)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidGroupMemberType, err.Error())
+	}
+
+	return rel, nil
+}
+
+// groupResource returns the group resource for the group ID.
+func (e *engine) groupResource(def *iapl.RBACGroupDefinition, id gidx.PrefixedID) (types.Resource, error) {
+	group, err := e.NewResourceFromID(id)
+	if err != nil {
+		return types.Resource{}, err
+	}
+
+	if err := checkResourceType(group, def.Name); err != nil {
+		return types.Resource{}, err
+	}
+
+	return group, nil
+}
+
+func (e *engine) CreateGroup(ctx context.Context, actor, owner types.Resource, name, description string) (types.Group, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.CreateGroup",
+		trace.WithAttributes(attribute.Stringer("owner_id", owner.ID)),
+	)
+	defer span.End()
+
+	fail := func(err error) (types.Group, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Group{}, err
+	}
+
+	def, err := e.groupDefinition()
+	if err != nil {
+		return fail(err)
+	}
+
+	id, err := gidx.NewID(e.schemaTypeMap[def.Name].IDPrefix)
+	if err != nil {
+		return fail(err)
+	}
+
+	group := types.Resource{Type: def.Name, ID: id}
+
+	ownerRel, err := e.groupRelationship(group, def.OwnerRelation, owner)
+	if err != nil {
+		return fail(fmt.Errorf("%w: invalid group owner: %s", ErrInvalidArgument, err.Error()))
+	}
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	dbGroup, err := e.store.CreateGroup(dbCtx, types.Group{
+		ID:          id,
+		OwnerID:     owner.ID,
+		Name:        name,
+		Description: description,
+		CreatedBy:   actor.ID,
+	})
+	if err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(err)
+	}
+
+	updates := []*pb.RelationshipUpdate{
+		{
+			Operation:    pb.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: ownerRel,
+		},
+	}
+
+	if err := e.applyUpdates(dbCtx, updates); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(err)
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(ctx), e.rollbackUpdates(ctx, updates))
+
+		return fail(err)
+	}
+
+	return dbGroup, nil
+}
+
+func (e *engine) GetGroup(ctx context.Context, id gidx.PrefixedID) (types.Group, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.GetGroup",
+		trace.WithAttributes(attribute.Stringer("group_id", id)),
+	)
+	defer span.End()
+
+	group, err := e.store.GetGroupByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrGroupNotFound) {
+			err = fmt.Errorf("%w: %s", ErrGroupNotFound, id)
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Group{}, err
+	}
+
+	return group, nil
+}
+
+func (e *engine) ListGroups(ctx context.Context, owner types.Resource) ([]types.Group, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListGroups",
+		trace.WithAttributes(attribute.Stringer("owner_id", owner.ID)),
+	)
+	defer span.End()
+
+	groups, err := e.store.ListOwnerGroups(ctx, owner.ID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+func (e *engine) UpdateGroup(ctx context.Context, actor types.Resource, id gidx.PrefixedID, name, description string) (types.Group, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.UpdateGroup",
+		trace.WithAttributes(attribute.Stringer("group_id", id)),
+	)
+	defer span.End()
+
+	fail := func(err error) (types.Group, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Group{}, err
+	}
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	group, err := e.store.UpdateGroup(dbCtx, actor.ID, id, name, description)
+	if err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		if errors.Is(err, storage.ErrGroupNotFound) {
+			err = fmt.Errorf("%w: %s", ErrGroupNotFound, id)
+		}
+
+		return fail(err)
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(err)
+	}
+
+	return group, nil
+}
+
+// DeleteGroup deletes a group with all its relationships, including those
+// where the group is the subject, such as role-bindings granted to the group.
+func (e *engine) DeleteGroup(ctx context.Context, id gidx.PrefixedID) error {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.DeleteGroup",
+		trace.WithAttributes(attribute.Stringer("group_id", id)),
+	)
+	defer span.End()
+
+	fail := func(err error) error {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	def, err := e.groupDefinition()
+	if err != nil {
+		return fail(err)
+	}
+
+	group, err := e.groupResource(def, id)
+	if err != nil {
+		return fail(err)
+	}
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	// 1. delete group from permission-api DB
+	if _, err := e.store.DeleteGroup(dbCtx, id); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		if errors.Is(err, storage.ErrGroupNotFound) {
+			err = fmt.Errorf("%w: %s", ErrGroupNotFound, id)
+		}
+
+		return fail(err)
+	}
+
+	// 2. delete group relationships from spice db
+	filters := []*pb.RelationshipFilter{
+		// 2.a remove all relationships from this group
+		{
+			ResourceType:       e.namespaced(group.Type),
+			OptionalResourceId: group.ID.String(),
+		},
+	}
+
+	// 2.b remove all relationships to this group
+	for _, rType := range e.schema {
+		if !resourceTypeRelatesTo(rType, group.Type) {
+			continue
+		}
+
+		filters = append(filters, &pb.RelationshipFilter{
+			ResourceType: e.namespaced(rType.Name),
+			OptionalSubjectFilter: &pb.SubjectFilter{
+				SubjectType:       e.namespaced(group.Type),
+				OptionalSubjectId: group.ID.String(),
+			},
+		})
+	}
+
+	for _, filter := range filters {
+		if err := e.deleteRelationships(ctx, filter); err != nil {
+			logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+			return fail(err)
+		}
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		// As with roles, spicedb changes have already been applied at this
+		// point, leaving the group without relationships in the permissions-api
+		// DB, to be cleaned up manually.
+		return fail(err)
+	}
+
+	return nil
+}
+
+// resourceTypeRelatesTo reports whether any relationship of the resource type
+// may have a subject of the given type.
+func resourceTypeRelatesTo(rType types.ResourceType, subjectType string) bool {
+	for _, rel := range rType.Relationships {
+		for _, tt := range rel.Types {
+			if tt.Name == subjectType {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ListGroupMembers lists the direct members of a group.
+func (e *engine) ListGroupMembers(ctx context.Context, id gidx.PrefixedID) ([]types.Resource, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListGroupMembers",
+		trace.WithAttributes(attribute.Stringer("group_id", id)),
+	)
+	defer span.End()
+
+	fail := func(err error) ([]types.Resource, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	def, err := e.groupDefinition()
+	if err != nil {
+		return fail(err)
+	}
+
+	group, err := e.groupResource(def, id)
+	if err != nil {
+		return fail(err)
+	}
+
+	members, err := e.directGroupMembers(ctx, def, group)
+	if err != nil {
+		return fail(err)
+	}
+
+	return members, nil
+}
+
+// directGroupMembers returns the direct members of a group, including member groups.
+func (e *engine) directGroupMembers(ctx context.Context, def *iapl.RBACGroupDefinition, group types.Resource) ([]types.Resource, error) {
+	relations := []string{def.MemberRelation}
+
+	if def.SubgroupRelation != "" {
+		relations = append(relations, def.SubgroupRelation)
+	}
+
+	members := []types.Resource{}
+
+	for _, relation := range relations {
+		filter := &pb.RelationshipFilter{
+			ResourceType:       e.namespaced(group.Type),
+			OptionalResourceId: group.ID.String(),
+			OptionalRelation:   relation,
+		}
+
+		err := e.streamRelationships(ctx, filter, func(rel *pb.Relationship) error {
+			memberID, err := gidx.Parse(rel.Subject.Object.ObjectId)
+			if err != nil {
+				return err
+			}
+
+			member, err := e.NewResourceFromID(memberID)
+			if err != nil {
+				return err
+			}
+
+			members = append(members, member)
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return members, nil
+}
+
+// ExpandGroupMembers lists all direct and transitive members of a group,
+// member groups are expanded to their members. Members are sorted by ID.
+func (e *engine) ExpandGroupMembers(ctx context.Context, id gidx.PrefixedID) ([]types.GroupMember, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ExpandGroupMembers",
+		trace.WithAttributes(attribute.Stringer("group_id", id)),
+	)
+	defer span.End()
+
+	fail := func(err error) ([]types.GroupMember, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	def, err := e.groupDefinition()
+	if err != nil {
+		return fail(err)
+	}
+
+	group, err := e.groupResource(def, id)
+	if err != nil {
+		return fail(err)
+	}
+
+	if _, err := e.store.GetGroupByID(ctx, id); err != nil {
+		if errors.Is(err, storage.ErrGroupNotFound) {
+			err = fmt.Errorf("%w: %s", ErrGroupNotFound, id)
+		}
+
+		return fail(err)
+	}
+
+	members := map[gidx.PrefixedID]*types.GroupMember{}
+	visited := map[gidx.PrefixedID]struct{}{group.ID: {}}
+	queue := []types.Resource{group}
+
+	// groups are walked breadth first, visited groups are skipped so
+	// memberships created before nesting was validated cannot loop forever.
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		direct, err := e.directGroupMembers(ctx, def, current)
+		if err != nil {
+			return fail(err)
+		}
+
+		for _, member := range direct {
+			if member.Type == def.Name {
+				if _, ok := visited[member.ID]; !ok {
+					visited[member.ID] = struct{}{}
+					queue = append(queue, member)
+				}
+
+				continue
+			}
+
+			if _, ok := members[member.ID]; !ok {
+				members[member.ID] = &types.GroupMember{Subject: member}
+			}
+
+			members[member.ID].Groups = append(members[member.ID].Groups, current.ID)
+		}
+	}
+
+	out := make([]types.GroupMember, 0, len(members))
+
+	for _, member := range members {
+		out = append(out, *member)
+	}
+
+	slices.SortFunc(out, func(a, b types.GroupMember) int {
+		return strings.Compare(a.Subject.ID.String(), b.Subject.ID.String())
+	})
+
+	span.SetAttributes(
+		attribute.Int("members", len(out)),
+		attribute.Int("groups", len(visited)),
+	)
+
+	return out, nil
+}
+
+// AddGroupMembers adds members to a group, members already in the group are ignored.
+func (e *engine) AddGroupMembers(ctx context.Context, id gidx.PrefixedID, members ...types.Resource) error {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.AddGroupMembers",
+		trace.WithAttributes(attribute.Stringer("group_id", id)),
+	)
+	defer span.End()
+
+	return e.updateGroupMembers(ctx, span, id, pb.RelationshipUpdate_OPERATION_TOUCH, members)
+}
+
+// RemoveGroupMembers removes members from a group, members not in the group are ignored.
+func (e *engine) RemoveGroupMembers(ctx context.Context, id gidx.PrefixedID, members ...types.Resource) error {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.RemoveGroupMembers",
+		trace.WithAttributes(attribute.Stringer("group_id", id)),
+	)
+	defer span.End()
+
+	return e.updateGroupMembers(ctx, span, id, pb.RelationshipUpdate_OPERATION_DELETE, members)
+}
+
+func (e *engine) updateGroupMembers(
+	ctx context.Context,
+	span trace.Span,
+	id gidx.PrefixedID,
+	op pb.RelationshipUpdate_Operation,
+	members []types.Resource,
+) error {
+	fail := func(err error) error {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	if len(members) == 0 {
+		return fail(fmt.Errorf("%w: no members provided", ErrInvalidArgument))
+	}
+
+	def, err := e.groupDefinition()
+	if err != nil {
+		return fail(err)
+	}
+
+	group, err := e.groupResource(def, id)
+	if err != nil {
+		return fail(err)
+	}
+
+	if _, err := e.store.GetGroupByID(ctx, id); err != nil {
+		if errors.Is(err, storage.ErrGroupNotFound) {
+			err = fmt.Errorf("%w: %s", ErrGroupNotFound, id)
+		}
+
+		return fail(err)
+	}
+
+	updates := make([]*pb.RelationshipUpdate, len(members))
+
+	for i, member := range members {
+		rel, err := e.groupMemberRelationship(def, group, member)
+		if err != nil {
+			return fail(err)
+		}
+
+		if op == pb.RelationshipUpdate_OPERATION_TOUCH && member.Type == def.Name {
+			if err := e.validateGroupNesting(ctx, def, group, member); err != nil {
+				return fail(err)
+			}
+		}
+
+		updates[i] = &pb.RelationshipUpdate{
+			Operation:    op,
+			Relationship: rel,
+		}
+	}
+
+	if err := e.applyUpdates(ctx, updates); err != nil {
+		return fail(err)
+	}
+
+	return nil
+}
+
+// groupNestingRelation returns the relation connecting a group to its member groups.
+func groupNestingRelation(def *iapl.RBACGroupDefinition) string {
+	if def.SubgroupRelation != "" {
+		return def.SubgroupRelation
+	}
+
+	return def.MemberRelation
+}
+
+// validateGroupNesting ensures adding the member group to the group neither
+// creates a cycle nor nests groups deeper than the configured maximum depth,
+// which bounds the number of hops SpiceDB resolves for checks.
+func (e *engine) validateGroupNesting(ctx context.Context, def *iapl.RBACGroupDefinition, group, member types.Resource) error {
+	relation := groupNestingRelation(def)
+
+	subgroups := func(ctx context.Context, g types.Resource) ([]types.Resource, error) {
+		return e.relatedGroups(ctx, def, &pb.RelationshipFilter{
+			ResourceType:       e.namespaced(def.Name),
+			OptionalResourceId: g.ID.String(),
+			OptionalRelation:   relation,
+			OptionalSubjectFilter: &pb.SubjectFilter{
+				SubjectType: e.namespaced(def.Name),
+			},
+		}, func(rel *pb.Relationship) string { return rel.Subject.Object.ObjectId })
+	}
+
+	parents := func(ctx context.Context, g types.Resource) ([]types.Resource, error) {
+		return e.relatedGroups(ctx, def, &pb.RelationshipFilter{
+			ResourceType:     e.namespaced(def.Name),
+			OptionalRelation: relation,
+			OptionalSubjectFilter: &pb.SubjectFilter{
+				SubjectType:       e.namespaced(def.Name),
+				OptionalSubjectId: g.ID.String(),
+			},
+		}, func(rel *pb.Relationship) string { return rel.Resource.ObjectId })
+	}
+
+	// the group is nested below the member if it can be reached from it
+	below, found, err := e.walkGroups(ctx, member, group.ID, subgroups)
+	if err != nil {
+		return err
+	}
+
+	if found {
+		return fmt.Errorf("%w: %s is already nested in %s", ErrGroupCycle, group.ID, member.ID)
+	}
+
+	above, _, err := e.walkGroups(ctx, group, "", parents)
+	if err != nil {
+		return err
+	}
+
+	if depth := above + 1 + below; depth > e.maxGroupDepth {
+		return fmt.Errorf("%w: adding %s to %s nests groups %d levels deep, at most %d are allowed",
+			ErrGroupNestingTooDeep, member.ID, group.ID, depth, e.maxGroupDepth)
+	}
+
+	return nil
+}
+
+// walkGroups walks the group hierarchy level by level from the start group,
+// using next to find the groups related to a group. It returns the number of
+// levels walked and whether the target group was reached. The walk stops once
+// more than the maximum nesting depth of levels were walked.
+func (e *engine) walkGroups(
+	ctx context.Context,
+	start types.Resource,
+	target gidx.PrefixedID,
+	next func(context.Context, types.Resource) ([]types.Resource, error),
+) (int, bool, error) {
+	visited := map[gidx.PrefixedID]struct{}{start.ID: {}}
+	level := []types.Resource{start}
+	depth := 0
+
+	for len(level) > 0 && depth <= e.maxGroupDepth {
+		var nextLevel []types.Resource
+
+		for _, g := range level {
+			related, err := next(ctx, g)
+			if err != nil {
+				return 0, false, err
+			}
+
+			for _, r := range related {
+				if r.ID == target {
+					return depth + 1, true, nil
+				}
+
+				if _, ok := visited[r.ID]; ok {
+					continue
+				}
+
+				visited[r.ID] = struct{}{}
+
+				nextLevel = append(nextLevel, r)
+			}
+		}
+
+		if len(nextLevel) == 0 {
+			break
+		}
+
+		depth++
+		level = nextLevel
+	}
+
+	return depth, false, nil
+}
+
+// relatedGroups returns the groups of the relationships matching the filter,
+// objectID selects the side of the relationship holding the group.
+func (e *engine) relatedGroups(
+	ctx context.Context,
+	def *iapl.RBACGroupDefinition,
+	filter *pb.RelationshipFilter,
+	objectID func(*pb.Relationship) string,
+) ([]types.Resource, error) {
+	groups := []types.Resource{}
+
+	err := e.streamRelationships(ctx, filter, func(rel *pb.Relationship) error {
+		id, err := gidx.Parse(objectID(rel))
+		if err != nil {
+			return err
+		}
+
+		group, err := e.groupResource(def, id)
+		if err != nil {
+			return err
+		}
+
+		groups = append(groups, group)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,256 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestGroupMembers(t *testing.T) {
+	namespace := "testgroups"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+	member, err := e.NewResourceFromIDString("idntusr-member")
+	require.NoError(t, err)
+	nested, err := e.NewResourceFromIDString("idntusr-nested")
+	require.NoError(t, err)
+
+	admins, err := e.CreateGroup(ctx, actor, tenant, "admins", "tenant admins")
+	require.NoError(t, err)
+	assert.Equal(t, tenant.ID, admins.OwnerID)
+
+	_, err = e.CreateGroup(ctx, actor, tenant, "admins", "")
+	assert.ErrorIs(t, err, storage.ErrGroupNameTaken)
+
+	operators, err := e.CreateGroup(ctx, actor, tenant, "operators", "")
+	require.NoError(t, err)
+
+	adminsRes, err := e.NewResourceFromID(admins.ID)
+	require.NoError(t, err)
+	operatorsRes, err := e.NewResourceFromID(operators.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, e.AddGroupMembers(ctx, operators.ID, nested))
+
+	role, err := e.CreateRoleV2(ctx, actor, tenant, "lb_viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
+	roleRes, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	_, err = e.CreateRoleBinding(ctx, actor, tenant, roleRes, []types.RoleBindingSubject{{SubjectResource: adminsRes}})
+	require.NoError(t, err)
+
+	tc := []testingx.TestCase[[]types.Resource, []types.Resource]{
+		{
+			Name:  "InvalidMemberType",
+			Input: []types.Resource{tenant},
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Resource]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidGroupMemberType)
+			},
+		},
+		{
+			Name:  "Self",
+			Input: []types.Resource{adminsRes},
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.Resource]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidGroupMemberType)
+			},
+		},
+		{
+			Name:  "Success",
+			Input: []types.Resource{member, operatorsRes},
+			Sync:  true,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]types.Resource]) {
+				require.NoError(t, res.Err)
+				assert.ElementsMatch(t, []types.Resource{member, operatorsRes}, res.Success)
+
+				err := e.SubjectHasPermission(ctx, member, "loadbalancer_get", tenant)
+				assert.NoError(t, err)
+
+				err = e.SubjectHasPermission(ctx, nested, "loadbalancer_get", tenant)
+				assert.NoError(t, err)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, members []types.Resource) testingx.TestResult[[]types.Resource] {
+		if err := e.AddGroupMembers(ctx, admins.ID, members...); err != nil {
+			return testingx.TestResult[[]types.Resource]{Err: err}
+		}
+
+		out, err := e.ListGroupMembers(ctx, admins.ID)
+
+		return testingx.TestResult[[]types.Resource]{Success: out, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+
+	require.NoError(t, e.RemoveGroupMembers(ctx, admins.ID, operatorsRes))
+
+	err = e.SubjectHasPermission(ctx, nested, "loadbalancer_get", tenant)
+	assert.ErrorIs(t, err, ErrActionNotAssigned)
+
+	require.NoError(t, e.DeleteGroup(ctx, admins.ID))
+
+	err = e.SubjectHasPermission(ctx, member, "loadbalancer_get", tenant)
+	assert.ErrorIs(t, err, ErrActionNotAssigned)
+
+	_, err = e.GetGroup(ctx, admins.ID)
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+
+	groups, err := e.ListGroups(ctx, tenant)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, operators.ID, groups[0].ID)
+}
+
+func TestGroupNesting(t *testing.T) {
+	namespace := "testgroupnesting"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+	e.maxGroupDepth = 2
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	newGroup := func(name string) types.Resource {
+		group, err := e.CreateGroup(ctx, actor, tenant, name, "")
+		require.NoError(t, err)
+
+		res, err := e.NewResourceFromID(group.ID)
+		require.NoError(t, err)
+
+		return res
+	}
+
+	top := newGroup("top")
+	middle := newGroup("middle")
+	bottom := newGroup("bottom")
+	other := newGroup("other")
+
+	type input struct {
+		group  types.Resource
+		member types.Resource
+	}
+
+	tc := []testingx.TestCase[input, any]{
+		{
+			Name:  "Nested",
+			Input: input{group: top, member: middle},
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				require.NoError(t, res.Err)
+			},
+		},
+		{
+			Name:  "DirectCycle",
+			Input: input{group: middle, member: top},
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.ErrorIs(t, res.Err, ErrGroupCycle)
+			},
+		},
+		{
+			Name:  "MaxDepth",
+			Input: input{group: middle, member: bottom},
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				require.NoError(t, res.Err)
+			},
+		},
+		{
+			Name:  "IndirectCycle",
+			Input: input{group: bottom, member: top},
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.ErrorIs(t, res.Err, ErrGroupCycle)
+			},
+		},
+		{
+			Name:  "TooDeepBelow",
+			Input: input{group: bottom, member: other},
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.ErrorIs(t, res.Err, ErrGroupNestingTooDeep)
+			},
+		},
+		{
+			Name:  "TooDeepAbove",
+			Input: input{group: other, member: top},
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.ErrorIs(t, res.Err, ErrGroupNestingTooDeep)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in input) testingx.TestResult[any] {
+		err := e.AddGroupMembers(ctx, in.group.ID, in.member)
+
+		return testingx.TestResult[any]{Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
+
+func TestExpandGroupMembers(t *testing.T) {
+	namespace := "testgroupexpand"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+	alice, err := e.NewResourceFromIDString("idntusr-alice")
+	require.NoError(t, err)
+	bob, err := e.NewResourceFromIDString("idntusr-bob")
+	require.NoError(t, err)
+
+	admins, err := e.CreateGroup(ctx, actor, tenant, "admins", "")
+	require.NoError(t, err)
+	operators, err := e.CreateGroup(ctx, actor, tenant, "operators", "")
+	require.NoError(t, err)
+
+	operatorsRes, err := e.NewResourceFromID(operators.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, e.AddGroupMembers(ctx, admins.ID, alice, operatorsRes))
+	require.NoError(t, e.AddGroupMembers(ctx, operators.ID, alice, bob))
+
+	members, err := e.ExpandGroupMembers(ctx, admins.ID)
+	require.NoError(t, err)
+
+	expected := []types.GroupMember{
+		{Subject: alice},
+		{Subject: bob, Groups: []gidx.PrefixedID{operators.ID}},
+	}
+
+	require.Len(t, members, len(expected))
+
+	for i, member := range members {
+		assert.Equal(t, expected[i].Subject, member.Subject)
+	}
+
+	assert.ElementsMatch(t, []gidx.PrefixedID{admins.ID, operators.ID}, members[0].Groups)
+	assert.Equal(t, expected[1].Groups, members[1].Groups)
+
+	_, err = e.ExpandGroupMembers(ctx, "idntgrp-unknown")
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+}
@@ -0,0 +1,335 @@
+package query
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// InvitationIDPrefix is the ID prefix of role binding invitations.
+	InvitationIDPrefix = "perminv"
+
+	// invitationTokenBytes is the number of random bytes in an invitation token.
+	invitationTokenBytes = 32
+)
+
+// newInvitationToken generates a random invitation token and returns it with
+// its hash, only the hash is stored.
+func newInvitationToken() (token, hash string, err error) {
+	b := make([]byte, invitationTokenBytes)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(b)
+
+	return token, hashInvitationToken(token), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func (e *engine) CreateInvitation(
+	ctx context.Context,
+	actor, resource, roleResource types.Resource,
+	email string,
+	ttl time.Duration,
+) (types.Invitation, string, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.CreateInvitation",
+		trace.WithAttributes(
+			attribute.Stringer("role_id", roleResource.ID),
+			attribute.Stringer("resource_id", resource.ID),
+		),
+	)
+	defer span.End()
+
+	if ttl <= 0 {
+		err := fmt.Errorf("%w: invitation must have a positive expiry", ErrInvalidArgument)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Invitation{}, "", err
+	}
+
+	if err := e.isRoleBindable(ctx, roleResource, resource); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Invitation{}, "", err
+	}
+
+	// the role is bound on redemption on behalf of the actor
+	if err := e.CheckRoleBindingDelegation(ctx, actor, resource, roleResource); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Invitation{}, "", err
+	}
+
+	dbrole, err := e.store.GetRoleByID(ctx, roleResource.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoRoleFound) {
+			err = fmt.Errorf("%w: role %s", ErrRoleNotFound, roleResource.ID)
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Invitation{}, "", err
+	}
+
+	// invited subjects are only known once redeemed, only the role is checked here
+	if err := e.checkTenantSettings(ctx, resource, dbrole.ResourceID, nil); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Invitation{}, "", err
+	}
+
+	id, err := gidx.NewID(InvitationIDPrefix)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Invitation{}, "", err
+	}
+
+	token, tokenHash, err := newInvitationToken()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Invitation{}, "", err
+	}
+
+	now := time.Now()
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Invitation{}, "", err
+	}
+
+	inv, err := e.store.CreateInvitation(dbCtx, types.Invitation{
+		ID:         id,
+		ResourceID: resource.ID,
+		RoleID:     roleResource.ID,
+		Email:      email,
+		CreatedBy:  actor.ID,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(ttl),
+	}, tokenHash)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return types.Invitation{}, "", err
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return types.Invitation{}, "", err
+	}
+
+	return inv, token, nil
+}
+
+func (e *engine) GetInvitation(ctx context.Context, id gidx.PrefixedID) (types.Invitation, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.GetInvitation",
+		trace.WithAttributes(attribute.Stringer("invitation_id", id)),
+	)
+	defer span.End()
+
+	inv, err := e.store.GetInvitationByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrInvitationNotFound) {
+			err = fmt.Errorf("%w: %s", ErrInvitationNotFound, id)
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Invitation{}, err
+	}
+
+	return inv, nil
+}
+
+func (e *engine) ListInvitations(ctx context.Context, resource types.Resource) ([]types.Invitation, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListInvitations",
+		trace.WithAttributes(attribute.Stringer("resource_id", resource.ID)),
+	)
+	defer span.End()
+
+	invitations, err := e.store.ListResourceInvitations(ctx, resource.ID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+func (e *engine) DeleteInvitation(ctx context.Context, id gidx.PrefixedID) error {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.DeleteInvitation",
+		trace.WithAttributes(attribute.Stringer("invitation_id", id)),
+	)
+	defer span.End()
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	if err := e.store.DeleteInvitation(dbCtx, id); err != nil {
+		if errors.Is(err, storage.ErrInvitationNotFound) {
+			err = fmt.Errorf("%w: %s", ErrInvitationNotFound, id)
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return err
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return err
+	}
+
+	return nil
+}
+
+// RedeemInvitation creates the role binding of the invitation for the subject.
+// Tokens are bearer tokens: the email of the invitation is not compared with
+// the subject, any subject holding the token may redeem it. The invitation
+// stays locked until the role binding is created, so a token is only
+// redeemed once.
+func (e *engine) RedeemInvitation(ctx context.Context, subject types.Resource, token string) (types.RoleBinding, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.RedeemInvitation",
+		trace.WithAttributes(attribute.Stringer("subject_id", subject.ID)),
+	)
+	defer span.End()
+
+	fail := func(err error) (types.RoleBinding, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBinding{}, err
+	}
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	inv, err := e.store.LockInvitationByTokenHash(dbCtx, hashInvitationToken(token))
+	if err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		if errors.Is(err, storage.ErrInvitationNotFound) {
+			err = ErrInvitationNotFound
+		}
+
+		return fail(err)
+	}
+
+	span.SetAttributes(attribute.Stringer("invitation_id", inv.ID))
+
+	switch {
+	case inv.RedeemedAt != nil:
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(fmt.Errorf("%w: %s", ErrInvitationRedeemed, inv.ID))
+	case !time.Now().Before(inv.ExpiresAt):
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(fmt.Errorf("%w: %s", ErrInvitationExpired, inv.ID))
+	}
+
+	resource, err := e.NewResourceFromID(inv.ResourceID)
+	if err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(err)
+	}
+
+	roleResource, err := e.NewResourceFromID(inv.RoleID)
+	if err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(err)
+	}
+
+	inviter, err := e.NewResourceFromID(inv.CreatedBy)
+	if err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(err)
+	}
+
+	// the role-binding is created on behalf of the inviter, whose delegation
+	// of the role was checked when the invitation was created
+	rb, err := e.createRoleBinding(ctx, inviter, resource, roleResource, []types.RoleBindingSubject{{SubjectResource: subject}}, nil)
+	if err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(err)
+	}
+
+	rbResource := types.Resource{Type: e.rbac.RoleBindingResource.Name, ID: rb.ID}
+
+	if _, err := e.store.RedeemInvitation(dbCtx, inv.ID, subject.ID, rb.ID); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(ctx), e.DeleteRoleBinding(ctx, rbResource))
+
+		return fail(err)
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(ctx), e.DeleteRoleBinding(ctx, rbResource))
+
+		return fail(err)
+	}
+
+	return rb, nil
+}
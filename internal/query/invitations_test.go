@@ -0,0 +1,108 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestRedeemInvitation(t *testing.T) {
+	namespace := "testinvitations"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+	invitee, err := e.NewResourceFromIDString("idntusr-invitee")
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, actor, tenant, "lb_viewer", []string{"loadbalancer_list", "loadbalancer_get"})
+	require.NoError(t, err)
+
+	roleRes, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	inv, token, err := e.CreateInvitation(ctx, actor, tenant, roleRes, "invitee@example.com", time.Hour)
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.Equal(t, "invitee@example.com", inv.Email)
+
+	_, expiredToken, err := e.CreateInvitation(ctx, actor, tenant, roleRes, "", time.Nanosecond)
+	require.NoError(t, err)
+
+	_, _, err = e.CreateInvitation(ctx, actor, tenant, roleRes, "", 0)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	invitations, err := e.ListInvitations(ctx, tenant)
+	require.NoError(t, err)
+	assert.Len(t, invitations, 2)
+
+	tc := []testingx.TestCase[string, types.RoleBinding]{
+		{
+			Name:  "UnknownToken",
+			Input: "unknown",
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+				assert.ErrorIs(t, res.Err, ErrInvitationNotFound)
+			},
+		},
+		{
+			Name:  "Expired",
+			Input: expiredToken,
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+				assert.ErrorIs(t, res.Err, ErrInvitationExpired)
+			},
+		},
+		{
+			Name:  "Success",
+			Input: token,
+			Sync:  true,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, role.ID, res.Success.RoleID)
+				assert.Equal(t, tenant.ID, res.Success.ResourceID)
+				require.Len(t, res.Success.SubjectIDs, 1)
+				assert.Equal(t, invitee.ID, res.Success.SubjectIDs[0])
+				assert.Equal(t, actor.ID, res.Success.CreatedBy)
+
+				redeemed, err := e.GetInvitation(ctx, inv.ID)
+				require.NoError(t, err)
+				assert.Equal(t, invitee.ID, redeemed.RedeemedBy)
+				assert.Equal(t, res.Success.ID, redeemed.RoleBindingID)
+
+				err = e.SubjectHasPermission(ctx, invitee, "loadbalancer_get", tenant)
+				assert.NoError(t, err)
+			},
+		},
+		{
+			Name:  "AlreadyRedeemed",
+			Input: token,
+			Sync:  true,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+				assert.ErrorIs(t, res.Err, ErrInvitationRedeemed)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, token string) testingx.TestResult[types.RoleBinding] {
+		rb, err := e.RedeemInvitation(ctx, invitee, token)
+
+		return testingx.TestResult[types.RoleBinding]{Success: rb, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+
+	require.NoError(t, e.DeleteInvitation(ctx, inv.ID))
+
+	_, err = e.GetInvitation(ctx, inv.ID)
+	assert.ErrorIs(t, err, ErrInvitationNotFound)
+}
@@ -0,0 +1,83 @@
+package query
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// outcomeError is the outcome of permission checks which failed.
+	outcomeError = "error"
+
+	// invalidActionLabel is the action label of permission checks of actions
+	// not defined for the resource type.
+	invalidActionLabel = "invalid"
+)
+
+var (
+	checksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "permissions_api",
+		Subsystem: "engine",
+		Name:      "checks_total",
+		Help:      "Number of permission checks by action and outcome (allowed, denied or error).",
+	}, []string{"action", "outcome"})
+
+	checkDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "permissions_api",
+		Subsystem: "engine",
+		Name:      "check_duration_seconds",
+		Help:      "Duration of permission checks by outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"outcome"})
+
+	mutationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "permissions_api",
+		Subsystem: "engine",
+		Name:      "mutations_total",
+		Help:      "Number of role, role-binding and relationship changes by type, e.g. role_created.",
+	}, []string{"type"})
+
+	degradedDecisions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "permissions_api",
+		Subsystem: "engine",
+		Name:      "degraded_decisions_total",
+		Help:      "Number of permission checks answered while SpiceDB was unavailable by source (fail_open, cache or fail_closed).",
+	}, []string{"source"})
+
+	checkCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "permissions_api",
+		Subsystem: "engine",
+		Name:      "check_cache_lookups_total",
+		Help:      "Number of permission check cache lookups by result (hit or miss).",
+	}, []string{"result"})
+
+	decisionsDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "permissions_api",
+		Subsystem: "engine",
+		Name:      "decisions_dropped_total",
+		Help:      "Number of permission check decisions which could not be recorded in the decision log.",
+	})
+)
+
+// checkActionLabel returns the action label of the metrics of a permission
+// check. Actions not defined by the policy for the resource type are labelled
+// invalid, as they are given by the caller and would create any number of
+// series.
+func (e *engine) checkActionLabel(resource types.Resource, action string) string {
+	if e.validateResourceActions(resource, action) != nil {
+		return invalidActionLabel
+	}
+
+	return action
+}
+
+// observeCheck records a permission check started at the given time, action
+// is the action label as returned by checkActionLabel.
+func observeCheck(action, outcome string, start time.Time) {
+	checksTotal.WithLabelValues(action, outcome).Inc()
+	checkDuration.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+}
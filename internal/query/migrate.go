@@ -0,0 +1,232 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// migratingRoleSuffix is appended to the name of the v2 role replacing a v1
+// role until the v1 role is deleted, as roles on the same resource must have
+// unique names.
+const migratingRoleSuffix = " (migrating)"
+
+// ListRoleV1Resources lists the resources with v1 roles, sorted by ID,
+// starting after the given resource ID.
+func (e *engine) ListRoleV1Resources(ctx context.Context, after gidx.PrefixedID, limit int) ([]types.Resource, error) {
+	ids, err := e.store.ListRoleResourceIDs(ctx, RolePrefix, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]types.Resource, len(ids))
+
+	for i, id := range ids {
+		if resources[i], err = e.NewResourceFromID(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return resources, nil
+}
+
+// MigrateRolesV1 replaces the v1 roles of a resource with v2 roles owned by
+// the resource, binding each v2 role on the resource to the subjects the v1
+// role was assigned to. Every role is migrated in steps:
+//
+//  1. a v2 role with the actions of the v1 role is created under a temporary name
+//  2. the v2 role is bound to the subjects of the v1 role
+//  3. the v1 role is deleted
+//  4. the v2 role is renamed to the name of the v1 role
+//
+// Subjects keep their permissions throughout, and an interrupted migration
+// continues with the step it was interrupted at when it is repeated. On dry
+// runs the v1 roles are only checked and nothing is changed.
+func (e *engine) MigrateRolesV1(ctx context.Context, actor, resource types.Resource, dryRun bool) ([]types.RoleMigration, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.MigrateRolesV1",
+		trace.WithAttributes(
+			attribute.Stringer("resource_id", resource.ID),
+			attribute.Bool("dry_run", dryRun),
+		),
+	)
+	defer span.End()
+
+	v1Roles, err := e.ListRoles(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	v2Roles, err := e.listMigrationRolesV2(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	v2ByName := make(map[string]types.Role, len(v2Roles))
+
+	for _, role := range v2Roles {
+		v2ByName[role.Name] = role
+	}
+
+	results := make([]types.RoleMigration, 0, len(v1Roles))
+	migrating := make(map[string]struct{}, len(v1Roles))
+
+	for _, v1Role := range v1Roles {
+		migrating[v1Role.Name+migratingRoleSuffix] = struct{}{}
+
+		result := types.RoleMigration{V1Role: v1Role}
+
+		result.Subjects, err = e.ListAssignments(ctx, v1Role)
+		if err == nil {
+			err = e.validateRoleMigration(resource, v1Role, v2ByName)
+		}
+
+		if err == nil && !dryRun {
+			tmpRole, ok := v2ByName[v1Role.Name+migratingRoleSuffix]
+
+			result.V2Role, result.RoleBinding, err = e.migrateRoleV1(ctx, actor, resource, v1Role, tmpRole, ok, result.Subjects)
+		}
+
+		result.Err = err
+
+		results = append(results, result)
+	}
+
+	// v2 roles with a temporary name but without a v1 role were interrupted
+	// after the v1 role was deleted and only need to be renamed.
+	for _, tmpRole := range v2Roles {
+		if _, ok := migrating[tmpRole.Name]; ok || !strings.HasSuffix(tmpRole.Name, migratingRoleSuffix) {
+			continue
+		}
+
+		result := types.RoleMigration{
+			V1Role: types.Role{
+				Name:       strings.TrimSuffix(tmpRole.Name, migratingRoleSuffix),
+				Actions:    tmpRole.Actions,
+				ResourceID: resource.ID,
+			},
+		}
+
+		if !dryRun {
+			result.V2Role, result.Err = e.renameMigratedRole(ctx, actor, tmpRole, result.V1Role.Name)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// listMigrationRolesV2 lists the v2 roles owned by the resource, resources
+// which cannot own v2 roles have none.
+func (e *engine) listMigrationRolesV2(ctx context.Context, resource types.Resource) ([]types.Role, error) {
+	if !slices.Contains(e.rbac.RoleOwners, resource.Type) {
+		return nil, nil
+	}
+
+	return e.ListRolesV2(ctx, resource)
+}
+
+// validateRoleMigration checks the v1 role can be replaced by a v2 role owned
+// by the resource.
+func (e *engine) validateRoleMigration(resource types.Resource, v1Role types.Role, v2ByName map[string]types.Role) error {
+	if !slices.Contains(e.rbac.RoleOwners, resource.Type) {
+		return fmt.Errorf("%w: %s is not a role owner", ErrRoleNotMigratable, resource.Type)
+	}
+
+	if _, ok := v2ByName[v1Role.Name]; ok {
+		return fmt.Errorf("%w: v2 role %s already exists", ErrRoleNotMigratable, v1Role.Name)
+	}
+
+	allActions := e.AllActions()
+
+	for _, action := range v1Role.Actions {
+		if !slices.Contains(allActions, action) {
+			return fmt.Errorf("%w: action %s cannot be granted by v2 roles", ErrRoleNotMigratable, action)
+		}
+	}
+
+	return nil
+}
+
+// migrateRoleV1 runs the steps of migrating a v1 role not yet done, tmpRole
+// is the v2 role created by an earlier, interrupted migration if exists is set.
+func (e *engine) migrateRoleV1(
+	ctx context.Context,
+	actor, resource types.Resource,
+	v1Role, tmpRole types.Role, exists bool,
+	subjects []types.Resource,
+) (types.Role, types.RoleBinding, error) {
+	var err error
+
+	if !exists {
+		tmpRole, err = e.createRoleV2(ctx, actor, resource, v1Role.Name+migratingRoleSuffix, v1Role.Actions)
+		if err != nil {
+			return types.Role{}, types.RoleBinding{}, fmt.Errorf("creating v2 role: %w", err)
+		}
+	}
+
+	tmpRoleResource, err := e.NewResourceFromID(tmpRole.ID)
+	if err != nil {
+		return types.Role{}, types.RoleBinding{}, err
+	}
+
+	var rb types.RoleBinding
+
+	if len(subjects) != 0 {
+		bindings, err := e.ListRoleBindings(ctx, resource, &tmpRoleResource)
+		if err != nil {
+			return tmpRole, types.RoleBinding{}, fmt.Errorf("listing role-bindings: %w", err)
+		}
+
+		if len(bindings) != 0 {
+			rb = bindings[0]
+		} else {
+			rbSubjects := make([]types.RoleBindingSubject, len(subjects))
+
+			for i, subject := range subjects {
+				rbSubjects[i] = types.RoleBindingSubject{SubjectResource: subject}
+			}
+
+			rb, err = e.createRoleBinding(ctx, actor, resource, tmpRoleResource, rbSubjects, nil)
+			if err != nil {
+				return tmpRole, types.RoleBinding{}, fmt.Errorf("creating role-binding: %w", err)
+			}
+		}
+	}
+
+	v1RoleResource, err := e.NewResourceFromID(v1Role.ID)
+	if err != nil {
+		return tmpRole, rb, err
+	}
+
+	if err := e.DeleteRole(ctx, v1RoleResource); err != nil {
+		return tmpRole, rb, fmt.Errorf("deleting v1 role: %w", err)
+	}
+
+	role, err := e.renameMigratedRole(ctx, actor, tmpRole, v1Role.Name)
+
+	return role, rb, err
+}
+
+// renameMigratedRole gives the v2 role the name of the deleted v1 role it replaces.
+func (e *engine) renameMigratedRole(ctx context.Context, actor types.Resource, tmpRole types.Role, name string) (types.Role, error) {
+	roleResource, err := e.NewResourceFromID(tmpRole.ID)
+	if err != nil {
+		return tmpRole, err
+	}
+
+	role, err := e.updateRoleV2(ctx, actor, roleResource, name, tmpRole.Actions)
+	if err != nil {
+		return tmpRole, fmt.Errorf("renaming v2 role: %w", err)
+	}
+
+	return role, nil
+}
@@ -0,0 +1,94 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+)
+
+func TestMigrateRolesV1(t *testing.T) {
+	namespace := "infratestmigrate"
+	ctx := context.Background()
+
+	policy, err := iapl.NewPolicyFromDirectory(PolicyDir)
+	require.NoError(t, err)
+
+	e := testEngine(ctx, t, namespace, policy)
+
+	tenant, err := e.NewResourceFromID(gidx.MustNewID("tnntten"))
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromID(gidx.MustNewID("idntusr"))
+	require.NoError(t, err)
+	subject, err := e.NewResourceFromID(gidx.MustNewID("idntusr"))
+	require.NoError(t, err)
+
+	viewer, err := e.CreateRole(ctx, actor, tenant, "viewer", []string{"role_get", "role_list"})
+	require.NoError(t, err)
+
+	require.NoError(t, e.AssignSubjectRole(ctx, subject, viewer))
+
+	unassigned, err := e.CreateRole(ctx, actor, tenant, "unassigned", []string{"role_get"})
+	require.NoError(t, err)
+
+	// an interrupted migration of the unassigned role, stopped after the v1
+	// role was deleted
+	_, err = e.CreateRoleV2(ctx, actor, tenant, "unassigned"+migratingRoleSuffix, []string{"role_get"})
+	require.NoError(t, err)
+
+	unassignedRes, err := e.NewResourceFromID(unassigned.ID)
+	require.NoError(t, err)
+	require.NoError(t, e.DeleteRole(ctx, unassignedRes))
+
+	resources, err := e.ListRoleV1Resources(ctx, "", 10)
+	require.NoError(t, err)
+	assert.Contains(t, resources, tenant)
+
+	// dry runs change nothing
+	results, err := e.MigrateRolesV1(ctx, actor, tenant, true)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "viewer", results[0].V1Role.Name)
+	require.Len(t, results[0].Subjects, 1)
+	assert.Equal(t, subject.ID, results[0].Subjects[0].ID)
+	assert.Empty(t, results[0].V2Role.ID)
+	assert.NoError(t, results[0].Err)
+
+	v1Roles, err := e.ListRoles(ctx, tenant)
+	require.NoError(t, err)
+	assert.Len(t, v1Roles, 1)
+
+	results, err = e.MigrateRolesV1(ctx, actor, tenant, false)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	for _, result := range results {
+		require.NoError(t, result.Err, result.V1Role.Name)
+		assert.Equal(t, result.V1Role.Name, result.V2Role.Name)
+		assert.ElementsMatch(t, result.V1Role.Actions, result.V2Role.Actions)
+	}
+
+	assert.NotEmpty(t, results[0].RoleBinding.ID)
+	assert.Empty(t, results[1].RoleBinding.ID)
+
+	v1Roles, err = e.ListRoles(ctx, tenant)
+	require.NoError(t, err)
+	assert.Empty(t, v1Roles)
+
+	v2Roles, err := e.ListRolesV2(ctx, tenant)
+	require.NoError(t, err)
+	assert.Len(t, v2Roles, 2)
+
+	// the subject keeps its permissions
+	require.NoError(t, e.SubjectHasPermission(ctx, subject, "role_list", tenant))
+
+	// migrating again does nothing
+	results, err = e.MigrateRolesV1(ctx, actor, tenant, false)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
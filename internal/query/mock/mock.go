@@ -3,6 +3,7 @@ package mock
 import (
 	"context"
 	"errors"
+	"time"
 
 	"go.infratographer.com/permissions-api/internal/iapl"
 	"go.infratographer.com/permissions-api/internal/query"
@@ -49,6 +50,34 @@ func (e *Engine) CreateRelationships(context.Context, []types.Relationship) erro
 	return args.Error(0)
 }
 
+// WriteRelationships returns the provided mock results.
+func (e *Engine) WriteRelationships(_ context.Context, writes []types.RelationshipWrite, preconditions []types.RelationshipPrecondition) (int, error) {
+	args := e.Called(writes, preconditions)
+
+	return args.Int(0), args.Error(1)
+}
+
+// ReadRelationships returns the provided mock results.
+func (e *Engine) ReadRelationships(_ context.Context, filter types.RelationshipFilter, limit int, cursor string) ([]types.RelationshipRecord, string, error) {
+	args := e.Called(filter, limit, cursor)
+
+	return args.Get(0).([]types.RelationshipRecord), args.String(1), args.Error(2)
+}
+
+// WatchRelationshipChanges calls fn with the changes the mock was set up
+// with, then returns the error the mock was set up with.
+func (e *Engine) WatchRelationshipChanges(_ context.Context, after string, fn func(types.RelationshipChange) error) error {
+	args := e.Called(after)
+
+	for _, change := range args.Get(0).([]types.RelationshipChange) {
+		if err := fn(change); err != nil {
+			return err
+		}
+	}
+
+	return args.Error(1)
+}
+
 // CreateRole creates a Role object and does not persist it anywhere.
 func (e *Engine) CreateRole(context.Context, types.Resource, types.Resource, string, []string) (types.Role, error) {
 	args := e.Called()
@@ -125,6 +154,25 @@ func (e *Engine) ListRelationshipsTo(context.Context, types.Resource) ([]types.R
 	return nil, nil
 }
 
+// StreamRelationshipsFrom calls fn with the relationships the mock was set up
+// with, then returns the error the mock was set up with.
+func (e *Engine) StreamRelationshipsFrom(_ context.Context, _ types.Resource, fn func(types.Relationship) error) error {
+	args := e.Called()
+
+	for _, rel := range args.Get(0).([]types.Relationship) {
+		if err := fn(rel); err != nil {
+			return err
+		}
+	}
+
+	return args.Error(1)
+}
+
+// StreamRelationshipsTo returns nothing but satisfies the Engine interface.
+func (e *Engine) StreamRelationshipsTo(context.Context, types.Resource, func(types.Relationship) error) error {
+	return nil
+}
+
 // ListRoles returns nothing but satisfies the Engine interface.
 func (e *Engine) ListRoles(context.Context, types.Resource) ([]types.Role, error) {
 	return nil, nil
@@ -149,6 +197,11 @@ func (e *Engine) DeleteRoleV2(context.Context, types.Resource) error {
 	return nil
 }
 
+// ForceDeleteRoleV2 does nothing but satisfies the Engine interface.
+func (e *Engine) ForceDeleteRoleV2(context.Context, types.Resource) (int, error) {
+	return 0, nil
+}
+
 // DeleteResourceRelationships does nothing but satisfies the Engine interface.
 func (e *Engine) DeleteResourceRelationships(context.Context, types.Resource) error {
 	args := e.Called()
@@ -156,6 +209,13 @@ func (e *Engine) DeleteResourceRelationships(context.Context, types.Resource) er
 	return args.Error(0)
 }
 
+// DeleteResource does nothing but satisfies the Engine interface.
+func (e *Engine) DeleteResource(context.Context, types.Resource) error {
+	args := e.Called()
+
+	return args.Error(0)
+}
+
 // NewResourceFromID creates a new resource object based on the given ID.
 func (e *Engine) NewResourceFromID(id gidx.PrefixedID) (types.Resource, error) {
 	prefix := id.Prefix()
@@ -201,11 +261,32 @@ func (e *Engine) GetResourceType(name string) *types.ResourceType {
 	return nil
 }
 
-// SubjectHasPermission returns nil to satisfy the Engine interface.
+// SubjectHasPermission returns the provided mock results.
 func (e *Engine) SubjectHasPermission(context.Context, types.Resource, string, types.Resource) error {
-	e.Called()
+	args := e.Called()
 
-	return nil
+	return args.Error(0)
+}
+
+// SubjectAllowedActions returns the actions the mock was set up with.
+func (e *Engine) SubjectAllowedActions(context.Context, types.Resource, types.Resource) ([]string, error) {
+	args := e.Called()
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// ExplainPermission returns the grant path the mock was set up with.
+func (e *Engine) ExplainPermission(context.Context, types.Resource, string, types.Resource) ([]types.GrantStep, error) {
+	args := e.Called()
+
+	return args.Get(0).([]types.GrantStep), args.Error(1)
+}
+
+// FilterAllowedResources returns the resources the mock was set up with.
+func (e *Engine) FilterAllowedResources(context.Context, types.Resource, string, []types.Resource) ([]types.Resource, error) {
+	args := e.Called()
+
+	return args.Get(0).([]types.Resource), args.Error(1)
 }
 
 // CreateRoleBinding returns nothing but satisfies the Engine interface.
@@ -213,11 +294,30 @@ func (e *Engine) CreateRoleBinding(context.Context, types.Resource, types.Resour
 	return types.RoleBinding{}, nil
 }
 
+// CreateRoleBindingWithCaveat returns nothing but satisfies the Engine interface.
+func (e *Engine) CreateRoleBindingWithCaveat(
+	context.Context, types.Resource, types.Resource, types.Resource, []types.RoleBindingSubject, types.RoleBindingCaveat,
+) (types.RoleBinding, error) {
+	return types.RoleBinding{}, nil
+}
+
 // ListRoleBindings returns nothing but satisfies the Engine interface.
 func (e *Engine) ListRoleBindings(context.Context, types.Resource, *types.Resource) ([]types.RoleBinding, error) {
 	return nil, nil
 }
 
+// StreamRoleBindings returns nothing but satisfies the Engine interface.
+func (e *Engine) StreamRoleBindings(context.Context, types.Resource, func(types.RoleBinding) error) error {
+	return nil
+}
+
+// ListSubjectRoleBindings returns the role-bindings the mock was set up with.
+func (e *Engine) ListSubjectRoleBindings(context.Context, types.Resource) ([]types.RoleBinding, error) {
+	args := e.Called()
+
+	return args.Get(0).([]types.RoleBinding), args.Error(1)
+}
+
 // GetRoleBinding returns nothing but satisfies the Engine interface.
 func (e *Engine) GetRoleBinding(context.Context, types.Resource) (types.RoleBinding, error) {
 	return types.RoleBinding{}, nil
@@ -238,7 +338,228 @@ func (e *Engine) GetRoleBindingResource(context.Context, types.Resource) (types.
 	return types.Resource{}, nil
 }
 
+// CreateRoleBindings returns the provided mock results.
+func (e *Engine) CreateRoleBindings(context.Context, types.Resource, types.Resource, []types.RoleBindingRequest) []types.RoleBindingResult {
+	args := e.Called()
+
+	return args.Get(0).([]types.RoleBindingResult)
+}
+
+// DeleteRoleBindings returns the provided mock results.
+func (e *Engine) DeleteRoleBindings(context.Context, types.Resource, []types.Resource) []types.RoleBindingResult {
+	args := e.Called()
+
+	return args.Get(0).([]types.RoleBindingResult)
+}
+
+// ListStaleRoleBindings returns nothing but satisfies the Engine interface.
+func (e *Engine) ListStaleRoleBindings(context.Context, types.Resource, time.Time) ([]types.RoleBinding, error) {
+	return nil, nil
+}
+
+// SuggestRoleBindingReductions returns nothing but satisfies the Engine interface.
+func (e *Engine) SuggestRoleBindingReductions(context.Context, types.Resource, time.Time) ([]types.RoleBindingSuggestion, error) {
+	return nil, nil
+}
+
+// CreateInvitation returns the invitation and token the mock was set up with.
+func (e *Engine) CreateInvitation(context.Context, types.Resource, types.Resource, types.Resource, string, time.Duration) (types.Invitation, string, error) {
+	args := e.Called()
+
+	return args.Get(0).(types.Invitation), args.String(1), args.Error(2)
+}
+
+// GetInvitation returns the invitation the mock was set up with.
+func (e *Engine) GetInvitation(context.Context, gidx.PrefixedID) (types.Invitation, error) {
+	args := e.Called()
+
+	return args.Get(0).(types.Invitation), args.Error(1)
+}
+
+// ListInvitations returns nothing but satisfies the Engine interface.
+func (e *Engine) ListInvitations(context.Context, types.Resource) ([]types.Invitation, error) {
+	return nil, nil
+}
+
+// DeleteInvitation returns nothing but satisfies the Engine interface.
+func (e *Engine) DeleteInvitation(context.Context, gidx.PrefixedID) error {
+	return nil
+}
+
+// RedeemInvitation returns the role-binding the mock was set up with.
+func (e *Engine) RedeemInvitation(context.Context, types.Resource, string) (types.RoleBinding, error) {
+	args := e.Called()
+
+	return args.Get(0).(types.RoleBinding), args.Error(1)
+}
+
+// CreateAccessRequest returns the access request the mock was set up with.
+func (e *Engine) CreateAccessRequest(context.Context, types.Resource, types.Resource, types.Resource, string) (types.AccessRequest, error) {
+	args := e.Called()
+
+	return args.Get(0).(types.AccessRequest), args.Error(1)
+}
+
+// GetAccessRequest returns the access request the mock was set up with.
+func (e *Engine) GetAccessRequest(context.Context, gidx.PrefixedID) (types.AccessRequest, error) {
+	args := e.Called()
+
+	return args.Get(0).(types.AccessRequest), args.Error(1)
+}
+
+// ListAccessRequests returns nothing but satisfies the Engine interface.
+func (e *Engine) ListAccessRequests(context.Context, types.Resource, types.AccessRequestStatus) ([]types.AccessRequest, error) {
+	return nil, nil
+}
+
+// DecideAccessRequest returns the access request the mock was set up with.
+func (e *Engine) DecideAccessRequest(context.Context, types.Resource, gidx.PrefixedID, bool, string) (types.AccessRequest, error) {
+	args := e.Called()
+
+	return args.Get(0).(types.AccessRequest), args.Error(1)
+}
+
+// CreateGroup returns the group the mock was set up with.
+func (e *Engine) CreateGroup(context.Context, types.Resource, types.Resource, string, string) (types.Group, error) {
+	args := e.Called()
+
+	return args.Get(0).(types.Group), args.Error(1)
+}
+
+// GetGroup returns nothing but satisfies the Engine interface.
+func (e *Engine) GetGroup(context.Context, gidx.PrefixedID) (types.Group, error) {
+	return types.Group{}, nil
+}
+
+// ListGroups returns the groups the mock was set up with.
+func (e *Engine) ListGroups(context.Context, types.Resource) ([]types.Group, error) {
+	args := e.Called()
+
+	return args.Get(0).([]types.Group), args.Error(1)
+}
+
+// UpdateGroup returns nothing but satisfies the Engine interface.
+func (e *Engine) UpdateGroup(context.Context, types.Resource, gidx.PrefixedID, string, string) (types.Group, error) {
+	return types.Group{}, nil
+}
+
+// DeleteGroup returns nothing but satisfies the Engine interface.
+func (e *Engine) DeleteGroup(context.Context, gidx.PrefixedID) error {
+	return nil
+}
+
+// ListGroupMembers returns nothing but satisfies the Engine interface.
+func (e *Engine) ListGroupMembers(context.Context, gidx.PrefixedID) ([]types.Resource, error) {
+	return nil, nil
+}
+
+// ExpandGroupMembers returns the members the mock was set up with.
+func (e *Engine) ExpandGroupMembers(context.Context, gidx.PrefixedID) ([]types.GroupMember, error) {
+	args := e.Called()
+
+	return args.Get(0).([]types.GroupMember), args.Error(1)
+}
+
+// AddGroupMembers returns nothing but satisfies the Engine interface.
+func (e *Engine) AddGroupMembers(context.Context, gidx.PrefixedID, ...types.Resource) error {
+	return nil
+}
+
+// RemoveGroupMembers returns nothing but satisfies the Engine interface.
+func (e *Engine) RemoveGroupMembers(context.Context, gidx.PrefixedID, ...types.Resource) error {
+	return nil
+}
+
+// ResolveResource returns the resource the mock was set up with for aliases,
+// prefixed IDs are resolved as with NewResourceFromID.
+func (e *Engine) ResolveResource(_ context.Context, id string) (types.Resource, error) {
+	if !query.IsResourceAlias(id) {
+		prefixedID, err := gidx.Parse(id)
+		if err != nil {
+			return types.Resource{}, err
+		}
+
+		return e.NewResourceFromID(prefixedID)
+	}
+
+	args := e.Called()
+
+	return args.Get(0).(types.Resource), args.Error(1)
+}
+
+// CreateResourceAlias returns the alias the mock was set up with.
+func (e *Engine) CreateResourceAlias(context.Context, types.Resource, types.Resource, string) (types.ResourceAlias, error) {
+	args := e.Called()
+
+	return args.Get(0).(types.ResourceAlias), args.Error(1)
+}
+
+// GetResourceAlias returns the alias the mock was set up with.
+func (e *Engine) GetResourceAlias(context.Context, string) (types.ResourceAlias, error) {
+	args := e.Called()
+
+	return args.Get(0).(types.ResourceAlias), args.Error(1)
+}
+
+// ListResourceAliases returns nothing but satisfies the Engine interface.
+func (e *Engine) ListResourceAliases(context.Context, types.Resource) ([]types.ResourceAlias, error) {
+	return nil, nil
+}
+
+// DeleteResourceAlias returns nothing but satisfies the Engine interface.
+func (e *Engine) DeleteResourceAlias(context.Context, string) error {
+	return nil
+}
+
+// GetTenantSettings returns the settings the mock was set up with.
+func (e *Engine) GetTenantSettings(context.Context, types.Resource) (types.TenantSettings, error) {
+	args := e.Called()
+
+	return args.Get(0).(types.TenantSettings), args.Error(1)
+}
+
+// UpdateTenantSettings returns the settings the mock was set up with.
+func (e *Engine) UpdateTenantSettings(context.Context, types.Resource, types.Resource, types.TenantSettings) (types.TenantSettings, error) {
+	args := e.Called()
+
+	return args.Get(0).(types.TenantSettings), args.Error(1)
+}
+
+// ListRoleV1Resources returns nothing but satisfies the Engine interface.
+func (e *Engine) ListRoleV1Resources(context.Context, gidx.PrefixedID, int) ([]types.Resource, error) {
+	return nil, nil
+}
+
+// MigrateRolesV1 returns nothing but satisfies the Engine interface.
+func (e *Engine) MigrateRolesV1(context.Context, types.Resource, types.Resource, bool) ([]types.RoleMigration, error) {
+	return nil, nil
+}
+
+// PurgeTenant returns the provided mock results.
+func (e *Engine) PurgeTenant(_ context.Context, tenant types.Resource, batchSize int, dryRun bool) (types.TenantPurgeReport, error) {
+	args := e.Called(tenant, batchSize, dryRun)
+
+	return args.Get(0).(types.TenantPurgeReport), args.Error(1)
+}
+
+// SyncRoleSubjectTypes returns nothing but satisfies the Engine interface.
+func (e *Engine) SyncRoleSubjectTypes(context.Context) (int, error) {
+	return 0, nil
+}
+
 // AllActions returns nothing but satisfies the Engine interface.
 func (e *Engine) AllActions() []string {
 	return nil
 }
+
+// AllActionGroups returns nothing but satisfies the Engine interface.
+func (e *Engine) AllActionGroups() []types.ActionGroup {
+	return nil
+}
+
+// PolicyInfo returns the policy info the mock was set up with.
+func (e *Engine) PolicyInfo(context.Context) (types.PolicyInfo, error) {
+	args := e.Called()
+
+	return args.Get(0).(types.PolicyInfo), args.Error(1)
+}
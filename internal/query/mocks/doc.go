@@ -0,0 +1,5 @@
+// Package mocks contains mocks of the query engine capabilities, generated by
+// mockery from .mockery.yaml. Tests needing only part of the engine, such as
+// the gRPC API tests, stub the Checker, RoleManager, BindingManager or
+// RelationshipWriter interfaces instead of the complete query.Engine.
+package mocks
@@ -0,0 +1,13 @@
+package mocks_test
+
+import (
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/query/mocks"
+)
+
+var (
+	_ query.Checker            = (*mocks.Checker)(nil)
+	_ query.RoleManager        = (*mocks.RoleManager)(nil)
+	_ query.BindingManager     = (*mocks.BindingManager)(nil)
+	_ query.RelationshipWriter = (*mocks.RelationshipWriter)(nil)
+)
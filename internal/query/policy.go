@@ -0,0 +1,66 @@
+package query
+
+import (
+	"context"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.opentelemetry.io/otel/codes"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// PolicyInfo returns the hashes of the loaded policy and the schema generated
+// from it, and compares the generated schema with the schema applied to SpiceDB.
+func (e *engine) PolicyInfo(ctx context.Context) (types.PolicyInfo, error) {
+	ctx, span := e.tracer.Start(ctx, "engine.PolicyInfo")
+	defer span.End()
+
+	fail := func(err error) (types.PolicyInfo, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.PolicyInfo{}, err
+	}
+
+	info := types.PolicyInfo{
+		PolicyHash: e.policyHash,
+		LoadedAt:   e.policyLoadedAt,
+	}
+
+	schema, err := spicedbx.GenerateSchema(e.namespace, e.schema, e.caveats...)
+	if err != nil {
+		return fail(err)
+	}
+
+	if info.SchemaHash, err = spicedbx.SchemaHash(schema); err != nil {
+		return fail(err)
+	}
+
+	resp, err := e.client.ReadSchema(ctx, &pb.ReadSchemaRequest{})
+
+	switch {
+	case status.Code(err) == grpccodes.NotFound:
+		// no schema was applied to SpiceDB yet.
+		return info, nil
+	case err != nil:
+		return fail(err)
+	}
+
+	// SpiceDB may serve several namespaces, only the definitions of this
+	// engine's namespace are compared.
+	liveSchema, err := spicedbx.NamespaceSchema(resp.SchemaText, e.namespace)
+	if err != nil {
+		return fail(err)
+	}
+
+	if info.LiveSchemaHash, err = spicedbx.SchemaHash(liveSchema); err != nil {
+		return fail(err)
+	}
+
+	info.SchemaMatches = info.LiveSchemaHash == info.SchemaHash
+
+	return info, nil
+}
@@ -0,0 +1,38 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+)
+
+func TestPolicyInfo(t *testing.T) {
+	ctx := context.Background()
+
+	policy := testPolicy()
+
+	e := testEngine(ctx, t, "infratestpolicyinfo", policy)
+
+	info, err := e.PolicyInfo(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, policy.Hash(), info.PolicyHash)
+	assert.NotEmpty(t, info.SchemaHash)
+	assert.Equal(t, info.SchemaHash, info.LiveSchemaHash)
+	assert.True(t, info.SchemaMatches)
+	assert.False(t, info.LoadedAt.IsZero())
+
+	// the default policy lacks the child type of the applied schema
+	WithPolicy(iapl.DefaultPolicy())(e)
+
+	info, err = e.PolicyInfo(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, iapl.DefaultPolicy().Hash(), info.PolicyHash)
+	assert.NotEqual(t, info.SchemaHash, info.LiveSchemaHash)
+	assert.False(t, info.SchemaMatches)
+}
@@ -0,0 +1,287 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// PurgeTenant deletes the authorization footprint of a tenant: the footprint
+// of every resource descending from the tenant, such as its child tenants and
+// their load balancers, and of the tenant itself, as deleted by DeleteResource.
+// A resource descends from another when it has a direct relationship to it,
+// roles, role-bindings and groups are deleted with their owners.
+//
+// The descendants are found by walking the tenant tree once, then deleted in
+// passes of at most batchSize resources, deepest first, so the remaining
+// descendants stay reachable from the tenant and an interrupted purge is
+// resumed by purging the tenant again. Once deleted, the
+// footprint left of the tenant is verified and reported. On dry runs nothing
+// is deleted and the current footprint is reported.
+func (e *engine) PurgeTenant(ctx context.Context, tenant types.Resource, batchSize int, dryRun bool) (types.TenantPurgeReport, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.PurgeTenant",
+		trace.WithAttributes(
+			attribute.Stringer("tenant_id", tenant.ID),
+			attribute.Int("batch_size", batchSize),
+			attribute.Bool("dry_run", dryRun),
+		),
+	)
+	defer span.End()
+
+	report := types.TenantPurgeReport{Tenant: tenant}
+
+	fail := func(err error) (types.TenantPurgeReport, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return report, err
+	}
+
+	if batchSize <= 0 {
+		return fail(fmt.Errorf("%w: batch size must be positive", ErrInvalidArgument))
+	}
+
+	// the tree is only walked once, deleting resources deepest first keeps
+	// the resources not yet deleted reachable from the tenant, so a purge
+	// which was interrupted walks the remaining tree when it is resumed.
+	descendants, err := e.descendantResources(ctx, tenant)
+	if err != nil {
+		return fail(err)
+	}
+
+	if dryRun {
+		report.Resources = descendants
+
+		if report.Remaining, err = e.resourceFootprint(ctx, tenant); err != nil {
+			return fail(err)
+		}
+
+		return report, nil
+	}
+
+	for batchStart := 0; batchStart < len(descendants); batchStart += batchSize {
+		batch := descendants[batchStart:min(batchStart+batchSize, len(descendants))]
+
+		for _, resource := range batch {
+			if err := e.DeleteResource(ctx, resource); err != nil {
+				return fail(fmt.Errorf("purging %s: %w", resource.ID, err))
+			}
+
+			report.Resources = append(report.Resources, resource)
+		}
+
+		report.Passes++
+
+		e.contextLogger(ctx).Infow("purged tenant resources", "tenant_id", tenant.ID, "pass", report.Passes, "resources", len(batch))
+	}
+
+	if err := e.DeleteResource(ctx, tenant); err != nil {
+		return fail(fmt.Errorf("purging %s: %w", tenant.ID, err))
+	}
+
+	// resources which could not be deleted, or were created while purging,
+	// are reported by the verification.
+	descendants, err = e.descendantResources(ctx, tenant)
+	if err != nil {
+		return fail(err)
+	}
+
+	for _, resource := range descendants {
+		report.Remaining = append(report.Remaining, "resource "+resource.ID.String())
+	}
+
+	remaining, err := e.resourceFootprint(ctx, tenant)
+	if err != nil {
+		return fail(err)
+	}
+
+	report.Remaining = append(report.Remaining, remaining...)
+
+	return report, nil
+}
+
+// descendantResources returns the resources descending from the resource,
+// deepest first.
+func (e *engine) descendantResources(ctx context.Context, resource types.Resource) ([]types.Resource, error) {
+	seen := map[gidx.PrefixedID]struct{}{resource.ID: {}}
+
+	var levels [][]types.Resource
+
+	for level := []types.Resource{resource}; len(level) != 0; {
+		var next []types.Resource
+
+		for _, parent := range level {
+			children, err := e.childResources(ctx, parent)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, child := range children {
+				if _, ok := seen[child.ID]; ok {
+					continue
+				}
+
+				seen[child.ID] = struct{}{}
+				next = append(next, child)
+			}
+		}
+
+		slices.SortFunc(next, func(a, b types.Resource) int {
+			return strings.Compare(a.ID.String(), b.ID.String())
+		})
+
+		if len(next) != 0 {
+			levels = append(levels, next)
+		}
+
+		level = next
+	}
+
+	var out []types.Resource
+
+	for i := len(levels) - 1; i >= 0; i-- {
+		out = append(out, levels[i]...)
+	}
+
+	return out, nil
+}
+
+// childResources returns the resources with a direct relationship to the
+// resource. Relationships to a relation of the resource, such as the members
+// of a parent tenant, and roles, role-bindings and groups are not children.
+func (e *engine) childResources(ctx context.Context, resource types.Resource) ([]types.Resource, error) {
+	var children []types.Resource
+
+	for relation, resTypes := range e.schemaSubjectRelationMap[resource.Type] {
+		for _, resType := range resTypes {
+			if e.isRBACResourceType(resType) {
+				continue
+			}
+
+			err := e.streamRelationships(ctx, &pb.RelationshipFilter{
+				ResourceType:     e.namespaced(resType),
+				OptionalRelation: relation,
+				OptionalSubjectFilter: &pb.SubjectFilter{
+					SubjectType:       e.namespaced(resource.Type),
+					OptionalSubjectId: resource.ID.String(),
+				},
+			}, func(rel *pb.Relationship) error {
+				if rel.Subject.OptionalRelation != "" {
+					return nil
+				}
+
+				child, err := e.NewResourceFromIDString(rel.Resource.ObjectId)
+				if err != nil {
+					return err
+				}
+
+				children = append(children, child)
+
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return children, nil
+}
+
+// isRBACResourceType reports whether the resource type is managed by the
+// engine, roles, role-bindings and groups.
+func (e *engine) isRBACResourceType(resType string) bool {
+	switch resType {
+	case e.rbac.RoleResource.Name, e.rbac.RoleBindingResource.Name, DefaultRoleResourceName:
+		return true
+	}
+
+	return e.rbac.GroupResource != nil && resType == e.rbac.GroupResource.Name
+}
+
+// resourceFootprint describes the role-bindings, roles, groups and
+// relationships of the resource.
+func (e *engine) resourceFootprint(ctx context.Context, resource types.Resource) ([]string, error) {
+	var footprint []string
+
+	if resourceHasRoleBindingV2(e.schemaTypeMap[resource.Type]) != nil {
+		rbs, err := e.ListRoleBindings(ctx, resource, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rb := range rbs {
+			footprint = append(footprint, "role-binding "+rb.ID.String())
+		}
+	}
+
+	roles, err := e.store.ListResourceRoles(ctx, resource.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range roles {
+		footprint = append(footprint, "role "+role.ID.String())
+	}
+
+	groups, err := e.store.ListOwnerGroups(ctx, resource.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		footprint = append(footprint, "group "+group.ID.String())
+	}
+
+	filters := []*pb.RelationshipFilter{{
+		ResourceType:       e.namespaced(resource.Type),
+		OptionalResourceId: resource.ID.String(),
+	}}
+
+	for _, resTypes := range e.schemaSubjectRelationMap[resource.Type] {
+		for _, resType := range resTypes {
+			filters = append(filters, &pb.RelationshipFilter{
+				ResourceType: e.namespaced(resType),
+				OptionalSubjectFilter: &pb.SubjectFilter{
+					SubjectType:       e.namespaced(resource.Type),
+					OptionalSubjectId: resource.ID.String(),
+				},
+			})
+		}
+	}
+
+	seen := make(map[string]struct{})
+
+	for _, filter := range filters {
+		err := e.streamRelationships(ctx, filter, func(rel *pb.Relationship) error {
+			rec := e.relationshipRecord(rel)
+
+			desc := fmt.Sprintf("relationship %s:%s#%s@%s:%s", rec.ResourceType, rec.ResourceID, rec.Relation, rec.SubjectType, rec.SubjectID)
+			if rec.SubjectRelation != "" {
+				desc += "#" + rec.SubjectRelation
+			}
+
+			if _, ok := seen[desc]; !ok {
+				seen[desc] = struct{}{}
+				footprint = append(footprint, desc)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return footprint, nil
+}
@@ -0,0 +1,120 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestPurgeTenant(t *testing.T) {
+	namespace := "testpurge"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	child, err := e.NewResourceFromIDString("tnntten-child")
+	require.NoError(t, err)
+	grandchild, err := e.NewResourceFromIDString("tnntten-grandchild")
+	require.NoError(t, err)
+	childLB, err := e.NewResourceFromIDString("loadbal-child")
+	require.NoError(t, err)
+	grandchildLB, err := e.NewResourceFromIDString("loadbal-grandchild")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
+		Updates: append(rbacV2CreateParentRel(root, child, e.namespace), rbacV2CreateParentRel(child, grandchild, e.namespace)...),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, e.CreateRelationships(ctx, []types.Relationship{
+		{Resource: childLB, Relation: "owner", Subject: child},
+		{Resource: grandchildLB, Relation: "owner", Subject: grandchild},
+	}))
+
+	rootRole, err := e.CreateRoleV2(ctx, actor, root, "lb_viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+	rootRoleRes, err := e.NewResourceFromID(rootRole.ID)
+	require.NoError(t, err)
+
+	rootRB, err := e.CreateRoleBinding(ctx, actor, root, rootRoleRes, []types.RoleBindingSubject{{SubjectResource: actor}})
+	require.NoError(t, err)
+
+	childRole, err := e.CreateRoleV2(ctx, actor, child, "lb_editor", []string{"loadbalancer_update"})
+	require.NoError(t, err)
+	childRoleRes, err := e.NewResourceFromID(childRole.ID)
+	require.NoError(t, err)
+
+	childRB, err := e.CreateRoleBinding(ctx, actor, child, childRoleRes, []types.RoleBindingSubject{{SubjectResource: actor}})
+	require.NoError(t, err)
+
+	grandchildRB, err := e.CreateRoleBinding(ctx, actor, grandchild, rootRoleRes, []types.RoleBindingSubject{{SubjectResource: actor}})
+	require.NoError(t, err)
+
+	group, err := e.CreateGroup(ctx, actor, grandchild, "editors", "")
+	require.NoError(t, err)
+
+	// descendants are ordered deepest first
+	expectResources := []types.Resource{grandchildLB, childLB, grandchild}
+
+	_, err = e.PurgeTenant(ctx, child, 0, false)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	// dry runs report the footprint without deleting it
+	report, err := e.PurgeTenant(ctx, child, 2, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, expectResources, report.Resources)
+	assert.Zero(t, report.Passes)
+	assert.Contains(t, report.Remaining, "role "+childRole.ID.String())
+	assert.Contains(t, report.Remaining, "role-binding "+childRB.ID.String())
+
+	_, err = e.GetGroup(ctx, group.ID)
+	require.NoError(t, err)
+
+	report, err = e.PurgeTenant(ctx, child, 2, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, expectResources, report.Resources)
+	assert.Equal(t, 2, report.Passes)
+	assert.Empty(t, report.Remaining)
+
+	// the footprint of the tenant and its descendants is deleted
+	_, err = e.GetRoleV2(ctx, childRoleRes)
+	assert.Error(t, err)
+
+	for _, rbID := range []types.RoleBinding{childRB, grandchildRB} {
+		_, err = e.GetRoleBinding(ctx, types.Resource{Type: e.rbac.RoleBindingResource.Name, ID: rbID.ID})
+		assert.ErrorIs(t, err, ErrRoleBindingNotFound)
+	}
+
+	_, err = e.GetGroup(ctx, group.ID)
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+
+	for _, resource := range []types.Resource{child, grandchild, childLB, grandchildLB} {
+		from, err := e.ListRelationshipsFrom(ctx, resource)
+		require.NoError(t, err)
+		assert.Empty(t, from, resource.ID)
+	}
+
+	// the parent tenant is kept
+	_, err = e.GetRoleV2(ctx, rootRoleRes)
+	assert.NoError(t, err)
+
+	_, err = e.GetRoleBinding(ctx, types.Resource{Type: e.rbac.RoleBindingResource.Name, ID: rootRB.ID})
+	assert.NoError(t, err)
+
+	// purging again finds nothing
+	report, err = e.PurgeTenant(ctx, child, 2, false)
+	require.NoError(t, err)
+
+	assert.Empty(t, report.Resources)
+	assert.Empty(t, report.Remaining)
+}
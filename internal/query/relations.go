@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
+	"time"
 
 	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"go.infratographer.com/x/gidx"
@@ -15,6 +17,7 @@ import (
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"go.infratographer.com/permissions-api/internal/storage"
 	"go.infratographer.com/permissions-api/internal/types"
@@ -22,6 +25,10 @@ import (
 
 var roleSubjectRelation = "subject"
 
+// allowedActionsConcurrency is the number of permission checks run at once
+// when computing the actions of a subject on a resource.
+const allowedActionsConcurrency = 10
+
 func (e *engine) getTypeForResource(res types.Resource) (types.ResourceType, error) {
 	for _, resType := range e.schema {
 		if res.Type == resType.Name {
@@ -87,7 +94,7 @@ func (e *engine) validateResourceActions(resource types.Resource, actions ...str
 		return nil
 	}
 
-	return fmt.Errorf("%w: %s for %s", ErrInvalidAction, strings.Join(invalidActions, ","), resource.Type)
+	return &InvalidActionsError{ResourceType: resource.Type, Actions: invalidActions}
 }
 
 // SubjectHasPermission checks if the given subject can do the given action on the given resource
@@ -113,6 +120,8 @@ func (e *engine) SubjectHasPermission(ctx context.Context, subject types.Resourc
 
 	defer span.End()
 
+	start := time.Now()
+
 	consistency, consName := e.determineConsistency(ctx, resource)
 	span.SetAttributes(
 		attribute.String(
@@ -123,6 +132,20 @@ func (e *engine) SubjectHasPermission(ctx context.Context, subject types.Resourc
 
 	err := e.validateResourceActions(resource, action)
 
+	actionLabel := action
+	if err != nil {
+		actionLabel = invalidActionLabel
+	}
+
+	var (
+		caveatContext *structpb.Struct
+		checkedAt     *pb.ZedToken
+	)
+
+	if err == nil {
+		caveatContext, err = e.checkCaveatContext(ctx)
+	}
+
 	// Only check permissions if the requested action exists in the policy.
 	if err == nil {
 		req := &pb.CheckPermissionRequest{
@@ -132,33 +155,149 @@ func (e *engine) SubjectHasPermission(ctx context.Context, subject types.Resourc
 			Subject: &pb.SubjectReference{
 				Object: resourceToSpiceDBRef(e.namespace, subject),
 			},
+			Context: caveatContext,
 		}
 
-		err = e.checkPermission(ctx, req)
+		checkedAt, err = e.cachedCheckPermission(ctx, req, consName, subject, action, resource)
+		err = e.degradedDecision(ctx, subject, action, resource, err)
 	}
 
+	outcome := outcomeError
+
 	switch {
 	case err == nil:
+		outcome = outcomeAllowed
+
 		span.SetAttributes(
 			attribute.String(
 				"permissions.outcome",
 				outcomeAllowed,
 			),
 		)
+
+		e.recordUsage(subject, action, resource)
+		observeCheck(actionLabel, outcomeAllowed, start)
 	case errors.Is(err, ErrActionNotAssigned), errors.Is(err, ErrInvalidAction):
+		outcome = outcomeDenied
+
 		span.SetAttributes(
 			attribute.String(
 				"permissions.outcome",
 				outcomeDenied,
 			),
 		)
+
+		observeCheck(actionLabel, outcomeDenied, start)
 	default:
 		span.SetStatus(codes.Error, err.Error())
+
+		observeCheck(actionLabel, outcomeError, start)
 	}
 
+	e.logDecision(ctx, types.Decision{
+		SubjectID:  subject.ID,
+		ResourceID: resource.ID,
+		Action:     action,
+		Outcome:    outcome,
+		Latency:    time.Since(start),
+		ZedToken:   checkedAt.GetToken(),
+		DecidedAt:  start,
+	})
+
 	return err
 }
 
+// SubjectAllowedActions returns all actions the given subject can do on the given resource
+func (e *engine) SubjectAllowedActions(ctx context.Context, subject, resource types.Resource) ([]string, error) {
+	ctx, span := e.tracer.Start(
+		ctx,
+		"SubjectAllowedActions",
+		trace.WithAttributes(
+			attribute.Stringer(
+				"permissions.actor",
+				subject.ID,
+			),
+			attribute.Stringer(
+				"permissions.resource",
+				resource.ID,
+			),
+		),
+	)
+
+	defer span.End()
+
+	resType, err := e.getTypeForResource(resource)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	consistency, consName := e.determineConsistency(ctx, resource)
+	span.SetAttributes(
+		attribute.String(
+			"permissions.consistency",
+			consName,
+		),
+	)
+
+	var (
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, allowedActionsConcurrency)
+		allowed = make([]bool, len(resType.Actions))
+		errs    = make([]error, len(resType.Actions))
+	)
+
+	for i, action := range resType.Actions {
+		req := &pb.CheckPermissionRequest{
+			Consistency: consistency,
+			Resource:    resourceToSpiceDBRef(e.namespace, resource),
+			Permission:  action.Name,
+			Subject: &pb.SubjectReference{
+				Object: resourceToSpiceDBRef(e.namespace, subject),
+			},
+		}
+
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			err := e.checkPermission(ctx, req)
+
+			switch {
+			case err == nil:
+				allowed[i] = true
+			case !errors.Is(err, ErrActionNotAssigned):
+				errs[i] = err
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if err := multierr.Combine(errs...); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	actions := make([]string, 0, len(resType.Actions))
+
+	for i, action := range resType.Actions {
+		if allowed[i] {
+			actions = append(actions, action.Name)
+		}
+	}
+
+	return actions, nil
+}
+
 // AssignSubjectRole assigns the given role to the given subject.
 func (e *engine) AssignSubjectRole(ctx context.Context, subject types.Resource, role types.Role) error {
 	request := &pb.WriteRelationshipsRequest{
@@ -171,6 +310,13 @@ func (e *engine) AssignSubjectRole(ctx context.Context, subject types.Resource,
 		return err
 	}
 
+	e.publishAuditEvent(ctx, auditEvent{
+		eventType:  AuditEventRoleAssigned,
+		subjectID:  role.ID,
+		resourceID: role.ResourceID,
+		relatedIDs: []gidx.PrefixedID{subject.ID},
+	})
+
 	return nil
 }
 
@@ -184,6 +330,13 @@ func (e *engine) UnassignSubjectRole(ctx context.Context, subject types.Resource
 		return err
 	}
 
+	e.publishAuditEvent(ctx, auditEvent{
+		eventType:  AuditEventRoleUnassigned,
+		subjectID:  role.ID,
+		resourceID: role.ResourceID,
+		relatedIDs: []gidx.PrefixedID{subject.ID},
+	})
+
 	return nil
 }
 
@@ -256,19 +409,31 @@ func (e *engine) subjectRoleRelDelete(subject types.Resource, role types.Role) *
 }
 
 func (e *engine) checkPermission(ctx context.Context, req *pb.CheckPermissionRequest) error {
+	_, err := e.checkPermissionAt(ctx, req)
+
+	return err
+}
+
+// checkPermissionAt checks a permission like checkPermission, returning the
+// revision the permission was checked at.
+func (e *engine) checkPermissionAt(ctx context.Context, req *pb.CheckPermissionRequest) (*pb.ZedToken, error) {
 	resp, err := e.client.CheckPermission(ctx, req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	// A conditional permission means the caveat context of the check was
+	// missing values required by a caveat, which is treated as denied.
 	if resp.Permissionship == pb.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION {
-		return nil
+		return resp.CheckedAt, nil
 	}
 
-	return ErrActionNotAssigned
+	return resp.CheckedAt, ErrActionNotAssigned
 }
 
-// CreateRelationships atomically creates the given relationships in SpiceDB.
+// CreateRelationships atomically creates the given relationships in SpiceDB,
+// provisioning the role templates of the policy for the role owners among the
+// resources of the relationships.
 func (e *engine) CreateRelationships(ctx context.Context, rels []types.Relationship) error {
 	ctx, span := e.tracer.Start(ctx, "engine.CreateRelationships", trace.WithAttributes(attribute.Int("relationships", len(rels))))
 
@@ -300,6 +465,15 @@ func (e *engine) CreateRelationships(ctx context.Context, rels []types.Relations
 
 	e.updateRelationshipZedTokens(ctx, rels, resp.WrittenAt.Token)
 
+	e.publishRelationshipAuditEvents(ctx, AuditEventRelationshipCreated, rels)
+
+	if err := e.provisionRoleTemplates(ctx, rels); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
 	return nil
 }
 
@@ -313,6 +487,10 @@ func (e *engine) CreateRole(ctx context.Context, actor, res types.Resource, role
 		return types.Role{}, err
 	}
 
+	if err := e.checkRoleNameAvailable(ctx, res.ID, "", roleName); err != nil {
+		return types.Role{}, err
+	}
+
 	role := newRole(roleName, actions)
 	roleRels := e.roleRelationships(role, res)
 
@@ -332,7 +510,7 @@ func (e *engine) CreateRole(ctx context.Context, actor, res types.Resource, role
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.Role{}, err
 	}
@@ -341,7 +519,7 @@ func (e *engine) CreateRole(ctx context.Context, actor, res types.Resource, role
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		// No rollback of spicedb relations are done here.
 		// This does result in dangling unused entries in spicedb,
@@ -357,6 +535,14 @@ func (e *engine) CreateRole(ctx context.Context, actor, res types.Resource, role
 	role.CreatedAt = dbRole.CreatedAt
 	role.UpdatedAt = dbRole.UpdatedAt
 
+	e.publishAuditEvent(ctx, auditEvent{
+		eventType:  AuditEventRoleCreated,
+		actor:      actor,
+		subjectID:  role.ID,
+		resourceID: role.ResourceID,
+		after:      auditRole(role),
+	})
+
 	return role, nil
 }
 
@@ -415,28 +601,28 @@ func (e *engine) UpdateRole(ctx context.Context, actor, roleResource types.Resou
 		span.RecordError(sErr)
 		span.SetStatus(codes.Error, sErr.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.Role{}, err
 	}
 
 	role, err := e.GetRole(dbCtx, roleResource)
 	if err != nil {
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.Role{}, err
 	}
 
 	res, err := e.NewResourceFromID(role.ResourceID)
 	if err != nil {
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.Role{}, err
 	}
 
 	// Validate actions against role resource
 	if err := e.validateResourceActions(res, newActions...); err != nil {
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.Role{}, err
 	}
@@ -445,6 +631,14 @@ func (e *engine) UpdateRole(ctx context.Context, actor, roleResource types.Resou
 		newName = role.Name
 	}
 
+	if err := e.checkRoleNameAvailable(dbCtx, role.ResourceID, role.ID, newName); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return types.Role{}, err
+	}
+
+	before := auditRole(role)
+
 	addActions, remActions := diff(role.Actions, newActions)
 
 	// If no changes, return existing role with no changes.
@@ -459,7 +653,7 @@ func (e *engine) UpdateRole(ctx context.Context, actor, roleResource types.Resou
 
 	dbRole, err := e.store.UpdateRole(dbCtx, actor.ID, role.ID, newName)
 	if err != nil {
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.Role{}, err
 	}
@@ -474,7 +668,7 @@ func (e *engine) UpdateRole(ctx context.Context, actor, roleResource types.Resou
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
 
-			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+			logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 			return types.Role{}, err
 		}
@@ -486,7 +680,7 @@ func (e *engine) UpdateRole(ctx context.Context, actor, roleResource types.Resou
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		// At this point, spicedb changes have already been applied.
 		// Attempting to rollback could result in failures that could result in the same situation.
@@ -503,6 +697,15 @@ func (e *engine) UpdateRole(ctx context.Context, actor, roleResource types.Resou
 	role.CreatedAt = dbRole.CreatedAt
 	role.UpdatedAt = dbRole.UpdatedAt
 
+	e.publishAuditEvent(ctx, auditEvent{
+		eventType:  AuditEventRoleUpdated,
+		actor:      actor,
+		subjectID:  role.ID,
+		resourceID: role.ResourceID,
+		before:     before,
+		after:      auditRole(role),
+	})
+
 	return role, nil
 }
 
@@ -614,6 +817,24 @@ func (e *engine) relationshipsToUpdates(rels []types.Relationship, operation pb.
 }
 
 func (e *engine) readRelationships(ctx context.Context, filter *pb.RelationshipFilter) ([]*pb.Relationship, error) {
+	var responses []*pb.Relationship
+
+	err := e.streamRelationships(ctx, filter, func(rel *pb.Relationship) error {
+		responses = append(responses, rel)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return responses, nil
+}
+
+// streamRelationships reads the relationships matching the filter, calling fn
+// for every relationship as it is received, so the relationships do not have
+// to be held in memory. Reading stops at the first error returned by fn.
+func (e *engine) streamRelationships(ctx context.Context, filter *pb.RelationshipFilter, fn func(*pb.Relationship) error) error {
 	req := pb.ReadRelationshipsRequest{
 		Consistency: &pb.Consistency{
 			Requirement: &pb.Consistency_FullyConsistent{
@@ -624,29 +845,27 @@ func (e *engine) readRelationships(ctx context.Context, filter *pb.RelationshipF
 
 	req.RelationshipFilter = filter
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	r, err := e.client.ReadRelationships(ctx, &req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var (
-		responses []*pb.Relationship
-		done      bool
-	)
-
-	for !done {
+	for {
 		rel, err := r.Recv()
 		switch err {
 		case nil:
-			responses = append(responses, rel.Relationship)
+			if err := fn(rel.Relationship); err != nil {
+				return err
+			}
 		case io.EOF:
-			done = true
+			return nil
 		default:
-			return nil, err
+			return err
 		}
 	}
-
-	return responses, nil
 }
 
 // DeleteRelationships removes the specified relationships.
@@ -693,6 +912,8 @@ func (e *engine) DeleteRelationships(ctx context.Context, relationships ...types
 
 	e.updateRelationshipZedTokens(ctx, relationships, resp.WrittenAt.Token)
 
+	e.publishRelationshipAuditEvents(ctx, AuditEventRelationshipDeleted, relationships)
+
 	return nil
 }
 
@@ -755,49 +976,74 @@ func relationshipsToRoles(rels []*pb.Relationship) []types.Role {
 	return out
 }
 
-func (e *engine) relationshipsToNonRoles(rels []*pb.Relationship) ([]types.Relationship, error) {
-	var out []types.Relationship
+// relationshipToNonRole converts a SpiceDB relationship, relationships for
+// roles are skipped and reported as not ok.
+func (e *engine) relationshipToNonRole(rel *pb.Relationship) (types.Relationship, bool, error) {
+	// skip relationships for v1 roles, and wildcard relationships for v2 roles
+	if rel.Subject.Object.ObjectType == e.namespace+"/role" || rel.Subject.Object.ObjectId == "*" {
+		return types.Relationship{}, false, nil
+	}
 
-	for _, rel := range rels {
-		// skip relationships for v1 roles, and wildcard relationships for v2 roles
-		if rel.Subject.Object.ObjectType == e.namespace+"/role" || rel.Subject.Object.ObjectId == "*" {
-			continue
-		}
+	resID, err := gidx.Parse(rel.Resource.ObjectId)
+	if err != nil {
+		return types.Relationship{}, false, err
+	}
 
-		resID, err := gidx.Parse(rel.Resource.ObjectId)
-		if err != nil {
-			return nil, err
-		}
+	res, err := e.NewResourceFromID(resID)
+	if err != nil {
+		return types.Relationship{}, false, err
+	}
 
-		res, err := e.NewResourceFromID(resID)
-		if err != nil {
-			return nil, err
-		}
+	subjID, err := gidx.Parse(rel.Subject.Object.ObjectId)
+	if err != nil {
+		return types.Relationship{}, false, err
+	}
 
-		subjID, err := gidx.Parse(rel.Subject.Object.ObjectId)
-		if err != nil {
-			return nil, err
-		}
+	subj, err := e.NewResourceFromID(subjID)
+	if err != nil {
+		return types.Relationship{}, false, err
+	}
 
-		subj, err := e.NewResourceFromID(subjID)
-		if err != nil {
-			return nil, err
-		}
+	item := types.Relationship{
+		Resource: res,
+		Relation: rel.Relation,
+		Subject:  subj,
+	}
 
-		item := types.Relationship{
-			Resource: res,
-			Relation: rel.Relation,
-			Subject:  subj,
+	return item, true, nil
+}
+
+// nonRoleRelationshipFn wraps fn to be called with the non-role relationships
+// of a SpiceDB relationship stream.
+func (e *engine) nonRoleRelationshipFn(fn func(types.Relationship) error) func(*pb.Relationship) error {
+	return func(rel *pb.Relationship) error {
+		item, ok, err := e.relationshipToNonRole(rel)
+		if err != nil || !ok {
+			return err
 		}
 
-		out = append(out, item)
+		return fn(item)
 	}
-
-	return out, nil
 }
 
 // ListRelationshipsFrom returns all non-role relationships bound to a given resource.
 func (e *engine) ListRelationshipsFrom(ctx context.Context, resource types.Resource) ([]types.Relationship, error) {
+	var out []types.Relationship
+
+	err := e.StreamRelationshipsFrom(ctx, resource, func(rel types.Relationship) error {
+		out = append(out, rel)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// StreamRelationshipsFrom calls fn for all non-role relationships bound to a given resource.
+func (e *engine) StreamRelationshipsFrom(ctx context.Context, resource types.Resource, fn func(types.Relationship) error) error {
 	resType := e.namespace + "/" + resource.Type
 
 	filter := &pb.RelationshipFilter{
@@ -805,41 +1051,48 @@ func (e *engine) ListRelationshipsFrom(ctx context.Context, resource types.Resou
 		OptionalResourceId: resource.ID.String(),
 	}
 
-	relationships, err := e.readRelationships(ctx, filter)
+	return e.streamRelationships(ctx, filter, e.nonRoleRelationshipFn(fn))
+}
+
+// ListRelationshipsTo returns all non-role relationships destined for a given resource.
+func (e *engine) ListRelationshipsTo(ctx context.Context, resource types.Resource) ([]types.Relationship, error) {
+	var out []types.Relationship
+
+	err := e.StreamRelationshipsTo(ctx, resource, func(rel types.Relationship) error {
+		out = append(out, rel)
+
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return e.relationshipsToNonRoles(relationships)
+	return out, nil
 }
 
-// ListRelationshipsTo returns all non-role relationships destined for a given resource.
-func (e *engine) ListRelationshipsTo(ctx context.Context, resource types.Resource) ([]types.Relationship, error) {
+// StreamRelationshipsTo calls fn for all non-role relationships destined for a given resource.
+func (e *engine) StreamRelationshipsTo(ctx context.Context, resource types.Resource, fn func(types.Relationship) error) error {
 	relTypes, ok := e.schemaSubjectRelationMap[resource.Type]
 	if !ok {
-		return nil, ErrInvalidType
+		return ErrInvalidType
 	}
 
-	var relationships []*pb.Relationship
-
 	for _, types := range relTypes {
 		for _, relType := range types {
-			rels, err := e.readRelationships(ctx, &pb.RelationshipFilter{
+			err := e.streamRelationships(ctx, &pb.RelationshipFilter{
 				ResourceType: e.namespace + "/" + relType,
 				OptionalSubjectFilter: &pb.SubjectFilter{
 					SubjectType:       e.namespace + "/" + resource.Type,
 					OptionalSubjectId: resource.ID.String(),
 				},
-			})
+			}, e.nonRoleRelationshipFn(fn))
 			if err != nil {
-				return nil, err
+				return err
 			}
-
-			relationships = append(relationships, rels...)
 		}
 	}
 
-	return e.relationshipsToNonRoles(relationships)
+	return nil
 }
 
 // ListRoles returns all roles bound to a given resource.
@@ -905,6 +1158,7 @@ func (e *engine) ListRoles(ctx context.Context, resource types.Resource) ([]type
 			UpdatedBy:  dbRole.UpdatedBy,
 			CreatedAt:  dbRole.CreatedAt,
 			UpdatedAt:  dbRole.UpdatedAt,
+			LastUsedAt: dbRole.LastUsedAt,
 		}
 	}
 
@@ -989,7 +1243,7 @@ func (e *engine) GetRole(ctx context.Context, roleResource types.Resource) (type
 
 		dbRole, err := e.store.GetRoleByID(ctx, roleResource.ID)
 		if err != nil && !errors.Is(err, storage.ErrNoRoleFound) {
-			e.logger.Error("error while getting role", zap.Error(err))
+			e.contextLogger(ctx).Error("error while getting role", zap.Error(err))
 		}
 
 		return types.Role{
@@ -1002,6 +1256,7 @@ func (e *engine) GetRole(ctx context.Context, roleResource types.Resource) (type
 			UpdatedBy:  dbRole.UpdatedBy,
 			CreatedAt:  dbRole.CreatedAt,
 			UpdatedAt:  dbRole.UpdatedAt,
+			LastUsedAt: dbRole.LastUsedAt,
 		}, nil
 	}
 
@@ -1057,7 +1312,7 @@ func (e *engine) DeleteRole(ctx context.Context, roleResource types.Resource) er
 		span.RecordError(sErr)
 		span.SetStatus(codes.Error, sErr.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return err
 	}
@@ -1067,7 +1322,7 @@ func (e *engine) DeleteRole(ctx context.Context, roleResource types.Resource) er
 	for _, resType := range e.schemaRoleables {
 		resActions, err = e.listRoleResourceActions(ctx, roleResource, resType.Name)
 		if err != nil {
-			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+			logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 			return err
 		}
@@ -1103,7 +1358,7 @@ func (e *engine) DeleteRole(ctx context.Context, roleResource types.Resource) er
 
 	_, err = e.store.DeleteRole(dbCtx, roleResource.ID)
 	if err != nil {
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		if errors.Is(err, storage.ErrNoRoleFound) {
 			return ErrRoleNotFound
@@ -1119,7 +1374,7 @@ func (e *engine) DeleteRole(ctx context.Context, roleResource types.Resource) er
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
 
-			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+			logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 			// At this point, some spicedb changes may have already been applied.
 			// Attempting to rollback could result in failures that could result in the same situation.
@@ -1134,7 +1389,7 @@ func (e *engine) DeleteRole(ctx context.Context, roleResource types.Resource) er
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		// At this point, spicedb changes have already been applied.
 		// Attempting to rollback could result in failures that could result in the same situation.
@@ -1144,6 +1399,23 @@ func (e *engine) DeleteRole(ctx context.Context, roleResource types.Resource) er
 		return err
 	}
 
+	deleted := types.Role{ID: roleResource.ID}
+
+	for resource, relActions := range resActions {
+		deleted.ResourceID = resource.ID
+
+		for _, relAction := range relActions {
+			deleted.Actions = append(deleted.Actions, relationToAction(relAction))
+		}
+	}
+
+	e.publishAuditEvent(ctx, auditEvent{
+		eventType:  AuditEventRoleDeleted,
+		subjectID:  deleted.ID,
+		resourceID: deleted.ResourceID,
+		before:     auditRole(deleted),
+	})
+
 	return nil
 }
 
@@ -1153,7 +1425,7 @@ func (e *engine) NewResourceFromID(id gidx.PrefixedID) (types.Resource, error) {
 
 	rType, ok := e.schemaPrefixMap[prefix]
 	if !ok {
-		return types.Resource{}, ErrInvalidNamespace
+		return types.Resource{}, &InvalidIDPrefixError{ID: id}
 	}
 
 	out := types.Resource{
@@ -1164,6 +1436,20 @@ func (e *engine) NewResourceFromID(id gidx.PrefixedID) (types.Resource, error) {
 	return out, nil
 }
 
+// checkResourceType returns an InvalidIDPrefixError if the resource is not
+// of one of the expected resource types.
+func checkResourceType(resource types.Resource, expected ...string) error {
+	if slices.Contains(expected, resource.Type) {
+		return nil
+	}
+
+	return &InvalidIDPrefixError{
+		ID:           resource.ID,
+		ResourceType: resource.Type,
+		Expected:     expected,
+	}
+}
+
 // GetResourceType returns the resource type by name
 func (e *engine) GetResourceType(name string) *types.ResourceType {
 	rType, ok := e.schemaTypeMap[name]
@@ -4,50 +4,39 @@ import (
 	"context"
 	"testing"
 
-	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
-	"github.com/authzed/authzed-go/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"go.infratographer.com/x/gidx"
 
 	"go.infratographer.com/permissions-api/internal/iapl"
-	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/spicedbx/testspicedb"
 	"go.infratographer.com/permissions-api/internal/storage"
 	"go.infratographer.com/permissions-api/internal/storage/teststore"
 	"go.infratographer.com/permissions-api/internal/testingx"
 	"go.infratographer.com/permissions-api/internal/types"
 )
 
+// testEngine returns an engine backed by a fresh test store and an isolated
+// SpiceDB namespace derived from the given namespace prefix. Tests must use
+// the engine's namespace rather than the prefix when referencing SpiceDB objects.
 func testEngine(ctx context.Context, t *testing.T, namespace string, policy iapl.Policy) *engine {
-	config := spicedbx.Config{
-		Endpoint: "spicedb:50051",
-		Key:      "infradev",
-		Insecure: true,
-	}
-
-	client, err := spicedbx.NewClient(config, false)
-	require.NoError(t, err)
+	client, namespace := testspicedb.NewTestSpiceDB(ctx, t, namespace, policy.Schema(), policy.Caveats()...)
 
 	store, cleanStore := teststore.NewTestStorage(t)
 
-	schema, err := spicedbx.GenerateSchema(namespace, policy.Schema())
-	require.NoError(t, err)
-
-	request := &pb.WriteSchemaRequest{Schema: schema}
-	_, err = client.WriteSchema(ctx, request)
-	require.NoError(t, err)
-
-	t.Cleanup(func() {
-		cleanDB(ctx, t, client, namespace, policy)
-		cleanStore()
-	})
+	t.Cleanup(cleanStore)
 
 	// We call the constructor here to ensure the engine is created appropriately, but
 	// then return the underlying type so we can do testing with it.
 	out, err := NewEngine(namespace, client, store, WithPolicy(policy))
 	require.NoError(t, err)
 
+	// stopped before the store is closed, so pending work is flushed
+	t.Cleanup(func() {
+		assert.NoError(t, out.Stop())
+	})
+
 	return out.(*engine)
 }
 
@@ -77,20 +66,6 @@ func testPolicy() iapl.Policy {
 	return policy
 }
 
-func cleanDB(ctx context.Context, t *testing.T, client *authzed.Client, namespace string, p iapl.Policy) {
-	for _, resourceType := range p.Schema() {
-		dbType := resourceType.Name
-		namespacedType := namespace + "/" + dbType
-		delRequest := &pb.DeleteRelationshipsRequest{
-			RelationshipFilter: &pb.RelationshipFilter{
-				ResourceType: namespacedType,
-			},
-		}
-		_, err := client.DeleteRelationships(ctx, delRequest)
-		require.NoError(t, err, "failure deleting relationships")
-	}
-}
-
 func TestCreateRoles(t *testing.T) {
 	namespace := "testroles"
 	ctx := context.Background()
@@ -815,6 +790,9 @@ func TestSubjectActions(t *testing.T) {
 			},
 			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[any]) {
 				assert.ErrorIs(t, res.Err, ErrInvalidAction)
+
+				// actions not in the policy must not create metric series
+				assert.False(t, checksTotal.DeleteLabelValues("bad_action", outcomeDenied))
 			},
 		},
 		{
@@ -839,3 +817,67 @@ func TestSubjectActions(t *testing.T) {
 
 	testingx.RunTests(ctx, t, testCases, testFn)
 }
+
+func TestSubjectAllowedActions(t *testing.T) {
+	namespace := "infratestallowedactions"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, testPolicy())
+
+	tenRes, err := e.NewResourceFromID(gidx.MustNewID("tnntten"))
+	require.NoError(t, err)
+	otherRes, err := e.NewResourceFromID(gidx.MustNewID("tnntten"))
+	require.NoError(t, err)
+	subjRes, err := e.NewResourceFromID(gidx.MustNewID("idntusr"))
+	require.NoError(t, err)
+	actorRes, err := e.NewResourceFromID(gidx.MustNewID("idntusr"))
+	require.NoError(t, err)
+
+	role, err := e.CreateRole(
+		ctx,
+		actorRes,
+		tenRes,
+		"test",
+		[]string{
+			"loadbalancer_get",
+			"loadbalancer_update",
+		},
+	)
+	require.NoError(t, err)
+
+	err = e.AssignSubjectRole(ctx, subjRes, role)
+	require.NoError(t, err)
+
+	testCases := []testingx.TestCase[types.Resource, []string]{
+		{
+			Name:  "AllowedActions",
+			Input: tenRes,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]string]) {
+				require.NoError(t, res.Err)
+
+				assert.Contains(t, res.Success, "loadbalancer_get")
+				assert.Contains(t, res.Success, "loadbalancer_update")
+				assert.NotContains(t, res.Success, "loadbalancer_delete")
+			},
+		},
+		{
+			Name:  "NoActions",
+			Input: otherRes,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]string]) {
+				require.NoError(t, res.Err)
+
+				assert.Empty(t, res.Success)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, resource types.Resource) testingx.TestResult[[]string] {
+		actions, err := e.SubjectAllowedActions(ctx, subjRes, resource)
+
+		return testingx.TestResult[[]string]{
+			Success: actions,
+			Err:     err,
+		}
+	}
+
+	testingx.RunTests(ctx, t, testCases, testFn)
+}
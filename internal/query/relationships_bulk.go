@@ -0,0 +1,128 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// WriteRelationships creates and deletes relationships in chunks of at most
+// maxRelationshipUpdatesPerWrite, in order. Every chunk is only applied if
+// all preconditions hold at the time it is written. Chunks are not atomic
+// with each other: the number of writes applied is returned together with
+// the error of the first chunk which failed.
+func (e *engine) WriteRelationships(ctx context.Context, writes []types.RelationshipWrite, preconditions []types.RelationshipPrecondition) (int, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.WriteRelationships",
+		trace.WithAttributes(
+			attribute.Int("writes", len(writes)),
+			attribute.Int("preconditions", len(preconditions)),
+		),
+	)
+	defer span.End()
+
+	fail := func(written int, err error) (int, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return written, err
+	}
+
+	updates := make([]*pb.RelationshipUpdate, len(writes))
+
+	for i, write := range writes {
+		if err := e.validateRelationship(write.Relationship); err != nil {
+			return fail(0, fmt.Errorf("%w: invalid write %d", err, i))
+		}
+
+		var operation pb.RelationshipUpdate_Operation
+
+		switch write.Operation {
+		case types.RelationshipOperationCreate:
+			operation = pb.RelationshipUpdate_OPERATION_TOUCH
+		case types.RelationshipOperationDelete:
+			operation = pb.RelationshipUpdate_OPERATION_DELETE
+		default:
+			return fail(0, fmt.Errorf("%w: invalid operation %q of write %d", ErrInvalidArgument, write.Operation, i))
+		}
+
+		updates[i] = e.relationshipsToUpdates([]types.Relationship{write.Relationship}, operation)[0]
+	}
+
+	pbPreconditions := make([]*pb.Precondition, len(preconditions))
+
+	for i, precondition := range preconditions {
+		if err := e.validateRelationship(precondition.Relationship); err != nil {
+			return fail(0, fmt.Errorf("%w: invalid precondition %d", err, i))
+		}
+
+		pbPreconditions[i] = e.relationshipPrecondition(precondition)
+	}
+
+	written := 0
+
+	for start := 0; start < len(writes); start += maxRelationshipUpdatesPerWrite {
+		end := min(start+maxRelationshipUpdatesPerWrite, len(writes))
+
+		resp, err := e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
+			Updates:               updates[start:end],
+			OptionalPreconditions: pbPreconditions,
+		})
+		if err != nil {
+			return fail(written, err)
+		}
+
+		var created, deleted []types.Relationship
+
+		for _, write := range writes[start:end] {
+			if write.Operation == types.RelationshipOperationCreate {
+				created = append(created, write.Relationship)
+			} else {
+				deleted = append(deleted, write.Relationship)
+			}
+		}
+
+		e.updateRelationshipZedTokens(ctx, append(created, deleted...), resp.WrittenAt.Token)
+
+		e.publishRelationshipAuditEvents(ctx, AuditEventRelationshipCreated, created)
+		e.publishRelationshipAuditEvents(ctx, AuditEventRelationshipDeleted, deleted)
+
+		written = end
+
+		if err := e.provisionRoleTemplates(ctx, created); err != nil {
+			return fail(written, err)
+		}
+	}
+
+	return written, nil
+}
+
+// relationshipPrecondition returns the SpiceDB precondition requiring the
+// relationship to exist or not to exist.
+func (e *engine) relationshipPrecondition(precondition types.RelationshipPrecondition) *pb.Precondition {
+	rel := precondition.Relationship
+
+	operation := pb.Precondition_OPERATION_MUST_NOT_MATCH
+	if precondition.MustExist {
+		operation = pb.Precondition_OPERATION_MUST_MATCH
+	}
+
+	return &pb.Precondition{
+		Operation: operation,
+		Filter: &pb.RelationshipFilter{
+			ResourceType:       e.namespaced(rel.Resource.Type),
+			OptionalResourceId: rel.Resource.ID.String(),
+			OptionalRelation:   rel.Relation,
+			OptionalSubjectFilter: &pb.SubjectFilter{
+				SubjectType:       e.namespaced(rel.Subject.Type),
+				OptionalSubjectId: rel.Subject.ID.String(),
+			},
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestWriteRelationships(t *testing.T) {
+	namespace := "testbulkrelationships"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	child, err := e.NewResourceFromIDString("tnntten-child")
+	require.NoError(t, err)
+	lb, err := e.NewResourceFromIDString("loadbal-lb")
+	require.NoError(t, err)
+
+	childParent := types.Relationship{Resource: child, Relation: "parent", Subject: root}
+	lbOwner := types.Relationship{Resource: lb, Relation: "owner", Subject: child}
+
+	// more writes than fit in a single chunk
+	writes := make([]types.RelationshipWrite, 0, maxRelationshipUpdatesPerWrite+2)
+
+	for i := 0; i < maxRelationshipUpdatesPerWrite; i++ {
+		lb, err := e.NewResourceFromIDString("loadbal-lb" + string(rune('a'+i%26)) + string(rune('a'+i/26)))
+		require.NoError(t, err)
+
+		writes = append(writes, types.RelationshipWrite{
+			Operation:    types.RelationshipOperationCreate,
+			Relationship: types.Relationship{Resource: lb, Relation: "owner", Subject: root},
+		})
+	}
+
+	writes = append(writes,
+		types.RelationshipWrite{Operation: types.RelationshipOperationCreate, Relationship: childParent},
+		types.RelationshipWrite{Operation: types.RelationshipOperationCreate, Relationship: lbOwner},
+	)
+
+	written, err := e.WriteRelationships(ctx, writes, []types.RelationshipPrecondition{
+		{Relationship: writes[0].Relationship, MustExist: false},
+	})
+	require.Error(t, err, "the precondition fails for the second chunk, once the first chunk created the relationship")
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, maxRelationshipUpdatesPerWrite, written)
+
+	rels, err := e.ListRelationshipsFrom(ctx, child)
+	require.NoError(t, err)
+	assert.Empty(t, rels)
+
+	written, err = e.WriteRelationships(ctx, writes[maxRelationshipUpdatesPerWrite:], nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, written)
+
+	rels, err = e.ListRelationshipsFrom(ctx, child)
+	require.NoError(t, err)
+	require.Len(t, rels, 1)
+	assert.Equal(t, "parent", rels[0].Relation)
+	assert.Equal(t, root.ID, rels[0].Subject.ID)
+
+	written, err = e.WriteRelationships(ctx,
+		[]types.RelationshipWrite{{Operation: types.RelationshipOperationDelete, Relationship: lbOwner}},
+		[]types.RelationshipPrecondition{{Relationship: childParent, MustExist: true}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 1, written)
+
+	rels, err = e.ListRelationshipsFrom(ctx, lb)
+	require.NoError(t, err)
+	assert.Empty(t, rels)
+
+	_, err = e.WriteRelationships(ctx,
+		[]types.RelationshipWrite{{Operation: "update", Relationship: lbOwner}},
+		nil,
+	)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, err = e.WriteRelationships(ctx,
+		[]types.RelationshipWrite{{
+			Operation:    types.RelationshipOperationCreate,
+			Relationship: types.Relationship{Resource: lb, Relation: "parent", Subject: root},
+		}},
+		nil,
+	)
+	assert.ErrorIs(t, err, ErrInvalidRelationship)
+}
@@ -0,0 +1,171 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// ReadRelationships returns a page of at most limit relationships matching the
+// filter, including the relationships of roles and role-bindings, starting
+// after the cursor. The returned cursor is empty when there are no further pages.
+func (e *engine) ReadRelationships(ctx context.Context, filter types.RelationshipFilter, limit int, cursor string) ([]types.RelationshipRecord, string, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ReadRelationships",
+		trace.WithAttributes(
+			attribute.String("resource_type", filter.ResourceType),
+			attribute.String("resource_id", filter.ResourceID),
+			attribute.String("relation", filter.Relation),
+			attribute.Int("limit", limit),
+		),
+	)
+	defer span.End()
+
+	fail := func(err error) ([]types.RelationshipRecord, string, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, "", err
+	}
+
+	if limit <= 0 {
+		return fail(fmt.Errorf("%w: limit must be positive", ErrInvalidArgument))
+	}
+
+	pbFilter, err := e.relationshipFilter(filter)
+	if err != nil {
+		return fail(err)
+	}
+
+	req := &pb.ReadRelationshipsRequest{
+		Consistency: &pb.Consistency{
+			Requirement: &pb.Consistency_FullyConsistent{
+				FullyConsistent: true,
+			},
+		},
+		RelationshipFilter: pbFilter,
+		OptionalLimit:      uint32(limit),
+	}
+
+	if cursor != "" {
+		req.OptionalCursor = &pb.Cursor{Token: cursor}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := e.client.ReadRelationships(ctx, req)
+	if err != nil {
+		return fail(err)
+	}
+
+	records := make([]types.RelationshipRecord, 0, limit)
+
+	var next string
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return fail(err)
+		}
+
+		records = append(records, e.relationshipRecord(resp.Relationship))
+		next = resp.AfterResultCursor.GetToken()
+	}
+
+	// a short page is the last one
+	if len(records) < limit {
+		next = ""
+	}
+
+	return records, next, nil
+}
+
+// relationshipFilter returns the SpiceDB filter for the relationship filter,
+// resource and subject types are taken from the ID prefixes when not set.
+func (e *engine) relationshipFilter(filter types.RelationshipFilter) (*pb.RelationshipFilter, error) {
+	resourceType := filter.ResourceType
+
+	if resourceType == "" {
+		if filter.ResourceID == "" {
+			return nil, fmt.Errorf("%w: resource type or resource ID is required", ErrInvalidArgument)
+		}
+
+		var err error
+
+		if resourceType, err = e.idResourceType(filter.ResourceID); err != nil {
+			return nil, err
+		}
+	}
+
+	out := &pb.RelationshipFilter{
+		ResourceType:       e.namespaced(resourceType),
+		OptionalResourceId: filter.ResourceID,
+		OptionalRelation:   filter.Relation,
+	}
+
+	if filter.SubjectType == "" && filter.SubjectID == "" {
+		return out, nil
+	}
+
+	subjectType := filter.SubjectType
+
+	if subjectType == "" {
+		var err error
+
+		if subjectType, err = e.idResourceType(filter.SubjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	out.OptionalSubjectFilter = &pb.SubjectFilter{
+		SubjectType:       e.namespaced(subjectType),
+		OptionalSubjectId: filter.SubjectID,
+	}
+
+	return out, nil
+}
+
+// idResourceType returns the resource type of the ID's prefix.
+func (e *engine) idResourceType(idStr string) (string, error) {
+	id, err := gidx.Parse(idStr)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid ID %q: %s", ErrInvalidArgument, idStr, err)
+	}
+
+	resource, err := e.NewResourceFromID(id)
+	if err != nil {
+		return "", err
+	}
+
+	return resource.Type, nil
+}
+
+// relationshipRecord converts a SpiceDB relationship, removing the namespace
+// from the resource and subject types.
+func (e *engine) relationshipRecord(rel *pb.Relationship) types.RelationshipRecord {
+	prefix := e.namespace + "/"
+
+	return types.RelationshipRecord{
+		ResourceType:    strings.TrimPrefix(rel.Resource.ObjectType, prefix),
+		ResourceID:      rel.Resource.ObjectId,
+		Relation:        rel.Relation,
+		SubjectType:     strings.TrimPrefix(rel.Subject.Object.ObjectType, prefix),
+		SubjectID:       rel.Subject.Object.ObjectId,
+		SubjectRelation: rel.Subject.OptionalRelation,
+	}
+}
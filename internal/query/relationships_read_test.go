@@ -0,0 +1,90 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestReadRelationships(t *testing.T) {
+	namespace := "testreadrelationships"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	child, err := e.NewResourceFromIDString("tnntten-child")
+	require.NoError(t, err)
+
+	writes := []types.RelationshipWrite{}
+
+	for _, id := range []string{"loadbal-lba", "loadbal-lbb", "loadbal-lbc"} {
+		lb, err := e.NewResourceFromIDString(id)
+		require.NoError(t, err)
+
+		writes = append(writes, types.RelationshipWrite{
+			Operation:    types.RelationshipOperationCreate,
+			Relationship: types.Relationship{Resource: lb, Relation: "owner", Subject: root},
+		})
+	}
+
+	writes = append(writes, types.RelationshipWrite{
+		Operation:    types.RelationshipOperationCreate,
+		Relationship: types.Relationship{Resource: child, Relation: "parent", Subject: root},
+	})
+
+	_, err = e.WriteRelationships(ctx, writes, nil)
+	require.NoError(t, err)
+
+	filter := types.RelationshipFilter{
+		ResourceType: "loadbalancer",
+		Relation:     "owner",
+		SubjectID:    root.ID.String(),
+	}
+
+	page, cursor, err := e.ReadRelationships(ctx, filter, 2, "")
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.NotEmpty(t, cursor)
+
+	next, cursor, err := e.ReadRelationships(ctx, filter, 2, cursor)
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+	assert.Empty(t, cursor)
+
+	ids := []string{}
+
+	for _, rec := range append(page, next...) {
+		assert.Equal(t, "loadbalancer", rec.ResourceType)
+		assert.Equal(t, "owner", rec.Relation)
+		assert.Equal(t, "tenant", rec.SubjectType)
+		assert.Equal(t, root.ID.String(), rec.SubjectID)
+
+		ids = append(ids, rec.ResourceID)
+	}
+
+	assert.ElementsMatch(t, []string{"loadbal-lba", "loadbal-lbb", "loadbal-lbc"}, ids)
+
+	recs, cursor, err := e.ReadRelationships(ctx, types.RelationshipFilter{ResourceID: child.ID.String()}, 10, "")
+	require.NoError(t, err)
+	assert.Empty(t, cursor)
+	assert.Equal(t, []types.RelationshipRecord{
+		{
+			ResourceType: "tenant",
+			ResourceID:   child.ID.String(),
+			Relation:     "parent",
+			SubjectType:  "tenant",
+			SubjectID:    root.ID.String(),
+		},
+	}, recs)
+
+	_, _, err = e.ReadRelationships(ctx, types.RelationshipFilter{Relation: "owner"}, 10, "")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, _, err = e.ReadRelationships(ctx, filter, 0, "")
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+}
@@ -0,0 +1,502 @@
+package query
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/authzed/authzed-go/v1"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// ReloadableEngine is an Engine whose policy can be replaced while it is in
+// use. Every call is served by the engine of a single policy, calls in flight
+// while the policy is reloaded finish with the previous policy.
+type ReloadableEngine struct {
+	current atomic.Pointer[engine]
+
+	// mu serializes reloads.
+	mu sync.Mutex
+}
+
+var _ Engine = (*ReloadableEngine)(nil)
+
+// NewReloadableEngine returns a new client for making permissions queries
+// whose policy can be reloaded.
+func NewReloadableEngine(namespace string, client *authzed.Client, store storage.Storage, options ...Option) (*ReloadableEngine, error) {
+	e, err := NewEngine(namespace, client, store, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ReloadableEngine{}
+
+	r.current.Store(e.(*engine))
+
+	return r, nil
+}
+
+// ReloadPolicy validates the policy and replaces the policy of the engine with
+// it, an invalid policy is refused and the current policy is kept. The SpiceDB
+// schema is not changed, it must be written before a policy depending on it is
+// loaded.
+func (r *ReloadableEngine) ReloadPolicy(policy iapl.Policy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the new engine shares the clients, the store and the background
+	// trackers with the current one, only the policy is replaced.
+	next := *r.current.Load()
+
+	WithPolicy(policy)(&next)
+
+	r.current.Store(&next)
+
+	// cached decisions may have been made with actions of the previous policy.
+	if next.checkCache != nil {
+		next.checkCache.reset()
+	}
+
+	if next.degraded != nil && next.degraded.cache != nil {
+		next.degraded.cache.clear()
+	}
+
+	return nil
+}
+
+// SetCheckCacheTTL changes the maximum time permission check results are
+// cached for, DefaultCheckCacheTTL when not positive. It has no effect when
+// the check cache is not enabled.
+func (r *ReloadableEngine) SetCheckCacheTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultCheckCacheTTL
+	}
+
+	// the caches are shared by the engines of all policies.
+	if e := r.current.Load(); e.checkCache != nil {
+		e.checkCache.decisions.setTTL(ttl)
+	}
+}
+
+// SetDegradedCacheTTL changes the time cached decisions may be served for
+// while the SpiceDB circuit is open, DefaultDecisionCacheTTL when not
+// positive. It has no effect when degraded mode does not cache decisions.
+func (r *ReloadableEngine) SetDegradedCacheTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultDecisionCacheTTL
+	}
+
+	if e := r.current.Load(); e.degraded != nil && e.degraded.cache != nil {
+		e.degraded.cache.setTTL(ttl)
+	}
+}
+
+// AssignSubjectRole calls AssignSubjectRole of the current engine.
+func (r *ReloadableEngine) AssignSubjectRole(ctx context.Context, subject types.Resource, role types.Role) error {
+	return r.current.Load().AssignSubjectRole(ctx, subject, role)
+}
+
+// UnassignSubjectRole calls UnassignSubjectRole of the current engine.
+func (r *ReloadableEngine) UnassignSubjectRole(ctx context.Context, subject types.Resource, role types.Role) error {
+	return r.current.Load().UnassignSubjectRole(ctx, subject, role)
+}
+
+// CreateRelationships calls CreateRelationships of the current engine.
+func (r *ReloadableEngine) CreateRelationships(ctx context.Context, rels []types.Relationship) error {
+	return r.current.Load().CreateRelationships(ctx, rels)
+}
+
+// CreateRole calls CreateRole of the current engine.
+func (r *ReloadableEngine) CreateRole(ctx context.Context, actor, res types.Resource, roleName string, actions []string) (types.Role, error) {
+	return r.current.Load().CreateRole(ctx, actor, res, roleName, actions)
+}
+
+// UpdateRole calls UpdateRole of the current engine.
+func (r *ReloadableEngine) UpdateRole(ctx context.Context, actor, roleResource types.Resource, newName string, newActions []string) (types.Role, error) {
+	return r.current.Load().UpdateRole(ctx, actor, roleResource, newName, newActions)
+}
+
+// GetRole calls GetRole of the current engine.
+func (r *ReloadableEngine) GetRole(ctx context.Context, roleResource types.Resource) (types.Role, error) {
+	return r.current.Load().GetRole(ctx, roleResource)
+}
+
+// GetRoleResource calls GetRoleResource of the current engine.
+func (r *ReloadableEngine) GetRoleResource(ctx context.Context, roleResource types.Resource) (types.Resource, error) {
+	return r.current.Load().GetRoleResource(ctx, roleResource)
+}
+
+// ListAssignments calls ListAssignments of the current engine.
+func (r *ReloadableEngine) ListAssignments(ctx context.Context, role types.Role) ([]types.Resource, error) {
+	return r.current.Load().ListAssignments(ctx, role)
+}
+
+// ListRelationshipsFrom calls ListRelationshipsFrom of the current engine.
+func (r *ReloadableEngine) ListRelationshipsFrom(ctx context.Context, resource types.Resource) ([]types.Relationship, error) {
+	return r.current.Load().ListRelationshipsFrom(ctx, resource)
+}
+
+// ListRelationshipsTo calls ListRelationshipsTo of the current engine.
+func (r *ReloadableEngine) ListRelationshipsTo(ctx context.Context, resource types.Resource) ([]types.Relationship, error) {
+	return r.current.Load().ListRelationshipsTo(ctx, resource)
+}
+
+// StreamRelationshipsFrom calls StreamRelationshipsFrom of the current engine.
+func (r *ReloadableEngine) StreamRelationshipsFrom(ctx context.Context, resource types.Resource, fn func(types.Relationship) error) error {
+	return r.current.Load().StreamRelationshipsFrom(ctx, resource, fn)
+}
+
+// StreamRelationshipsTo calls StreamRelationshipsTo of the current engine.
+func (r *ReloadableEngine) StreamRelationshipsTo(ctx context.Context, resource types.Resource, fn func(types.Relationship) error) error {
+	return r.current.Load().StreamRelationshipsTo(ctx, resource, fn)
+}
+
+// ListRoles calls ListRoles of the current engine.
+func (r *ReloadableEngine) ListRoles(ctx context.Context, resource types.Resource) ([]types.Role, error) {
+	return r.current.Load().ListRoles(ctx, resource)
+}
+
+// DeleteRelationships calls DeleteRelationships of the current engine.
+func (r *ReloadableEngine) DeleteRelationships(ctx context.Context, relationships ...types.Relationship) error {
+	return r.current.Load().DeleteRelationships(ctx, relationships...)
+}
+
+// WriteRelationships calls WriteRelationships of the current engine.
+func (r *ReloadableEngine) WriteRelationships(ctx context.Context, writes []types.RelationshipWrite, preconditions []types.RelationshipPrecondition) (int, error) {
+	return r.current.Load().WriteRelationships(ctx, writes, preconditions)
+}
+
+// ReadRelationships calls ReadRelationships of the current engine.
+func (r *ReloadableEngine) ReadRelationships(ctx context.Context, filter types.RelationshipFilter, limit int, cursor string) ([]types.RelationshipRecord, string, error) {
+	return r.current.Load().ReadRelationships(ctx, filter, limit, cursor)
+}
+
+// WatchRelationshipChanges calls WatchRelationshipChanges of the current engine.
+func (r *ReloadableEngine) WatchRelationshipChanges(ctx context.Context, after string, fn func(types.RelationshipChange) error) error {
+	return r.current.Load().WatchRelationshipChanges(ctx, after, fn)
+}
+
+// DeleteRole calls DeleteRole of the current engine.
+func (r *ReloadableEngine) DeleteRole(ctx context.Context, roleResource types.Resource) error {
+	return r.current.Load().DeleteRole(ctx, roleResource)
+}
+
+// DeleteResourceRelationships calls DeleteResourceRelationships of the current engine.
+func (r *ReloadableEngine) DeleteResourceRelationships(ctx context.Context, resource types.Resource) error {
+	return r.current.Load().DeleteResourceRelationships(ctx, resource)
+}
+
+// DeleteResource calls DeleteResource of the current engine.
+func (r *ReloadableEngine) DeleteResource(ctx context.Context, resource types.Resource) error {
+	return r.current.Load().DeleteResource(ctx, resource)
+}
+
+// NewResourceFromID calls NewResourceFromID of the current engine.
+func (r *ReloadableEngine) NewResourceFromID(id gidx.PrefixedID) (types.Resource, error) {
+	return r.current.Load().NewResourceFromID(id)
+}
+
+// GetResourceType calls GetResourceType of the current engine.
+func (r *ReloadableEngine) GetResourceType(name string) *types.ResourceType {
+	return r.current.Load().GetResourceType(name)
+}
+
+// SubjectHasPermission calls SubjectHasPermission of the current engine.
+func (r *ReloadableEngine) SubjectHasPermission(ctx context.Context, subject types.Resource, action string, resource types.Resource) error {
+	return r.current.Load().SubjectHasPermission(ctx, subject, action, resource)
+}
+
+// SubjectAllowedActions calls SubjectAllowedActions of the current engine.
+func (r *ReloadableEngine) SubjectAllowedActions(ctx context.Context, subject, resource types.Resource) ([]string, error) {
+	return r.current.Load().SubjectAllowedActions(ctx, subject, resource)
+}
+
+// ExplainPermission calls ExplainPermission of the current engine.
+func (r *ReloadableEngine) ExplainPermission(ctx context.Context, subject types.Resource, action string, resource types.Resource) ([]types.GrantStep, error) {
+	return r.current.Load().ExplainPermission(ctx, subject, action, resource)
+}
+
+// FilterAllowedResources calls FilterAllowedResources of the current engine.
+func (r *ReloadableEngine) FilterAllowedResources(ctx context.Context, subject types.Resource, action string, resources []types.Resource) ([]types.Resource, error) {
+	return r.current.Load().FilterAllowedResources(ctx, subject, action, resources)
+}
+
+// CreateRoleV2 calls CreateRoleV2 of the current engine.
+func (r *ReloadableEngine) CreateRoleV2(ctx context.Context, actor, owner types.Resource, roleName string, actions []string) (types.Role, error) {
+	return r.current.Load().CreateRoleV2(ctx, actor, owner, roleName, actions)
+}
+
+// ListRolesV2 calls ListRolesV2 of the current engine.
+func (r *ReloadableEngine) ListRolesV2(ctx context.Context, owner types.Resource) ([]types.Role, error) {
+	return r.current.Load().ListRolesV2(ctx, owner)
+}
+
+// GetRoleV2 calls GetRoleV2 of the current engine.
+func (r *ReloadableEngine) GetRoleV2(ctx context.Context, role types.Resource) (types.Role, error) {
+	return r.current.Load().GetRoleV2(ctx, role)
+}
+
+// UpdateRoleV2 calls UpdateRoleV2 of the current engine.
+func (r *ReloadableEngine) UpdateRoleV2(ctx context.Context, actor, roleResource types.Resource, newName string, newActions []string) (types.Role, error) {
+	return r.current.Load().UpdateRoleV2(ctx, actor, roleResource, newName, newActions)
+}
+
+// DeleteRoleV2 calls DeleteRoleV2 of the current engine.
+func (r *ReloadableEngine) DeleteRoleV2(ctx context.Context, roleResource types.Resource) error {
+	return r.current.Load().DeleteRoleV2(ctx, roleResource)
+}
+
+// ForceDeleteRoleV2 calls ForceDeleteRoleV2 of the current engine.
+func (r *ReloadableEngine) ForceDeleteRoleV2(ctx context.Context, roleResource types.Resource) (int, error) {
+	return r.current.Load().ForceDeleteRoleV2(ctx, roleResource)
+}
+
+// CreateRoleBinding calls CreateRoleBinding of the current engine.
+func (r *ReloadableEngine) CreateRoleBinding(ctx context.Context, actor, resource, role types.Resource, subjects []types.RoleBindingSubject) (types.RoleBinding, error) {
+	return r.current.Load().CreateRoleBinding(ctx, actor, resource, role, subjects)
+}
+
+// CreateRoleBindingWithCaveat calls CreateRoleBindingWithCaveat of the current engine.
+func (r *ReloadableEngine) CreateRoleBindingWithCaveat(
+	ctx context.Context,
+	actor, resource, role types.Resource,
+	subjects []types.RoleBindingSubject,
+	caveat types.RoleBindingCaveat,
+) (types.RoleBinding, error) {
+	return r.current.Load().CreateRoleBindingWithCaveat(ctx, actor, resource, role, subjects, caveat)
+}
+
+// ListRoleBindings calls ListRoleBindings of the current engine.
+func (r *ReloadableEngine) ListRoleBindings(ctx context.Context, resource types.Resource, optionalRole *types.Resource) ([]types.RoleBinding, error) {
+	return r.current.Load().ListRoleBindings(ctx, resource, optionalRole)
+}
+
+// StreamRoleBindings calls StreamRoleBindings of the current engine.
+func (r *ReloadableEngine) StreamRoleBindings(ctx context.Context, resource types.Resource, fn func(types.RoleBinding) error) error {
+	return r.current.Load().StreamRoleBindings(ctx, resource, fn)
+}
+
+// ListSubjectRoleBindings calls ListSubjectRoleBindings of the current engine.
+func (r *ReloadableEngine) ListSubjectRoleBindings(ctx context.Context, subject types.Resource) ([]types.RoleBinding, error) {
+	return r.current.Load().ListSubjectRoleBindings(ctx, subject)
+}
+
+// GetRoleBinding calls GetRoleBinding of the current engine.
+func (r *ReloadableEngine) GetRoleBinding(ctx context.Context, rolebinding types.Resource) (types.RoleBinding, error) {
+	return r.current.Load().GetRoleBinding(ctx, rolebinding)
+}
+
+// UpdateRoleBinding calls UpdateRoleBinding of the current engine.
+func (r *ReloadableEngine) UpdateRoleBinding(ctx context.Context, actor, rolebinding types.Resource, subjects []types.RoleBindingSubject) (types.RoleBinding, error) {
+	return r.current.Load().UpdateRoleBinding(ctx, actor, rolebinding, subjects)
+}
+
+// DeleteRoleBinding calls DeleteRoleBinding of the current engine.
+func (r *ReloadableEngine) DeleteRoleBinding(ctx context.Context, rolebinding types.Resource) error {
+	return r.current.Load().DeleteRoleBinding(ctx, rolebinding)
+}
+
+// GetRoleBindingResource calls GetRoleBindingResource of the current engine.
+func (r *ReloadableEngine) GetRoleBindingResource(ctx context.Context, rb types.Resource) (types.Resource, error) {
+	return r.current.Load().GetRoleBindingResource(ctx, rb)
+}
+
+// CreateRoleBindings calls CreateRoleBindings of the current engine.
+func (r *ReloadableEngine) CreateRoleBindings(ctx context.Context, actor, resource types.Resource, requests []types.RoleBindingRequest) []types.RoleBindingResult {
+	return r.current.Load().CreateRoleBindings(ctx, actor, resource, requests)
+}
+
+// DeleteRoleBindings calls DeleteRoleBindings of the current engine.
+func (r *ReloadableEngine) DeleteRoleBindings(ctx context.Context, resource types.Resource, rolebindings []types.Resource) []types.RoleBindingResult {
+	return r.current.Load().DeleteRoleBindings(ctx, resource, rolebindings)
+}
+
+// ListStaleRoleBindings calls ListStaleRoleBindings of the current engine.
+func (r *ReloadableEngine) ListStaleRoleBindings(ctx context.Context, resource types.Resource, unusedSince time.Time) ([]types.RoleBinding, error) {
+	return r.current.Load().ListStaleRoleBindings(ctx, resource, unusedSince)
+}
+
+// SuggestRoleBindingReductions calls SuggestRoleBindingReductions of the current engine.
+func (r *ReloadableEngine) SuggestRoleBindingReductions(ctx context.Context, resource types.Resource, usedSince time.Time) ([]types.RoleBindingSuggestion, error) {
+	return r.current.Load().SuggestRoleBindingReductions(ctx, resource, usedSince)
+}
+
+// CreateInvitation calls CreateInvitation of the current engine.
+func (r *ReloadableEngine) CreateInvitation(ctx context.Context, actor, resource, role types.Resource, email string, ttl time.Duration) (types.Invitation, string, error) {
+	return r.current.Load().CreateInvitation(ctx, actor, resource, role, email, ttl)
+}
+
+// GetInvitation calls GetInvitation of the current engine.
+func (r *ReloadableEngine) GetInvitation(ctx context.Context, id gidx.PrefixedID) (types.Invitation, error) {
+	return r.current.Load().GetInvitation(ctx, id)
+}
+
+// ListInvitations calls ListInvitations of the current engine.
+func (r *ReloadableEngine) ListInvitations(ctx context.Context, resource types.Resource) ([]types.Invitation, error) {
+	return r.current.Load().ListInvitations(ctx, resource)
+}
+
+// DeleteInvitation calls DeleteInvitation of the current engine.
+func (r *ReloadableEngine) DeleteInvitation(ctx context.Context, id gidx.PrefixedID) error {
+	return r.current.Load().DeleteInvitation(ctx, id)
+}
+
+// RedeemInvitation calls RedeemInvitation of the current engine.
+func (r *ReloadableEngine) RedeemInvitation(ctx context.Context, subject types.Resource, token string) (types.RoleBinding, error) {
+	return r.current.Load().RedeemInvitation(ctx, subject, token)
+}
+
+// CreateAccessRequest calls CreateAccessRequest of the current engine.
+func (r *ReloadableEngine) CreateAccessRequest(ctx context.Context, subject, resource, role types.Resource, reason string) (types.AccessRequest, error) {
+	return r.current.Load().CreateAccessRequest(ctx, subject, resource, role, reason)
+}
+
+// GetAccessRequest calls GetAccessRequest of the current engine.
+func (r *ReloadableEngine) GetAccessRequest(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error) {
+	return r.current.Load().GetAccessRequest(ctx, id)
+}
+
+// ListAccessRequests calls ListAccessRequests of the current engine.
+func (r *ReloadableEngine) ListAccessRequests(ctx context.Context, resource types.Resource, status types.AccessRequestStatus) ([]types.AccessRequest, error) {
+	return r.current.Load().ListAccessRequests(ctx, resource, status)
+}
+
+// DecideAccessRequest calls DecideAccessRequest of the current engine.
+func (r *ReloadableEngine) DecideAccessRequest(
+	ctx context.Context,
+	actor types.Resource,
+	id gidx.PrefixedID,
+	approve bool,
+	reason string,
+) (types.AccessRequest, error) {
+	return r.current.Load().DecideAccessRequest(ctx, actor, id, approve, reason)
+}
+
+// CreateGroup calls CreateGroup of the current engine.
+func (r *ReloadableEngine) CreateGroup(ctx context.Context, actor, owner types.Resource, name, description string) (types.Group, error) {
+	return r.current.Load().CreateGroup(ctx, actor, owner, name, description)
+}
+
+// GetGroup calls GetGroup of the current engine.
+func (r *ReloadableEngine) GetGroup(ctx context.Context, id gidx.PrefixedID) (types.Group, error) {
+	return r.current.Load().GetGroup(ctx, id)
+}
+
+// ListGroups calls ListGroups of the current engine.
+func (r *ReloadableEngine) ListGroups(ctx context.Context, owner types.Resource) ([]types.Group, error) {
+	return r.current.Load().ListGroups(ctx, owner)
+}
+
+// UpdateGroup calls UpdateGroup of the current engine.
+func (r *ReloadableEngine) UpdateGroup(ctx context.Context, actor types.Resource, id gidx.PrefixedID, name, description string) (types.Group, error) {
+	return r.current.Load().UpdateGroup(ctx, actor, id, name, description)
+}
+
+// DeleteGroup calls DeleteGroup of the current engine.
+func (r *ReloadableEngine) DeleteGroup(ctx context.Context, id gidx.PrefixedID) error {
+	return r.current.Load().DeleteGroup(ctx, id)
+}
+
+// ListGroupMembers calls ListGroupMembers of the current engine.
+func (r *ReloadableEngine) ListGroupMembers(ctx context.Context, id gidx.PrefixedID) ([]types.Resource, error) {
+	return r.current.Load().ListGroupMembers(ctx, id)
+}
+
+// ExpandGroupMembers calls ExpandGroupMembers of the current engine.
+func (r *ReloadableEngine) ExpandGroupMembers(ctx context.Context, id gidx.PrefixedID) ([]types.GroupMember, error) {
+	return r.current.Load().ExpandGroupMembers(ctx, id)
+}
+
+// AddGroupMembers calls AddGroupMembers of the current engine.
+func (r *ReloadableEngine) AddGroupMembers(ctx context.Context, id gidx.PrefixedID, members ...types.Resource) error {
+	return r.current.Load().AddGroupMembers(ctx, id, members...)
+}
+
+// RemoveGroupMembers calls RemoveGroupMembers of the current engine.
+func (r *ReloadableEngine) RemoveGroupMembers(ctx context.Context, id gidx.PrefixedID, members ...types.Resource) error {
+	return r.current.Load().RemoveGroupMembers(ctx, id, members...)
+}
+
+// ResolveResource calls ResolveResource of the current engine.
+func (r *ReloadableEngine) ResolveResource(ctx context.Context, id string) (types.Resource, error) {
+	return r.current.Load().ResolveResource(ctx, id)
+}
+
+// CreateResourceAlias calls CreateResourceAlias of the current engine.
+func (r *ReloadableEngine) CreateResourceAlias(ctx context.Context, actor, resource types.Resource, alias string) (types.ResourceAlias, error) {
+	return r.current.Load().CreateResourceAlias(ctx, actor, resource, alias)
+}
+
+// GetResourceAlias calls GetResourceAlias of the current engine.
+func (r *ReloadableEngine) GetResourceAlias(ctx context.Context, alias string) (types.ResourceAlias, error) {
+	return r.current.Load().GetResourceAlias(ctx, alias)
+}
+
+// ListResourceAliases calls ListResourceAliases of the current engine.
+func (r *ReloadableEngine) ListResourceAliases(ctx context.Context, resource types.Resource) ([]types.ResourceAlias, error) {
+	return r.current.Load().ListResourceAliases(ctx, resource)
+}
+
+// DeleteResourceAlias calls DeleteResourceAlias of the current engine.
+func (r *ReloadableEngine) DeleteResourceAlias(ctx context.Context, alias string) error {
+	return r.current.Load().DeleteResourceAlias(ctx, alias)
+}
+
+// GetTenantSettings calls GetTenantSettings of the current engine.
+func (r *ReloadableEngine) GetTenantSettings(ctx context.Context, tenant types.Resource) (types.TenantSettings, error) {
+	return r.current.Load().GetTenantSettings(ctx, tenant)
+}
+
+// UpdateTenantSettings calls UpdateTenantSettings of the current engine.
+func (r *ReloadableEngine) UpdateTenantSettings(ctx context.Context, actor, tenant types.Resource, settings types.TenantSettings) (types.TenantSettings, error) {
+	return r.current.Load().UpdateTenantSettings(ctx, actor, tenant, settings)
+}
+
+// ListRoleV1Resources calls ListRoleV1Resources of the current engine.
+func (r *ReloadableEngine) ListRoleV1Resources(ctx context.Context, after gidx.PrefixedID, limit int) ([]types.Resource, error) {
+	return r.current.Load().ListRoleV1Resources(ctx, after, limit)
+}
+
+// MigrateRolesV1 calls MigrateRolesV1 of the current engine.
+func (r *ReloadableEngine) MigrateRolesV1(ctx context.Context, actor, resource types.Resource, dryRun bool) ([]types.RoleMigration, error) {
+	return r.current.Load().MigrateRolesV1(ctx, actor, resource, dryRun)
+}
+
+// PurgeTenant calls PurgeTenant of the current engine.
+func (r *ReloadableEngine) PurgeTenant(ctx context.Context, tenant types.Resource, batchSize int, dryRun bool) (types.TenantPurgeReport, error) {
+	return r.current.Load().PurgeTenant(ctx, tenant, batchSize, dryRun)
+}
+
+// SyncRoleSubjectTypes calls SyncRoleSubjectTypes of the current engine.
+func (r *ReloadableEngine) SyncRoleSubjectTypes(ctx context.Context) (int, error) {
+	return r.current.Load().SyncRoleSubjectTypes(ctx)
+}
+
+// AllActions calls AllActions of the current engine.
+func (r *ReloadableEngine) AllActions() []string {
+	return r.current.Load().AllActions()
+}
+
+// AllActionGroups calls AllActionGroups of the current engine.
+func (r *ReloadableEngine) AllActionGroups() []types.ActionGroup {
+	return r.current.Load().AllActionGroups()
+}
+
+// PolicyInfo calls PolicyInfo of the current engine.
+func (r *ReloadableEngine) PolicyInfo(ctx context.Context) (types.PolicyInfo, error) {
+	return r.current.Load().PolicyInfo(ctx)
+}
+
+// Stop stops the background work shared by the engines of all policies.
+func (r *ReloadableEngine) Stop() error {
+	return r.current.Load().Stop()
+}
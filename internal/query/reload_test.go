@@ -0,0 +1,57 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/spicedbx/testspicedb"
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestReloadPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	policy := testPolicy()
+
+	client, namespace := testspicedb.NewTestSpiceDB(ctx, t, "infratestreload", policy.Schema())
+
+	store, cleanStore := teststore.NewTestStorage(t)
+
+	t.Cleanup(cleanStore)
+
+	e, err := NewReloadableEngine(namespace, client, store, WithPolicy(iapl.DefaultPolicy()))
+	require.NoError(t, err)
+
+	_, err = e.NewResourceFromID(gidx.MustNewID("chldten"))
+	assert.ErrorIs(t, err, ErrInvalidNamespace, "child type is not defined before the reload")
+
+	// invalid policies are refused and the current policy is kept
+	invalid := iapl.NewPolicy(iapl.PolicyDocument{
+		ResourceTypes: []iapl.ResourceType{
+			{
+				Name:     "child",
+				IDPrefix: "chldten",
+				Relationships: []iapl.Relationship{
+					{Relation: "parent", TargetTypes: []types.TargetType{{Name: "missing"}}},
+				},
+			},
+		},
+	})
+
+	require.Error(t, e.ReloadPolicy(invalid))
+
+	_, err = e.NewResourceFromID(gidx.MustNewID("tnntten"))
+	require.NoError(t, err, "tenant type is still defined after a refused reload")
+
+	require.NoError(t, e.ReloadPolicy(policy))
+
+	child, err := e.NewResourceFromID(gidx.MustNewID("chldten"))
+	require.NoError(t, err)
+	assert.Equal(t, "child", child.Type)
+}
@@ -0,0 +1,52 @@
+package query
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"go.uber.org/zap"
+)
+
+// requestIDBytes is the number of random bytes of generated request IDs.
+const requestIDBytes = 16
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a context whose log lines, permission checks and
+// audit events are correlated with the given request ID.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID of ctx, empty if none was set.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+
+	return requestID
+}
+
+// NewRequestID returns a random request ID for requests which don't carry one.
+func NewRequestID() string {
+	b := make([]byte, requestIDBytes)
+
+	// crypto/rand.Read never returns an error on supported platforms
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// ContextLogger returns the logger with the request ID of ctx, if any, so log
+// lines can be correlated with the request.
+func ContextLogger(ctx context.Context, logger *zap.SugaredLogger) *zap.SugaredLogger {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		return logger.With("request_id", requestID)
+	}
+
+	return logger
+}
+
+// contextLogger returns the engine logger with the request ID of ctx, if any.
+func (e *engine) contextLogger(ctx context.Context) *zap.SugaredLogger {
+	return ContextLogger(ctx, e.logger)
+}
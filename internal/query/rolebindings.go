@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 
 	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"go.infratographer.com/x/gidx"
@@ -23,6 +24,13 @@ func (e *engine) GetRoleBinding(ctx context.Context, roleBinding types.Resource)
 	)
 	defer span.End()
 
+	if err := checkResourceType(roleBinding, e.rbac.RoleBindingResource.Name); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBinding{}, err
+	}
+
 	rb, err := e.store.GetRoleBindingByID(ctx, roleBinding.ID)
 	if err != nil {
 		if errors.Is(err, storage.ErrRoleBindingNotFound) {
@@ -83,16 +91,52 @@ func (e *engine) GetRoleBinding(ctx context.Context, roleBinding types.Resource)
 
 				return types.RoleBinding{}, err
 			}
+
+			rb.Caveat = e.roleBindingCaveat(rel.OptionalCaveat)
 		}
 	}
 
 	return rb, nil
 }
 
+// CreateRoleBinding binds the role to the subjects on the resource, once the
+// actor is checked to be allowed to bind the role, see CheckRoleBindingDelegation.
 func (e *engine) CreateRoleBinding(
 	ctx context.Context,
 	actor, resource, roleResource types.Resource,
 	subjects []types.RoleBindingSubject,
+) (types.RoleBinding, error) {
+	if err := e.CheckRoleBindingDelegation(ctx, actor, resource, roleResource); err != nil {
+		return types.RoleBinding{}, err
+	}
+
+	return e.createRoleBinding(ctx, actor, resource, roleResource, subjects, nil)
+}
+
+// CreateRoleBindingWithCaveat creates a role-binding which only applies when
+// the caveat is satisfied, evaluated with the caveat context and the context
+// of each permission check.
+func (e *engine) CreateRoleBindingWithCaveat(
+	ctx context.Context,
+	actor, resource, roleResource types.Resource,
+	subjects []types.RoleBindingSubject,
+	caveat types.RoleBindingCaveat,
+) (types.RoleBinding, error) {
+	if err := e.CheckRoleBindingDelegation(ctx, actor, resource, roleResource); err != nil {
+		return types.RoleBinding{}, err
+	}
+
+	return e.createRoleBinding(ctx, actor, resource, roleResource, subjects, &caveat)
+}
+
+// createRoleBinding creates a role-binding without checking its delegation,
+// for role-bindings whose delegation was checked beforehand, such as the
+// role-bindings of invitations.
+func (e *engine) createRoleBinding(
+	ctx context.Context,
+	actor, resource, roleResource types.Resource,
+	subjects []types.RoleBindingSubject,
+	caveat *types.RoleBindingCaveat,
 ) (types.RoleBinding, error) {
 	ctx, span := e.tracer.Start(
 		ctx, "engine.CreateRoleBinding",
@@ -111,6 +155,27 @@ func (e *engine) CreateRoleBinding(
 		return types.RoleBinding{}, err
 	}
 
+	if err := checkResourceType(roleResource, e.rbac.RoleResource.Name); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBinding{}, err
+	}
+
+	if err := e.validateRoleBindingSubjects(subjects); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBinding{}, err
+	}
+
+	if err := e.validateRoleBindingCaveat(caveat); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBinding{}, err
+	}
+
 	if err := e.isRoleBindable(ctx, roleResource, resource); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -130,6 +195,13 @@ func (e *engine) CreateRoleBinding(
 		return types.RoleBinding{}, err
 	}
 
+	if err := e.checkTenantSettings(ctx, resource, dbrole.ResourceID, subjects); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBinding{}, err
+	}
+
 	dbCtx, err := e.store.BeginContext(ctx)
 	if err != nil {
 		span.RecordError(err)
@@ -144,7 +216,7 @@ func (e *engine) CreateRoleBinding(
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.RoleBinding{}, err
 	}
@@ -153,20 +225,28 @@ func (e *engine) CreateRoleBinding(
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.RoleBinding{}, err
 	}
 
 	rb.RoleID = dbrole.ID
+	rb.Caveat = caveat
+
+	roleRel, err := e.rolebindingRoleRelationship(dbrole.ID.String(), rb.ID.String(), caveat)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
-	roleRel := e.rolebindingRoleRelationship(dbrole.ID.String(), rb.ID.String())
+		return types.RoleBinding{}, err
+	}
 
 	grantRel, err := e.rolebindingGrantResourceRelationship(resource, rb.ID.String())
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.RoleBinding{}, err
 	}
@@ -190,7 +270,7 @@ func (e *engine) CreateRoleBinding(
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
-			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+			logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 			return types.RoleBinding{}, err
 		}
@@ -207,7 +287,7 @@ func (e *engine) CreateRoleBinding(
 	if err := e.applyUpdates(dbCtx, updates); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.RoleBinding{}, err
 	}
@@ -215,12 +295,21 @@ func (e *engine) CreateRoleBinding(
 	if err := e.store.CommitContext(dbCtx); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-		logRollbackErr(e.logger, e.rollbackUpdates(ctx, updates))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(ctx), e.rollbackUpdates(ctx, updates))
 
 		return types.RoleBinding{}, err
 	}
 
+	e.publishAuditEvent(ctx, auditEvent{
+		eventType:  AuditEventRoleBindingCreated,
+		actor:      actor,
+		subjectID:  rb.ID,
+		resourceID: rb.ResourceID,
+		relatedIDs: rb.SubjectIDs,
+		after:      auditRoleBinding(rb),
+	})
+
 	return rb, nil
 }
 
@@ -233,6 +322,13 @@ func (e *engine) DeleteRoleBinding(ctx context.Context, rb types.Resource) error
 	)
 	defer span.End()
 
+	if err := checkResourceType(rb, e.rbac.RoleBindingResource.Name); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
 	dbCtx, err := e.store.BeginContext(ctx)
 	if err != nil {
 		span.RecordError(err)
@@ -244,7 +340,7 @@ func (e *engine) DeleteRoleBinding(ctx context.Context, rb types.Resource) error
 	if err := e.store.LockRoleBindingForUpdate(dbCtx, rb.ID); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return err
 	}
@@ -273,7 +369,7 @@ func (e *engine) DeleteRoleBinding(ctx context.Context, rb types.Resource) error
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return err
 	}
@@ -290,7 +386,7 @@ func (e *engine) DeleteRoleBinding(ctx context.Context, rb types.Resource) error
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return err
 	}
@@ -309,7 +405,7 @@ func (e *engine) DeleteRoleBinding(ctx context.Context, rb types.Resource) error
 	if err := e.applyUpdates(dbCtx, updates); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return err
 	}
@@ -317,8 +413,8 @@ func (e *engine) DeleteRoleBinding(ctx context.Context, rb types.Resource) error
 	if err := e.store.DeleteRoleBinding(dbCtx, rb.ID); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-		logRollbackErr(e.logger, e.rollbackUpdates(ctx, updates))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(ctx), e.rollbackUpdates(ctx, updates))
 
 		return err
 	}
@@ -326,12 +422,22 @@ func (e *engine) DeleteRoleBinding(ctx context.Context, rb types.Resource) error
 	if err := e.store.CommitContext(dbCtx); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-		logRollbackErr(e.logger, e.rollbackUpdates(ctx, updates))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(ctx), e.rollbackUpdates(ctx, updates))
 
 		return err
 	}
 
+	deleted := auditRoleBindingRelationships(rbFromDB, fromRels)
+
+	e.publishAuditEvent(ctx, auditEvent{
+		eventType:  AuditEventRoleBindingDeleted,
+		subjectID:  deleted.ID,
+		resourceID: deleted.ResourceID,
+		relatedIDs: deleted.SubjectIDs,
+		before:     auditRoleBinding(deleted),
+	})
+
 	return nil
 }
 
@@ -344,7 +450,7 @@ func (e *engine) ListRoleBindings(ctx context.Context, resource types.Resource,
 	)
 	defer span.End()
 
-	e.logger.Debugf("listing role-bindings for resource: %s, optionalRole: %v", resource.ID, optionalRole)
+	e.contextLogger(ctx).Debugf("listing role-bindings for resource: %s, optionalRole: %v", resource.ID, optionalRole)
 
 	// 1. list all grants on the resource
 	listRbFilter := &pb.RelationshipFilter{
@@ -388,7 +494,7 @@ func (e *engine) ListRoleBindings(ctx context.Context, resource types.Resource,
 
 				span.RecordError(err)
 				span.SetStatus(codes.Error, err.Error())
-				e.logger.Warnf(err.Error())
+				e.contextLogger(ctx).Warnf(err.Error())
 			}
 
 			errs = append(errs, err)
@@ -419,6 +525,147 @@ func (e *engine) ListRoleBindings(ctx context.Context, resource types.Resource,
 	return bindings, nil
 }
 
+// StreamRoleBindings calls fn for every role-binding on a resource as its
+// grant is read, so large numbers of role-bindings do not have to be held in
+// memory. Unlike ListRoleBindings, it stops at the first error.
+func (e *engine) StreamRoleBindings(ctx context.Context, resource types.Resource, fn func(types.RoleBinding) error) error {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.StreamRoleBindings",
+		trace.WithAttributes(
+			attribute.Stringer("resource_id", resource.ID),
+		),
+	)
+	defer span.End()
+
+	listRbFilter := &pb.RelationshipFilter{
+		ResourceType:       e.namespaced(resource.Type),
+		OptionalResourceId: resource.ID.String(),
+		OptionalRelation:   iapl.GrantRelationship,
+		OptionalSubjectFilter: &pb.SubjectFilter{
+			SubjectType: e.namespaced(e.rbac.RoleBindingResource.Name),
+		},
+	}
+
+	err := e.streamRelationships(ctx, listRbFilter, func(rel *pb.Relationship) error {
+		rbRes, err := e.NewResourceFromIDString(rel.Subject.Object.ObjectId)
+		if err != nil {
+			return err
+		}
+
+		rb, err := e.GetRoleBinding(ctx, rbRes)
+		if err != nil {
+			if errors.Is(err, ErrRoleBindingNotFound) {
+				err = fmt.Errorf("%w: dangling grant relationship: %s", err, rel.String())
+			}
+
+			return err
+		}
+
+		if len(rb.SubjectIDs) == 0 {
+			return nil
+		}
+
+		return fn(rb)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	return nil
+}
+
+func (e *engine) ListSubjectRoleBindings(ctx context.Context, subject types.Resource) ([]types.RoleBinding, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListSubjectRoleBindings",
+		trace.WithAttributes(
+			attribute.Stringer("subject_id", subject.ID),
+		),
+	)
+	defer span.End()
+
+	subjConf, err := e.rolebindingSubjectType(subject)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	// 1. list all role-bindings the subject is a subject of
+	subjectFilter := &pb.SubjectFilter{
+		SubjectType:       e.namespaced(subjConf.Name),
+		OptionalSubjectId: subject.ID.String(),
+	}
+
+	// for grants like "group#member"
+	if subjConf.SubjectRelation != "" {
+		subjectFilter.OptionalRelation = &pb.SubjectFilter_RelationFilter{
+			Relation: subjConf.SubjectRelation,
+		}
+	}
+
+	listRbFilter := &pb.RelationshipFilter{
+		ResourceType:          e.namespaced(e.rbac.RoleBindingResource.Name),
+		OptionalRelation:      iapl.RolebindingSubjectRelation,
+		OptionalSubjectFilter: subjectFilter,
+	}
+
+	subjRels, err := e.readRelationships(ctx, listRbFilter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	// 2. fetch role-binding details for each role-binding
+	bindings := make([]types.RoleBinding, 0, len(subjRels))
+	seen := make(map[string]struct{}, len(subjRels))
+
+	for _, rel := range subjRels {
+		rbID := rel.Resource.ObjectId
+
+		if _, ok := seen[rbID]; ok {
+			continue
+		}
+
+		seen[rbID] = struct{}{}
+
+		rbRes, err := e.NewResourceFromIDString(rbID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, err
+		}
+
+		rb, err := e.GetRoleBinding(ctx, rbRes)
+		if err != nil {
+			if errors.Is(err, ErrRoleBindingNotFound) {
+				// the role-binding no longer exists in the permissions-api
+				// database, skip its dangling subject relationship.
+				e.contextLogger(ctx).Warnf("%s: dangling subject relationship: %s", err.Error(), rel.String())
+
+				continue
+			}
+
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, err
+		}
+
+		bindings = append(bindings, rb)
+	}
+
+	return bindings, nil
+}
+
+// UpdateRoleBinding replaces the subjects of the role-binding, once the actor
+// is checked to be allowed to bind its role, see CheckRoleBindingDelegation.
 func (e *engine) UpdateRoleBinding(ctx context.Context, actor, rb types.Resource, subjects []types.RoleBindingSubject) (types.RoleBinding, error) {
 	ctx, span := e.tracer.Start(
 		ctx, "engine.UpdateRoleBindings",
@@ -428,6 +675,20 @@ func (e *engine) UpdateRoleBinding(ctx context.Context, actor, rb types.Resource
 	)
 	defer span.End()
 
+	if err := checkResourceType(rb, e.rbac.RoleBindingResource.Name); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBinding{}, err
+	}
+
+	if err := e.validateRoleBindingSubjects(subjects); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.RoleBinding{}, err
+	}
+
 	dbCtx, err := e.store.BeginContext(ctx)
 	if err != nil {
 		span.RecordError(err)
@@ -439,16 +700,31 @@ func (e *engine) UpdateRoleBinding(ctx context.Context, actor, rb types.Resource
 	if err := e.store.LockRoleBindingForUpdate(dbCtx, rb.ID); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.RoleBinding{}, err
 	}
 
 	rolebinding, err := e.GetRoleBinding(dbCtx, rb)
+	if err == nil {
+		err = checkIfMatch(ctx, rolebinding.ID, rolebinding.UpdatedAt)
+	}
+
+	var resource types.Resource
+
+	if err == nil {
+		resource, err = e.NewResourceFromID(rolebinding.ResourceID)
+	}
+
+	if err == nil {
+		roleResource := types.Resource{Type: e.rbac.RoleResource.Name, ID: rolebinding.RoleID}
+		err = e.CheckRoleBindingDelegation(dbCtx, actor, resource, roleResource)
+	}
+
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.RoleBinding{}, err
 	}
@@ -469,11 +745,31 @@ func (e *engine) UpdateRoleBinding(ctx context.Context, actor, rb types.Resource
 
 	add, remove := diff(current, incoming)
 
+	before := auditRoleBinding(rolebinding)
+
 	// return if there are no changes
 	if (len(add) + len(remove)) == 0 {
 		return rolebinding, nil
 	}
 
+	if len(add) > 0 {
+		added := make([]types.RoleBindingSubject, 0, len(add))
+
+		for _, subj := range subjects {
+			if slices.Contains(add, subj.SubjectResource.ID.String()) {
+				added = append(added, subj)
+			}
+		}
+
+		if err := e.checkTenantSettings(dbCtx, resource, "", added); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+			return types.RoleBinding{}, err
+		}
+	}
+
 	// 2. create relationship updates
 	updates := make([]*pb.RelationshipUpdate, 0, len(add)+len(remove))
 
@@ -482,7 +778,7 @@ func (e *engine) UpdateRoleBinding(ctx context.Context, actor, rb types.Resource
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
-			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+			logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 			return types.RoleBinding{}, err
 		}
@@ -495,7 +791,7 @@ func (e *engine) UpdateRoleBinding(ctx context.Context, actor, rb types.Resource
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
-			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+			logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 			return types.RoleBinding{}, err
 		}
@@ -506,7 +802,7 @@ func (e *engine) UpdateRoleBinding(ctx context.Context, actor, rb types.Resource
 	if err := e.applyUpdates(dbCtx, updates); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.RoleBinding{}, err
 	}
@@ -516,15 +812,15 @@ func (e *engine) UpdateRoleBinding(ctx context.Context, actor, rb types.Resource
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-		logRollbackErr(e.logger, e.rollbackUpdates(ctx, updates))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(ctx), e.rollbackUpdates(ctx, updates))
 	}
 
 	if err := e.store.CommitContext(dbCtx); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
-		logRollbackErr(e.logger, e.rollbackUpdates(ctx, updates))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(ctx), e.rollbackUpdates(ctx, updates))
 
 		return types.RoleBinding{}, err
 	}
@@ -533,10 +829,24 @@ func (e *engine) UpdateRoleBinding(ctx context.Context, actor, rb types.Resource
 	rolebinding.UpdatedAt = rbFromDB.UpdatedAt
 	rolebinding.UpdatedBy = rbFromDB.UpdatedBy
 
+	e.publishAuditEvent(ctx, auditEvent{
+		eventType:  AuditEventRoleBindingUpdated,
+		actor:      actor,
+		subjectID:  rolebinding.ID,
+		resourceID: rolebinding.ResourceID,
+		relatedIDs: newSubjectIDs,
+		before:     before,
+		after:      auditRoleBinding(rolebinding),
+	})
+
 	return rolebinding, nil
 }
 
 func (e *engine) GetRoleBindingResource(ctx context.Context, rb types.Resource) (types.Resource, error) {
+	if err := checkResourceType(rb, e.rbac.RoleBindingResource.Name); err != nil {
+		return types.Resource{}, err
+	}
+
 	rbFromDB, err := e.store.GetRoleBindingByID(ctx, rb.ID)
 	if err != nil {
 		if errors.Is(err, storage.ErrRoleBindingNotFound) {
@@ -585,12 +895,9 @@ func (e *engine) isRoleBindable(ctx context.Context, role, res types.Resource) e
 // rolebindingSubjectRelationship is a helper function that creates a
 // relationship between a role-binding and a subject.
 func (e *engine) rolebindingSubjectRelationship(subj types.Resource, rbID string) (*pb.Relationship, error) {
-	subjConf, ok := e.rolebindingSubjectsMap[subj.Type]
-	if !ok {
-		return nil, fmt.Errorf(
-			"%w: subject: %s, subject type: %s", ErrInvalidRoleBindingSubjectType,
-			subj.ID, subj.Type,
-		)
+	subjConf, err := e.rolebindingSubjectType(subj)
+	if err != nil {
+		return nil, err
 	}
 
 	relationshipSubject := &pb.SubjectReference{
@@ -617,9 +924,50 @@ func (e *engine) rolebindingSubjectRelationship(subj types.Resource, rbID string
 	return relationship, nil
 }
 
+// rolebindingSubjectType returns the role-binding subject type of the policy
+// for the type of the subject.
+func (e *engine) rolebindingSubjectType(subj types.Resource) (types.TargetType, error) {
+	subjConf, ok := e.rolebindingSubjectsMap[subj.Type]
+	if !ok {
+		allowed := make([]string, 0, len(e.rbac.RoleBindingSubjects))
+
+		for _, subject := range e.rbac.RoleBindingSubjects {
+			if !slices.Contains(allowed, subject.Name) {
+				allowed = append(allowed, subject.Name)
+			}
+		}
+
+		return types.TargetType{}, &InvalidRoleBindingSubjectError{
+			SubjectID:   subj.ID,
+			SubjectType: subj.Type,
+			Allowed:     allowed,
+		}
+	}
+
+	return subjConf, nil
+}
+
+// validateRoleBindingSubjects checks the subjects are of role-binding subject
+// types of the policy before anything is written, relationships to other
+// subject types would never be matched by SpiceDB.
+func (e *engine) validateRoleBindingSubjects(subjects []types.RoleBindingSubject) error {
+	for _, subj := range subjects {
+		if _, err := e.rolebindingSubjectType(subj.SubjectResource); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // rolebindingRoleRelationship is a helper function that creates a relationship
-// between a role-binding and a role.
-func (e *engine) rolebindingRoleRelationship(roleID, rbID string) *pb.Relationship {
+// between a role-binding and a role, conditioned on the caveat if set.
+func (e *engine) rolebindingRoleRelationship(roleID, rbID string, caveat *types.RoleBindingCaveat) (*pb.Relationship, error) {
+	optionalCaveat, err := e.spicedbCaveat(caveat)
+	if err != nil {
+		return nil, err
+	}
+
 	return &pb.Relationship{
 		Resource: &pb.ObjectReference{
 			ObjectType: e.namespaced(e.rbac.RoleBindingResource.Name),
@@ -632,7 +980,8 @@ func (e *engine) rolebindingRoleRelationship(roleID, rbID string) *pb.Relationsh
 				ObjectId:   roleID,
 			},
 		},
-	}
+		OptionalCaveat: optionalCaveat,
+	}, nil
 }
 
 // rolebindingGrantResourceRelationship is a helper function that creates the
@@ -0,0 +1,454 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// bulkRoleBindingBatchSize is the maximum number of role-bindings written
+	// to the database and SpiceDB at once in bulk requests.
+	bulkRoleBindingBatchSize = 50
+
+	// maxRelationshipUpdatesPerWrite is the maximum number of relationship
+	// updates sent in a single WriteRelationships request, matching SpiceDB's
+	// default limit.
+	maxRelationshipUpdatesPerWrite = 1000
+)
+
+// pendingRoleBinding is a validated role-binding change waiting to be written in a batch.
+type pendingRoleBinding struct {
+	index   int
+	rb      types.RoleBinding
+	updates []*pb.RelationshipUpdate
+}
+
+// roleBindingBatch groups pending role-binding changes written together, a
+// batch is full once it reaches bulkRoleBindingBatchSize role-bindings or
+// adding a role-binding would exceed maxRelationshipUpdatesPerWrite updates.
+type roleBindingBatch struct {
+	items   []pendingRoleBinding
+	updates int
+}
+
+func (b *roleBindingBatch) fits(p pendingRoleBinding) bool {
+	return len(b.items) < bulkRoleBindingBatchSize && b.updates+len(p.updates) <= maxRelationshipUpdatesPerWrite
+}
+
+func (b *roleBindingBatch) add(p pendingRoleBinding) {
+	b.items = append(b.items, p)
+	b.updates += len(p.updates)
+}
+
+func (b *roleBindingBatch) ids() []gidx.PrefixedID {
+	ids := make([]gidx.PrefixedID, len(b.items))
+
+	for i, item := range b.items {
+		ids[i] = item.rb.ID
+	}
+
+	return ids
+}
+
+func (b *roleBindingBatch) relationshipUpdates() []*pb.RelationshipUpdate {
+	updates := make([]*pb.RelationshipUpdate, 0, b.updates)
+
+	for _, item := range b.items {
+		updates = append(updates, item.updates...)
+	}
+
+	return updates
+}
+
+// fail records err as the result of every role-binding in the batch.
+func (b *roleBindingBatch) fail(results []types.RoleBindingResult, err error) {
+	for _, item := range b.items {
+		results[item.index].Err = err
+	}
+}
+
+// CreateRoleBindings creates many role-bindings on a resource. Role-bindings
+// are written to the database and SpiceDB in batches, a failed batch does not
+// affect other batches. A result is returned for every request, in order.
+func (e *engine) CreateRoleBindings(
+	ctx context.Context,
+	actor, resource types.Resource,
+	requests []types.RoleBindingRequest,
+) []types.RoleBindingResult {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.CreateRoleBindings",
+		trace.WithAttributes(
+			attribute.Stringer("resource_id", resource.ID),
+			attribute.Int("rolebindings", len(requests)),
+		),
+	)
+	defer span.End()
+
+	results := make([]types.RoleBindingResult, len(requests))
+
+	var batch roleBindingBatch
+
+	for i, req := range requests {
+		pending, err := e.prepareRoleBindingCreate(ctx, actor, resource, req)
+		if err != nil {
+			span.RecordError(err)
+			results[i].Err = err
+
+			continue
+		}
+
+		pending.index = i
+
+		if !batch.fits(pending) {
+			e.writeRoleBindingCreates(ctx, actor, resource, &batch, results)
+			batch = roleBindingBatch{}
+		}
+
+		batch.add(pending)
+	}
+
+	if len(batch.items) != 0 {
+		e.writeRoleBindingCreates(ctx, actor, resource, &batch, results)
+	}
+
+	return results
+}
+
+// prepareRoleBindingCreate validates a role-binding request, checks the actor
+// may bind its role and builds its relationships.
+func (e *engine) prepareRoleBindingCreate(ctx context.Context, actor, resource types.Resource, req types.RoleBindingRequest) (pendingRoleBinding, error) {
+	if len(req.Subjects) == 0 {
+		return pendingRoleBinding{}, ErrCreateRoleBindingWithNoSubjects
+	}
+
+	if err := e.validateRoleBindingSubjects(req.Subjects); err != nil {
+		return pendingRoleBinding{}, err
+	}
+
+	if err := e.validateRoleBindingCaveat(req.Caveat); err != nil {
+		return pendingRoleBinding{}, err
+	}
+
+	if err := e.isRoleBindable(ctx, req.Role, resource); err != nil {
+		return pendingRoleBinding{}, err
+	}
+
+	if err := e.CheckRoleBindingDelegation(ctx, actor, resource, req.Role); err != nil {
+		return pendingRoleBinding{}, err
+	}
+
+	dbrole, err := e.store.GetRoleByID(ctx, req.Role.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoRoleFound) {
+			err = fmt.Errorf("%w: role %s", ErrRoleNotFound, req.Role.ID)
+		}
+
+		return pendingRoleBinding{}, err
+	}
+
+	if err := e.checkTenantSettings(ctx, resource, dbrole.ResourceID, req.Subjects); err != nil {
+		return pendingRoleBinding{}, err
+	}
+
+	rbResourceType := e.schemaTypeMap[e.rbac.RoleBindingResource.Name]
+
+	rbID, err := gidx.NewID(rbResourceType.IDPrefix)
+	if err != nil {
+		return pendingRoleBinding{}, err
+	}
+
+	roleRel, err := e.rolebindingRoleRelationship(dbrole.ID.String(), rbID.String(), req.Caveat)
+	if err != nil {
+		return pendingRoleBinding{}, err
+	}
+
+	grantRel, err := e.rolebindingGrantResourceRelationship(resource, rbID.String())
+	if err != nil {
+		return pendingRoleBinding{}, err
+	}
+
+	pending := pendingRoleBinding{
+		rb: types.RoleBinding{
+			ID:         rbID,
+			ResourceID: resource.ID,
+			RoleID:     dbrole.ID,
+			SubjectIDs: make([]gidx.PrefixedID, len(req.Subjects)),
+			Caveat:     req.Caveat,
+		},
+		updates: []*pb.RelationshipUpdate{
+			{
+				Operation:    pb.RelationshipUpdate_OPERATION_TOUCH,
+				Relationship: roleRel,
+			},
+			{
+				Operation:    pb.RelationshipUpdate_OPERATION_TOUCH,
+				Relationship: grantRel,
+			},
+		},
+	}
+
+	for i, subj := range req.Subjects {
+		rel, err := e.rolebindingSubjectRelationship(subj.SubjectResource, rbID.String())
+		if err != nil {
+			return pendingRoleBinding{}, err
+		}
+
+		pending.rb.SubjectIDs[i] = subj.SubjectResource.ID
+		pending.updates = append(pending.updates, &pb.RelationshipUpdate{
+			Operation:    pb.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: rel,
+		})
+	}
+
+	if len(pending.updates) > maxRelationshipUpdatesPerWrite {
+		return pendingRoleBinding{}, fmt.Errorf("%w: role binding has too many subjects: %d", ErrInvalidArgument, len(req.Subjects))
+	}
+
+	return pending, nil
+}
+
+// writeRoleBindingCreates writes a batch of role-bindings in a single
+// transaction and WriteRelationships request.
+func (e *engine) writeRoleBindingCreates(
+	ctx context.Context,
+	actor, resource types.Resource,
+	batch *roleBindingBatch,
+	results []types.RoleBindingResult,
+) {
+	ctx, span := e.tracer.Start(ctx, "engine.writeRoleBindingCreates", trace.WithAttributes(attribute.Int("rolebindings", len(batch.items))))
+	defer span.End()
+
+	failed := func(err error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		batch.fail(results, err)
+	}
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		failed(err)
+
+		return
+	}
+
+	dbrbs, err := e.store.CreateRoleBindings(dbCtx, actor.ID, resource.ID, batch.ids()...)
+	if err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		failed(err)
+
+		return
+	}
+
+	updates := batch.relationshipUpdates()
+
+	if err := e.applyUpdates(dbCtx, updates); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		failed(err)
+
+		return
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(ctx), e.rollbackUpdates(ctx, updates))
+		failed(err)
+
+		return
+	}
+
+	created := make(map[gidx.PrefixedID]types.RoleBinding, len(dbrbs))
+
+	for _, rb := range dbrbs {
+		created[rb.ID] = rb
+	}
+
+	for _, item := range batch.items {
+		rb := created[item.rb.ID]
+		rb.RoleID = item.rb.RoleID
+		rb.SubjectIDs = item.rb.SubjectIDs
+
+		results[item.index].RoleBinding = rb
+
+		e.publishAuditEvent(ctx, auditEvent{
+			eventType:  AuditEventRoleBindingCreated,
+			actor:      actor,
+			subjectID:  rb.ID,
+			resourceID: rb.ResourceID,
+			relatedIDs: rb.SubjectIDs,
+			after:      auditRoleBinding(rb),
+		})
+	}
+}
+
+// DeleteRoleBindings deletes many role-bindings from a resource. Role-bindings
+// not belonging to the resource are not deleted. Role-bindings are removed from
+// the database and SpiceDB in batches, a failed batch does not affect other
+// batches. A result is returned for every role-binding, in order.
+func (e *engine) DeleteRoleBindings(ctx context.Context, resource types.Resource, rolebindings []types.Resource) []types.RoleBindingResult {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.DeleteRoleBindings",
+		trace.WithAttributes(
+			attribute.Stringer("resource_id", resource.ID),
+			attribute.Int("rolebindings", len(rolebindings)),
+		),
+	)
+	defer span.End()
+
+	results := make([]types.RoleBindingResult, len(rolebindings))
+
+	for i, rb := range rolebindings {
+		results[i].RoleBinding = types.RoleBinding{ID: rb.ID, ResourceID: resource.ID}
+	}
+
+	owned, err := e.store.ListResourceRoleBindings(ctx, resource.ID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		for i := range results {
+			results[i].Err = err
+		}
+
+		return results
+	}
+
+	remaining := make(map[gidx.PrefixedID]bool, len(owned))
+
+	for _, rb := range owned {
+		remaining[rb.ID] = true
+	}
+
+	var batch roleBindingBatch
+
+	for i, rb := range rolebindings {
+		if !remaining[rb.ID] {
+			err := fmt.Errorf("%w: role-binding %s on resource %s", ErrRoleBindingNotFound, rb.ID, resource.ID)
+
+			span.RecordError(err)
+			results[i].Err = err
+
+			continue
+		}
+
+		// a role-binding listed twice is only deleted once
+		remaining[rb.ID] = false
+
+		pending, err := e.prepareRoleBindingDelete(ctx, resource, rb)
+		if err != nil {
+			span.RecordError(err)
+			results[i].Err = err
+
+			continue
+		}
+
+		pending.index = i
+
+		if !batch.fits(pending) {
+			e.writeRoleBindingDeletes(ctx, &batch, results)
+			batch = roleBindingBatch{}
+		}
+
+		batch.add(pending)
+	}
+
+	if len(batch.items) != 0 {
+		e.writeRoleBindingDeletes(ctx, &batch, results)
+	}
+
+	return results
+}
+
+// prepareRoleBindingDelete builds the relationship deletions of a role-binding.
+func (e *engine) prepareRoleBindingDelete(ctx context.Context, resource, rb types.Resource) (pendingRoleBinding, error) {
+	rels, err := e.readRelationships(ctx, &pb.RelationshipFilter{
+		ResourceType:       e.namespaced(e.rbac.RoleBindingResource.Name),
+		OptionalResourceId: rb.ID.String(),
+	})
+	if err != nil {
+		return pendingRoleBinding{}, err
+	}
+
+	grantRel, err := e.rolebindingGrantResourceRelationship(resource, rb.ID.String())
+	if err != nil {
+		return pendingRoleBinding{}, err
+	}
+
+	pending := pendingRoleBinding{
+		rb:      auditRoleBindingRelationships(types.RoleBinding{ID: rb.ID, ResourceID: resource.ID}, rels),
+		updates: make([]*pb.RelationshipUpdate, 0, len(rels)+1),
+	}
+
+	for _, rel := range append(rels, grantRel) {
+		pending.updates = append(pending.updates, &pb.RelationshipUpdate{
+			Operation:    pb.RelationshipUpdate_OPERATION_DELETE,
+			Relationship: rel,
+		})
+	}
+
+	return pending, nil
+}
+
+// writeRoleBindingDeletes deletes a batch of role-bindings in a single
+// transaction and WriteRelationships request.
+func (e *engine) writeRoleBindingDeletes(ctx context.Context, batch *roleBindingBatch, results []types.RoleBindingResult) {
+	ctx, span := e.tracer.Start(ctx, "engine.writeRoleBindingDeletes", trace.WithAttributes(attribute.Int("rolebindings", len(batch.items))))
+	defer span.End()
+
+	failed := func(err error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		batch.fail(results, err)
+	}
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		failed(err)
+
+		return
+	}
+
+	updates := batch.relationshipUpdates()
+
+	if err := e.applyUpdates(dbCtx, updates); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		failed(err)
+
+		return
+	}
+
+	if err := e.store.DeleteRoleBindings(dbCtx, batch.ids()...); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(ctx), e.rollbackUpdates(ctx, updates))
+		failed(err)
+
+		return
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(ctx), e.rollbackUpdates(ctx, updates))
+		failed(err)
+
+		return
+	}
+
+	for _, item := range batch.items {
+		e.publishAuditEvent(ctx, auditEvent{
+			eventType:  AuditEventRoleBindingDeleted,
+			subjectID:  item.rb.ID,
+			resourceID: item.rb.ResourceID,
+			relatedIDs: item.rb.SubjectIDs,
+			before:     auditRoleBinding(item.rb),
+		})
+	}
+}
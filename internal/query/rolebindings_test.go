@@ -2,11 +2,13 @@ package query
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
 
 	"go.infratographer.com/permissions-api/internal/iapl"
 	"go.infratographer.com/permissions-api/internal/storage"
@@ -60,7 +62,7 @@ func TestCreateRoleBinding(t *testing.T) {
 	require.NoError(t, err)
 
 	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
-		Updates: rbacV2CreateParentRel(root, child, namespace),
+		Updates: rbacV2CreateParentRel(root, child, e.namespace),
 	})
 	require.NoError(t, err)
 
@@ -90,7 +92,32 @@ func TestCreateRoleBinding(t *testing.T) {
 				subjects: []types.RoleBindingSubject{{SubjectResource: subj}},
 			},
 			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
-				assert.ErrorIs(t, res.Err, ErrRoleNotFound)
+				var prefixErr *InvalidIDPrefixError
+
+				require.ErrorAs(t, res.Err, &prefixErr)
+				assert.ErrorIs(t, res.Err, ErrInvalidIDPrefix)
+				assert.Equal(t, []string{"rolev2"}, prefixErr.Expected)
+			},
+		},
+		{
+			Name: "CreateRoleBindingInvalidSubjectType",
+			Input: input{
+				resource: root,
+				role:     roleRes,
+				subjects: []types.RoleBindingSubject{{SubjectResource: subj}, {SubjectResource: orphan}},
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+				var subjectErr *InvalidRoleBindingSubjectError
+
+				require.ErrorAs(t, res.Err, &subjectErr)
+				assert.ErrorIs(t, res.Err, ErrInvalidArgument)
+				assert.Equal(t, orphan.ID, subjectErr.SubjectID)
+				assert.Equal(t, "tenant", subjectErr.SubjectType)
+				assert.Equal(t, []string{"user", "client", "group"}, subjectErr.Allowed)
+
+				rb, err := e.ListRoleBindings(ctx, root, nil)
+				require.NoError(t, err)
+				assert.Empty(t, rb)
 			},
 		},
 		{
@@ -199,7 +226,7 @@ func TestListRoleBindings(t *testing.T) {
 	require.NoError(t, err)
 
 	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
-		Updates: rbacV2CreateParentRel(root, child, namespace),
+		Updates: rbacV2CreateParentRel(root, child, e.namespace),
 	})
 	require.NoError(t, err)
 
@@ -269,6 +296,90 @@ func TestListRoleBindings(t *testing.T) {
 	testingx.RunTests(ctx, t, tc, testFn)
 }
 
+func TestListSubjectRoleBindings(t *testing.T) {
+	namespace := "testroles"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	child, err := e.NewResourceFromIDString("tnntten-child")
+	require.NoError(t, err)
+	subj, err := e.NewResourceFromIDString("idntusr-subj")
+	require.NoError(t, err)
+	other, err := e.NewResourceFromIDString("idntusr-other")
+	require.NoError(t, err)
+	group, err := e.NewResourceFromIDString("idntgrp-group")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
+		Updates: rbacV2CreateParentRel(root, child, e.namespace),
+	})
+	require.NoError(t, err)
+
+	viewer, err := e.CreateRoleV2(ctx, actor, root, "lb_viewer", []string{"loadbalancer_list", "loadbalancer_get"})
+	require.NoError(t, err)
+
+	viewerRes, err := e.NewResourceFromID(viewer.ID)
+	require.NoError(t, err)
+
+	rootRB, err := e.CreateRoleBinding(ctx, actor, root, viewerRes, []types.RoleBindingSubject{{SubjectResource: subj}})
+	require.NoError(t, err)
+
+	childRB, err := e.CreateRoleBinding(ctx, actor, child, viewerRes, []types.RoleBindingSubject{{SubjectResource: subj}, {SubjectResource: group}})
+	require.NoError(t, err)
+
+	tc := []testingx.TestCase[types.Resource, []types.RoleBinding]{
+		{
+			Name:  "ListUser",
+			Input: subj,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBinding]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 2)
+
+				resources := []gidx.PrefixedID{res.Success[0].ResourceID, res.Success[1].ResourceID}
+				assert.ElementsMatch(t, []gidx.PrefixedID{root.ID, child.ID}, resources)
+
+				ids := []gidx.PrefixedID{res.Success[0].ID, res.Success[1].ID}
+				assert.ElementsMatch(t, []gidx.PrefixedID{rootRB.ID, childRB.ID}, ids)
+			},
+		},
+		{
+			Name:  "ListGroup",
+			Input: group,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBinding]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 1)
+				assert.Equal(t, childRB.ID, res.Success[0].ID)
+			},
+		},
+		{
+			Name:  "ListNoRoleBindings",
+			Input: other,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBinding]) {
+				require.NoError(t, res.Err)
+				assert.Len(t, res.Success, 0)
+			},
+		},
+		{
+			Name:  "InvalidSubjectType",
+			Input: root,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBinding]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidRoleBindingSubjectType)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in types.Resource) testingx.TestResult[[]types.RoleBinding] {
+		rbs, err := e.ListSubjectRoleBindings(ctx, in)
+		return testingx.TestResult[[]types.RoleBinding]{Success: rbs, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
+
 func TestGetRoleBinding(t *testing.T) {
 	namespace := "testroles"
 	ctx := context.Background()
@@ -316,6 +427,15 @@ func TestGetRoleBinding(t *testing.T) {
 				assert.ErrorContains(t, res.Err, ErrRoleBindingNotFound.Error())
 			},
 		},
+		{
+			Name:  "GetRoleBindingRoleID",
+			Input: viewerRes,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidIDPrefix)
+				assert.ErrorIs(t, res.Err, ErrInvalidType)
+				assert.ErrorContains(t, res.Err, "expected rolebinding")
+			},
+		},
 	}
 
 	testFn := func(ctx context.Context, in types.Resource) testingx.TestResult[types.RoleBinding] {
@@ -473,6 +593,99 @@ func TestDeleteRoleBinding(t *testing.T) {
 	testingx.RunTests(ctx, t, tc, testFn)
 }
 
+func TestBulkRoleBindings(t *testing.T) {
+	namespace := "testroles"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	other, err := e.NewResourceFromIDString("tnntten-other")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	viewer, err := e.CreateRoleV2(ctx, actor, root, "lb_viewer", []string{"loadbalancer_list", "loadbalancer_get"})
+	require.NoError(t, err)
+	viewerRes, err := e.NewResourceFromID(viewer.ID)
+	require.NoError(t, err)
+
+	notfoundRole, err := e.NewResourceFromIDString("permrv2-notfound")
+	require.NoError(t, err)
+
+	otherViewer, err := e.CreateRoleV2(ctx, actor, other, "lb_viewer", []string{"loadbalancer_list", "loadbalancer_get"})
+	require.NoError(t, err)
+	otherViewerRes, err := e.NewResourceFromID(otherViewer.ID)
+	require.NoError(t, err)
+
+	otherRB, err := e.CreateRoleBinding(ctx, actor, other, otherViewerRes, []types.RoleBindingSubject{{SubjectResource: actor}})
+	require.NoError(t, err)
+	otherRBRes, err := e.NewResourceFromID(otherRB.ID)
+	require.NoError(t, err)
+
+	// more role-bindings than fit in a single batch
+	requests := make([]types.RoleBindingRequest, bulkRoleBindingBatchSize+10)
+
+	for i := range requests {
+		subj, err := e.NewResourceFromIDString(fmt.Sprintf("idntusr-user%d", i))
+		require.NoError(t, err)
+
+		requests[i] = types.RoleBindingRequest{
+			Role:     viewerRes,
+			Subjects: []types.RoleBindingSubject{{SubjectResource: subj}},
+		}
+	}
+
+	requests[3].Role = notfoundRole
+	requests[5].Subjects = nil
+
+	results := e.CreateRoleBindings(ctx, actor, root, requests)
+	require.Len(t, results, len(requests))
+
+	created := []types.Resource{}
+
+	for i, res := range results {
+		switch i {
+		case 3:
+			assert.ErrorIs(t, res.Err, ErrRoleNotFound)
+		case 5:
+			assert.ErrorIs(t, res.Err, ErrCreateRoleBindingWithNoSubjects)
+		default:
+			require.NoError(t, res.Err)
+			assert.Equal(t, viewer.ID, res.RoleBinding.RoleID)
+			assert.Equal(t, root.ID, res.RoleBinding.ResourceID)
+			assert.Equal(t, actor.ID, res.RoleBinding.CreatedBy)
+			assert.Equal(t, requests[i].Subjects[0].SubjectResource.ID, res.RoleBinding.SubjectIDs[0])
+
+			rbRes, err := e.NewResourceFromID(res.RoleBinding.ID)
+			require.NoError(t, err)
+
+			created = append(created, rbRes)
+		}
+	}
+
+	rbs, err := e.ListRoleBindings(ctx, root, nil)
+	require.NoError(t, err)
+	assert.Len(t, rbs, len(requests)-2)
+
+	results = e.DeleteRoleBindings(ctx, root, append(created, otherRBRes))
+	require.Len(t, results, len(created)+1)
+
+	for _, res := range results[:len(created)] {
+		assert.NoError(t, res.Err)
+	}
+
+	assert.ErrorIs(t, results[len(created)].Err, ErrRoleBindingNotFound)
+
+	rbs, err = e.ListRoleBindings(ctx, root, nil)
+	require.NoError(t, err)
+	assert.Empty(t, rbs)
+
+	rbs, err = e.ListRoleBindings(ctx, other, nil)
+	require.NoError(t, err)
+	assert.Len(t, rbs, 1)
+}
+
 func TestPermissions(t *testing.T) {
 	namespace := "testroles"
 	ctx := context.Background()
@@ -487,7 +700,7 @@ func TestPermissions(t *testing.T) {
 
 	// create child tenant relationships
 	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
-		Updates: rbacV2CreateParentRel(root, child, namespace),
+		Updates: rbacV2CreateParentRel(root, child, e.namespace),
 	})
 	require.NoError(t, err)
 
@@ -516,7 +729,7 @@ func TestPermissions(t *testing.T) {
 	require.NoError(t, err)
 
 	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
-		Updates: rbacV2CreateParentRel(root, group1, namespace),
+		Updates: rbacV2CreateParentRel(root, group1, e.namespace),
 	})
 	require.NoError(t, err)
 
@@ -539,9 +752,9 @@ func TestPermissions(t *testing.T) {
 			SetupFn: func(ctx context.Context, t *testing.T) context.Context {
 				err := e.checkPermission(ctx, &pb.CheckPermissionRequest{
 					Consistency: fullconsistency,
-					Resource:    resourceToSpiceDBRef(namespace, lb1),
+					Resource:    resourceToSpiceDBRef(e.namespace, lb1),
 					Permission:  "loadbalancer_get",
-					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user1)},
+					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(e.namespace, user1)},
 				})
 				require.Error(t, err)
 
@@ -553,9 +766,9 @@ func TestPermissions(t *testing.T) {
 			CheckFn: func(ctx context.Context, t *testing.T, _ testingx.TestResult[any]) {
 				err := e.checkPermission(ctx, &pb.CheckPermissionRequest{
 					Consistency: fullconsistency,
-					Resource:    resourceToSpiceDBRef(namespace, lb1),
+					Resource:    resourceToSpiceDBRef(e.namespace, lb1),
 					Permission:  "loadbalancer_get",
-					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user1)},
+					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(e.namespace, user1)},
 				})
 				assert.NoError(t, err)
 			},
@@ -573,9 +786,9 @@ func TestPermissions(t *testing.T) {
 			SetupFn: func(ctx context.Context, t *testing.T) context.Context {
 				err := e.checkPermission(ctx, &pb.CheckPermissionRequest{
 					Consistency: fullconsistency,
-					Resource:    resourceToSpiceDBRef(namespace, lb1),
+					Resource:    resourceToSpiceDBRef(e.namespace, lb1),
 					Permission:  "loadbalancer_get",
-					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user1)},
+					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(e.namespace, user1)},
 				})
 				require.Error(t, err)
 
@@ -587,9 +800,9 @@ func TestPermissions(t *testing.T) {
 			CheckFn: func(ctx context.Context, t *testing.T, _ testingx.TestResult[any]) {
 				err := e.checkPermission(ctx, &pb.CheckPermissionRequest{
 					Consistency: fullconsistency,
-					Resource:    resourceToSpiceDBRef(namespace, lb1),
+					Resource:    resourceToSpiceDBRef(e.namespace, lb1),
 					Permission:  "loadbalancer_get",
-					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user1)},
+					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(e.namespace, user1)},
 				})
 				assert.NoError(t, err)
 			},
@@ -607,9 +820,9 @@ func TestPermissions(t *testing.T) {
 			SetupFn: func(ctx context.Context, t *testing.T) context.Context {
 				err := e.checkPermission(ctx, &pb.CheckPermissionRequest{
 					Consistency: fullconsistency,
-					Resource:    resourceToSpiceDBRef(namespace, lb1),
+					Resource:    resourceToSpiceDBRef(e.namespace, lb1),
 					Permission:  "loadbalancer_get",
-					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user1)},
+					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(e.namespace, user1)},
 				})
 				require.Error(t, err)
 
@@ -621,9 +834,9 @@ func TestPermissions(t *testing.T) {
 			CheckFn: func(ctx context.Context, t *testing.T, _ testingx.TestResult[any]) {
 				err := e.checkPermission(ctx, &pb.CheckPermissionRequest{
 					Consistency: fullconsistency,
-					Resource:    resourceToSpiceDBRef(namespace, lb1),
+					Resource:    resourceToSpiceDBRef(e.namespace, lb1),
 					Permission:  "loadbalancer_get",
-					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user1)},
+					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(e.namespace, user1)},
 				})
 				assert.NoError(t, err)
 			},
@@ -641,9 +854,9 @@ func TestPermissions(t *testing.T) {
 			SetupFn: func(ctx context.Context, t *testing.T) context.Context {
 				err := e.checkPermission(ctx, &pb.CheckPermissionRequest{
 					Consistency: fullconsistency,
-					Resource:    resourceToSpiceDBRef(namespace, lb1),
+					Resource:    resourceToSpiceDBRef(e.namespace, lb1),
 					Permission:  "loadbalancer_get",
-					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user2)},
+					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(e.namespace, user2)},
 				})
 				require.Error(t, err)
 
@@ -655,9 +868,9 @@ func TestPermissions(t *testing.T) {
 			CheckFn: func(ctx context.Context, t *testing.T, _ testingx.TestResult[any]) {
 				err := e.checkPermission(ctx, &pb.CheckPermissionRequest{
 					Consistency: fullconsistency,
-					Resource:    resourceToSpiceDBRef(namespace, lb1),
+					Resource:    resourceToSpiceDBRef(e.namespace, lb1),
 					Permission:  "loadbalancer_get",
-					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user2)},
+					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(e.namespace, user2)},
 				})
 				assert.NoError(t, err)
 			},
@@ -669,9 +882,9 @@ func TestPermissions(t *testing.T) {
 			SetupFn: func(ctx context.Context, t *testing.T) context.Context {
 				err := e.checkPermission(ctx, &pb.CheckPermissionRequest{
 					Consistency: fullconsistency,
-					Resource:    resourceToSpiceDBRef(namespace, lb1),
+					Resource:    resourceToSpiceDBRef(e.namespace, lb1),
 					Permission:  "loadbalancer_get",
-					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user2)},
+					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(e.namespace, user2)},
 				})
 				require.NoError(t, err)
 
@@ -687,9 +900,9 @@ func TestPermissions(t *testing.T) {
 			CheckFn: func(ctx context.Context, t *testing.T, _ testingx.TestResult[any]) {
 				err := e.checkPermission(ctx, &pb.CheckPermissionRequest{
 					Consistency: fullconsistency,
-					Resource:    resourceToSpiceDBRef(namespace, lb1),
+					Resource:    resourceToSpiceDBRef(e.namespace, lb1),
 					Permission:  "loadbalancer_get",
-					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user2)},
+					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(e.namespace, user2)},
 				})
 				assert.Error(t, err)
 			},
@@ -707,9 +920,9 @@ func TestPermissions(t *testing.T) {
 			SetupFn: func(ctx context.Context, t *testing.T) context.Context {
 				err := e.checkPermission(ctx, &pb.CheckPermissionRequest{
 					Consistency: fullconsistency,
-					Resource:    resourceToSpiceDBRef(namespace, lb1),
+					Resource:    resourceToSpiceDBRef(e.namespace, lb1),
 					Permission:  "loadbalancer_get",
-					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user2)},
+					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(e.namespace, user2)},
 				})
 				require.NoError(t, err)
 
@@ -721,9 +934,9 @@ func TestPermissions(t *testing.T) {
 			CheckFn: func(ctx context.Context, t *testing.T, _ testingx.TestResult[any]) {
 				err := e.checkPermission(ctx, &pb.CheckPermissionRequest{
 					Consistency: fullconsistency,
-					Resource:    resourceToSpiceDBRef(namespace, lb1),
+					Resource:    resourceToSpiceDBRef(e.namespace, lb1),
 					Permission:  "loadbalancer_get",
-					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user2)},
+					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(e.namespace, user2)},
 				})
 				assert.Error(t, err)
 			},
@@ -737,9 +950,9 @@ func TestPermissions(t *testing.T) {
 			SetupFn: func(ctx context.Context, t *testing.T) context.Context {
 				err := e.checkPermission(ctx, &pb.CheckPermissionRequest{
 					Consistency: fullconsistency,
-					Resource:    resourceToSpiceDBRef(namespace, lb1),
+					Resource:    resourceToSpiceDBRef(e.namespace, lb1),
 					Permission:  "loadbalancer_get",
-					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user2)},
+					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(e.namespace, user2)},
 				})
 				require.NoError(t, err)
 
@@ -753,9 +966,9 @@ func TestPermissions(t *testing.T) {
 			CheckFn: func(ctx context.Context, t *testing.T, _ testingx.TestResult[any]) {
 				err := e.checkPermission(ctx, &pb.CheckPermissionRequest{
 					Consistency: fullconsistency,
-					Resource:    resourceToSpiceDBRef(namespace, lb1),
+					Resource:    resourceToSpiceDBRef(e.namespace, lb1),
 					Permission:  "loadbalancer_get",
-					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(namespace, user2)},
+					Subject:     &pb.SubjectReference{Object: resourceToSpiceDBRef(e.namespace, user2)},
 				})
 				assert.Error(t, err)
 			},
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 
 	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"go.infratographer.com/x/gidx"
@@ -13,20 +14,103 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/storage"
 	"go.infratographer.com/permissions-api/internal/types"
 )
 
 // V2 Role and Role Bindings
 
+// AllActionGroups lists the action groups defined by the policy
+func (e *engine) AllActionGroups() []types.ActionGroup {
+	groups := make([]types.ActionGroup, len(e.actionGroupNames))
+
+	for i, name := range e.actionGroupNames {
+		groups[i] = e.actionGroups[name]
+	}
+
+	return groups
+}
+
+// expandActionGroups replaces action groups in the given actions with the
+// actions of the group, actions included more than once are deduplicated.
+func (e *engine) expandActionGroups(actions []string) []string {
+	if len(e.actionGroups) == 0 || actions == nil {
+		return actions
+	}
+
+	expanded := make([]string, 0, len(actions))
+	seen := make(map[string]struct{}, len(actions))
+
+	add := func(action string) {
+		if _, ok := seen[action]; ok {
+			return
+		}
+
+		seen[action] = struct{}{}
+
+		expanded = append(expanded, action)
+	}
+
+	for _, action := range actions {
+		group, ok := e.actionGroups[action]
+		if !ok {
+			add(action)
+
+			continue
+		}
+
+		for _, groupAction := range group.Actions {
+			add(groupAction)
+		}
+	}
+
+	return expanded
+}
+
 func (e *engine) namespaced(name string) string {
 	return e.namespace + "/" + name
 }
 
+// CreateRoleV2 creates a role owned by the owner, once the actor is checked
+// to be allowed to grant the actions, see CheckRoleDelegation.
 func (e *engine) CreateRoleV2(ctx context.Context, actor, owner types.Resource, roleName string, actions []string) (types.Role, error) {
+	if err := e.CheckRoleDelegation(ctx, actor, owner, actions); err != nil {
+		return types.Role{}, err
+	}
+
+	return e.createRoleV2(ctx, actor, owner, roleName, actions)
+}
+
+// createRoleV2 creates a role without checking its delegation, for roles the
+// permissions-api creates itself, such as role templates.
+func (e *engine) createRoleV2(ctx context.Context, actor, owner types.Resource, roleName string, actions []string) (types.Role, error) {
 	ctx, span := e.tracer.Start(ctx, "engine.CreateRoleV2")
 
 	defer span.End()
 
+	if err := checkResourceType(owner, e.rbac.RoleOwners...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Role{}, err
+	}
+
+	actions = e.expandActionGroups(actions)
+
+	if err := e.validateRoleV2Actions(actions); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Role{}, err
+	}
+
+	if err := e.checkRoleNameAvailable(ctx, owner.ID, "", roleName); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Role{}, err
+	}
+
 	role, err := newRoleWithPrefix(e.schemaTypeMap[e.rbac.RoleResource.Name].IDPrefix, roleName, actions)
 	if err != nil {
 		return types.Role{}, err
@@ -53,7 +137,7 @@ func (e *engine) CreateRoleV2(ctx context.Context, actor, owner types.Resource,
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.Role{}, err
 	}
@@ -64,7 +148,7 @@ func (e *engine) CreateRoleV2(ctx context.Context, actor, owner types.Resource,
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.Role{}, err
 	}
@@ -73,7 +157,7 @@ func (e *engine) CreateRoleV2(ctx context.Context, actor, owner types.Resource,
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		// No rollback of spicedb relations are done here.
 		// This does result in dangling unused entries in spicedb,
@@ -89,6 +173,14 @@ func (e *engine) CreateRoleV2(ctx context.Context, actor, owner types.Resource,
 	role.CreatedAt = dbRole.CreatedAt
 	role.UpdatedAt = dbRole.UpdatedAt
 
+	e.publishAuditEvent(ctx, auditEvent{
+		eventType:  AuditEventRoleCreated,
+		actor:      actor,
+		subjectID:  role.ID,
+		resourceID: role.ResourceID,
+		after:      auditRole(role),
+	})
+
 	return role, nil
 }
 
@@ -105,8 +197,7 @@ func (e *engine) ListRolesV2(ctx context.Context, owner types.Resource) ([]types
 	)
 	defer span.End()
 
-	if _, ok := e.rbac.RoleOwnersSet()[owner.Type]; !ok {
-		err := fmt.Errorf("%w: %s is not a valid role owner", ErrInvalidType, owner.Type)
+	if err := checkResourceType(owner, e.rbac.RoleOwners...); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
@@ -166,8 +257,9 @@ func (e *engine) ListRolesV2(ctx context.Context, owner types.Resource) ([]types
 
 	for i, r := range storageRoles {
 		roles[i] = types.Role{
-			Name: r.Name,
-			ID:   r.ID,
+			Name:       r.Name,
+			ID:         r.ID,
+			LastUsedAt: r.LastUsedAt,
 		}
 	}
 
@@ -183,8 +275,7 @@ func (e *engine) GetRoleV2(ctx context.Context, role types.Resource) (types.Role
 	defer span.End()
 
 	// check if the role is a valid v2 role
-	if role.Type != e.rbac.RoleResource.Name {
-		err := fmt.Errorf("%w: %s is not a valid v2 Role", ErrInvalidType, role.Type)
+	if err := checkResourceType(role, e.rbac.RoleResource.Name); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
@@ -220,15 +311,43 @@ func (e *engine) GetRoleV2(ctx context.Context, role types.Resource) (types.Role
 		UpdatedBy:  dbrole.UpdatedBy,
 		CreatedAt:  dbrole.CreatedAt,
 		UpdatedAt:  dbrole.UpdatedAt,
+		LastUsedAt: dbrole.LastUsedAt,
 	}
 
 	return resp, nil
 }
 
+// UpdateRoleV2 updates the name and actions of a role, once the actor is
+// checked to be allowed to grant the actions, see CheckRoleDelegation.
 func (e *engine) UpdateRoleV2(ctx context.Context, actor, roleResource types.Resource, newName string, newActions []string) (types.Role, error) {
+	if err := e.CheckRoleDelegation(ctx, actor, roleResource, newActions); err != nil {
+		return types.Role{}, err
+	}
+
+	return e.updateRoleV2(ctx, actor, roleResource, newName, newActions)
+}
+
+// updateRoleV2 updates a role without checking its delegation.
+func (e *engine) updateRoleV2(ctx context.Context, actor, roleResource types.Resource, newName string, newActions []string) (types.Role, error) {
 	ctx, span := e.tracer.Start(ctx, "engine.UpdateRoleV2")
 	defer span.End()
 
+	if err := checkResourceType(roleResource, e.rbac.RoleResource.Name); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Role{}, err
+	}
+
+	newActions = e.expandActionGroups(newActions)
+
+	if err := e.validateRoleV2Actions(newActions); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.Role{}, err
+	}
+
 	dbCtx, err := e.store.BeginContext(ctx)
 	if err != nil {
 		return types.Role{}, err
@@ -241,17 +360,21 @@ func (e *engine) UpdateRoleV2(ctx context.Context, actor, roleResource types.Res
 		span.RecordError(sErr)
 		span.SetStatus(codes.Error, sErr.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.Role{}, err
 	}
 
 	role, err := e.GetRoleV2(dbCtx, roleResource)
+	if err == nil {
+		err = checkIfMatch(ctx, role.ID, role.UpdatedAt)
+	}
+
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.Role{}, err
 	}
@@ -260,6 +383,17 @@ func (e *engine) UpdateRoleV2(ctx context.Context, actor, roleResource types.Res
 		newName = role.Name
 	}
 
+	if err := e.checkRoleNameAvailable(dbCtx, role.ResourceID, role.ID, newName); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return types.Role{}, err
+	}
+
+	before := auditRole(role)
+
 	addActions, rmActions := diff(role.Actions, newActions)
 
 	// If no changes, return existing role
@@ -280,7 +414,7 @@ func (e *engine) UpdateRoleV2(ctx context.Context, actor, roleResource types.Res
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.Role{}, err
 	}
@@ -318,7 +452,7 @@ func (e *engine) UpdateRoleV2(ctx context.Context, actor, roleResource types.Res
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return types.Role{}, err
 	}
@@ -327,7 +461,7 @@ func (e *engine) UpdateRoleV2(ctx context.Context, actor, roleResource types.Res
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		// At this point, SpiceDB changes have already been applied.
 		// Attempting to rollback could result in failures that could result in the same situation.
@@ -345,6 +479,15 @@ func (e *engine) UpdateRoleV2(ctx context.Context, actor, roleResource types.Res
 	role.UpdatedAt = dbRole.UpdatedAt
 	role.Actions = newActions
 
+	e.publishAuditEvent(ctx, auditEvent{
+		eventType:  AuditEventRoleUpdated,
+		actor:      actor,
+		subjectID:  role.ID,
+		resourceID: role.ResourceID,
+		before:     before,
+		after:      auditRole(role),
+	})
+
 	return role, nil
 }
 
@@ -352,6 +495,13 @@ func (e *engine) DeleteRoleV2(ctx context.Context, roleResource types.Resource)
 	ctx, span := e.tracer.Start(ctx, "engine.DeleteRoleV2")
 	defer span.End()
 
+	if err := checkResourceType(roleResource, e.rbac.RoleResource.Name); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
 	dbCtx, err := e.store.BeginContext(ctx)
 	if err != nil {
 		span.RecordError(err)
@@ -367,22 +517,13 @@ func (e *engine) DeleteRoleV2(ctx context.Context, roleResource types.Resource)
 		span.RecordError(sErr)
 		span.SetStatus(codes.Error, sErr.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return err
 	}
 
 	// find all the bindings for the role
-	findBindingsFilter := &pb.RelationshipFilter{
-		ResourceType:     e.namespaced(e.rbac.RoleBindingResource.Name),
-		OptionalRelation: iapl.RolebindingRoleRelation,
-		OptionalSubjectFilter: &pb.SubjectFilter{
-			SubjectType:       e.namespaced(e.rbac.RoleResource.Name),
-			OptionalSubjectId: roleResource.ID.String(),
-		},
-	}
-
-	bindings, err := e.readRelationships(dbCtx, findBindingsFilter)
+	bindings, err := e.readRelationships(dbCtx, e.roleV2BindingsFilter(roleResource))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -415,12 +556,30 @@ func (e *engine) DeleteRoleV2(ctx context.Context, roleResource types.Resource)
 		return err
 	}
 
+	deleted := types.Role{
+		ID:         dbRole.ID,
+		Name:       dbRole.Name,
+		ResourceID: dbRole.ResourceID,
+	}
+
+	// the actions of the role are only needed for the audit event
+	if e.audit != nil {
+		if deleted.Actions, err = e.listRoleV2Actions(dbCtx, deleted); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+			return err
+		}
+	}
+
 	// 1. delete role from permission-api DB
 	if _, err = e.store.DeleteRole(dbCtx, roleResource.ID); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		return err
 	}
@@ -462,7 +621,7 @@ func (e *engine) DeleteRoleV2(ctx context.Context, roleResource types.Resource)
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
 
-			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+			logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 			return err
 		}
@@ -472,7 +631,7 @@ func (e *engine) DeleteRoleV2(ctx context.Context, roleResource types.Resource)
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 		// At this point, spicedb changes have already been applied.
 		// Attempting to rollback could result in failures that could result in the same situation.
@@ -488,10 +647,146 @@ func (e *engine) DeleteRoleV2(ctx context.Context, roleResource types.Resource)
 		return err
 	}
 
+	e.publishAuditEvent(ctx, auditEvent{
+		eventType:  AuditEventRoleDeleted,
+		subjectID:  deleted.ID,
+		resourceID: deleted.ResourceID,
+		before:     auditRole(deleted),
+	})
+
 	return nil
 }
 
 // roleV2OwnerRelationship creates a relationships between a V2 role and its owner.
+// ForceDeleteRoleV2 deletes a V2 role together with all role-bindings of the
+// role, on any resource. Role-bindings are deleted in batches before the role,
+// the number of deleted role-bindings is returned even when deleting some of
+// them or the role fails.
+func (e *engine) ForceDeleteRoleV2(ctx context.Context, roleResource types.Resource) (int, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ForceDeleteRoleV2",
+		trace.WithAttributes(
+			attribute.Stringer("role_id", roleResource.ID),
+		),
+	)
+	defer span.End()
+
+	if err := checkResourceType(roleResource, e.rbac.RoleResource.Name); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return 0, err
+	}
+
+	bindings, err := e.readRelationships(ctx, e.roleV2BindingsFilter(roleResource))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return 0, err
+	}
+
+	results := make([]types.RoleBindingResult, len(bindings))
+
+	var batch roleBindingBatch
+
+	for i, rel := range bindings {
+		pending, err := e.prepareRoleBindingDeleteByID(ctx, rel.Resource.ObjectId)
+
+		switch {
+		case errors.Is(err, storage.ErrRoleBindingNotFound):
+			// deleted concurrently
+			continue
+		case err != nil:
+			span.RecordError(err)
+			results[i].Err = err
+
+			continue
+		}
+
+		pending.index = i
+		results[i].RoleBinding = pending.rb
+
+		if !batch.fits(pending) {
+			e.writeRoleBindingDeletes(ctx, &batch, results)
+			batch = roleBindingBatch{}
+		}
+
+		batch.add(pending)
+	}
+
+	if len(batch.items) != 0 {
+		e.writeRoleBindingDeletes(ctx, &batch, results)
+	}
+
+	var (
+		deleted int
+		errs    []error
+	)
+
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			errs = append(errs, result.Err)
+		case result.RoleBinding.ID != "":
+			deleted++
+		}
+	}
+
+	span.SetAttributes(attribute.Int("rolebindings.deleted", deleted))
+
+	if len(errs) != 0 {
+		err := fmt.Errorf("failed to delete %d of %d role-bindings of role %s: %w", len(errs), len(bindings), roleResource.ID, errors.Join(errs...))
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return deleted, err
+	}
+
+	if err := e.DeleteRoleV2(ctx, roleResource); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return deleted, err
+	}
+
+	return deleted, nil
+}
+
+// prepareRoleBindingDeleteByID looks up the resource of a role-binding and
+// builds its relationship deletions.
+func (e *engine) prepareRoleBindingDeleteByID(ctx context.Context, id string) (pendingRoleBinding, error) {
+	rb, err := e.NewResourceFromIDString(id)
+	if err != nil {
+		return pendingRoleBinding{}, err
+	}
+
+	rbFromDB, err := e.store.GetRoleBindingByID(ctx, rb.ID)
+	if err != nil {
+		return pendingRoleBinding{}, err
+	}
+
+	resource, err := e.NewResourceFromID(rbFromDB.ResourceID)
+	if err != nil {
+		return pendingRoleBinding{}, err
+	}
+
+	return e.prepareRoleBindingDelete(ctx, resource, rb)
+}
+
+// roleV2BindingsFilter matches the role relationships of all role-bindings of a role.
+func (e *engine) roleV2BindingsFilter(roleResource types.Resource) *pb.RelationshipFilter {
+	return &pb.RelationshipFilter{
+		ResourceType:     e.namespaced(e.rbac.RoleBindingResource.Name),
+		OptionalRelation: iapl.RolebindingRoleRelation,
+		OptionalSubjectFilter: &pb.SubjectFilter{
+			SubjectType:       e.namespaced(e.rbac.RoleResource.Name),
+			OptionalSubjectId: roleResource.ID.String(),
+		},
+	}
+}
+
 func (e *engine) roleV2OwnerRelationship(role types.Role, owner types.Resource) ([]*pb.RelationshipUpdate, error) {
 	roleResource, err := e.NewResourceFromID(role.ID)
 	if err != nil {
@@ -600,17 +895,12 @@ func (e *engine) listRoleV2Actions(ctx context.Context, role types.Role) ([]stri
 	//   infratographer/rolev2:lb_viewer#loadbalancer_get_rel@infratographer/user:*
 	//   infratographer/rolev2:lb_viewer#loadbalancer_get_rel@infratographer/client:*
 	// here we only need one of them since the action is the only thing we care
-	// about
-	permRelationshipSubjType := e.namespaced(e.rbac.RoleSubjectTypes[0])
-
+	// about. Any subject type is accepted, roles created before a subject type
+	// was added to the policy are not granted to it until they are synced.
 	rid := role.ID.String()
 	filter := &pb.RelationshipFilter{
 		ResourceType:       e.namespaced(e.rbac.RoleResource.Name),
 		OptionalResourceId: rid,
-		OptionalSubjectFilter: &pb.SubjectFilter{
-			SubjectType:       permRelationshipSubjType,
-			OptionalSubjectId: "*",
-		},
 	}
 
 	relationships, err := e.readRelationships(ctx, filter)
@@ -618,15 +908,140 @@ func (e *engine) listRoleV2Actions(ctx context.Context, role types.Role) ([]stri
 		return nil, err
 	}
 
-	actions := make([]string, len(relationships))
+	actions := []string{}
+
+	for _, rel := range relationships {
+		if rel.Subject.Object.ObjectId != "*" {
+			continue
+		}
 
-	for i, rel := range relationships {
-		actions[i] = relationToAction(rel.Relation)
+		if action := relationToAction(rel.Relation); !slices.Contains(actions, action) {
+			actions = append(actions, action)
+		}
 	}
 
 	return actions, nil
 }
 
+// SyncRoleSubjectTypes grants the actions of every v2 role to all role
+// subject types of the policy, so subject types added to the policy are
+// granted the actions of existing roles. It returns the number of
+// relationships created.
+func (e *engine) SyncRoleSubjectTypes(ctx context.Context) (int, error) {
+	ctx, span := e.tracer.Start(ctx, "engine.SyncRoleSubjectTypes")
+	defer span.End()
+
+	if len(e.rbac.RoleSubjectTypes) == 0 {
+		return 0, nil
+	}
+
+	// granted maps the permission relationships of every role to the subject
+	// types they are granted to.
+	type rolePermission struct {
+		roleID   string
+		relation string
+	}
+
+	granted := map[rolePermission][]string{}
+
+	filter := &pb.RelationshipFilter{
+		ResourceType: e.namespaced(e.rbac.RoleResource.Name),
+	}
+
+	err := e.streamRelationships(ctx, filter, func(rel *pb.Relationship) error {
+		if rel.Subject.Object.ObjectId != "*" {
+			return nil
+		}
+
+		perm := rolePermission{roleID: rel.Resource.ObjectId, relation: rel.Relation}
+		granted[perm] = append(granted[perm], rel.Subject.Object.ObjectType)
+
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return 0, err
+	}
+
+	var updates []*pb.RelationshipUpdate
+
+	for perm, subjTypes := range granted {
+		roleRef := &pb.ObjectReference{
+			ObjectType: e.namespaced(e.rbac.RoleResource.Name),
+			ObjectId:   perm.roleID,
+		}
+
+		for _, update := range e.createRoleV2RelationshipUpdatesForAction(
+			relationToAction(perm.relation), roleRef,
+			pb.RelationshipUpdate_OPERATION_TOUCH,
+		) {
+			if !slices.Contains(subjTypes, update.Relationship.Subject.Object.ObjectType) {
+				updates = append(updates, update)
+			}
+		}
+	}
+
+	span.SetAttributes(attribute.Int("updates", len(updates)))
+
+	for start := 0; start < len(updates); start += maxRelationshipUpdatesPerWrite {
+		end := min(start+maxRelationshipUpdatesPerWrite, len(updates))
+
+		if _, err := e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{Updates: updates[start:end]}); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return start, err
+		}
+	}
+
+	return len(updates), nil
+}
+
+// validateRoleV2Actions ensures the actions are defined by the policy for
+// resource types supporting role-bindings, listing all undefined actions in
+// an InvalidActionsError.
+func (e *engine) validateRoleV2Actions(actions []string) error {
+	if _, ok := e.schemaTypeMap[e.rbac.RoleBindingResource.Name]; !ok {
+		return ErrRoleV2ResourceNotDefined
+	}
+
+	allowed := e.AllActions()
+
+	var invalid []string
+
+	for _, action := range actions {
+		if !slices.Contains(allowed, action) && !slices.Contains(invalid, action) {
+			invalid = append(invalid, action)
+		}
+	}
+
+	if len(invalid) == 0 {
+		return nil
+	}
+
+	return &InvalidActionsError{ResourceType: e.rbac.RoleResource.Name, Actions: invalid}
+}
+
+// checkRoleNameAvailable returns ErrRoleNameTaken when a role other than
+// roleID owned by ownerID already has the given name, ignoring case. Requests
+// racing past this check are still rejected by the storage constraint.
+func (e *engine) checkRoleNameAvailable(ctx context.Context, ownerID, roleID gidx.PrefixedID, name string) error {
+	existing, err := e.store.GetResourceRoleByName(ctx, ownerID, name)
+
+	switch {
+	case errors.Is(err, storage.ErrNoRoleFound):
+		return nil
+	case err != nil:
+		return err
+	case existing.ID == roleID:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", storage.ErrRoleNameTaken, name)
+	}
+}
+
 // AllActions list all available actions for a role
 func (e *engine) AllActions() []string {
 	rbv2, ok := e.schemaTypeMap[e.rbac.RoleBindingResource.Name]
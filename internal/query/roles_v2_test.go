@@ -7,8 +7,6 @@ import (
 	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 
 	"go.infratographer.com/permissions-api/internal/iapl"
 	"go.infratographer.com/permissions-api/internal/storage"
@@ -77,6 +75,9 @@ func TestCreateRolesV2(t *testing.T) {
 	invalidOwner, err := e.NewResourceFromIDString("idntgrp-group")
 	require.NoError(t, err)
 
+	_, err = e.CreateRoleV2(ctx, actor, tenant, "lb_admin", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
 	type input struct {
 		name    string
 		actions []string
@@ -88,11 +89,27 @@ func TestCreateRolesV2(t *testing.T) {
 			Name: "InvalidActions",
 			Input: input{
 				name:    "role1",
-				actions: []string{"action1", "action2"},
+				actions: []string{"action1", "loadbalancer_get", "action2"},
 				owner:   tenant,
 			},
 			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
-				require.Error(t, res.Err)
+				require.ErrorIs(t, res.Err, ErrInvalidAction)
+
+				var invalidErr *InvalidActionsError
+
+				require.ErrorAs(t, res.Err, &invalidErr)
+				assert.Equal(t, []string{"action1", "action2"}, invalidErr.Actions)
+			},
+		},
+		{
+			Name: "NameTaken",
+			Input: input{
+				name:    "LB_Admin",
+				actions: []string{"loadbalancer_get"},
+				owner:   tenant,
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
+				assert.ErrorIs(t, res.Err, storage.ErrRoleNameTaken)
 			},
 		},
 		{
@@ -128,6 +145,24 @@ func TestCreateRolesV2(t *testing.T) {
 				require.Len(t, role.Actions, 2)
 			},
 		},
+		{
+			Name: "CreateWithActionGroup",
+			Input: input{
+				name:  "lb_group_viewer",
+				owner: tenant,
+				actions: []string{
+					"loadbalancer_viewer",
+					"loadbalancer_get",
+				},
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
+				require.NoError(t, res.Err)
+
+				role := res.Success
+				require.Equal(t, "lb_group_viewer", role.Name)
+				assert.ElementsMatch(t, []string{"loadbalancer_get", "loadbalancer_list"}, role.Actions)
+			},
+		},
 	}
 
 	testFn := func(ctx context.Context, in input) testingx.TestResult[types.Role] {
@@ -225,7 +260,7 @@ func TestListRolesV2(t *testing.T) {
 	require.NoError(t, err)
 
 	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
-		Updates: rbacV2CreateParentRel(root, child, namespace),
+		Updates: rbacV2CreateParentRel(root, child, e.namespace),
 	})
 	require.NoError(t, err)
 
@@ -303,6 +338,9 @@ func TestUpdateRolesV2(t *testing.T) {
 	role, err := e.CreateRoleV2(ctx, actor, tenant, "lb_viewer", []string{"loadbalancer_list", "loadbalancer_get"})
 	require.NoError(t, err)
 
+	_, err = e.CreateRoleV2(ctx, actor, tenant, "lb_admin", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
 	roleRes, err := e.NewResourceFromID(role.ID)
 	require.NoError(t, err)
 
@@ -348,8 +386,24 @@ func TestUpdateRolesV2(t *testing.T) {
 				role:    roleRes,
 			},
 			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
-				assert.Equal(t, status.Code(res.Err), codes.FailedPrecondition)
-				assert.ErrorContains(t, res.Err, "not found")
+				require.ErrorIs(t, res.Err, ErrInvalidAction)
+
+				var invalidErr *InvalidActionsError
+
+				require.ErrorAs(t, res.Err, &invalidErr)
+				assert.Equal(t, []string{"notfound"}, invalidErr.Actions)
+			},
+			Sync: true,
+		},
+		{
+			Name: "UpdateNameTaken",
+			Input: input{
+				name:    "LB_Admin",
+				actions: []string{"loadbalancer_list", "loadbalancer_get"},
+				role:    roleRes,
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.Role]) {
+				assert.ErrorIs(t, res.Err, storage.ErrRoleNameTaken)
 			},
 			Sync: true,
 		},
@@ -424,12 +478,12 @@ func TestDeleteRolesV2(t *testing.T) {
 	require.NoError(t, err)
 
 	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
-		Updates: rbacV2CreateParentRel(root, child, namespace),
+		Updates: rbacV2CreateParentRel(root, child, e.namespace),
 	})
 	require.NoError(t, err)
 
 	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
-		Updates: rbacV2CreateParentRel(root, theotherchild, namespace),
+		Updates: rbacV2CreateParentRel(root, theotherchild, e.namespace),
 	})
 	require.NoError(t, err)
 
@@ -523,3 +577,147 @@ func TestDeleteRolesV2(t *testing.T) {
 
 	testingx.RunTests(ctx, t, tc, testFn)
 }
+
+func TestForceDeleteRoleV2(t *testing.T) {
+	namespace := "testroles"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	child, err := e.NewResourceFromIDString("tnntten-child")
+	require.NoError(t, err)
+	subj, err := e.NewResourceFromIDString("idntusr-subj")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
+		Updates: rbacV2CreateParentRel(root, child, e.namespace),
+	})
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, actor, root, "lb_viewer", []string{"loadbalancer_list", "loadbalancer_get"})
+	require.NoError(t, err)
+
+	roleRes, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	unusedRole, err := e.CreateRoleV2(ctx, actor, root, "lb_editor", []string{"loadbalancer_update"})
+	require.NoError(t, err)
+
+	unusedRoleRes, err := e.NewResourceFromID(unusedRole.ID)
+	require.NoError(t, err)
+
+	_, err = e.CreateRoleBinding(ctx, actor, root, roleRes, []types.RoleBindingSubject{{SubjectResource: subj}})
+	require.NoError(t, err)
+
+	_, err = e.CreateRoleBinding(ctx, actor, child, roleRes, []types.RoleBindingSubject{{SubjectResource: subj}})
+	require.NoError(t, err)
+
+	tc := []testingx.TestCase[types.Resource, int]{
+		{
+			Name:  "RoleNotFound",
+			Input: types.Resource{Type: roleRes.Type, ID: "permrv2-notfound"},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[int]) {
+				assert.ErrorIs(t, res.Err, storage.ErrNoRoleFound)
+				assert.Zero(t, res.Success)
+			},
+		},
+		{
+			Name:  "NoBindings",
+			Input: unusedRoleRes,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[int]) {
+				require.NoError(t, res.Err)
+				assert.Zero(t, res.Success)
+
+				_, err := e.GetRoleV2(ctx, unusedRoleRes)
+				assert.ErrorIs(t, err, storage.ErrNoRoleFound)
+			},
+		},
+		{
+			Name:  "CascadeBindings",
+			Input: roleRes,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[int]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, 2, res.Success)
+
+				_, err := e.GetRoleV2(ctx, roleRes)
+				assert.ErrorIs(t, err, storage.ErrNoRoleFound)
+
+				for _, resource := range []types.Resource{root, child} {
+					rbs, err := e.ListRoleBindings(ctx, resource, nil)
+					require.NoError(t, err)
+					assert.Empty(t, rbs)
+				}
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in types.Resource) testingx.TestResult[int] {
+		deleted, err := e.ForceDeleteRoleV2(ctx, in)
+
+		return testingx.TestResult[int]{Success: deleted, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
+
+func TestSyncRoleSubjectTypes(t *testing.T) {
+	namespace := "testsyncrolesubjects"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+
+	actions := []string{"loadbalancer_get", "loadbalancer_update"}
+
+	role, err := e.CreateRoleV2(ctx, actor, tenant, "lb_editor", actions)
+	require.NoError(t, err)
+
+	roleRes, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	// the role was created before client was a role subject type
+	var updates []*pb.RelationshipUpdate
+
+	for _, action := range actions {
+		for _, update := range e.createRoleV2RelationshipUpdatesForAction(action, resourceToSpiceDBRef(e.namespace, roleRes), pb.RelationshipUpdate_OPERATION_DELETE) {
+			if update.Relationship.Subject.Object.ObjectType == e.namespaced("client") {
+				updates = append(updates, update)
+			}
+		}
+	}
+
+	require.Len(t, updates, len(actions))
+
+	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{Updates: updates})
+	require.NoError(t, err)
+
+	client, err := e.NewResourceFromIDString("idntclt-client")
+	require.NoError(t, err)
+
+	_, err = e.CreateRoleBinding(ctx, actor, tenant, roleRes, []types.RoleBindingSubject{{SubjectResource: client}})
+	require.NoError(t, err)
+
+	err = e.SubjectHasPermission(ctx, client, "loadbalancer_get", tenant)
+	assert.ErrorIs(t, err, ErrActionNotAssigned)
+
+	created, err := e.SyncRoleSubjectTypes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(actions), created)
+
+	err = e.SubjectHasPermission(ctx, client, "loadbalancer_get", tenant)
+	assert.NoError(t, err)
+
+	got, err := e.GetRoleV2(ctx, roleRes)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, actions, got.Actions)
+
+	created, err = e.SyncRoleSubjectTypes(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, created)
+}
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// provisionRoleTemplates provisions the role templates of the policy for the
+// role owners among the resources of the given relationships.
+func (e *engine) provisionRoleTemplates(ctx context.Context, rels []types.Relationship) error {
+	if len(e.roleTemplates) == 0 {
+		return nil
+	}
+
+	seen := make(map[gidx.PrefixedID]struct{}, len(rels))
+
+	for _, rel := range rels {
+		if _, ok := seen[rel.Resource.ID]; ok {
+			continue
+		}
+
+		seen[rel.Resource.ID] = struct{}{}
+
+		if err := e.provisionOwnerRoles(ctx, rel.Resource); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// provisionOwnerRoles creates the roles of the role templates for the owner
+// which it does not have yet, roles named after a template are kept as they
+// are. Template roles are created by their owner.
+func (e *engine) provisionOwnerRoles(ctx context.Context, owner types.Resource) error {
+	if _, ok := e.rbac.RoleOwnersSet()[owner.Type]; !ok {
+		return nil
+	}
+
+	var templates []iapl.RoleTemplate
+
+	for _, template := range e.roleTemplates {
+		if len(template.Owners) == 0 || slices.Contains(template.Owners, owner.Type) {
+			templates = append(templates, template)
+		}
+	}
+
+	if len(templates) == 0 {
+		return nil
+	}
+
+	roles, err := e.store.ListResourceRoles(ctx, owner.ID)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]struct{}, len(roles))
+
+	for _, role := range roles {
+		existing[role.Name] = struct{}{}
+	}
+
+	for _, template := range templates {
+		if _, ok := existing[template.Name]; ok {
+			continue
+		}
+
+		_, err := e.createRoleV2(ctx, owner, owner, template.Name, template.Actions)
+
+		// the role was provisioned concurrently
+		if errors.Is(err, storage.ErrRoleNameTaken) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: provisioning role template %s for %s", err, template.Name, owner.ID)
+		}
+
+		e.contextLogger(ctx).Infow("provisioned role template", "role_template", template.Name, "owner_id", owner.ID)
+	}
+
+	return nil
+}
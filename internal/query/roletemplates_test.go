@@ -0,0 +1,68 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestProvisionRoleTemplates(t *testing.T) {
+	ctx := context.Background()
+
+	doc := DefaultPolicyDocumentV2()
+	doc.ActionGroups = []iapl.ActionGroup{
+		{Name: "loadbalancer_viewer", Actions: []string{"loadbalancer_get", "loadbalancer_list"}},
+	}
+	doc.RoleTemplates = []iapl.RoleTemplate{
+		{Name: "viewer", Actions: []string{"loadbalancer_viewer"}},
+		{Name: "admin", Actions: []string{"loadbalancer_create", "loadbalancer_get", "loadbalancer_delete"}},
+	}
+
+	policy := iapl.NewPolicy(doc)
+	require.NoError(t, policy.Validate())
+
+	e := testEngine(ctx, t, "infratestroletemplates", policy)
+
+	parent, err := e.NewResourceFromID(gidx.MustNewID("tnntten"))
+	require.NoError(t, err)
+
+	child, err := e.NewResourceFromID(gidx.MustNewID("tnntten"))
+	require.NoError(t, err)
+
+	// roles named after a template are kept
+	existing, err := e.CreateRoleV2(ctx, parent, child, "admin", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+
+	rels := []types.Relationship{{Resource: child, Relation: "parent", Subject: parent}}
+
+	require.NoError(t, e.CreateRelationships(ctx, rels))
+
+	// provisioning is idempotent
+	require.NoError(t, e.CreateRelationships(ctx, rels))
+
+	roles, err := e.store.ListResourceRoles(ctx, child.ID)
+	require.NoError(t, err)
+	require.Len(t, roles, 2)
+
+	for _, dbRole := range roles {
+		role, err := e.GetRoleV2(ctx, types.Resource{Type: "rolev2", ID: dbRole.ID})
+		require.NoError(t, err)
+
+		switch role.Name {
+		case "viewer":
+			assert.ElementsMatch(t, []string{"loadbalancer_get", "loadbalancer_list"}, role.Actions)
+			assert.Equal(t, child.ID, role.CreatedBy)
+		case "admin":
+			assert.Equal(t, existing.ID, role.ID)
+			assert.Equal(t, []string{"loadbalancer_get"}, role.Actions)
+		default:
+			t.Errorf("unexpected role %s", role.Name)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package query
 
 import (
 	"context"
+	"time"
 
 	"github.com/authzed/authzed-go/v1"
+	"go.infratographer.com/x/events"
 	"go.infratographer.com/x/gidx"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
@@ -12,6 +14,7 @@ import (
 	"go.infratographer.com/permissions-api/internal/iapl"
 	"go.infratographer.com/permissions-api/internal/storage"
 	"go.infratographer.com/permissions-api/internal/types"
+	"go.infratographer.com/permissions-api/internal/webhooks"
 )
 
 const (
@@ -22,29 +25,34 @@ const (
 	DefaultRoleResourceName = "role"
 	// DefaultRoleBindingResourceName is the default name for a role binding resource
 	DefaultRoleBindingResourceName = "role_binding"
+	// DefaultMaxGroupDepth is the default maximum number of levels groups may be nested
+	DefaultMaxGroupDepth = 5
 )
 
-// Engine represents a client for making permissions queries.
-type Engine interface {
-	AssignSubjectRole(ctx context.Context, subject types.Resource, role types.Role) error
-	UnassignSubjectRole(ctx context.Context, subject types.Resource, role types.Role) error
-	CreateRelationships(ctx context.Context, rels []types.Relationship) error
-	CreateRole(ctx context.Context, actor, res types.Resource, roleName string, actions []string) (types.Role, error)
-	UpdateRole(ctx context.Context, actor, roleResource types.Resource, newName string, newActions []string) (types.Role, error)
-	GetRole(ctx context.Context, roleResource types.Resource) (types.Role, error)
-	GetRoleResource(ctx context.Context, roleResource types.Resource) (types.Resource, error)
-	ListAssignments(ctx context.Context, role types.Role) ([]types.Resource, error)
-	ListRelationshipsFrom(ctx context.Context, resource types.Resource) ([]types.Relationship, error)
-	ListRelationshipsTo(ctx context.Context, resource types.Resource) ([]types.Relationship, error)
-	ListRoles(ctx context.Context, resource types.Resource) ([]types.Role, error)
-	DeleteRelationships(ctx context.Context, relationships ...types.Relationship) error
-	DeleteRole(ctx context.Context, roleResource types.Resource) error
-	DeleteResourceRelationships(ctx context.Context, resource types.Resource) error
+// ResourceResolver resolves resources and resource types of the policy.
+type ResourceResolver interface {
 	NewResourceFromID(id gidx.PrefixedID) (types.Resource, error)
 	GetResourceType(name string) *types.ResourceType
+	// ResolveResource returns the resource for a prefixed ID or a registered alias.
+	ResolveResource(ctx context.Context, id string) (types.Resource, error)
+}
+
+// Checker checks the permissions of subjects on resources.
+type Checker interface {
+	ResourceResolver
+
 	SubjectHasPermission(ctx context.Context, subject types.Resource, action string, resource types.Resource) error
+	// SubjectAllowedActions returns all actions the subject can do on the resource.
+	SubjectAllowedActions(ctx context.Context, subject, resource types.Resource) ([]string, error)
+	// ExplainPermission returns a grant path of the action on the resource to the subject.
+	ExplainPermission(ctx context.Context, subject types.Resource, action string, resource types.Resource) ([]types.GrantStep, error)
+	// FilterAllowedResources returns the resources the subject is allowed to do the action on.
+	FilterAllowedResources(ctx context.Context, subject types.Resource, action string, resources []types.Resource) ([]types.Resource, error)
+}
 
-	// v2 functions, add role bindings support
+// RoleManager manages v2 roles.
+type RoleManager interface {
+	ResourceResolver
 
 	// CreateRoleV2 creates a v2 role scoped to the given owner resource with the given actions.
 	CreateRoleV2(ctx context.Context, actor, owner types.Resource, roleName string, actions []string) (types.Role, error)
@@ -56,14 +64,35 @@ type Engine interface {
 	UpdateRoleV2(ctx context.Context, actor, roleResource types.Resource, newName string, newActions []string) (types.Role, error)
 	// DeleteRoleV2 deletes a V2 role.
 	DeleteRoleV2(ctx context.Context, roleResource types.Resource) error
+	// ForceDeleteRoleV2 deletes a V2 role and all role-bindings of the role,
+	// returning the number of deleted role-bindings.
+	ForceDeleteRoleV2(ctx context.Context, roleResource types.Resource) (int, error)
+}
+
+// BindingManager manages role-bindings.
+type BindingManager interface {
+	ResourceResolver
 
 	// CreateRoleBinding creates all the necessary relationships for a role binding.
 	// role binding here establishes a three-way relationship between a role,
 	// a resource, and the subjects.
 	CreateRoleBinding(ctx context.Context, actor, resource, role types.Resource, subjects []types.RoleBindingSubject) (types.RoleBinding, error)
+	// CreateRoleBindingWithCaveat creates a role binding which only applies
+	// when the given policy caveat is satisfied.
+	CreateRoleBindingWithCaveat(
+		ctx context.Context,
+		actor, resource, role types.Resource,
+		subjects []types.RoleBindingSubject,
+		caveat types.RoleBindingCaveat,
+	) (types.RoleBinding, error)
 	// ListRoleBindings lists all role-bindings for a resource, an optional Role
 	// can be provided to filter the role-bindings.
 	ListRoleBindings(ctx context.Context, resource types.Resource, optionalRole *types.Resource) ([]types.RoleBinding, error)
+	// StreamRoleBindings calls fn for every role-binding for a resource.
+	StreamRoleBindings(ctx context.Context, resource types.Resource, fn func(types.RoleBinding) error) error
+	// ListSubjectRoleBindings lists all role-bindings, across resources, the
+	// given subject is a subject of.
+	ListSubjectRoleBindings(ctx context.Context, subject types.Resource) ([]types.RoleBinding, error)
 	// GetRoleBinding fetches a role-binding by its ID.
 	GetRoleBinding(ctx context.Context, rolebinding types.Resource) (types.RoleBinding, error)
 	// UpdateRoleBinding updates the subjects of a role-binding.
@@ -73,8 +102,149 @@ type Engine interface {
 	// GetRoleBindingResource fetches the resource to which a role-binding
 	// belongs
 	GetRoleBindingResource(ctx context.Context, rb types.Resource) (types.Resource, error)
+	// CreateRoleBindings creates many role-bindings on a resource in batches,
+	// returning a result for every request.
+	CreateRoleBindings(ctx context.Context, actor, resource types.Resource, requests []types.RoleBindingRequest) []types.RoleBindingResult
+	// DeleteRoleBindings deletes many role-bindings from a resource in batches,
+	// returning a result for every role-binding.
+	DeleteRoleBindings(ctx context.Context, resource types.Resource, rolebindings []types.Resource) []types.RoleBindingResult
+	// ListStaleRoleBindings lists the role-bindings on a resource which have not
+	// been used since the given time.
+	ListStaleRoleBindings(ctx context.Context, resource types.Resource, unusedSince time.Time) ([]types.RoleBinding, error)
+	// SuggestRoleBindingReductions lists the role-bindings on a resource granting
+	// actions which have not been used since the given time.
+	SuggestRoleBindingReductions(ctx context.Context, resource types.Resource, usedSince time.Time) ([]types.RoleBindingSuggestion, error)
+}
+
+// RelationshipWriter creates and deletes relationships between resources.
+type RelationshipWriter interface {
+	ResourceResolver
+
+	CreateRelationships(ctx context.Context, rels []types.Relationship) error
+	DeleteRelationships(ctx context.Context, relationships ...types.Relationship) error
+	// WriteRelationships creates and deletes relationships in chunks, each
+	// applied only if the preconditions hold, returning the number of writes applied.
+	WriteRelationships(ctx context.Context, writes []types.RelationshipWrite, preconditions []types.RelationshipPrecondition) (int, error)
+	DeleteResourceRelationships(ctx context.Context, resource types.Resource) error
+}
+
+// Engine represents a client for making permissions queries.
+type Engine interface {
+	Checker
+	RoleManager
+	BindingManager
+	RelationshipWriter
+
+	AssignSubjectRole(ctx context.Context, subject types.Resource, role types.Role) error
+	UnassignSubjectRole(ctx context.Context, subject types.Resource, role types.Role) error
+	CreateRole(ctx context.Context, actor, res types.Resource, roleName string, actions []string) (types.Role, error)
+	UpdateRole(ctx context.Context, actor, roleResource types.Resource, newName string, newActions []string) (types.Role, error)
+	GetRole(ctx context.Context, roleResource types.Resource) (types.Role, error)
+	GetRoleResource(ctx context.Context, roleResource types.Resource) (types.Resource, error)
+	ListAssignments(ctx context.Context, role types.Role) ([]types.Resource, error)
+	ListRelationshipsFrom(ctx context.Context, resource types.Resource) ([]types.Relationship, error)
+	ListRelationshipsTo(ctx context.Context, resource types.Resource) ([]types.Relationship, error)
+	// StreamRelationshipsFrom calls fn for every non-role relationship bound to a given resource.
+	StreamRelationshipsFrom(ctx context.Context, resource types.Resource, fn func(types.Relationship) error) error
+	// StreamRelationshipsTo calls fn for every non-role relationship destined for a given resource.
+	StreamRelationshipsTo(ctx context.Context, resource types.Resource, fn func(types.Relationship) error) error
+	ListRoles(ctx context.Context, resource types.Resource) ([]types.Role, error)
+	// ReadRelationships returns a page of at most limit relationships matching the
+	// filter, starting after the cursor, and the cursor of the next page.
+	ReadRelationships(ctx context.Context, filter types.RelationshipFilter, limit int, cursor string) ([]types.RelationshipRecord, string, error)
+	// WatchRelationshipChanges calls fn for every relationship created or
+	// deleted after the given ZedToken, or after the current revision when empty.
+	WatchRelationshipChanges(ctx context.Context, after string, fn func(types.RelationshipChange) error) error
+	DeleteRole(ctx context.Context, roleResource types.Resource) error
+	// DeleteResource removes the role-bindings, roles, groups and relationships
+	// left behind by a deleted resource.
+	DeleteResource(ctx context.Context, resource types.Resource) error
+
+	// CreateInvitation creates an invitation to bind the role on the resource,
+	// returning the invitation and its token. The token is not stored and
+	// cannot be retrieved later.
+	CreateInvitation(ctx context.Context, actor, resource, role types.Resource, email string, ttl time.Duration) (types.Invitation, string, error)
+	// GetInvitation fetches an invitation by its ID.
+	GetInvitation(ctx context.Context, id gidx.PrefixedID) (types.Invitation, error)
+	// ListInvitations lists all invitations for a resource.
+	ListInvitations(ctx context.Context, resource types.Resource) ([]types.Invitation, error)
+	// DeleteInvitation deletes an invitation, revoking its token.
+	DeleteInvitation(ctx context.Context, id gidx.PrefixedID) error
+	// RedeemInvitation creates the role-binding of the invitation with the
+	// given token for the subject, on behalf of the creator of the invitation.
+	// Any subject holding the token may redeem it.
+	RedeemInvitation(ctx context.Context, subject types.Resource, token string) (types.RoleBinding, error)
+
+	// CreateAccessRequest records a request by the subject to be bound to the
+	// role on the resource, pending a decision by an approver.
+	CreateAccessRequest(ctx context.Context, subject, resource, role types.Resource, reason string) (types.AccessRequest, error)
+	// GetAccessRequest fetches an access request by its ID.
+	GetAccessRequest(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error)
+	// ListAccessRequests lists the access requests for a resource, only those
+	// with the given status if one is provided.
+	ListAccessRequests(ctx context.Context, resource types.Resource, status types.AccessRequestStatus) ([]types.AccessRequest, error)
+	// DecideAccessRequest approves or denies a pending access request,
+	// approving creates its role-binding.
+	DecideAccessRequest(ctx context.Context, actor types.Resource, id gidx.PrefixedID, approve bool, reason string) (types.AccessRequest, error)
+
+	// CreateGroup creates a group owned by the given resource.
+	CreateGroup(ctx context.Context, actor, owner types.Resource, name, description string) (types.Group, error)
+	// GetGroup fetches a group by its ID.
+	GetGroup(ctx context.Context, id gidx.PrefixedID) (types.Group, error)
+	// ListGroups lists all groups owned by a resource.
+	ListGroups(ctx context.Context, owner types.Resource) ([]types.Group, error)
+	// UpdateGroup updates the name and description of a group.
+	UpdateGroup(ctx context.Context, actor types.Resource, id gidx.PrefixedID, name, description string) (types.Group, error)
+	// DeleteGroup deletes a group, removing it from all role-bindings and groups.
+	DeleteGroup(ctx context.Context, id gidx.PrefixedID) error
+	// ListGroupMembers lists the direct members of a group.
+	ListGroupMembers(ctx context.Context, id gidx.PrefixedID) ([]types.Resource, error)
+	// ExpandGroupMembers lists all direct and transitive members of a group, sorted by ID.
+	ExpandGroupMembers(ctx context.Context, id gidx.PrefixedID) ([]types.GroupMember, error)
+	// AddGroupMembers adds subjects, including other groups, to a group.
+	AddGroupMembers(ctx context.Context, id gidx.PrefixedID, members ...types.Resource) error
+	// RemoveGroupMembers removes subjects from a group.
+	RemoveGroupMembers(ctx context.Context, id gidx.PrefixedID, members ...types.Resource) error
+
+	// CreateResourceAlias registers an alias, such as the URN of the resource
+	// in an external system, for a resource.
+	CreateResourceAlias(ctx context.Context, actor, resource types.Resource, alias string) (types.ResourceAlias, error)
+	// GetResourceAlias fetches a registered alias.
+	GetResourceAlias(ctx context.Context, alias string) (types.ResourceAlias, error)
+	// ListResourceAliases lists all aliases of a resource.
+	ListResourceAliases(ctx context.Context, resource types.Resource) ([]types.ResourceAlias, error)
+	// DeleteResourceAlias deletes a registered alias.
+	DeleteResourceAlias(ctx context.Context, alias string) error
+
+	// GetTenantSettings returns the settings of a tenant, defaults are returned if none are stored.
+	GetTenantSettings(ctx context.Context, tenant types.Resource) (types.TenantSettings, error)
+	// UpdateTenantSettings stores the settings of a tenant.
+	UpdateTenantSettings(ctx context.Context, actor, tenant types.Resource, settings types.TenantSettings) (types.TenantSettings, error)
+
+	// PurgeTenant deletes the authorization footprint of a tenant and its
+	// descendants in batches, reporting the footprint left once deleted.
+	PurgeTenant(ctx context.Context, tenant types.Resource, batchSize int, dryRun bool) (types.TenantPurgeReport, error)
+
+	// ListRoleV1Resources lists the resources with v1 roles, sorted by ID,
+	// starting after the given resource ID.
+	ListRoleV1Resources(ctx context.Context, after gidx.PrefixedID, limit int) ([]types.Resource, error)
+	// MigrateRolesV1 replaces the v1 roles of a resource with v2 roles and
+	// role-bindings, returning the outcome for every role.
+	MigrateRolesV1(ctx context.Context, actor, resource types.Resource, dryRun bool) ([]types.RoleMigration, error)
+	// SyncRoleSubjectTypes grants the actions of every v2 role to all role subject
+	// types of the policy, returning the number of relationships created.
+	SyncRoleSubjectTypes(ctx context.Context) (int, error)
 
 	AllActions() []string
+	// AllActionGroups lists the action groups defined by the policy.
+	AllActionGroups() []types.ActionGroup
+	// PolicyInfo returns the hashes of the loaded policy and the schema it
+	// generated, and whether the schema applied to SpiceDB matches.
+	PolicyInfo(ctx context.Context) (types.PolicyInfo, error)
+
+	// Stop stops the background work of the engine, flushing pending usage
+	// and decisions. The engine must not be used once stopped.
+	Stop() error
 }
 
 type engine struct {
@@ -96,6 +266,49 @@ type engine struct {
 	// rbacV2ResourceTypes is a list of resource types that had rbac V2 enabled,
 	// role-binding only works with resource types that are in this list
 	rbacV2ResourceTypes []types.ResourceType
+	// actionGroups maps the name of an action group to the group, action
+	// groups are expanded to their actions when roles are created or updated.
+	actionGroups map[string]types.ActionGroup
+	// actionGroupNames keeps the action groups in the order of the policy.
+	actionGroupNames []string
+	// roleTemplates are provisioned for new role owners.
+	roleTemplates []iapl.RoleTemplate
+	// caveats role-bindings may be conditioned on, caveatMap provides quick
+	// lookups by name.
+	caveats   []types.Caveat
+	caveatMap map[string]types.Caveat
+
+	// usage, when set, tracks when role-bindings and roles were last used.
+	usage *usageTracker
+
+	// maxGroupDepth is the maximum number of levels groups may be nested.
+	maxGroupDepth int
+
+	// audit, when set, publishes an event for every change of permissions.
+	audit *auditPublisher
+
+	// degraded, when set, answers permission checks while the SpiceDB circuit is open.
+	degraded *degradedMode
+
+	// checkCache, when set, caches permission check results until relationships change.
+	checkCache *checkCache
+
+	// decisions, when set, records the outcome of permission checks.
+	decisions *decisionLog
+
+	// webhooks, when set, delivers role and role-binding changes to webhooks.
+	webhooks *webhooks.Dispatcher
+
+	// delegationDisabled ignores the delegation restrictions of the policy.
+	delegationDisabled bool
+
+	// policyHash is the hash of the loaded policy, policyLoadedAt is when it was loaded.
+	policyHash     string
+	policyLoadedAt time.Time
+
+	// background runs the usage tracker, check cache watch and decision log
+	// until the engine is stopped.
+	background *backgroundTasks
 }
 
 func (e *engine) cacheSchemaResources() {
@@ -168,6 +381,8 @@ func NewEngine(namespace string, client *authzed.Client, store storage.Storage,
 		client:    client,
 		store:     store,
 		tracer:    tracer,
+
+		maxGroupDepth: DefaultMaxGroupDepth,
 	}
 
 	for _, fn := range options {
@@ -178,10 +393,26 @@ func NewEngine(namespace string, client *authzed.Client, store storage.Storage,
 		p := iapl.DefaultPolicy()
 		e.schema = p.Schema()
 		e.rbac = iapl.RBAC{}
+		e.policyHash = p.Hash()
+		e.policyLoadedAt = time.Now()
 
 		e.cacheSchemaResources()
 	}
 
+	e.background = newBackgroundTasks()
+
+	if e.usage != nil {
+		e.background.run(e.trackUsage)
+	}
+
+	if e.checkCache != nil {
+		e.background.run(e.watchRelationships)
+	}
+
+	if e.decisions != nil {
+		e.background.run(e.runDecisionLog)
+	}
+
 	return e, nil
 }
 
@@ -199,6 +430,8 @@ func WithLogger(logger *zap.SugaredLogger) Option {
 func WithPolicy(policy iapl.Policy) Option {
 	return func(e *engine) {
 		e.schema = policy.Schema()
+		e.policyHash = policy.Hash()
+		e.policyLoadedAt = time.Now()
 
 		rbac := policy.RBAC()
 		if rbac == nil {
@@ -207,6 +440,136 @@ func WithPolicy(policy iapl.Policy) Option {
 			e.rbac = *rbac
 		}
 
+		e.actionGroups = make(map[string]types.ActionGroup)
+		e.actionGroupNames = nil
+
+		for _, group := range policy.ActionGroups() {
+			e.actionGroups[group.Name] = types.ActionGroup{
+				Name:        group.Name,
+				Description: group.Description,
+				Actions:     group.Actions,
+			}
+
+			e.actionGroupNames = append(e.actionGroupNames, group.Name)
+		}
+
+		e.roleTemplates = policy.RoleTemplates()
+
+		e.caveats = policy.Caveats()
+		e.caveatMap = make(map[string]types.Caveat, len(e.caveats))
+
+		for _, caveat := range e.caveats {
+			e.caveatMap[caveat.Name] = caveat
+		}
+
 		e.cacheSchemaResources()
 	}
 }
+
+// WithMaxGroupDepth sets the maximum number of levels groups may be nested,
+// adding a group to another group is rejected if it would exceed the depth.
+func WithMaxGroupDepth(depth int) Option {
+	return func(e *engine) {
+		if depth <= 0 {
+			return
+		}
+
+		e.maxGroupDepth = depth
+	}
+}
+
+// WithAuditEvents publishes an event to the given topic for every change of
+// roles, role-bindings and relationships.
+func WithAuditEvents(publisher events.Publisher, topic string) Option {
+	return func(e *engine) {
+		if publisher == nil || topic == "" {
+			return
+		}
+
+		e.audit = &auditPublisher{
+			publisher: publisher,
+			topic:     topic,
+		}
+	}
+}
+
+// WithUsageTracking enables tracking when role-bindings and roles were last
+// used, allowed decisions are resolved and recorded every flush interval.
+func WithUsageTracking(flushInterval time.Duration) Option {
+	return func(e *engine) {
+		if flushInterval <= 0 {
+			return
+		}
+
+		e.usage = &usageTracker{
+			interval:  flushInterval,
+			decisions: make(chan usageDecision, usageBufferSize),
+		}
+	}
+}
+
+// WithDegradedMode answers permission checks rejected by an open SpiceDB
+// circuit breaker with the configured fallback instead of failing them.
+func WithDegradedMode(config DegradedConfig) Option {
+	return func(e *engine) {
+		if len(config.FailOpenActions) == 0 && !config.CacheDecisions {
+			return
+		}
+
+		e.degraded = &degradedMode{
+			failOpen: make(map[string]bool, len(config.FailOpenActions)),
+		}
+
+		for _, action := range config.FailOpenActions {
+			e.degraded.failOpen[action] = true
+		}
+
+		if config.CacheDecisions {
+			e.degraded.cache = newDecisionCache(config.CacheTTL, config.CacheSize)
+		}
+	}
+}
+
+// WithCheckCache caches permission check results, invalidated by watching
+// SpiceDB for relationship changes.
+func WithCheckCache(config CheckCacheConfig) Option {
+	return func(e *engine) {
+		if !config.Enabled {
+			return
+		}
+
+		e.checkCache = newCheckCache(config)
+	}
+}
+
+// WithDecisionLog records the outcome of permission checks to the sink. Only
+// the given fraction of allowed decisions is recorded, all when 1 or more.
+func WithDecisionLog(sink DecisionSink, sampleRate float64) Option {
+	return func(e *engine) {
+		if sink == nil {
+			return
+		}
+
+		e.decisions = &decisionLog{
+			sink:       sink,
+			sampleRate: sampleRate,
+			decisions:  make(chan types.Decision, decisionLogBufferSize),
+		}
+	}
+}
+
+// WithoutDelegation ignores the delegation restrictions of the policy, for
+// tools run by operators, such as seeding, which act as any actor.
+func WithoutDelegation() Option {
+	return func(e *engine) {
+		e.delegationDisabled = true
+	}
+}
+
+// WithWebhooks delivers role and role-binding changes to the webhooks of the
+// dispatcher. The dispatcher must be run separately to send the deliveries.
+func WithWebhooks(dispatcher *webhooks.Dispatcher) Option {
+	return func(e *engine) {
+		e.webhooks = dispatcher
+	}
+}
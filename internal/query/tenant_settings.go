@@ -0,0 +1,231 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// DefaultTenantSettings returns the settings of tenants without stored settings.
+func DefaultTenantSettings(tenantID gidx.PrefixedID) types.TenantSettings {
+	return types.TenantSettings{
+		TenantID:           tenantID,
+		AllowGroupBindings: true,
+		InheritParentRoles: true,
+	}
+}
+
+// validateTenant ensures settings can be stored for the resource, settings
+// are stored for role owners, e.g. tenants.
+func (e *engine) validateTenant(tenant types.Resource) error {
+	return checkResourceType(tenant, e.rbac.RoleOwners...)
+}
+
+// GetTenantSettings returns the settings of a tenant, defaults are returned
+// for tenants without stored settings.
+func (e *engine) GetTenantSettings(ctx context.Context, tenant types.Resource) (types.TenantSettings, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.GetTenantSettings",
+		trace.WithAttributes(attribute.Stringer("tenant_id", tenant.ID)),
+	)
+	defer span.End()
+
+	fail := func(err error) (types.TenantSettings, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.TenantSettings{}, err
+	}
+
+	if err := e.validateTenant(tenant); err != nil {
+		return fail(err)
+	}
+
+	settings, err := e.tenantSettings(ctx, tenant.ID)
+	if err != nil {
+		return fail(err)
+	}
+
+	return settings, nil
+}
+
+// UpdateTenantSettings stores the settings of a tenant.
+func (e *engine) UpdateTenantSettings(ctx context.Context, actor, tenant types.Resource, settings types.TenantSettings) (types.TenantSettings, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.UpdateTenantSettings",
+		trace.WithAttributes(
+			attribute.Stringer("tenant_id", tenant.ID),
+			attribute.Bool("allow_group_bindings", settings.AllowGroupBindings),
+			attribute.Bool("inherit_parent_roles", settings.InheritParentRoles),
+		),
+	)
+	defer span.End()
+
+	fail := func(err error) (types.TenantSettings, error) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return types.TenantSettings{}, err
+	}
+
+	if err := e.validateTenant(tenant); err != nil {
+		return fail(err)
+	}
+
+	settings.TenantID = tenant.ID
+	settings.UpdatedBy = actor.ID
+
+	dbCtx, err := e.store.BeginContext(ctx)
+	if err != nil {
+		return fail(err)
+	}
+
+	out, err := e.store.UpdateTenantSettings(dbCtx, settings)
+	if err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(err)
+	}
+
+	if err := e.store.CommitContext(dbCtx); err != nil {
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
+
+		return fail(err)
+	}
+
+	return out, nil
+}
+
+func (e *engine) tenantSettings(ctx context.Context, tenantID gidx.PrefixedID) (types.TenantSettings, error) {
+	settings, err := e.store.GetTenantSettings(ctx, tenantID)
+
+	switch {
+	case err == nil:
+		return settings, nil
+	case errors.Is(err, storage.ErrTenantSettingsNotFound):
+		return DefaultTenantSettings(tenantID), nil
+	default:
+		return types.TenantSettings{}, err
+	}
+}
+
+// resourceTenants returns the tenants a resource belongs to, nearest first.
+// The resource itself is included if it is a tenant. Tenants are found by
+// following direct relationships to role owners, e.g. owner and parent.
+func (e *engine) resourceTenants(ctx context.Context, resource types.Resource) ([]types.Resource, error) {
+	owners := e.rbac.RoleOwnersSet()
+
+	var tenants []types.Resource
+
+	if _, ok := owners[resource.Type]; ok {
+		tenants = append(tenants, resource)
+	}
+
+	visited := map[gidx.PrefixedID]struct{}{resource.ID: {}}
+	queue := []types.Resource{resource}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		filter := &pb.RelationshipFilter{
+			ResourceType:       e.namespaced(current.Type),
+			OptionalResourceId: current.ID.String(),
+		}
+
+		err := e.streamRelationships(ctx, filter, func(rel *pb.Relationship) error {
+			// relationships to sets of subjects, e.g. tenant#member, are not ownership
+			if rel.Subject.OptionalRelation != "" {
+				return nil
+			}
+
+			id, err := gidx.Parse(rel.Subject.Object.ObjectId)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := visited[id]; ok {
+				return nil
+			}
+
+			tenant, err := e.NewResourceFromID(id)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := owners[tenant.Type]; !ok {
+				return nil
+			}
+
+			visited[id] = struct{}{}
+
+			tenants = append(tenants, tenant)
+			queue = append(queue, tenant)
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tenants, nil
+}
+
+// checkTenantSettings ensures the settings of the tenants the resource belongs
+// to allow binding the role owned by roleOwnerID to the subjects. An empty
+// roleOwnerID skips the role check, e.g. when only subjects are added.
+func (e *engine) checkTenantSettings(
+	ctx context.Context,
+	resource types.Resource,
+	roleOwnerID gidx.PrefixedID,
+	subjects []types.RoleBindingSubject,
+) error {
+	var groupSubject *types.Resource
+
+	for _, subj := range subjects {
+		if e.rolebindingSubjectsMap[subj.SubjectResource.Type].SubjectRelation != "" {
+			groupSubject = &subj.SubjectResource
+
+			break
+		}
+	}
+
+	tenants, err := e.resourceTenants(ctx, resource)
+	if err != nil {
+		return err
+	}
+
+	// roles owned by the tenants up to an isolated tenant may be bound
+	roleAvailable := roleOwnerID == ""
+
+	for _, tenant := range tenants {
+		if tenant.ID == roleOwnerID {
+			roleAvailable = true
+		}
+
+		settings, err := e.tenantSettings(ctx, tenant.ID)
+		if err != nil {
+			return err
+		}
+
+		if groupSubject != nil && !settings.AllowGroupBindings {
+			return fmt.Errorf("%w: tenant %s does not allow binding roles to %s", ErrDeniedByTenantSettings, tenant.ID, groupSubject.ID)
+		}
+
+		if !roleAvailable && !settings.InheritParentRoles {
+			return fmt.Errorf("%w: tenant %s does not allow binding roles owned by %s", ErrDeniedByTenantSettings, tenant.ID, roleOwnerID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestTenantSettings(t *testing.T) {
+	namespace := "testtenantsettings"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	root, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	child, err := e.NewResourceFromIDString("tnntten-child")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+	user, err := e.NewResourceFromIDString("idntusr-user")
+	require.NoError(t, err)
+	group, err := e.NewResourceFromIDString("idntgrp-group")
+	require.NoError(t, err)
+	lb, err := e.NewResourceFromIDString("loadbal-lb")
+	require.NoError(t, err)
+
+	_, err = e.client.WriteRelationships(ctx, &pb.WriteRelationshipsRequest{
+		Updates: rbacV2CreateParentRel(root, child, e.namespace),
+	})
+	require.NoError(t, err)
+
+	err = e.CreateRelationships(ctx, []types.Relationship{{
+		Resource: lb,
+		Relation: "owner",
+		Subject:  child,
+	}})
+	require.NoError(t, err)
+
+	rootRole, err := e.CreateRoleV2(ctx, actor, root, "lb_viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+	rootRoleRes, err := e.NewResourceFromID(rootRole.ID)
+	require.NoError(t, err)
+
+	childRole, err := e.CreateRoleV2(ctx, actor, child, "lb_viewer", []string{"loadbalancer_get"})
+	require.NoError(t, err)
+	childRoleRes, err := e.NewResourceFromID(childRole.ID)
+	require.NoError(t, err)
+
+	settings, err := e.GetTenantSettings(ctx, child)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTenantSettings(child.ID), settings)
+
+	_, err = e.GetTenantSettings(ctx, lb)
+	assert.ErrorIs(t, err, ErrInvalidType)
+
+	_, err = e.UpdateTenantSettings(ctx, actor, root, types.TenantSettings{AllowGroupBindings: false, InheritParentRoles: true})
+	require.NoError(t, err)
+
+	_, err = e.UpdateTenantSettings(ctx, actor, child, types.TenantSettings{AllowGroupBindings: true, InheritParentRoles: false})
+	require.NoError(t, err)
+
+	type input struct {
+		role    types.Resource
+		subject types.Resource
+	}
+
+	tc := []testingx.TestCase[input, types.RoleBinding]{
+		{
+			Name:  "ParentRoleDenied",
+			Input: input{role: rootRoleRes, subject: user},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+				assert.ErrorIs(t, res.Err, ErrDeniedByTenantSettings)
+			},
+		},
+		{
+			Name:  "GroupDeniedByParent",
+			Input: input{role: childRoleRes, subject: group},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+				assert.ErrorIs(t, res.Err, ErrDeniedByTenantSettings)
+			},
+		},
+		{
+			Name:  "Allowed",
+			Input: input{role: childRoleRes, subject: user},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.RoleBinding]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, lb.ID, res.Success.ResourceID)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, in input) testingx.TestResult[types.RoleBinding] {
+		rb, err := e.CreateRoleBinding(ctx, actor, lb, in.role, []types.RoleBindingSubject{{SubjectResource: in.subject}})
+
+		return testingx.TestResult[types.RoleBinding]{Success: rb, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
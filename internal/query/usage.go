@@ -0,0 +1,422 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/maps"
+
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// usageBufferSize is the number of allowed decisions which may be queued
+	// before new decisions are dropped.
+	usageBufferSize = 1024
+
+	// maxPendingUsage is the maximum number of distinct decisions resolved per flush.
+	maxPendingUsage = 10000
+
+	// maxUsageResources limits the number of resources walked when resolving
+	// the role-bindings granting a decision.
+	maxUsageResources = 32
+
+	usageFlushTimeout = time.Minute
+)
+
+// usageDecision is an allowed permission decision.
+type usageDecision struct {
+	subject  types.Resource
+	action   string
+	resource types.Resource
+}
+
+// usageTracker records allowed decisions so the role-bindings and roles which
+// granted them can be marked as used. Decisions are queued without blocking
+// permission checks, deduplicated, and resolved in the background on every
+// flush, so last used times are accurate to the flush interval.
+type usageTracker struct {
+	interval  time.Duration
+	decisions chan usageDecision
+}
+
+// recordUsage queues an allowed decision, it is dropped if the queue is full.
+func (e *engine) recordUsage(subject types.Resource, action string, resource types.Resource) {
+	if e.usage == nil {
+		return
+	}
+
+	select {
+	case e.usage.decisions <- usageDecision{subject: subject, action: action, resource: resource}:
+	default:
+		e.logger.Debugw("usage queue full, dropping decision", "subject", subject.ID, "action", action, "resource", resource.ID)
+	}
+}
+
+// trackUsage processes queued decisions until ctx is canceled, the decisions
+// queued and pending then are flushed before returning.
+func (e *engine) trackUsage(ctx context.Context) {
+	ticker := time.NewTicker(e.usage.interval)
+	defer ticker.Stop()
+
+	pending := make(map[usageDecision]struct{})
+
+	flush := func(now time.Time) {
+		if len(pending) == 0 {
+			return
+		}
+
+		flushCtx, cancel := context.WithTimeout(context.Background(), usageFlushTimeout)
+		defer cancel()
+
+		e.flushUsage(flushCtx, now, pending)
+
+		pending = make(map[usageDecision]struct{})
+	}
+
+	for {
+		select {
+		case decision := <-e.usage.decisions:
+			if len(pending) < maxPendingUsage {
+				pending[decision] = struct{}{}
+			}
+		case now := <-ticker.C:
+			flush(now)
+		case <-ctx.Done():
+			for len(e.usage.decisions) != 0 && len(pending) < maxPendingUsage {
+				pending[<-e.usage.decisions] = struct{}{}
+			}
+
+			flush(time.Now())
+
+			return
+		}
+	}
+}
+
+func (e *engine) flushUsage(ctx context.Context, usedAt time.Time, decisions map[usageDecision]struct{}) {
+	ctx, span := e.tracer.Start(ctx, "engine.flushUsage", trace.WithAttributes(attribute.Int("decisions", len(decisions))))
+	defer span.End()
+
+	bindingActions := make(map[gidx.PrefixedID]map[string]struct{})
+	roleIDs := make(map[gidx.PrefixedID]struct{})
+
+	for decision := range decisions {
+		if err := e.resolveGrantingBindings(ctx, decision, bindingActions, roleIDs); err != nil {
+			span.RecordError(err)
+			e.logger.Warnw("failed to resolve role-bindings for decision", "error", err,
+				"subject", decision.subject.ID, "action", decision.action, "resource", decision.resource.ID)
+		}
+	}
+
+	if err := e.store.RecordRoleBindingsUsed(ctx, usedAt, maps.Keys(bindingActions)...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		e.logger.Errorw("failed to record role-binding usage", "error", err)
+	}
+
+	usedActions := make(map[gidx.PrefixedID][]string, len(bindingActions))
+
+	for rbID, actions := range bindingActions {
+		usedActions[rbID] = maps.Keys(actions)
+	}
+
+	if err := e.store.RecordRoleBindingActionsUsed(ctx, usedAt, usedActions); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		e.logger.Errorw("failed to record role-binding action usage", "error", err)
+	}
+
+	if err := e.store.RecordRolesUsed(ctx, usedAt, maps.Keys(roleIDs)...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		e.logger.Errorw("failed to record role usage", "error", err)
+	}
+}
+
+// resolveGrantingBindings finds the role-bindings, the actions they granted, and
+// their roles, which grant the decision. Grants on the resource are checked
+// first, then grants on the resources the action is inherited from.
+func (e *engine) resolveGrantingBindings(
+	ctx context.Context,
+	decision usageDecision,
+	bindingActions map[gidx.PrefixedID]map[string]struct{},
+	roleIDs map[gidx.PrefixedID]struct{},
+) error {
+	type node struct {
+		resource types.Resource
+		action   string
+	}
+
+	queue := []node{{decision.resource, decision.action}}
+	visited := make(map[node]bool)
+
+	for len(queue) != 0 && len(visited) < maxUsageResources {
+		n := queue[0]
+		queue = queue[1:]
+
+		if visited[n] {
+			continue
+		}
+
+		visited[n] = true
+
+		resType, ok := e.schemaTypeMap[n.resource.Type]
+		if !ok {
+			continue
+		}
+
+		for _, action := range resType.Actions {
+			if action.Name != n.action {
+				continue
+			}
+
+			for _, cond := range action.Conditions {
+				switch {
+				case cond.RoleBindingV2 != nil:
+					if err := e.resolveGrants(ctx, decision.subject, n.resource, n.action, bindingActions, roleIDs); err != nil {
+						return err
+					}
+				case cond.RelationshipAction != nil:
+					parents, err := e.readRelationships(ctx, &pb.RelationshipFilter{
+						ResourceType:       e.namespaced(n.resource.Type),
+						OptionalResourceId: n.resource.ID.String(),
+						OptionalRelation:   cond.RelationshipAction.Relation,
+					})
+					if err != nil {
+						return err
+					}
+
+					for _, rel := range parents {
+						parent, err := e.NewResourceFromIDString(rel.Subject.Object.ObjectId)
+						if err != nil {
+							continue
+						}
+
+						queue = append(queue, node{parent, cond.RelationshipAction.ActionName})
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolveGrants checks which role-bindings granted on the resource give the subject the action.
+func (e *engine) resolveGrants(
+	ctx context.Context,
+	subject, resource types.Resource,
+	action string,
+	bindingActions map[gidx.PrefixedID]map[string]struct{},
+	roleIDs map[gidx.PrefixedID]struct{},
+) error {
+	grants, err := e.readRelationships(ctx, &pb.RelationshipFilter{
+		ResourceType:       e.namespaced(resource.Type),
+		OptionalResourceId: resource.ID.String(),
+		OptionalRelation:   iapl.GrantRelationship,
+		OptionalSubjectFilter: &pb.SubjectFilter{
+			SubjectType: e.namespaced(e.rbac.RoleBindingResource.Name),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, grant := range grants {
+		rbID, err := gidx.Parse(grant.Subject.Object.ObjectId)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := bindingActions[rbID][action]; ok {
+			continue
+		}
+
+		err = e.checkPermission(ctx, &pb.CheckPermissionRequest{
+			Consistency: &pb.Consistency{
+				Requirement: &pb.Consistency_MinimizeLatency{MinimizeLatency: true},
+			},
+			Resource: &pb.ObjectReference{
+				ObjectType: e.namespaced(e.rbac.RoleBindingResource.Name),
+				ObjectId:   rbID.String(),
+			},
+			Permission: action,
+			Subject: &pb.SubjectReference{
+				Object: resourceToSpiceDBRef(e.namespace, subject),
+			},
+		})
+		if err != nil {
+			continue
+		}
+
+		if _, ok := bindingActions[rbID]; !ok {
+			bindingActions[rbID] = make(map[string]struct{})
+		}
+
+		bindingActions[rbID][action] = struct{}{}
+
+		roleRels, err := e.readRelationships(ctx, &pb.RelationshipFilter{
+			ResourceType:       e.namespaced(e.rbac.RoleBindingResource.Name),
+			OptionalResourceId: rbID.String(),
+			OptionalRelation:   iapl.RolebindingRoleRelation,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, rel := range roleRels {
+			if roleID, err := gidx.Parse(rel.Subject.Object.ObjectId); err == nil {
+				roleIDs[roleID] = struct{}{}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *engine) ListStaleRoleBindings(ctx context.Context, resource types.Resource, unusedSince time.Time) ([]types.RoleBinding, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.ListStaleRoleBindings",
+		trace.WithAttributes(
+			attribute.Stringer("resource_id", resource.ID),
+			attribute.String("unused_since", unusedSince.Format(time.RFC3339)),
+		),
+	)
+	defer span.End()
+
+	dbBindings, err := e.store.ListStaleRoleBindings(ctx, resource.ID, unusedSince)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	bindings := make([]types.RoleBinding, 0, len(dbBindings))
+
+	for _, dbBinding := range dbBindings {
+		rbRes, err := e.NewResourceFromID(dbBinding.ID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, err
+		}
+
+		rb, err := e.GetRoleBinding(ctx, rbRes)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, err
+		}
+
+		bindings = append(bindings, rb)
+	}
+
+	return bindings, nil
+}
+
+// SuggestRoleBindingReductions compares the actions granted by the role-bindings
+// on a resource with the actions used since the given time. Role-bindings
+// created after that time are skipped, as their usage is not yet known.
+func (e *engine) SuggestRoleBindingReductions(ctx context.Context, resource types.Resource, usedSince time.Time) ([]types.RoleBindingSuggestion, error) {
+	ctx, span := e.tracer.Start(
+		ctx, "engine.SuggestRoleBindingReductions",
+		trace.WithAttributes(
+			attribute.Stringer("resource_id", resource.ID),
+			attribute.String("used_since", usedSince.Format(time.RFC3339)),
+		),
+	)
+	defer span.End()
+
+	rbs, err := e.ListRoleBindings(ctx, resource, nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	candidates := make([]types.RoleBinding, 0, len(rbs))
+
+	for _, rb := range rbs {
+		if rb.CreatedAt.Before(usedSince) {
+			candidates = append(candidates, rb)
+		}
+	}
+
+	rbIDs := make([]gidx.PrefixedID, len(candidates))
+
+	for i, rb := range candidates {
+		rbIDs[i] = rb.ID
+	}
+
+	used, err := e.store.ListRoleBindingActionsUsed(ctx, usedSince, rbIDs...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	roleActions := make(map[gidx.PrefixedID][]string)
+	suggestions := []types.RoleBindingSuggestion{}
+
+	for _, rb := range candidates {
+		actions, ok := roleActions[rb.RoleID]
+		if !ok {
+			roleRes, err := e.NewResourceFromID(rb.RoleID)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+
+				return nil, err
+			}
+
+			role, err := e.GetRoleV2(ctx, roleRes)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+
+				return nil, err
+			}
+
+			actions = role.Actions
+			roleActions[rb.RoleID] = actions
+		}
+
+		usedActions := make(map[string]struct{}, len(used[rb.ID]))
+
+		for _, action := range used[rb.ID] {
+			usedActions[action] = struct{}{}
+		}
+
+		suggestion := types.RoleBindingSuggestion{
+			RoleBinding:   rb,
+			UsedActions:   []string{},
+			UnusedActions: []string{},
+		}
+
+		for _, action := range actions {
+			if _, ok := usedActions[action]; ok {
+				suggestion.UsedActions = append(suggestion.UsedActions, action)
+			} else {
+				suggestion.UnusedActions = append(suggestion.UnusedActions, action)
+			}
+		}
+
+		if len(suggestion.UnusedActions) != 0 {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	return suggestions, nil
+}
@@ -0,0 +1,93 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestSuggestRoleBindingReductions(t *testing.T) {
+	namespace := "testsuggestions"
+	ctx := context.Background()
+	e := testEngine(ctx, t, namespace, rbacv2TestPolicy())
+
+	tenant, err := e.NewResourceFromIDString("tnntten-root")
+	require.NoError(t, err)
+	actor, err := e.NewResourceFromIDString("idntusr-actor")
+	require.NoError(t, err)
+	subj, err := e.NewResourceFromIDString("idntusr-subj")
+	require.NoError(t, err)
+
+	role, err := e.CreateRoleV2(ctx, actor, tenant, "lb_viewer", []string{"loadbalancer_list", "loadbalancer_get"})
+	require.NoError(t, err)
+
+	roleRes, err := e.NewResourceFromID(role.ID)
+	require.NoError(t, err)
+
+	subjects := []types.RoleBindingSubject{{SubjectResource: subj}}
+
+	partlyUsed, err := e.CreateRoleBinding(ctx, actor, tenant, roleRes, subjects)
+	require.NoError(t, err)
+
+	fullyUsed, err := e.CreateRoleBinding(ctx, actor, tenant, roleRes, subjects)
+	require.NoError(t, err)
+
+	unused, err := e.CreateRoleBinding(ctx, actor, tenant, roleRes, subjects)
+	require.NoError(t, err)
+
+	createdAt := time.Now()
+
+	err = e.store.RecordRoleBindingActionsUsed(ctx, createdAt.Add(time.Second), map[gidx.PrefixedID][]string{
+		partlyUsed.ID: {"loadbalancer_get"},
+		fullyUsed.ID:  {"loadbalancer_get", "loadbalancer_list"},
+	})
+	require.NoError(t, err)
+
+	tc := []testingx.TestCase[time.Time, []types.RoleBindingSuggestion]{
+		{
+			Name:  "BeforeCreation",
+			Input: createdAt.Add(-time.Hour),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBindingSuggestion]) {
+				require.NoError(t, res.Err)
+				assert.Empty(t, res.Success)
+			},
+		},
+		{
+			Name:  "AfterCreation",
+			Input: createdAt,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBindingSuggestion]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 2)
+
+				suggestions := make(map[gidx.PrefixedID]types.RoleBindingSuggestion, len(res.Success))
+
+				for _, s := range res.Success {
+					suggestions[s.RoleBinding.ID] = s
+				}
+
+				require.Contains(t, suggestions, partlyUsed.ID)
+				assert.Equal(t, []string{"loadbalancer_get"}, suggestions[partlyUsed.ID].UsedActions)
+				assert.Equal(t, []string{"loadbalancer_list"}, suggestions[partlyUsed.ID].UnusedActions)
+
+				require.Contains(t, suggestions, unused.ID)
+				assert.Empty(t, suggestions[unused.ID].UsedActions)
+				assert.ElementsMatch(t, role.Actions, suggestions[unused.ID].UnusedActions)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, usedSince time.Time) testingx.TestResult[[]types.RoleBindingSuggestion] {
+		suggestions, err := e.SuggestRoleBindingReductions(ctx, tenant, usedSince)
+
+		return testingx.TestResult[[]types.RoleBindingSuggestion]{Success: suggestions, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+}
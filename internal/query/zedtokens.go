@@ -79,11 +79,11 @@ func (e *engine) updateRelationshipZedTokens(ctx context.Context, rels []types.R
 
 	for resourceID := range resourceIDMap {
 		if err := e.upsertZedToken(dbCtx, resourceID, zedToken); err != nil {
-			e.logger.Warnw("error upserting ZedToken", "error", err.Error(), "resource_id", resourceID)
+			e.contextLogger(ctx).Warnw("error upserting ZedToken", "error", err.Error(), "resource_id", resourceID)
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
 
-			logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+			logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 
 			return
 		}
@@ -93,25 +93,31 @@ func (e *engine) updateRelationshipZedTokens(ctx context.Context, rels []types.R
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 
-		logRollbackErr(e.logger, e.store.RollbackContext(dbCtx))
+		logRollbackErr(e.contextLogger(dbCtx), e.store.RollbackContext(dbCtx))
 	}
 }
 
-// determineConsistency produces a consistency strategy based on whether a ZedToken exists for a
-// given resource. If a ZedToken is available for the resource, at_least_as_fresh is used with the
-// retrieved ZedToken. If no such token is found, minimize_latency is used. This ensures that if
-// NATS is not working or available for some reason, we can still make permissions checks (albeit
-// in a degraded state).
-func (e *engine) determineConsistency(ctx context.Context, resource types.Resource) (*pb.Consistency, string) {
-	resourceID := resource.ID
+// determineConsistency produces a consistency strategy based on whether a ZedToken exists for the
+// given resources. If a ZedToken is available for any of the resources, at_least_as_fresh is used
+// with the latest retrieved ZedToken. If no such token is found, minimize_latency is used. This
+// ensures that if NATS is not working or available for some reason, we can still make permissions
+// checks (albeit in a degraded state).
+func (e *engine) determineConsistency(ctx context.Context, resources ...types.Resource) (*pb.Consistency, string) {
+	resourceIDs := make([]gidx.PrefixedID, len(resources))
+	resourceIDStrs := make([]string, len(resources))
+
+	for i, resource := range resources {
+		resourceIDs[i] = resource.ID
+		resourceIDStrs[i] = resource.ID.String()
+	}
 
 	_, span := e.tracer.Start(
 		ctx,
 		"determineConsistency",
 		trace.WithAttributes(
-			attribute.Stringer(
+			attribute.StringSlice(
 				"permissions.resource",
-				resourceID,
+				resourceIDStrs,
 			),
 		),
 	)
@@ -126,11 +132,11 @@ func (e *engine) determineConsistency(ctx context.Context, resource types.Resour
 
 	consistencyName := consistencyMinimizeLatency
 
-	zedToken, err := e.store.GetLatestZedToken(ctx, resourceID)
+	zedToken, err := e.store.GetLatestZedToken(ctx, resourceIDs...)
 
 	switch {
 	case err != nil:
-		e.logger.Warnw("error getting ZedToken", "error", err.Error())
+		e.contextLogger(ctx).Warnw("error getting ZedToken", "error", err.Error())
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 	case zedToken != "":
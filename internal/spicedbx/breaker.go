@@ -0,0 +1,269 @@
+package spicedbx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultBreakerFailureThreshold is the default number of consecutive failed calls opening the circuit.
+	DefaultBreakerFailureThreshold = 5
+
+	// DefaultBreakerOpenTimeout is the default time the circuit stays open before a call is let through.
+	DefaultBreakerOpenTimeout = 10 * time.Second
+)
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets all calls through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects all calls.
+	BreakerOpen
+	// BreakerHalfOpen lets a single probing call through, closing the circuit if it succeeds.
+	BreakerHalfOpen
+)
+
+// String returns the name of the state.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+var spicedbBreakerState = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: "permissions_api",
+	Subsystem: "spicedb",
+	Name:      "circuit_state",
+	Help:      "State of the SpiceDB circuit breaker, 0 when closed, 1 when open and 2 when half-open.",
+})
+
+// BreakerConfig is the configuration of the SpiceDB circuit breaker.
+type BreakerConfig struct {
+	// Enabled enables the circuit breaker.
+	Enabled bool
+	// FailureThreshold is the number of consecutive calls failing with a
+	// transient error after which the circuit opens.
+	FailureThreshold int
+	// OpenTimeout is the time the circuit stays open before a probing call is let through.
+	OpenTimeout time.Duration
+}
+
+// Breaker is a circuit breaker for SpiceDB calls. Once SpiceDB is unavailable
+// for a number of consecutive calls the circuit opens and calls fail fast with
+// ErrorCircuitOpen, instead of piling up waiting for SpiceDB. After the open
+// timeout a single call probes SpiceDB, closing the circuit if it succeeds.
+type Breaker struct {
+	config BreakerConfig
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	now func() time.Time
+}
+
+// withDefaults returns the config with unset values replaced by their defaults.
+func (c BreakerConfig) withDefaults() BreakerConfig {
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = DefaultBreakerFailureThreshold
+	}
+
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = DefaultBreakerOpenTimeout
+	}
+
+	return c
+}
+
+// NewBreaker creates a new Breaker from the config.
+func NewBreaker(config BreakerConfig) *Breaker {
+	spicedbBreakerState.Set(float64(BreakerClosed))
+
+	return &Breaker{
+		config: config.withDefaults(),
+		now:    time.Now,
+	}
+}
+
+// SetConfig changes the failure threshold and open timeout of the breaker
+// without changing the state of the circuit. Enabled is ignored, a breaker
+// cannot be removed from a client.
+func (b *Breaker) SetConfig(config BreakerConfig) {
+	config = config.withDefaults()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.config.FailureThreshold = config.FailureThreshold
+	b.config.OpenTimeout = config.OpenTimeout
+}
+
+// State returns the current state of the circuit.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// setState changes the state of the circuit, b.mu must be held.
+func (b *Breaker) setState(state BreakerState) {
+	b.state = state
+
+	spicedbBreakerState.Set(float64(state))
+}
+
+// allow returns an error wrapping ErrorCircuitOpen when the call must be rejected.
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.config.OpenTimeout {
+			return fmt.Errorf("%w: after %d consecutive failures", ErrorCircuitOpen, b.failures)
+		}
+
+		b.setState(BreakerHalfOpen)
+		b.probing = true
+
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return fmt.Errorf("%w: waiting on probing call", ErrorCircuitOpen)
+		}
+
+		b.probing = true
+
+		return nil
+	default:
+		return nil
+	}
+}
+
+// record records the result of a call let through by allow.
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		b.failures++
+
+		if b.state == BreakerHalfOpen || b.failures >= b.config.FailureThreshold {
+			b.openedAt = b.now()
+			b.probing = false
+			b.setState(BreakerOpen)
+		}
+	case codes.Canceled:
+		// the caller gave up, which says nothing about SpiceDB
+		b.probing = false
+	default:
+		// any other response, including errors, means SpiceDB is reachable
+		b.failures = 0
+		b.probing = false
+		b.setState(BreakerClosed)
+	}
+}
+
+// HealthCheck returns an error while the circuit is not closed, reporting
+// the service as degraded.
+func (b *Breaker) HealthCheck(_ context.Context) error {
+	if state := b.State(); state != BreakerClosed {
+		return fmt.Errorf("%w: circuit %s", ErrorCircuitOpen, state)
+	}
+
+	return nil
+}
+
+// UnaryClientInterceptor rejects unary SpiceDB calls while the circuit is open.
+func (b *Breaker) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := b.allow(); err != nil {
+			return err
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		b.record(err)
+
+		return err
+	}
+}
+
+// StreamClientInterceptor rejects streaming SpiceDB calls while the circuit
+// is open, a stream is recorded once its first message or error is received.
+func (b *Breaker) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := b.allow(); err != nil {
+			return nil, err
+		}
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			b.record(err)
+
+			return nil, err
+		}
+
+		return &breakerClientStream{ClientStream: stream, breaker: b}, nil
+	}
+}
+
+// breakerClientStream records the first result received on the stream.
+type breakerClientStream struct {
+	grpc.ClientStream
+
+	breaker  *Breaker
+	recorded bool
+}
+
+func (s *breakerClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	if !s.recorded {
+		s.recorded = true
+
+		if errors.Is(err, io.EOF) {
+			s.breaker.record(nil)
+		} else {
+			s.breaker.record(err)
+		}
+	}
+
+	return err
+}
+
+// DialOptions returns the dial options installing the breaker interceptors on
+// a SpiceDB client, a nil Breaker returns no options.
+func (b *Breaker) DialOptions() []grpc.DialOption {
+	if b == nil {
+		return nil
+	}
+
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(b.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(b.StreamClientInterceptor()),
+	}
+}
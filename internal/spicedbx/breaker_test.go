@@ -0,0 +1,166 @@
+package spicedbx
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBreakerUnary(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	breaker := NewBreaker(BreakerConfig{Enabled: true, FailureThreshold: 2, OpenTimeout: time.Minute})
+	breaker.now = func() time.Time { return now }
+
+	var (
+		invoked int
+		callErr error
+	)
+
+	invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		invoked++
+
+		return callErr
+	}
+
+	call := func() error {
+		return breaker.UnaryClientInterceptor()(context.Background(), "/test.Breaker/Unary", nil, nil, nil, invoker)
+	}
+
+	// errors which are not transient do not open the circuit
+	callErr = status.Error(codes.InvalidArgument, "")
+
+	require.Equal(t, codes.InvalidArgument, status.Code(call()))
+	assert.Equal(t, BreakerClosed, breaker.State())
+
+	callErr = status.Error(codes.Unavailable, "")
+
+	require.Equal(t, codes.Unavailable, status.Code(call()))
+	assert.Equal(t, BreakerClosed, breaker.State())
+
+	require.Equal(t, codes.Unavailable, status.Code(call()))
+	assert.Equal(t, BreakerOpen, breaker.State())
+	assert.ErrorIs(t, breaker.HealthCheck(context.Background()), ErrorCircuitOpen)
+
+	// calls fail fast while open
+	assert.ErrorIs(t, call(), ErrorCircuitOpen)
+	assert.Equal(t, 3, invoked)
+
+	// a failed probe opens the circuit again
+	now = now.Add(time.Minute)
+
+	require.Equal(t, codes.Unavailable, status.Code(call()))
+	assert.Equal(t, BreakerOpen, breaker.State())
+	assert.Equal(t, 4, invoked)
+
+	assert.ErrorIs(t, call(), ErrorCircuitOpen)
+	assert.Equal(t, 4, invoked)
+
+	// a successful probe closes the circuit
+	now = now.Add(time.Minute)
+	callErr = nil
+
+	require.NoError(t, call())
+	assert.Equal(t, BreakerClosed, breaker.State())
+	assert.NoError(t, breaker.HealthCheck(context.Background()))
+	assert.Equal(t, 5, invoked)
+}
+
+func TestBreakerHalfOpenSingleProbe(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	breaker := NewBreaker(BreakerConfig{Enabled: true, FailureThreshold: 1, OpenTimeout: time.Minute})
+	breaker.now = func() time.Time { return now }
+
+	breaker.record(status.Error(codes.Unavailable, ""))
+	require.Equal(t, BreakerOpen, breaker.State())
+
+	now = now.Add(time.Minute)
+
+	require.NoError(t, breaker.allow())
+	assert.Equal(t, BreakerHalfOpen, breaker.State())
+
+	// only a single call probes SpiceDB
+	assert.ErrorIs(t, breaker.allow(), ErrorCircuitOpen)
+
+	// the probe being canceled lets another call probe
+	breaker.record(status.Error(codes.Canceled, ""))
+
+	assert.NoError(t, breaker.allow())
+}
+
+func TestBreakerSetConfig(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	breaker := NewBreaker(BreakerConfig{Enabled: true, FailureThreshold: 5, OpenTimeout: time.Minute})
+	breaker.now = func() time.Time { return now }
+
+	breaker.SetConfig(BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Second})
+
+	breaker.record(status.Error(codes.Unavailable, ""))
+	require.Equal(t, BreakerOpen, breaker.State())
+
+	now = now.Add(time.Second)
+
+	require.NoError(t, breaker.allow())
+	assert.Equal(t, BreakerHalfOpen, breaker.State())
+
+	// unset values are reset to their defaults
+	breaker.SetConfig(BreakerConfig{})
+	assert.Equal(t, DefaultBreakerFailureThreshold, breaker.config.FailureThreshold)
+	assert.Equal(t, DefaultBreakerOpenTimeout, breaker.config.OpenTimeout)
+}
+
+func TestBreakerStream(t *testing.T) {
+	t.Parallel()
+
+	breaker := NewBreaker(BreakerConfig{Enabled: true, FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	streams := []*testClientStream{
+		{errs: []error{io.EOF}},
+		{errs: []error{status.Error(codes.Unavailable, "")}},
+	}
+
+	var opened int
+
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream := streams[opened]
+		opened++
+
+		return stream, nil
+	}
+
+	open := func() (grpc.ClientStream, error) {
+		return breaker.StreamClientInterceptor()(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, "/test.Breaker/Stream", streamer)
+	}
+
+	// an empty stream is a success
+	stream, err := open()
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, stream.RecvMsg(nil), io.EOF)
+	assert.Equal(t, BreakerClosed, breaker.State())
+
+	stream, err = open()
+	require.NoError(t, err)
+
+	assert.Equal(t, codes.Unavailable, status.Code(stream.RecvMsg(nil)))
+	assert.Equal(t, BreakerOpen, breaker.State())
+
+	_, err = open()
+	assert.ErrorIs(t, err, ErrorCircuitOpen)
+	assert.Equal(t, 2, opened)
+}
@@ -0,0 +1,203 @@
+package spicedbx
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+)
+
+const (
+	// DefaultBudgetWindow is the default period over which SpiceDB calls of a caller are counted.
+	DefaultBudgetWindow = time.Minute
+
+	// internalCaller is the caller recorded for calls not made on behalf of a request, e.g. background jobs.
+	internalCaller = "internal"
+)
+
+var (
+	spicedbCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "permissions_api",
+		Subsystem: "spicedb",
+		Name:      "calls_total",
+		Help:      "Number of SpiceDB calls by caller and method.",
+	}, []string{"caller", "method"})
+
+	spicedbBudgetExceeded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "permissions_api",
+		Subsystem: "spicedb",
+		Name:      "budget_exceeded_total",
+		Help:      "Number of SpiceDB calls rejected because the caller exceeded its budget.",
+	}, []string{"caller"})
+)
+
+// BudgetConfig is the configuration for accounting SpiceDB calls per caller.
+type BudgetConfig struct {
+	// Enabled enables accounting of SpiceDB calls per caller.
+	Enabled bool
+	// Window is the period over which the calls of a caller are counted.
+	Window time.Duration
+	// RequestLimit, when set, is the maximum number of SpiceDB calls made for a single request.
+	RequestLimit int64
+	// WindowLimit, when set, is the maximum number of SpiceDB calls made for a caller within the window.
+	WindowLimit int64
+}
+
+type callAccountKey struct{}
+
+// CallAccount counts the SpiceDB calls made on behalf of a caller within a request.
+type CallAccount struct {
+	caller string
+	calls  atomic.Int64
+}
+
+// Caller returns the caller the calls are accounted to.
+func (a *CallAccount) Caller() string {
+	return a.caller
+}
+
+// Calls returns the number of SpiceDB calls made so far.
+func (a *CallAccount) Calls() int64 {
+	return a.calls.Load()
+}
+
+// WithCaller returns a context accounting SpiceDB calls made with it to the given caller.
+func WithCaller(ctx context.Context, caller string) (context.Context, *CallAccount) {
+	account := &CallAccount{caller: caller}
+
+	return context.WithValue(ctx, callAccountKey{}, account), account
+}
+
+func callAccountFromContext(ctx context.Context) *CallAccount {
+	account, _ := ctx.Value(callAccountKey{}).(*CallAccount)
+
+	return account
+}
+
+// Budget accounts SpiceDB calls per caller, within a request and across a
+// fixed window, and rejects calls once a configured budget is exceeded.
+type Budget struct {
+	config BudgetConfig
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[string]int64
+
+	now func() time.Time
+}
+
+// NewBudget creates a new Budget from the config.
+func NewBudget(config BudgetConfig) *Budget {
+	if config.Window <= 0 {
+		config.Window = DefaultBudgetWindow
+	}
+
+	return &Budget{
+		config:      config,
+		windowStart: time.Now(),
+		counts:      make(map[string]int64),
+		now:         time.Now,
+	}
+}
+
+// WindowCalls returns the number of SpiceDB calls made for the caller in the current window.
+func (b *Budget) WindowCalls(caller string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rotate()
+
+	return b.counts[caller]
+}
+
+// WindowLimit returns the maximum number of SpiceDB calls per caller in a window, 0 when unlimited.
+func (b *Budget) WindowLimit() int64 {
+	return b.config.WindowLimit
+}
+
+// rotate starts a new window once the current one has passed, b.mu must be held.
+func (b *Budget) rotate() {
+	now := b.now()
+
+	if now.Sub(b.windowStart) >= b.config.Window {
+		b.windowStart = now
+		b.counts = make(map[string]int64, len(b.counts))
+	}
+}
+
+// take accounts a call for the caller of the context, returning an error
+// wrapping ErrorBudgetExceeded when the call is over budget.
+func (b *Budget) take(ctx context.Context, method string) error {
+	account := callAccountFromContext(ctx)
+	if account == nil {
+		spicedbCalls.WithLabelValues(internalCaller, method).Inc()
+
+		return nil
+	}
+
+	spicedbCalls.WithLabelValues(account.caller, method).Inc()
+
+	requestCalls := account.calls.Add(1)
+
+	b.mu.Lock()
+
+	b.rotate()
+
+	b.counts[account.caller]++
+	windowCalls := b.counts[account.caller]
+
+	b.mu.Unlock()
+
+	switch {
+	case b.config.RequestLimit > 0 && requestCalls > b.config.RequestLimit:
+		spicedbBudgetExceeded.WithLabelValues(account.caller).Inc()
+
+		return fmt.Errorf("%w: more than %d calls in request", ErrorBudgetExceeded, b.config.RequestLimit)
+	case b.config.WindowLimit > 0 && windowCalls > b.config.WindowLimit:
+		spicedbBudgetExceeded.WithLabelValues(account.caller).Inc()
+
+		return fmt.Errorf("%w: more than %d calls in %s", ErrorBudgetExceeded, b.config.WindowLimit, b.config.Window)
+	default:
+		return nil
+	}
+}
+
+// UnaryClientInterceptor accounts unary SpiceDB calls.
+func (b *Budget) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := b.take(ctx, method); err != nil {
+			return err
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor accounts streaming SpiceDB calls, each stream counts as a single call.
+func (b *Budget) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := b.take(ctx, method); err != nil {
+			return nil, err
+		}
+
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// DialOptions returns the dial options installing the budget interceptors on
+// a SpiceDB client, a nil Budget returns no options.
+func (b *Budget) DialOptions() []grpc.DialOption {
+	if b == nil {
+		return nil
+	}
+
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(b.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(b.StreamClientInterceptor()),
+	}
+}
@@ -0,0 +1,71 @@
+package spicedbx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestBudget(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	budget := NewBudget(BudgetConfig{
+		Enabled:      true,
+		Window:       time.Minute,
+		RequestLimit: 3,
+		WindowLimit:  4,
+	})
+	budget.now = func() time.Time { return now }
+
+	interceptor := budget.UnaryClientInterceptor()
+
+	var invoked int
+
+	invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		invoked++
+
+		return nil
+	}
+
+	call := func(ctx context.Context) error {
+		return interceptor(ctx, "/authzed.api.v1.PermissionsService/CheckPermission", nil, nil, nil, invoker)
+	}
+
+	// calls without a caller are not limited
+	for i := 0; i < 5; i++ {
+		require.NoError(t, call(context.Background()))
+	}
+
+	ctx, account := WithCaller(context.Background(), "idntusr-abc")
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, call(ctx))
+	}
+
+	assert.ErrorIs(t, call(ctx), ErrorBudgetExceeded)
+	assert.Equal(t, int64(4), account.Calls())
+	assert.Equal(t, 8, invoked)
+
+	// a new request is limited by the window
+	ctx, _ = WithCaller(context.Background(), "idntusr-abc")
+
+	assert.ErrorIs(t, call(ctx), ErrorBudgetExceeded)
+	assert.Equal(t, int64(5), budget.WindowCalls("idntusr-abc"))
+
+	// other callers have their own budget
+	otherCtx, _ := WithCaller(context.Background(), "idntusr-def")
+
+	require.NoError(t, call(otherCtx))
+
+	// the window is reset once it passed
+	now = now.Add(time.Minute)
+
+	assert.Equal(t, int64(0), budget.WindowCalls("idntusr-abc"))
+	require.NoError(t, call(ctx))
+}
@@ -4,6 +4,8 @@ package spicedbx
 import (
 	"context"
 	"fmt"
+	"slices"
+	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/authzed/authzed-go/v1"
@@ -11,6 +13,8 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+
+	"go.infratographer.com/permissions-api/internal/policysource"
 )
 
 // Config values for a SpiceDB connection
@@ -21,10 +25,54 @@ type Config struct {
 	VerifyCA  bool `mapstruct:"verifyca"`
 	Prefix    string
 	PolicyDir string
+	Budget    BudgetConfig
+	Retry     RetryConfig
+	Breaker   BreakerConfig
+	Endpoints EndpointsConfig
+
+	// PolicyReloadInterval is how often the policy directory is checked for
+	// changes by the server, which are then loaded. Zero disables it.
+	PolicyReloadInterval time.Duration
+
+	// PolicySource loads the policy from a remote URL instead of PolicyDir,
+	// checked for changes every PolicyReloadInterval.
+	PolicySource policysource.Config
+
+	// SchemaCheck is what the server does when the schema generated from its
+	// policy differs from the schema applied to SpiceDB, see SchemaCheckWarn
+	// and SchemaCheckBlock.
+	SchemaCheck string
+
+	// Namespaces are additional namespaces served alongside the default one,
+	// each with its own policy and SpiceDB schema prefix.
+	Namespaces []NamespaceConfig
+
+	// SlowCallThreshold, when set, logs SpiceDB calls taking longer than it
+	// with their request, see SlowCallLog.
+	SlowCallThreshold time.Duration
+}
+
+// NamespaceConfig configures an additional namespace served by the server.
+type NamespaceConfig struct {
+	// Name is the namespace, used as the SpiceDB schema prefix and to route
+	// API requests to the namespace.
+	Name string
+	// PolicyDir is the directory the policy of the namespace is loaded from.
+	PolicyDir string
 }
 
-// NewClient returns a new spicedb/authzed client
-func NewClient(cfg Config, enableTracing bool) (*authzed.Client, error) {
+const (
+	// SchemaCheckWarn logs a warning when the schemas differ.
+	SchemaCheckWarn = "warn"
+	// SchemaCheckBlock logs a warning and refuses to become ready while the schemas differ.
+	SchemaCheckBlock = "block"
+)
+
+// NewClient returns a new spicedb/authzed client recording metrics of all
+// calls, applying the consistency requested with WithConsistency and retrying idempotent calls as configured. Additional dial options,
+// e.g. Budget, Breaker or SlowCallLog interceptors, are installed before the retries so
+// they see every logical call once.
+func NewClient(cfg Config, enableTracing bool, dialOpts ...grpc.DialOption) (*authzed.Client, error) {
 	clientOpts := []grpc.DialOption{}
 
 	if cfg.Insecure {
@@ -54,13 +102,43 @@ func NewClient(cfg Config, enableTracing bool) (*authzed.Client, error) {
 		}
 	}
 
+	// endpoint health probes connect with the same credentials
+	probeOpts := slices.Clone(clientOpts)
+
 	if enableTracing {
 		clientOpts = append(clientOpts,
 			grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 		)
 	}
 
-	return authzed.NewClient(cfg.Endpoint, clientOpts...)
+	clientOpts = append(clientOpts,
+		grpc.WithChainUnaryInterceptor(metricsUnaryClientInterceptor, consistencyUnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(metricsStreamClientInterceptor, consistencyStreamClientInterceptor),
+	)
+
+	clientOpts = append(clientOpts, dialOpts...)
+
+	clientOpts = append(clientOpts, cfg.Retry.DialOptions()...)
+
+	if len(cfg.Endpoints.Fallbacks) == 0 {
+		return authzed.NewClient(cfg.Endpoint, clientOpts...)
+	}
+
+	endpoints, err := newEndpointSet(cfg, probeOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	clientOpts = append(clientOpts, endpoints.dialOptions()...)
+
+	client, err := authzed.NewClient(endpoints.target(), clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	go endpoints.run()
+
+	return client, nil
 }
 
 // Healthcheck reads the schema to check if the connection is working
@@ -0,0 +1,170 @@
+package spicedbx
+
+import (
+	"context"
+	"sync"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+)
+
+type consistencyKey struct{}
+
+// Consistency carries the consistency requested by a caller through the
+// SpiceDB calls made for a request, and records the revision of the
+// relationships written by them, so callers can read their own writes on
+// later requests.
+type Consistency struct {
+	atLeastAsFresh string
+
+	mu      sync.Mutex
+	written string
+}
+
+// WithConsistency returns a context evaluating the SpiceDB reads made with it
+// at least as fresh as the given token, an empty token leaves reads unchanged.
+// Tokens of relationship writes made with the context are recorded.
+func WithConsistency(ctx context.Context, token string) (context.Context, *Consistency) {
+	consistency := &Consistency{atLeastAsFresh: token}
+
+	return context.WithValue(ctx, consistencyKey{}, consistency), consistency
+}
+
+// ConsistencyFromContext returns the consistency of the context, nil if none was set.
+func ConsistencyFromContext(ctx context.Context) *Consistency {
+	consistency, _ := ctx.Value(consistencyKey{}).(*Consistency)
+
+	return consistency
+}
+
+// AtLeastAsFresh returns the token reads are evaluated at least as fresh as.
+func (c *Consistency) AtLeastAsFresh() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.atLeastAsFresh
+}
+
+// Written returns the token of the last relationship write, empty if nothing was written.
+func (c *Consistency) Written() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.written
+}
+
+func (c *Consistency) record(token *v1.ZedToken) {
+	if token == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.written = token.Token
+}
+
+// consistencyRequest is implemented by all SpiceDB read requests.
+type consistencyRequest interface {
+	GetConsistency() *v1.Consistency
+}
+
+// apply sets the requested consistency on a read request. Reads already
+// required to be at least as fresh as another token are made fully
+// consistent, as tokens can not be compared.
+func (c *Consistency) apply(req any) {
+	if c.atLeastAsFresh == "" {
+		return
+	}
+
+	read, ok := req.(consistencyRequest)
+	if !ok {
+		return
+	}
+
+	consistency := &v1.Consistency{
+		Requirement: &v1.Consistency_AtLeastAsFresh{
+			AtLeastAsFresh: &v1.ZedToken{Token: c.atLeastAsFresh},
+		},
+	}
+
+	if current := read.GetConsistency(); current != nil {
+		switch current.Requirement.(type) {
+		case *v1.Consistency_FullyConsistent:
+			return
+		case *v1.Consistency_AtLeastAsFresh, *v1.Consistency_AtExactSnapshot:
+			consistency = &v1.Consistency{
+				Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true},
+			}
+		}
+	}
+
+	switch r := req.(type) {
+	case *v1.CheckPermissionRequest:
+		r.Consistency = consistency
+	case *v1.CheckBulkPermissionsRequest:
+		r.Consistency = consistency
+	case *v1.ExpandPermissionTreeRequest:
+		r.Consistency = consistency
+	case *v1.ReadRelationshipsRequest:
+		r.Consistency = consistency
+	case *v1.LookupResourcesRequest:
+		r.Consistency = consistency
+	case *v1.LookupSubjectsRequest:
+		r.Consistency = consistency
+	}
+}
+
+// consistencyUnaryClientInterceptor applies the consistency of the context to
+// reads and records the tokens of writes.
+func consistencyUnaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	consistency := ConsistencyFromContext(ctx)
+	if consistency == nil {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	consistency.apply(req)
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err != nil {
+		return err
+	}
+
+	switch resp := reply.(type) {
+	case *v1.WriteRelationshipsResponse:
+		consistency.record(resp.WrittenAt)
+	case *v1.DeleteRelationshipsResponse:
+		consistency.record(resp.DeletedAt)
+	}
+
+	return nil
+}
+
+// consistencyStreamClientInterceptor applies the consistency of the context
+// to streaming reads.
+func consistencyStreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	consistency := ConsistencyFromContext(ctx)
+	if consistency == nil {
+		return stream, nil
+	}
+
+	return &consistencyClientStream{ClientStream: stream, consistency: consistency}, nil
+}
+
+type consistencyClientStream struct {
+	grpc.ClientStream
+
+	consistency *Consistency
+}
+
+func (s *consistencyClientStream) SendMsg(m any) error {
+	s.consistency.apply(m)
+
+	return s.ClientStream.SendMsg(m)
+}
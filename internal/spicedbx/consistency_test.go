@@ -0,0 +1,56 @@
+package spicedbx
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestConsistency(t *testing.T) {
+	t.Parallel()
+
+	invoker := func(_ context.Context, _ string, _, reply any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		if resp, ok := reply.(*v1.WriteRelationshipsResponse); ok {
+			resp.WrittenAt = &v1.ZedToken{Token: "written"}
+		}
+
+		return nil
+	}
+
+	call := func(ctx context.Context, req, reply any) {
+		require.NoError(t, consistencyUnaryClientInterceptor(ctx, "/test", req, reply, nil, invoker))
+	}
+
+	// requests without a caller token are unchanged
+	ctx, consistency := WithConsistency(context.Background(), "")
+
+	req := &v1.CheckPermissionRequest{}
+
+	call(ctx, req, &v1.CheckPermissionResponse{})
+	assert.Nil(t, req.Consistency)
+
+	call(ctx, &v1.WriteRelationshipsRequest{}, &v1.WriteRelationshipsResponse{})
+	assert.Equal(t, "written", consistency.Written())
+
+	ctx, _ = WithConsistency(context.Background(), "caller")
+
+	// minimize latency reads are at least as fresh as the caller token
+	req = &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: true}},
+	}
+
+	call(ctx, req, &v1.CheckPermissionResponse{})
+	assert.Equal(t, "caller", req.Consistency.GetAtLeastAsFresh().GetToken())
+
+	// reads at least as fresh as another token are fully consistent
+	req = &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: &v1.ZedToken{Token: "resource"}}},
+	}
+
+	call(ctx, req, &v1.CheckPermissionResponse{})
+	assert.True(t, req.Consistency.GetFullyConsistent())
+}
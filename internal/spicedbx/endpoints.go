@@ -0,0 +1,220 @@
+package spicedbx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+const (
+	// DefaultProbeInterval is the default interval at which the health of SpiceDB endpoints is probed.
+	DefaultProbeInterval = 5 * time.Second
+
+	// probeTimeout is the maximum time to wait for an endpoint to report its health.
+	probeTimeout = 2 * time.Second
+
+	// endpointsScheme is the resolver scheme of clients using several endpoints.
+	endpointsScheme = "spicedb-endpoints"
+
+	roundRobinServiceConfig = `{"loadBalancingConfig":[{"round_robin":{}}]}`
+)
+
+var spicedbEndpointHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "permissions_api",
+	Subsystem: "spicedb",
+	Name:      "endpoint_healthy",
+	Help:      "Whether a SpiceDB endpoint reported itself serving on the last health probe.",
+}, []string{"endpoint"})
+
+// EndpointsConfig configures the use of several SpiceDB endpoints. The
+// primary endpoint is used as long as it is healthy, calls fail over to the
+// first healthy fallback endpoint otherwise. With Balance, calls are spread
+// across all healthy endpoints instead.
+type EndpointsConfig struct {
+	// Fallbacks are the SpiceDB endpoints (host:port) used when the primary endpoint is unhealthy.
+	Fallbacks []string
+	// Balance spreads calls across all healthy endpoints.
+	Balance bool
+	// ProbeInterval is the interval at which the health of the endpoints is probed.
+	ProbeInterval time.Duration
+}
+
+// endpointSet probes the health of SpiceDB endpoints using the gRPC health
+// service and points the client at the healthy ones.
+type endpointSet struct {
+	endpoints []string
+	balance   bool
+	interval  time.Duration
+
+	probes   []healthpb.HealthClient
+	resolver *manual.Resolver
+
+	healthy []bool
+	current []string
+
+	// publish updates the addresses the client connects to.
+	publish func(addresses []string)
+}
+
+func newEndpointSet(cfg Config, probeOpts []grpc.DialOption) (*endpointSet, error) {
+	s := &endpointSet{
+		endpoints: append([]string{cfg.Endpoint}, cfg.Endpoints.Fallbacks...),
+		balance:   cfg.Endpoints.Balance,
+		interval:  cfg.Endpoints.ProbeInterval,
+		resolver:  manual.NewBuilderWithScheme(endpointsScheme),
+	}
+
+	if s.interval <= 0 {
+		s.interval = DefaultProbeInterval
+	}
+
+	for _, endpoint := range s.endpoints {
+		conn, err := grpc.Dial(endpoint, probeOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to spicedb endpoint %s: %w", endpoint, err)
+		}
+
+		s.probes = append(s.probes, healthpb.NewHealthClient(conn))
+	}
+
+	// endpoints are considered healthy until probed
+	s.healthy = make([]bool, len(s.endpoints))
+
+	for i := range s.healthy {
+		s.healthy[i] = true
+	}
+
+	s.current = s.addresses()
+
+	s.resolver.InitialState(resolverState(s.current))
+
+	s.publish = func(addresses []string) {
+		s.resolver.UpdateState(resolverState(addresses))
+	}
+
+	return s, nil
+}
+
+// resolverState returns the resolver state of the endpoints. Every address
+// sets its own server name, as the client authority is the primary endpoint,
+// so TLS connections to a fallback endpoint verify the fallback's hostname.
+func resolverState(addresses []string) resolver.State {
+	state := resolver.State{}
+
+	for _, addr := range addresses {
+		state.Addresses = append(state.Addresses, resolver.Address{Addr: addr, ServerName: endpointHost(addr)})
+	}
+
+	return state
+}
+
+// endpointHost returns the host of a host:port endpoint.
+func endpointHost(endpoint string) string {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return endpoint
+	}
+
+	return host
+}
+
+// target returns the target to dial the client with.
+func (s *endpointSet) target() string {
+	return endpointsScheme + ":///" + s.endpoints[0]
+}
+
+// dialOptions returns the dial options resolving the target to the healthy endpoints.
+func (s *endpointSet) dialOptions() []grpc.DialOption {
+	opts := []grpc.DialOption{
+		grpc.WithResolvers(s.resolver),
+	}
+
+	if s.balance {
+		opts = append(opts, grpc.WithDefaultServiceConfig(roundRobinServiceConfig))
+	}
+
+	return opts
+}
+
+// addresses returns the endpoints calls are sent to, all healthy endpoints
+// when balancing and the first healthy endpoint otherwise. When no endpoint is
+// healthy all endpoints are returned, so calls are still attempted.
+func (s *endpointSet) addresses() []string {
+	var addresses []string
+
+	for i, endpoint := range s.endpoints {
+		if !s.healthy[i] {
+			continue
+		}
+
+		addresses = append(addresses, endpoint)
+
+		if !s.balance {
+			break
+		}
+	}
+
+	if len(addresses) == 0 {
+		return slices.Clone(s.endpoints)
+	}
+
+	return addresses
+}
+
+// probe checks the health of every endpoint and updates the addresses used by
+// the client if they changed.
+func (s *endpointSet) probe(ctx context.Context) {
+	for i, probe := range s.probes {
+		s.healthy[i] = probeEndpoint(ctx, probe)
+
+		healthy := 0.0
+		if s.healthy[i] {
+			healthy = 1
+		}
+
+		spicedbEndpointHealthy.WithLabelValues(s.endpoints[i]).Set(healthy)
+	}
+
+	if addresses := s.addresses(); !slices.Equal(addresses, s.current) {
+		s.current = addresses
+
+		s.publish(addresses)
+	}
+}
+
+// probeEndpoint reports whether the endpoint is serving the permissions service.
+func probeEndpoint(ctx context.Context, probe healthpb.HealthClient) bool {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	resp, err := probe.Check(ctx, &healthpb.HealthCheckRequest{
+		Service: v1.PermissionsService_ServiceDesc.ServiceName,
+	})
+	if err != nil {
+		return false
+	}
+
+	return resp.Status == healthpb.HealthCheckResponse_SERVING
+}
+
+// run probes the endpoints every interval for the lifetime of the process.
+func (s *endpointSet) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.probe(context.Background())
+
+		<-ticker.C
+	}
+}
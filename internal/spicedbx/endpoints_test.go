@@ -0,0 +1,142 @@
+package spicedbx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+// testHealthClient reports an endpoint serving or fails when unavailable.
+type testHealthClient struct {
+	healthpb.HealthClient
+
+	serving     bool
+	unavailable bool
+}
+
+func (c *testHealthClient) Check(context.Context, *healthpb.HealthCheckRequest, ...grpc.CallOption) (*healthpb.HealthCheckResponse, error) {
+	if c.unavailable {
+		return nil, status.Error(codes.Unavailable, "")
+	}
+
+	if c.serving {
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+	}
+
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+}
+
+func TestEndpointSetProbe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		balance bool
+		health  []*testHealthClient
+		want    []string
+	}{
+		{
+			name:   "PrimaryHealthy",
+			health: []*testHealthClient{{serving: true}, {serving: true}, {serving: true}},
+			want:   []string{"primary"},
+		},
+		{
+			name:   "PrimaryUnavailable",
+			health: []*testHealthClient{{unavailable: true}, {serving: false}, {serving: true}},
+			want:   []string{"third"},
+		},
+		{
+			name:    "Balance",
+			balance: true,
+			health:  []*testHealthClient{{serving: true}, {unavailable: true}, {serving: true}},
+			want:    []string{"primary", "third"},
+		},
+		{
+			name:   "NoneHealthy",
+			health: []*testHealthClient{{unavailable: true}, {unavailable: true}, {serving: false}},
+			want:   []string{"primary", "second", "third"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var published []string
+
+			s := &endpointSet{
+				endpoints: []string{"primary", "second", "third"},
+				balance:   tt.balance,
+				healthy:   []bool{true, true, true},
+				publish: func(addresses []string) {
+					published = addresses
+				},
+			}
+
+			for _, health := range tt.health {
+				s.probes = append(s.probes, health)
+			}
+
+			s.current = s.addresses()
+
+			s.probe(context.Background())
+
+			assert.Equal(t, tt.want, s.current)
+
+			if published != nil {
+				assert.Equal(t, tt.want, published)
+			}
+		})
+	}
+}
+
+func TestEndpointSetFailback(t *testing.T) {
+	t.Parallel()
+
+	primary := &testHealthClient{unavailable: true}
+
+	var published [][]string
+
+	s := &endpointSet{
+		endpoints: []string{"primary", "fallback"},
+		probes:    []healthpb.HealthClient{primary, &testHealthClient{serving: true}},
+		healthy:   []bool{true, true},
+		current:   []string{"primary"},
+		publish: func(addresses []string) {
+			published = append(published, addresses)
+		},
+	}
+
+	s.probe(context.Background())
+
+	primary.unavailable = false
+	primary.serving = true
+
+	s.probe(context.Background())
+	s.probe(context.Background())
+
+	// addresses are only published when they change
+	assert.Equal(t, [][]string{{"fallback"}, {"primary"}}, published)
+}
+
+func TestResolverStateServerName(t *testing.T) {
+	t.Parallel()
+
+	state := resolverState([]string{"spicedb-primary.example.com:50051", "spicedb-fallback.example.net:443", "10.0.0.1:50051"})
+
+	// connections to fallback endpoints must verify the fallback's own name,
+	// not the name of the primary endpoint the client was dialed with.
+	assert.Equal(t, []resolver.Address{
+		{Addr: "spicedb-primary.example.com:50051", ServerName: "spicedb-primary.example.com"},
+		{Addr: "spicedb-fallback.example.net:443", ServerName: "spicedb-fallback.example.net"},
+		{Addr: "10.0.0.1:50051", ServerName: "10.0.0.1"},
+	}, state.Addresses)
+}
@@ -5,4 +5,19 @@ import "errors"
 var (
 	// ErrorNoNamespace is returned when no namespace is provided with a query
 	ErrorNoNamespace = errors.New("no namespace provided")
+
+	// ErrorBudgetExceeded is returned when a caller exceeded its SpiceDB call budget
+	ErrorBudgetExceeded = errors.New("spicedb call budget exceeded")
+
+	// ErrorCircuitOpen is returned when SpiceDB calls are rejected while the circuit breaker is open
+	ErrorCircuitOpen = errors.New("spicedb circuit breaker open")
+
+	// ErrorUnknownResourceType is returned when a resource type is not defined by the policy
+	ErrorUnknownResourceType = errors.New("unknown resource type")
+
+	// ErrorInvalidSchema is returned when a schema cannot be split into its definitions
+	ErrorInvalidSchema = errors.New("invalid schema")
+
+	// ErrorSchemaMismatch is returned when the schema applied to SpiceDB is not the schema generated from the policy
+	ErrorSchemaMismatch = errors.New("spicedb schema does not match the policy")
 )
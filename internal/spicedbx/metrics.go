@@ -0,0 +1,76 @@
+package spicedbx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var spicedbCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "permissions_api",
+	Subsystem: "spicedb",
+	Name:      "call_duration_seconds",
+	Help:      "Duration of SpiceDB calls by method and gRPC status code, streams are observed until fully read.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"method", "code"})
+
+// observeCall records a SpiceDB call started at the given time.
+func observeCall(method string, start time.Time, err error) {
+	spicedbCallDuration.WithLabelValues(method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+}
+
+// metricsUnaryClientInterceptor records the duration and status of unary SpiceDB calls.
+func metricsUnaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+
+	observeCall(method, start, err)
+
+	return err
+}
+
+// metricsStreamClientInterceptor records the duration and status of streaming SpiceDB calls.
+func metricsStreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	start := time.Now()
+
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		observeCall(method, start, err)
+
+		return nil, err
+	}
+
+	return &metricsClientStream{ClientStream: stream, method: method, start: start}, nil
+}
+
+// metricsClientStream records the call once the stream ends.
+type metricsClientStream struct {
+	grpc.ClientStream
+
+	method   string
+	start    time.Time
+	observed bool
+}
+
+func (s *metricsClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	if err != nil && !s.observed {
+		s.observed = true
+
+		if errors.Is(err, io.EOF) {
+			observeCall(s.method, s.start, nil)
+		} else {
+			observeCall(s.method, s.start, err)
+		}
+	}
+
+	return err
+}
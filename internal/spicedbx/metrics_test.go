@@ -0,0 +1,68 @@
+package spicedbx
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testClientStream returns the given errors from RecvMsg in order.
+type testClientStream struct {
+	grpc.ClientStream
+
+	errs []error
+}
+
+func (s *testClientStream) SendMsg(any) error {
+	return nil
+}
+
+func (s *testClientStream) CloseSend() error {
+	return nil
+}
+
+func (s *testClientStream) RecvMsg(any) error {
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+
+	return err
+}
+
+func TestMetricsInterceptors(t *testing.T) {
+	series := func() int {
+		return testutil.CollectAndCount(spicedbCallDuration)
+	}
+
+	start := series()
+
+	invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "leader election")
+	}
+
+	err := metricsUnaryClientInterceptor(context.Background(), "/test.Metrics/Unary", nil, nil, nil, invoker)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// a new series for the method and code
+	assert.Equal(t, start+1, series())
+
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return &testClientStream{errs: []error{nil, io.EOF}}, nil
+	}
+
+	stream, err := metricsStreamClientInterceptor(context.Background(), &grpc.StreamDesc{}, nil, "/test.Metrics/Stream", streamer)
+	require.NoError(t, err)
+
+	// the stream is only observed once it ends
+	require.NoError(t, stream.RecvMsg(nil))
+	assert.Equal(t, start+1, series())
+
+	assert.ErrorIs(t, stream.RecvMsg(nil), io.EOF)
+	assert.Equal(t, start+2, series())
+}
@@ -0,0 +1,256 @@
+package spicedbx
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultRetryConfig is the retry configuration used when none is configured.
+var DefaultRetryConfig = RetryConfig{
+	MaxAttempts:    3,
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     time.Second,
+}
+
+var spicedbRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "permissions_api",
+	Subsystem: "spicedb",
+	Name:      "retries_total",
+	Help:      "Number of SpiceDB calls retried after a transient error by method.",
+}, []string{"method"})
+
+// idempotentMethods are the SpiceDB methods which may always be retried.
+// WriteRelationships is retried depending on its updates.
+var idempotentMethods = map[string]bool{
+	"/authzed.api.v1.PermissionsService/CheckPermission":      true,
+	"/authzed.api.v1.PermissionsService/CheckBulkPermissions": true,
+	"/authzed.api.v1.PermissionsService/ExpandPermissionTree": true,
+	"/authzed.api.v1.PermissionsService/ReadRelationships":    true,
+	"/authzed.api.v1.PermissionsService/LookupResources":      true,
+	"/authzed.api.v1.PermissionsService/LookupSubjects":       true,
+	"/authzed.api.v1.PermissionsService/DeleteRelationships":  true,
+	"/authzed.api.v1.SchemaService/ReadSchema":                true,
+}
+
+const writeRelationshipsMethod = "/authzed.api.v1.PermissionsService/WriteRelationships"
+
+// RetryConfig configures retrying idempotent SpiceDB calls failing with a
+// transient error, e.g. while SpiceDB elects a new leader. The backoff
+// between attempts is jittered and doubles after every attempt, up to
+// MaxBackoff.
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of attempts of a call, including the
+	// first one. Retries are disabled when 1 or less.
+	MaxAttempts int
+	// InitialBackoff is the time waited before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum time waited between attempts.
+	MaxBackoff time.Duration
+}
+
+func (c RetryConfig) enabled() bool {
+	return c.MaxAttempts > 1
+}
+
+// backoff returns the time to wait after the given failed attempt, starting at
+// 1. Half of the backoff is random, so clients failing together do not retry
+// together.
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	backoff := c.InitialBackoff
+
+	for i := 1; i < attempt && (c.MaxBackoff <= 0 || backoff < c.MaxBackoff); i++ {
+		backoff *= 2
+	}
+
+	if c.MaxBackoff > 0 && backoff > c.MaxBackoff {
+		backoff = c.MaxBackoff
+	}
+
+	if half := int64(backoff / 2); half > 0 {
+		backoff = time.Duration(half + rand.Int63n(half)) //nolint:gosec // jitter does not need a secure source
+	}
+
+	return backoff
+}
+
+// wait waits before the next attempt, returning false if the context ends first.
+func (c RetryConfig) wait(ctx context.Context, method string, attempt int) bool {
+	timer := time.NewTimer(c.backoff(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		spicedbRetries.WithLabelValues(method).Inc()
+
+		return true
+	}
+}
+
+// retryableError reports whether a call failed with a transient error.
+func retryableError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// idempotent reports whether a call may be retried. Relationship writes may be
+// retried if they only touch or delete relationships unconditionally, as
+// creating a relationship fails if it already exists.
+func idempotent(method string, req any) bool {
+	if idempotentMethods[method] {
+		return true
+	}
+
+	if method != writeRelationshipsMethod {
+		return false
+	}
+
+	write, ok := req.(*v1.WriteRelationshipsRequest)
+	if !ok || len(write.OptionalPreconditions) != 0 {
+		return false
+	}
+
+	for _, update := range write.Updates {
+		switch update.Operation {
+		case v1.RelationshipUpdate_OPERATION_TOUCH, v1.RelationshipUpdate_OPERATION_DELETE:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// UnaryClientInterceptor retries idempotent unary SpiceDB calls.
+func (c RetryConfig) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !idempotent(method, req) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		for attempt := 1; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || attempt >= c.MaxAttempts || !retryableError(err) || !c.wait(ctx, method, attempt) {
+				return err
+			}
+		}
+	}
+}
+
+// StreamClientInterceptor retries idempotent server streaming SpiceDB calls.
+// Streams are only retried until the first message is received, so no
+// message is received twice.
+func (c RetryConfig) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if desc.ClientStreams || !idempotentMethods[method] {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		stream := &retryClientStream{
+			ctx:      ctx,
+			desc:     desc,
+			cc:       cc,
+			method:   method,
+			streamer: streamer,
+			opts:     opts,
+			config:   c,
+		}
+
+		for {
+			var err error
+
+			stream.attempt++
+
+			stream.ClientStream, err = streamer(ctx, desc, cc, method, opts...)
+			if err == nil {
+				return stream, nil
+			}
+
+			if stream.attempt >= c.MaxAttempts || !retryableError(err) || !c.wait(ctx, method, stream.attempt) {
+				return nil, err
+			}
+		}
+	}
+}
+
+// retryClientStream opens the stream again if it fails before the first
+// message is received.
+type retryClientStream struct {
+	grpc.ClientStream
+
+	ctx      context.Context
+	desc     *grpc.StreamDesc
+	cc       *grpc.ClientConn
+	method   string
+	streamer grpc.Streamer
+	opts     []grpc.CallOption
+	config   RetryConfig
+
+	req      any
+	received bool
+	attempt  int
+}
+
+func (s *retryClientStream) SendMsg(m any) error {
+	s.req = m
+
+	return s.ClientStream.SendMsg(m)
+}
+
+func (s *retryClientStream) RecvMsg(m any) error {
+	for {
+		err := s.ClientStream.RecvMsg(m)
+		if err == nil {
+			s.received = true
+
+			return nil
+		}
+
+		if s.received || s.req == nil || s.attempt >= s.config.MaxAttempts || !retryableError(err) || !s.config.wait(s.ctx, s.method, s.attempt) {
+			return err
+		}
+
+		s.attempt++
+
+		stream, serr := s.streamer(s.ctx, s.desc, s.cc, s.method, s.opts...)
+		if serr != nil {
+			return serr
+		}
+
+		if serr := stream.SendMsg(s.req); serr != nil {
+			return serr
+		}
+
+		if serr := stream.CloseSend(); serr != nil {
+			return serr
+		}
+
+		s.ClientStream = stream
+	}
+}
+
+// DialOptions returns the dial options installing the retry interceptors on
+// a SpiceDB client, no options are returned when retries are disabled.
+func (c RetryConfig) DialOptions() []grpc.DialOption {
+	if !c.enabled() {
+		return nil
+	}
+
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(c.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(c.StreamClientInterceptor()),
+	}
+}
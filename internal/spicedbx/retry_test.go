@@ -0,0 +1,175 @@
+package spicedbx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testRetryConfig = RetryConfig{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+}
+
+func TestRetryUnary(t *testing.T) {
+	t.Parallel()
+
+	writeRequest := func(op v1.RelationshipUpdate_Operation) *v1.WriteRelationshipsRequest {
+		return &v1.WriteRelationshipsRequest{
+			Updates: []*v1.RelationshipUpdate{{Operation: op}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		req      any
+		errs     []error
+		invoked  int
+		wantCode codes.Code
+	}{
+		{
+			name:     "CheckRecovers",
+			method:   "/authzed.api.v1.PermissionsService/CheckPermission",
+			errs:     []error{status.Error(codes.Unavailable, ""), status.Error(codes.ResourceExhausted, ""), nil},
+			invoked:  3,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "CheckExhausted",
+			method:   "/authzed.api.v1.PermissionsService/CheckPermission",
+			errs:     []error{status.Error(codes.Unavailable, ""), status.Error(codes.Unavailable, ""), status.Error(codes.Unavailable, "")},
+			invoked:  3,
+			wantCode: codes.Unavailable,
+		},
+		{
+			name:     "NotTransient",
+			method:   "/authzed.api.v1.PermissionsService/CheckPermission",
+			errs:     []error{status.Error(codes.InvalidArgument, "")},
+			invoked:  1,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "TouchWrite",
+			method:   writeRelationshipsMethod,
+			req:      writeRequest(v1.RelationshipUpdate_OPERATION_TOUCH),
+			errs:     []error{status.Error(codes.Unavailable, ""), nil},
+			invoked:  2,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "CreateWrite",
+			method:   writeRelationshipsMethod,
+			req:      writeRequest(v1.RelationshipUpdate_OPERATION_CREATE),
+			errs:     []error{status.Error(codes.Unavailable, "")},
+			invoked:  1,
+			wantCode: codes.Unavailable,
+		},
+		{
+			name:     "WriteSchema",
+			method:   "/authzed.api.v1.SchemaService/WriteSchema",
+			errs:     []error{status.Error(codes.Unavailable, "")},
+			invoked:  1,
+			wantCode: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var invoked int
+
+			invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+				err := tt.errs[invoked]
+				invoked++
+
+				return err
+			}
+
+			err := testRetryConfig.UnaryClientInterceptor()(context.Background(), tt.method, tt.req, nil, nil, invoker)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.invoked, invoked)
+		})
+	}
+}
+
+func TestRetryUnaryContextDone(t *testing.T) {
+	t.Parallel()
+
+	config := RetryConfig{MaxAttempts: 3, InitialBackoff: time.Minute}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var invoked int
+
+	invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		invoked++
+
+		return status.Error(codes.Unavailable, "")
+	}
+
+	err := config.UnaryClientInterceptor()(ctx, "/authzed.api.v1.PermissionsService/CheckPermission", nil, nil, nil, invoker)
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, invoked)
+}
+
+func TestRetryStream(t *testing.T) {
+	t.Parallel()
+
+	var opened int
+
+	// the first stream fails before sending a message, the second fails
+	// after sending one, which is not retried
+	streams := []*testClientStream{
+		{errs: []error{status.Error(codes.Unavailable, "")}},
+		{errs: []error{nil, status.Error(codes.Unavailable, "")}},
+	}
+
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream := streams[opened]
+		opened++
+
+		return stream, nil
+	}
+
+	desc := &grpc.StreamDesc{ServerStreams: true}
+
+	stream, err := testRetryConfig.StreamClientInterceptor()(context.Background(), desc, nil, "/authzed.api.v1.PermissionsService/ReadRelationships", streamer)
+	require.NoError(t, err)
+
+	require.NoError(t, stream.SendMsg(&v1.ReadRelationshipsRequest{}))
+	require.NoError(t, stream.CloseSend())
+
+	require.NoError(t, stream.RecvMsg(nil))
+	assert.Equal(t, 2, opened)
+
+	assert.Equal(t, codes.Unavailable, status.Code(stream.RecvMsg(nil)))
+	assert.Equal(t, 2, opened)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	t.Parallel()
+
+	config := RetryConfig{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	for attempt, limit := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		backoff := config.backoff(attempt + 1)
+
+		assert.GreaterOrEqual(t, backoff, limit/2)
+		assert.Less(t, backoff, limit)
+	}
+}
@@ -37,6 +37,16 @@ var schemaTemplate = template.Must(template.New("schema").Parse(`
 {{- end -}}
 
 {{- $namespace := .Namespace -}}
+{{- range .Caveats -}}
+caveat {{$namespace}}/{{.Name}}(
+	{{- range $index, $param := .Parameters -}}
+		{{- if $index }}, {{end}}
+		{{- $param.Name}} {{$param.Type}}
+	{{- end -}}
+) {
+    {{.Expression}}
+}
+{{end}}
 {{- range .ResourceTypes -}}
 definition {{$namespace}}/{{.Name}} {
 {{- range .Relationships }}
@@ -45,6 +55,7 @@ definition {{$namespace}}/{{.Name}} {
 			{{- $namespace}}/{{$type.Name}}
 			{{- if $type.SubjectIdentifier}}:{{$type.SubjectIdentifier}}{{end}}
 			{{- if $type.SubjectRelation}}#{{$type.SubjectRelation}}{{end}}
+			{{- if $type.Caveat}} with {{$namespace}}/{{$type.Caveat}}{{end}}
 		{{- end }}
 {{- end }}
 
@@ -58,8 +69,9 @@ definition {{$namespace}}/{{.Name}} {
 }
 {{end}}`))
 
-// GenerateSchema generates the spicedb schema from the template
-func GenerateSchema(namespace string, resourceTypes []types.ResourceType) (string, error) {
+// GenerateSchema generates the spicedb schema from the template, caveats are
+// defined before the resource types.
+func GenerateSchema(namespace string, resourceTypes []types.ResourceType, caveats ...types.Caveat) (string, error) {
 	if namespace == "" {
 		return "", ErrorNoNamespace
 	}
@@ -67,10 +79,12 @@ func GenerateSchema(namespace string, resourceTypes []types.ResourceType) (strin
 	var data struct {
 		Namespace     string
 		ResourceTypes []types.ResourceType
+		Caveats       []types.Caveat
 	}
 
 	data.Namespace = namespace
 	data.ResourceTypes = resourceTypes
+	data.Caveats = caveats
 
 	var out bytes.Buffer
 
@@ -86,7 +100,7 @@ func GenerateSchema(namespace string, resourceTypes []types.ResourceType) (strin
 func GeneratedSchema(namespace string) string {
 	policy := iapl.DefaultPolicy()
 
-	schema, err := GenerateSchema(namespace, policy.Schema())
+	schema, err := GenerateSchema(namespace, policy.Schema(), policy.Caveats()...)
 	if err != nil {
 		panic(err)
 	}
@@ -14,6 +14,7 @@ func TestSchema(t *testing.T) {
 	type testInput struct {
 		namespace     string
 		resourceTypes []types.ResourceType
+		caveats       []types.Caveat
 	}
 
 	type testResult struct {
@@ -253,6 +254,57 @@ definition foo/port {
     relation port_get_rel: foo/role#subject
     permission port_get = port_get_rel + owner->port_get
 }
+`
+
+	caveatResourceTypes := []types.ResourceType{
+		{
+			Name: "user",
+		},
+		{
+			Name: "role",
+		},
+		{
+			Name: "rolebinding",
+			Relationships: []types.ResourceTypeRelationship{
+				{
+					Relation: "role",
+					Types: []types.TargetType{
+						{Name: "role"},
+						{Name: "role", Caveat: "on_vpn"},
+					},
+				},
+				{
+					Relation: "subject",
+					Types: []types.TargetType{
+						{Name: "user"},
+					},
+				},
+			},
+		},
+	}
+
+	caveats := []types.Caveat{
+		{
+			Name: "on_vpn",
+			Parameters: []types.CaveatParameter{
+				{Name: "source_ip", Type: "ipaddress"},
+				{Name: "cidrs", Type: "list<string>"},
+			},
+			Expression: "cidrs.exists(cidr, source_ip.in_cidr(cidr))",
+		},
+	}
+
+	caveatSchemaOutput := `caveat foo/on_vpn(source_ip ipaddress, cidrs list<string>) {
+    cidrs.exists(cidr, source_ip.in_cidr(cidr))
+}
+definition foo/user {
+}
+definition foo/role {
+}
+definition foo/rolebinding {
+    relation role: foo/role | foo/role with foo/on_vpn
+    relation subject: foo/user
+}
 `
 
 	testCases := []testCase{
@@ -278,6 +330,18 @@ definition foo/port {
 				assert.Equal(t, schemaOutput, res.success)
 			},
 		},
+		{
+			name: "SuccessCaveats",
+			input: testInput{
+				namespace:     "foo",
+				resourceTypes: caveatResourceTypes,
+				caveats:       caveats,
+			},
+			checkFn: func(t *testing.T, res testResult) {
+				assert.NoError(t, res.err)
+				assert.Equal(t, caveatSchemaOutput, res.success)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -287,7 +351,7 @@ definition foo/port {
 
 			var result testResult
 
-			result.success, result.err = GenerateSchema(tc.input.namespace, tc.input.resourceTypes)
+			result.success, result.err = GenerateSchema(tc.input.namespace, tc.input.resourceTypes, tc.input.caveats...)
 
 			tc.checkFn(t, result)
 		})
@@ -0,0 +1,265 @@
+package spicedbx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// SchemaSegment is the definition of a single type in a SpiceDB schema.
+type SchemaSegment struct {
+	// Name is the namespaced name of the type, e.g. infratographer/tenant.
+	Name string
+	// Definition is the schema text defining the type, including any comments preceding it.
+	Definition string
+}
+
+// GenerateSchemaSegments produces the schema definitions of the named resource
+// types only, so they can be applied without changing the definitions of
+// other types.
+func GenerateSchemaSegments(namespace string, resourceTypes []types.ResourceType, names []string) ([]SchemaSegment, error) {
+	typeMap := make(map[string]types.ResourceType, len(resourceTypes))
+
+	for _, rt := range resourceTypes {
+		typeMap[rt.Name] = rt
+	}
+
+	segments := make([]SchemaSegment, len(names))
+
+	for i, name := range names {
+		rt, ok := typeMap[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrorUnknownResourceType, name)
+		}
+
+		definition, err := GenerateSchema(namespace, []types.ResourceType{rt})
+		if err != nil {
+			return nil, err
+		}
+
+		segments[i] = SchemaSegment{
+			Name:       namespace + "/" + name,
+			Definition: strings.TrimSpace(definition),
+		}
+	}
+
+	return segments, nil
+}
+
+// SplitSchema splits a SpiceDB schema, as returned by ReadSchema, into the
+// definitions of its types and caveats.
+func SplitSchema(schema string) ([]SchemaSegment, error) {
+	var (
+		segments []SchemaSegment
+		start    int
+		depth    int
+	)
+
+	for i := 0; i < len(schema); i++ {
+		switch {
+		case strings.HasPrefix(schema[i:], "//"):
+			if end := strings.IndexByte(schema[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(schema)
+			}
+		case strings.HasPrefix(schema[i:], "/*"):
+			end := strings.Index(schema[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated comment", ErrorInvalidSchema)
+			}
+
+			i += end + 3
+		case schema[i] == '{':
+			depth++
+		case schema[i] == '}':
+			depth--
+
+			if depth < 0 {
+				return nil, fmt.Errorf("%w: unexpected '}'", ErrorInvalidSchema)
+			}
+
+			if depth > 0 {
+				continue
+			}
+
+			definition := strings.TrimSpace(schema[start : i+1])
+
+			name, err := segmentName(definition)
+			if err != nil {
+				return nil, err
+			}
+
+			segments = append(segments, SchemaSegment{Name: name, Definition: definition})
+
+			start = i + 1
+		}
+	}
+
+	if depth != 0 || strings.TrimSpace(schema[start:]) != "" {
+		return nil, fmt.Errorf("%w: unterminated definition", ErrorInvalidSchema)
+	}
+
+	return segments, nil
+}
+
+// segmentName returns the name of the type or caveat defined by the segment.
+func segmentName(definition string) (string, error) {
+	for _, line := range strings.Split(definition, "\n") {
+		fields := strings.Fields(strings.ReplaceAll(line, "{", " { "))
+
+		if len(fields) < 2 {
+			continue
+		}
+
+		if fields[0] == "definition" || fields[0] == "caveat" {
+			name, _, _ := strings.Cut(fields[1], "(")
+
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: definition without a name", ErrorInvalidSchema)
+}
+
+// MergeSchema replaces the definitions of the given segments in the current
+// schema, segments of types not yet defined are appended. All other
+// definitions are kept as they are.
+func MergeSchema(current string, segments []SchemaSegment) (string, error) {
+	currentSegments, err := SplitSchema(current)
+	if err != nil {
+		return "", err
+	}
+
+	index := make(map[string]int, len(currentSegments))
+
+	for i, segment := range currentSegments {
+		index[segment.Name] = i
+	}
+
+	for _, segment := range segments {
+		if i, ok := index[segment.Name]; ok {
+			currentSegments[i] = segment
+
+			continue
+		}
+
+		index[segment.Name] = len(currentSegments)
+		currentSegments = append(currentSegments, segment)
+	}
+
+	return joinSegments(currentSegments), nil
+}
+
+// NamespaceSchema returns the definitions of the namespace in a schema shared
+// by several namespaces, as returned by ReadSchema.
+func NamespaceSchema(schema, namespace string) (string, error) {
+	segments, err := SplitSchema(schema)
+	if err != nil {
+		return "", err
+	}
+
+	segments = slices.DeleteFunc(segments, func(segment SchemaSegment) bool {
+		return !inNamespace(segment, namespace)
+	})
+
+	return joinSegments(segments), nil
+}
+
+// ReplaceNamespaceSchema replaces all definitions of the namespace in the
+// current schema with the definitions of schema, keeping the definitions of
+// other namespaces as they are.
+func ReplaceNamespaceSchema(current, namespace, schema string) (string, error) {
+	currentSegments, err := SplitSchema(current)
+	if err != nil {
+		return "", err
+	}
+
+	segments, err := SplitSchema(schema)
+	if err != nil {
+		return "", err
+	}
+
+	currentSegments = slices.DeleteFunc(currentSegments, func(segment SchemaSegment) bool {
+		return inNamespace(segment, namespace)
+	})
+
+	return joinSegments(append(currentSegments, segments...)), nil
+}
+
+// inNamespace reports whether the segment defines a type or caveat of the namespace.
+func inNamespace(segment SchemaSegment, namespace string) bool {
+	return strings.HasPrefix(segment.Name, namespace+"/")
+}
+
+// joinSegments returns the schema text of the segments.
+func joinSegments(segments []SchemaSegment) string {
+	definitions := make([]string, len(segments))
+
+	for i, segment := range segments {
+		definitions[i] = segment.Definition
+	}
+
+	return strings.Join(definitions, "\n\n") + "\n"
+}
+
+// SchemaHash returns the hex encoded SHA-256 hash of the definitions of a
+// schema, ignoring their order, comments and formatting, so a generated schema
+// can be compared with the schema returned by ReadSchema.
+func SchemaHash(schema string) (string, error) {
+	segments, err := SplitSchema(schema)
+	if err != nil {
+		return "", err
+	}
+
+	definitions := make([]string, len(segments))
+
+	for i, segment := range segments {
+		definitions[i] = normalizeDefinition(segment.Definition)
+	}
+
+	slices.Sort(definitions)
+
+	sum := sha256.Sum256([]byte(strings.Join(definitions, "\n")))
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// normalizeDefinition removes comments from a definition and collapses its
+// whitespace, dropping whitespace inside parentheses.
+func normalizeDefinition(definition string) string {
+	var out strings.Builder
+
+	for i := 0; i < len(definition); i++ {
+		switch {
+		case strings.HasPrefix(definition[i:], "//"):
+			end := strings.IndexByte(definition[i:], '\n')
+			if end < 0 {
+				return normalizeWhitespace(out.String())
+			}
+
+			i += end
+		case strings.HasPrefix(definition[i:], "/*"):
+			end := strings.Index(definition[i+2:], "*/")
+			if end < 0 {
+				return normalizeWhitespace(out.String())
+			}
+
+			i += end + 3
+		default:
+			out.WriteByte(definition[i])
+		}
+	}
+
+	return normalizeWhitespace(out.String())
+}
+
+func normalizeWhitespace(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+
+	return strings.NewReplacer("( ", "(", " )", ")").Replace(s)
+}
@@ -0,0 +1,205 @@
+package spicedbx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestMergeSchema(t *testing.T) {
+	t.Parallel()
+
+	current := `/** user is a user */
+definition foo/user {}
+
+definition foo/tenant {
+	relation parent: foo/tenant
+	permission loadbalancer_get = parent->loadbalancer_get
+}
+
+caveat foo/on_weekdays(day string) {
+	day != "saturday"
+}
+
+// loadbalancer is a load balancer
+definition foo/loadbalancer {
+	relation owner: foo/tenant
+}
+`
+
+	resourceTypes := []types.ResourceType{
+		{Name: "user"},
+		{
+			Name: "tenant",
+			Relationships: []types.ResourceTypeRelationship{
+				{Relation: "parent", Types: []types.TargetType{{Name: "tenant"}}},
+				{Relation: "member", Types: []types.TargetType{{Name: "user"}}},
+			},
+		},
+		{Name: "port"},
+	}
+
+	type testResult struct {
+		success string
+		err     error
+	}
+
+	type testCase struct {
+		name    string
+		input   []string
+		checkFn func(*testing.T, testResult)
+	}
+
+	testCases := []testCase{
+		{
+			name:  "UnknownResourceType",
+			input: []string{"loadbalancer"},
+			checkFn: func(t *testing.T, res testResult) {
+				assert.ErrorIs(t, res.err, ErrorUnknownResourceType)
+			},
+		},
+		{
+			name:  "ReplaceDefinition",
+			input: []string{"tenant"},
+			checkFn: func(t *testing.T, res testResult) {
+				require.NoError(t, res.err)
+
+				expected := `/** user is a user */
+definition foo/user {}
+
+definition foo/tenant {
+    relation parent: foo/tenant
+    relation member: foo/user
+}
+
+caveat foo/on_weekdays(day string) {
+	day != "saturday"
+}
+
+// loadbalancer is a load balancer
+definition foo/loadbalancer {
+	relation owner: foo/tenant
+}
+`
+
+				assert.Equal(t, expected, res.success)
+			},
+		},
+		{
+			name:  "AddDefinition",
+			input: []string{"port"},
+			checkFn: func(t *testing.T, res testResult) {
+				require.NoError(t, res.err)
+
+				segments, err := SplitSchema(res.success)
+				require.NoError(t, err)
+				require.Len(t, segments, 5)
+
+				assert.Equal(t, "foo/port", segments[4].Name)
+				assert.Equal(t, "definition foo/port {\n}", segments[4].Definition)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var result testResult
+
+			segments, err := GenerateSchemaSegments("foo", resourceTypes, tc.input)
+			if err != nil {
+				result.err = err
+			} else {
+				result.success, result.err = MergeSchema(current, segments)
+			}
+
+			tc.checkFn(t, result)
+		})
+	}
+}
+
+func TestSplitSchemaInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := SplitSchema("definition foo/user {")
+	assert.ErrorIs(t, err, ErrorInvalidSchema)
+
+	_, err = SplitSchema("definition foo/user {}\n}")
+	assert.ErrorIs(t, err, ErrorInvalidSchema)
+}
+
+func TestSchemaHash(t *testing.T) {
+	t.Parallel()
+
+	generated := `definition foo/user {
+}
+definition foo/tenant {
+    relation parent: foo/tenant
+    permission loadbalancer_get = (parent->loadbalancer_get + owner) & member
+}
+`
+
+	// as returned by ReadSchema, reordered and formatted differently
+	read := `/** tenant is a tenant */
+definition foo/tenant {
+	relation parent: foo/tenant
+
+	// loadbalancer_get allows reading load balancers
+	permission loadbalancer_get = ( parent->loadbalancer_get + owner ) & member
+}
+
+definition foo/user {}
+`
+
+	generatedHash, err := SchemaHash(generated)
+	require.NoError(t, err)
+
+	readHash, err := SchemaHash(read)
+	require.NoError(t, err)
+
+	assert.Equal(t, generatedHash, readHash)
+
+	changedHash, err := SchemaHash(strings.Replace(read, "owner", "admin", 1))
+	require.NoError(t, err)
+
+	assert.NotEqual(t, generatedHash, changedHash)
+}
+
+func TestNamespaceSchemas(t *testing.T) {
+	t.Parallel()
+
+	current := `definition foo/user {}
+
+definition bar/user {}
+
+caveat bar/on_weekdays(day string) {
+	day != "saturday"
+}
+
+definition foo/tenant {
+	relation member: foo/user
+}
+`
+
+	foo, err := NamespaceSchema(current, "foo")
+	require.NoError(t, err)
+
+	assert.Equal(t, "definition foo/user {}\n\ndefinition foo/tenant {\n\trelation member: foo/user\n}\n", foo)
+
+	replaced, err := ReplaceNamespaceSchema(current, "bar", "definition bar/tenant {}\n")
+	require.NoError(t, err)
+
+	assert.Equal(t, "definition foo/user {}\n\ndefinition foo/tenant {\n\trelation member: foo/user\n}\n\ndefinition bar/tenant {}\n", replaced)
+
+	// a namespace is not a prefix of another namespace's name
+	foobar, err := NamespaceSchema("definition foobar/user {}\n", "foo")
+	require.NoError(t, err)
+
+	assert.Equal(t, "\n", foobar)
+}
@@ -0,0 +1,158 @@
+package spicedbx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var spicedbSlowCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "permissions_api",
+	Subsystem: "spicedb",
+	Name:      "slow_calls_total",
+	Help:      "Number of SpiceDB calls taking longer than the slow call threshold by method.",
+}, []string{"method"})
+
+// SlowCallLog logs SpiceDB calls taking longer than a threshold with their
+// request, so the filters causing slow calls can be found.
+type SlowCallLog struct {
+	threshold atomic.Int64
+	logger    *zap.SugaredLogger
+}
+
+// NewSlowCallLog returns a SlowCallLog logging calls taking longer than the
+// threshold, calls are not logged while the threshold is not positive.
+func NewSlowCallLog(threshold time.Duration, logger *zap.SugaredLogger) *SlowCallLog {
+	l := &SlowCallLog{
+		logger: logger.Named("spicedb"),
+	}
+
+	l.SetThreshold(threshold)
+
+	return l
+}
+
+// SetThreshold changes the threshold above which calls are logged, zero
+// disables logging slow calls.
+func (l *SlowCallLog) SetThreshold(threshold time.Duration) {
+	l.threshold.Store(int64(threshold))
+}
+
+// observe logs the call if it took longer than the threshold.
+func (l *SlowCallLog) observe(ctx context.Context, method string, req any, start time.Time, err error) {
+	threshold := time.Duration(l.threshold.Load())
+
+	duration := time.Since(start)
+	if threshold <= 0 || duration < threshold {
+		return
+	}
+
+	spicedbSlowCalls.WithLabelValues(method).Inc()
+
+	fields := []any{
+		"method", method,
+		"duration", duration,
+		"code", status.Code(err).String(),
+		"request", req,
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		fields = append(fields, "trace_id", sc.TraceID().String())
+	}
+
+	l.logger.Warnw("slow spicedb call", fields...)
+}
+
+// UnaryClientInterceptor logs slow unary SpiceDB calls.
+func (l *SlowCallLog) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		l.observe(ctx, method, req, start, err)
+
+		return err
+	}
+}
+
+// StreamClientInterceptor logs slow streaming SpiceDB calls, streams are
+// timed until fully read. Watches are expected to be long-lived and are not logged.
+func (l *SlowCallLog) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if strings.HasSuffix(method, "/Watch") {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		start := time.Now()
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			l.observe(ctx, method, nil, start, err)
+
+			return nil, err
+		}
+
+		return &slowCallClientStream{ClientStream: stream, log: l, ctx: ctx, method: method, start: start}, nil
+	}
+}
+
+// DialOptions returns the dial options installing the slow call interceptors
+// on a SpiceDB client, a nil SlowCallLog returns no options.
+func (l *SlowCallLog) DialOptions() []grpc.DialOption {
+	if l == nil {
+		return nil
+	}
+
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(l.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(l.StreamClientInterceptor()),
+	}
+}
+
+// slowCallClientStream keeps the request sent on the stream and observes the
+// call once the stream ends.
+type slowCallClientStream struct {
+	grpc.ClientStream
+
+	log      *SlowCallLog
+	ctx      context.Context
+	method   string
+	start    time.Time
+	req      any
+	observed bool
+}
+
+func (s *slowCallClientStream) SendMsg(m any) error {
+	if s.req == nil {
+		s.req = m
+	}
+
+	return s.ClientStream.SendMsg(m)
+}
+
+func (s *slowCallClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	if err != nil && !s.observed {
+		s.observed = true
+
+		if errors.Is(err, io.EOF) {
+			s.log.observe(s.ctx, s.method, s.req, s.start, nil)
+		} else {
+			s.log.observe(s.ctx, s.method, s.req, s.start, err)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,72 @@
+package spicedbx
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+)
+
+func TestSlowCallLog(t *testing.T) {
+	assert.Empty(t, (*SlowCallLog)(nil).DialOptions())
+
+	core, logs := observer.New(zapcore.WarnLevel)
+
+	log := NewSlowCallLog(0, zap.New(core).Sugar())
+
+	fast := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		return nil
+	}
+
+	slow := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		time.Sleep(2 * time.Millisecond)
+
+		return nil
+	}
+
+	interceptor := log.UnaryClientInterceptor()
+
+	// calls are not logged until a threshold is set
+	require.NoError(t, interceptor(context.Background(), "/test.SlowCalls/Slow", "slow request", nil, nil, slow))
+	assert.Equal(t, 0, logs.Len())
+
+	log.SetThreshold(time.Millisecond)
+
+	require.NoError(t, interceptor(context.Background(), "/test.SlowCalls/Fast", "fast request", nil, nil, fast))
+	assert.Equal(t, 0, logs.Len())
+
+	require.NoError(t, interceptor(context.Background(), "/test.SlowCalls/Slow", "slow request", nil, nil, slow))
+	require.Equal(t, 1, logs.Len())
+
+	entry := logs.TakeAll()[0]
+	assert.Equal(t, "slow spicedb call", entry.Message)
+	assert.Equal(t, "/test.SlowCalls/Slow", entry.ContextMap()["method"])
+	assert.Equal(t, "slow request", entry.ContextMap()["request"])
+	assert.Equal(t, 1.0, testutil.ToFloat64(spicedbSlowCalls.WithLabelValues("/test.SlowCalls/Slow")))
+
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return &testClientStream{errs: []error{nil, io.EOF}}, nil
+	}
+
+	stream, err := log.StreamClientInterceptor()(context.Background(), &grpc.StreamDesc{}, nil, "/test.SlowCalls/Stream", streamer)
+	require.NoError(t, err)
+
+	require.NoError(t, stream.SendMsg("stream request"))
+	require.NoError(t, stream.RecvMsg(nil))
+
+	time.Sleep(2 * time.Millisecond)
+
+	assert.ErrorIs(t, stream.RecvMsg(nil), io.EOF)
+
+	// the stream is logged once it ends, with the request sent on it
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "stream request", logs.TakeAll()[0].ContextMap()["request"])
+}
@@ -0,0 +1,311 @@
+// Package testspicedb is a testing helper package which provisions an isolated
+// SpiceDB namespace, and its schema, for each test so integration tests may run
+// in parallel against a single shared SpiceDB instance.
+//
+// The SpiceDB endpoint and preshared key default to the dev container values
+// and may be overridden with the PERMISSIONSAPI_TEST_SPICEDB_ENDPOINT and
+// PERMISSIONSAPI_TEST_SPICEDB_KEY environment variables. When the SpiceDB
+// instance runs with `spicedb serve-testing`, setting
+// PERMISSIONSAPI_TEST_SPICEDB_ISOLATED=true gives every test its own preshared
+// key, and therefore its own datastore, which fully isolates concurrent CI jobs.
+package testspicedb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/authzed-go/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/permissions-api/internal/spicedbx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	endpointEnv = "PERMISSIONSAPI_TEST_SPICEDB_ENDPOINT"
+	keyEnv      = "PERMISSIONSAPI_TEST_SPICEDB_KEY"
+	isolatedEnv = "PERMISSIONSAPI_TEST_SPICEDB_ISOLATED"
+
+	defaultEndpoint = "spicedb:50051"
+	defaultKey      = "infradev"
+
+	// schemaWriteAttempts is the number of times a merged schema write is
+	// attempted when a concurrent writer modifies the schema.
+	schemaWriteAttempts = 10
+
+	// schemaSettleDelay is how long a schema write settles before it is
+	// verified, so writers in other test binaries which read the schema before
+	// the write have written theirs.
+	schemaSettleDelay = 100 * time.Millisecond
+
+	suffixBytes = 4
+)
+
+// schemaMu serializes schema read-modify-write cycles within a test binary,
+// writes of other test binaries are verified after writing instead.
+var schemaMu sync.Mutex
+
+// errSchemaOverwritten is returned when the namespace definitions are lost to
+// concurrent writers on every attempt.
+var errSchemaOverwritten = errors.New("schema overwritten by a concurrent writer")
+
+// NewTestSpiceDB returns a SpiceDB client and a unique namespace derived from
+// the given prefix, with the schema for the given resource types and caveats written to
+// SpiceDB. All relationships and schema definitions in the namespace are
+// removed when the test completes.
+func NewTestSpiceDB(ctx context.Context, t *testing.T, prefix string, resourceTypes []types.ResourceType, caveats ...types.Caveat) (*authzed.Client, string) {
+	t.Helper()
+
+	key := envOrDefault(keyEnv, defaultKey)
+
+	if isolated, _ := strconv.ParseBool(os.Getenv(isolatedEnv)); isolated {
+		key = "test-" + randomSuffix(t)
+	}
+
+	client, err := spicedbx.NewClient(spicedbx.Config{
+		Endpoint: envOrDefault(endpointEnv, defaultEndpoint),
+		Key:      key,
+		Insecure: true,
+	}, false)
+	if err != nil {
+		t.Fatalf("failed to create spicedb client: %s", err)
+	}
+
+	return client, Provision(ctx, t, client, prefix, resourceTypes, caveats...)
+}
+
+// Provision writes the schema for the given resource types and caveats to a
+// unique namespace derived from the given prefix, using an existing SpiceDB
+// client, and returns the namespace. All relationships and schema definitions
+// in the namespace are removed when the test completes.
+func Provision(ctx context.Context, t *testing.T, client *authzed.Client, prefix string, resourceTypes []types.ResourceType, caveats ...types.Caveat) string {
+	t.Helper()
+
+	namespace := NewNamespace(t, prefix)
+
+	schema, err := spicedbx.GenerateSchema(namespace, resourceTypes, caveats...)
+	if err != nil {
+		t.Fatalf("failed to generate schema: %s", err)
+	}
+
+	if err := writeNamespaceSchema(ctx, client, namespace, schema); err != nil {
+		t.Fatalf("failed to write schema for namespace %s: %s", namespace, err)
+	}
+
+	t.Cleanup(func() {
+		// the test context may already be canceled by the time cleanup runs
+		ctx := context.Background()
+
+		for _, resourceType := range resourceTypes {
+			_, err := client.DeleteRelationships(ctx, &pb.DeleteRelationshipsRequest{
+				RelationshipFilter: &pb.RelationshipFilter{
+					ResourceType: namespace + "/" + resourceType.Name,
+				},
+			})
+			if err != nil {
+				t.Errorf("failed to delete relationships for %s/%s: %s", namespace, resourceType.Name, err)
+			}
+		}
+
+		if err := writeNamespaceSchema(ctx, client, namespace, ""); err != nil {
+			t.Errorf("failed to remove schema for namespace %s: %s", namespace, err)
+		}
+	})
+
+	return namespace
+}
+
+// NewNamespace returns a unique, valid SpiceDB namespace for the given prefix.
+func NewNamespace(t *testing.T, prefix string) string {
+	t.Helper()
+
+	return strings.ToLower(prefix) + "_" + randomSuffix(t)
+}
+
+// writeNamespaceSchema replaces the definitions belonging to the namespace in
+// the current SpiceDB schema with the given schema, preserving the definitions
+// of all other namespaces. An empty schema removes the namespace definitions.
+//
+// Test binaries of different packages run concurrently against the same
+// SpiceDB, a writer which read the schema before this write replaces it
+// without the namespace definitions. Every write is therefore read back once
+// settled, and retried when the namespace definitions are not as written.
+func writeNamespaceSchema(ctx context.Context, client *authzed.Client, namespace, schema string) error {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+
+	want := namespaceDefinitions(schema, namespace)
+
+	var err error
+
+	for i := 0; i < schemaWriteAttempts; i++ {
+		var current string
+
+		current, err = readSchema(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		merged := mergeNamespaceSchema(current, namespace, schema)
+
+		// SpiceDB rejects empty schemas, leave the last definitions in place.
+		if merged == "" {
+			return nil
+		}
+
+		if _, err = client.WriteSchema(ctx, &pb.WriteSchemaRequest{Schema: merged}); err != nil {
+			// A failed precondition is returned when definitions removed by a
+			// concurrent writer still have relationships, retry with a fresh schema.
+			if status.Code(err) != codes.FailedPrecondition {
+				return err
+			}
+
+			continue
+		}
+
+		time.Sleep(schemaSettleDelay)
+
+		current, err = readSchema(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		if slices.Equal(namespaceDefinitions(current, namespace), want) {
+			return nil
+		}
+
+		err = fmt.Errorf("%w: %s", errSchemaOverwritten, namespace)
+	}
+
+	return err
+}
+
+func readSchema(ctx context.Context, client *authzed.Client) (string, error) {
+	resp, err := client.ReadSchema(ctx, &pb.ReadSchemaRequest{})
+
+	switch {
+	case status.Code(err) == codes.NotFound:
+		return "", nil
+	case err != nil:
+		return "", err
+	default:
+		return resp.SchemaText, nil
+	}
+}
+
+// mergeNamespaceSchema removes all top level blocks belonging to the namespace
+// from the current schema and appends the given namespace schema.
+func mergeNamespaceSchema(current, namespace, schema string) string {
+	var out strings.Builder
+
+	for _, block := range splitSchemaBlocks(current) {
+		if blockNamespace(block) == namespace {
+			continue
+		}
+
+		out.WriteString(block)
+		out.WriteString("\n\n")
+	}
+
+	out.WriteString(schema)
+
+	return strings.TrimSpace(out.String())
+}
+
+// splitSchemaBlocks splits a schema into its top level definition and caveat blocks.
+func splitSchemaBlocks(schema string) []string {
+	var (
+		blocks []string
+		depth  int
+		start  = -1
+	)
+
+	for i, r := range schema {
+		switch r {
+		case '{':
+			if depth == 0 && start == -1 {
+				start = strings.LastIndexAny(schema[:i], "}\n") + 1
+			}
+
+			depth++
+		case '}':
+			depth--
+
+			if depth == 0 && start != -1 {
+				blocks = append(blocks, strings.TrimSpace(schema[start:i+1]))
+				start = -1
+			}
+		}
+	}
+
+	return blocks
+}
+
+// namespaceDefinitions returns the sorted headers, such as "definition ns/type",
+// of the blocks of the schema belonging to the namespace. Only headers are
+// compared, as SpiceDB may format the schema it returns differently.
+func namespaceDefinitions(schema, namespace string) []string {
+	var headers []string
+
+	for _, block := range splitSchemaBlocks(schema) {
+		if blockNamespace(block) != namespace {
+			continue
+		}
+
+		header, _, _ := strings.Cut(block, "{")
+		headers = append(headers, strings.Join(strings.Fields(header), " "))
+	}
+
+	slices.Sort(headers)
+
+	return headers
+}
+
+// blockNamespace returns the namespace of a block such as "definition ns/type {".
+func blockNamespace(block string) string {
+	header, _, _ := strings.Cut(block, "{")
+
+	_, name, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found {
+		return ""
+	}
+
+	namespace, _, found := strings.Cut(strings.TrimSpace(name), "/")
+	if !found {
+		return ""
+	}
+
+	return namespace
+}
+
+func randomSuffix(t *testing.T) string {
+	t.Helper()
+
+	b := make([]byte, suffixBytes)
+
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("failed to generate random suffix: %s", err)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return def
+}
@@ -0,0 +1,54 @@
+package testspicedb
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeNamespaceSchema(t *testing.T) {
+	current := `definition other/user {}
+
+definition other/tenant {
+	relation parent: other/tenant
+	permission view = parent->view
+}
+
+definition mine/user {}
+
+definition mine/tenant {
+	relation parent: mine/tenant
+}`
+
+	merged := mergeNamespaceSchema(current, "mine", "definition mine/client {}")
+
+	assert.Contains(t, merged, "definition other/user {}")
+	assert.Contains(t, merged, "permission view = parent->view")
+	assert.Contains(t, merged, "definition mine/client {}")
+	assert.NotContains(t, merged, "definition mine/user")
+	assert.NotContains(t, merged, "definition mine/tenant")
+
+	removed := mergeNamespaceSchema(merged, "mine", "")
+
+	assert.NotContains(t, removed, "mine/")
+	assert.Equal(t, 2, strings.Count(removed, "definition other/"))
+
+	assert.Equal(t, []string{"definition mine/tenant", "definition mine/user"}, namespaceDefinitions(current, "mine"))
+	assert.Equal(t, []string{"definition mine/client"}, namespaceDefinitions(merged, "mine"))
+	assert.Empty(t, namespaceDefinitions(removed, "mine"))
+}
+
+func TestNewNamespace(t *testing.T) {
+	valid := regexp.MustCompile(`^[a-z][a-z0-9_]{1,62}[a-z0-9]$`)
+
+	first := NewNamespace(t, "TestRoles")
+	second := NewNamespace(t, "TestRoles")
+
+	require.Regexp(t, valid, first)
+	require.Regexp(t, valid, second)
+	assert.NotEqual(t, first, second)
+	assert.True(t, strings.HasPrefix(first, "testroles_"))
+}
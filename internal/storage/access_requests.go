@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// AccessRequestService represents a service for managing access requests in
+// the permissions API storage
+type AccessRequestService interface {
+	// CreateAccessRequest creates a new pending access request in the database.
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	CreateAccessRequest(ctx context.Context, request types.AccessRequest) (types.AccessRequest, error)
+
+	// GetAccessRequestByID returns an access request by its prefixed ID
+	// an ErrAccessRequestNotFound error is returned if no access request is found
+	GetAccessRequestByID(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error)
+
+	// ListResourceAccessRequests returns the access requests for a given
+	// resource, only those with the given status if one is provided.
+	// an empty slice is returned if no access requests are found
+	ListResourceAccessRequests(ctx context.Context, resourceID gidx.PrefixedID, status types.AccessRequestStatus) ([]types.AccessRequest, error)
+
+	// LockAccessRequest returns the access request with the given ID and locks
+	// it to be decided.
+	// If the access request is not found, an ErrAccessRequestNotFound error is returned.
+	// This method must be called with a context returned from BeginContext.
+	LockAccessRequest(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error)
+
+	// DecideAccessRequest records the decision on a pending access request and
+	// the role binding created for it, if approved.
+	// If the access request is not pending, an ErrAccessRequestNotFound error is returned.
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	DecideAccessRequest(
+		ctx context.Context,
+		id gidx.PrefixedID,
+		status types.AccessRequestStatus,
+		decidedBy gidx.PrefixedID,
+		reason string,
+		rbID gidx.PrefixedID,
+	) (types.AccessRequest, error)
+}
+
+const accessRequestColumns = `id, resource_id, role_id, subject_id, reason, status, created_at, decided_by, decided_at, decision_reason, rolebinding_id`
+
+// scanAccessRequest scans an access request row selected with accessRequestColumns,
+// opening the sealed reasons.
+func (e *engine) scanAccessRequest(ctx context.Context, row rowScanner) (types.AccessRequest, error) {
+	var (
+		req           types.AccessRequest
+		decidedBy     sql.NullString
+		roleBindingID sql.NullString
+	)
+
+	err := row.Scan(
+		&req.ID,
+		&req.ResourceID,
+		&req.RoleID,
+		&req.SubjectID,
+		&req.Reason,
+		&req.Status,
+		&req.CreatedAt,
+		&decidedBy,
+		&req.DecidedAt,
+		&req.DecisionReason,
+		&roleBindingID,
+	)
+	if err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	req.DecidedBy = gidx.PrefixedID(decidedBy.String)
+	req.RoleBindingID = gidx.PrefixedID(roleBindingID.String)
+
+	req.Reason, err = e.openValue(ctx, req.ID.String(), req.Reason)
+	if err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	req.DecisionReason, err = e.openValue(ctx, req.ID.String(), req.DecisionReason)
+	if err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	return req, nil
+}
+
+func (e *engine) CreateAccessRequest(ctx context.Context, request types.AccessRequest) (types.AccessRequest, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	reason, err := e.sealValue(ctx, request.ID.String(), request.Reason)
+	if err != nil {
+		return types.AccessRequest{}, fmt.Errorf("%w: %s", err, request.ID.String())
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		INSERT INTO access_requests (id, resource_id, role_id, subject_id, reason, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+accessRequestColumns,
+		request.ID.String(), request.ResourceID.String(), request.RoleID.String(),
+		request.SubjectID.String(), reason, string(types.AccessRequestPending), request.CreatedAt,
+	)
+
+	req, err := e.scanAccessRequest(ctx, row)
+	if err != nil {
+		return types.AccessRequest{}, fmt.Errorf("%w: %s", err, request.ID.String())
+	}
+
+	return req, nil
+}
+
+func (e *engine) GetAccessRequestByID(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	row := db.QueryRowContext(ctx, `SELECT `+accessRequestColumns+` FROM access_requests WHERE id = $1`, id.String())
+
+	req, err := e.scanAccessRequest(ctx, row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.AccessRequest{}, fmt.Errorf("%w: %s", ErrAccessRequestNotFound, id.String())
+		}
+
+		return types.AccessRequest{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return req, nil
+}
+
+func (e *engine) ListResourceAccessRequests(
+	ctx context.Context,
+	resourceID gidx.PrefixedID,
+	status types.AccessRequestStatus,
+) ([]types.AccessRequest, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+accessRequestColumns+`
+		FROM access_requests WHERE resource_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at ASC
+		`, resourceID.String(), string(status),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+	}
+	defer rows.Close()
+
+	requests := []types.AccessRequest{}
+
+	for rows.Next() {
+		req, err := e.scanAccessRequest(ctx, rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+		}
+
+		requests = append(requests, req)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+	}
+
+	return requests, nil
+}
+
+func (e *engine) LockAccessRequest(ctx context.Context, id gidx.PrefixedID) (types.AccessRequest, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	row := tx.QueryRowContext(ctx, `SELECT `+accessRequestColumns+` FROM access_requests WHERE id = $1 FOR UPDATE`, id.String())
+
+	req, err := e.scanAccessRequest(ctx, row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.AccessRequest{}, fmt.Errorf("%w: %s", ErrAccessRequestNotFound, id.String())
+		}
+
+		return types.AccessRequest{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return req, nil
+}
+
+func (e *engine) DecideAccessRequest(
+	ctx context.Context,
+	id gidx.PrefixedID,
+	status types.AccessRequestStatus,
+	decidedBy gidx.PrefixedID,
+	reason string,
+	rbID gidx.PrefixedID,
+) (types.AccessRequest, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.AccessRequest{}, err
+	}
+
+	decisionReason, err := e.sealValue(ctx, id.String(), reason)
+	if err != nil {
+		return types.AccessRequest{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	var roleBindingID sql.NullString
+
+	if rbID != "" {
+		roleBindingID = sql.NullString{String: rbID.String(), Valid: true}
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		UPDATE access_requests
+		SET status = $1, decided_by = $2, decided_at = now(), decision_reason = $3, rolebinding_id = $4
+		WHERE id = $5 AND status = $6
+		RETURNING `+accessRequestColumns,
+		string(status), decidedBy.String(), decisionReason, roleBindingID, id.String(), string(types.AccessRequestPending),
+	)
+
+	req, err := e.scanAccessRequest(ctx, row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.AccessRequest{}, fmt.Errorf("%w: %s", ErrAccessRequestNotFound, id.String())
+		}
+
+		return types.AccessRequest{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return req, nil
+}
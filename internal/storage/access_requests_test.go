@@ -0,0 +1,168 @@
+package storage_test
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"go.infratographer.com/permissions-api/internal/encryption"
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+)
+
+func TestAccessRequests(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+	approverID := gidx.PrefixedID("idntusr-approver")
+	resourceID := gidx.PrefixedID("tentten-tenant")
+	roleID := gidx.MustNewID("permrv2")
+	rbID := gidx.MustNewID("permrbn")
+	now := time.Now().Truncate(time.Microsecond)
+
+	request := types.AccessRequest{
+		ID:         gidx.MustNewID("permacr"),
+		ResourceID: resourceID,
+		RoleID:     roleID,
+		SubjectID:  gidx.PrefixedID("idntusr-requester"),
+		Reason:     "on call this week",
+		CreatedAt:  now,
+	}
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err, "no error expected beginning transaction context")
+
+	created, err := store.CreateAccessRequest(dbCtx, request)
+	require.NoError(t, err, "no error expected creating access request")
+
+	err = store.CommitContext(dbCtx)
+	require.NoError(t, err, "no error expected committing transaction context")
+
+	assert.Equal(t, request.ID, created.ID)
+	assert.Equal(t, request.Reason, created.Reason)
+	assert.Equal(t, types.AccessRequestPending, created.Status)
+	assert.Empty(t, created.DecidedBy)
+	assert.Nil(t, created.DecidedAt)
+
+	pending, err := store.ListResourceAccessRequests(ctx, resourceID, types.AccessRequestPending)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, request.ID, pending[0].ID)
+
+	tc := []testingx.TestCase[gidx.PrefixedID, types.AccessRequest]{
+		{
+			Name:  "Unknown",
+			Sync:  true,
+			Input: gidx.MustNewID("permacr"),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.AccessRequest]) {
+				assert.ErrorIs(t, res.Err, storage.ErrAccessRequestNotFound)
+			},
+		},
+		{
+			Name:  "Approved",
+			Sync:  true,
+			Input: request.ID,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.AccessRequest]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, types.AccessRequestApproved, res.Success.Status)
+				assert.Equal(t, approverID, res.Success.DecidedBy)
+				assert.Equal(t, "approved for the incident", res.Success.DecisionReason)
+				assert.NotNil(t, res.Success.DecidedAt)
+				assert.Equal(t, rbID, res.Success.RoleBindingID)
+			},
+		},
+		{
+			Name:  "AlreadyDecided",
+			Sync:  true,
+			Input: request.ID,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.AccessRequest]) {
+				assert.ErrorIs(t, res.Err, storage.ErrAccessRequestNotFound)
+			},
+		},
+	}
+
+	testfn := func(ctx context.Context, id gidx.PrefixedID) testingx.TestResult[types.AccessRequest] {
+		dbCtx, err := store.BeginContext(ctx)
+		if err != nil {
+			return testingx.TestResult[types.AccessRequest]{Err: err}
+		}
+
+		req, err := store.LockAccessRequest(dbCtx, id)
+		if err != nil {
+			_ = store.RollbackContext(dbCtx)
+
+			return testingx.TestResult[types.AccessRequest]{Err: err}
+		}
+
+		req, err = store.DecideAccessRequest(dbCtx, req.ID, types.AccessRequestApproved, approverID, "approved for the incident", rbID)
+		if err != nil {
+			_ = store.RollbackContext(dbCtx)
+
+			return testingx.TestResult[types.AccessRequest]{Err: err}
+		}
+
+		return testingx.TestResult[types.AccessRequest]{Success: req, Err: store.CommitContext(dbCtx)}
+	}
+
+	testingx.RunTests(ctx, t, tc, testfn)
+
+	pending, err = store.ListResourceAccessRequests(ctx, resourceID, types.AccessRequestPending)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	all, err := store.ListResourceAccessRequests(ctx, resourceID, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+
+	fetched, err := store.GetAccessRequestByID(ctx, request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.AccessRequestApproved, fetched.Status)
+}
+
+func TestAccessRequestsEncrypted(t *testing.T) {
+	db, closeDB := teststore.NewTestDB(t)
+	t.Cleanup(closeDB)
+
+	ctx := context.Background()
+
+	provider, err := encryption.NewLocalKeyProvider("k1", []string{"k1=" + base64.StdEncoding.EncodeToString(make([]byte, 32))})
+	require.NoError(t, err)
+
+	store := storage.New(db, storage.WithEncryptor(encryption.NewEncryptor(provider)))
+	plainStore := storage.New(db)
+
+	request := types.AccessRequest{
+		ID:         gidx.MustNewID("permacr"),
+		ResourceID: gidx.PrefixedID("tentten-tenant"),
+		RoleID:     gidx.MustNewID("permrv2"),
+		SubjectID:  gidx.PrefixedID("idntusr-requester"),
+		Reason:     "on call this week",
+		CreatedAt:  time.Now().Truncate(time.Microsecond),
+	}
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err)
+
+	created, err := store.CreateAccessRequest(dbCtx, request)
+	require.NoError(t, err)
+
+	decided, err := store.DecideAccessRequest(dbCtx, created.ID, types.AccessRequestDenied, gidx.PrefixedID("idntusr-approver"), "not on call", "")
+	require.NoError(t, err)
+
+	require.NoError(t, store.CommitContext(dbCtx))
+
+	assert.Equal(t, request.Reason, decided.Reason)
+	assert.Equal(t, "not on call", decided.DecisionReason)
+
+	// the reasons are sealed, so they cannot be read without the key
+	_, err = plainStore.GetAccessRequestByID(ctx, request.ID)
+	assert.ErrorIs(t, err, encryption.ErrUnknownKey)
+}
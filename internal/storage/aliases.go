@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// ResourceAliasService represents a service for managing aliases of resources
+// in external systems in the permissions API storage
+type ResourceAliasService interface {
+	// CreateResourceAlias registers an alias for a resource.
+	// If the alias is already registered, an ErrResourceAliasExists error is returned.
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	CreateResourceAlias(ctx context.Context, alias types.ResourceAlias) (types.ResourceAlias, error)
+
+	// GetResourceAlias returns a resource alias
+	// an ErrResourceAliasNotFound error is returned if the alias is not registered
+	GetResourceAlias(ctx context.Context, alias string) (types.ResourceAlias, error)
+
+	// ListResourceAliases returns all aliases of a given resource
+	// an empty slice is returned if no aliases are found
+	ListResourceAliases(ctx context.Context, resourceID gidx.PrefixedID) ([]types.ResourceAlias, error)
+
+	// DeleteResourceAlias deletes a resource alias from the database
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	DeleteResourceAlias(ctx context.Context, alias string) error
+}
+
+const resourceAliasColumns = `alias, resource_id, created_by, created_at`
+
+func scanResourceAlias(row rowScanner) (types.ResourceAlias, error) {
+	var alias types.ResourceAlias
+
+	err := row.Scan(
+		&alias.Alias,
+		&alias.ResourceID,
+		&alias.CreatedBy,
+		&alias.CreatedAt,
+	)
+	if err != nil {
+		return types.ResourceAlias{}, err
+	}
+
+	return alias, nil
+}
+
+func (e *engine) CreateResourceAlias(ctx context.Context, alias types.ResourceAlias) (types.ResourceAlias, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.ResourceAlias{}, err
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		INSERT INTO resource_aliases (alias, resource_id, created_by, created_at)
+		VALUES ($1, $2, $3, now())
+		RETURNING `+resourceAliasColumns,
+		alias.Alias, alias.ResourceID.String(), alias.CreatedBy.String(),
+	)
+
+	out, err := scanResourceAlias(row)
+	if err != nil {
+		if pqIsResourceAliasExistsError(err) {
+			return types.ResourceAlias{}, fmt.Errorf("%w: %s", ErrResourceAliasExists, alias.Alias)
+		}
+
+		return types.ResourceAlias{}, fmt.Errorf("%w: %s", err, alias.Alias)
+	}
+
+	return out, nil
+}
+
+func (e *engine) GetResourceAlias(ctx context.Context, alias string) (types.ResourceAlias, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return types.ResourceAlias{}, err
+	}
+
+	row := db.QueryRowContext(ctx, `SELECT `+resourceAliasColumns+` FROM resource_aliases WHERE alias = $1`, alias)
+
+	out, err := scanResourceAlias(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.ResourceAlias{}, fmt.Errorf("%w: %s", ErrResourceAliasNotFound, alias)
+		}
+
+		return types.ResourceAlias{}, fmt.Errorf("%w: %s", err, alias)
+	}
+
+	return out, nil
+}
+
+func (e *engine) ListResourceAliases(ctx context.Context, resourceID gidx.PrefixedID) ([]types.ResourceAlias, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+resourceAliasColumns+`
+		FROM resource_aliases WHERE resource_id = $1 ORDER BY alias ASC
+		`, resourceID.String(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+	}
+	defer rows.Close()
+
+	aliases := []types.ResourceAlias{}
+
+	for rows.Next() {
+		alias, err := scanResourceAlias(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+		}
+
+		aliases = append(aliases, alias)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+	}
+
+	return aliases, nil
+}
+
+func (e *engine) DeleteResourceAlias(ctx context.Context, alias string) error {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM resource_aliases WHERE alias = $1`, alias)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, alias)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, alias)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrResourceAliasNotFound, alias)
+	}
+
+	return nil
+}
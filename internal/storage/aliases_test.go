@@ -0,0 +1,82 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+)
+
+func TestResourceAliases(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+	actorID := gidx.PrefixedID("idntusr-user")
+	resourceID := gidx.PrefixedID("tnntten-tenant")
+
+	createAlias := func(alias string) (types.ResourceAlias, error) {
+		dbCtx, err := store.BeginContext(ctx)
+		require.NoError(t, err, "no error expected beginning transaction context")
+
+		out, err := store.CreateResourceAlias(dbCtx, types.ResourceAlias{
+			Alias:      alias,
+			ResourceID: resourceID,
+			CreatedBy:  actorID,
+		})
+		if err != nil {
+			require.NoError(t, store.RollbackContext(dbCtx))
+
+			return types.ResourceAlias{}, err
+		}
+
+		require.NoError(t, store.CommitContext(dbCtx), "no error expected committing transaction context")
+
+		return out, nil
+	}
+
+	created, err := createAlias("urn:partner:account:1234")
+	require.NoError(t, err, "no error expected creating alias")
+	assert.Equal(t, resourceID, created.ResourceID)
+	assert.Equal(t, actorID, created.CreatedBy)
+
+	_, err = createAlias("urn:partner:account:1234")
+	assert.ErrorIs(t, err, storage.ErrResourceAliasExists)
+
+	_, err = createAlias("urn:other:tenant:1")
+	require.NoError(t, err, "no error expected creating alias")
+
+	alias, err := store.GetResourceAlias(ctx, "urn:partner:account:1234")
+	require.NoError(t, err)
+	assert.Equal(t, resourceID, alias.ResourceID)
+
+	_, err = store.GetResourceAlias(ctx, "urn:partner:account:unknown")
+	assert.ErrorIs(t, err, storage.ErrResourceAliasNotFound)
+
+	aliases, err := store.ListResourceAliases(ctx, resourceID)
+	require.NoError(t, err)
+	require.Len(t, aliases, 2)
+	assert.Equal(t, "urn:other:tenant:1", aliases[0].Alias)
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, store.DeleteResourceAlias(dbCtx, "urn:partner:account:1234"))
+	require.NoError(t, store.CommitContext(dbCtx))
+
+	_, err = store.GetResourceAlias(ctx, "urn:partner:account:1234")
+	assert.ErrorIs(t, err, storage.ErrResourceAliasNotFound)
+
+	dbCtx, err = store.BeginContext(ctx)
+	require.NoError(t, err)
+
+	err = store.DeleteResourceAlias(dbCtx, "urn:partner:account:1234")
+	assert.ErrorIs(t, err, storage.ErrResourceAliasNotFound)
+	require.NoError(t, store.RollbackContext(dbCtx))
+}
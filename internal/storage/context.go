@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 // TransactionManager manages the state of sql transactions within a context
@@ -39,26 +40,33 @@ func getContextTx(ctx context.Context) (*sql.Tx, error) {
 	}
 }
 
-func getContextDBQuery(ctx context.Context, def DBQuery) (DBQuery, error) {
+func getContextDBQuery(ctx context.Context, e *engine) (DBQuery, error) {
 	tx, err := getContextTx(ctx)
 
 	switch err {
 	case nil:
-		return tx, nil
+		return metricsDBQuery{DBQuery: tx, slow: e.slowQueries}, nil
 	case ErrorMissingContextTx:
-		return def, nil
+		return metricsDBQuery{DBQuery: e.DB, slow: e.slowQueries}, nil
 	default:
 		return nil, err
 	}
 }
 
-func commitContextTx(ctx context.Context) error {
+func commitContextTx(ctx context.Context, slow *slowQueryLog) error {
 	tx, err := getContextTx(ctx)
 	if err != nil {
 		return err
 	}
 
-	return tx.Commit()
+	start := time.Now()
+
+	err = tx.Commit()
+
+	observeQuery("commit", start, err)
+	slow.observe(ctx, "commit", "COMMIT", nil, start)
+
+	return err
 }
 
 func rollbackContextTx(ctx context.Context) error {
@@ -77,7 +85,7 @@ func (e *engine) BeginContext(ctx context.Context) (context.Context, error) {
 
 // CommitContext commits the transaction in the provided context.
 func (e *engine) CommitContext(ctx context.Context) error {
-	return commitContextTx(ctx)
+	return commitContextTx(ctx, e.slowQueries)
 }
 
 // RollbackContext rollsback the transaction in the provided context.
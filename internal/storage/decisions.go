@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// DecisionLogService represents a service for recording the outcome of
+// permission checks.
+type DecisionLogService interface {
+	// RecordDecisions stores the given permission check decisions.
+	RecordDecisions(ctx context.Context, decisions ...types.Decision) error
+
+	// ListResourceDecisions returns the decisions made on a resource since the
+	// given time, oldest first.
+	ListResourceDecisions(ctx context.Context, resourceID gidx.PrefixedID, since time.Time) ([]types.Decision, error)
+}
+
+// decisionColumns is the number of columns inserted per decision.
+const decisionColumns = 8
+
+func (e *engine) RecordDecisions(ctx context.Context, decisions ...types.Decision) error {
+	if len(decisions) == 0 {
+		return nil
+	}
+
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return err
+	}
+
+	values := make([]string, len(decisions))
+	args := make([]any, 0, len(decisions)*decisionColumns)
+
+	for i, d := range decisions {
+		placeholders := make([]string, decisionColumns)
+
+		for j := range placeholders {
+			placeholders[j] = fmt.Sprintf("$%d", i*decisionColumns+j+1)
+		}
+
+		values[i] = "(" + strings.Join(placeholders, ", ") + ")"
+
+		args = append(args,
+			d.DecidedAt,
+			d.SubjectID.String(),
+			d.ResourceID.String(),
+			d.Action,
+			d.Outcome,
+			d.Latency.Microseconds(),
+			d.ZedToken,
+			d.RequestID,
+		)
+	}
+
+	q := `
+		INSERT INTO decision_log (decided_at, subject_id, resource_id, action, outcome, latency_us, zedtoken, request_id)
+		VALUES ` + strings.Join(values, ", ")
+
+	if _, err := db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("failed to record decisions: %w", err)
+	}
+
+	return nil
+}
+
+func (e *engine) ListResourceDecisions(ctx context.Context, resourceID gidx.PrefixedID, since time.Time) ([]types.Decision, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT decided_at, subject_id, resource_id, action, outcome, latency_us, zedtoken, request_id
+		FROM decision_log
+		WHERE resource_id = $1 AND decided_at >= $2
+		ORDER BY decided_at ASC
+	`, resourceID.String(), since)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var decisions []types.Decision
+
+	for rows.Next() {
+		var (
+			d         types.Decision
+			latencyUS int64
+		)
+
+		if err := rows.Scan(&d.DecidedAt, &d.SubjectID, &d.ResourceID, &d.Action, &d.Outcome, &latencyUS, &d.ZedToken, &d.RequestID); err != nil {
+			return nil, err
+		}
+
+		d.Latency = time.Duration(latencyUS) * time.Microsecond
+
+		decisions = append(decisions, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return decisions, nil
+}
@@ -0,0 +1,72 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestRecordDecisions(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+	resourceID := gidx.PrefixedID("tnntten-decisions")
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	decisions := []types.Decision{
+		{
+			SubjectID:  "idntusr-allowed",
+			ResourceID: resourceID,
+			Action:     "loadbalancer_get",
+			Outcome:    "allowed",
+			Latency:    1500 * time.Microsecond,
+			ZedToken:   "token",
+			RequestID:  "request",
+			DecidedAt:  now.Add(-time.Minute),
+		},
+		{
+			SubjectID:  "idntusr-denied",
+			ResourceID: resourceID,
+			Action:     "loadbalancer_delete",
+			Outcome:    "denied",
+			Latency:    time.Millisecond,
+			DecidedAt:  now,
+		},
+		{
+			SubjectID:  "idntusr-old",
+			ResourceID: resourceID,
+			Action:     "loadbalancer_get",
+			Outcome:    "allowed",
+			DecidedAt:  now.Add(-48 * time.Hour),
+		},
+		{
+			SubjectID:  "idntusr-allowed",
+			ResourceID: "tnntten-other",
+			Action:     "loadbalancer_get",
+			Outcome:    "allowed",
+			DecidedAt:  now,
+		},
+	}
+
+	require.NoError(t, store.RecordDecisions(ctx, decisions...))
+	require.NoError(t, store.RecordDecisions(ctx))
+
+	out, err := store.ListResourceDecisions(ctx, resourceID, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+
+	assert.Equal(t, decisions[0].SubjectID, out[0].SubjectID)
+	assert.Equal(t, decisions[0].Latency, out[0].Latency)
+	assert.Equal(t, "token", out[0].ZedToken)
+	assert.Equal(t, "request", out[0].RequestID)
+	assert.WithinDuration(t, decisions[0].DecidedAt, out[0].DecidedAt, 0)
+	assert.Equal(t, "denied", out[1].Outcome)
+}
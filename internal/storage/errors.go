@@ -14,6 +14,7 @@ var (
 	ErrRoleAlreadyExists = errors.New("role already exists")
 
 	// ErrRoleNameTaken is returned when the role name provided already exists under the same resource id.
+	// Role names are compared case-insensitively.
 	ErrRoleNameTaken = errors.New("role name already taken")
 
 	// ErrMethodUnavailable is returned when the provided method is called is unavailable in the current environment.
@@ -28,6 +29,30 @@ var (
 
 	// ErrRoleBindingNotFound is returned when no role binding is found when retrieving or deleting a role binding.
 	ErrRoleBindingNotFound = errors.New("role binding not found")
+
+	// ErrInvitationNotFound is returned when no invitation is found when retrieving, redeeming or deleting an invitation.
+	ErrInvitationNotFound = errors.New("invitation not found")
+
+	// ErrAccessRequestNotFound is returned when no pending access request is found when retrieving or deciding an access request.
+	ErrAccessRequestNotFound = errors.New("access request not found")
+
+	// ErrGroupNotFound is returned when no group is found when retrieving, updating or deleting a group.
+	ErrGroupNotFound = errors.New("group not found")
+
+	// ErrGroupNameTaken is returned when the group name provided already exists under the same owner.
+	ErrGroupNameTaken = errors.New("group name already taken")
+
+	// ErrResourceAliasNotFound is returned when no resource alias is found when resolving or deleting an alias.
+	ErrResourceAliasNotFound = errors.New("resource alias not found")
+
+	// ErrResourceAliasExists is returned when registering an alias which is already registered.
+	ErrResourceAliasExists = errors.New("resource alias already exists")
+
+	// ErrTenantSettingsNotFound is returned when no settings are stored for a tenant.
+	ErrTenantSettingsNotFound = errors.New("tenant settings not found")
+
+	// ErrWebhookDeliveryNotFound is returned when updating a webhook delivery which does not exist.
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
 )
 
 const (
@@ -35,7 +60,9 @@ const (
 	pgErrCodeUniqueViolation = "23505"
 
 	pqIndexRolesPrimaryKey     = "roles_pkey"
-	pqIndexRolesResourceIDName = "roles_resource_id_name"
+	pqIndexRolesResourceIDName = "roles_resource_id_lower_name"
+	pqIndexGroupsOwnerIDName   = "groups_owner_id_name"
+	pqIndexResourceAliasesPKey = "resource_aliases_pkey"
 )
 
 // pqIsRoleAlreadyExistsError checks that the provided error is a postgres error.
@@ -53,7 +80,7 @@ func pqIsRoleAlreadyExistsError(err error) bool {
 // pqIsRoleNameTakenError checks that the provided error is a postgres error.
 // If so, checks if postgres threw a unique_violation error on the roles resource id name index.
 // If postgres has raised a unique violation error on this index it means a record already exists
-// with the same resource id and role name combination.
+// with the same resource id and case-insensitive role name combination.
 func pqIsRoleNameTakenError(err error) bool {
 	if pgErr, ok := err.(*pgconn.PgError); ok {
 		return pgErr.Code == pgErrCodeUniqueViolation && pgErr.ConstraintName == pqIndexRolesResourceIDName
@@ -61,3 +88,23 @@ func pqIsRoleNameTakenError(err error) bool {
 
 	return false
 }
+
+// pqIsGroupNameTakenError checks that the provided error is a postgres error.
+// If so, checks if postgres threw a unique_violation error on the groups owner id name index.
+func pqIsGroupNameTakenError(err error) bool {
+	if pgErr, ok := err.(*pgconn.PgError); ok {
+		return pgErr.Code == pgErrCodeUniqueViolation && pgErr.ConstraintName == pqIndexGroupsOwnerIDName
+	}
+
+	return false
+}
+
+// pqIsResourceAliasExistsError checks that the provided error is a postgres error.
+// If so, checks if postgres threw a unique_violation error on the resource aliases primary key index.
+func pqIsResourceAliasExistsError(err error) bool {
+	if pgErr, ok := err.(*pgconn.PgError); ok {
+		return pgErr.Code == pgErrCodeUniqueViolation && pgErr.ConstraintName == pqIndexResourceAliasesPKey
+	}
+
+	return false
+}
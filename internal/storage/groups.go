@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// GroupService represents a service for managing group metadata in the
+// permissions API storage, group members are stored in SpiceDB.
+type GroupService interface {
+	// CreateGroup creates a new group in the database.
+	// If a group with the same name already exists under the same owner, an
+	// ErrGroupNameTaken error is returned.
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	CreateGroup(ctx context.Context, group types.Group) (types.Group, error)
+
+	// GetGroupByID returns a group by its prefixed ID
+	// an ErrGroupNotFound error is returned if no group is found
+	GetGroupByID(ctx context.Context, id gidx.PrefixedID) (types.Group, error)
+
+	// ListOwnerGroups returns all groups owned by a given resource
+	// an empty slice is returned if no groups are found
+	ListOwnerGroups(ctx context.Context, ownerID gidx.PrefixedID) ([]types.Group, error)
+
+	// UpdateGroup updates the name and description of a group.
+	// If the new name is already taken under the same owner, an ErrGroupNameTaken error is returned.
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	UpdateGroup(ctx context.Context, actorID, id gidx.PrefixedID, name, description string) (types.Group, error)
+
+	// DeleteGroup deletes a group from the database and returns the deleted group.
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	DeleteGroup(ctx context.Context, id gidx.PrefixedID) (types.Group, error)
+}
+
+const groupColumns = `id, owner_id, name, description, created_by, updated_by, created_at, updated_at`
+
+func scanGroup(row rowScanner) (types.Group, error) {
+	var group types.Group
+
+	err := row.Scan(
+		&group.ID,
+		&group.OwnerID,
+		&group.Name,
+		&group.Description,
+		&group.CreatedBy,
+		&group.UpdatedBy,
+		&group.CreatedAt,
+		&group.UpdatedAt,
+	)
+	if err != nil {
+		return types.Group{}, err
+	}
+
+	return group, nil
+}
+
+func (e *engine) CreateGroup(ctx context.Context, group types.Group) (types.Group, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.Group{}, err
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		INSERT INTO groups (id, owner_id, name, description, created_by, updated_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5, now(), now())
+		RETURNING `+groupColumns,
+		group.ID.String(), group.OwnerID.String(), group.Name, group.Description, group.CreatedBy.String(),
+	)
+
+	out, err := scanGroup(row)
+	if err != nil {
+		if pqIsGroupNameTakenError(err) {
+			return types.Group{}, fmt.Errorf("%w: %s", ErrGroupNameTaken, group.Name)
+		}
+
+		return types.Group{}, fmt.Errorf("%w: %s", err, group.ID.String())
+	}
+
+	return out, nil
+}
+
+func (e *engine) GetGroupByID(ctx context.Context, id gidx.PrefixedID) (types.Group, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return types.Group{}, err
+	}
+
+	row := db.QueryRowContext(ctx, `SELECT `+groupColumns+` FROM groups WHERE id = $1`, id.String())
+
+	group, err := scanGroup(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.Group{}, fmt.Errorf("%w: %s", ErrGroupNotFound, id.String())
+		}
+
+		return types.Group{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return group, nil
+}
+
+func (e *engine) ListOwnerGroups(ctx context.Context, ownerID gidx.PrefixedID) ([]types.Group, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+groupColumns+`
+		FROM groups WHERE owner_id = $1 ORDER BY name ASC
+		`, ownerID.String(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, ownerID.String())
+	}
+	defer rows.Close()
+
+	groups := []types.Group{}
+
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, ownerID.String())
+		}
+
+		groups = append(groups, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, ownerID.String())
+	}
+
+	return groups, nil
+}
+
+func (e *engine) UpdateGroup(ctx context.Context, actorID, id gidx.PrefixedID, name, description string) (types.Group, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.Group{}, err
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		UPDATE groups SET name = $1, description = $2, updated_by = $3, updated_at = now() WHERE id = $4
+		RETURNING `+groupColumns,
+		name, description, actorID.String(), id.String(),
+	)
+
+	group, err := scanGroup(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.Group{}, fmt.Errorf("%w: %s", ErrGroupNotFound, id.String())
+		}
+
+		if pqIsGroupNameTakenError(err) {
+			return types.Group{}, fmt.Errorf("%w: %s", ErrGroupNameTaken, name)
+		}
+
+		return types.Group{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return group, nil
+}
+
+func (e *engine) DeleteGroup(ctx context.Context, id gidx.PrefixedID) (types.Group, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.Group{}, err
+	}
+
+	row := tx.QueryRowContext(ctx, `DELETE FROM groups WHERE id = $1 RETURNING `+groupColumns, id.String())
+
+	group, err := scanGroup(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.Group{}, fmt.Errorf("%w: %s", ErrGroupNotFound, id.String())
+		}
+
+		return types.Group{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return group, nil
+}
@@ -0,0 +1,117 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+)
+
+func TestGroups(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+	actorID := gidx.PrefixedID("idntusr-user")
+	ownerID := gidx.PrefixedID("tnntten-tenant")
+
+	createGroup := func(name string) (types.Group, error) {
+		dbCtx, err := store.BeginContext(ctx)
+		require.NoError(t, err, "no error expected beginning transaction context")
+
+		group, err := store.CreateGroup(dbCtx, types.Group{
+			ID:          gidx.MustNewID("idntgrp"),
+			OwnerID:     ownerID,
+			Name:        name,
+			Description: name + " group",
+			CreatedBy:   actorID,
+		})
+		if err != nil {
+			require.NoError(t, store.RollbackContext(dbCtx))
+
+			return types.Group{}, err
+		}
+
+		require.NoError(t, store.CommitContext(dbCtx), "no error expected committing transaction context")
+
+		return group, nil
+	}
+
+	admins, err := createGroup("admins")
+	require.NoError(t, err, "no error expected creating group")
+	assert.Equal(t, ownerID, admins.OwnerID)
+	assert.Equal(t, actorID, admins.UpdatedBy)
+
+	_, err = createGroup("admins")
+	assert.ErrorIs(t, err, storage.ErrGroupNameTaken)
+
+	_, err = createGroup("viewers")
+	require.NoError(t, err, "no error expected creating group")
+
+	groups, err := store.ListOwnerGroups(ctx, ownerID)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "admins", groups[0].Name)
+
+	tc := []testingx.TestCase[string, types.Group]{
+		{
+			Name:  "NameTaken",
+			Sync:  true,
+			Input: "viewers",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Group]) {
+				assert.ErrorIs(t, res.Err, storage.ErrGroupNameTaken)
+			},
+		},
+		{
+			Name:  "Updated",
+			Sync:  true,
+			Input: "operators",
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[types.Group]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, "operators", res.Success.Name)
+
+				group, err := store.GetGroupByID(ctx, admins.ID)
+				require.NoError(t, err)
+				assert.Equal(t, "operators", group.Name)
+				assert.Equal(t, "renamed", group.Description)
+			},
+		},
+	}
+
+	testFn := func(ctx context.Context, name string) testingx.TestResult[types.Group] {
+		dbCtx, err := store.BeginContext(ctx)
+		if err != nil {
+			return testingx.TestResult[types.Group]{Err: err}
+		}
+
+		group, err := store.UpdateGroup(dbCtx, actorID, admins.ID, name, "renamed")
+		if err != nil {
+			_ = store.RollbackContext(dbCtx)
+
+			return testingx.TestResult[types.Group]{Err: err}
+		}
+
+		return testingx.TestResult[types.Group]{Success: group, Err: store.CommitContext(dbCtx)}
+	}
+
+	testingx.RunTests(ctx, t, tc, testFn)
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err)
+
+	deleted, err := store.DeleteGroup(dbCtx, admins.ID)
+	require.NoError(t, err)
+	assert.Equal(t, admins.ID, deleted.ID)
+
+	require.NoError(t, store.CommitContext(dbCtx))
+
+	_, err = store.GetGroupByID(ctx, admins.ID)
+	assert.ErrorIs(t, err, storage.ErrGroupNotFound)
+}
@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// InvitationService represents a service for managing role binding
+// invitations in the permissions API storage
+type InvitationService interface {
+	// CreateInvitation creates a new invitation in the database, only the hash
+	// of the invitation token is stored.
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	CreateInvitation(ctx context.Context, invitation types.Invitation, tokenHash string) (types.Invitation, error)
+
+	// GetInvitationByID returns an invitation by its prefixed ID
+	// an ErrInvitationNotFound error is returned if no invitation is found
+	GetInvitationByID(ctx context.Context, id gidx.PrefixedID) (types.Invitation, error)
+
+	// ListResourceInvitations returns all invitations for a given resource
+	// an empty slice is returned if no invitations are found
+	ListResourceInvitations(ctx context.Context, resourceID gidx.PrefixedID) ([]types.Invitation, error)
+
+	// LockInvitationByTokenHash returns the invitation with the given token hash
+	// and locks it to be redeemed.
+	// If the invitation is not found, an ErrInvitationNotFound error is returned.
+	// This method must be called with a context returned from BeginContext.
+	LockInvitationByTokenHash(ctx context.Context, tokenHash string) (types.Invitation, error)
+
+	// RedeemInvitation records the subject which redeemed the invitation and
+	// the role binding created for it.
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	RedeemInvitation(ctx context.Context, id, subjectID, rbID gidx.PrefixedID) (types.Invitation, error)
+
+	// DeleteInvitation deletes an invitation from the database
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	DeleteInvitation(ctx context.Context, id gidx.PrefixedID) error
+}
+
+const invitationColumns = `id, resource_id, role_id, email, created_by, created_at, expires_at, redeemed_by, redeemed_at, rolebinding_id`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanInvitation scans an invitation row selected with invitationColumns,
+// opening the sealed email.
+func (e *engine) scanInvitation(ctx context.Context, row rowScanner) (types.Invitation, error) {
+	var (
+		inv           types.Invitation
+		redeemedBy    sql.NullString
+		roleBindingID sql.NullString
+	)
+
+	err := row.Scan(
+		&inv.ID,
+		&inv.ResourceID,
+		&inv.RoleID,
+		&inv.Email,
+		&inv.CreatedBy,
+		&inv.CreatedAt,
+		&inv.ExpiresAt,
+		&redeemedBy,
+		&inv.RedeemedAt,
+		&roleBindingID,
+	)
+	if err != nil {
+		return types.Invitation{}, err
+	}
+
+	inv.RedeemedBy = gidx.PrefixedID(redeemedBy.String)
+	inv.RoleBindingID = gidx.PrefixedID(roleBindingID.String)
+
+	inv.Email, err = e.openValue(ctx, inv.ID.String(), inv.Email)
+	if err != nil {
+		return types.Invitation{}, err
+	}
+
+	return inv, nil
+}
+
+func (e *engine) CreateInvitation(ctx context.Context, invitation types.Invitation, tokenHash string) (types.Invitation, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.Invitation{}, err
+	}
+
+	email, err := e.sealValue(ctx, invitation.ID.String(), invitation.Email)
+	if err != nil {
+		return types.Invitation{}, fmt.Errorf("%w: %s", err, invitation.ID.String())
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		INSERT INTO invitations (id, resource_id, role_id, token_hash, email, created_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+invitationColumns,
+		invitation.ID.String(), invitation.ResourceID.String(), invitation.RoleID.String(),
+		tokenHash, email, invitation.CreatedBy.String(), invitation.CreatedAt, invitation.ExpiresAt,
+	)
+
+	inv, err := e.scanInvitation(ctx, row)
+	if err != nil {
+		return types.Invitation{}, fmt.Errorf("%w: %s", err, invitation.ID.String())
+	}
+
+	return inv, nil
+}
+
+func (e *engine) GetInvitationByID(ctx context.Context, id gidx.PrefixedID) (types.Invitation, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return types.Invitation{}, err
+	}
+
+	row := db.QueryRowContext(ctx, `SELECT `+invitationColumns+` FROM invitations WHERE id = $1`, id.String())
+
+	inv, err := e.scanInvitation(ctx, row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.Invitation{}, fmt.Errorf("%w: %s", ErrInvitationNotFound, id.String())
+		}
+
+		return types.Invitation{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return inv, nil
+}
+
+func (e *engine) ListResourceInvitations(ctx context.Context, resourceID gidx.PrefixedID) ([]types.Invitation, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+invitationColumns+`
+		FROM invitations WHERE resource_id = $1 ORDER BY created_at ASC
+		`, resourceID.String(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+	}
+	defer rows.Close()
+
+	invitations := []types.Invitation{}
+
+	for rows.Next() {
+		inv, err := e.scanInvitation(ctx, rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+		}
+
+		invitations = append(invitations, inv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+	}
+
+	return invitations, nil
+}
+
+func (e *engine) LockInvitationByTokenHash(ctx context.Context, tokenHash string) (types.Invitation, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.Invitation{}, err
+	}
+
+	row := tx.QueryRowContext(ctx, `SELECT `+invitationColumns+` FROM invitations WHERE token_hash = $1 FOR UPDATE`, tokenHash)
+
+	inv, err := e.scanInvitation(ctx, row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.Invitation{}, ErrInvitationNotFound
+		}
+
+		return types.Invitation{}, err
+	}
+
+	return inv, nil
+}
+
+func (e *engine) RedeemInvitation(ctx context.Context, id, subjectID, rbID gidx.PrefixedID) (types.Invitation, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.Invitation{}, err
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		UPDATE invitations
+		SET redeemed_by = $1, redeemed_at = now(), rolebinding_id = $2
+		WHERE id = $3 AND redeemed_at IS NULL
+		RETURNING `+invitationColumns,
+		subjectID.String(), rbID.String(), id.String(),
+	)
+
+	inv, err := e.scanInvitation(ctx, row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.Invitation{}, fmt.Errorf("%w: %s", ErrInvitationNotFound, id.String())
+		}
+
+		return types.Invitation{}, fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	return inv, nil
+}
+
+func (e *engine) DeleteInvitation(ctx context.Context, id gidx.PrefixedID) error {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM invitations WHERE id = $1`, id.String())
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, id.String())
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrInvitationNotFound, id.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+)
+
+func TestInvitations(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+	actorID := gidx.PrefixedID("idntusr-user")
+	subjectID := gidx.PrefixedID("idntusr-invitee")
+	resourceID := gidx.PrefixedID("tentten-tenant")
+	roleID := gidx.MustNewID("permrv2")
+	now := time.Now().Truncate(time.Microsecond)
+
+	invitation := types.Invitation{
+		ID:         gidx.MustNewID("perminv"),
+		ResourceID: resourceID,
+		RoleID:     roleID,
+		Email:      "invitee@example.com",
+		CreatedBy:  actorID,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(time.Hour),
+	}
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err, "no error expected beginning transaction context")
+
+	created, err := store.CreateInvitation(dbCtx, invitation, "token-hash")
+	require.NoError(t, err, "no error expected creating invitation")
+
+	err = store.CommitContext(dbCtx)
+	require.NoError(t, err, "no error expected committing transaction context")
+
+	assert.Equal(t, invitation.ID, created.ID)
+	assert.Equal(t, invitation.Email, created.Email)
+	assert.Empty(t, created.RedeemedBy)
+	assert.Nil(t, created.RedeemedAt)
+
+	invitations, err := store.ListResourceInvitations(ctx, resourceID)
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+	assert.Equal(t, invitation.ID, invitations[0].ID)
+
+	tc := []testingx.TestCase[string, types.Invitation]{
+		{
+			Name:  "UnknownToken",
+			Sync:  true,
+			Input: "unknown-hash",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Invitation]) {
+				assert.ErrorIs(t, res.Err, storage.ErrInvitationNotFound)
+			},
+		},
+		{
+			Name:  "Redeemed",
+			Sync:  true,
+			Input: "token-hash",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Invitation]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, subjectID, res.Success.RedeemedBy)
+				assert.NotNil(t, res.Success.RedeemedAt)
+				assert.NotEmpty(t, res.Success.RoleBindingID)
+			},
+		},
+		{
+			Name:  "AlreadyRedeemed",
+			Sync:  true,
+			Input: "token-hash",
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[types.Invitation]) {
+				assert.ErrorIs(t, res.Err, storage.ErrInvitationNotFound)
+			},
+		},
+	}
+
+	testfn := func(ctx context.Context, tokenHash string) testingx.TestResult[types.Invitation] {
+		dbCtx, err := store.BeginContext(ctx)
+		if err != nil {
+			return testingx.TestResult[types.Invitation]{Err: err}
+		}
+
+		inv, err := store.LockInvitationByTokenHash(dbCtx, tokenHash)
+		if err != nil {
+			_ = store.RollbackContext(dbCtx)
+
+			return testingx.TestResult[types.Invitation]{Err: err}
+		}
+
+		inv, err = store.RedeemInvitation(dbCtx, inv.ID, subjectID, gidx.MustNewID("permrbn"))
+		if err != nil {
+			_ = store.RollbackContext(dbCtx)
+
+			return testingx.TestResult[types.Invitation]{Err: err}
+		}
+
+		return testingx.TestResult[types.Invitation]{Success: inv, Err: store.CommitContext(dbCtx)}
+	}
+
+	testingx.RunTests(ctx, t, tc, testfn)
+
+	dbCtx, err = store.BeginContext(ctx)
+	require.NoError(t, err, "no error expected beginning transaction context")
+
+	require.NoError(t, store.DeleteInvitation(dbCtx, invitation.ID))
+	require.NoError(t, store.CommitContext(dbCtx))
+
+	_, err = store.GetInvitationByID(ctx, invitation.ID)
+	assert.ErrorIs(t, err, storage.ErrInvitationNotFound)
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var (
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "permissions_api",
+		Subsystem: "storage",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of database queries by operation (query, query_row, exec or commit) and result (ok or error).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "result"})
+
+	slowQueries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "permissions_api",
+		Subsystem: "storage",
+		Name:      "slow_queries_total",
+		Help:      "Number of database queries taking longer than the slow query threshold by operation.",
+	}, []string{"operation"})
+)
+
+// observeQuery records a database query started at the given time.
+func observeQuery(operation string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+
+	queryDuration.WithLabelValues(operation, result).Observe(time.Since(start).Seconds())
+}
+
+// slowQueryLog logs database queries taking longer than the threshold with
+// their arguments. The threshold can be changed while queries are running.
+type slowQueryLog struct {
+	threshold atomic.Int64
+	logger    *zap.SugaredLogger
+}
+
+// setThreshold changes the threshold, zero disables logging slow queries.
+func (l *slowQueryLog) setThreshold(threshold time.Duration) {
+	l.threshold.Store(int64(threshold))
+}
+
+// observe logs the query if it took longer than the threshold.
+func (l *slowQueryLog) observe(ctx context.Context, operation, query string, args []any, start time.Time) {
+	threshold := time.Duration(l.threshold.Load())
+
+	duration := time.Since(start)
+	if threshold <= 0 || duration < threshold {
+		return
+	}
+
+	slowQueries.WithLabelValues(operation).Inc()
+
+	fields := []any{
+		"operation", operation,
+		"duration", duration,
+		"query", query,
+		"args", args,
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		fields = append(fields, "trace_id", sc.TraceID().String())
+	}
+
+	l.logger.Warnw("slow database query", fields...)
+}
+
+// metricsDBQuery records the duration of all queries run with the wrapped
+// DBQuery and logs slow queries.
+type metricsDBQuery struct {
+	DBQuery
+
+	slow *slowQueryLog
+}
+
+func (q metricsDBQuery) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+
+	rows, err := q.DBQuery.QueryContext(ctx, query, args...)
+
+	observeQuery("query", start, err)
+	q.slow.observe(ctx, "query", query, args, start)
+
+	return rows, err
+}
+
+func (q metricsDBQuery) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
+
+	row := q.DBQuery.QueryRowContext(ctx, query, args...)
+
+	observeQuery("query_row", start, row.Err())
+	q.slow.observe(ctx, "query_row", query, args, start)
+
+	return row
+}
+
+func (q metricsDBQuery) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+
+	result, err := q.DBQuery.ExecContext(ctx, query, args...)
+
+	observeQuery("exec", start, err)
+	q.slow.observe(ctx, "exec", query, args, start)
+
+	return result, err
+}
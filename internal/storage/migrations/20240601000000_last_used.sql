@@ -0,0 +1,16 @@
+-- +goose Up
+
+-- add "last_used_at" to "roles" and "rolebindings" tables
+ALTER TABLE "roles" ADD COLUMN "last_used_at" timestamptz NULL;
+ALTER TABLE "rolebindings" ADD COLUMN "last_used_at" timestamptz NULL;
+
+-- create index "rolebindings_resource_id_last_used_at" to table: "rolebindings"
+CREATE INDEX "rolebindings_resource_id_last_used_at" ON "rolebindings" ("resource_id", "last_used_at");
+
+-- +goose Down
+-- reverse: create index "rolebindings_resource_id_last_used_at" to table: "rolebindings"
+DROP INDEX "rolebindings_resource_id_last_used_at";
+
+-- reverse: add "last_used_at" to "roles" and "rolebindings" tables
+ALTER TABLE "rolebindings" DROP COLUMN "last_used_at";
+ALTER TABLE "roles" DROP COLUMN "last_used_at";
@@ -0,0 +1,30 @@
+-- +goose Up
+
+-- create "invitations" table
+CREATE TABLE "invitations" (
+  "id" character varying NOT NULL,
+  "resource_id" character varying NOT NULL,
+  "role_id" character varying NOT NULL,
+  "token_hash" character varying NOT NULL,
+  "email" character varying NOT NULL DEFAULT '',
+  "created_by" character varying NOT NULL,
+  "created_at" timestamptz NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "redeemed_by" character varying NULL,
+  "redeemed_at" timestamptz NULL,
+  "rolebinding_id" character varying NULL,
+  PRIMARY KEY ("id")
+);
+
+-- create index "invitations_token_hash" to table: "invitations"
+CREATE UNIQUE INDEX "invitations_token_hash" ON "invitations" ("token_hash");
+-- create index "invitations_resource_id_created_at" to table: "invitations"
+CREATE INDEX "invitations_resource_id_created_at" ON "invitations" ("resource_id", "created_at");
+
+-- +goose Down
+-- reverse: create index "invitations_resource_id_created_at" to table: "invitations"
+DROP INDEX "invitations_resource_id_created_at";
+-- reverse: create index "invitations_token_hash" to table: "invitations"
+DROP INDEX "invitations_token_hash";
+-- reverse: create "invitations" table
+DROP TABLE "invitations";
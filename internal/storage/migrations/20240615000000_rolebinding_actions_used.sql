@@ -0,0 +1,14 @@
+-- +goose Up
+
+-- create "rolebinding_actions_used" table
+CREATE TABLE "rolebinding_actions_used" (
+  "rolebinding_id" character varying NOT NULL,
+  "action" character varying NOT NULL,
+  "last_used_at" timestamptz NOT NULL,
+  PRIMARY KEY ("rolebinding_id", "action"),
+  CONSTRAINT "rolebinding_actions_used_rolebinding_id" FOREIGN KEY ("rolebinding_id") REFERENCES "rolebindings" ("id") ON DELETE CASCADE
+);
+
+-- +goose Down
+-- reverse: create "rolebinding_actions_used" table
+DROP TABLE "rolebinding_actions_used";
@@ -0,0 +1,23 @@
+-- +goose Up
+
+-- create "groups" table
+CREATE TABLE "groups" (
+  "id" character varying NOT NULL,
+  "owner_id" character varying NOT NULL,
+  "name" character varying(64) NOT NULL,
+  "description" character varying NOT NULL DEFAULT '',
+  "created_by" character varying NOT NULL,
+  "updated_by" character varying NOT NULL,
+  "created_at" timestamptz NOT NULL,
+  "updated_at" timestamptz NOT NULL,
+  PRIMARY KEY ("id")
+);
+
+-- create index "groups_owner_id_name" to table: "groups"
+CREATE UNIQUE INDEX "groups_owner_id_name" ON "groups" ("owner_id", "name");
+
+-- +goose Down
+-- reverse: create index "groups_owner_id_name" to table: "groups"
+DROP INDEX "groups_owner_id_name";
+-- reverse: create "groups" table
+DROP TABLE "groups";
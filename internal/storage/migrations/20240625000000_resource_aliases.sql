@@ -0,0 +1,19 @@
+-- +goose Up
+
+-- create "resource_aliases" table
+CREATE TABLE "resource_aliases" (
+  "alias" character varying NOT NULL,
+  "resource_id" character varying NOT NULL,
+  "created_by" character varying NOT NULL,
+  "created_at" timestamptz NOT NULL,
+  PRIMARY KEY ("alias")
+);
+
+-- create index "resource_aliases_resource_id" to table: "resource_aliases"
+CREATE INDEX "resource_aliases_resource_id" ON "resource_aliases" ("resource_id");
+
+-- +goose Down
+-- reverse: create index "resource_aliases_resource_id" to table: "resource_aliases"
+DROP INDEX "resource_aliases_resource_id";
+-- reverse: create "resource_aliases" table
+DROP TABLE "resource_aliases";
@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- create "tenant_settings" table
+CREATE TABLE "tenant_settings" (
+  "tenant_id" character varying NOT NULL,
+  "allow_group_bindings" boolean NOT NULL DEFAULT true,
+  "inherit_parent_roles" boolean NOT NULL DEFAULT true,
+  "updated_by" character varying NOT NULL,
+  "updated_at" timestamptz NOT NULL,
+  PRIMARY KEY ("tenant_id")
+);
+
+-- +goose Down
+-- reverse: create "tenant_settings" table
+DROP TABLE "tenant_settings";
@@ -0,0 +1,12 @@
+-- +goose Up
+
+-- create index "roles_resource_id_lower_name" to table: "roles"
+CREATE UNIQUE INDEX "roles_resource_id_lower_name" ON "roles" ("resource_id", lower("name"));
+-- drop index "roles_resource_id_name" from table: "roles"
+DROP INDEX "roles_resource_id_name";
+
+-- +goose Down
+-- reverse: drop index "roles_resource_id_name" from table: "roles"
+CREATE UNIQUE INDEX "roles_resource_id_name" ON "roles" ("resource_id", "name");
+-- reverse: create index "roles_resource_id_lower_name" to table: "roles"
+DROP INDEX "roles_resource_id_lower_name";
@@ -0,0 +1,31 @@
+-- +goose Up
+
+-- create "decision_log" table
+CREATE TABLE "decision_log" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "decided_at" timestamptz NOT NULL,
+  "subject_id" character varying NOT NULL,
+  "resource_id" character varying NOT NULL,
+  "action" character varying NOT NULL,
+  "outcome" character varying NOT NULL,
+  "latency_us" bigint NOT NULL,
+  "zedtoken" character varying NOT NULL DEFAULT '',
+  "request_id" character varying NOT NULL DEFAULT '',
+  PRIMARY KEY ("id")
+);
+
+-- create index "decision_log_resource_id_decided_at" to table: "decision_log"
+CREATE INDEX "decision_log_resource_id_decided_at" ON "decision_log" ("resource_id", "decided_at");
+
+-- create index "decision_log_subject_id_decided_at" to table: "decision_log"
+CREATE INDEX "decision_log_subject_id_decided_at" ON "decision_log" ("subject_id", "decided_at");
+
+-- +goose Down
+-- reverse: create index "decision_log_subject_id_decided_at" to table: "decision_log"
+DROP INDEX "decision_log_subject_id_decided_at";
+
+-- reverse: create index "decision_log_resource_id_decided_at" to table: "decision_log"
+DROP INDEX "decision_log_resource_id_decided_at";
+
+-- reverse: create "decision_log" table
+DROP TABLE "decision_log";
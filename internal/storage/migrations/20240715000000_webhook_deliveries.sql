@@ -0,0 +1,30 @@
+-- +goose Up
+
+-- create "webhook_deliveries" table
+CREATE TABLE "webhook_deliveries" (
+  "id" character varying NOT NULL,
+  "webhook" character varying NOT NULL,
+  "event_type" character varying NOT NULL,
+  "payload" bytea NOT NULL,
+  "status" character varying NOT NULL,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "response_code" integer NOT NULL DEFAULT 0,
+  "last_error" character varying NOT NULL DEFAULT '',
+  "next_attempt_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL,
+  "updated_at" timestamptz NOT NULL,
+  PRIMARY KEY ("id")
+);
+
+-- create index "webhook_deliveries_status_next_attempt_at" to table: "webhook_deliveries"
+CREATE INDEX "webhook_deliveries_status_next_attempt_at" ON "webhook_deliveries" ("status", "next_attempt_at");
+-- create index "webhook_deliveries_webhook_created_at" to table: "webhook_deliveries"
+CREATE INDEX "webhook_deliveries_webhook_created_at" ON "webhook_deliveries" ("webhook", "created_at");
+
+-- +goose Down
+-- reverse: create index "webhook_deliveries_webhook_created_at" to table: "webhook_deliveries"
+DROP INDEX "webhook_deliveries_webhook_created_at";
+-- reverse: create index "webhook_deliveries_status_next_attempt_at" to table: "webhook_deliveries"
+DROP INDEX "webhook_deliveries_status_next_attempt_at";
+-- reverse: create "webhook_deliveries" table
+DROP TABLE "webhook_deliveries";
@@ -0,0 +1,26 @@
+-- +goose Up
+
+-- create "access_requests" table
+CREATE TABLE "access_requests" (
+  "id" character varying NOT NULL,
+  "resource_id" character varying NOT NULL,
+  "role_id" character varying NOT NULL,
+  "subject_id" character varying NOT NULL,
+  "reason" character varying NOT NULL DEFAULT '',
+  "status" character varying NOT NULL DEFAULT 'pending',
+  "created_at" timestamptz NOT NULL,
+  "decided_by" character varying NULL,
+  "decided_at" timestamptz NULL,
+  "decision_reason" character varying NOT NULL DEFAULT '',
+  "rolebinding_id" character varying NULL,
+  PRIMARY KEY ("id")
+);
+
+-- create index "access_requests_resource_id_created_at" to table: "access_requests"
+CREATE INDEX "access_requests_resource_id_created_at" ON "access_requests" ("resource_id", "created_at");
+
+-- +goose Down
+-- reverse: create index "access_requests_resource_id_created_at" to table: "access_requests"
+DROP INDEX "access_requests_resource_id_created_at";
+-- reverse: create "access_requests" table
+DROP TABLE "access_requests";
@@ -1,6 +1,12 @@
 package storage
 
-import "go.uber.org/zap"
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.infratographer.com/permissions-api/internal/encryption"
+)
 
 // Option defines a storage engine configuration option.
 type Option func(e *engine)
@@ -11,3 +17,19 @@ func WithLogger(logger *zap.SugaredLogger) Option {
 		e.logger = logger.Named("storage")
 	}
 }
+
+// WithEncryptor sets the encryptor used to protect sensitive values at rest.
+// When unset, sensitive values are stored as provided.
+func WithEncryptor(encryptor *encryption.Encryptor) Option {
+	return func(e *engine) {
+		e.encryptor = encryptor
+	}
+}
+
+// WithSlowQueryThreshold logs database queries taking longer than the
+// threshold with their arguments. Zero disables logging slow queries.
+func WithSlowQueryThreshold(threshold time.Duration) Option {
+	return func(e *engine) {
+		e.slowQueries.setThreshold(threshold)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.infratographer.com/permissions-api/internal/types"
@@ -44,6 +45,17 @@ type RoleBindingService interface {
 	// LockRoleBindingForUpdate locks a role binding record to be updated to ensure consistency.
 	// If the role binding is not found, an ErrRoleBindingNotFound error is returned.
 	LockRoleBindingForUpdate(ctx context.Context, id gidx.PrefixedID) error
+
+	// CreateRoleBindings creates many role bindings on a resource in a single statement.
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	CreateRoleBindings(ctx context.Context, actorID, resourceID gidx.PrefixedID, rbIDs ...gidx.PrefixedID) ([]types.RoleBinding, error)
+
+	// DeleteRoleBindings deletes many role bindings in a single statement.
+	// An ErrRoleBindingNotFound error is returned if any of the role bindings is not found.
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	DeleteRoleBindings(ctx context.Context, ids ...gidx.PrefixedID) error
 }
 
 func (e *engine) GetRoleBindingByID(ctx context.Context, id gidx.PrefixedID) (types.RoleBinding, error) {
@@ -55,7 +67,7 @@ func (e *engine) GetRoleBindingByID(ctx context.Context, id gidx.PrefixedID) (ty
 	var roleBinding types.RoleBinding
 
 	err = db.QueryRowContext(ctx, `
-		SELECT id, resource_id, created_by, updated_by, created_at, updated_at
+		SELECT id, resource_id, created_by, updated_by, created_at, updated_at, last_used_at
 		FROM rolebindings WHERE id = $1
 		`, id.String(),
 	).Scan(
@@ -65,6 +77,7 @@ func (e *engine) GetRoleBindingByID(ctx context.Context, id gidx.PrefixedID) (ty
 		&roleBinding.UpdatedBy,
 		&roleBinding.CreatedAt,
 		&roleBinding.UpdatedAt,
+		&roleBinding.LastUsedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -84,7 +97,7 @@ func (e *engine) ListResourceRoleBindings(ctx context.Context, resourceID gidx.P
 	}
 
 	rows, err := db.QueryContext(ctx, `
-		SELECT id, resource_id, created_by, updated_by, created_at, updated_at
+		SELECT id, resource_id, created_by, updated_by, created_at, updated_at, last_used_at
 		FROM rolebindings WHERE resource_id = $1 ORDER BY created_at ASC
 		`, resourceID.String(),
 	)
@@ -105,6 +118,7 @@ func (e *engine) ListResourceRoleBindings(ctx context.Context, resourceID gidx.P
 			&roleBinding.UpdatedBy,
 			&roleBinding.CreatedAt,
 			&roleBinding.UpdatedAt,
+			&roleBinding.LastUsedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%w: %s", err, resourceID.String())
@@ -127,7 +141,7 @@ func (e *engine) CreateRoleBinding(ctx context.Context, actorID, rbID, resourceI
 	err = tx.QueryRowContext(ctx, `
 		INSERT INTO rolebindings (id, resource_id, created_by, updated_by, created_at, updated_at)
 		VALUES ($1, $2, $3, $3, $4, $4)
-		RETURNING id, resource_id, created_by, updated_by, created_at, updated_at
+		RETURNING id, resource_id, created_by, updated_by, created_at, updated_at, last_used_at
 		`, rbID.String(), resourceID.String(), actorID.String(), time.Now(),
 	).Scan(
 		&rb.ID,
@@ -136,6 +150,7 @@ func (e *engine) CreateRoleBinding(ctx context.Context, actorID, rbID, resourceI
 		&rb.UpdatedBy,
 		&rb.CreatedAt,
 		&rb.UpdatedAt,
+		&rb.LastUsedAt,
 	)
 	if err != nil {
 		return types.RoleBinding{}, fmt.Errorf("%w: %s", err, rbID.String())
@@ -156,7 +171,7 @@ func (e *engine) UpdateRoleBinding(ctx context.Context, actorID, rbID gidx.Prefi
 		UPDATE rolebindings
 		SET updated_by = $1, updated_at = now()
 		WHERE id = $2
-		RETURNING id, resource_id, created_by, updated_by, created_at, updated_at
+		RETURNING id, resource_id, created_by, updated_by, created_at, updated_at, last_used_at
 		`,
 		actorID.String(), rbID.String(),
 	).Scan(
@@ -166,6 +181,7 @@ func (e *engine) UpdateRoleBinding(ctx context.Context, actorID, rbID gidx.Prefi
 		&rb.UpdatedBy,
 		&rb.CreatedAt,
 		&rb.UpdatedAt,
+		&rb.LastUsedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -227,6 +243,94 @@ func (e *engine) LockRoleBindingForUpdate(ctx context.Context, id gidx.PrefixedI
 	return nil
 }
 
+func (e *engine) CreateRoleBindings(ctx context.Context, actorID, resourceID gidx.PrefixedID, rbIDs ...gidx.PrefixedID) ([]types.RoleBinding, error) {
+	if len(rbIDs) == 0 {
+		return []types.RoleBinding{}, nil
+	}
+
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []any{resourceID.String(), actorID.String(), time.Now()}
+	values := make([]string, len(rbIDs))
+
+	for i, id := range rbIDs {
+		args = append(args, id.String())
+		values[i] = fmt.Sprintf("($%d, $1, $2, $2, $3, $3)", len(args))
+	}
+
+	q := fmt.Sprintf(`
+		INSERT INTO rolebindings (id, resource_id, created_by, updated_by, created_at, updated_at)
+		VALUES %s
+		RETURNING id, resource_id, created_by, updated_by, created_at, updated_at, last_used_at
+		`, strings.Join(values, ", "),
+	)
+
+	rows, err := tx.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+	}
+	defer rows.Close()
+
+	roleBindings := make([]types.RoleBinding, 0, len(rbIDs))
+
+	for rows.Next() {
+		var rb types.RoleBinding
+
+		err = rows.Scan(
+			&rb.ID,
+			&rb.ResourceID,
+			&rb.CreatedBy,
+			&rb.UpdatedBy,
+			&rb.CreatedAt,
+			&rb.UpdatedAt,
+			&rb.LastUsedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+		}
+
+		roleBindings = append(roleBindings, rb)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+	}
+
+	return roleBindings, nil
+}
+
+func (e *engine) DeleteRoleBindings(ctx context.Context, ids ...gidx.PrefixedID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	inClause, args := e.buildBatchInClauseWithIDs(ids)
+
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM rolebindings WHERE id IN (%s)`, inClause), args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected != int64(len(ids)) {
+		return fmt.Errorf("%w: deleted %d of %d role bindings", ErrRoleBindingNotFound, rowsAffected, len(ids))
+	}
+
+	return nil
+}
+
 // buildBatchInClauseWithIDs is a helper function that builds an IN clause for
 // a batch query with the provided prefixed IDs.
 func (e *engine) buildBatchInClauseWithIDs(ids []gidx.PrefixedID) (clause string, args []any) {
@@ -314,3 +314,131 @@ func TestDeleteRoleBinding(t *testing.T) {
 
 	testingx.RunTests(ctx, t, tc, testfn)
 }
+
+func TestCreateRoleBindings(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+	actorID := gidx.PrefixedID("idntusr-user")
+	resourceID := gidx.PrefixedID("tentten-tenant")
+	rbIDs := []gidx.PrefixedID{gidx.MustNewID("permrbn"), gidx.MustNewID("permrbn")}
+
+	tc := []testingx.TestCase[[]gidx.PrefixedID, []types.RoleBinding]{
+		{
+			Name:  "ok",
+			Input: rbIDs,
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBinding]) {
+				require.NoError(t, res.Err, "no error expected")
+				require.Len(t, res.Success, len(rbIDs))
+
+				for _, rb := range res.Success {
+					assert.Contains(t, rbIDs, rb.ID)
+					assert.Equal(t, resourceID, rb.ResourceID)
+					assert.Equal(t, actorID, rb.CreatedBy)
+					assert.NotZero(t, rb.CreatedAt, "expected created at to be set")
+				}
+			},
+			Sync: true,
+		},
+		{
+			Name:  "IDConflict",
+			Input: []gidx.PrefixedID{gidx.MustNewID("permrbn"), rbIDs[0]},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBinding]) {
+				assert.Error(t, res.Err)
+				assert.Empty(t, res.Success)
+			},
+			Sync: true,
+		},
+	}
+
+	testfn := func(ctx context.Context, input []gidx.PrefixedID) testingx.TestResult[[]types.RoleBinding] {
+		result := testingx.TestResult[[]types.RoleBinding]{}
+
+		dbCtx, err := store.BeginContext(ctx)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		result.Success, result.Err = store.CreateRoleBindings(dbCtx, actorID, resourceID, input...)
+		if result.Err != nil {
+			store.RollbackContext(dbCtx) //nolint:errcheck // skip check in test
+
+			return result
+		}
+
+		result.Err = store.CommitContext(dbCtx)
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, tc, testfn)
+}
+
+func TestDeleteRoleBindings(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+	actorID := gidx.PrefixedID("idntusr-user")
+	resourceID := gidx.PrefixedID("tentten-tenant")
+	rbIDs := []gidx.PrefixedID{gidx.MustNewID("permrbn"), gidx.MustNewID("permrbn"), gidx.MustNewID("permrbn")}
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err, "no error expected beginning transaction context")
+
+	_, err = store.CreateRoleBindings(dbCtx, actorID, resourceID, rbIDs...)
+	require.NoError(t, err, "no error expected creating role bindings")
+
+	err = store.CommitContext(dbCtx)
+	require.NoError(t, err, "no error expected committing transaction context")
+
+	tc := []testingx.TestCase[[]gidx.PrefixedID, error]{
+		{
+			Name:  "PartiallyNotFound",
+			Input: []gidx.PrefixedID{rbIDs[2], "permrbn-definitely_not_exists"},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[error]) {
+				assert.ErrorIs(t, res.Err, storage.ErrRoleBindingNotFound)
+			},
+			Sync: true,
+		},
+		{
+			Name:  "ok",
+			Input: rbIDs,
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[error]) {
+				require.NoError(t, res.Err, "no error expected")
+
+				rbs, err := store.ListResourceRoleBindings(ctx, resourceID)
+				require.NoError(t, err)
+				assert.Empty(t, rbs)
+			},
+			Sync: true,
+		},
+	}
+
+	testfn := func(ctx context.Context, input []gidx.PrefixedID) testingx.TestResult[error] {
+		result := testingx.TestResult[error]{}
+
+		dbCtx, err := store.BeginContext(ctx)
+		if err != nil {
+			result.Err = err
+
+			return result
+		}
+
+		result.Err = store.DeleteRoleBindings(dbCtx, input...)
+		if result.Err != nil {
+			store.RollbackContext(dbCtx) //nolint:errcheck // skip check in test
+
+			return result
+		}
+
+		result.Err = store.CommitContext(dbCtx)
+
+		return result
+	}
+
+	testingx.RunTests(ctx, t, tc, testfn)
+}
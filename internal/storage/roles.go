@@ -20,6 +20,7 @@ type RoleService interface {
 	DeleteRole(ctx context.Context, roleID gidx.PrefixedID) (Role, error)
 	LockRoleForUpdate(ctx context.Context, roleID gidx.PrefixedID) error
 	BatchGetRoleByID(ctx context.Context, ids []gidx.PrefixedID) ([]Role, error)
+	ListRoleResourceIDs(ctx context.Context, rolePrefix string, after gidx.PrefixedID, limit int) ([]gidx.PrefixedID, error)
 }
 
 // Role represents a role in the database.
@@ -31,6 +32,7 @@ type Role struct {
 	UpdatedBy  gidx.PrefixedID
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+	LastUsedAt *time.Time
 }
 
 // GetRoleByID retrieves a role from the database by the provided prefixed ID.
@@ -51,7 +53,8 @@ func (e *engine) GetRoleByID(ctx context.Context, id gidx.PrefixedID) (Role, err
 			created_by,
 			updated_by,
 			created_at,
-			updated_at
+			updated_at,
+			last_used_at
 		FROM roles
 		WHERE id = $1
 		`, id.String(),
@@ -63,6 +66,7 @@ func (e *engine) GetRoleByID(ctx context.Context, id gidx.PrefixedID) (Role, err
 		&role.UpdatedBy,
 		&role.CreatedAt,
 		&role.UpdatedAt,
+		&role.LastUsedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -101,7 +105,7 @@ func (e *engine) LockRoleForUpdate(ctx context.Context, id gidx.PrefixedID) erro
 }
 
 // GetResourceRoleByName retrieves a role from the database by the provided resource ID and role name.
-// If no role exists an ErrRoleNotFound error is returned.
+// The name is matched case-insensitively. If no role exists an ErrRoleNotFound error is returned.
 func (e *engine) GetResourceRoleByName(ctx context.Context, resourceID gidx.PrefixedID, name string) (Role, error) {
 	db, err := getContextDBQuery(ctx, e)
 	if err != nil {
@@ -118,11 +122,12 @@ func (e *engine) GetResourceRoleByName(ctx context.Context, resourceID gidx.Pref
 			created_by,
 			updated_by,
 			created_at,
-			updated_at
+			updated_at,
+			last_used_at
 		FROM roles
 		WHERE
 			resource_id = $1
-			AND	name = $2
+			AND	lower(name) = lower($2)
 		`,
 		resourceID.String(),
 		name,
@@ -134,6 +139,7 @@ func (e *engine) GetResourceRoleByName(ctx context.Context, resourceID gidx.Pref
 		&role.UpdatedBy,
 		&role.CreatedAt,
 		&role.UpdatedAt,
+		&role.LastUsedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -162,7 +168,8 @@ func (e *engine) ListResourceRoles(ctx context.Context, resourceID gidx.Prefixed
 			created_by,
 			updated_by,
 			created_at,
-			updated_at
+			updated_at,
+			last_used_at
 		FROM roles
 		WHERE
 			resource_id = $1
@@ -178,7 +185,7 @@ func (e *engine) ListResourceRoles(ctx context.Context, resourceID gidx.Prefixed
 	for rows.Next() {
 		var role Role
 
-		if err := rows.Scan(&role.ID, &role.Name, &role.ResourceID, &role.CreatedBy, &role.UpdatedBy, &role.CreatedAt, &role.UpdatedAt); err != nil {
+		if err := rows.Scan(&role.ID, &role.Name, &role.ResourceID, &role.CreatedBy, &role.UpdatedBy, &role.CreatedAt, &role.UpdatedAt, &role.LastUsedAt); err != nil {
 			return nil, err
 		}
 
@@ -190,7 +197,7 @@ func (e *engine) ListResourceRoles(ctx context.Context, resourceID gidx.Prefixed
 
 // CreateRole creates a role with the provided details.
 // If a role already exists with the given roleID an ErrRoleAlreadyExists error is returned.
-// If a role already exists with the same name under the given resource ID then an ErrRoleNameTaken error is returned.
+// If a role already exists with the same name, ignoring case, under the given resource ID then an ErrRoleNameTaken error is returned.
 //
 // This method must be called with a context returned from BeginContext.
 // CommitContext or RollbackContext must be called afterwards if this method returns no error.
@@ -206,7 +213,7 @@ func (e *engine) CreateRole(ctx context.Context, actorID, roleID gidx.PrefixedID
 		INSERT
 			INTO roles (id, name, resource_id, created_by, updated_by, created_at, updated_at)
 			VALUES ($1, $2, $3, $4, $4, now(), now())
-		RETURNING id, name, resource_id, created_by, updated_by, created_at, updated_at
+		RETURNING id, name, resource_id, created_by, updated_by, created_at, updated_at, last_used_at
 		`, roleID.String(), name, resourceID.String(), actorID.String(),
 	).Scan(
 		&role.ID,
@@ -216,6 +223,7 @@ func (e *engine) CreateRole(ctx context.Context, actorID, roleID gidx.PrefixedID
 		&role.UpdatedBy,
 		&role.CreatedAt,
 		&role.UpdatedAt,
+		&role.LastUsedAt,
 	)
 	if err != nil {
 		if pqIsRoleAlreadyExistsError(err) {
@@ -247,7 +255,7 @@ func (e *engine) UpdateRole(ctx context.Context, actorID, roleID gidx.PrefixedID
 
 	err = tx.QueryRowContext(ctx, `
 		UPDATE roles SET name = $1, updated_by = $2, updated_at = now() WHERE id = $3
-		RETURNING id, name, resource_id, created_by, updated_by, created_at, updated_at
+		RETURNING id, name, resource_id, created_by, updated_by, created_at, updated_at, last_used_at
 		`, name, actorID.String(), roleID.String(),
 	).Scan(
 		&role.ID,
@@ -257,6 +265,7 @@ func (e *engine) UpdateRole(ctx context.Context, actorID, roleID gidx.PrefixedID
 		&role.UpdatedBy,
 		&role.CreatedAt,
 		&role.UpdatedAt,
+		&role.LastUsedAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -317,7 +326,7 @@ func (e *engine) BatchGetRoleByID(ctx context.Context, ids []gidx.PrefixedID) ([
 	q := fmt.Sprintf(`
 		SELECT
 			id, name, resource_id,
-			created_by, updated_by, created_at, updated_at
+			created_by, updated_by, created_at, updated_at, last_used_at
 		FROM roles
 		WHERE id IN (%s)
 	`, inClause)
@@ -332,7 +341,7 @@ func (e *engine) BatchGetRoleByID(ctx context.Context, ids []gidx.PrefixedID) ([
 	for rows.Next() {
 		var role Role
 
-		if err := rows.Scan(&role.ID, &role.Name, &role.ResourceID, &role.CreatedBy, &role.UpdatedBy, &role.CreatedAt, &role.UpdatedAt); err != nil {
+		if err := rows.Scan(&role.ID, &role.Name, &role.ResourceID, &role.CreatedBy, &role.UpdatedBy, &role.CreatedAt, &role.UpdatedAt, &role.LastUsedAt); err != nil {
 			return nil, err
 		}
 
@@ -341,3 +350,47 @@ func (e *engine) BatchGetRoleByID(ctx context.Context, ids []gidx.PrefixedID) ([
 
 	return roles, nil
 }
+
+// ListRoleResourceIDs lists the IDs of the resources owning roles with the
+// given role ID prefix, e.g. to find all resources with v1 roles. IDs are
+// sorted and only IDs after the given ID are returned, so all resources can be
+// listed in pages of at most limit IDs.
+func (e *engine) ListRoleResourceIDs(ctx context.Context, rolePrefix string, after gidx.PrefixedID, limit int) ([]gidx.PrefixedID, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT DISTINCT resource_id
+		FROM roles
+		WHERE
+			id LIKE $1
+			AND resource_id > $2
+		ORDER BY resource_id
+		LIMIT $3
+		`,
+		rolePrefix+"-%",
+		after.String(),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var ids []gidx.PrefixedID
+
+	for rows.Next() {
+		var id gidx.PrefixedID
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
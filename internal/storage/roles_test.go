@@ -204,6 +204,19 @@ func TestCreateRole(t *testing.T) {
 			},
 			Sync: true,
 		},
+		{
+			Name: "NameTakenDifferentCase",
+			Input: testInput{
+				id:   "permrol-ghi789",
+				name: "Admins",
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[storage.Role]) {
+				assert.Error(t, res.Err, "expected error for already taken name with different case")
+				assert.ErrorIs(t, res.Err, storage.ErrRoleNameTaken, "expected error to be for already taken name")
+				require.Empty(t, res.Success.ID, "expected role to be empty")
+			},
+			Sync: true,
+		},
 	}
 
 	testFn := func(ctx context.Context, input testInput) testingx.TestResult[storage.Role] {
@@ -279,6 +292,32 @@ func TestUpdateRole(t *testing.T) {
 			},
 			Sync: true,
 		},
+		{
+			Name: "NameTakenDifferentCase",
+			Input: testInput{
+				id:   role1ID,
+				name: "Users",
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[storage.Role]) {
+				assert.Error(t, res.Err, "expected error updating role name to an already taken role name with different case")
+				assert.ErrorIs(t, res.Err, storage.ErrRoleNameTaken, "expected error to be role name taken error")
+				assert.Empty(t, res.Success.ID, "expected role to be empty")
+			},
+			Sync: true,
+		},
+		{
+			Name: "ChangeCase",
+			Input: testInput{
+				id:   role1ID,
+				name: "Admins",
+			},
+			CheckFn: func(ctx context.Context, t *testing.T, res testingx.TestResult[storage.Role]) {
+				require.NoError(t, res.Err, "no error expected changing the case of the role name")
+
+				assert.Equal(t, "Admins", res.Success.Name)
+			},
+			Sync: true,
+		},
 		{
 			Name: "Success",
 			Input: testInput{
@@ -390,3 +429,41 @@ func TestDeleteRole(t *testing.T) {
 	assert.Equal(t, roleID, createdDBRole.ID, "unexpected created role id")
 	assert.Equal(t, roleID, deletedDBRole.ID, "unexpected deleted role id")
 }
+
+func TestListRoleResourceIDs(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+
+	actorID := gidx.PrefixedID("idntusr-abc123")
+
+	roles := map[gidx.PrefixedID]gidx.PrefixedID{
+		"permrol-abc123": "testten-aaa111",
+		"permrol-def456": "testten-aaa111",
+		"permrol-ghi789": "testten-bbb222",
+		"permrv2-abc123": "testten-ccc333",
+		"permrol-jkl012": "testten-ddd444",
+	}
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err, "no error expected beginning transaction context")
+
+	for roleID, resourceID := range roles {
+		_, err := store.CreateRole(dbCtx, actorID, roleID, roleID.String(), resourceID)
+
+		require.NoError(t, err, "no error expected creating role", roleID)
+	}
+
+	err = store.CommitContext(dbCtx)
+	require.NoError(t, err, "no error expected while committing roles")
+
+	ids, err := store.ListRoleResourceIDs(ctx, "permrol", "", 2)
+	require.NoError(t, err, "no error expected listing resource ids")
+	assert.Equal(t, []gidx.PrefixedID{"testten-aaa111", "testten-bbb222"}, ids)
+
+	ids, err = store.ListRoleResourceIDs(ctx, "permrol", ids[len(ids)-1], 2)
+	require.NoError(t, err, "no error expected listing resource ids")
+	assert.Equal(t, []gidx.PrefixedID{"testten-ddd444"}, ids)
+}
@@ -4,18 +4,34 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"time"
 
 	"go.uber.org/zap"
+
+	"go.infratographer.com/permissions-api/internal/encryption"
 )
 
 // Storage defines the interface the engine exposes.
 type Storage interface {
 	RoleService
 	RoleBindingService
+	InvitationService
+	AccessRequestService
+	GroupService
+	ResourceAliasService
+	TenantSettingsService
+	UsageService
+	DecisionLogService
+	WebhookService
 	ZedTokenService
 	TransactionManager
 
 	HealthCheck(ctx context.Context) error
+
+	// SetSlowQueryThreshold changes the threshold above which database queries
+	// are logged, zero disables logging slow queries.
+	SetSlowQueryThreshold(threshold time.Duration)
 }
 
 // DB is the interface the database package requires from a database engine to run.
@@ -36,7 +52,9 @@ type DBQuery interface {
 
 type engine struct {
 	DB
-	logger *zap.SugaredLogger
+	logger      *zap.SugaredLogger
+	encryptor   *encryption.Encryptor
+	slowQueries *slowQueryLog
 }
 
 // HealthCheck calls the underlying databases PingContext to check that the database is alive and accepting connections.
@@ -44,16 +62,54 @@ func (e *engine) HealthCheck(ctx context.Context) error {
 	return e.PingContext(ctx)
 }
 
+// SetSlowQueryThreshold changes the threshold above which database queries are
+// logged, zero disables logging slow queries.
+func (e *engine) SetSlowQueryThreshold(threshold time.Duration) {
+	e.slowQueries.setThreshold(threshold)
+}
+
 // New creates a new storage engine using the provided underlying DB.
 func New(db DB, options ...Option) Storage {
 	s := &engine{
-		DB:     db,
-		logger: zap.NewNop().Sugar(),
+		DB:          db,
+		logger:      zap.NewNop().Sugar(),
+		slowQueries: &slowQueryLog{},
 	}
 
 	for _, opt := range options {
 		opt(s)
 	}
 
+	s.slowQueries.logger = s.logger
+
 	return s
 }
+
+// sealValue encrypts a sensitive column value when an encryptor is configured.
+// The row ID is authenticated with the value so it cannot be moved between rows.
+func (e *engine) sealValue(ctx context.Context, rowID, value string) (string, error) {
+	if e.encryptor == nil || value == "" {
+		return value, nil
+	}
+
+	return e.encryptor.Encrypt(ctx, []byte(value), []byte(rowID))
+}
+
+// openValue decrypts a sensitive column value. Values written before encryption
+// was enabled are returned as is.
+func (e *engine) openValue(ctx context.Context, rowID, value string) (string, error) {
+	if !encryption.IsEncrypted(value) {
+		return value, nil
+	}
+
+	if e.encryptor == nil {
+		return "", fmt.Errorf("%w: encrypted value found but no encryptor configured", encryption.ErrUnknownKey)
+	}
+
+	plaintext, err := e.encryptor.Decrypt(ctx, value, []byte(rowID))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// TenantSettingsService represents a service for managing the settings of
+// tenants in the permissions API storage
+type TenantSettingsService interface {
+	// GetTenantSettings returns the settings of a tenant
+	// an ErrTenantSettingsNotFound error is returned if no settings are stored for the tenant
+	GetTenantSettings(ctx context.Context, tenantID gidx.PrefixedID) (types.TenantSettings, error)
+
+	// UpdateTenantSettings stores the settings of a tenant, replacing any stored settings.
+	// This method must be called with a context returned from BeginContext.
+	// CommitContext or RollbackContext must be called afterwards if this method returns no error.
+	UpdateTenantSettings(ctx context.Context, settings types.TenantSettings) (types.TenantSettings, error)
+}
+
+const tenantSettingsColumns = `tenant_id, allow_group_bindings, inherit_parent_roles, updated_by, updated_at`
+
+func scanTenantSettings(row rowScanner) (types.TenantSettings, error) {
+	var settings types.TenantSettings
+
+	err := row.Scan(
+		&settings.TenantID,
+		&settings.AllowGroupBindings,
+		&settings.InheritParentRoles,
+		&settings.UpdatedBy,
+		&settings.UpdatedAt,
+	)
+	if err != nil {
+		return types.TenantSettings{}, err
+	}
+
+	return settings, nil
+}
+
+func (e *engine) GetTenantSettings(ctx context.Context, tenantID gidx.PrefixedID) (types.TenantSettings, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return types.TenantSettings{}, err
+	}
+
+	row := db.QueryRowContext(ctx, `SELECT `+tenantSettingsColumns+` FROM tenant_settings WHERE tenant_id = $1`, tenantID.String())
+
+	settings, err := scanTenantSettings(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return types.TenantSettings{}, fmt.Errorf("%w: %s", ErrTenantSettingsNotFound, tenantID.String())
+		}
+
+		return types.TenantSettings{}, fmt.Errorf("%w: %s", err, tenantID.String())
+	}
+
+	return settings, nil
+}
+
+func (e *engine) UpdateTenantSettings(ctx context.Context, settings types.TenantSettings) (types.TenantSettings, error) {
+	tx, err := getContextTx(ctx)
+	if err != nil {
+		return types.TenantSettings{}, err
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		UPSERT INTO tenant_settings (tenant_id, allow_group_bindings, inherit_parent_roles, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, now())
+		RETURNING `+tenantSettingsColumns,
+		settings.TenantID.String(), settings.AllowGroupBindings, settings.InheritParentRoles, settings.UpdatedBy.String(),
+	)
+
+	out, err := scanTenantSettings(row)
+	if err != nil {
+		return types.TenantSettings{}, fmt.Errorf("%w: %s", err, settings.TenantID.String())
+	}
+
+	return out, nil
+}
@@ -0,0 +1,61 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+)
+
+func TestTenantSettings(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+	actorID := gidx.PrefixedID("idntusr-user")
+	tenantID := gidx.PrefixedID("tnntten-tenant")
+
+	_, err := store.GetTenantSettings(ctx, tenantID)
+	assert.ErrorIs(t, err, storage.ErrTenantSettingsNotFound)
+
+	update := func(settings types.TenantSettings) types.TenantSettings {
+		dbCtx, err := store.BeginContext(ctx)
+		require.NoError(t, err, "no error expected beginning transaction context")
+
+		out, err := store.UpdateTenantSettings(dbCtx, settings)
+		require.NoError(t, err, "no error expected updating tenant settings")
+
+		require.NoError(t, store.CommitContext(dbCtx), "no error expected committing transaction context")
+
+		return out
+	}
+
+	created := update(types.TenantSettings{
+		TenantID:           tenantID,
+		AllowGroupBindings: false,
+		InheritParentRoles: true,
+		UpdatedBy:          actorID,
+	})
+	assert.Equal(t, tenantID, created.TenantID)
+	assert.False(t, created.AllowGroupBindings)
+	assert.True(t, created.InheritParentRoles)
+	assert.Equal(t, actorID, created.UpdatedBy)
+
+	update(types.TenantSettings{
+		TenantID:           tenantID,
+		AllowGroupBindings: true,
+		InheritParentRoles: false,
+		UpdatedBy:          actorID,
+	})
+
+	settings, err := store.GetTenantSettings(ctx, tenantID)
+	require.NoError(t, err)
+	assert.True(t, settings.AllowGroupBindings)
+	assert.False(t, settings.InheritParentRoles)
+}
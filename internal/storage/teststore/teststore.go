@@ -4,6 +4,7 @@ package teststore
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/cockroachdb/cockroach-go/v2/testserver"
@@ -14,7 +15,17 @@ import (
 )
 
 // NewTestStorage creates a new permissions database instance for testing.
-func NewTestStorage(t *testing.T) (storage.Storage, func()) {
+func NewTestStorage(t *testing.T, options ...storage.Option) (storage.Storage, func()) {
+	t.Helper()
+
+	db, closeDB := NewTestDB(t)
+
+	return storage.New(db, options...), closeDB
+}
+
+// NewTestDB creates a new migrated permissions database for testing, allowing
+// tests to create several stores using the same database.
+func NewTestDB(t *testing.T) (*sql.DB, func()) {
 	t.Helper()
 
 	server, err := testserver.NewTestServer()
@@ -45,5 +56,5 @@ func NewTestStorage(t *testing.T) (storage.Storage, func()) {
 		return nil, func() {}
 	}
 
-	return storage.New(db), func() { db.Close() }
+	return db, func() { db.Close() }
 }
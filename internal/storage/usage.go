@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// UsageService represents a service for tracking when roles and role bindings were last used.
+type UsageService interface {
+	// RecordRolesUsed sets the last used time of the given roles, unless a later
+	// time has already been recorded.
+	RecordRolesUsed(ctx context.Context, usedAt time.Time, ids ...gidx.PrefixedID) error
+
+	// RecordRoleBindingsUsed sets the last used time of the given role bindings,
+	// unless a later time has already been recorded.
+	RecordRoleBindingsUsed(ctx context.Context, usedAt time.Time, ids ...gidx.PrefixedID) error
+
+	// ListStaleRoleBindings returns the role bindings for a resource which have not
+	// been used since the given time. Role bindings which have never been used are
+	// included if they were created before the given time, and are listed first.
+	ListStaleRoleBindings(ctx context.Context, resourceID gidx.PrefixedID, unusedSince time.Time) ([]types.RoleBinding, error)
+
+	// RecordRoleBindingActionsUsed sets the last used time of the given actions
+	// granted by each role binding, unless a later time has already been recorded.
+	RecordRoleBindingActionsUsed(ctx context.Context, usedAt time.Time, actions map[gidx.PrefixedID][]string) error
+
+	// ListRoleBindingActionsUsed returns the actions granted by each of the given
+	// role bindings which have been used since the given time. Role bindings
+	// without used actions are not included.
+	ListRoleBindingActionsUsed(ctx context.Context, usedSince time.Time, ids ...gidx.PrefixedID) (map[gidx.PrefixedID][]string, error)
+}
+
+func (e *engine) RecordRolesUsed(ctx context.Context, usedAt time.Time, ids ...gidx.PrefixedID) error {
+	return e.recordUsed(ctx, "roles", usedAt, ids)
+}
+
+func (e *engine) RecordRoleBindingsUsed(ctx context.Context, usedAt time.Time, ids ...gidx.PrefixedID) error {
+	return e.recordUsed(ctx, "rolebindings", usedAt, ids)
+}
+
+func (e *engine) recordUsed(ctx context.Context, table string, usedAt time.Time, ids []gidx.PrefixedID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return err
+	}
+
+	inClause, args := e.buildBatchInClauseWithIDs(ids)
+	args = append(args, usedAt)
+
+	// table is never user provided.
+	q := fmt.Sprintf(`
+		UPDATE %s SET last_used_at = $%d
+		WHERE id IN (%s) AND (last_used_at IS NULL OR last_used_at < $%[2]d)
+	`, table, len(args), inClause)
+
+	if _, err := db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("failed to record %s usage: %w", table, err)
+	}
+
+	return nil
+}
+
+func (e *engine) ListStaleRoleBindings(ctx context.Context, resourceID gidx.PrefixedID, unusedSince time.Time) ([]types.RoleBinding, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, resource_id, created_by, updated_by, created_at, updated_at, last_used_at
+		FROM rolebindings
+		WHERE
+			resource_id = $1
+			AND (last_used_at < $2 OR (last_used_at IS NULL AND created_at < $2))
+		ORDER BY last_used_at ASC, created_at ASC
+		`, resourceID.String(), unusedSince,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+	}
+	defer rows.Close()
+
+	var roleBindings []types.RoleBinding
+
+	for rows.Next() {
+		var roleBinding types.RoleBinding
+
+		err = rows.Scan(
+			&roleBinding.ID,
+			&roleBinding.ResourceID,
+			&roleBinding.CreatedBy,
+			&roleBinding.UpdatedBy,
+			&roleBinding.CreatedAt,
+			&roleBinding.UpdatedAt,
+			&roleBinding.LastUsedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, resourceID.String())
+		}
+
+		roleBindings = append(roleBindings, roleBinding)
+	}
+
+	return roleBindings, nil
+}
+
+func (e *engine) RecordRoleBindingActionsUsed(ctx context.Context, usedAt time.Time, actions map[gidx.PrefixedID][]string) error {
+	args := []any{usedAt}
+	values := []string{}
+
+	for rbID, rbActions := range actions {
+		for _, action := range rbActions {
+			args = append(args, rbID.String(), action)
+			values = append(values, fmt.Sprintf("($%d, $%d, $1)", len(args)-1, len(args)))
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return err
+	}
+
+	q := fmt.Sprintf(`
+		INSERT INTO rolebinding_actions_used (rolebinding_id, action, last_used_at)
+		VALUES %s
+		ON CONFLICT (rolebinding_id, action) DO UPDATE
+		SET last_used_at = greatest(rolebinding_actions_used.last_used_at, excluded.last_used_at)
+	`, strings.Join(values, ", "))
+
+	if _, err := db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("failed to record role binding action usage: %w", err)
+	}
+
+	return nil
+}
+
+func (e *engine) ListRoleBindingActionsUsed(ctx context.Context, usedSince time.Time, ids ...gidx.PrefixedID) (map[gidx.PrefixedID][]string, error) {
+	used := make(map[gidx.PrefixedID][]string)
+
+	if len(ids) == 0 {
+		return used, nil
+	}
+
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	inClause, args := e.buildBatchInClauseWithIDs(ids)
+	args = append(args, usedSince)
+
+	q := fmt.Sprintf(`
+		SELECT rolebinding_id, action
+		FROM rolebinding_actions_used
+		WHERE rolebinding_id IN (%s) AND last_used_at >= $%d
+		ORDER BY rolebinding_id, action
+	`, inClause, len(args))
+
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role binding action usage: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			rbID   gidx.PrefixedID
+			action string
+		)
+
+		if err := rows.Scan(&rbID, &action); err != nil {
+			return nil, fmt.Errorf("failed to list role binding action usage: %w", err)
+		}
+
+		used[rbID] = append(used[rbID], action)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list role binding action usage: %w", err)
+	}
+
+	return used, nil
+}
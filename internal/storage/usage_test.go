@@ -0,0 +1,166 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+)
+
+func TestListStaleRoleBindings(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+	actorID := gidx.PrefixedID("idntusr-user")
+	resourceID := gidx.PrefixedID("tentten-tenant")
+
+	usedID := gidx.MustNewID("permrbn")
+	unusedID := gidx.MustNewID("permrbn")
+	oldUseID := gidx.MustNewID("permrbn")
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err, "no error expected beginning transaction context")
+
+	for _, rbID := range []gidx.PrefixedID{usedID, unusedID, oldUseID} {
+		_, err = store.CreateRoleBinding(dbCtx, actorID, rbID, resourceID)
+		require.NoError(t, err, "no error expected creating role binding")
+	}
+
+	err = store.CommitContext(dbCtx)
+	require.NoError(t, err, "no error expected committing transaction context")
+
+	now := time.Now()
+
+	require.NoError(t, store.RecordRoleBindingsUsed(ctx, now.Add(-48*time.Hour), oldUseID))
+	require.NoError(t, store.RecordRoleBindingsUsed(ctx, now, usedID))
+
+	// recording an earlier use must not move the last used time back
+	require.NoError(t, store.RecordRoleBindingsUsed(ctx, now.Add(-72*time.Hour), usedID))
+
+	rb, err := store.GetRoleBindingByID(ctx, usedID)
+	require.NoError(t, err)
+	require.NotNil(t, rb.LastUsedAt)
+	assert.WithinDuration(t, now, *rb.LastUsedAt, time.Second)
+
+	tc := []testingx.TestCase[time.Time, []types.RoleBinding]{
+		{
+			Name:  "BeforeCreation",
+			Input: now.Add(-time.Hour * 24 * 365),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBinding]) {
+				require.NoError(t, res.Err)
+				assert.Empty(t, res.Success)
+			},
+		},
+		{
+			Name:  "UnusedForADay",
+			Input: now.Add(-24 * time.Hour),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBinding]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 1)
+				assert.Equal(t, oldUseID, res.Success[0].ID)
+			},
+		},
+		{
+			Name:  "UnusedSinceNow",
+			Input: now.Add(time.Minute),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[[]types.RoleBinding]) {
+				require.NoError(t, res.Err)
+				require.Len(t, res.Success, 3)
+
+				// never used bindings are listed first
+				assert.Equal(t, unusedID, res.Success[0].ID)
+				assert.Nil(t, res.Success[0].LastUsedAt)
+				assert.Equal(t, oldUseID, res.Success[1].ID)
+				assert.Equal(t, usedID, res.Success[2].ID)
+			},
+		},
+	}
+
+	testfn := func(ctx context.Context, input time.Time) testingx.TestResult[[]types.RoleBinding] {
+		rbs, err := store.ListStaleRoleBindings(ctx, resourceID, input)
+
+		return testingx.TestResult[[]types.RoleBinding]{Success: rbs, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testfn)
+}
+
+func TestRoleBindingActionsUsed(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+	actorID := gidx.PrefixedID("idntusr-user")
+	resourceID := gidx.PrefixedID("tentten-tenant")
+
+	usedID := gidx.MustNewID("permrbn")
+	unusedID := gidx.MustNewID("permrbn")
+
+	dbCtx, err := store.BeginContext(ctx)
+	require.NoError(t, err, "no error expected beginning transaction context")
+
+	for _, rbID := range []gidx.PrefixedID{usedID, unusedID} {
+		_, err = store.CreateRoleBinding(dbCtx, actorID, rbID, resourceID)
+		require.NoError(t, err, "no error expected creating role binding")
+	}
+
+	err = store.CommitContext(dbCtx)
+	require.NoError(t, err, "no error expected committing transaction context")
+
+	now := time.Now()
+
+	err = store.RecordRoleBindingActionsUsed(ctx, now.Add(-48*time.Hour), map[gidx.PrefixedID][]string{
+		usedID: {"loadbalancer_get", "loadbalancer_list"},
+	})
+	require.NoError(t, err)
+
+	err = store.RecordRoleBindingActionsUsed(ctx, now, map[gidx.PrefixedID][]string{
+		usedID: {"loadbalancer_get"},
+	})
+	require.NoError(t, err)
+
+	// recording an earlier use must not move the last used time back
+	err = store.RecordRoleBindingActionsUsed(ctx, now.Add(-72*time.Hour), map[gidx.PrefixedID][]string{
+		usedID: {"loadbalancer_get"},
+	})
+	require.NoError(t, err)
+
+	tc := []testingx.TestCase[time.Time, map[gidx.PrefixedID][]string]{
+		{
+			Name:  "LastWeek",
+			Input: now.Add(-7 * 24 * time.Hour),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[map[gidx.PrefixedID][]string]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, map[gidx.PrefixedID][]string{
+					usedID: {"loadbalancer_get", "loadbalancer_list"},
+				}, res.Success)
+			},
+		},
+		{
+			Name:  "LastDay",
+			Input: now.Add(-24 * time.Hour),
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[map[gidx.PrefixedID][]string]) {
+				require.NoError(t, res.Err)
+				assert.Equal(t, map[gidx.PrefixedID][]string{
+					usedID: {"loadbalancer_get"},
+				}, res.Success)
+			},
+		},
+	}
+
+	testfn := func(ctx context.Context, input time.Time) testingx.TestResult[map[gidx.PrefixedID][]string] {
+		used, err := store.ListRoleBindingActionsUsed(ctx, input, usedID, unusedID)
+
+		return testingx.TestResult[map[gidx.PrefixedID][]string]{Success: used, Err: err}
+	}
+
+	testingx.RunTests(ctx, t, tc, testfn)
+}
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// WebhookService represents a service for tracking the deliveries of change
+// notifications to webhooks.
+type WebhookService interface {
+	// CreateWebhookDeliveries stores new pending deliveries, due immediately.
+	CreateWebhookDeliveries(ctx context.Context, deliveries ...types.WebhookDelivery) error
+
+	// ClaimWebhookDeliveries returns up to limit pending deliveries due at the
+	// given time, oldest first. The deliveries are not due again until leaseUntil,
+	// so other replicas don't attempt them at the same time.
+	ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]types.WebhookDelivery, error)
+
+	// UpdateWebhookDelivery stores the status, attempts, outcome of the last
+	// attempt and next attempt time of a delivery.
+	UpdateWebhookDelivery(ctx context.Context, delivery types.WebhookDelivery) error
+
+	// ListWebhookDeliveries returns the latest deliveries to a webhook, newest first.
+	ListWebhookDeliveries(ctx context.Context, webhook string, limit int) ([]types.WebhookDelivery, error)
+}
+
+const webhookDeliveryColumns = `id, webhook, event_type, payload, status, attempts, response_code, last_error, next_attempt_at, created_at, updated_at`
+
+func scanWebhookDeliveries(rows *sql.Rows) ([]types.WebhookDelivery, error) {
+	var deliveries []types.WebhookDelivery
+
+	for rows.Next() {
+		var d types.WebhookDelivery
+
+		err := rows.Scan(
+			&d.ID,
+			&d.Webhook,
+			&d.EventType,
+			&d.Payload,
+			&d.Status,
+			&d.Attempts,
+			&d.ResponseCode,
+			&d.LastError,
+			&d.NextAttemptAt,
+			&d.CreatedAt,
+			&d.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (e *engine) CreateWebhookDeliveries(ctx context.Context, deliveries ...types.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return err
+	}
+
+	const columns = 5
+
+	values := make([]string, len(deliveries))
+	args := make([]any, 0, len(deliveries)*columns)
+
+	for i, d := range deliveries {
+		n := i * columns
+
+		values[i] = fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, now(), now(), now())", n+1, n+2, n+3, n+4, n+5)
+
+		args = append(args, d.ID.String(), d.Webhook, d.EventType, d.Payload, types.WebhookDeliveryPending)
+	}
+
+	q := `
+		INSERT INTO webhook_deliveries (id, webhook, event_type, payload, status, next_attempt_at, created_at, updated_at)
+		VALUES ` + strings.Join(values, ", ")
+
+	if _, err := db.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("failed to create webhook deliveries: %w", err)
+	}
+
+	return nil
+}
+
+func (e *engine) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]types.WebhookDelivery, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		UPDATE webhook_deliveries SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $3 AND next_attempt_at <= $1
+			ORDER BY next_attempt_at ASC
+			LIMIT $4
+		)
+		RETURNING `+webhookDeliveryColumns,
+		now, leaseUntil, types.WebhookDeliveryPending, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+func (e *engine) UpdateWebhookDelivery(ctx context.Context, delivery types.WebhookDelivery) error {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, response_code = $4, last_error = $5, next_attempt_at = $6, updated_at = now()
+		WHERE id = $1`,
+		delivery.ID.String(),
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseCode,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrWebhookDeliveryNotFound, delivery.ID)
+	}
+
+	return nil
+}
+
+func (e *engine) ListWebhookDeliveries(ctx context.Context, webhook string, limit int) ([]types.WebhookDelivery, error) {
+	db, err := getContextDBQuery(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries
+		WHERE webhook = $1
+		ORDER BY created_at DESC
+		LIMIT $2`,
+		webhook, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
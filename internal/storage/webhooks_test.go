@@ -0,0 +1,74 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+
+	first := types.WebhookDelivery{
+		ID:        gidx.MustNewID("permwhd"),
+		Webhook:   "tickets",
+		EventType: "role_created",
+		Payload:   []byte(`{"event_type":"role_created"}`),
+	}
+	second := types.WebhookDelivery{
+		ID:        gidx.MustNewID("permwhd"),
+		Webhook:   "tickets",
+		EventType: "role_deleted",
+		Payload:   []byte(`{"event_type":"role_deleted"}`),
+	}
+
+	require.NoError(t, store.CreateWebhookDeliveries(ctx, first, second))
+
+	now := time.Now().Add(time.Second)
+	lease := now.Add(time.Minute)
+
+	claimed, err := store.ClaimWebhookDeliveries(ctx, now, lease, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, types.WebhookDeliveryPending, claimed[0].Status)
+	assert.Equal(t, first.Payload, claimed[0].Payload)
+
+	// leased deliveries are not claimed again until the lease expired
+	claimed, err = store.ClaimWebhookDeliveries(ctx, now, lease, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	delivered := first
+	delivered.Status = types.WebhookDeliveryDelivered
+	delivered.Attempts = 1
+	delivered.ResponseCode = 200
+	delivered.NextAttemptAt = now
+
+	require.NoError(t, store.UpdateWebhookDelivery(ctx, delivered))
+
+	claimed, err = store.ClaimWebhookDeliveries(ctx, lease.Add(time.Second), lease.Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, second.ID, claimed[0].ID)
+
+	deliveries, err := store.ListWebhookDeliveries(ctx, "tickets", 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+
+	missing := first
+	missing.ID = gidx.MustNewID("permwhd")
+
+	err = store.UpdateWebhookDelivery(ctx, missing)
+	assert.ErrorIs(t, err, storage.ErrWebhookDeliveryNotFound)
+}
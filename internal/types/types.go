@@ -18,6 +18,7 @@ type Role struct {
 	UpdatedBy  gidx.PrefixedID
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+	LastUsedAt *time.Time
 }
 
 // TargetType represents a relationship target, as defined in spiceDB's schema
@@ -26,6 +27,33 @@ type TargetType struct {
 	Name              string
 	SubjectIdentifier string
 	SubjectRelation   string
+	// Caveat is the name of the caveat relationships to the target may be
+	// conditioned on.
+	Caveat string
+}
+
+// Caveat is a named condition defined by the policy, relationships with a
+// caveat only apply when SpiceDB evaluates the caveat expression to true,
+// e.g. when a request comes from a given CIDR range.
+// https://authzed.com/docs/spicedb/concepts/caveats
+type Caveat struct {
+	Name       string
+	Parameters []CaveatParameter
+	Expression string
+}
+
+// CaveatParameter is a typed parameter of a caveat, e.g. a string or an ipaddress.
+type CaveatParameter struct {
+	Name string
+	Type string
+}
+
+// RoleBindingCaveat conditions a role binding on a caveat. Context holds
+// values of caveat parameters fixed when the role binding is created, the
+// remaining parameters are provided by permission checks.
+type RoleBindingCaveat struct {
+	Name    string
+	Context map[string]any
 }
 
 // ResourceTypeRelationship is a relationship for a resource type.
@@ -67,6 +95,13 @@ type Action struct {
 	ConditionSets []ConditionSet
 }
 
+// ActionGroup is a named bundle of actions which can be granted together.
+type ActionGroup struct {
+	Name        string
+	Description string
+	Actions     []string
+}
+
 // ResourceType defines a type of resource managed by the api
 type ResourceType struct {
 	Name          string
@@ -93,15 +128,309 @@ type Relationship struct {
 	Subject  Resource
 }
 
+// RelationshipOperation is the operation of a relationship write.
+type RelationshipOperation string
+
+const (
+	// RelationshipOperationCreate creates the relationship, keeping it if it already exists.
+	RelationshipOperationCreate RelationshipOperation = "create"
+	// RelationshipOperationDelete deletes the relationship, if it exists.
+	RelationshipOperationDelete RelationshipOperation = "delete"
+)
+
+// RelationshipWrite is a relationship to create or delete.
+type RelationshipWrite struct {
+	Operation    RelationshipOperation
+	Relationship Relationship
+}
+
+// RelationshipPrecondition requires a relationship to exist, or not to exist,
+// for relationship writes to be applied.
+type RelationshipPrecondition struct {
+	Relationship Relationship
+	// MustExist requires the relationship to exist when true, and to not
+	// exist when false.
+	MustExist bool
+}
+
+// RelationshipFilter selects the relationships to read. Empty fields match
+// any value, but either ResourceType or ResourceID must be set.
+type RelationshipFilter struct {
+	ResourceType string
+	ResourceID   string
+	Relation     string
+	SubjectType  string
+	SubjectID    string
+}
+
+// RelationshipRecord is a relationship as it is stored in SpiceDB, including
+// the relationships of roles and role-bindings which may not reference
+// resources by their prefixed IDs.
+type RelationshipRecord struct {
+	ResourceType    string
+	ResourceID      string
+	Relation        string
+	SubjectType     string
+	SubjectID       string
+	SubjectRelation string
+}
+
+// RelationshipChangeOperation is the change made to a relationship.
+type RelationshipChangeOperation string
+
+const (
+	// RelationshipChangeCreated is a relationship which was created or touched.
+	RelationshipChangeCreated RelationshipChangeOperation = "created"
+	// RelationshipChangeDeleted is a relationship which was deleted.
+	RelationshipChangeDeleted RelationshipChangeOperation = "deleted"
+)
+
+// RelationshipChange is a change of a relationship stored in SpiceDB.
+type RelationshipChange struct {
+	Operation    RelationshipChangeOperation
+	Relationship RelationshipRecord
+	// ZedToken is the revision the change was made at. Changes after it can
+	// be watched by starting from it.
+	ZedToken string
+}
+
 // RoleBinding represents a role binding between a role and a resource.
 type RoleBinding struct {
 	ID         gidx.PrefixedID
 	ResourceID gidx.PrefixedID
 	RoleID     gidx.PrefixedID
 	SubjectIDs []gidx.PrefixedID
+	// Caveat is set when the role binding is conditioned on a caveat.
+	Caveat *RoleBindingCaveat
+
+	CreatedBy  gidx.PrefixedID
+	UpdatedBy  gidx.PrefixedID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+// RoleBindingSuggestion suggests reducing a role binding granting more actions
+// than its subjects used. A role binding without used actions can be removed.
+type RoleBindingSuggestion struct {
+	RoleBinding RoleBinding
+	// UsedActions are the actions granted by the role binding which were used.
+	UsedActions []string
+	// UnusedActions are the actions granted by the role binding which were not used.
+	UnusedActions []string
+}
+
+// Invitation is a pending role binding of a role on a resource, created for
+// the subject which redeems the invitation token.
+type Invitation struct {
+	ID         gidx.PrefixedID
+	ResourceID gidx.PrefixedID
+	RoleID     gidx.PrefixedID
+	// Email is the address the invitation was sent to, for reference only. It
+	// is not checked when the invitation is redeemed.
+	Email string
+
+	CreatedBy gidx.PrefixedID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+
+	RedeemedBy    gidx.PrefixedID
+	RedeemedAt    *time.Time
+	RoleBindingID gidx.PrefixedID
+}
+
+// AccessRequestStatus is the state of an access request.
+type AccessRequestStatus string
+
+const (
+	// AccessRequestPending is the status of an access request awaiting a decision.
+	AccessRequestPending AccessRequestStatus = "pending"
+	// AccessRequestApproved is the status of an approved access request, its
+	// role binding has been created.
+	AccessRequestApproved AccessRequestStatus = "approved"
+	// AccessRequestDenied is the status of a denied access request.
+	AccessRequestDenied AccessRequestStatus = "denied"
+)
+
+// AccessRequest is a request by a subject to be bound to a role on a
+// resource, which an approver approves or denies.
+type AccessRequest struct {
+	ID         gidx.PrefixedID
+	ResourceID gidx.PrefixedID
+	RoleID     gidx.PrefixedID
+	SubjectID  gidx.PrefixedID
+	// Reason is the justification given by the subject.
+	Reason string
+	Status AccessRequestStatus
+
+	CreatedAt time.Time
+
+	DecidedBy gidx.PrefixedID
+	DecidedAt *time.Time
+	// DecisionReason is the justification given by the approver.
+	DecisionReason string
+	RoleBindingID  gidx.PrefixedID
+}
+
+// Group is a set of subjects owned by a resource, which can be bound to roles
+// as a single subject.
+type Group struct {
+	ID          gidx.PrefixedID
+	OwnerID     gidx.PrefixedID
+	Name        string
+	Description string
 
 	CreatedBy gidx.PrefixedID
 	UpdatedBy gidx.PrefixedID
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
+
+// GrantStep is a relation followed to grant a permission, the steps of a
+// grant path lead from the checked resource and action to the subject.
+type GrantStep struct {
+	// Type is the resource type of the object, e.g. tenant or rolebinding.
+	Type string
+	// ID is the ID of the object.
+	ID string
+	// Relation is the relation or permission of the object followed, empty for the subject.
+	Relation string
+}
+
+// GroupMember is a direct or transitive member of a group.
+type GroupMember struct {
+	Subject Resource
+	// Groups are the groups of the hierarchy the subject is a direct member of.
+	Groups []gidx.PrefixedID
+}
+
+// TenantSettings toggles policy behaviors for the resources of a tenant,
+// allowing stricter defaults for some tenants within one deployment.
+type TenantSettings struct {
+	TenantID gidx.PrefixedID
+	// AllowGroupBindings allows binding roles to groups, or other subjects
+	// representing a set of members, within the tenant.
+	AllowGroupBindings bool
+	// InheritParentRoles allows binding roles owned by the ancestors of the
+	// tenant within the tenant.
+	InheritParentRoles bool
+
+	UpdatedBy gidx.PrefixedID
+	UpdatedAt time.Time
+}
+
+// ResourceAlias maps an identifier of a resource in an external system, such
+// as a URN, to the resource ID.
+type ResourceAlias struct {
+	Alias      string
+	ResourceID gidx.PrefixedID
+
+	CreatedBy gidx.PrefixedID
+	CreatedAt time.Time
+}
+
+// RoleBindingRequest describes a role binding to be created in a bulk request.
+type RoleBindingRequest struct {
+	Role     Resource
+	Subjects []RoleBindingSubject
+	// Caveat optionally conditions the role binding on a caveat.
+	Caveat *RoleBindingCaveat
+}
+
+// RoleBindingResult is the outcome of a single role binding in a bulk request,
+// Err is set when the role binding failed.
+type RoleBindingResult struct {
+	RoleBinding RoleBinding
+	Err         error
+}
+
+// RoleMigration is the outcome of migrating a single v1 role to a v2 role,
+// Err is set when the role could not be migrated.
+type RoleMigration struct {
+	// V1Role is the migrated v1 role.
+	V1Role Role
+	// V2Role is the v2 role replacing the v1 role, empty on dry runs.
+	V2Role Role
+	// RoleBinding binds the v2 role to the Subjects on the resource, empty on
+	// dry runs and when the v1 role was not assigned to any subjects.
+	RoleBinding RoleBinding
+	// Subjects are the subjects the v1 role was assigned to.
+	Subjects []Resource
+	Err      error
+}
+
+// TenantPurgeReport is the outcome of purging the authorization footprint of a tenant.
+type TenantPurgeReport struct {
+	// Tenant is the purged tenant.
+	Tenant Resource
+	// Resources are the resources descending from the tenant whose footprint
+	// was deleted, deepest first. On dry runs, the resources which would be deleted.
+	Resources []Resource
+	// Passes is the number of batches the resources were deleted in.
+	Passes int
+	// Remaining describes the footprint left of the tenant, it is empty when
+	// the purge is complete. On dry runs, the footprint of the tenant itself.
+	Remaining []string
+}
+
+// PolicyInfo identifies the policy loaded by the engine and the SpiceDB schema
+// it generated.
+type PolicyInfo struct {
+	// PolicyHash is the hash of the loaded policy document.
+	PolicyHash string
+	// SchemaHash is the hash of the schema generated from the policy.
+	SchemaHash string
+	// LiveSchemaHash is the hash of the schema applied to SpiceDB, empty if
+	// no schema was applied yet.
+	LiveSchemaHash string
+	// SchemaMatches is true when the schema applied to SpiceDB is the schema
+	// generated from the policy.
+	SchemaMatches bool
+	LoadedAt      time.Time
+}
+
+// Decision is the outcome of a permission check, as recorded by the decision log.
+type Decision struct {
+	SubjectID  gidx.PrefixedID
+	ResourceID gidx.PrefixedID
+	Action     string
+	// Outcome is either allowed, denied or error.
+	Outcome string
+	Latency time.Duration
+	// ZedToken is the SpiceDB revision the permission was checked at, empty
+	// when the check did not reach SpiceDB.
+	ZedToken string
+	// RequestID is the ID of the API request the check was made for, if any.
+	RequestID string
+	DecidedAt time.Time
+}
+
+// Webhook delivery statuses.
+const (
+	// WebhookDeliveryPending is the status of deliveries which have yet to succeed.
+	WebhookDeliveryPending = "pending"
+	// WebhookDeliveryDelivered is the status of deliveries accepted by the webhook.
+	WebhookDeliveryDelivered = "delivered"
+	// WebhookDeliveryFailed is the status of deliveries which failed all attempts.
+	WebhookDeliveryFailed = "failed"
+)
+
+// WebhookDelivery is the notification of a change sent to a webhook.
+type WebhookDelivery struct {
+	ID gidx.PrefixedID
+	// Webhook is the name of the webhook the change is delivered to.
+	Webhook   string
+	EventType string
+	// Payload is the JSON encoded body sent to the webhook.
+	Payload []byte
+	Status  string
+	// Attempts is the number of failed or successful delivery attempts.
+	Attempts int
+	// ResponseCode and LastError describe the outcome of the last attempt.
+	ResponseCode  int
+	LastError     string
+	NextAttemptAt time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,14 @@
+// Package webhooks notifies external systems, e.g. ticketing or compliance
+// systems, of changes to roles and role-bindings over HTTP, so they can react
+// to changes without consuming NATS.
+//
+// Every change is stored as a delivery per matching webhook in the
+// permissions database before it is sent, and retried with exponential
+// backoff until the webhook accepts it or all attempts failed. The status of
+// every delivery is kept in the database. Deliveries are at least once, the
+// delivery ID passed with every request allows webhooks to drop duplicates.
+//
+// Requests are signed with the HMAC-SHA256 of the body, keyed with the secret
+// of the webhook, so webhooks can verify the request was sent by
+// permissions-api.
+package webhooks
@@ -0,0 +1,6 @@
+package webhooks
+
+import "errors"
+
+// ErrInvalidWebhook is returned when a webhook is misconfigured.
+var ErrInvalidWebhook = errors.New("invalid webhook")
@@ -0,0 +1,366 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"go.infratographer.com/x/gidx"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+const (
+	// DeliveryIDPrefix is the ID prefix of webhook deliveries.
+	DeliveryIDPrefix = "permwhd"
+
+	// SignatureHeader is the request header holding the signature of the body.
+	SignatureHeader = "X-Permissions-Signature"
+	// EventHeader is the request header holding the event type.
+	EventHeader = "X-Permissions-Event"
+	// DeliveryHeader is the request header holding the delivery ID, which is
+	// the same for all attempts of a delivery.
+	DeliveryHeader = "X-Permissions-Delivery"
+
+	// DefaultMaxAttempts is the default number of attempts to deliver a change.
+	DefaultMaxAttempts = 5
+	// DefaultInitialBackoff is the default time waited before retrying a failed delivery.
+	DefaultInitialBackoff = 10 * time.Second
+	// DefaultMaxBackoff is the default maximum time waited between attempts.
+	DefaultMaxBackoff = 10 * time.Minute
+	// DefaultTimeout is the default timeout of a delivery request.
+	DefaultTimeout = 10 * time.Second
+	// DefaultPollInterval is the default interval at which due deliveries are sent.
+	DefaultPollInterval = 5 * time.Second
+
+	// claimBatchSize is the maximum number of deliveries sent per poll.
+	claimBatchSize = 100
+
+	// maxErrorLength limits the length of the error stored with a failed attempt.
+	maxErrorLength = 500
+)
+
+// Endpoint is a webhook changes are delivered to.
+type Endpoint struct {
+	// Name identifies the webhook in delivery statuses.
+	Name string
+	// URL is where changes are posted to.
+	URL string
+	// Secret signs the requests.
+	Secret string
+	// Events are the event types delivered, all role and role-binding changes if empty.
+	Events []string
+}
+
+// Config configures the webhooks changes are delivered to.
+type Config struct {
+	Endpoints []Endpoint
+	// MaxAttempts is the number of attempts to deliver a change, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the time waited before the first retry, doubled after every attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum time waited between attempts.
+	MaxBackoff time.Duration
+	// Timeout is the timeout of a delivery request.
+	Timeout time.Duration
+	// PollInterval is the interval at which due deliveries are sent.
+	PollInterval time.Duration
+}
+
+// Validate ensures every endpoint has a unique name, a valid URL and a secret.
+func (c Config) Validate() error {
+	names := make(map[string]struct{}, len(c.Endpoints))
+
+	for _, ep := range c.Endpoints {
+		if ep.Name == "" {
+			return fmt.Errorf("%w: name is required", ErrInvalidWebhook)
+		}
+
+		if _, ok := names[ep.Name]; ok {
+			return fmt.Errorf("%w: %s: duplicate name", ErrInvalidWebhook, ep.Name)
+		}
+
+		names[ep.Name] = struct{}{}
+
+		u, err := url.Parse(ep.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: %s: url must be an absolute http or https url", ErrInvalidWebhook, ep.Name)
+		}
+
+		if ep.Secret == "" {
+			return fmt.Errorf("%w: %s: secret is required", ErrInvalidWebhook, ep.Name)
+		}
+	}
+
+	return nil
+}
+
+// Event is a change delivered to webhooks.
+type Event struct {
+	Type      string          `json:"event_type"`
+	SubjectID gidx.PrefixedID `json:"subject_id"`
+	// ResourceID is the resource the changed object belongs to.
+	ResourceID gidx.PrefixedID `json:"resource_id,omitempty"`
+	ActorID    gidx.PrefixedID `json:"actor_id,omitempty"`
+	Before     map[string]any  `json:"before,omitempty"`
+	After      map[string]any  `json:"after,omitempty"`
+	Timestamp  time.Time       `json:"timestamp"`
+}
+
+// Dispatcher stores changes as deliveries to the matching webhooks and sends
+// the deliveries in the background.
+type Dispatcher struct {
+	store  storage.WebhookService
+	config Config
+	client *http.Client
+	logger *zap.SugaredLogger
+}
+
+// Option is a functional option for the dispatcher.
+type Option func(*Dispatcher)
+
+// WithLogger sets the logger of the dispatcher.
+func WithLogger(logger *zap.SugaredLogger) Option {
+	return func(d *Dispatcher) {
+		d.logger = logger
+	}
+}
+
+// WithHTTPClient sets the client deliveries are sent with.
+func WithHTTPClient(client *http.Client) Option {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// NewDispatcher returns a dispatcher delivering changes to the configured webhooks.
+func NewDispatcher(store storage.WebhookService, config Config, options ...Option) (*Dispatcher, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = DefaultInitialBackoff
+	}
+
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+
+	d := &Dispatcher{
+		store:  store,
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		logger: zap.NewNop().Sugar(),
+	}
+
+	for _, opt := range options {
+		opt(d)
+	}
+
+	return d, nil
+}
+
+// Enqueue stores the event as a delivery to every webhook subscribed to its type.
+func (d *Dispatcher) Enqueue(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []types.WebhookDelivery
+
+	for _, ep := range d.config.Endpoints {
+		if len(ep.Events) != 0 && !slices.Contains(ep.Events, event.Type) {
+			continue
+		}
+
+		id, err := gidx.NewID(DeliveryIDPrefix)
+		if err != nil {
+			return err
+		}
+
+		deliveries = append(deliveries, types.WebhookDelivery{
+			ID:        id,
+			Webhook:   ep.Name,
+			EventType: event.Type,
+			Payload:   payload,
+		})
+	}
+
+	return d.store.CreateWebhookDeliveries(ctx, deliveries...)
+}
+
+// Run sends due deliveries every poll interval until the context is canceled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.deliverDue(ctx)
+		}
+	}
+}
+
+// deliverDue sends all deliveries which are due.
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	for {
+		now := time.Now()
+
+		// deliveries are leased for as long as all of them may take to send.
+		lease := now.Add(d.config.Timeout * claimBatchSize)
+
+		deliveries, err := d.store.ClaimWebhookDeliveries(ctx, now, lease, claimBatchSize)
+		if err != nil {
+			d.logger.Errorw("failed to claim webhook deliveries", "error", err)
+
+			return
+		}
+
+		for _, delivery := range deliveries {
+			d.attempt(ctx, delivery)
+		}
+
+		if len(deliveries) < claimBatchSize {
+			return
+		}
+	}
+}
+
+// attempt sends a delivery and records the outcome.
+func (d *Dispatcher) attempt(ctx context.Context, delivery types.WebhookDelivery) {
+	delivery.Attempts++
+
+	ep, ok := d.endpoint(delivery.Webhook)
+	if !ok {
+		// the webhook was removed from the configuration.
+		delivery.Status = types.WebhookDeliveryFailed
+		delivery.LastError = "webhook is not configured"
+	} else {
+		delivery.ResponseCode, delivery.LastError = d.send(ctx, ep, delivery)
+
+		switch {
+		case delivery.LastError == "":
+			delivery.Status = types.WebhookDeliveryDelivered
+		case delivery.Attempts >= d.config.MaxAttempts:
+			delivery.Status = types.WebhookDeliveryFailed
+		default:
+			delivery.NextAttemptAt = time.Now().Add(d.backoff(delivery.Attempts))
+		}
+	}
+
+	if delivery.Status == types.WebhookDeliveryFailed {
+		d.logger.Warnw("webhook delivery failed",
+			"webhook", delivery.Webhook,
+			"delivery", delivery.ID,
+			"attempts", delivery.Attempts,
+			"error", delivery.LastError,
+		)
+	}
+
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = time.Now()
+	}
+
+	if err := d.store.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		d.logger.Errorw("failed to update webhook delivery", "delivery", delivery.ID, "error", err)
+	}
+}
+
+// send posts a delivery to the webhook, returning the response code and an
+// error message if the delivery was not accepted.
+func (d *Dispatcher) send(ctx context.Context, ep Endpoint, delivery types.WebhookDelivery) (int, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, truncateError(err.Error())
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(SignatureHeader, Sign(ep.Secret, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, truncateError(err.Error())
+	}
+
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorLength))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Sprintf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, ""
+}
+
+func (d *Dispatcher) endpoint(name string) (Endpoint, bool) {
+	for _, ep := range d.config.Endpoints {
+		if ep.Name == name {
+			return ep, true
+		}
+	}
+
+	return Endpoint{}, false
+}
+
+// backoff returns the time to wait after the given failed attempt, starting at 1.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	backoff := d.config.InitialBackoff
+
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+
+		if backoff >= d.config.MaxBackoff {
+			return d.config.MaxBackoff
+		}
+	}
+
+	return backoff
+}
+
+// Sign returns the signature of a body, the hex encoded HMAC-SHA256 of the
+// body keyed with the secret, prefixed with "sha256=".
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func truncateError(msg string) string {
+	if len(msg) > maxErrorLength {
+		return msg[:maxErrorLength]
+	}
+
+	return msg
+}
@@ -0,0 +1,197 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/testingx"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+func TestConfigValidate(t *testing.T) {
+	valid := Endpoint{Name: "tickets", URL: "https://tickets.example.com/hook", Secret: "secret"}
+
+	tc := []testingx.TestCase[[]Endpoint, any]{
+		{
+			Name:  "Valid",
+			Input: []Endpoint{valid},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.NoError(t, res.Err)
+			},
+		},
+		{
+			Name:  "MissingName",
+			Input: []Endpoint{{URL: valid.URL, Secret: valid.Secret}},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidWebhook)
+			},
+		},
+		{
+			Name:  "DuplicateName",
+			Input: []Endpoint{valid, valid},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidWebhook)
+			},
+		},
+		{
+			Name:  "RelativeURL",
+			Input: []Endpoint{{Name: "tickets", URL: "/hook", Secret: valid.Secret}},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidWebhook)
+			},
+		},
+		{
+			Name:  "MissingSecret",
+			Input: []Endpoint{{Name: "tickets", URL: valid.URL}},
+			CheckFn: func(_ context.Context, t *testing.T, res testingx.TestResult[any]) {
+				assert.ErrorIs(t, res.Err, ErrInvalidWebhook)
+			},
+		},
+	}
+
+	testFn := func(_ context.Context, endpoints []Endpoint) testingx.TestResult[any] {
+		return testingx.TestResult[any]{Err: Config{Endpoints: endpoints}.Validate()}
+	}
+
+	testingx.RunTests(context.Background(), t, tc, testFn)
+}
+
+func TestDispatcher(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+
+	var (
+		mu       sync.Mutex
+		received []*http.Request
+		bodies   [][]byte
+		failures = 1
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		body, _ := io.ReadAll(r.Body)
+
+		if r.URL.Path == "/flaky" && failures > 0 {
+			failures--
+
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		received = append(received, r)
+		bodies = append(bodies, body)
+	}))
+	t.Cleanup(srv.Close)
+
+	d, err := NewDispatcher(store, Config{
+		Endpoints: []Endpoint{
+			{Name: "all", URL: srv.URL + "/all", Secret: "all-secret"},
+			{Name: "flaky", URL: srv.URL + "/flaky", Secret: "flaky-secret", Events: []string{"role_created"}},
+			{Name: "bindings", URL: srv.URL + "/bindings", Secret: "bindings-secret", Events: []string{"rolebinding_created"}},
+		},
+		InitialBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, d.Enqueue(ctx, Event{
+		Type:       "role_created",
+		SubjectID:  "permrv2-role",
+		ResourceID: "tnntten-root",
+		ActorID:    "idntusr-actor",
+		After:      map[string]any{"name": "viewer"},
+		Timestamp:  time.Now(),
+	}))
+
+	d.deliverDue(ctx)
+
+	all, err := store.ListWebhookDeliveries(ctx, "all", 10)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, types.WebhookDeliveryDelivered, all[0].Status)
+	assert.Equal(t, 1, all[0].Attempts)
+	assert.Equal(t, http.StatusOK, all[0].ResponseCode)
+
+	flaky, err := store.ListWebhookDeliveries(ctx, "flaky", 10)
+	require.NoError(t, err)
+	require.Len(t, flaky, 1)
+	assert.Equal(t, types.WebhookDeliveryPending, flaky[0].Status)
+	assert.Equal(t, http.StatusServiceUnavailable, flaky[0].ResponseCode)
+	assert.NotEmpty(t, flaky[0].LastError)
+
+	bindings, err := store.ListWebhookDeliveries(ctx, "bindings", 10)
+	require.NoError(t, err)
+	assert.Empty(t, bindings)
+
+	// the failed delivery is retried once its backoff passed
+	time.Sleep(10 * time.Millisecond)
+	d.deliverDue(ctx)
+
+	flaky, err = store.ListWebhookDeliveries(ctx, "flaky", 10)
+	require.NoError(t, err)
+	require.Len(t, flaky, 1)
+	assert.Equal(t, types.WebhookDeliveryDelivered, flaky[0].Status)
+	assert.Equal(t, 2, flaky[0].Attempts)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, received, 2)
+
+	for i, r := range received {
+		secret := "all-secret"
+		if r.URL.Path == "/flaky" {
+			secret = "flaky-secret"
+		}
+
+		assert.Equal(t, "role_created", r.Header.Get(EventHeader))
+		assert.NotEmpty(t, r.Header.Get(DeliveryHeader))
+		assert.Equal(t, Sign(secret, bodies[i]), r.Header.Get(SignatureHeader))
+		assert.Contains(t, string(bodies[i]), `"subject_id":"permrv2-role"`)
+	}
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	d, err := NewDispatcher(store, Config{
+		Endpoints:      []Endpoint{{Name: "down", URL: srv.URL, Secret: "secret"}},
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, d.Enqueue(ctx, Event{Type: "rolebinding_deleted", SubjectID: "permrbn-binding", Timestamp: time.Now()}))
+
+	for i := 0; i < 3; i++ {
+		d.deliverDue(ctx)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	deliveries, err := store.ListWebhookDeliveries(ctx, "down", 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, types.WebhookDeliveryFailed, deliveries[0].Status)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"go.infratographer.com/x/gidx"
+)
+
+const (
+	checkModeAll = "all"
+	checkModeAny = "any"
+)
+
+type checkRequest struct {
+	Actions []AccessRequest `json:"actions"`
+	Mode    string          `json:"mode,omitempty"`
+}
+
+// Check reports whether the authenticated subject may perform the action on the resource.
+func (c *Client) Check(ctx context.Context, resourceID gidx.PrefixedID, action string, opts ...CallOption) (bool, error) {
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/api/v1/allow",
+		query: url.Values{
+			"resource": []string{resourceID.String()},
+			"action":   []string{action},
+		},
+		idempotent: true,
+		status:     http.StatusOK,
+	}, nil, opts)
+
+	return checkResult(err)
+}
+
+// CheckAll reports whether the authenticated subject may perform all requested actions.
+func (c *Client) CheckAll(ctx context.Context, requests []AccessRequest, opts ...CallOption) (bool, error) {
+	return c.check(ctx, checkModeAll, requests, opts)
+}
+
+// CheckAny reports whether the authenticated subject may perform any of the requested actions.
+func (c *Client) CheckAny(ctx context.Context, requests []AccessRequest, opts ...CallOption) (bool, error) {
+	return c.check(ctx, checkModeAny, requests, opts)
+}
+
+func (c *Client) check(ctx context.Context, mode string, requests []AccessRequest, opts []CallOption) (bool, error) {
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/v1/allow",
+		body:   checkRequest{Actions: requests, Mode: mode},
+		// checks do not change any state
+		idempotent: true,
+		status:     http.StatusOK,
+	}, nil, opts)
+
+	return checkResult(err)
+}
+
+type filterRequest struct {
+	Action      string            `json:"action"`
+	ResourceIDs []gidx.PrefixedID `json:"resource_ids"`
+}
+
+type filterResponse struct {
+	ResourceIDs []gidx.PrefixedID `json:"resource_ids"`
+}
+
+// FilterAllowed returns the resources the authenticated subject may perform
+// the action on, in the order they were given, so list results can be
+// post-filtered in a single call.
+func (c *Client) FilterAllowed(ctx context.Context, action string, resourceIDs []gidx.PrefixedID, opts ...CallOption) ([]gidx.PrefixedID, error) {
+	var resp filterResponse
+
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/v3/allow/filter",
+		body:   filterRequest{Action: action, ResourceIDs: resourceIDs},
+		// filters do not change any state
+		idempotent: true,
+		status:     http.StatusOK,
+	}, &resp, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.ResourceIDs, nil
+}
+
+// checkResult converts the error of a check to its result, denied checks are not errors.
+func checkResult(err error) (bool, error) {
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, ErrPermissionDenied):
+		return false, nil
+	default:
+		return false, err
+	}
+}
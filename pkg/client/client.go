@@ -0,0 +1,238 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ConsistencyTokenHeader is the header carrying consistency tokens between the client and the API.
+	ConsistencyTokenHeader = "X-Consistency-Token"
+
+	// DefaultMaxRetries is the default number of times a failed call is retried.
+	DefaultMaxRetries = 3
+
+	// DefaultRetryBackoff is the default initial wait before retrying a call.
+	DefaultRetryBackoff = 100 * time.Millisecond
+
+	// DefaultMaxRetryBackoff is the default maximum wait before retrying a call.
+	DefaultMaxRetryBackoff = 5 * time.Second
+
+	defaultTimeout = 10 * time.Second
+
+	maxErrorBodySize = 64 << 10
+)
+
+// TokenSource returns the bearer token to authenticate a request with.
+type TokenSource func(ctx context.Context) (string, error)
+
+// Client is a permissions-api client, it is safe for concurrent use.
+type Client struct {
+	baseURL *url.URL
+	http    *http.Client
+	token   TokenSource
+
+	maxRetries      int
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+}
+
+// New creates a new client for the permissions-api served at baseURL, e.g. https://permissions.example.com.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid base url: %s", ErrClient, err.Error())
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%w: invalid base url: %s", ErrClient, baseURL)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	c := &Client{
+		baseURL:         u,
+		http:            &http.Client{Timeout: defaultTimeout},
+		maxRetries:      DefaultMaxRetries,
+		retryBackoff:    DefaultRetryBackoff,
+		maxRetryBackoff: DefaultMaxRetryBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// call describes a single API call.
+type call struct {
+	method string
+	path   string
+	query  url.Values
+	body   any
+	// idempotent calls are retried on server errors, all calls are retried
+	// when rate limited as the request was not processed.
+	idempotent bool
+	// status is the expected response status.
+	status int
+}
+
+// do performs the call, retrying it as needed, and decodes the response into out, if not nil.
+func (c *Client) do(ctx context.Context, cl call, out any, opts []CallOption) error {
+	callOpts := callOptions{}
+
+	for _, opt := range opts {
+		opt(&callOpts)
+	}
+
+	var body []byte
+
+	if cl.body != nil {
+		var err error
+
+		body, err = json.Marshal(cl.body)
+		if err != nil {
+			return fmt.Errorf("%w: encoding request: %s", ErrClient, err.Error())
+		}
+	}
+
+	u := c.baseURL.JoinPath(cl.path)
+	u.RawQuery = cl.query.Encode()
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.attempt(ctx, cl.method, u.String(), body, callOpts)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == cl.status {
+			return c.decode(resp, out, callOpts)
+		}
+
+		apiErr := newError(resp)
+
+		resp.Body.Close()
+
+		if attempt >= c.maxRetries || !retryable(cl, resp.StatusCode) {
+			return apiErr
+		}
+
+		if err := c.wait(ctx, attempt, resp.Header.Get("Retry-After")); err != nil {
+			return apiErr
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, method, u string, body []byte, callOpts callOptions) (*http.Response, error) {
+	var reqBody io.Reader
+
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w: creating request: %s", ErrClient, err.Error())
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.token != nil {
+		token, err := c.token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: getting token: %w", ErrClient, err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	if callOpts.atLeastAsFresh != "" {
+		req.Header.Set(ConsistencyTokenHeader, callOpts.atLeastAsFresh)
+	}
+
+	if callOpts.ifMatch != "" {
+		req.Header.Set("If-Match", callOpts.ifMatch)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrClient, err)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) decode(resp *http.Response, out any, callOpts callOptions) error {
+	defer resp.Body.Close()
+
+	if callOpts.consistencyToken != nil {
+		if token := resp.Header.Get(ConsistencyTokenHeader); token != "" {
+			*callOpts.consistencyToken = token
+		}
+	}
+
+	if callOpts.etag != nil {
+		*callOpts.etag = resp.Header.Get("ETag")
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%w: decoding response: %s", ErrClient, err.Error())
+	}
+
+	return nil
+}
+
+// retryable reports whether a call failing with the given status may be retried.
+func retryable(cl call, status int) bool {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return true
+	case status >= http.StatusInternalServerError:
+		return cl.idempotent
+	default:
+		return false
+	}
+}
+
+// wait waits before the next attempt, as long as requested by the Retry-After
+// header, or with an exponential, jittered backoff otherwise.
+func (c *Client) wait(ctx context.Context, attempt int, retryAfter string) error {
+	delay := c.retryBackoff << attempt
+	if delay <= 0 || delay > c.maxRetryBackoff {
+		delay = c.maxRetryBackoff
+	}
+
+	delay = delay/2 + rand.N(delay/2+1) //nolint:gosec // jitter does not need a secure source
+
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		delay = min(time.Duration(seconds)*time.Second, c.maxRetryBackoff)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
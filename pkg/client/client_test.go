@@ -0,0 +1,241 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/pkg/client"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *client.Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c, err := client.New(srv.URL, client.WithToken("secret"), client.WithRetries(2, time.Millisecond, time.Millisecond))
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestRoles(t *testing.T) {
+	t.Parallel()
+
+	roleID := gidx.PrefixedID("permrv2-abc123")
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v3/resources/tnntten-abc123/roles":
+			var body map[string]any
+
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "admin", body["name"])
+
+			w.Header().Set("X-Consistency-Token", "written")
+			w.WriteHeader(http.StatusCreated)
+
+			_, _ = w.Write([]byte(`{"id":"permrv2-abc123","name":"admin","actions":["tenant_get"]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v3/roles/permrv2-abc123":
+			assert.Equal(t, "written", r.Header.Get("X-Consistency-Token"))
+
+			_, _ = w.Write([]byte(`{"id":"permrv2-abc123","name":"admin","actions":["tenant_get"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+
+			_, _ = w.Write([]byte(`{"error":{"code":"not_found","status":404,"message":"role not found"}}`))
+		}
+	})
+
+	var token string
+
+	role, err := c.CreateRole(context.Background(), "tnntten-abc123", "admin", []string{"tenant_get"}, client.ConsistencyToken(&token))
+	require.NoError(t, err)
+	assert.Equal(t, roleID, role.ID)
+	assert.Equal(t, "written", token)
+
+	role, err = c.GetRole(context.Background(), roleID, client.AtLeastAsFresh(token))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tenant_get"}, role.Actions)
+
+	err = c.DeleteRole(context.Background(), "permrv2-def456")
+	require.ErrorIs(t, err, client.ErrNotFound)
+
+	var apiErr *client.Error
+
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "not_found", apiErr.Code)
+	assert.Equal(t, "role not found", apiErr.Message)
+}
+
+func TestRoleETags(t *testing.T) {
+	t.Parallel()
+
+	roleID := gidx.PrefixedID("permrv2-abc123")
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("ETag", `"v1"`)
+
+			_, _ = w.Write([]byte(`{"id":"permrv2-abc123","name":"admin","actions":["tenant_get"]}`))
+		case http.MethodPatch:
+			if r.Header.Get("If-Match") != `"v1"` {
+				w.WriteHeader(http.StatusPreconditionFailed)
+
+				_, _ = w.Write([]byte(`{"error":{"code":"precondition_failed","status":412,"message":"role was modified"}}`))
+
+				return
+			}
+
+			w.Header().Set("ETag", `"v2"`)
+
+			_, _ = w.Write([]byte(`{"id":"permrv2-abc123","name":"owner","actions":["tenant_get"]}`))
+		}
+	})
+
+	var etag string
+
+	_, err := c.GetRole(context.Background(), roleID, client.ETag(&etag))
+	require.NoError(t, err)
+	assert.Equal(t, `"v1"`, etag)
+
+	role, err := c.UpdateRole(context.Background(), roleID, "owner", []string{"tenant_get"}, client.IfMatch(etag), client.ETag(&etag))
+	require.NoError(t, err)
+	assert.Equal(t, "owner", role.Name)
+	assert.Equal(t, `"v2"`, etag)
+
+	_, err = c.UpdateRole(context.Background(), roleID, "admin", []string{"tenant_get"}, client.IfMatch(`"v0"`))
+	assert.ErrorIs(t, err, client.ErrPreconditionFailed)
+}
+
+func TestRetries(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		status   int
+		create   bool
+		err      error
+		attempts int32
+	}{
+		{name: "rate limited", status: http.StatusTooManyRequests, create: true, err: client.ErrRateLimited, attempts: 3},
+		{name: "idempotent", status: http.StatusServiceUnavailable, err: client.ErrUnavailable, attempts: 3},
+		{name: "not idempotent", status: http.StatusInternalServerError, create: true, err: client.ErrServer, attempts: 1},
+		{name: "client error", status: http.StatusBadRequest, err: client.ErrInvalidRequest, attempts: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var attempts atomic.Int32
+
+			c := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+				attempts.Add(1)
+
+				w.WriteHeader(tc.status)
+
+				_, _ = w.Write([]byte(`{"message":"failed"}`))
+			})
+
+			var err error
+
+			if tc.create {
+				_, err = c.CreateRoleBinding(context.Background(), "tnntten-abc123", "permrv2-abc123", []gidx.PrefixedID{"idntusr-abc123"})
+			} else {
+				_, err = c.ListRoleBindings(context.Background(), "tnntten-abc123")
+			}
+
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.attempts, attempts.Load())
+		})
+	}
+}
+
+func TestChecks(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if r.URL.Query().Get("action") == "tenant_get" {
+				_, _ = w.Write([]byte(`{}`))
+
+				return
+			}
+
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		var body struct {
+			Mode string `json:"mode"`
+		}
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		if body.Mode == "any" {
+			_, _ = w.Write([]byte(`{}`))
+
+			return
+		}
+
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	allowed, err := c.Check(context.Background(), "tnntten-abc123", "tenant_get")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = c.Check(context.Background(), "tnntten-abc123", "tenant_delete")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	requests := []client.AccessRequest{
+		{ResourceID: "tnntten-abc123", Action: "tenant_get"},
+		{ResourceID: "tnntten-abc123", Action: "tenant_delete"},
+	}
+
+	allowed, err = c.CheckAll(context.Background(), requests)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	allowed, err = c.CheckAny(context.Background(), requests)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestFilterAllowed(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/allow/filter", r.URL.Path)
+
+		var body struct {
+			Action      string   `json:"action"`
+			ResourceIDs []string `json:"resource_ids"`
+		}
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "loadbalancer_get", body.Action)
+		assert.Equal(t, []string{"loadbal-abc123", "loadbal-def456"}, body.ResourceIDs)
+
+		_, _ = w.Write([]byte(`{"subject_id":"idntusr-abc123","action":"loadbalancer_get","resource_ids":["loadbal-def456"]}`))
+	})
+
+	allowed, err := c.FilterAllowed(context.Background(), "loadbalancer_get", []gidx.PrefixedID{"loadbal-abc123", "loadbal-def456"})
+	require.NoError(t, err)
+	assert.Equal(t, []gidx.PrefixedID{"loadbal-def456"}, allowed)
+}
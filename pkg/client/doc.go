@@ -0,0 +1,6 @@
+// Package client is a Go client for the permissions-api REST API, covering
+// roles, role-bindings, relationships and permission checks. Errors returned
+// by the API are mapped to *Error values matching the sentinel errors of this
+// package, rate limited and failed idempotent calls are retried, and calls can
+// read their own writes by passing consistency tokens between them.
+package client
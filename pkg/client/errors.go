@@ -0,0 +1,140 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrClient is the root error for all client errors.
+	ErrClient = errors.New("permissions-api client error")
+
+	// ErrInvalidRequest is returned when the API rejected the request as invalid.
+	ErrInvalidRequest = fmt.Errorf("%w: invalid request", ErrClient)
+
+	// ErrUnauthenticated is returned when the request was not authenticated.
+	ErrUnauthenticated = fmt.Errorf("%w: unauthenticated", ErrClient)
+
+	// ErrPermissionDenied is returned when the subject is not allowed to perform the request.
+	ErrPermissionDenied = fmt.Errorf("%w: permission denied", ErrClient)
+
+	// ErrNotFound is returned when the requested object does not exist.
+	ErrNotFound = fmt.Errorf("%w: not found", ErrClient)
+
+	// ErrConflict is returned when the request conflicts with an existing object.
+	ErrConflict = fmt.Errorf("%w: conflict", ErrClient)
+
+	// ErrPreconditionFailed is returned when an object was modified since the
+	// version passed to IfMatch.
+	ErrPreconditionFailed = fmt.Errorf("%w: precondition failed", ErrClient)
+
+	// ErrGone is returned when the requested object is no longer available.
+	ErrGone = fmt.Errorf("%w: gone", ErrClient)
+
+	// ErrRateLimited is returned when the request was still rate limited after all retries.
+	ErrRateLimited = fmt.Errorf("%w: rate limited", ErrClient)
+
+	// ErrUnavailable is returned when the API or its dependencies are unavailable.
+	ErrUnavailable = fmt.Errorf("%w: unavailable", ErrClient)
+
+	// ErrServer is returned for all other server errors.
+	ErrServer = fmt.Errorf("%w: server error", ErrClient)
+)
+
+// Error is an error response of the API.
+type Error struct {
+	// Code is the machine readable error code, e.g. not_found.
+	Code string
+	// Status is the HTTP status code of the response.
+	Status int
+	// Message is the human readable description of the error.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("permissions-api: %s (%d): %s", e.Code, e.Status, e.Message)
+}
+
+// Unwrap returns the sentinel error matching the status of the response.
+func (e *Error) Unwrap() error {
+	switch {
+	case e.Status == http.StatusBadRequest, e.Status == http.StatusUnprocessableEntity:
+		return ErrInvalidRequest
+	case e.Status == http.StatusUnauthorized:
+		return ErrUnauthenticated
+	case e.Status == http.StatusForbidden:
+		return ErrPermissionDenied
+	case e.Status == http.StatusNotFound:
+		return ErrNotFound
+	case e.Status == http.StatusConflict:
+		return ErrConflict
+	case e.Status == http.StatusGone:
+		return ErrGone
+	case e.Status == http.StatusPreconditionFailed:
+		return ErrPreconditionFailed
+	case e.Status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.Status == http.StatusServiceUnavailable:
+		return ErrUnavailable
+	case e.Status >= http.StatusInternalServerError:
+		return ErrServer
+	default:
+		return ErrClient
+	}
+}
+
+// errorBody matches both the structured error responses of v3 and later API
+// versions, and the error responses of earlier versions.
+type errorBody struct {
+	Error *struct {
+		Code    string `json:"code"`
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+	} `json:"error"`
+	Message string `json:"message"`
+}
+
+// newError reads the error response.
+func newError(resp *http.Response) *Error {
+	apiErr := &Error{
+		Code:    errorCode(resp.StatusCode),
+		Status:  resp.StatusCode,
+		Message: http.StatusText(resp.StatusCode),
+	}
+
+	var body errorBody
+
+	//nolint:errcheck // the status alone describes the error if the body can not be decoded.
+	json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&body)
+
+	switch {
+	case body.Error != nil:
+		if body.Error.Code != "" {
+			apiErr.Code = body.Error.Code
+		}
+
+		if body.Error.Message != "" {
+			apiErr.Message = body.Error.Message
+		}
+	case body.Message != "":
+		apiErr.Message = body.Message
+	}
+
+	return apiErr
+}
+
+// errorCode derives the error code from the status, as the API does for
+// versions without structured errors.
+func errorCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Option configures the client.
+type Option func(c *Client)
+
+// WithHTTPClient sets the http client used to make requests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.http = client
+	}
+}
+
+// WithToken authenticates all requests with the given bearer token.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = func(context.Context) (string, error) {
+			return token, nil
+		}
+	}
+}
+
+// WithTokenSource authenticates each request with the bearer token returned
+// by the source, e.g. to refresh expiring tokens.
+func WithTokenSource(source TokenSource) Option {
+	return func(c *Client) {
+		c.token = source
+	}
+}
+
+// WithRetries sets how often failed calls are retried and the initial and
+// maximum wait between attempts, zero retries disables retrying.
+func WithRetries(maxRetries int, backoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+		c.maxRetryBackoff = maxBackoff
+	}
+}
+
+// CallOption configures a single call.
+type CallOption func(o *callOptions)
+
+type callOptions struct {
+	atLeastAsFresh   string
+	consistencyToken *string
+	ifMatch          string
+	etag             *string
+}
+
+// AtLeastAsFresh evaluates the call at least as fresh as the given
+// consistency token, returned by an earlier write, so the call sees its effects.
+func AtLeastAsFresh(token string) CallOption {
+	return func(o *callOptions) {
+		o.atLeastAsFresh = token
+	}
+}
+
+// ConsistencyToken stores the consistency token of the relationships written
+// by the call in token, it is left unchanged when the call did not write any.
+func ConsistencyToken(token *string) CallOption {
+	return func(o *callOptions) {
+		o.consistencyToken = token
+	}
+}
+
+// IfMatch only applies the update of a role or role-binding when it was not
+// modified since the version with the given entity tag was read, failing with
+// ErrPreconditionFailed otherwise.
+func IfMatch(etag string) CallOption {
+	return func(o *callOptions) {
+		o.ifMatch = etag
+	}
+}
+
+// ETag stores the entity tag of the role or role-binding returned by the call
+// in etag, to be passed to IfMatch by a later update.
+func ETag(etag *string) CallOption {
+	return func(o *callOptions) {
+		o.etag = etag
+	}
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"go.infratographer.com/x/gidx"
+)
+
+// ListRelationshipsFrom lists the relationships from the resource to its subjects.
+func (c *Client) ListRelationshipsFrom(ctx context.Context, resourceID gidx.PrefixedID, opts ...CallOption) ([]Relationship, error) {
+	return c.listRelationships(ctx, "/api/v1/relationships/from/"+resourceID.String(), opts)
+}
+
+// ListRelationshipsTo lists the relationships from other resources to the subject.
+func (c *Client) ListRelationshipsTo(ctx context.Context, subjectID gidx.PrefixedID, opts ...CallOption) ([]Relationship, error) {
+	return c.listRelationships(ctx, "/api/v1/relationships/to/"+subjectID.String(), opts)
+}
+
+func (c *Client) listRelationships(ctx context.Context, path string, opts []CallOption) ([]Relationship, error) {
+	var resp listResponse[Relationship]
+
+	err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       path,
+		idempotent: true,
+		status:     http.StatusOK,
+	}, &resp, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"go.infratographer.com/x/gidx"
+)
+
+type roleBindingRequest struct {
+	RoleID     string            `json:"role_id,omitempty"`
+	SubjectIDs []gidx.PrefixedID `json:"subject_ids"`
+}
+
+// CreateRoleBinding grants the role on the resource to the subjects.
+func (c *Client) CreateRoleBinding(ctx context.Context, resourceID, roleID gidx.PrefixedID, subjectIDs []gidx.PrefixedID, opts ...CallOption) (*RoleBinding, error) {
+	var rb RoleBinding
+
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/v3/resources/" + resourceID.String() + "/role-bindings",
+		body:   roleBindingRequest{RoleID: roleID.String(), SubjectIDs: subjectIDs},
+		status: http.StatusCreated,
+	}, &rb, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rb, nil
+}
+
+// GetRoleBinding returns the role-binding.
+func (c *Client) GetRoleBinding(ctx context.Context, roleBindingID gidx.PrefixedID, opts ...CallOption) (*RoleBinding, error) {
+	var rb RoleBinding
+
+	err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       "/api/v3/role-bindings/" + roleBindingID.String(),
+		idempotent: true,
+		status:     http.StatusOK,
+	}, &rb, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rb, nil
+}
+
+// ListRoleBindings lists the role-bindings on the resource.
+func (c *Client) ListRoleBindings(ctx context.Context, resourceID gidx.PrefixedID, opts ...CallOption) ([]RoleBinding, error) {
+	var resp listResponse[RoleBinding]
+
+	err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       "/api/v3/resources/" + resourceID.String() + "/role-bindings",
+		idempotent: true,
+		status:     http.StatusOK,
+	}, &resp, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// UpdateRoleBinding replaces the subjects of the role-binding.
+func (c *Client) UpdateRoleBinding(ctx context.Context, roleBindingID gidx.PrefixedID, subjectIDs []gidx.PrefixedID, opts ...CallOption) (*RoleBinding, error) {
+	var rb RoleBinding
+
+	err := c.do(ctx, call{
+		method:     http.MethodPatch,
+		path:       "/api/v3/role-bindings/" + roleBindingID.String(),
+		body:       roleBindingRequest{SubjectIDs: subjectIDs},
+		idempotent: true,
+		status:     http.StatusOK,
+	}, &rb, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rb, nil
+}
+
+// DeleteRoleBinding deletes the role-binding.
+func (c *Client) DeleteRoleBinding(ctx context.Context, roleBindingID gidx.PrefixedID, opts ...CallOption) error {
+	return c.do(ctx, call{
+		method:     http.MethodDelete,
+		path:       "/api/v3/role-bindings/" + roleBindingID.String(),
+		idempotent: true,
+		status:     http.StatusOK,
+	}, nil, opts)
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"go.infratographer.com/x/gidx"
+)
+
+type deleteRoleResponse struct {
+	DeletedRoleBindings int `json:"deleted_role_bindings"`
+}
+
+type roleRequest struct {
+	Name    string   `json:"name,omitempty"`
+	Actions []string `json:"actions,omitempty"`
+}
+
+// CreateRole creates a role with the given actions on the resource.
+func (c *Client) CreateRole(ctx context.Context, resourceID gidx.PrefixedID, name string, actions []string, opts ...CallOption) (*Role, error) {
+	var role Role
+
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/v3/resources/" + resourceID.String() + "/roles",
+		body:   roleRequest{Name: name, Actions: actions},
+		status: http.StatusCreated,
+	}, &role, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// GetRole returns the role.
+func (c *Client) GetRole(ctx context.Context, roleID gidx.PrefixedID, opts ...CallOption) (*Role, error) {
+	var role Role
+
+	err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       "/api/v3/roles/" + roleID.String(),
+		idempotent: true,
+		status:     http.StatusOK,
+	}, &role, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// ListRoles lists the roles available on the resource, only their ID and name are set.
+func (c *Client) ListRoles(ctx context.Context, resourceID gidx.PrefixedID, opts ...CallOption) ([]Role, error) {
+	var resp listResponse[Role]
+
+	err := c.do(ctx, call{
+		method:     http.MethodGet,
+		path:       "/api/v3/resources/" + resourceID.String() + "/roles",
+		idempotent: true,
+		status:     http.StatusOK,
+	}, &resp, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
+// UpdateRole updates the name and actions of the role, empty values are left unchanged.
+func (c *Client) UpdateRole(ctx context.Context, roleID gidx.PrefixedID, name string, actions []string, opts ...CallOption) (*Role, error) {
+	var role Role
+
+	err := c.do(ctx, call{
+		method:     http.MethodPatch,
+		path:       "/api/v3/roles/" + roleID.String(),
+		body:       roleRequest{Name: name, Actions: actions},
+		idempotent: true,
+		status:     http.StatusOK,
+	}, &role, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// DeleteRole deletes the role.
+func (c *Client) DeleteRole(ctx context.Context, roleID gidx.PrefixedID, opts ...CallOption) error {
+	return c.do(ctx, call{
+		method:     http.MethodDelete,
+		path:       "/api/v3/roles/" + roleID.String(),
+		idempotent: true,
+		status:     http.StatusOK,
+	}, nil, opts)
+}
+
+// ForceDeleteRole deletes the role together with all role-bindings of the
+// role, returning the number of deleted role-bindings.
+func (c *Client) ForceDeleteRole(ctx context.Context, roleID gidx.PrefixedID, opts ...CallOption) (int, error) {
+	var resp deleteRoleResponse
+
+	err := c.do(ctx, call{
+		method:     http.MethodDelete,
+		path:       "/api/v3/roles/" + roleID.String(),
+		query:      url.Values{"force": []string{"true"}},
+		idempotent: true,
+		status:     http.StatusOK,
+	}, &resp, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.DeletedRoleBindings, nil
+}
@@ -0,0 +1,49 @@
+package client
+
+import "go.infratographer.com/x/gidx"
+
+// Role is a named set of actions defined on a resource.
+type Role struct {
+	ID         gidx.PrefixedID `json:"id"`
+	Name       string          `json:"name"`
+	Actions    []string        `json:"actions,omitempty"`
+	ResourceID gidx.PrefixedID `json:"resource_id,omitempty"`
+
+	CreatedBy  gidx.PrefixedID `json:"created_by,omitempty"`
+	UpdatedBy  gidx.PrefixedID `json:"updated_by,omitempty"`
+	CreatedAt  string          `json:"created_at,omitempty"`
+	UpdatedAt  string          `json:"updated_at,omitempty"`
+	LastUsedAt string          `json:"last_used_at,omitempty"`
+}
+
+// RoleBinding grants the actions of a role on a resource to subjects.
+type RoleBinding struct {
+	ID         gidx.PrefixedID   `json:"id"`
+	ResourceID gidx.PrefixedID   `json:"resource_id"`
+	RoleID     gidx.PrefixedID   `json:"role_id"`
+	SubjectIDs []gidx.PrefixedID `json:"subject_ids"`
+
+	CreatedBy  gidx.PrefixedID `json:"created_by,omitempty"`
+	UpdatedBy  gidx.PrefixedID `json:"updated_by,omitempty"`
+	CreatedAt  string          `json:"created_at,omitempty"`
+	UpdatedAt  string          `json:"updated_at,omitempty"`
+	LastUsedAt string          `json:"last_used_at,omitempty"`
+}
+
+// Relationship is a relation between a resource and a subject, only the
+// other side of the relationship is set when listing relationships of a resource.
+type Relationship struct {
+	ResourceID string `json:"resource_id,omitempty"`
+	Relation   string `json:"relation"`
+	SubjectID  string `json:"subject_id,omitempty"`
+}
+
+// AccessRequest is an action to check on a resource.
+type AccessRequest struct {
+	ResourceID gidx.PrefixedID `json:"resource_id"`
+	Action     string          `json:"action"`
+}
+
+type listResponse[T any] struct {
+	Data []T `json:"data"`
+}
@@ -0,0 +1,91 @@
+package permissions
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheSize is the default maximum number of cached allow decisions.
+const DefaultCacheSize = 10000
+
+// decisionCache caches allow decisions for a limited time. Denied checks are
+// never cached, so a granted permission takes effect immediately while a
+// revoked permission may be allowed for up to the TTL.
+type decisionCache struct {
+	ttl  time.Duration
+	size int
+
+	mu      sync.Mutex
+	expires map[string]time.Time
+
+	now func() time.Time
+}
+
+func newDecisionCache(ttl time.Duration, size int) *decisionCache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+
+	return &decisionCache{
+		ttl:     ttl,
+		size:    size,
+		expires: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// decisionKey identifies the access requests of an actor checked together.
+func decisionKey(actor string, mode CheckMode, requests []AccessRequest) string {
+	var key strings.Builder
+
+	key.WriteString(actor)
+	key.WriteString("|")
+	key.WriteString(string(mode))
+
+	for _, request := range requests {
+		key.WriteString("|")
+		key.WriteString(request.ResourceID.String())
+		key.WriteString(":")
+		key.WriteString(request.Action)
+	}
+
+	return key.String()
+}
+
+// allowed reports whether an allow decision is cached and not expired.
+func (c *decisionCache) allowed(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires, ok := c.expires[key]
+
+	return ok && c.now().Before(expires)
+}
+
+// allow caches an allow decision, making room by dropping expired decisions,
+// or arbitrary ones if none expired, once the cache is full.
+func (c *decisionCache) allow(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+
+	if _, ok := c.expires[key]; !ok && len(c.expires) >= c.size {
+		for k, expires := range c.expires {
+			if !now.Before(expires) {
+				delete(c.expires, k)
+			}
+		}
+
+		for k := range c.expires {
+			if len(c.expires) < c.size {
+				break
+			}
+
+			delete(c.expires, k)
+		}
+	}
+
+	c.expires[key] = now.Add(c.ttl)
+}
@@ -31,8 +31,32 @@ type AccessRequest struct {
 	Action     string          `json:"action"`
 }
 
+// CheckMode defines how the results of several access requests are combined.
+type CheckMode string
+
+const (
+	// CheckModeAll requires all access requests to be allowed.
+	CheckModeAll CheckMode = "all"
+
+	// CheckModeAny requires at least one access request to be allowed.
+	CheckModeAny CheckMode = "any"
+)
+
 type checkerCtxKey struct{}
 
+type checkModeCtxKey struct{}
+
+// CheckModeFromContext returns the mode the checker should combine the
+// results of access requests with, CheckModeAll unless called by CheckAny.
+func CheckModeFromContext(ctx context.Context) CheckMode {
+	mode, ok := ctx.Value(checkModeCtxKey{}).(CheckMode)
+	if !ok {
+		return CheckModeAll
+	}
+
+	return mode
+}
+
 func setCheckerContext(c echo.Context, checker Checker) {
 	if checker == nil {
 		checker = DefaultDenyChecker
@@ -73,3 +97,14 @@ func CheckAll(ctx context.Context, requests ...AccessRequest) error {
 
 	return checker(ctx, requests...)
 }
+
+// CheckAny runs the checker function to check if any of the provided resources and actions are permitted.
+// All requests are checked in a single call.
+func CheckAny(ctx context.Context, requests ...AccessRequest) error {
+	checker, ok := ctx.Value(CheckerCtxKey).(Checker)
+	if !ok {
+		return ErrCheckerNotFound
+	}
+
+	return checker(context.WithValue(ctx, checkModeCtxKey{}, CheckModeAny), requests...)
+}
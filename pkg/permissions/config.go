@@ -1,6 +1,8 @@
 package permissions
 
 import (
+	"time"
+
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"go.infratographer.com/x/viperx"
@@ -18,6 +20,14 @@ type Config struct {
 
 	// DefaultAllow if set to true, will allow all permissions checks when URL is not set.
 	DefaultAllow bool
+
+	// CacheTTL, when set, caches allow decisions for the given time, which is
+	// the maximum time a revoked permission may still be allowed for. Routes
+	// can bypass the cache with WithCacheSkipper.
+	CacheTTL time.Duration
+
+	// CacheSize is the maximum number of cached allow decisions.
+	CacheSize int
 }
 
 // MustViperFlags adds permissions config flags and viper bindings
@@ -30,4 +40,10 @@ func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
 
 	flags.Bool("permissions-default-allow", false, "grant permission checks when url is not set")
 	viperx.MustBindFlag(v, "permissions.defaultAllow", flags.Lookup("permissions-default-allow"))
+
+	flags.Duration("permissions-cache-ttl", 0, "time allow decisions are cached for (caching is disabled when 0)")
+	viperx.MustBindFlag(v, "permissions.cacheTTL", flags.Lookup("permissions-cache-ttl"))
+
+	flags.Int("permissions-cache-size", DefaultCacheSize, "maximum number of cached allow decisions")
+	viperx.MustBindFlag(v, "permissions.cacheSize", flags.Lookup("permissions-cache-size"))
 }
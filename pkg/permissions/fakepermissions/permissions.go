@@ -0,0 +1,253 @@
+// Package fakepermissions provides an in-memory fake of the permissions
+// checker and auth relationship publisher with programmable allow and deny
+// rules, so applications can test their authorization logic without running
+// permissions-api, SpiceDB and NATS.
+package fakepermissions
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/pkg/permissions"
+)
+
+var (
+	_ permissions.AuthRelationshipRequestHandler = (*Permissions)(nil)
+	_ events.AuthRelationshipPublisher           = (*Permissions)(nil)
+)
+
+// anyAction is the rule key matching every action on a resource.
+const anyAction = ""
+
+// Permissions is an in-memory fake of permissions-api. Access requests are
+// allowed or denied by the rules set with Allow and Deny, requests matching no
+// rule are denied unless DefaultAllow is set. Relationships written or deleted,
+// either through the request handler or the publisher, are recorded.
+type Permissions struct {
+	mu sync.Mutex
+
+	rules        map[gidx.PrefixedID]map[string]bool
+	defaultAllow bool
+	checks       []permissions.AccessRequest
+
+	relationships     map[gidx.PrefixedID][]events.AuthRelationshipRelation
+	relationshipError error
+}
+
+// New creates a new fake denying all access requests.
+func New() *Permissions {
+	return &Permissions{
+		rules:         make(map[gidx.PrefixedID]map[string]bool),
+		relationships: make(map[gidx.PrefixedID][]events.AuthRelationshipRelation),
+	}
+}
+
+func (p *Permissions) setRule(allowed bool, resourceID gidx.PrefixedID, actions []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.rules[resourceID] == nil {
+		p.rules[resourceID] = make(map[string]bool)
+	}
+
+	if len(actions) == 0 {
+		actions = []string{anyAction}
+	}
+
+	for _, action := range actions {
+		p.rules[resourceID][action] = allowed
+	}
+}
+
+// Allow allows the given actions on the resource, or all actions if none are given.
+func (p *Permissions) Allow(resourceID gidx.PrefixedID, actions ...string) *Permissions {
+	p.setRule(true, resourceID, actions)
+
+	return p
+}
+
+// Deny denies the given actions on the resource, or all actions if none are given.
+// Rules for specific actions take precedence over rules for all actions.
+func (p *Permissions) Deny(resourceID gidx.PrefixedID, actions ...string) *Permissions {
+	p.setRule(false, resourceID, actions)
+
+	return p
+}
+
+// DefaultAllow sets whether access requests matching no rule are allowed.
+func (p *Permissions) DefaultAllow(allow bool) *Permissions {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.defaultAllow = allow
+
+	return p
+}
+
+// SetRelationshipError makes all following relationship requests fail with the given error, nil resets it.
+func (p *Permissions) SetRelationshipError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.relationshipError = err
+}
+
+// allowed returns whether an access request is allowed, p.mu must be held.
+func (p *Permissions) allowed(request permissions.AccessRequest) bool {
+	rules := p.rules[request.ResourceID]
+
+	if allowed, ok := rules[request.Action]; ok {
+		return allowed
+	}
+
+	if allowed, ok := rules[anyAction]; ok {
+		return allowed
+	}
+
+	return p.defaultAllow
+}
+
+// Checker returns the permissions.Checker evaluating access requests against
+// the rules, combining them as requested by permissions.CheckAll or permissions.CheckAny.
+func (p *Permissions) Checker() permissions.Checker {
+	return func(ctx context.Context, requests ...permissions.AccessRequest) error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		p.checks = append(p.checks, requests...)
+
+		allowed := 0
+
+		for _, request := range requests {
+			if p.allowed(request) {
+				allowed++
+			}
+		}
+
+		switch permissions.CheckModeFromContext(ctx) {
+		case permissions.CheckModeAny:
+			if allowed == 0 {
+				return permissions.ErrPermissionDenied
+			}
+		default:
+			if allowed != len(requests) {
+				return permissions.ErrPermissionDenied
+			}
+		}
+
+		return nil
+	}
+}
+
+// Checks returns all access requests checked so far.
+func (p *Permissions) Checks() []permissions.AccessRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return slices.Clone(p.checks)
+}
+
+// ContextWithHandler returns the context with the fake set as the checker and
+// auth relationship request handler, as the permissions middleware would.
+func (p *Permissions) ContextWithHandler(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, permissions.CheckerCtxKey, p.Checker())
+
+	return context.WithValue(ctx, permissions.AuthRelationshipRequestHandlerCtxKey, p)
+}
+
+// Relationships returns the relationships recorded for the resource.
+func (p *Permissions) Relationships(resourceID gidx.PrefixedID) []events.AuthRelationshipRelation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return slices.Clone(p.relationships[resourceID])
+}
+
+// applyRequest records the relationships written or deleted by the request.
+func (p *Permissions) applyRequest(request events.AuthRelationshipRequest) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.relationshipError != nil {
+		return p.relationshipError
+	}
+
+	relationships := p.relationships[request.ObjectID]
+
+	for _, relation := range request.Relations {
+		idx := slices.Index(relationships, relation)
+
+		switch {
+		case request.Action == events.WriteAuthRelationshipAction && idx == -1:
+			relationships = append(relationships, relation)
+		case request.Action == events.DeleteAuthRelationshipAction && idx != -1:
+			relationships = slices.Delete(relationships, idx, idx+1)
+		}
+	}
+
+	p.relationships[request.ObjectID] = relationships
+
+	return nil
+}
+
+// CreateAuthRelationships implements permissions.AuthRelationshipRequestHandler.
+func (p *Permissions) CreateAuthRelationships(_ context.Context, _ string, resourceID gidx.PrefixedID, relations ...events.AuthRelationshipRelation) error {
+	return p.applyRequest(events.AuthRelationshipRequest{
+		Action:    events.WriteAuthRelationshipAction,
+		ObjectID:  resourceID,
+		Relations: relations,
+	})
+}
+
+// DeleteAuthRelationships implements permissions.AuthRelationshipRequestHandler.
+func (p *Permissions) DeleteAuthRelationships(_ context.Context, _ string, resourceID gidx.PrefixedID, relations ...events.AuthRelationshipRelation) error {
+	return p.applyRequest(events.AuthRelationshipRequest{
+		Action:    events.DeleteAuthRelationshipAction,
+		ObjectID:  resourceID,
+		Relations: relations,
+	})
+}
+
+// PublishAuthRelationshipRequest implements events.AuthRelationshipPublisher,
+// so the fake can be passed to permissions.WithEventsPublisher.
+func (p *Permissions) PublishAuthRelationshipRequest(_ context.Context, topic string, message events.AuthRelationshipRequest) (events.Message[events.AuthRelationshipResponse], error) {
+	response := events.AuthRelationshipResponse{}
+
+	if err := p.applyRequest(message); err != nil {
+		response.Errors = append(response.Errors, err)
+	}
+
+	return &responseMessage{
+		topic:     topic,
+		response:  response,
+		timestamp: time.Now(),
+	}, nil
+}
+
+// responseMessage is the response to a published auth relationship request.
+type responseMessage struct {
+	topic     string
+	response  events.AuthRelationshipResponse
+	timestamp time.Time
+}
+
+func (m *responseMessage) Connection() events.Connection            { return nil }
+func (m *responseMessage) ID() string                               { return "" }
+func (m *responseMessage) Topic() string                            { return m.topic }
+func (m *responseMessage) Message() events.AuthRelationshipResponse { return m.response }
+func (m *responseMessage) Ack() error                               { return nil }
+func (m *responseMessage) Nak(time.Duration) error                  { return nil }
+func (m *responseMessage) Term() error                              { return nil }
+func (m *responseMessage) Timestamp() time.Time                     { return m.timestamp }
+func (m *responseMessage) Deliveries() uint64                       { return 1 }
+func (m *responseMessage) Error() error                             { return nil }
+func (m *responseMessage) Source() any                              { return nil }
@@ -0,0 +1,81 @@
+package fakepermissions_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/permissions-api/pkg/permissions/fakepermissions"
+)
+
+func TestChecker(t *testing.T) {
+	tenantID := gidx.PrefixedID("tnntten-abc123")
+	otherID := gidx.PrefixedID("tnntten-def456")
+
+	fake := fakepermissions.New().
+		Allow(tenantID).
+		Deny(tenantID, "tenant_delete").
+		Allow(otherID, "tenant_get")
+
+	ctx := fake.ContextWithHandler(context.Background())
+
+	assert.NoError(t, permissions.CheckAccess(ctx, tenantID, "tenant_update"))
+	assert.ErrorIs(t, permissions.CheckAccess(ctx, tenantID, "tenant_delete"), permissions.ErrPermissionDenied)
+	assert.NoError(t, permissions.CheckAccess(ctx, otherID, "tenant_get"))
+	assert.ErrorIs(t, permissions.CheckAccess(ctx, otherID, "tenant_update"), permissions.ErrPermissionDenied)
+
+	requests := []permissions.AccessRequest{
+		{ResourceID: tenantID, Action: "tenant_delete"},
+		{ResourceID: otherID, Action: "tenant_get"},
+	}
+
+	assert.ErrorIs(t, permissions.CheckAll(ctx, requests...), permissions.ErrPermissionDenied)
+	assert.NoError(t, permissions.CheckAny(ctx, requests...))
+
+	assert.Len(t, fake.Checks(), 8)
+
+	fake.DefaultAllow(true)
+
+	assert.NoError(t, permissions.CheckAccess(ctx, "tnntten-unknown", "tenant_get"))
+}
+
+func TestRelationships(t *testing.T) {
+	resourceID := gidx.PrefixedID("loadbal-abc123")
+
+	parent := events.AuthRelationshipRelation{Relation: "owner", SubjectID: "tnntten-abc123"}
+
+	t.Run("handler", func(t *testing.T) {
+		fake := fakepermissions.New()
+
+		ctx := fake.ContextWithHandler(context.Background())
+
+		require.NoError(t, permissions.CreateAuthRelationships(ctx, "loadbalancer", resourceID, parent))
+		assert.Equal(t, []events.AuthRelationshipRelation{parent}, fake.Relationships(resourceID))
+
+		require.NoError(t, permissions.DeleteAuthRelationships(ctx, "loadbalancer", resourceID, parent))
+		assert.Empty(t, fake.Relationships(resourceID))
+	})
+
+	t.Run("publisher", func(t *testing.T) {
+		fake := fakepermissions.New()
+
+		perms, err := permissions.New(permissions.Config{}, permissions.WithEventsPublisher(fake))
+		require.NoError(t, err)
+
+		require.NoError(t, perms.CreateAuthRelationships(context.Background(), "loadbalancer", resourceID, parent))
+		assert.Equal(t, []events.AuthRelationshipRelation{parent}, fake.Relationships(resourceID))
+
+		errFailed := errors.New("failed")
+
+		fake.SetRelationshipError(errFailed)
+
+		assert.ErrorIs(t, perms.DeleteAuthRelationships(context.Background(), "loadbalancer", resourceID, parent), errFailed)
+		assert.Equal(t, []events.AuthRelationshipRelation{parent}, fake.Relationships(resourceID))
+	})
+}
@@ -55,3 +55,13 @@ func WithDefaultChecker(checker Checker) Option {
 		return nil
 	}
 }
+
+// WithCacheSkipper sets the function deciding which requests bypass the
+// decision cache, e.g. sensitive routes which must always be checked.
+func WithCacheSkipper(skipper middleware.Skipper) Option {
+	return func(p *Permissions) error {
+		p.cacheSkipper = skipper
+
+		return nil
+	}
+}
@@ -52,6 +52,8 @@ type Permissions struct {
 	skipper            middleware.Skipper
 	defaultChecker     Checker
 	ignoreNoResponders bool
+	cache              *decisionCache
+	cacheSkipper       middleware.Skipper
 }
 
 // Middleware produces echo middleware to handle authorization checks
@@ -92,24 +94,56 @@ func (p *Permissions) Middleware() echo.MiddlewareFunc {
 
 type checkPermissionRequest struct {
 	Actions []AccessRequest `json:"actions"`
+	Mode    CheckMode       `json:"mode,omitempty"`
 }
 
 func (p *Permissions) checker(c echo.Context, actor, _ string) Checker {
+	cache := p.cache
+	if cache != nil && p.cacheSkipper(c) {
+		cache = nil
+	}
+
 	return func(ctx context.Context, requests ...AccessRequest) error {
 		ctx, span := tracer.Start(ctx, "permissions.checker")
 		defer span.End()
 
+		mode := CheckModeFromContext(ctx)
+
 		span.SetAttributes(
 			attribute.String("permissions.actor", actor),
 			attribute.Int("permissions.requests", len(requests)),
+			attribute.String("permissions.mode", string(mode)),
 		)
 
-		logger := p.logger.With("actor", actor, "requests", len(requests))
+		logger := p.logger.With("actor", actor, "requests", len(requests), "mode", mode)
 
 		request := checkPermissionRequest{
 			Actions: requests,
 		}
 
+		if mode != CheckModeAll {
+			request.Mode = mode
+		}
+
+		var cacheKey string
+
+		if cache != nil {
+			cacheKey = decisionKey(actor, mode, requests)
+
+			if cache.allowed(cacheKey) {
+				span.SetAttributes(
+					attribute.String(
+						"permissions.outcome",
+						outcomeAllowed,
+					),
+					attribute.Bool("permissions.cached", true),
+				)
+				logger.Debug("access granted to resource from cache")
+
+				return nil
+			}
+		}
+
 		var reqBody bytes.Buffer
 
 		if err := json.NewEncoder(&reqBody).Encode(request); err != nil {
@@ -175,6 +209,10 @@ func (p *Permissions) checker(c echo.Context, actor, _ string) Checker {
 		)
 		logger.Debug("access granted to resource")
 
+		if cache != nil {
+			cache.allow(cacheKey)
+		}
+
 		return nil
 	}
 }
@@ -185,6 +223,7 @@ func New(config Config, options ...Option) (*Permissions, error) {
 		enableChecker:      config.URL != "",
 		client:             defaultClient,
 		skipper:            middleware.DefaultSkipper,
+		cacheSkipper:       middleware.DefaultSkipper,
 		defaultChecker:     DefaultDenyChecker,
 		ignoreNoResponders: config.IgnoreNoResponders,
 	}
@@ -198,6 +237,10 @@ func New(config Config, options ...Option) (*Permissions, error) {
 		p.url = uri
 	}
 
+	if config.CacheTTL > 0 {
+		p.cache = newDecisionCache(config.CacheTTL, config.CacheSize)
+	}
+
 	if config.URL == "" && config.DefaultAllow {
 		p.defaultChecker = DefaultAllowChecker
 	}
@@ -1,10 +1,12 @@
 package permissions_test
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/require"
@@ -260,3 +262,131 @@ func TestPermissions(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckAny(t *testing.T) {
+	allowedID := gidx.MustNewID("testgid")
+	deniedID := gidx.MustNewID("testgid")
+
+	var requests int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		var reqBody struct {
+			Actions []struct {
+				ResourceID string `json:"resource_id"`
+			} `json:"actions"`
+			Mode string `json:"mode"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		var allowed int
+
+		for _, request := range reqBody.Actions {
+			if request.ResourceID == allowedID.String() {
+				allowed++
+			}
+		}
+
+		switch {
+		case reqBody.Mode == "any" && allowed != 0:
+		case allowed == len(reqBody.Actions):
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+
+	perms, err := permissions.New(permissions.Config{URL: srv.URL})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer good-token")
+
+	ctx := echo.New().NewContext(req, httptest.NewRecorder())
+	ctx.Set(echojwtx.ActorKey, "idntusr-abc123")
+
+	err = perms.Middleware()(func(echo.Context) error { return nil })(ctx)
+	require.NoError(t, err)
+
+	requestCtx := ctx.Request().Context()
+
+	mixed := []permissions.AccessRequest{
+		{ResourceID: deniedID, Action: "resource_get"},
+		{ResourceID: allowedID, Action: "resource_get"},
+	}
+
+	require.NoError(t, permissions.CheckAny(requestCtx, mixed...))
+	require.ErrorIs(t, permissions.CheckAll(requestCtx, mixed...), permissions.ErrPermissionDenied)
+	require.ErrorIs(t, permissions.CheckAny(requestCtx, mixed[0]), permissions.ErrPermissionDenied)
+
+	// every check is a single request
+	require.Equal(t, 3, requests)
+}
+
+func TestDecisionCache(t *testing.T) {
+	allowedID := gidx.MustNewID("testgid")
+	deniedID := gidx.MustNewID("testgid")
+
+	var requests int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		var reqBody struct {
+			Actions []struct {
+				ResourceID string `json:"resource_id"`
+			} `json:"actions"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		for _, request := range reqBody.Actions {
+			if request.ResourceID != allowedID.String() {
+				w.WriteHeader(http.StatusForbidden)
+
+				return
+			}
+		}
+	}))
+
+	perms, err := permissions.New(
+		permissions.Config{URL: srv.URL, CacheTTL: time.Minute},
+		permissions.WithCacheSkipper(func(c echo.Context) bool {
+			return c.Request().URL.Path == "/sensitive"
+		}),
+	)
+	require.NoError(t, err)
+
+	checkCtx := func(path string) context.Context {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer good-token")
+
+		ctx := echo.New().NewContext(req, httptest.NewRecorder())
+		ctx.Set(echojwtx.ActorKey, "idntusr-abc123")
+
+		err := perms.Middleware()(func(echo.Context) error { return nil })(ctx)
+		require.NoError(t, err)
+
+		return ctx.Request().Context()
+	}
+
+	require.NoError(t, permissions.CheckAccess(checkCtx("/"), allowedID, "resource_get"))
+	require.NoError(t, permissions.CheckAccess(checkCtx("/"), allowedID, "resource_get"))
+	require.Equal(t, 1, requests, "allow decisions are cached")
+
+	require.ErrorIs(t, permissions.CheckAccess(checkCtx("/"), deniedID, "resource_get"), permissions.ErrPermissionDenied)
+	require.ErrorIs(t, permissions.CheckAccess(checkCtx("/"), deniedID, "resource_get"), permissions.ErrPermissionDenied)
+	require.Equal(t, 3, requests, "denied decisions are not cached")
+
+	require.NoError(t, permissions.CheckAccess(checkCtx("/sensitive"), allowedID, "resource_get"))
+	require.Equal(t, 4, requests, "skipped routes bypass the cache")
+}
@@ -0,0 +1,15 @@
+// Package permissionstest provides a permissions-api server for integration
+// tests, backed by SpiceDB and a CockroachDB test server with a policy
+// loaded, so tests don't depend on a hand-run docker-compose environment.
+//
+// Every harness serves its own SpiceDB namespace, so harnesses of parallel
+// tests may share a SpiceDB instance:
+//
+//	h := permissionstest.New(t, permissionstest.WithPolicyDir("policies"))
+//
+//	req.Header.Set("Authorization", "Bearer "+h.Token(t, "idntusr-abc123"))
+//
+// SpiceDB is provisioned like the integration tests of permissions-api, the
+// instance used is set with the PERMISSIONSAPI_TEST_SPICEDB_ENDPOINT and
+// PERMISSIONSAPI_TEST_SPICEDB_KEY environment variables.
+package permissionstest
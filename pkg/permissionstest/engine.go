@@ -0,0 +1,123 @@
+package permissionstest
+
+import (
+	"context"
+	"errors"
+
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/types"
+)
+
+// Engine sets up the roles, role-bindings and relationships tests start from,
+// without the permission checks of the API.
+type Engine interface {
+	// CreateRole creates a role with the given actions owned by the resource.
+	CreateRole(ctx context.Context, actorID, ownerID gidx.PrefixedID, name string, actions []string) (gidx.PrefixedID, error)
+	// CreateRoleBinding binds the role to the subjects on the resource.
+	CreateRoleBinding(ctx context.Context, actorID, resourceID, roleID gidx.PrefixedID, subjectIDs []gidx.PrefixedID) (gidx.PrefixedID, error)
+	// CreateRelationship creates a relationship between the resource and the subject.
+	CreateRelationship(ctx context.Context, resourceID gidx.PrefixedID, relation string, subjectID gidx.PrefixedID) error
+	// SubjectHasPermission reports whether the subject may perform the action on the resource.
+	SubjectHasPermission(ctx context.Context, subjectID gidx.PrefixedID, action string, resourceID gidx.PrefixedID) (bool, error)
+}
+
+// setupEngine implements Engine with a query engine not restricted by role delegation.
+type setupEngine struct {
+	engine query.Engine
+}
+
+var _ Engine = (*setupEngine)(nil)
+
+// CreateRole creates a v2 role owned by the resource.
+func (e *setupEngine) CreateRole(ctx context.Context, actorID, ownerID gidx.PrefixedID, name string, actions []string) (gidx.PrefixedID, error) {
+	resources, err := e.resources(actorID, ownerID)
+	if err != nil {
+		return "", err
+	}
+
+	role, err := e.engine.CreateRoleV2(ctx, resources[0], resources[1], name, actions)
+	if err != nil {
+		return "", err
+	}
+
+	return role.ID, nil
+}
+
+// CreateRoleBinding binds the role to the subjects on the resource.
+func (e *setupEngine) CreateRoleBinding(ctx context.Context, actorID, resourceID, roleID gidx.PrefixedID, subjectIDs []gidx.PrefixedID) (gidx.PrefixedID, error) {
+	resources, err := e.resources(actorID, resourceID, roleID)
+	if err != nil {
+		return "", err
+	}
+
+	subjectResources, err := e.resources(subjectIDs...)
+	if err != nil {
+		return "", err
+	}
+
+	subjects := make([]types.RoleBindingSubject, len(subjectResources))
+
+	for i, subject := range subjectResources {
+		subjects[i] = types.RoleBindingSubject{SubjectResource: subject}
+	}
+
+	rb, err := e.engine.CreateRoleBinding(ctx, resources[0], resources[1], resources[2], subjects)
+	if err != nil {
+		return "", err
+	}
+
+	return rb.ID, nil
+}
+
+// CreateRelationship creates a relationship between the resource and the subject.
+func (e *setupEngine) CreateRelationship(ctx context.Context, resourceID gidx.PrefixedID, relation string, subjectID gidx.PrefixedID) error {
+	resources, err := e.resources(resourceID, subjectID)
+	if err != nil {
+		return err
+	}
+
+	return e.engine.CreateRelationships(ctx, []types.Relationship{
+		{
+			Resource: resources[0],
+			Relation: relation,
+			Subject:  resources[1],
+		},
+	})
+}
+
+// SubjectHasPermission reports whether the subject may perform the action on the resource.
+func (e *setupEngine) SubjectHasPermission(ctx context.Context, subjectID gidx.PrefixedID, action string, resourceID gidx.PrefixedID) (bool, error) {
+	resources, err := e.resources(subjectID, resourceID)
+	if err != nil {
+		return false, err
+	}
+
+	err = e.engine.SubjectHasPermission(ctx, resources[0], action, resources[1])
+
+	switch {
+	case errors.Is(err, query.ErrActionNotAssigned):
+		return false, nil
+	case err != nil:
+		return false, err
+	default:
+		return true, nil
+	}
+}
+
+// resources returns the resources of the IDs in the policy of the harness.
+func (e *setupEngine) resources(ids ...gidx.PrefixedID) ([]types.Resource, error) {
+	resources := make([]types.Resource, len(ids))
+
+	for i, id := range ids {
+		resource, err := e.engine.NewResourceFromID(id)
+		if err != nil {
+			return nil, err
+		}
+
+		resources[i] = resource
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,147 @@
+package permissionstest
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/authzed/authzed-go/v1"
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/echojwtx"
+
+	"go.infratographer.com/permissions-api/internal/api"
+	"go.infratographer.com/permissions-api/internal/iapl"
+	"go.infratographer.com/permissions-api/internal/query"
+	"go.infratographer.com/permissions-api/internal/spicedbx/testspicedb"
+	"go.infratographer.com/permissions-api/internal/storage"
+	"go.infratographer.com/permissions-api/internal/storage/teststore"
+	"go.infratographer.com/permissions-api/internal/testauth"
+)
+
+// namespacePrefix is the prefix of the SpiceDB namespaces served by harnesses.
+const namespacePrefix = "permissionstest"
+
+// Harness is a permissions-api server for integration tests.
+type Harness struct {
+	// URL is the base URL of the permissions-api server, e.g. URL + "/api/v2/policy".
+	URL string
+	// Namespace is the SpiceDB namespace served by the harness.
+	Namespace string
+
+	auth   *testauth.Server
+	engine *setupEngine
+}
+
+type config struct {
+	policy func() (iapl.Policy, error)
+}
+
+// Option configures a harness.
+type Option func(*config)
+
+// WithPolicyDir loads the policy from the YAML files in the directory. The
+// default policy is loaded when no policy is given.
+func WithPolicyDir(dir string) Option {
+	return func(c *config) {
+		c.policy = func() (iapl.Policy, error) {
+			return iapl.NewPolicyFromDirectory(dir)
+		}
+	}
+}
+
+// WithPolicyFile loads the policy from a YAML file.
+func WithPolicyFile(path string) Option {
+	return func(c *config) {
+		c.policy = func() (iapl.Policy, error) {
+			return iapl.NewPolicyFromFile(path)
+		}
+	}
+}
+
+// New starts a permissions-api server with the policy loaded, everything is
+// stopped and removed when the test completes.
+func New(t *testing.T, options ...Option) *Harness {
+	t.Helper()
+
+	ctx := context.Background()
+
+	cfg := config{
+		policy: func() (iapl.Policy, error) {
+			return iapl.DefaultPolicy(), nil
+		},
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	policy, err := cfg.policy()
+	if err != nil {
+		t.Fatalf("failed to load policy: %s", err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		t.Fatalf("invalid policy: %s", err)
+	}
+
+	client, namespace := testspicedb.NewTestSpiceDB(ctx, t, namespacePrefix, policy.Schema(), policy.Caveats()...)
+
+	store, closeStore := teststore.NewTestStorage(t)
+	t.Cleanup(closeStore)
+
+	engine := newEngine(t, namespace, client, store, query.WithPolicy(policy))
+
+	// tests set up their state without the role delegation restrictions of the server
+	setup := newEngine(t, namespace, client, store, query.WithPolicy(policy), query.WithoutDelegation())
+
+	auth := testauth.NewServer(t)
+
+	router, err := api.NewRouter(echojwtx.AuthConfig{Issuer: auth.Issuer}, engine)
+	if err != nil {
+		t.Fatalf("failed to create router: %s", err)
+	}
+
+	e := echo.New()
+	router.Routes(e.Group(""))
+
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	return &Harness{
+		URL:       srv.URL,
+		Namespace: namespace,
+		auth:      auth,
+		engine:    &setupEngine{engine: setup},
+	}
+}
+
+// Token returns an access token for the subject accepted by the harness.
+func (h *Harness) Token(t *testing.T, subjectID string) string {
+	t.Helper()
+
+	return h.auth.TSignSubject(t, subjectID)
+}
+
+// Engine returns the engine tests set up the roles, role-bindings and
+// relationships they start from with.
+func (h *Harness) Engine() Engine {
+	return h.engine
+}
+
+// newEngine returns a query engine stopped when the test completes.
+func newEngine(t *testing.T, namespace string, client *authzed.Client, store storage.Storage, options ...query.Option) query.Engine {
+	t.Helper()
+
+	engine, err := query.NewEngine(namespace, client, store, options...)
+	if err != nil {
+		t.Fatalf("failed to create engine: %s", err)
+	}
+
+	t.Cleanup(func() {
+		if err := engine.Stop(); err != nil {
+			t.Errorf("failed to stop engine: %s", err)
+		}
+	})
+
+	return engine
+}
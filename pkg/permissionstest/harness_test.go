@@ -0,0 +1,39 @@
+package permissionstest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/permissions-api/pkg/client"
+	"go.infratographer.com/permissions-api/pkg/permissionstest"
+)
+
+func TestHarness(t *testing.T) {
+	ctx := context.Background()
+
+	h := permissionstest.New(t)
+
+	resp, err := http.Get(h.URL + "/api/v1/allow?resource=tnntten-abc123&action=loadbalancer_get")
+	require.NoError(t, err)
+
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	c, err := client.New(h.URL, client.WithToken(h.Token(t, "idntusr-abc123")))
+	require.NoError(t, err)
+
+	allowed, err := c.Check(ctx, "tnntten-abc123", "loadbalancer_get")
+	require.NoError(t, err)
+
+	assert.False(t, allowed)
+
+	allowed, err = h.Engine().SubjectHasPermission(ctx, "idntusr-abc123", "loadbalancer_get", "tnntten-abc123")
+	require.NoError(t, err)
+
+	assert.False(t, allowed)
+}